@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/config"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/mock"
+	"github.com/weekday-masters/backend/internal/pii"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/storage"
+)
+
+const commandUsage = `usage:
+  server                         run the API server
+  server backup                  dump the database to backup storage
+  server backups                 list stored backups, newest first
+  server restore <key|latest> --yes
+                                 replace the database with a backup
+  server export [file]           write the club to a JSON bundle (stdout by default)
+  server import <file> --yes     recreate an exported club in an empty database
+  server rotate-pii-keys         re-encrypt personal data with PII_ENCRYPTION_KEY,
+                                 reading old values with PII_ENCRYPTION_OLD_KEYS
+  server mock [flags]            serve the API from in-memory fixtures, without
+                                 Postgres or Auth0 (-h for flags)`
+
+// runCommand runs a maintenance command instead of the server. Backups and
+// restores are recorded as job runs, so they show under /api/admin/jobs.
+func runCommand(cfg *config.Config, name string, args []string) error {
+	switch name {
+	case "backup", "backups", "restore":
+		return runBackupCommand(cfg, name, args)
+
+	case "export":
+		if len(args) > 1 {
+			return errors.New(commandUsage)
+		}
+		bundle, err := services.NewClubExportService().Export()
+		if err != nil {
+			return err
+		}
+		out := io.Writer(os.Stdout)
+		if len(args) == 1 {
+			f, err := os.Create(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(bundle)
+
+	case "import":
+		if len(args) != 2 || args[1] != "--yes" {
+			return errors.New("import writes a whole club into this database; run `server import <file> --yes` to confirm\n" + commandUsage)
+		}
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		var bundle services.ClubBundle
+		if err := json.Unmarshal(raw, &bundle); err != nil {
+			return fmt.Errorf("reading bundle: %w", err)
+		}
+		// A fresh deployment may never have started the server
+		if err := database.Migrate(); err != nil {
+			return err
+		}
+		summary, err := services.NewClubExportService().Import(&bundle)
+		if err != nil {
+			return err
+		}
+		for section, n := range summary {
+			fmt.Printf("%s\t%d\n", section, n)
+		}
+		return nil
+
+	case "rotate-pii-keys":
+		if len(args) != 0 {
+			return errors.New(commandUsage)
+		}
+		return rotatePIIKeys()
+
+	default:
+		return fmt.Errorf("unknown command %q\n%s", name, commandUsage)
+	}
+}
+
+// piiBatchSize is how many users rotate-pii-keys reads at a time
+const piiBatchSize = 500
+
+// encryptedColumns are the users columns stored with the "encrypted" serializer
+var encryptedColumns = []string{
+	"phone_number",
+	"emergency_contact_name",
+	"emergency_contact_phone",
+	"medical_notes",
+}
+
+// rotatePIIKeys re-encrypts personal data with the current
+// PII_ENCRYPTION_KEY. PII_ENCRYPTION_OLD_KEYS must hold the previous key(s)
+// so existing values can be read. Plaintext values left over from before
+// encryption was enabled are encrypted too.
+func rotatePIIKeys() error {
+	if !pii.Enabled() {
+		return errors.New("PII_ENCRYPTION_KEY must be set")
+	}
+
+	// Read the raw columns rather than the model so values can be inspected before decryption
+	type row struct {
+		ID                    uuid.UUID
+		PhoneNumber           string
+		EmergencyContactName  string
+		EmergencyContactPhone string
+		MedicalNotes          string
+	}
+
+	rotated := 0
+	lastID := uuid.Nil
+	for {
+		var rows []row
+		if err := database.DB.Table("users").
+			Select("id, "+strings.Join(encryptedColumns, ", ")).
+			Where("id > ?", lastID).
+			Order("id ASC").
+			Limit(piiBatchSize).
+			Scan(&rows).Error; err != nil {
+			return fmt.Errorf("reading users: %w", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		for _, r := range rows {
+			lastID = r.ID
+			values := map[string]string{
+				"phone_number":            r.PhoneNumber,
+				"emergency_contact_name":  r.EmergencyContactName,
+				"emergency_contact_phone": r.EmergencyContactPhone,
+				"medical_notes":           r.MedicalNotes,
+			}
+
+			updates := map[string]interface{}{}
+			for _, column := range encryptedColumns {
+				if !pii.NeedsRotation(values[column]) {
+					continue
+				}
+				plaintext, err := pii.Decrypt(values[column])
+				if err != nil {
+					return fmt.Errorf("decrypting %s for user %s: %w", column, r.ID, err)
+				}
+				ciphertext, err := pii.Encrypt(plaintext)
+				if err != nil {
+					return fmt.Errorf("encrypting %s for user %s: %w", column, r.ID, err)
+				}
+				updates[column] = ciphertext
+			}
+			if len(updates) == 0 {
+				continue
+			}
+
+			if err := database.DB.Table("users").
+				Where("id = ?", r.ID).
+				UpdateColumns(updates).Error; err != nil {
+				return fmt.Errorf("updating user %s: %w", r.ID, err)
+			}
+			rotated += len(updates)
+		}
+	}
+
+	log.Printf("Re-encrypted %d values with key %s", rotated, pii.CurrentKeyID())
+	return nil
+}
+
+// runMock serves the mock API for frontend development on the usual port
+func runMock(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("mock", flag.ContinueOnError)
+	var mockCfg mock.Config
+	flags.DurationVar(&mockCfg.Latency, "latency", 0, "delay added to every request")
+	flags.DurationVar(&mockCfg.Jitter, "jitter", 0, "up to this much more delay, at random")
+	flags.Float64Var(&mockCfg.ErrorRate, "error-rate", 0, "share of requests, 0 to 1, that fail")
+	flags.IntVar(&mockCfg.ErrorStatus, "error-status", http.StatusInternalServerError, "status of failed requests")
+	flags.Int64Var(&mockCfg.Seed, "seed", 1, "seed for jitter and failures, to repeat a run")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if mockCfg.ErrorRate < 0 || mockCfg.ErrorRate > 1 {
+		return errors.New("-error-rate must be from 0 to 1")
+	}
+	if mockCfg.ErrorStatus < 400 || mockCfg.ErrorStatus > 599 {
+		return errors.New("-error-status must be from 400 to 599")
+	}
+
+	mockCfg.FrontendURL = cfg.FrontendURL
+
+	log.Printf("Mock server starting on port %s", cfg.Port)
+	return mock.New(mockCfg).Router().Run(":" + cfg.Port)
+}
+
+// runBackupCommand runs the commands that need backup storage
+func runBackupCommand(cfg *config.Config, name string, args []string) error {
+	backupService, err := newBackupService(cfg)
+	if err != nil {
+		return err
+	}
+	if backupService == nil {
+		return errors.New("BACKUP_STORAGE_BACKEND is not set")
+	}
+	jobService := services.NewJobService("")
+	ctx := context.Background()
+
+	switch name {
+	case "backup":
+		return jobService.Run(services.JobDatabaseBackup, func() error {
+			key, err := backupService.Backup(ctx, nil)
+			if err == nil {
+				fmt.Println(key)
+			}
+			return err
+		})
+
+	case "backups":
+		backups, err := backupService.ListBackups(ctx)
+		if err != nil {
+			return err
+		}
+		for _, b := range backups {
+			fmt.Printf("%s\t%d bytes\t%s\n", b.Key, b.Size, b.UpdatedAt.Format("2006-01-02 15:04"))
+		}
+		return nil
+
+	case "restore":
+		if len(args) != 2 || args[1] != "--yes" {
+			return errors.New("restore replaces every table; run `server restore <key|latest> --yes` to confirm\n" + commandUsage)
+		}
+		return jobService.Run(services.JobDatabaseRestore, func() error {
+			return backupService.Restore(ctx, args[0])
+		})
+	}
+	return nil
+}
+
+// newBackupService returns the configured backup service, or nil when backups are off
+func newBackupService(cfg *config.Config) (*services.BackupService, error) {
+	if cfg.BackupStorageBackend == "" {
+		return nil, nil
+	}
+	store, err := storage.New(context.Background(), cfg.BackupStorageBackend, cfg.BackupBucket, cfg.BackupLocalDir)
+	if err != nil {
+		return nil, fmt.Errorf("backup storage unavailable: %w", err)
+	}
+	log.Printf("Database backups go to %s", store.Name())
+	return services.NewBackupService(store, cfg.DatabaseURL, cfg.BackupRetentionDays)
+}
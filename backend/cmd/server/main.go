@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/weekday-masters/backend/internal/config"
 	"github.com/weekday-masters/backend/internal/database"
 	"github.com/weekday-masters/backend/internal/handlers"
+	"github.com/weekday-masters/backend/internal/kiosk"
 	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/pii"
 	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/storage"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -21,11 +29,36 @@ func main() {
 	// Set Gin mode
 	gin.SetMode(cfg.GinMode)
 
+	// Mock mode serves fixtures, so it needs no database or encryption key
+	if len(os.Args) > 1 && os.Args[1] == "mock" {
+		if err := runMock(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Configure encryption of personal data before anything is read or written
+	if err := pii.Configure(cfg.PIIEncryptionKey, cfg.PIIEncryptionOldKeys); err != nil {
+		log.Fatal("Failed to configure PII encryption:", err)
+	}
+	if !pii.Enabled() {
+		log.Println("Warning: PII_ENCRYPTION_KEY not set, personal data is stored unencrypted")
+	}
+
 	// Connect to database
 	if err := database.Connect(cfg.DatabaseURL); err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	// Maintenance commands (backup, restore, export, import, rotate-pii-keys)
+	// run instead of the server
+	if len(os.Args) > 1 {
+		if err := runCommand(cfg, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Run migrations
 	if err := database.Migrate(); err != nil {
 		log.Fatal("Failed to run migrations:", err)
@@ -33,8 +66,6 @@ func main() {
 
 	// Initialize services
 	userService := services.NewUserService(cfg.AdminEmail)
-	sessionService := services.NewSessionService()
-	rsvpService := services.NewRSVPService()
 
 	// Initialize notification service
 	notificationService := services.NewNotificationService(services.NotificationConfig{
@@ -43,33 +74,152 @@ func main() {
 		SendGridFromEmail:   cfg.SendGridFromEmail,
 		SendGridFromName:    cfg.SendGridFromName,
 		FrontendURL:         cfg.FrontendURL,
+		TwilioAccountSID:    cfg.TwilioAccountSID,
+		TwilioAuthToken:     cfg.TwilioAuthToken,
+		TwilioFromNumber:    cfg.TwilioFromNumber,
+		InboundEmailDomain:  cfg.InboundEmailDomain,
+		InboundEmailSecret:  cfg.InboundEmailSecret,
+	})
+
+	// Object storage for club documents, session and incident attachments and member photos; uploads are disabled without it
+	var documentStore storage.Store
+	if cfg.StorageBackend != "" {
+		store, err := storage.New(context.Background(), cfg.StorageBackend, cfg.StorageBucket, cfg.StorageLocalDir)
+		if err != nil {
+			log.Printf("Warning: document storage unavailable: %v", err)
+		} else {
+			documentStore = store
+		}
+	}
+	documentService := services.NewDocumentService(documentStore)
+	incidentService := services.NewIncidentService(documentStore, notificationService)
+	avatarService := services.NewAvatarService(documentStore, notificationService)
+
+	// Domain events recorded with the changes that raise them, for notifications and webhooks
+	outbox := services.NewOutbox(notificationService, cfg.WebhookURLs, cfg.WebhookSecret)
+
+	var changeDigest *services.SessionChangeDigest
+	if cfg.SessionChangeDebounceMinutes > 0 {
+		changeDigest = services.NewSessionChangeDigest(notificationService, time.Duration(cfg.SessionChangeDebounceMinutes)*time.Minute)
+	}
+	sessionService := services.NewSessionService(notificationService, changeDigest, documentStore, outbox)
+	rsvpService := services.NewRSVPService(notificationService, documentService, outbox)
+	badgeService := services.NewBadgeService(notificationService)
+	tournamentService := services.NewTournamentService(badgeService)
+	gameService := services.NewGameService(badgeService)
+	courtService := services.NewCourtService(notificationService)
+	reportService := services.NewReportService()
+	moderationService := services.NewModerationService(cfg.BannedWords, notificationService)
+	commentService := services.NewCommentService(moderationService, notificationService)
+	syncService := services.NewSyncService()
+	widgetService := services.NewWidgetService()
+	shareService := services.NewShareService(cfg.ShareLinkSecret)
+	cardService := services.NewCardService(cfg.MemberCardSecret, time.Duration(cfg.MemberCardValidDays)*24*time.Hour)
+	pendingActionService := services.NewPendingActionService(userService, sessionService)
+	jobService := services.NewJobService(cfg.HealthcheckURL)
+	accountLinkService := services.NewAccountLinkService(notificationService)
+	joinRuleService := services.NewJoinRuleService()
+	inviteService := services.NewInviteService(cfg.FrontendURL)
+	announcementService := services.NewAnnouncementService(notificationService,
+		time.Duration(cfg.AnnouncementAckNudgeHours)*time.Hour, cfg.AnnouncementAckMaxNudges)
+	archiveService := services.NewArchiveService(cfg.ArchiveNotificationsAfterMonths, cfg.ArchiveRSVPsAfterMonths)
+	orderService := services.NewOrderService(notificationService)
+	statsService := services.NewStatsService(notificationService)
+	inactivityService := services.NewMemberInactivityService(notificationService, cfg.MemberInactiveAfterMonths, cfg.MemberInactiveGraceDays)
+	contactLogService := services.NewContactLogService(notificationService)
+	usageService := services.NewUsageService()
+	usageService.Start(time.Minute)
+	allocationService, err := services.NewAllocationService(rsvpService, models.AllocationAlgorithm(cfg.AllocationAlgorithm), cfg.AllocationWindowDays)
+	if err != nil {
+		log.Fatal("Invalid fair-share allocation settings:", err)
+	}
+
+	backupService, err := newBackupService(cfg)
+	if err != nil {
+		log.Printf("Warning: database backups unavailable: %v", err)
+	}
+
+	// Forecasts for outdoor session reminders and weather cancellation proposals
+	var weather services.WeatherProvider
+	if cfg.WeatherLatitude != 0 || cfg.WeatherLongitude != 0 {
+		weather = services.NewOpenMeteoWeather(cfg.WeatherLatitude, cfg.WeatherLongitude)
+	}
+	weatherProposalService := services.NewWeatherProposalService(weather, sessionService, notificationService)
+
+	// Initialize scheduler for notification and maintenance cron jobs.
+	// It always runs since badge evaluation doesn't depend on a delivery channel;
+	// notifications are still recorded in history when no channel is enabled.
+	scheduler := services.NewSchedulerService(services.SchedulerConfig{
+		NotificationService:    notificationService,
+		BadgeService:           badgeService,
+		AllocationService:      allocationService,
+		SessionService:         sessionService,
+		RSVPService:            rsvpService,
+		AnnouncementService:    announcementService,
+		ArchiveService:         archiveService,
+		InactivityService:      inactivityService,
+		OrderService:           orderService,
+		StatsService:           statsService,
+		JobService:             jobService,
+		SessionChangeDigest:    changeDigest,
+		Outbox:                 outbox,
+		BackupService:          backupService,
+		NightlyBackup:          cfg.BackupNightly,
+		Weather:                weather,
+		WeatherProposals:       weatherProposalService,
+		ContactLogService:      contactLogService,
+		SessionReminderHours24: cfg.SessionReminderHours24,
+		SessionReminderHours12: cfg.SessionReminderHours12,
+		DeadlineReminderHours:  cfg.DeadlineReminderHours,
 	})
+	scheduler.Start()
 
-	// Initialize scheduler for notification cron jobs
-	var scheduler *services.SchedulerService
-	if notificationService.IsEnabled() {
-		scheduler = services.NewSchedulerService(services.SchedulerConfig{
-			NotificationService:    notificationService,
-			SessionReminderHours24: cfg.SessionReminderHours24,
-			SessionReminderHours12: cfg.SessionReminderHours12,
-			DeadlineReminderHours:  cfg.DeadlineReminderHours,
-		})
-		scheduler.Start()
+	// Settings that can be reloaded without a restart (SIGHUP or admin endpoint)
+	if err := cfg.Reloadable().Validate(); err != nil {
+		log.Fatal("Invalid reloadable settings:", err)
 	}
+	liveConfig := config.NewLive(cfg.Reloadable())
+	authCallbackRate := middleware.NewLimit(cfg.RateLimits.AuthCallback)
+	authCallbackAccountRate := middleware.NewLimit(cfg.RateLimits.AuthCallbackAccount)
+	accountLinkRate := middleware.NewLimit(cfg.RateLimits.AccountLink)
+	accountLinkAccountRate := middleware.NewLimit(cfg.RateLimits.AccountLinkAccount)
+	invitePreviewRate := middleware.NewLimit(cfg.RateLimits.InvitePreview)
+	liveConfig.OnChange(func(r config.Reloadable) {
+		scheduler.UpdateTimings(r.SessionReminderHours24, r.SessionReminderHours12, r.DeadlineReminderHours)
+		moderationService.SetBannedWords(r.BannedWords)
+		authCallbackRate.Set(r.RateLimits.AuthCallback)
+		authCallbackAccountRate.Set(r.RateLimits.AuthCallbackAccount)
+		accountLinkRate.Set(r.RateLimits.AccountLink)
+		accountLinkAccountRate.Set(r.RateLimits.AccountLinkAccount)
+		invitePreviewRate.Set(r.RateLimits.InvitePreview)
+	})
+
+	// Secrets rotated in the secret manager are picked up by the next refresh.
+	// DATABASE_URL still needs a restart.
+	inboundEmailService := services.NewInboundEmailService(cfg.InboundEmailDomain, cfg.InboundEmailSecret, rsvpService, notificationService)
+	cfg.OnSecretChange("FIREBASE_CREDENTIALS", notificationService.SetFirebaseCredentials)
+	cfg.OnSecretChange("SENDGRID_API_KEY", notificationService.SetSendGridAPIKey)
+	cfg.OnSecretChange("TWILIO_AUTH_TOKEN", notificationService.SetTwilioAuthToken)
+	cfg.OnSecretChange("INBOUND_EMAIL_SECRET", func(secret string) {
+		notificationService.SetInboundEmailSecret(secret)
+		inboundEmailService.SetSecret(secret)
+	})
+	cfg.OnSecretChange("SHARE_LINK_SECRET", shareService.SetSecret)
+	cfg.OnSecretChange("MEMBER_CARD_SECRET", cardService.SetSecret)
+	cfg.OnSecretChange("WEBHOOK_SECRET", outbox.SetWebhookSecret)
+	cfg.OnSecretChange("PII_ENCRYPTION_KEY", func(key string) {
+		if err := pii.SetCurrentKey(key); err != nil {
+			log.Printf("Warning: keeping the current PII key: %v", err)
+			return
+		}
+		log.Println("Encrypting personal data with the rotated PII key; add the previous key to PII_ENCRYPTION_OLD_KEYS before the next restart")
+	})
 
 	// Refresh recurring sessions on startup
-	if err := sessionService.RefreshRecurringSessions(); err != nil {
+	if err := jobService.Run(services.JobRecurringSessions, sessionService.RefreshRecurringSessions); err != nil {
 		log.Println("Warning: Failed to refresh recurring sessions:", err)
 	}
 
-	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(userService)
-	userHandler := handlers.NewUserHandler(userService)
-	sessionHandler := handlers.NewSessionHandler(sessionService, rsvpService)
-	rsvpHandler := handlers.NewRSVPHandler(rsvpService)
-	adminHandler := handlers.NewAdminHandler(userService, sessionService, rsvpService)
-	notificationHandler := handlers.NewNotificationHandler(notificationService)
-
 	// Auth0 config for middleware
 	auth0Config := middleware.Auth0Config{
 		Domain:   cfg.Auth0Domain,
@@ -78,84 +228,142 @@ func main() {
 
 	// Setup router
 	r := gin.Default()
+	if len(cfg.TrustedProxies) > 0 {
+		if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+			log.Fatal("Invalid TRUSTED_PROXIES:", err)
+		}
+	} else if len(cfg.AdminIPAllowlist) > 0 {
+		log.Println("Warning: ADMIN_IP_ALLOWLIST is set without TRUSTED_PROXIES, so a forged X-Forwarded-For can get past it")
+	}
+	adminIPAllowlist, err := middleware.IPAllowlist(cfg.AdminIPAllowlist)
+	if err != nil {
+		log.Fatal("Invalid ADMIN_IP_ALLOWLIST:", err)
+	}
 
 	// CORS middleware
-	r.Use(middleware.CORS(cfg.FrontendURL))
+	r.Use(middleware.CORS(liveConfig.AllowsOrigin))
+	r.Use(middleware.Compress())
 
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
-	// API routes
-	api := r.Group("/api")
-	{
-		// Public routes
-		api.POST("/auth/callback", authHandler.Callback)
-		api.GET("/club", adminHandler.GetClub)
-
-		// Protected routes (requires valid JWT)
-		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware(auth0Config))
-		{
-			// User routes
-			protected.GET("/users/me", userHandler.GetMe)
-			protected.PUT("/users/me", userHandler.UpdateMe)
-
-			// Notification preferences routes (available to all authenticated users)
-			protected.GET("/users/me/notifications", notificationHandler.GetPreferences)
-			protected.PUT("/users/me/notifications", notificationHandler.UpdatePreferences)
-			protected.POST("/users/me/push-tokens", notificationHandler.RegisterPushToken)
-			protected.DELETE("/users/me/push-tokens", notificationHandler.UnregisterPushToken)
-			protected.GET("/users/me/notifications/history", notificationHandler.GetNotificationHistory)
-			protected.POST("/notifications/:id/read", notificationHandler.MarkNotificationRead)
-
-			// These routes require approved membership
-			approved := protected.Group("")
-			approved.Use(middleware.RequireApproved())
-			{
-				protected.GET("/users", userHandler.ListMembers)
-
-				// Session routes
-				protected.GET("/sessions", sessionHandler.ListSessions)
-				protected.GET("/sessions/cancelled", sessionHandler.ListCancelledSessions)
-				protected.GET("/sessions/:id", sessionHandler.GetSession)
-
-				// RSVP routes
-				protected.POST("/sessions/:id/rsvp", rsvpHandler.CreateRSVP)
-				protected.PUT("/sessions/:id/rsvp", rsvpHandler.UpdateRSVP)
-				protected.DELETE("/sessions/:id/rsvp", rsvpHandler.DeleteRSVP)
-				protected.GET("/sessions/:id/rsvp/me", rsvpHandler.GetMyRSVP)
-			}
-
-			// Admin routes
-			admin := protected.Group("/admin")
-			admin.Use(middleware.RequireAdmin())
-			{
-				// Join requests
-				admin.GET("/join-requests", adminHandler.ListJoinRequests)
-				admin.POST("/join-requests/:id/approve", adminHandler.ApproveJoinRequest)
-				admin.POST("/join-requests/:id/reject", adminHandler.RejectJoinRequest)
-
-				// User management
-				admin.PUT("/users/:id/role", adminHandler.UpdateUserRole)
+	// Initialize handlers
+	api := handlers.NewAPI(handlers.Services{
+		Users:            userService,
+		AccountLinks:     accountLinkService,
+		JoinRules:        joinRuleService,
+		Invites:          inviteService,
+		Avatars:          avatarService,
+		Sessions:         sessionService,
+		RSVPs:            rsvpService,
+		PendingActions:   pendingActionService,
+		Club:             services.NewClubService(),
+		Notifications:    notificationService,
+		Moderation:       moderationService,
+		Announcements:    announcementService,
+		Tournaments:      tournamentService,
+		Games:            gameService,
+		Courts:           courtService,
+		Reports:          reportService,
+		Stats:            statsService,
+		Comments:         commentService,
+		Sync:             syncService,
+		Documents:        documentService,
+		Incidents:        incidentService,
+		Widget:           widgetService,
+		Allocation:       allocationService,
+		Jobs:             jobService,
+		Scheduler:        scheduler,
+		Events:           services.NewEventService(),
+		Archive:          archiveService,
+		ClubExport:       services.NewClubExportService(),
+		Inactivity:       inactivityService,
+		ContactLogs:      contactLogService,
+		Usage:            usageService,
+		Orders:           orderService,
+		Coaching:         services.NewCoachingService(),
+		Carpools:         services.NewCarpoolService(notificationService),
+		Inventory:        services.NewInventoryService(notificationService),
+		Cards:            cardService,
+		Messages:         services.NewMessageCatalogService(),
+		Share:            shareService,
+		Search:           services.NewSearchService(),
+		WeatherProposals: weatherProposalService,
+		Committee:        services.NewCommitteeService(),
+		InboundEmail:     inboundEmailService,
+	}, handlers.APIConfig{
+		UserInfo:           handlers.Auth0UserInfo(cfg.Auth0Domain),
+		FrontendURL:        cfg.FrontendURL,
+		AnnouncementLimit:  cfg.AnnouncementLimit,
+		AnnouncementWindow: time.Duration(cfg.AnnouncementWindowHours) * time.Hour,
+		Live:               liveConfig,
+	}, handlers.Middleware{
+		Token:            middleware.TokenMiddleware(auth0Config),
+		Authenticate:     middleware.AuthMiddleware(auth0Config),
+		AdminIPAllowlist: adminIPAllowlist,
+		RecentMFA:        middleware.RequireRecentMFA(time.Duration(cfg.AdminMFAMaxAgeMinutes) * time.Minute),
+
+		// Shared across API versions so the limit can't be doubled by switching prefix
+		AuthCallbackLimit: middleware.RateLimitByIP(authCallbackRate, time.Minute),
+		AccountLinkLimit:  middleware.RateLimitByIP(accountLinkRate, time.Minute),
+		// Per login as well, so one account can't be hammered from many addresses
+		AuthCallbackAccountLimit: middleware.RateLimitByAccount(authCallbackAccountRate, time.Minute),
+		AccountLinkAccountLimit:  middleware.RateLimitByAccount(accountLinkAccountRate, time.Minute),
+		InvitePreviewLimit:       middleware.RateLimitByIP(invitePreviewRate, time.Minute),
+	})
 
-				// Session management
-				admin.POST("/sessions", adminHandler.CreateSession)
-				admin.PUT("/sessions/:id", adminHandler.UpdateSession)
-				admin.DELETE("/sessions/:id", adminHandler.DeleteSession)
-				admin.POST("/sessions/:id/cancel", adminHandler.CancelSession)
+	// Share pages and the inbound email webhook
+	api.RegisterPages(r)
 
-				// Admin RSVP management
-				admin.POST("/sessions/:id/rsvp/:userId", adminHandler.AddPlayerRSVP)
+	// Presigned links to files kept on local disk
+	if localStore, ok := documentStore.(*storage.LocalStore); ok {
+		r.GET(storage.LocalFilesPath+"*key", handlers.NewFileHandler(localStore).ServeFile)
+	}
 
-				// Club management
-				admin.PUT("/club", adminHandler.UpdateClub)
+	// /api/v1, /api/v2, ... pin the version; plain /api negotiates it from the
+	// API-Version header and defaults to v1 for the existing PWA
+	for _, version := range middleware.SupportedAPIVersions {
+		versioned := r.Group("/api/" + version)
+		versioned.Use(middleware.APIVersion(version))
+		api.Register(versioned)
+	}
+	unversioned := r.Group("/api")
+	unversioned.Use(middleware.NegotiateAPIVersion())
+	api.Register(unversioned)
 
-				// Announcements
-				admin.POST("/announcements", notificationHandler.SendAnnouncement)
+	// Reload config on SIGHUP
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := liveConfig.Apply(config.LoadReloadable()); err != nil {
+				log.Printf("Config reload rejected: %v", err)
+			} else {
+				log.Println("Config reloaded")
 			}
 		}
+	}()
+
+	// Kiosk gRPC API, on its own port so it can sit behind the venue network
+	var kioskServer *grpc.Server
+	if cfg.KioskGRPCPort != "" {
+		creds, err := kiosk.ServerCredentials(cfg.KioskTLSCert, cfg.KioskTLSKey, cfg.KioskClientCAFile)
+		if err != nil {
+			log.Fatal("Failed to set up kiosk TLS:", err)
+		}
+		listener, err := net.Listen("tcp", ":"+cfg.KioskGRPCPort)
+		if err != nil {
+			log.Fatal("Failed to listen for kiosk API:", err)
+		}
+		kioskServer = kiosk.NewGRPCServer(creds, kiosk.NewServer(sessionService, rsvpService, gameService, cardService))
+		go func() {
+			log.Printf("Kiosk API starting on port %s", cfg.KioskGRPCPort)
+			if err := kioskServer.Serve(listener); err != nil {
+				log.Fatal("Failed to serve kiosk API:", err)
+			}
+		}()
 	}
 
 	// Handle graceful shutdown
@@ -174,9 +382,20 @@ func main() {
 	<-quit
 	log.Println("Shutting down server...")
 
-	// Stop scheduler if running
-	if scheduler != nil {
-		scheduler.Stop()
+	// Stop scheduler
+	scheduler.Stop()
+
+	// Save the last API usage counts
+	usageService.Stop()
+
+	// Stop kiosk API
+	if kioskServer != nil {
+		kioskServer.GracefulStop()
+	}
+
+	// Stop secret refresh
+	if cfg.Secrets != nil {
+		cfg.Secrets.Stop()
 	}
 
 	log.Println("Server stopped")
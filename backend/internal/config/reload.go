@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/joho/godotenv"
+)
+
+// Reloadable holds the settings that can be changed without restarting the server
+type Reloadable struct {
+	SessionReminderHours24 int        `json:"session_reminder_hours_24"`
+	SessionReminderHours12 int        `json:"session_reminder_hours_12"`
+	DeadlineReminderHours  int        `json:"deadline_reminder_hours"`
+	CORSOrigins            []string   `json:"cors_origins"`
+	BannedWords            []string   `json:"banned_words"`
+	RateLimits             RateLimits `json:"rate_limits"`
+	DisabledFeatures       []string   `json:"disabled_features"`
+}
+
+// RateLimits are the requests allowed per minute on endpoints that could be
+// used to guess links, codes or accounts. Each has a limit per client IP and,
+// for signed-in calls, one per login.
+type RateLimits struct {
+	AuthCallback        int `json:"auth_callback"`
+	AuthCallbackAccount int `json:"auth_callback_account"`
+	AccountLink         int `json:"account_link"`
+	AccountLinkAccount  int `json:"account_link_account"`
+	InvitePreview       int `json:"invite_preview"`
+}
+
+func loadRateLimits() RateLimits {
+	return RateLimits{
+		AuthCallback:        getEnvInt("RATE_LIMIT_AUTH_CALLBACK", 20),
+		AuthCallbackAccount: getEnvInt("RATE_LIMIT_AUTH_CALLBACK_ACCOUNT", 10),
+		AccountLink:         getEnvInt("RATE_LIMIT_ACCOUNT_LINK", 10),
+		AccountLinkAccount:  getEnvInt("RATE_LIMIT_ACCOUNT_LINK_ACCOUNT", 5),
+		InvitePreview:       getEnvInt("RATE_LIMIT_INVITE_PREVIEW", 30),
+	}
+}
+
+// Features are the parts of the app a club can turn off with DISABLED_FEATURES.
+// Their routes answer 404 while off.
+var Features = []string{"carpools", "coaching", "orders", "tournaments"}
+
+// Validate checks that the settings are safe to apply
+func (r Reloadable) Validate() error {
+	if r.SessionReminderHours24 <= 0 || r.SessionReminderHours12 <= 0 || r.DeadlineReminderHours <= 0 {
+		return errors.New("reminder hours must be positive")
+	}
+	if r.SessionReminderHours12 >= r.SessionReminderHours24 {
+		return errors.New("second session reminder must be closer to the session than the first")
+	}
+	if len(r.CORSOrigins) == 0 {
+		return errors.New("at least one CORS origin is required")
+	}
+	for _, origin := range r.CORSOrigins {
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid CORS origin %q", origin)
+		}
+	}
+	limits := r.RateLimits
+	if limits.AuthCallback <= 0 || limits.AuthCallbackAccount <= 0 || limits.AccountLink <= 0 ||
+		limits.AccountLinkAccount <= 0 || limits.InvitePreview <= 0 {
+		return errors.New("rate limits must be positive")
+	}
+	for _, feature := range r.DisabledFeatures {
+		if !slices.Contains(Features, feature) {
+			return fmt.Errorf("unknown feature %q", feature)
+		}
+	}
+	return nil
+}
+
+// Reloadable returns the hot-reloadable subset of the config
+func (cfg *Config) Reloadable() Reloadable {
+	return Reloadable{
+		SessionReminderHours24: cfg.SessionReminderHours24,
+		SessionReminderHours12: cfg.SessionReminderHours12,
+		DeadlineReminderHours:  cfg.DeadlineReminderHours,
+		CORSOrigins:            cfg.CORSOrigins,
+		BannedWords:            cfg.BannedWords,
+		RateLimits:             cfg.RateLimits,
+		DisabledFeatures:       cfg.DisabledFeatures,
+	}
+}
+
+// LoadReloadable re-reads the reloadable settings, letting values in .env
+// override the process environment so edits to the file take effect
+func LoadReloadable() Reloadable {
+	godotenv.Overload()
+	return Reloadable{
+		SessionReminderHours24: getEnvInt("SESSION_REMINDER_HOURS_24", 24),
+		SessionReminderHours12: getEnvInt("SESSION_REMINDER_HOURS_12", 12),
+		DeadlineReminderHours:  getEnvInt("DEADLINE_REMINDER_HOURS", 6),
+		CORSOrigins:            corsOrigins(),
+		BannedWords:            getEnvList("MODERATION_BANNED_WORDS"),
+		RateLimits:             loadRateLimits(),
+		DisabledFeatures:       getEnvList("DISABLED_FEATURES"),
+	}
+}
+
+// Live holds the currently applied reloadable settings and notifies
+// subscribers when they change
+type Live struct {
+	mu          sync.RWMutex
+	current     Reloadable
+	subscribers []func(Reloadable)
+}
+
+func NewLive(initial Reloadable) *Live {
+	return &Live{current: initial}
+}
+
+// Current returns the applied settings
+func (l *Live) Current() Reloadable {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.current
+}
+
+// OnChange registers a function called with the new settings after each reload
+func (l *Live) OnChange(fn func(Reloadable)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.subscribers = append(l.subscribers, fn)
+}
+
+// Apply validates and applies new settings. Invalid settings are rejected and
+// the current ones stay in effect.
+func (l *Live) Apply(r Reloadable) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.current = r
+	subscribers := append([]func(Reloadable){}, l.subscribers...)
+	l.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(r)
+	}
+	return nil
+}
+
+// AllowsOrigin reports whether an origin is in the applied CORS list
+func (l *Live) AllowsOrigin(origin string) bool {
+	for _, o := range l.Current().CORSOrigins {
+		if o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// FeatureEnabled reports whether feature is on in the applied settings
+func (l *Live) FeatureEnabled(feature string) bool {
+	return !slices.Contains(l.Current().DisabledFeatures, feature)
+}
@@ -27,21 +27,161 @@ func Connect(databaseURL string) error {
 func Migrate() error {
 	log.Println("Running database migrations...")
 
+	if err := migrateSessionTimes(); err != nil {
+		return err
+	}
+	hadWaitlist := DB.Migrator().HasTable(&models.WaitlistEntry{})
+	hadAttendance := DB.Migrator().HasTable(&models.Attendance{})
+	hadRSVPCounts := DB.Migrator().HasColumn(&models.Session{}, "confirmed_count")
+	hadTypePreferences := DB.Migrator().HasTable(&models.NotificationTypePreference{})
+
 	err := DB.AutoMigrate(
 		&models.Club{},
 		&models.User{},
 		&models.Session{},
 		&models.RSVP{},
+		&models.ArchivedRSVP{},
+		&models.GuestRSVP{},
 		// Notification models
 		&models.UserNotificationPreferences{},
+		&models.NotificationTypePreference{},
 		&models.UserPushToken{},
 		&models.Notification{},
+		&models.ArchivedNotification{},
 		&models.Announcement{},
+		&models.AnnouncementAcknowledgement{},
+		&models.UserBadge{},
+		// Tournament models
+		&models.Tournament{},
+		&models.TournamentEntry{},
+		&models.TournamentMatch{},
+		// Casual game models
+		&models.Game{},
+		&models.GamePlayer{},
+		&models.PlayerRating{},
+		&models.PeerRating{},
+		&models.CourtAssignment{},
+		&models.Comment{},
+		&models.CommentReport{},
+		&models.AvatarReport{},
+		&models.SessionCommentSetting{},
+		&models.AuditLog{},
+		&models.Tombstone{},
+		&models.Document{},
+		&models.DocumentAcknowledgement{},
+		&models.Incident{},
+		&models.IncidentAttachment{},
+		&models.PendingAction{},
+		&models.AllocationResult{},
+		&models.JobRun{},
+		&models.PendingSessionChange{},
+		&models.DomainEvent{},
+		&models.OutboxEvent{},
+		&models.AccountLinkCode{},
+		&models.MessageTemplate{},
+		// Group orders
+		&models.OrderWindow{},
+		&models.OrderItem{},
+		&models.Order{},
+		&models.OrderLine{},
+		// Training program
+		&models.TrainingProgress{},
+		&models.TrainingAttendance{},
+		&models.SkillAssessment{},
+		// Carpools
+		&models.CarpoolOffer{},
+		&models.CarpoolRequest{},
+		// Equipment inventory
+		&models.InventoryItem{},
+		&models.InventoryCheckout{},
+		&models.InventoryMovement{},
+		&models.JoinRules{},
+		&models.JoinApproval{},
+		&models.ReferralCode{},
+		&models.PreapprovedMember{},
+		&models.Invite{},
+		&models.InviteUse{},
+		&models.RSVPEvent{},
+		&models.SessionAttachment{},
+		&models.SeriesRegular{},
+		&models.WaitlistEntry{},
+		&models.Attendance{},
+		&models.CancellationProposal{},
+		&models.CommitteeMinutes{},
+		&models.SeasonRollover{},
+		&models.BlackoutDate{},
+		&models.ContactLog{},
+		&models.APIUsage{},
 	)
 	if err != nil {
 		return err
 	}
 
+	if !hadWaitlist {
+		if err := migrateWaitlist(); err != nil {
+			return err
+		}
+	}
+	if !hadAttendance {
+		if err := migrateCheckIns(); err != nil {
+			return err
+		}
+	}
+
+	if !hadRSVPCounts {
+		if err := migrateRSVPCounts(); err != nil {
+			return err
+		}
+	}
+
+	if !hadTypePreferences {
+		if err := migrateNotificationTypePreferences(); err != nil {
+			return err
+		}
+	}
+
+	// Sessions were limited to 3 courts; the bound now lives in SessionService
+	// (models.MaxCourts) and the column only has to be positive
+	if err := DB.Exec(`ALTER TABLE sessions DROP CONSTRAINT IF EXISTS chk_sessions_courts`).Error; err != nil {
+		return err
+	}
+
+	// Message wording is kept per language, so the key alone is no longer unique
+	if err := DB.Exec(`DROP INDEX IF EXISTS idx_message_templates_key`).Error; err != nil {
+		return err
+	}
+
+	// Full-text search over notification history. A generated column keeps
+	// the vector in step with title and body without application code.
+	if err := DB.Exec(`ALTER TABLE notifications ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (to_tsvector('english', coalesce(title, '') || ' ' || coalesce(body, ''))) STORED`).Error; err != nil {
+		return err
+	}
+	if err := DB.Exec(`CREATE INDEX IF NOT EXISTS idx_notifications_search ON notifications USING gin (search_vector)`).Error; err != nil {
+		return err
+	}
+
+	// Site search (services.SearchService) over member names, session titles
+	// and descriptions, and announcements. Names use the simple configuration
+	// so they aren't stemmed; titles weigh more than the text under them.
+	for _, stmt := range []string{
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (to_tsvector('simple', coalesce(name, ''))) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_users_search ON users USING gin (search_vector)`,
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+				setweight(to_tsvector('english', coalesce(description, '')), 'B')) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_search ON sessions USING gin (search_vector)`,
+		`ALTER TABLE announcements ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+				setweight(to_tsvector('english', coalesce(body, '')), 'B')) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_announcements_search ON announcements USING gin (search_vector)`,
+	} {
+		if err := DB.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
 	// Seed default club if not exists
 	var count int64
 	DB.Model(&models.Club{}).Count(&count)
@@ -56,3 +196,138 @@ func Migrate() error {
 	log.Println("Database migrations completed")
 	return nil
 }
+
+// migrateSessionTimes replaces sessions' "HH:MM" start_time and end_time
+// columns with starts_at and ends_at timestamps, read as Sydney wall-clock
+// times on the session's date. Sessions ending at or before their start time
+// finish after midnight. It runs before AutoMigrate, which can't add the
+// columns as NOT NULL to a table that already has rows.
+func migrateSessionTimes() error {
+	if !DB.Migrator().HasColumn("sessions", "start_time") {
+		return nil
+	}
+	log.Println("Moving session start and end times to timestamps...")
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range []string{
+			`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS starts_at timestamptz`,
+			`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS ends_at timestamptz`,
+			`UPDATE sessions SET
+				starts_at = (session_date + start_time::time) AT TIME ZONE 'Australia/Sydney',
+				ends_at = (session_date + end_time::time
+					+ CASE WHEN end_time::time <= start_time::time THEN interval '1 day' ELSE interval '0' END)
+					AT TIME ZONE 'Australia/Sydney'`,
+			`ALTER TABLE sessions ALTER COLUMN starts_at SET NOT NULL`,
+			`ALTER TABLE sessions ALTER COLUMN ends_at SET NOT NULL`,
+			`ALTER TABLE sessions DROP COLUMN start_time`,
+			`ALTER TABLE sessions DROP COLUMN end_time`,
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateWaitlist moves the waitlists of upcoming sessions, which were the IN
+// RSVPs past a session's capacity, to waitlisted RSVPs with waitlist entries
+// in the same order. It runs once, when the waitlist table is created.
+func migrateWaitlist() error {
+	log.Println("Moving session waitlists to waitlist entries...")
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`UPDATE rsvps SET status = ?, updated_at = now()
+			FROM (
+				SELECT rsvps.id, sessions.max_players,
+					ROW_NUMBER() OVER (PARTITION BY rsvps.session_id ORDER BY rsvps.rsvp_timestamp) AS n
+				FROM rsvps JOIN sessions ON sessions.id = rsvps.session_id
+				WHERE rsvps.status = ? AND sessions.status = ? AND sessions.starts_at > now()
+			) ranked
+			WHERE rsvps.id = ranked.id AND ranked.n > ranked.max_players`,
+			models.RSVPStatusWaitlisted, models.RSVPStatusIn, models.SessionStatusOpen).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO waitlist_entries (id, session_id, user_id, position, created_at)
+			SELECT gen_random_uuid(), session_id, user_id,
+				ROW_NUMBER() OVER (PARTITION BY session_id ORDER BY rsvp_timestamp), now()
+			FROM rsvps WHERE status = ?`, models.RSVPStatusWaitlisted).Error
+	})
+}
+
+// migrateCheckIns records the check-ins made before attendance was tracked
+// as attendance. It runs once, when the attendance table is created.
+func migrateCheckIns() error {
+	return DB.Exec(`INSERT INTO attendances (id, session_id, user_id, status, checked_in_at, created_at, updated_at)
+		SELECT gen_random_uuid(), session_id, user_id, ?, checked_in_at, checked_in_at, checked_in_at
+		FROM rsvps WHERE checked_in_at IS NOT NULL`, models.AttendancePresent).Error
+}
+
+// migrateRSVPCounts fills in the RSVP counters of existing sessions. It runs
+// once, when the counters are added; the rsvp_counts job keeps them right
+// after that.
+func migrateRSVPCounts() error {
+	log.Println("Counting session RSVPs...")
+
+	return DB.Exec(`UPDATE sessions SET
+			confirmed_count = c.confirmed_count,
+			maybe_count = c.maybe_count,
+			out_count = c.out_count,
+			requested_count = c.requested_count,
+			waitlist_count = c.waitlist_count,
+			guest_count = c.guest_count
+		FROM (?) c
+		WHERE sessions.id = c.session_id`, models.RSVPTallies(DB)).Error
+}
+
+// legacyPreferenceColumns are the per-type columns user_notification_preferences
+// had before choices moved to notification_type_preferences, with the
+// default each column had
+var legacyPreferenceColumns = []struct {
+	column   string
+	typ      models.NotificationType
+	channel  models.NotificationChannel
+	fallback bool
+}{
+	{"push_session_reminders", models.NotificationSessionReminder, models.ChannelPush, true},
+	{"push_rsvp_deadlines", models.NotificationRSVPDeadline, models.ChannelPush, true},
+	{"push_waitlist_updates", models.NotificationWaitlistUpdate, models.ChannelPush, true},
+	{"push_admin_announcements", models.NotificationAdminAnnouncement, models.ChannelPush, true},
+	{"push_badge_awards", models.NotificationBadgeAwarded, models.ChannelPush, false},
+	{"push_court_assignments", models.NotificationCourtAssignment, models.ChannelPush, true},
+	{"email_session_reminders", models.NotificationSessionReminder, models.ChannelEmail, true},
+	{"email_rsvp_deadlines", models.NotificationRSVPDeadline, models.ChannelEmail, true},
+	{"email_waitlist_updates", models.NotificationWaitlistUpdate, models.ChannelEmail, true},
+	{"email_admin_announcements", models.NotificationAdminAnnouncement, models.ChannelEmail, true},
+	{"email_badge_awards", models.NotificationBadgeAwarded, models.ChannelEmail, false},
+}
+
+// migrateNotificationTypePreferences copies members' choices out of the old
+// per-type preference columns into notification_type_preferences. Only
+// values that differ from the column's default are copied, so everyone else
+// follows models.NotificationDefaults. The columns are no longer read or
+// written; they stay until clients of the v1 preference fields are gone, so
+// a rollback still finds them.
+func migrateNotificationTypePreferences() error {
+	if !DB.Migrator().HasColumn("user_notification_preferences", "push_session_reminders") {
+		return nil
+	}
+	log.Println("Moving notification preferences to per-type rows...")
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		for _, c := range legacyPreferenceColumns {
+			if !tx.Migrator().HasColumn("user_notification_preferences", c.column) {
+				continue
+			}
+			err := tx.Exec(`INSERT INTO notification_type_preferences (id, user_id, type, channel, enabled, created_at, updated_at)
+				SELECT gen_random_uuid(), user_id, ?, ?, `+c.column+`, now(), now()
+				FROM user_notification_preferences WHERE `+c.column+` <> ?
+				ON CONFLICT (user_id, type, channel) DO NOTHING`, c.typ, c.channel, c.fallback).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
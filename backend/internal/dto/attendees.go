@@ -0,0 +1,183 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+// joiningStatuses are the RSVPs that count as taking part for
+// models.AttendeeAfterRSVP; saying OUT doesn't reveal who's coming
+var joiningStatuses = []models.RSVPStatus{models.RSVPStatusIn, models.RSVPStatusWaitlisted, models.RSVPStatusMaybe, models.RSVPStatusRequested}
+
+// AttendeeLookup reads what the attendee filter needs: the club's attendee
+// visibility, which of sessionIDs a member is taking part in, and who runs
+// each session
+type AttendeeLookup interface {
+	AttendeeVisibility() (models.AttendeeVisibility, error)
+	JoinedSessions(userID uuid.UUID, sessionIDs []uuid.UUID) ([]uuid.UUID, error)
+	SessionStaff(sessionIDs []uuid.UUID) ([]models.Session, error)
+}
+
+// Attendees is where the attendee filter reads from: the database, unless
+// swapped out as the mock server does for its fixtures
+var Attendees AttendeeLookup = databaseAttendees{}
+
+type databaseAttendees struct{}
+
+func (databaseAttendees) AttendeeVisibility() (models.AttendeeVisibility, error) {
+	var club models.Club
+	err := database.DB.Select("attendee_visibility").First(&club).Error
+	return club.AttendeeVisibility, err
+}
+
+func (databaseAttendees) JoinedSessions(userID uuid.UUID, sessionIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var joined []uuid.UUID
+	err := database.DB.Model(&models.RSVP{}).
+		Where("user_id = ? AND session_id IN ? AND status IN ?", userID, sessionIDs, joiningStatuses).
+		Pluck("session_id", &joined).Error
+	return joined, err
+}
+
+// SessionStaff loads just the creator and coach of each session
+func (databaseAttendees) SessionStaff(sessionIDs []uuid.UUID) ([]models.Session, error) {
+	var sessions []models.Session
+	err := database.DB.Select("id, created_by, coach_id").Where("id IN ?", sessionIDs).Find(&sessions).Error
+	return sessions, err
+}
+
+// attendeeFilter decides, under the club's attendee visibility, whose RSVPs
+// a viewer sees in each session. Where they can't see everyone they still
+// see their own RSVP, and the counts in the RSVP summary are unaffected.
+type attendeeFilter struct {
+	viewer  *models.User
+	mode    models.AttendeeVisibility
+	joined  map[uuid.UUID]bool // sessions the viewer is taking part in
+	seesAll bool
+}
+
+// newAttendeeFilter loads what's needed to filter the RSVPs of sessionIDs
+// for viewer. Nothing is loaded for admins or when names are shown anyway.
+func newAttendeeFilter(viewer *models.User, sessionIDs []uuid.UUID) *attendeeFilter {
+	f := &attendeeFilter{viewer: viewer, mode: models.AttendeeNames}
+	if VisibilityFor(viewer) == VisibilityAdmin {
+		f.seesAll = true
+		return f
+	}
+
+	if mode, err := Attendees.AttendeeVisibility(); err == nil && mode.IsValid() {
+		f.mode = mode
+	}
+	if f.mode == models.AttendeeNames {
+		f.seesAll = true
+		return f
+	}
+
+	if f.mode == models.AttendeeAfterRSVP && viewer != nil && len(sessionIDs) > 0 {
+		joined, _ := Attendees.JoinedSessions(viewer.ID, sessionIDs)
+		f.joined = make(map[uuid.UUID]bool, len(joined))
+		for _, id := range joined {
+			f.joined[id] = true
+		}
+	}
+	return f
+}
+
+// seesAttendees reports whether the viewer sees everyone's RSVP to s
+func (f *attendeeFilter) seesAttendees(s *models.Session) bool {
+	if f.seesAll {
+		return true
+	}
+	if f.viewer == nil {
+		return false
+	}
+	if s.CreatedBy == f.viewer.ID || (s.CoachID != nil && *s.CoachID == f.viewer.ID) {
+		return true
+	}
+	return f.mode == models.AttendeeAfterRSVP && f.joined[s.ID]
+}
+
+// visible returns the RSVPs the viewer sees: all of them, or only their own
+func (f *attendeeFilter) visible(s *models.Session, rsvps []models.RSVP) []models.RSVP {
+	if f.seesAttendees(s) {
+		return rsvps
+	}
+	var own []models.RSVP
+	for _, r := range rsvps {
+		if f.viewer != nil && r.UserID == f.viewer.ID {
+			own = append(own, r)
+		}
+	}
+	return own
+}
+
+// SeesAttendees reports whether viewer may see who is coming to s, such as
+// the names on its waitlist
+func SeesAttendees(s *models.Session, viewer *models.User) bool {
+	return newAttendeeFilter(viewer, []uuid.UUID{s.ID}).seesAttendees(s)
+}
+
+// SessionAttendance serializes the attendance at each of sessions, in order,
+// as seen by viewer: everyone's where they can see who's coming, otherwise
+// only their own
+func SessionAttendance(sessions []models.Session, records map[uuid.UUID][]models.Attendance, viewer *models.User) [][]AttendanceResponse {
+	result := make([][]AttendanceResponse, len(sessions))
+	if !CanSeeMembers(viewer) {
+		for i := range result {
+			result[i] = []AttendanceResponse{}
+		}
+		return result
+	}
+
+	ids := make([]uuid.UUID, len(sessions))
+	for i := range sessions {
+		ids[i] = sessions[i].ID
+	}
+	attendees := newAttendeeFilter(viewer, ids)
+	for i := range sessions {
+		s := &sessions[i]
+		visible := records[s.ID]
+		if !attendees.seesAttendees(s) {
+			visible = nil
+			for _, a := range records[s.ID] {
+				if a.UserID == viewer.ID {
+					visible = append(visible, a)
+				}
+			}
+		}
+		result[i] = Attendance(visible, viewer)
+	}
+	return result
+}
+
+// AttendeeRSVPs serializes RSVPs across sessions, such as a sync delta,
+// leaving out other members' RSVPs to sessions where viewer can't see who's
+// coming
+func AttendeeRSVPs(rsvps []models.RSVP, viewer *models.User) []RSVPResponse {
+	ids := make([]uuid.UUID, 0, len(rsvps))
+	seen := make(map[uuid.UUID]bool)
+	for _, r := range rsvps {
+		if !seen[r.SessionID] {
+			seen[r.SessionID] = true
+			ids = append(ids, r.SessionID)
+		}
+	}
+	attendees := newAttendeeFilter(viewer, ids)
+	if attendees.seesAll {
+		return RSVPs(rsvps, viewer)
+	}
+
+	sessions, _ := Attendees.SessionStaff(ids)
+	sees := make(map[uuid.UUID]bool, len(sessions))
+	for i := range sessions {
+		sees[sessions[i].ID] = attendees.seesAttendees(&sessions[i])
+	}
+
+	visible := make([]models.RSVP, 0, len(rsvps))
+	for _, r := range rsvps {
+		if sees[r.SessionID] || (viewer != nil && r.UserID == viewer.ID) {
+			visible = append(visible, r)
+		}
+	}
+	return RSVPs(visible, viewer)
+}
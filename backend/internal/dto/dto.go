@@ -0,0 +1,323 @@
+// Package dto builds API responses from models, filtering fields by who is
+// looking (see Visibility). Members see what they need to organise games;
+// contact details and identity-provider IDs are limited to the user
+// themselves and admins, and guests see no other members at all. Members can
+// also keep themselves off waitlists, leaderboards and attendance stats that
+// other members see (see models.PrivacySettings).
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+// canSeePrivate reports whether viewer may see subject's private fields
+func canSeePrivate(viewer *models.User, subjectID uuid.UUID) bool {
+	return VisibilityFor(viewer) == VisibilityAdmin || (viewer != nil && viewer.ID == subjectID)
+}
+
+type UserResponse struct {
+	ID               uuid.UUID               `json:"id"`
+	Name             string                  `json:"name"`
+	ProfilePicture   string                  `json:"profile_picture"`
+	AvatarURL        string                  `json:"avatar_url"` // uploaded photo, Auth0 picture or initials
+	Role             models.UserRole         `json:"role"`
+	IsPlayer         bool                    `json:"is_player"`
+	MembershipStatus models.MembershipStatus `json:"membership_status"`
+	CommitteeRole    models.CommitteeRole    `json:"committee_role,omitempty"`
+	Badges           []models.UserBadge      `json:"badges,omitempty"`
+
+	// Self and admins only
+	Email       string                `json:"email,omitempty"`
+	PhoneNumber string                `json:"phone_number,omitempty"`
+	Auth0ID     string                `json:"auth0_id,omitempty"`
+	Tier        models.MembershipTier `json:"tier,omitempty"`
+	CreatedAt   *time.Time            `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time            `json:"updated_at,omitempty"`
+
+	// Privacy choices, also self and admins only
+	Privacy *models.PrivacySettings `json:"privacy,omitempty"`
+
+	// Notification language, also self and admins only
+	PreferredLanguage models.Language `json:"preferred_language,omitempty"`
+
+	// Inactivity, also self and admins only
+	InactiveNotifiedAt *time.Time `json:"inactive_notified_at,omitempty"`
+	ArchivedAt         *time.Time `json:"archived_at,omitempty"`
+}
+
+// User serializes a user as seen by viewer
+func User(u *models.User, viewer *models.User) *UserResponse {
+	if u == nil {
+		return nil
+	}
+	r := &UserResponse{
+		ID:               u.ID,
+		Name:             u.Name,
+		ProfilePicture:   u.ProfilePicture,
+		AvatarURL:        services.AvatarURL(u, models.AvatarMedium),
+		Role:             u.Role,
+		IsPlayer:         u.IsPlayer,
+		MembershipStatus: u.MembershipStatus,
+		CommitteeRole:    u.CommitteeRole,
+		Badges:           visibleBadges(u, viewer),
+	}
+	if canSeePrivate(viewer, u.ID) {
+		r.Email = u.Email
+		r.PhoneNumber = u.PhoneNumber
+		r.Auth0ID = u.Auth0ID
+		r.Tier = u.Tier
+		r.CreatedAt = &u.CreatedAt
+		r.UpdatedAt = &u.UpdatedAt
+		r.Privacy = &u.Privacy
+		r.PreferredLanguage = u.PreferredLanguage
+		r.InactiveNotifiedAt = u.InactiveNotifiedAt
+		r.ArchivedAt = u.ArchivedAt
+	}
+	return r
+}
+
+// Users serializes a list of users as seen by viewer
+func Users(users []models.User, viewer *models.User) []UserResponse {
+	result := make([]UserResponse, len(users))
+	for i := range users {
+		result[i] = *User(&users[i], viewer)
+	}
+	return result
+}
+
+type RSVPResponse struct {
+	ID            uuid.UUID         `json:"id"`
+	SessionID     uuid.UUID         `json:"session_id"`
+	UserID        uuid.UUID         `json:"user_id"`
+	Status        models.RSVPStatus `json:"status"`
+	RSVPTimestamp time.Time         `json:"rsvp_timestamp"`
+	IsLateRSVP    bool              `json:"is_late_rsvp"`
+	AddedByAdmin  bool              `json:"added_by_admin"`
+	AsRegular     bool              `json:"as_regular"`
+	CheckedInAt   *time.Time        `json:"checked_in_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	User          *UserResponse     `json:"user,omitempty"`
+}
+
+// RSVP serializes an RSVP as seen by viewer
+func RSVP(r *models.RSVP, viewer *models.User) *RSVPResponse {
+	if r == nil {
+		return nil
+	}
+	checkedInAt := r.CheckedInAt
+	if hides(r.User, viewer, hideAttendance) {
+		checkedInAt = nil
+	}
+	return &RSVPResponse{
+		ID:            r.ID,
+		SessionID:     r.SessionID,
+		UserID:        r.UserID,
+		Status:        r.Status,
+		RSVPTimestamp: r.RSVPTimestamp,
+		IsLateRSVP:    r.IsLateRSVP,
+		AddedByAdmin:  r.AddedByAdmin,
+		AsRegular:     r.AsRegular,
+		CheckedInAt:   checkedInAt,
+		CreatedAt:     r.CreatedAt,
+		UpdatedAt:     r.UpdatedAt,
+		User:          User(r.User, viewer),
+	}
+}
+
+// RSVPs serializes a list of RSVPs as seen by viewer
+func RSVPs(rsvps []models.RSVP, viewer *models.User) []RSVPResponse {
+	result := make([]RSVPResponse, len(rsvps))
+	for i := range rsvps {
+		result[i] = *RSVP(&rsvps[i], viewer)
+	}
+	return result
+}
+
+type AttendanceResponse struct {
+	ID          uuid.UUID               `json:"id"`
+	SessionID   uuid.UUID               `json:"session_id"`
+	UserID      uuid.UUID               `json:"user_id"`
+	Status      models.AttendanceStatus `json:"status"`
+	CheckedInAt *time.Time              `json:"checked_in_at,omitempty"`
+	RecordedBy  *uuid.UUID              `json:"recorded_by,omitempty"`
+	Note        string                  `json:"note,omitempty"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+	User        *UserResponse           `json:"user,omitempty"`
+}
+
+// Attendance serializes attendance records as seen by viewer, leaving out
+// members who hide their attendance from them
+func Attendance(records []models.Attendance, viewer *models.User) []AttendanceResponse {
+	result := make([]AttendanceResponse, 0, len(records))
+	for _, a := range records {
+		if hides(a.User, viewer, hideAttendance) {
+			continue
+		}
+		result = append(result, AttendanceResponse{
+			ID:          a.ID,
+			SessionID:   a.SessionID,
+			UserID:      a.UserID,
+			Status:      a.Status,
+			CheckedInAt: a.CheckedInAt,
+			RecordedBy:  a.RecordedBy,
+			Note:        a.Note,
+			UpdatedAt:   a.UpdatedAt,
+			User:        User(a.User, viewer),
+		})
+	}
+	return result
+}
+
+type GuestResponse struct {
+	ID        uuid.UUID     `json:"id"`
+	SessionID uuid.UUID     `json:"session_id"`
+	Name      string        `json:"name"`
+	InvitedBy uuid.UUID     `json:"invited_by"`
+	CreatedAt time.Time     `json:"created_at"`
+	Inviter   *UserResponse `json:"inviter,omitempty"`
+}
+
+// Guest serializes a guest as seen by viewer
+func Guest(g *models.GuestRSVP, viewer *models.User) *GuestResponse {
+	if g == nil {
+		return nil
+	}
+	return &GuestResponse{
+		ID:        g.ID,
+		SessionID: g.SessionID,
+		Name:      g.Name,
+		InvitedBy: g.InvitedBy,
+		CreatedAt: g.CreatedAt,
+		Inviter:   User(g.Inviter, viewer),
+	}
+}
+
+// Guests serializes a session's guests as seen by viewer
+func Guests(guests []models.GuestRSVP, viewer *models.User) []GuestResponse {
+	result := make([]GuestResponse, len(guests))
+	for i := range guests {
+		result[i] = *Guest(&guests[i], viewer)
+	}
+	return result
+}
+
+type SessionResponse struct {
+	ID                 uuid.UUID                  `json:"id"`
+	Title              string                     `json:"title"`
+	Description        string                     `json:"description"`
+	SessionDate        time.Time                  `json:"session_date"`
+	StartsAt           time.Time                  `json:"starts_at"`
+	EndsAt             time.Time                  `json:"ends_at"`
+	StartTime          string                     `json:"start_time"` // HH:MM in Sydney
+	EndTime            string                     `json:"end_time"`   // HH:MM in Sydney
+	Courts             int                        `json:"courts"`
+	MaxPlayers         int                        `json:"max_players"`
+	Overbooking        int                        `json:"overbooking"`
+	RSVPDeadline       time.Time                  `json:"rsvp_deadline"`
+	IsRecurring        bool                       `json:"is_recurring"`
+	RecurringDayOfWeek *int                       `json:"recurring_day_of_week"`
+	RecurringParentID  *uuid.UUID                 `json:"recurring_parent_id"`
+	Status             models.SessionStatus       `json:"status"`
+	IsOutdoor          bool                       `json:"is_outdoor"`
+	RequiresApproval   bool                       `json:"requires_approval"`
+	FairShare          bool                       `json:"fair_share"`
+	AllowGuests        bool                       `json:"allow_guests"`
+	SessionType        models.SessionType         `json:"session_type"`
+	CoachID            *uuid.UUID                 `json:"coach_id,omitempty"`
+	CurriculumNotes    string                     `json:"curriculum_notes,omitempty"`
+	AllocatedAt        *time.Time                 `json:"allocated_at,omitempty"`
+	CancellationReason string                     `json:"cancellation_reason,omitempty"`
+	CreatedBy          uuid.UUID                  `json:"created_by"`
+	CreatedAt          time.Time                  `json:"created_at"`
+	UpdatedAt          time.Time                  `json:"updated_at"`
+	RSVPs              []RSVPResponse             `json:"rsvps,omitempty"`
+	Creator            *UserResponse              `json:"creator,omitempty"`
+	Coach              *UserResponse              `json:"coach,omitempty"`
+	Attachments        []models.SessionAttachment `json:"attachments,omitempty"` // members only
+	ShuttlesUsed       *int                       `json:"shuttles_used,omitempty"`
+	ActualStartAt      *time.Time                 `json:"actual_start_at,omitempty"`
+	ActualEndAt        *time.Time                 `json:"actual_end_at,omitempty"`
+}
+
+// Session serializes a session as seen by viewer
+func Session(s *models.Session, viewer *models.User) *SessionResponse {
+	return session(s, viewer, nil)
+}
+
+// session serializes s, listing the RSVPs attendees lets the viewer see. A
+// nil attendees is loaded for s alone when it has RSVPs to filter.
+func session(s *models.Session, viewer *models.User, attendees *attendeeFilter) *SessionResponse {
+	if s == nil {
+		return nil
+	}
+	r := &SessionResponse{
+		ID:                 s.ID,
+		Title:              s.Title,
+		Description:        s.Description,
+		SessionDate:        s.SessionDate,
+		StartsAt:           s.StartsAt,
+		EndsAt:             s.EndsAt,
+		StartTime:          utils.FormatClock(s.StartsAt),
+		EndTime:            utils.FormatClock(s.EndsAt),
+		Courts:             s.Courts,
+		MaxPlayers:         s.MaxPlayers,
+		Overbooking:        s.Overbooking,
+		RSVPDeadline:       s.RSVPDeadline,
+		IsRecurring:        s.IsRecurring,
+		RecurringDayOfWeek: s.RecurringDayOfWeek,
+		RecurringParentID:  s.RecurringParentID,
+		Status:             s.Status,
+		IsOutdoor:          s.IsOutdoor,
+		RequiresApproval:   s.RequiresApproval,
+		FairShare:          s.FairShare,
+		AllowGuests:        s.AllowGuests,
+		SessionType:        s.SessionType,
+		CoachID:            s.CoachID,
+		CurriculumNotes:    s.CurriculumNotes,
+		AllocatedAt:        s.AllocatedAt,
+		CancellationReason: s.CancellationReason,
+		CreatedBy:          s.CreatedBy,
+		CreatedAt:          s.CreatedAt,
+		UpdatedAt:          s.UpdatedAt,
+		ShuttlesUsed:       s.ShuttlesUsed,
+		ActualStartAt:      s.ActualStartAt,
+		ActualEndAt:        s.ActualEndAt,
+	}
+	// Guests get the schedule without anyone's name
+	if !CanSeeMembers(viewer) {
+		return r
+	}
+	r.Creator = User(s.Creator, viewer)
+	r.Coach = User(s.Coach, viewer)
+	r.Attachments = s.Attachments
+	if len(s.RSVPs) > 0 {
+		if attendees == nil {
+			attendees = newAttendeeFilter(viewer, []uuid.UUID{s.ID})
+		}
+		if rsvps := attendees.visible(s, s.RSVPs); len(rsvps) > 0 {
+			r.RSVPs = RSVPs(rsvps, viewer)
+		}
+	}
+	return r
+}
+
+// Sessions serializes a list of sessions as seen by viewer
+func Sessions(sessions []models.Session, viewer *models.User) []SessionResponse {
+	ids := make([]uuid.UUID, len(sessions))
+	for i := range sessions {
+		ids[i] = sessions[i].ID
+	}
+	attendees := newAttendeeFilter(viewer, ids)
+
+	result := make([]SessionResponse, len(sessions))
+	for i := range sessions {
+		result[i] = *session(&sessions[i], viewer, attendees)
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+// HiddenMemberName stands in for members who have opted out of a list
+const HiddenMemberName = "Hidden member"
+
+// hides reports whether subject's privacy choice hidden keeps them from viewer
+func hides(subject *models.User, viewer *models.User, hidden func(models.PrivacySettings) bool) bool {
+	return subject != nil && hidden(subject.Privacy) && !canSeePrivate(viewer, subject.ID)
+}
+
+func hideFromWaitlist(p models.PrivacySettings) bool     { return p.HideFromWaitlist }
+func hideFromLeaderboards(p models.PrivacySettings) bool { return p.HideFromLeaderboards }
+func hideAttendance(p models.PrivacySettings) bool       { return p.HideAttendance }
+
+// WaitlistEntryResponse is a waitlisted player; hidden players keep their
+// place in the queue but lose their name and ID
+type WaitlistEntryResponse struct {
+	Position       int        `json:"position"`
+	UserID         *uuid.UUID `json:"user_id,omitempty"`
+	Name           string     `json:"name"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"` // while a spot is held for them
+}
+
+// Waitlist serializes a session's waitlist as seen by viewer
+func Waitlist(entries []services.WaitlistEntry, viewer *models.User) []WaitlistEntryResponse {
+	result := make([]WaitlistEntryResponse, len(entries))
+	for i, e := range entries {
+		result[i] = WaitlistEntryResponse{Position: e.Position, Name: HiddenMemberName, OfferExpiresAt: e.OfferExpiresAt}
+		if !hides(e.User, viewer, hideFromWaitlist) {
+			result[i].UserID = &entries[i].UserID
+			result[i].Name = e.Name
+		}
+	}
+	return result
+}
+
+// StandingResponse is a tournament table row; hidden players keep their
+// record and rank but lose their name and ID
+type StandingResponse struct {
+	UserID        *uuid.UUID `json:"user_id,omitempty"`
+	Name          string     `json:"name"`
+	Played        int        `json:"played"`
+	Wins          int        `json:"wins"`
+	Losses        int        `json:"losses"`
+	PointsFor     int        `json:"points_for"`
+	PointsAgainst int        `json:"points_against"`
+}
+
+// Standings serializes a tournament table as seen by viewer
+func Standings(standings []services.Standing, viewer *models.User) []StandingResponse {
+	result := make([]StandingResponse, len(standings))
+	for i, s := range standings {
+		result[i] = StandingResponse{
+			Name:          HiddenMemberName,
+			Played:        s.Played,
+			Wins:          s.Wins,
+			Losses:        s.Losses,
+			PointsFor:     s.PointsFor,
+			PointsAgainst: s.PointsAgainst,
+		}
+		if !hides(s.User, viewer, hideFromLeaderboards) {
+			result[i].UserID = &standings[i].UserID
+			result[i].Name = s.Name
+		}
+	}
+	return result
+}
+
+// visibleBadges drops attendance badges from members who hide their attendance
+func visibleBadges(u *models.User, viewer *models.User) []models.UserBadge {
+	if !hides(u, viewer, hideAttendance) {
+		return u.Badges
+	}
+	var badges []models.UserBadge
+	for _, b := range u.Badges {
+		if !b.BadgeType.IsAttendance() {
+			badges = append(badges, b)
+		}
+	}
+	return badges
+}
+
+// FastestFingerResponse is a row of the weekly fastest-RSVP board; hidden
+// players keep their place but lose their name and ID
+type FastestFingerResponse struct {
+	UserID  *uuid.UUID `json:"user_id,omitempty"`
+	Name    string     `json:"name"`
+	Seconds int        `json:"seconds"`
+}
+
+// FastestFingers serializes the weekly fastest-RSVP board as seen by viewer
+func FastestFingers(board []services.FastestFinger, viewer *models.User) []FastestFingerResponse {
+	result := make([]FastestFingerResponse, len(board))
+	for i, f := range board {
+		result[i] = FastestFingerResponse{Name: HiddenMemberName, Seconds: f.Seconds}
+		if !hides(f.User, viewer, hideFromLeaderboards) {
+			result[i].UserID = &board[i].UserID
+			result[i].Name = f.Name
+		}
+	}
+	return result
+}
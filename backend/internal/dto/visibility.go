@@ -0,0 +1,37 @@
+package dto
+
+import "github.com/weekday-masters/backend/internal/models"
+
+// Visibility is how much of the club a viewer may see. Responses are
+// downgraded to match instead of refused, so people waiting for approval can
+// browse the schedule and see what they're joining.
+type Visibility int
+
+const (
+	// VisibilityGuest sees sessions and spot counts but not who is playing:
+	// pending or rejected members, or no user at all
+	VisibilityGuest Visibility = iota
+	// VisibilityMember sees other members' names and RSVPs
+	VisibilityMember
+	// VisibilityAdmin also sees everyone's private fields
+	VisibilityAdmin
+)
+
+// VisibilityFor returns what viewer may see
+func VisibilityFor(viewer *models.User) Visibility {
+	switch {
+	case viewer == nil:
+		return VisibilityGuest
+	case viewer.IsAdmin():
+		return VisibilityAdmin
+	case viewer.IsApproved():
+		return VisibilityMember
+	default:
+		return VisibilityGuest
+	}
+}
+
+// CanSeeMembers reports whether viewer may see who belongs to the club and who is playing
+func CanSeeMembers(viewer *models.User) bool {
+	return VisibilityFor(viewer) >= VisibilityMember
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"io"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/dto"
 	"github.com/weekday-masters/backend/internal/middleware"
 	"github.com/weekday-masters/backend/internal/models"
 	"github.com/weekday-masters/backend/internal/services"
@@ -13,16 +17,21 @@ import (
 )
 
 type AdminHandler struct {
-	userService    *services.UserService
-	sessionService *services.SessionService
-	rsvpService    *services.RSVPService
+	userService    UserService
+	sessionService SessionService
+	rsvpService    RSVPService
+
+	pendingActionService PendingActionService
+	clubService          ClubService
 }
 
-func NewAdminHandler(userService *services.UserService, sessionService *services.SessionService, rsvpService *services.RSVPService) *AdminHandler {
+func NewAdminHandler(userService UserService, sessionService SessionService, rsvpService RSVPService, pendingActionService PendingActionService, clubService ClubService) *AdminHandler {
 	return &AdminHandler{
-		userService:    userService,
-		sessionService: sessionService,
-		rsvpService:    rsvpService,
+		userService:          userService,
+		sessionService:       sessionService,
+		rsvpService:          rsvpService,
+		pendingActionService: pendingActionService,
+		clubService:          clubService,
 	}
 }
 
@@ -34,7 +43,7 @@ func (h *AdminHandler) ListJoinRequests(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	c.JSON(http.StatusOK, dto.Users(users, currentUser(c)))
 }
 
 // ApproveJoinRequest approves a membership request
@@ -46,13 +55,13 @@ func (h *AdminHandler) ApproveJoinRequest(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.ApproveJoinRequest(id)
+	user, err := h.userService.ApproveJoinRequest(id, currentUser(c).ID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, dto.User(user, currentUser(c)))
 }
 
 // RejectJoinRequest rejects a membership request
@@ -64,17 +73,27 @@ func (h *AdminHandler) RejectJoinRequest(c *gin.Context) {
 		return
 	}
 
+	pending, err := h.pendingActionService.RequestIfRequired(models.PendingActionRejectMember, id, "", currentUser(c).ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if pending != nil {
+		respondPendingApproval(c, pending)
+		return
+	}
+
 	user, err := h.userService.RejectJoinRequest(id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, dto.User(user, currentUser(c)))
 }
 
 type UpdateRoleRequest struct {
-	Role string `json:"role" binding:"required,oneof=pending player admin"`
+	Role string `json:"role" binding:"required,oneof=pending player coach admin"`
 }
 
 // UpdateUserRole updates a user's role
@@ -88,17 +107,54 @@ func (h *AdminHandler) UpdateUserRole(c *gin.Context) {
 
 	var req UpdateRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	pending, err := h.pendingActionService.RequestIfRequired(models.PendingActionChangeAdminRole, id, req.Role, currentUser(c).ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if pending != nil {
+		respondPendingApproval(c, pending)
 		return
 	}
 
 	user, err := h.userService.UpdateUserRole(id, models.UserRole(req.Role))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.User(user, currentUser(c)))
+}
+
+type UpdateTierRequest struct {
+	Tier string `json:"tier" binding:"required,oneof=regular twice_a_week unlimited"`
+}
+
+// UpdateUserTier changes a member's tier, and with it their weekly RSVP limit
+func (h *AdminHandler) UpdateUserTier(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UpdateTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	user, err := h.userService.UpdateUserTier(id, models.MembershipTier(req.Tier))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.User(user, currentUser(c)))
 }
 
 type CreateSessionRequest struct {
@@ -107,10 +163,19 @@ type CreateSessionRequest struct {
 	SessionDate        string `json:"session_date" binding:"required"` // YYYY-MM-DD
 	StartTime          string `json:"start_time" binding:"required"`   // HH:MM
 	EndTime            string `json:"end_time" binding:"required"`     // HH:MM
-	Courts             int    `json:"courts" binding:"required,min=1,max=3"`
+	Courts             int    `json:"courts" binding:"required,min=1"`
+	IsOutdoor          bool   `json:"is_outdoor"`
+	RequiresApproval   bool   `json:"requires_approval"`
+	FairShare          bool   `json:"fair_share"` // implies requires_approval
+	AllowGuests        bool   `json:"allow_guests"`
 	IsRecurring        bool   `json:"is_recurring"`
 	RecurringDayOfWeek *int   `json:"recurring_day_of_week"`
 	Occurrences        *int   `json:"occurrences"` // Number of recurring sessions to create
+	SessionType        string `json:"session_type" binding:"omitempty,oneof=social training"`
+	CoachID            string `json:"coach_id"`         // training sessions only
+	CurriculumNotes    string `json:"curriculum_notes"` // training sessions only
+	Spots              *int   `json:"spots"`            // training sessions only; defaults to what the courts hold
+	Overbooking        int    `json:"overbooking" binding:"min=0"`
 }
 
 // CreateSession creates a new session
@@ -123,7 +188,7 @@ func (h *AdminHandler) CreateSession(c *gin.Context) {
 
 	var req CreateSessionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -132,36 +197,124 @@ func (h *AdminHandler) CreateSession(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
 		return
 	}
+	startTime, err := utils.ParseClock(req.StartTime)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	endTime, err := utils.ParseClock(req.EndTime)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	var coachID *uuid.UUID
+	if req.CoachID != "" {
+		id, err := uuid.Parse(req.CoachID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coach ID"})
+			return
+		}
+		coachID = &id
+	}
 
 	session, err := h.sessionService.CreateSession(services.CreateSessionInput{
 		Title:              req.Title,
 		Description:        req.Description,
 		SessionDate:        sessionDate,
-		StartTime:          req.StartTime,
-		EndTime:            req.EndTime,
+		StartTime:          startTime,
+		EndTime:            endTime,
 		Courts:             req.Courts,
+		IsOutdoor:          req.IsOutdoor,
+		RequiresApproval:   req.RequiresApproval,
+		FairShare:          req.FairShare,
+		AllowGuests:        req.AllowGuests,
 		IsRecurring:        req.IsRecurring,
 		RecurringDayOfWeek: req.RecurringDayOfWeek,
 		Occurrences:        req.Occurrences,
+		SessionType:        models.SessionType(req.SessionType),
+		CoachID:            coachID,
+		CurriculumNotes:    req.CurriculumNotes,
+		Spots:              req.Spots,
+		Overbooking:        req.Overbooking,
 		CreatedBy:          user.ID,
 	})
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, session)
+	c.JSON(http.StatusCreated, dto.Session(session, user))
+}
+
+// PreviewRecurrenceRequest is the recurrence part of a CreateSessionRequest
+type PreviewRecurrenceRequest struct {
+	Title              string `json:"title"`
+	SessionDate        string `json:"session_date" binding:"required"` // YYYY-MM-DD
+	StartTime          string `json:"start_time" binding:"required"`   // HH:MM
+	EndTime            string `json:"end_time" binding:"required"`     // HH:MM
+	RecurringDayOfWeek *int   `json:"recurring_day_of_week" binding:"required"`
+	Occurrences        *int   `json:"occurrences"`
+}
+
+// PreviewRecurrence returns the sessions a recurring series would generate,
+// without creating them
+func (h *AdminHandler) PreviewRecurrence(c *gin.Context) {
+	var req PreviewRecurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	sessionDate, err := utils.ParseDateInSydney(req.SessionDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+	startTime, err := utils.ParseClock(req.StartTime)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	endTime, err := utils.ParseClock(req.EndTime)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	preview, err := h.sessionService.PreviewRecurrence(services.RecurrencePreviewInput{
+		Title:              req.Title,
+		SessionDate:        sessionDate,
+		StartTime:          startTime,
+		EndTime:            endTime,
+		RecurringDayOfWeek: req.RecurringDayOfWeek,
+		Occurrences:        req.Occurrences,
+	})
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
 }
 
 type UpdateSessionRequest struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	SessionDate *string `json:"session_date"` // YYYY-MM-DD
-	StartTime   *string `json:"start_time"`   // HH:MM
-	EndTime     *string `json:"end_time"`     // HH:MM
-	Courts      *int    `json:"courts"`
-	Status      *string `json:"status"`
+	Title            *string `json:"title"`
+	Description      *string `json:"description"`
+	SessionDate      *string `json:"session_date"` // YYYY-MM-DD
+	StartTime        *string `json:"start_time"`   // HH:MM
+	EndTime          *string `json:"end_time"`     // HH:MM
+	Courts           *int    `json:"courts"`
+	IsOutdoor        *bool   `json:"is_outdoor"`
+	RequiresApproval *bool   `json:"requires_approval"`
+	FairShare        *bool   `json:"fair_share"`
+	AllowGuests      *bool   `json:"allow_guests"`
+	Status           *string `json:"status"`
+	SessionType      *string `json:"session_type" binding:"omitempty,oneof=social training"`
+	CoachID          *string `json:"coach_id"`
+	CurriculumNotes  *string `json:"curriculum_notes"`
+	Spots            *int    `json:"spots"`
+	Overbooking      *int    `json:"overbooking" binding:"omitempty,min=0"`
 }
 
 // UpdateSession updates a session
@@ -175,16 +328,18 @@ func (h *AdminHandler) UpdateSession(c *gin.Context) {
 
 	var req UpdateSessionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	input := services.UpdateSessionInput{
-		Title:       req.Title,
-		Description: req.Description,
-		StartTime:   req.StartTime,
-		EndTime:     req.EndTime,
-		Courts:      req.Courts,
+		Title:            req.Title,
+		Description:      req.Description,
+		Courts:           req.Courts,
+		IsOutdoor:        req.IsOutdoor,
+		RequiresApproval: req.RequiresApproval,
+		FairShare:        req.FairShare,
+		AllowGuests:      req.AllowGuests,
 	}
 
 	if req.SessionDate != nil {
@@ -195,19 +350,50 @@ func (h *AdminHandler) UpdateSession(c *gin.Context) {
 		}
 		input.SessionDate = &sessionDate
 	}
+	if req.StartTime != nil {
+		startTime, err := utils.ParseClock(*req.StartTime)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		input.StartTime = &startTime
+	}
+	if req.EndTime != nil {
+		endTime, err := utils.ParseClock(*req.EndTime)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		input.EndTime = &endTime
+	}
 
 	if req.Status != nil {
 		status := models.SessionStatus(*req.Status)
 		input.Status = &status
 	}
+	if req.SessionType != nil {
+		sessionType := models.SessionType(*req.SessionType)
+		input.SessionType = &sessionType
+	}
+	if req.CoachID != nil {
+		coachID, err := uuid.Parse(*req.CoachID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coach ID"})
+			return
+		}
+		input.CoachID = &coachID
+	}
+	input.CurriculumNotes = req.CurriculumNotes
+	input.Spots = req.Spots
+	input.Overbooking = req.Overbooking
 
 	session, err := h.sessionService.UpdateSession(id, input)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, session)
+	c.JSON(http.StatusOK, dto.Session(session, currentUser(c)))
 }
 
 // DeleteSession deletes or cancels a session
@@ -219,8 +405,18 @@ func (h *AdminHandler) DeleteSession(c *gin.Context) {
 		return
 	}
 
+	pending, err := h.pendingActionService.RequestIfRequired(models.PendingActionDeleteSession, id, "", currentUser(c).ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if pending != nil {
+		respondPendingApproval(c, pending)
+		return
+	}
+
 	if err := h.sessionService.DeleteSession(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -248,11 +444,169 @@ func (h *AdminHandler) CancelSession(c *gin.Context) {
 
 	session, err := h.sessionService.CancelSession(id, req.Reason)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Session(session, currentUser(c)))
+}
+
+type MergeSessionsRequest struct {
+	RSVPConflict string `json:"rsvp_conflict" binding:"omitempty,oneof=latest most_committed target source"`
+}
+
+// MergeSessions folds the duplicate session :otherId into :id and cancels it
+func (h *AdminHandler) MergeSessions(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+	otherID, err := uuid.Parse(c.Param("otherId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID to merge"})
+		return
+	}
+
+	var req MergeSessionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := h.sessionService.MergeSessions(id, otherID, services.RSVPConflict(req.RSVPConflict), user.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"session":           dto.Session(result.Session, currentUser(c)),
+		"moved_rsvps":       result.MovedRSVPs,
+		"merged_rsvps":      result.MergedRSVPs,
+		"moved_comments":    result.MovedComments,
+		"moved_attachments": result.MovedAttachments,
+	})
+}
+
+type SessionUsageRequest struct {
+	ShuttlesUsed  *int       `json:"shuttles_used"`
+	ActualStartAt *time.Time `json:"actual_start_at"`
+	ActualEndAt   *time.Time `json:"actual_end_at"`
+}
+
+// RecordSessionUsage records shuttles used and actual start/end times for a session
+func (h *AdminHandler) RecordSessionUsage(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req SessionUsageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	session, err := h.sessionService.RecordUsage(id, services.SessionUsageInput{
+		ShuttlesUsed:  req.ShuttlesUsed,
+		ActualStartAt: req.ActualStartAt,
+		ActualEndAt:   req.ActualEndAt,
+	})
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Session(session, currentUser(c)))
+}
+
+// GetSessionNotes returns a session's private admin notes
+func (h *AdminHandler) GetSessionNotes(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	notes, err := h.sessionService.GetAdminNotes(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"session_id": id, "notes": notes})
+}
+
+type SessionNotesRequest struct {
+	Notes string `json:"notes"`
+}
+
+// UpdateSessionNotes replaces a session's private admin notes
+func (h *AdminHandler) UpdateSessionNotes(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req SessionNotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.sessionService.UpdateAdminNotes(id, req.Notes); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, session)
+	c.JSON(http.StatusOK, gin.H{"session_id": id, "notes": req.Notes})
+}
+
+type ExtendDeadlineRequest struct {
+	RSVPDeadline time.Time `json:"rsvp_deadline" binding:"required"`
+	Reason       string    `json:"reason"`
+}
+
+// ExtendDeadline moves a session's RSVP deadline later and re-notifies members who haven't responded
+func (h *AdminHandler) ExtendDeadline(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req ExtendDeadlineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	session, err := h.sessionService.ExtendDeadline(id, req.RSVPDeadline, user.ID, req.Reason)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Session(session, currentUser(c)))
 }
 
 type AdminRSVPRequest struct {
@@ -277,7 +631,7 @@ func (h *AdminHandler) AddPlayerRSVP(c *gin.Context) {
 
 	var req AdminRSVPRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -288,17 +642,135 @@ func (h *AdminHandler) AddPlayerRSVP(c *gin.Context) {
 	}, true) // byAdmin = true
 
 	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RSVP(rsvp, currentUser(c)))
+}
+
+type AdminRemoveRSVPRequest struct {
+	Status string `json:"status" binding:"omitempty,oneof=out maybe"`
+	Reason string `json:"reason"`
+}
+
+// RemovePlayerRSVP allows admin to remove a player's RSVP or change it to out/maybe
+func (h *AdminHandler) RemovePlayerRSVP(c *gin.Context) {
+	sessionIDStr := c.Param("id")
+	sessionID, err := uuid.Parse(sessionIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	userIDStr := c.Param("userId")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// Body is optional; with no status the RSVP is deleted
+	var req AdminRemoveRSVPRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	var newStatus *models.RSVPStatus
+	if req.Status != "" {
+		status := models.RSVPStatus(req.Status)
+		newStatus = &status
+	}
+
+	if err := h.rsvpService.AdminRemoveRSVP(sessionID, userID, newStatus, req.Reason); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "RSVP updated"})
+}
+
+// CheckInPlayer records that a confirmed player has arrived
+func (h *AdminHandler) CheckInPlayer(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	rsvp, err := h.rsvpService.CheckIn(sessionID, userID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RSVP(rsvp, currentUser(c)))
+}
+
+type MarkAttendanceRequest struct {
+	Status models.AttendanceStatus `json:"status" binding:"required,oneof=present absent excused"`
+	Note   string                  `json:"note" binding:"max=500"`
+}
+
+// RecordAttendance marks whether a member came to a session: present,
+// absent or excused. Members who weren't IN can be marked present as
+// walk-ins.
+func (h *AdminHandler) RecordAttendance(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req MarkAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, rsvp)
+	attendance, err := h.rsvpService.RecordAttendance(sessionID, userID, req.Status, req.Note, currentUser(c).ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attendance)
+}
+
+// GetSessionAttendance lists the attendance recorded for a session
+func (h *AdminHandler) GetSessionAttendance(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	attendance, err := h.rsvpService.GetSessionAttendance(sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get attendance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Attendance(attendance, currentUser(c)))
 }
 
 // GetClub returns club information
 func (h *AdminHandler) GetClub(c *gin.Context) {
-	var club models.Club
-	if err := database.DB.First(&club).Error; err != nil {
+	club, err := h.clubService.GetClub()
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Club not found"})
 		return
 	}
@@ -310,18 +782,54 @@ type UpdateClubRequest struct {
 	Name         *string `json:"name"`
 	VenueName    *string `json:"venue_name"`
 	VenueAddress *string `json:"venue_address"`
+
+	// Capacity formula for sessions created or re-sized from now on
+	PlayersPerCourt *int `json:"players_per_court" binding:"omitempty,min=0"`
+	ExtraPlayers    *int `json:"extra_players" binding:"omitempty,min=0"`
+
+	// Hours in the club's timezone during which non-urgent emails go out
+	EmailWindowStart *int `json:"email_window_start" binding:"omitempty,min=0,max=23"`
+	EmailWindowEnd   *int `json:"email_window_end" binding:"omitempty,min=0,max=23"`
+
+	// How many weeks of recurring sessions to keep generated
+	RecurringWeeksAhead *int `json:"recurring_weeks_ahead" binding:"omitempty,min=1,max=52"`
+
+	// Club-wide RSVP caps; 0 removes the cap
+	MaxRSVPsPerWeek   *int `json:"max_rsvps_per_week" binding:"omitempty,min=0,max=14"`
+	MaxRSVPsPerSeries *int `json:"max_rsvps_per_series" binding:"omitempty,min=0,max=52"`
+
+	// Also send the RSVP summary to the session's organizer
+	RSVPSummaryToOrganizer *bool `json:"rsvp_summary_to_organizer"`
+
+	// Hours before the RSVP deadline to nudge members still on MAYBE; 0 is off
+	MaybeNudgeHours *int `json:"maybe_nudge_hours" binding:"omitempty,min=0,max=168"`
+
+	// Change MAYBE RSVPs to OUT this many hours before the deadline
+	ExpireMaybes     *bool `json:"expire_maybes"`
+	MaybeExpiryHours *int  `json:"maybe_expiry_hours" binding:"omitempty,min=0,max=168"`
+
+	// Hours a spot offered off the waitlist is held for confirmation
+	WaitlistOfferHours *int `json:"waitlist_offer_hours" binding:"omitempty,min=1,max=72"`
+
+	// Forecast thresholds for proposing to cancel outdoor sessions; 0 ignores one
+	WeatherRainChance  *int `json:"weather_rain_chance" binding:"omitempty,min=0,max=100"`
+	WeatherWindKmh     *int `json:"weather_wind_kmh" binding:"omitempty,min=0,max=200"`
+	WeatherTemperature *int `json:"weather_temperature" binding:"omitempty,min=0,max=60"`
+
+	// Whether members see who's coming: names, count or after_rsvp
+	AttendeeVisibility *models.AttendeeVisibility `json:"attendee_visibility" binding:"omitempty,oneof=names count after_rsvp"`
 }
 
 // UpdateClub updates club information
 func (h *AdminHandler) UpdateClub(c *gin.Context) {
 	var req UpdateClubRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	var club models.Club
-	if err := database.DB.First(&club).Error; err != nil {
+	club, err := h.clubService.GetClub()
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Club not found"})
 		return
 	}
@@ -335,11 +843,76 @@ func (h *AdminHandler) UpdateClub(c *gin.Context) {
 	if req.VenueAddress != nil {
 		club.VenueAddress = *req.VenueAddress
 	}
+	if req.PlayersPerCourt != nil {
+		club.PlayersPerCourt = *req.PlayersPerCourt
+	}
+	if req.ExtraPlayers != nil {
+		club.ExtraPlayers = *req.ExtraPlayers
+	}
+	if req.EmailWindowStart != nil {
+		club.EmailWindowStart = *req.EmailWindowStart
+	}
+	if req.EmailWindowEnd != nil {
+		club.EmailWindowEnd = *req.EmailWindowEnd
+	}
+	if req.MaxRSVPsPerWeek != nil {
+		club.MaxRSVPsPerWeek = *req.MaxRSVPsPerWeek
+	}
+	if req.MaxRSVPsPerSeries != nil {
+		club.MaxRSVPsPerSeries = *req.MaxRSVPsPerSeries
+	}
+	if req.RSVPSummaryToOrganizer != nil {
+		club.RSVPSummaryToOrganizer = *req.RSVPSummaryToOrganizer
+	}
+	if req.MaybeNudgeHours != nil {
+		club.MaybeNudgeHours = *req.MaybeNudgeHours
+	}
+	if req.ExpireMaybes != nil {
+		club.ExpireMaybes = *req.ExpireMaybes
+	}
+	if req.MaybeExpiryHours != nil {
+		club.MaybeExpiryHours = *req.MaybeExpiryHours
+	}
+	if req.WaitlistOfferHours != nil {
+		club.WaitlistOfferHours = *req.WaitlistOfferHours
+	}
+	if req.WeatherRainChance != nil {
+		club.WeatherRainChance = *req.WeatherRainChance
+	}
+	if req.WeatherWindKmh != nil {
+		club.WeatherWindKmh = *req.WeatherWindKmh
+	}
+	if req.WeatherTemperature != nil {
+		club.WeatherTemperature = *req.WeatherTemperature
+	}
+	if req.AttendeeVisibility != nil {
+		club.AttendeeVisibility = *req.AttendeeVisibility
+	}
+	previousWeeksAhead := club.RecurringWeeksAhead
+	if req.RecurringWeeksAhead != nil {
+		club.RecurringWeeksAhead = *req.RecurringWeeksAhead
+	}
 
-	if err := database.DB.Save(&club).Error; err != nil {
+	if err := h.clubService.SaveClub(club); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update club"})
 		return
 	}
 
+	// Fill a longer window straight away; a shorter one drops the untouched
+	// sessions that now fall outside it
+	if club.RecurringWeeksAhead > previousWeeksAhead {
+		if err := h.sessionService.RefreshRecurringSessions(); err != nil {
+			log.Printf("Failed to extend recurring sessions: %v", err)
+		}
+	} else if club.RecurringWeeksAhead < previousWeeksAhead {
+		trimmed, err := h.sessionService.TrimRecurringSessions()
+		if err != nil {
+			log.Printf("Failed to trim recurring sessions: %v", err)
+		}
+		if trimmed > 0 {
+			log.Printf("Removed %d recurring sessions beyond the %d-week window", trimmed, club.RecurringWeeksAhead)
+		}
+	}
+
 	c.JSON(http.StatusOK, club)
 }
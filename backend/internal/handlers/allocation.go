@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+type AllocationHandler struct {
+	allocationService AllocationService
+}
+
+func NewAllocationHandler(allocationService AllocationService) *AllocationHandler {
+	return &AllocationHandler{allocationService: allocationService}
+}
+
+// AllocationResultResponse is one request's outcome with the member it belongs to
+type AllocationResultResponse struct {
+	models.AllocationResult
+	User *dto.UserResponse `json:"user,omitempty"`
+}
+
+// GetAllocation returns how a fair-share session's spots were allocated, best ranked first
+func (h *AllocationHandler) GetAllocation(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	results, err := h.allocationService.ListAllocationResults(sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get allocation"})
+		return
+	}
+
+	viewer := currentUser(c)
+	response := make([]AllocationResultResponse, len(results))
+	for i := range results {
+		response[i] = AllocationResultResponse{
+			AllocationResult: results[i],
+			User:             dto.User(results[i].User, viewer),
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+type AnnouncementHandler struct {
+	announcementService AnnouncementService
+}
+
+func NewAnnouncementHandler(announcementService AnnouncementService) *AnnouncementHandler {
+	return &AnnouncementHandler{announcementService: announcementService}
+}
+
+// announcementLimit reads ?limit=, defaulting to 50
+func announcementLimit(c *gin.Context) int {
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+	return limit
+}
+
+// AnnouncementView is an announcement with its sender as seen by the viewer
+type AnnouncementView struct {
+	models.Announcement
+	Creator *dto.UserResponse `json:"creator,omitempty"`
+}
+
+func announcementViews(announcements []models.Announcement, viewer *models.User) []AnnouncementView {
+	views := make([]AnnouncementView, len(announcements))
+	for i := range announcements {
+		views[i] = AnnouncementView{Announcement: announcements[i], Creator: dto.User(announcements[i].Creator, viewer)}
+	}
+	return views
+}
+
+// AnnouncementWithAckResponse is an announcement and when the current user
+// acknowledged it
+type AnnouncementWithAckResponse struct {
+	AnnouncementView
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// ListAnnouncements returns recent announcements with the current user's acknowledgements
+func (h *AnnouncementHandler) ListAnnouncements(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	announcements, err := h.announcementService.ListAnnouncements(user, announcementLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list announcements"})
+		return
+	}
+
+	response := make([]AnnouncementWithAckResponse, len(announcements))
+	for i, a := range announcements {
+		response[i] = AnnouncementWithAckResponse{
+			AnnouncementView: AnnouncementView{Announcement: a.Announcement, Creator: dto.User(a.Creator, user)},
+			AcknowledgedAt:   a.AcknowledgedAt,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// AcknowledgeAnnouncement records that the current user has read an announcement
+func (h *AnnouncementHandler) AcknowledgeAnnouncement(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return
+	}
+
+	ack, err := h.announcementService.Acknowledge(id, user)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ack)
+}
+
+// ListAcknowledgementSummaries returns announcements that ask for
+// acknowledgement with acknowledged and outstanding counts (admin only)
+func (h *AnnouncementHandler) ListAcknowledgementSummaries(c *gin.Context) {
+	summaries, err := h.announcementService.AckSummaries(announcementLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list announcement acknowledgements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summaries)
+}
+
+// AcknowledgementResponse is a member who has acknowledged an announcement
+type AcknowledgementResponse struct {
+	User           *dto.UserResponse `json:"user"`
+	AcknowledgedAt time.Time         `json:"acknowledged_at"`
+}
+
+// GetAcknowledgements lists who has acknowledged an announcement and which
+// approved members are outstanding (admin only)
+func (h *AnnouncementHandler) GetAcknowledgements(c *gin.Context) {
+	admin, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return
+	}
+
+	announcement, acks, err := h.announcementService.GetAcks(id)
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	acknowledged := make([]AcknowledgementResponse, len(acks.Acknowledged))
+	for i, ack := range acks.Acknowledged {
+		acknowledged[i] = AcknowledgementResponse{
+			User:           dto.User(ack.User, admin),
+			AcknowledgedAt: ack.AcknowledgedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"announcement": announcement,
+		"acknowledged": acknowledged,
+		"outstanding":  dto.Users(acks.Outstanding, admin),
+	})
+}
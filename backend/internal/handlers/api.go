@@ -0,0 +1,606 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/config"
+	"github.com/weekday-masters/backend/internal/middleware"
+)
+
+// Services are what the API's handlers are built on
+type Services struct {
+	Users            UserService
+	AccountLinks     AccountLinkService
+	JoinRules        JoinRuleService
+	Invites          InviteService
+	Avatars          AvatarService
+	Sessions         SessionService
+	RSVPs            RSVPService
+	PendingActions   PendingActionService
+	Club             ClubService
+	Notifications    NotificationService
+	Moderation       ModerationService
+	Announcements    AnnouncementService
+	Tournaments      TournamentService
+	Games            GameService
+	Courts           CourtService
+	Reports          ReportService
+	Stats            StatsService
+	Comments         CommentService
+	Sync             SyncService
+	Documents        DocumentService
+	Incidents        IncidentService
+	Widget           WidgetService
+	Allocation       AllocationService
+	Jobs             JobService
+	Scheduler        SchedulerService
+	Events           EventService
+	Archive          ArchiveService
+	ClubExport       ClubExportService
+	Inactivity       MemberInactivityService
+	ContactLogs      ContactLogService
+	Usage            UsageService
+	Orders           OrderService
+	Coaching         CoachingService
+	Carpools         CarpoolService
+	Inventory        InventoryService
+	Cards            CardService
+	Messages         MessageCatalogService
+	Share            ShareService
+	Search           SearchService
+	WeatherProposals WeatherProposalService
+	Committee        CommitteeService
+	InboundEmail     InboundEmailService
+}
+
+// APIConfig is the configuration the API's handlers are built with
+type APIConfig struct {
+	UserInfo    UserInfoFunc // profiles for the sign-in callback
+	FrontendURL string       // base of share links
+
+	// At most AnnouncementLimit announcements per AnnouncementWindow without
+	// an override; 0 leaves them uncapped
+	AnnouncementLimit  int
+	AnnouncementWindow time.Duration
+
+	// Reloadable settings, including which features are turned on
+	Live *config.Live
+}
+
+// Middleware is how the API routes check who's calling. The server verifies
+// Auth0 tokens; the mock server signs requests in as fixture members.
+type Middleware struct {
+	Token        gin.HandlerFunc // verifies a login that may have no account yet
+	Authenticate gin.HandlerFunc // verifies the login and loads its member
+
+	// Run with RequireAdmin on the admin routes
+	AdminIPAllowlist gin.HandlerFunc
+	RecentMFA        gin.HandlerFunc
+
+	// Shared across API versions so a limit can't be doubled by switching
+	// prefix
+	AuthCallbackLimit        gin.HandlerFunc
+	AuthCallbackAccountLimit gin.HandlerFunc
+	AccountLinkLimit         gin.HandlerFunc
+	AccountLinkAccountLimit  gin.HandlerFunc
+	InvitePreviewLimit       gin.HandlerFunc
+}
+
+// API is every handler of the REST API. It's built once and registered
+// under each version prefix.
+type API struct {
+	middleware Middleware
+	live       *config.Live
+	trackUsage gin.HandlerFunc
+
+	auth            *AuthHandler
+	user            *UserHandler
+	avatar          *AvatarHandler
+	session         *SessionHandler
+	rsvp            *RSVPHandler
+	admin           *AdminHandler
+	notification    *NotificationHandler
+	tournament      *TournamentHandler
+	game            *GameHandler
+	court           *CourtHandler
+	report          *ReportHandler
+	stats           *StatsHandler
+	comment         *CommentHandler
+	config          *ConfigHandler
+	sync            *SyncHandler
+	document        *DocumentHandler
+	incident        *IncidentHandler
+	widget          *WidgetHandler
+	allocation      *AllocationHandler
+	job             *JobHandler
+	event           *EventHandler
+	archive         *ArchiveHandler
+	clubExport      *ClubExportHandler
+	inactivity      *MemberInactivityHandler
+	contactLog      *ContactLogHandler
+	usage           *UsageHandler
+	order           *OrderHandler
+	coaching        *CoachingHandler
+	carpool         *CarpoolHandler
+	inventory       *InventoryHandler
+	joinRule        *JoinRuleHandler
+	invite          *InviteHandler
+	announcement    *AnnouncementHandler
+	card            *CardHandler
+	messageTemplate *MessageTemplateHandler
+	share           *ShareHandler
+	search          *SearchHandler
+	weatherProposal *WeatherProposalHandler
+	seasonRollover  *SeasonRolloverHandler
+	committee       *CommitteeHandler
+	inboundEmail    *InboundEmailHandler
+}
+
+func NewAPI(s Services, cfg APIConfig, mw Middleware) *API {
+	return &API{
+		middleware: mw,
+		live:       cfg.Live,
+		// Counts each member's requests for the admin usage report
+		trackUsage: middleware.TrackUsage(s.Usage),
+
+		auth:         NewAuthHandler(s.Users, s.AccountLinks, s.JoinRules, s.Invites, cfg.UserInfo),
+		user:         NewUserHandler(s.Users),
+		avatar:       NewAvatarHandler(s.Avatars),
+		session:      NewSessionHandler(s.Sessions, s.RSVPs),
+		rsvp:         NewRSVPHandler(s.RSVPs),
+		admin:        NewAdminHandler(s.Users, s.Sessions, s.RSVPs, s.PendingActions, s.Club),
+		notification: NewNotificationHandler(s.Notifications, s.Moderation, s.Announcements, cfg.AnnouncementLimit, cfg.AnnouncementWindow),
+		tournament:   NewTournamentHandler(s.Tournaments),
+		game:         NewGameHandler(s.Games),
+		court:        NewCourtHandler(s.Courts),
+		report:       NewReportHandler(s.Reports),
+		stats:        NewStatsHandler(s.Stats),
+		comment:      NewCommentHandler(s.Comments, s.Moderation),
+		config:       NewConfigHandler(cfg.Live),
+		sync:         NewSyncHandler(s.Sync),
+		document:     NewDocumentHandler(s.Documents),
+		incident:     NewIncidentHandler(s.Incidents),
+		widget:       NewWidgetHandler(s.Widget),
+		allocation:   NewAllocationHandler(s.Allocation),
+		job:          NewJobHandler(s.Jobs, s.Scheduler),
+		event:        NewEventHandler(s.Events),
+		archive:      NewArchiveHandler(s.Archive),
+		clubExport:   NewClubExportHandler(s.ClubExport),
+		inactivity:   NewMemberInactivityHandler(s.Inactivity),
+		contactLog:   NewContactLogHandler(s.ContactLogs),
+		usage:        NewUsageHandler(s.Usage),
+		order:        NewOrderHandler(s.Orders),
+		coaching:     NewCoachingHandler(s.Coaching),
+		carpool:      NewCarpoolHandler(s.Carpools),
+		inventory:    NewInventoryHandler(s.Inventory),
+		joinRule:     NewJoinRuleHandler(s.JoinRules),
+		invite:       NewInviteHandler(s.Invites),
+		announcement: NewAnnouncementHandler(s.Announcements),
+		card:         NewCardHandler(s.Cards),
+
+		messageTemplate: NewMessageTemplateHandler(s.Messages),
+		share:           NewShareHandler(s.Share, s.Sessions, cfg.FrontendURL),
+		search:          NewSearchHandler(s.Search),
+		weatherProposal: NewWeatherProposalHandler(s.WeatherProposals),
+		seasonRollover:  NewSeasonRolloverHandler(s.Sessions),
+		committee:       NewCommitteeHandler(s.Committee, s.Announcements, s.Moderation),
+		inboundEmail:    NewInboundEmailHandler(s.InboundEmail),
+	}
+}
+
+// feature guards routes for a feature that can be turned off with
+// DISABLED_FEATURES
+func (a *API) feature(name string) gin.HandlerFunc {
+	return middleware.RequireFeature(a.live.FeatureEnabled, name)
+}
+
+// RegisterPages adds the routes served outside the API
+func (a *API) RegisterPages(r gin.IRoutes) {
+	// Shared session links, served as pages so chat apps can show a preview
+	r.GET("/share/sessions/:token", a.share.SharePage)
+	r.GET("/share/sessions/:token/image.png", a.share.SharePreviewImage)
+
+	// Replies to reminder emails, posted by SendGrid Inbound Parse
+	r.POST("/webhooks/email/inbound", a.inboundEmail.ReceiveEmail)
+}
+
+// Register adds the API routes to api, the group for one version prefix
+func (a *API) Register(api *gin.RouterGroup) {
+	// Public routes
+	api.POST("/auth/callback",
+		a.middleware.AuthCallbackLimit,
+		a.middleware.Token,
+		a.middleware.AuthCallbackAccountLimit,
+		a.auth.Callback)
+	// Claiming an existing account from a new login
+	api.POST("/auth/link/request",
+		a.middleware.AccountLinkLimit,
+		a.middleware.Token,
+		a.middleware.AccountLinkAccountLimit,
+		a.auth.RequestAccountLink)
+	api.POST("/auth/link/confirm",
+		a.middleware.AccountLinkLimit,
+		a.middleware.Token,
+		a.middleware.AccountLinkAccountLimit,
+		a.auth.ConfirmAccountLink)
+	api.GET("/club", a.admin.GetClub)
+	// Member photos, for image tags; see services.AvatarPath
+	api.GET("/avatars/:avatarId/:size", a.avatar.ServeAvatar)
+	// What an invite link offers, shown before signing in
+	api.GET("/invites/:token", a.middleware.InvitePreviewLimit, a.invite.PreviewInvite)
+	// Approve and decline buttons on admins' weather proposal pushes;
+	// each link is signed for the admin it was sent to
+	api.POST("/weather-proposals/:id/:decision", a.weatherProposal.DecideFromLink)
+
+	// Embeddable endpoints, open to any origin
+	public := api.Group("/public")
+	public.Use(middleware.PublicCORS())
+	{
+		public.GET("/widget", a.widget.GetWidget)
+		// Preflight is answered by PublicCORS; the route just lets it run
+		public.OPTIONS("/widget", func(c *gin.Context) {})
+
+		// Session previews behind signed share links
+		public.GET("/sessions/:token", a.share.GetSharedSession)
+		public.OPTIONS("/sessions/:token", func(c *gin.Context) {})
+	}
+
+	// Protected routes (requires valid JWT)
+	protected := api.Group("")
+	protected.Use(a.middleware.Authenticate)
+	// Counts each member's requests for the admin usage report
+	protected.Use(a.trackUsage)
+	{
+		// User routes
+		protected.GET("/users/me", a.user.GetMe)
+		protected.PUT("/users/me", a.user.UpdateMe)
+		protected.POST("/users/me/avatar", a.avatar.UploadMyAvatar)
+		protected.DELETE("/users/me/avatar", a.avatar.DeleteMyAvatar)
+
+		// Notification preferences routes (available to all authenticated users)
+		protected.GET("/users/me/notifications", a.notification.GetPreferences)
+		protected.PUT("/users/me/notifications", a.notification.UpdatePreferences)
+		protected.POST("/users/me/push-tokens", a.notification.RegisterPushToken)
+		protected.DELETE("/users/me/push-tokens", a.notification.UnregisterPushToken)
+		protected.POST("/users/me/devices/init", a.notification.InitDevice)
+		protected.GET("/users/me/notifications/history", a.notification.GetNotificationHistory)
+		// Pending members can still use a friend's referral code
+		protected.POST("/users/me/referral-code/redeem", a.joinRule.RedeemReferralCode)
+		protected.POST("/notifications/:id/read", a.notification.MarkNotificationRead)
+
+		// The schedule is readable by members awaiting approval too;
+		// responses leave out member names for them (dto.Visibility)
+		protected.GET("/sessions", a.session.ListSessions)
+		protected.GET("/sessions/cancelled", a.session.ListCancelledSessions)
+		protected.GET("/sessions/:id", a.session.GetSession)
+
+		// Search; members awaiting approval only find sessions
+		protected.GET("/search", a.search.Search)
+
+		// These routes require approved membership
+		approved := protected.Group("")
+		approved.Use(middleware.RequireApproved())
+		{
+			approved.GET("/users", a.user.ListMembers)
+
+			// Session routes
+			approved.GET("/sessions/past", a.session.ListPastSessions)
+			approved.GET("/sessions/history", a.session.ListSessionHistory)
+			approved.GET("/users/me/sessions", a.session.ListMySessions)
+
+			// RSVP routes
+			approved.POST("/sessions/:id/rsvp", a.rsvp.CreateRSVP)
+			approved.PUT("/sessions/:id/rsvp", a.rsvp.UpdateRSVP)
+			approved.DELETE("/sessions/:id/rsvp", a.rsvp.DeleteRSVP)
+			approved.GET("/sessions/:id/rsvp/me", a.rsvp.GetMyRSVP)
+			approved.POST("/sessions/:id/checkin", a.rsvp.CheckIn)
+			approved.POST("/sessions/:id/waitlist/confirm", a.rsvp.ConfirmWaitlistOffer)
+			approved.POST("/sessions/:id/waitlist/leave", a.rsvp.LeaveWaitlist)
+			approved.POST("/sessions/:id/guests", a.rsvp.AddGuest)
+			approved.DELETE("/sessions/:id/guests/:guestId", a.rsvp.RemoveGuest)
+
+			// Casual game scores
+			approved.GET("/sessions/:id/games", a.game.ListGames)
+			approved.POST("/sessions/:id/games", a.game.RecordGame)
+			approved.POST("/sessions/:id/games/:gameId/confirm", a.game.ConfirmGame)
+			approved.GET("/sessions/:id/games/:gameId/ratings", a.game.GetMyGameRatings)
+			approved.PUT("/sessions/:id/games/:gameId/ratings", a.game.RatePlayers)
+			approved.GET("/users/me/rating", a.game.GetMyRating)
+			approved.GET("/users/me/card", a.card.GetMyCard)
+			approved.GET("/users/me/referral-code", a.joinRule.GetMyReferralCode)
+			approved.GET("/users/me/training", a.feature("coaching"), a.coaching.GetMyProgress)
+
+			// Streaks, monthly recap and the weekly fastest-RSVP board
+			approved.GET("/stats/recap", a.stats.GetRecap)
+
+			// Public preview link for advertising a session
+			approved.GET("/sessions/:id/share", a.share.ShareSession)
+
+			// Court maps, draws and other files for a session
+			approved.GET("/sessions/:id/attachments/:attachmentId", a.session.DownloadAttachment)
+
+			// Printable sheet; admins and the session organizer only
+			approved.GET("/sessions/:id/sheet", a.session.SessionSheet)
+
+			// RSVPs across sessions
+			approved.GET("/rsvps/me", a.rsvp.GetMyRSVPs)
+
+			// Offline delta sync
+			approved.GET("/sync", a.sync.GetChanges)
+
+			// Session comments
+			approved.GET("/sessions/:id/comments", a.comment.ListComments)
+			approved.POST("/sessions/:id/comments", a.comment.CreateComment)
+			approved.GET("/sessions/:id/comment-notifications", a.comment.GetCommentNotifications)
+			approved.PUT("/sessions/:id/comment-notifications", a.comment.UpdateCommentNotifications)
+			approved.DELETE("/sessions/:id/comment-notifications", a.comment.ResetCommentNotifications)
+			approved.DELETE("/comments/:commentId", a.comment.DeleteComment)
+			approved.POST("/comments/:commentId/report", a.comment.ReportComment)
+			approved.POST("/users/:id/avatar/report", a.avatar.ReportAvatar)
+
+			// Live court board
+			approved.GET("/sessions/:id/courts", a.court.GetBoard)
+
+			// Carpools; confirming and declining riders is for the driver
+			approved.GET("/sessions/:id/carpool", a.feature("carpools"), a.carpool.GetCarpool)
+			approved.PUT("/sessions/:id/carpool/offer", a.feature("carpools"), a.carpool.SaveOffer)
+			approved.DELETE("/sessions/:id/carpool/offer", a.feature("carpools"), a.carpool.DeleteOffer)
+			approved.PUT("/sessions/:id/carpool/request", a.feature("carpools"), a.carpool.SaveRequest)
+			approved.DELETE("/sessions/:id/carpool/request", a.feature("carpools"), a.carpool.DeleteRequest)
+			approved.POST("/sessions/:id/carpool/requests/:requestId/confirm", a.feature("carpools"), a.carpool.ConfirmRider)
+			approved.POST("/sessions/:id/carpool/requests/:requestId/decline", a.feature("carpools"), a.carpool.DeclineRider)
+
+			// Announcements and acknowledgements
+			approved.GET("/announcements", a.announcement.ListAnnouncements)
+			approved.POST("/announcements/:id/acknowledge", a.announcement.AcknowledgeAnnouncement)
+
+			// Club documents
+			approved.GET("/documents", a.document.ListDocuments)
+			approved.GET("/documents/:id/download", a.document.DownloadDocument)
+			approved.POST("/documents/:id/acknowledge", a.document.AcknowledgeDocument)
+
+			// Who sits on the committee
+			approved.GET("/committee", a.committee.ListCommittee)
+
+			// Incident reports; filed by admins and session organizers
+			approved.POST("/sessions/:id/incidents", a.incident.CreateIncident)
+			approved.GET("/incidents/:id", a.incident.GetIncident)
+			approved.POST("/incidents/:id/attachments", a.incident.UploadAttachment)
+			approved.GET("/incidents/:id/attachments/:attachmentId", a.incident.DownloadAttachment)
+
+			// Equipment taken to a session; by admins and session organizers
+			approved.GET("/sessions/:id/equipment", a.inventory.GetSessionEquipment)
+			approved.POST("/sessions/:id/equipment", a.inventory.CheckOutEquipment)
+			approved.POST("/sessions/:id/equipment/:checkoutId/return", a.inventory.CheckInEquipment)
+
+			// Tournament routes
+			approved.GET("/tournaments", a.feature("tournaments"), a.tournament.ListTournaments)
+			approved.GET("/tournaments/:id", a.feature("tournaments"), a.tournament.GetTournament)
+			approved.GET("/tournaments/:id/standings", a.feature("tournaments"), a.tournament.GetStandings)
+			approved.POST("/tournaments/:id/register", a.feature("tournaments"), a.tournament.Register)
+			approved.DELETE("/tournaments/:id/register", a.feature("tournaments"), a.tournament.Withdraw)
+
+			// Group orders for club shirts, shuttles and the like
+			approved.GET("/orders", a.feature("orders"), a.order.ListWindows)
+			approved.GET("/orders/:id", a.feature("orders"), a.order.GetWindow)
+			approved.PUT("/orders/:id/my-order", a.feature("orders"), a.order.SubmitOrder)
+			approved.DELETE("/orders/:id/my-order", a.feature("orders"), a.order.CancelOrder)
+
+			// Training program, for coaches and admins
+			coaching := approved.Group("/coaching")
+			coaching.Use(a.feature("coaching"), middleware.RequireCoach())
+			{
+				coaching.GET("/sessions", a.coaching.ListSessions)
+				coaching.PUT("/sessions/:id/curriculum", a.coaching.UpdateCurriculum)
+				coaching.GET("/sessions/:id/attendance", a.coaching.GetAttendance)
+				coaching.POST("/sessions/:id/attendance", a.coaching.RecordAttendance)
+				coaching.GET("/players", a.coaching.ListPlayers)
+				coaching.GET("/players/:id/progress", a.coaching.GetPlayerProgress)
+				coaching.PUT("/players/:id/goals", a.coaching.UpdatePlayerGoals)
+				coaching.POST("/players/:id/assessments", a.coaching.RecordAssessment)
+				coaching.DELETE("/assessments/:id", a.coaching.DeleteAssessment)
+			}
+
+			// Committee minutes, papers and announcements, for the committee and admins
+			committee := approved.Group("/committee")
+			committee.Use(middleware.RequireCommittee())
+			{
+				committee.GET("/minutes", a.committee.ListMinutes)
+				committee.POST("/minutes", a.committee.CreateMinutes)
+				committee.GET("/minutes/:id", a.committee.GetMinutes)
+				committee.PUT("/minutes/:id", a.committee.UpdateMinutes)
+				committee.DELETE("/minutes/:id", a.committee.DeleteMinutes)
+				committee.GET("/documents", a.document.ListCommitteeDocuments)
+				committee.POST("/documents", a.document.UploadCommitteeDocument)
+				committee.DELETE("/documents/:id", a.document.DeleteCommitteeDocument)
+				committee.POST("/announcements", a.committee.SendAnnouncement)
+			}
+		}
+
+		requireAdmin := []gin.HandlerFunc{
+			a.middleware.AdminIPAllowlist,
+			middleware.RequireAdmin(),
+			a.middleware.RecentMFA,
+		}
+
+		// Running group orders, alongside the member routes under /orders
+		orderAdmin := protected.Group("/orders")
+		orderAdmin.Use(a.feature("orders"))
+		orderAdmin.Use(requireAdmin...)
+		{
+			orderAdmin.POST("", a.order.CreateWindow)
+			orderAdmin.PUT("/:id", a.order.UpdateWindow)
+			orderAdmin.POST("/:id/close", a.order.CloseWindow)
+			orderAdmin.GET("/:id/summary", a.order.GetSummary)
+			orderAdmin.GET("/:id/export", a.order.ExportOrders)
+		}
+
+		// Admin routes
+		admin := protected.Group("/admin")
+		admin.Use(requireAdmin...)
+		{
+			// Join requests
+			admin.GET("/join-requests", a.admin.ListJoinRequests)
+			admin.POST("/join-requests/:id/approve", a.admin.ApproveJoinRequest)
+			admin.POST("/join-requests/:id/reject", a.admin.RejectJoinRequest)
+
+			// Join request auto-approval
+			admin.GET("/join-rules", a.joinRule.GetJoinRules)
+			admin.PUT("/join-rules", a.joinRule.UpdateJoinRules)
+			admin.GET("/join-approvals", a.joinRule.ListJoinApprovals)
+			admin.GET("/referral-codes", a.joinRule.ListReferralCodes)
+			admin.POST("/referral-codes/:id/disable", a.joinRule.DisableReferralCode)
+			admin.POST("/referral-codes/:id/enable", a.joinRule.EnableReferralCode)
+			admin.GET("/preapproved-members", a.joinRule.ListPreapprovedMembers)
+			admin.POST("/preapproved-members", a.joinRule.ImportPreapprovedMembers)
+			admin.DELETE("/preapproved-members/:id", a.joinRule.DeletePreapprovedMember)
+
+			// Invite links
+			admin.GET("/invites", a.invite.ListInvites)
+			admin.POST("/invites", a.invite.CreateInvite)
+			admin.GET("/invites/:id", a.invite.GetInvite)
+			admin.POST("/invites/:id/revoke", a.invite.RevokeInvite)
+
+			// User management
+			admin.PUT("/users/:id/role", a.admin.UpdateUserRole)
+			admin.PUT("/users/:id/tier", a.admin.UpdateUserTier)
+			admin.PUT("/users/:id/committee-role", a.committee.UpdateCommitteeRole)
+			admin.POST("/users/:id/restore", a.inactivity.RestoreMember)
+
+			// Off-platform contact with members, and follow-ups
+			admin.GET("/users/:id/timeline", a.contactLog.GetUserTimeline)
+			admin.POST("/users/:id/contacts", a.contactLog.LogContact)
+			admin.PUT("/contacts/:id", a.contactLog.UpdateContact)
+			admin.DELETE("/contacts/:id", a.contactLog.DeleteContact)
+			admin.POST("/contacts/:id/follow-up/done", a.contactLog.CompleteFollowUp)
+			admin.GET("/follow-ups", a.contactLog.ListFollowUps)
+
+			// Inactive members awaiting review for archival
+			admin.GET("/inactive-members", a.inactivity.ListInactiveMembers)
+			admin.POST("/inactive-members/:id/archive", a.inactivity.ArchiveMember)
+			admin.POST("/inactive-members/:id/keep", a.inactivity.KeepMember)
+
+			// Session management
+			admin.POST("/sessions", a.admin.CreateSession)
+			admin.POST("/sessions/preview-recurrence", a.admin.PreviewRecurrence)
+			admin.GET("/season-rollovers", a.seasonRollover.ListRollovers)
+			admin.POST("/season-rollovers/preview", a.seasonRollover.PreviewRollover)
+			admin.POST("/season-rollovers", a.seasonRollover.RollOverSeason)
+			admin.POST("/season-rollovers/:id/undo", a.seasonRollover.UndoRollover)
+			admin.PUT("/sessions/:id", a.admin.UpdateSession)
+			admin.DELETE("/sessions/:id", a.admin.DeleteSession)
+			admin.POST("/sessions/:id/cancel", a.admin.CancelSession)
+			admin.GET("/weather-proposals", a.weatherProposal.ListProposals)
+			admin.POST("/weather-proposals/:id/approve", a.weatherProposal.ApproveProposal)
+			admin.POST("/weather-proposals/:id/decline", a.weatherProposal.DeclineProposal)
+			admin.POST("/sessions/:id/merge/:otherId", a.admin.MergeSessions)
+			admin.PUT("/sessions/:id/usage", a.admin.RecordSessionUsage)
+			admin.POST("/sessions/:id/extend-deadline", a.admin.ExtendDeadline)
+			admin.GET("/sessions/:id/notes", a.admin.GetSessionNotes)
+			admin.GET("/sessions/:id/rsvp-timeline", a.admin.GetRSVPTimeline)
+			admin.GET("/sessions/:id/forecast", a.admin.GetSessionForecast)
+			admin.PUT("/sessions/:id/notes", a.admin.UpdateSessionNotes)
+			admin.POST("/sessions/:id/attachments", a.admin.UploadSessionAttachment)
+			admin.DELETE("/sessions/:id/attachments/:attachmentId", a.admin.DeleteSessionAttachment)
+			admin.GET("/sessions/:id/regulars", a.admin.GetSeriesRegulars)
+			admin.PUT("/sessions/:id/regulars", a.admin.SetSeriesRegulars)
+
+			// Admin RSVP management
+			admin.POST("/sessions/:id/rsvp/:userId", a.admin.AddPlayerRSVP)
+			admin.DELETE("/sessions/:id/rsvp/:userId", a.admin.RemovePlayerRSVP)
+			admin.POST("/sessions/:id/check-ins/:userId", a.admin.CheckInPlayer)
+			admin.GET("/sessions/:id/attendance", a.admin.GetSessionAttendance)
+			admin.POST("/sessions/:id/attendance/:userId", a.admin.RecordAttendance)
+
+			// RSVP requests on sessions that require approval
+			admin.GET("/sessions/:id/rsvp-requests", a.admin.ListRSVPRequests)
+			admin.POST("/sessions/:id/rsvp-requests/:userId/approve", a.admin.ApproveRSVPRequest)
+			admin.POST("/sessions/:id/rsvp-requests/:userId/decline", a.admin.DeclineRSVPRequest)
+			admin.GET("/sessions/:id/allocation", a.allocation.GetAllocation)
+
+			// Court assignments
+			admin.POST("/sessions/:id/courts/assign", a.court.AssignNextUp)
+			admin.PUT("/sessions/:id/courts/:court", a.court.AssignCourt)
+			admin.POST("/sessions/:id/courts/:court/release", a.court.ReleaseCourt)
+
+			// Reports
+			admin.GET("/reports/monthly", a.report.GetMonthlyReport)
+			admin.GET("/reports/attendance", a.report.GetAttendanceReport)
+			admin.GET("/reports/consumption", a.inventory.GetConsumptionReport)
+			admin.GET("/usage", a.usage.GetUsage)
+
+			// Equipment and consumables
+			admin.GET("/inventory", a.inventory.ListItems)
+			admin.POST("/inventory", a.inventory.CreateItem)
+			admin.PUT("/inventory/:id", a.inventory.UpdateItem)
+			admin.DELETE("/inventory/:id", a.inventory.DeleteItem)
+			admin.POST("/inventory/:id/adjust", a.inventory.AdjustItem)
+			admin.GET("/inventory/:id/movements", a.inventory.ListMovements)
+
+			// Club management
+			admin.PUT("/club", a.admin.UpdateClub)
+
+			// Club documents
+			admin.POST("/documents", a.document.UploadDocument)
+			admin.DELETE("/documents/:id", a.document.DeleteDocument)
+
+			// Incident reports
+			admin.GET("/incidents", a.incident.ListIncidents)
+			admin.POST("/incidents/:id/resolve", a.incident.ResolveIncident)
+
+			// Membership cards scanned at the door
+			admin.POST("/cards/verify", a.card.VerifyCard)
+
+			// Announcements
+			admin.POST("/announcements", a.notification.SendAnnouncement)
+			admin.GET("/announcements/acknowledgements", a.announcement.ListAcknowledgementSummaries)
+			admin.GET("/announcements/:id/acknowledgements", a.announcement.GetAcknowledgements)
+
+			// Notification wording
+			admin.GET("/message-templates", a.messageTemplate.ListMessageTemplates)
+			admin.PUT("/message-templates/:key", a.messageTemplate.UpdateMessageTemplate)
+			admin.DELETE("/message-templates/:key", a.messageTemplate.ResetMessageTemplate)
+			admin.POST("/message-templates/:key/preview", a.messageTemplate.PreviewMessageTemplate)
+
+			// Notification delivery log
+			admin.GET("/notifications", a.notification.ListNotificationLog)
+			admin.POST("/notifications/:id/resend", a.notification.ResendNotification)
+
+			// Comment moderation
+			admin.GET("/moderation/reports", a.comment.ListReports)
+			admin.POST("/moderation/reports/:id/resolve", a.comment.ResolveReport)
+
+			// Photo moderation
+			admin.GET("/moderation/avatar-reports", a.avatar.ListReports)
+			admin.POST("/moderation/avatar-reports/:id/resolve", a.avatar.ResolveReport)
+
+			// Tournaments
+			admin.POST("/tournaments", a.feature("tournaments"), a.tournament.CreateTournament)
+			admin.POST("/tournaments/:id/fixtures", a.feature("tournaments"), a.tournament.GenerateFixtures)
+			admin.POST("/tournaments/:id/matches/:matchId/result", a.feature("tournaments"), a.tournament.RecordResult)
+
+			// Second-admin approval for destructive actions
+			admin.GET("/pending-actions", a.admin.ListPendingActions)
+			admin.POST("/pending-actions/:id/approve", a.admin.ApprovePendingAction)
+			admin.POST("/pending-actions/:id/decline", a.admin.DeclinePendingAction)
+
+			// Scheduler job runs
+			admin.GET("/jobs", a.job.ListJobs)
+			admin.POST("/jobs/:name/run", a.job.RunJob)
+
+			// Domain event changelog
+			admin.GET("/events", a.event.ListEvents)
+
+			// Archived notifications and RSVPs
+			admin.GET("/archive", a.archive.GetStats)
+
+			// Whole-club export for moving to another deployment
+			admin.GET("/export", a.clubExport.Export)
+
+			// Runtime configuration
+			admin.GET("/config", a.config.GetConfig)
+			admin.POST("/config/reload", a.config.ReloadConfig)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ArchiveHandler struct {
+	archiveService ArchiveService
+}
+
+func NewArchiveHandler(archiveService ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{archiveService: archiveService}
+}
+
+// GetStats returns live and archived row counts for each archived table
+func (h *ArchiveHandler) GetStats(c *gin.Context) {
+	stats, err := h.archiveService.Stats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get archive stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
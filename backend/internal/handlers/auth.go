@@ -1,49 +1,201 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
 	"github.com/weekday-masters/backend/internal/services"
 )
 
 type AuthHandler struct {
-	userService *services.UserService
+	userService        UserService
+	accountLinkService AccountLinkService
+	joinRuleService    JoinRuleService
+	inviteService      InviteService
+	userInfo           UserInfoFunc
 }
 
-func NewAuthHandler(userService *services.UserService) *AuthHandler {
-	return &AuthHandler{userService: userService}
+// UserInfoFunc fetches the profile of the login an access token is for
+type UserInfoFunc func(ctx context.Context, accessToken string) (*middleware.Auth0UserInfo, error)
+
+// Auth0UserInfo fetches profiles from the userinfo endpoint of an Auth0 domain
+func Auth0UserInfo(domain string) UserInfoFunc {
+	return func(ctx context.Context, accessToken string) (*middleware.Auth0UserInfo, error) {
+		return middleware.GetAuth0UserInfo(ctx, domain, accessToken)
+	}
+}
+
+func NewAuthHandler(userService UserService, accountLinkService AccountLinkService, joinRuleService JoinRuleService, inviteService InviteService, userInfo UserInfoFunc) *AuthHandler {
+	return &AuthHandler{
+		userService:        userService,
+		accountLinkService: accountLinkService,
+		joinRuleService:    joinRuleService,
+		inviteService:      inviteService,
+		userInfo:           userInfo,
+	}
 }
 
 type AuthCallbackRequest struct {
-	Auth0ID        string `json:"auth0_id" binding:"required"`
-	Email          string `json:"email" binding:"required,email"`
-	Name           string `json:"name" binding:"required"`
-	ProfilePicture string `json:"profile_picture"`
+	ReferralCode string `json:"referral_code" binding:"max=20"` // a member's invite, for the join rules
+	InviteToken  string `json:"invite_token" binding:"max=64"`  // from an admin's invite link
 }
 
-// Callback handles user registration/login after Auth0 authentication
+// Callback handles user registration/login after Auth0 authentication.
+// Identity comes from the verified access token and Auth0's userinfo
+// endpoint; the body only carries an optional invite token and referral
+// code. A pending member joins with the invite, if any, and is approved
+// here when the invite or a join rule says so.
 func (h *AuthHandler) Callback(c *gin.Context) {
+	auth0ID := c.GetString("auth0ID")
+	accessToken := c.GetString("accessToken")
+
 	var req AuthCallbackRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	info, err := h.userInfo(c.Request.Context(), accessToken)
+	if err != nil {
+		log.Printf("Auth callback userinfo error: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to verify user profile"})
+		return
+	}
+	if info.Sub != auth0ID {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token subject mismatch"})
 		return
 	}
+	if info.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Auth0 profile has no email; request the email scope"})
+		return
+	}
+
+	name := info.Name
+	if name == "" {
+		name = info.Email
+	}
 
 	user, isNew, err := h.userService.CreateOrUpdateUser(services.CreateUserInput{
-		Auth0ID:        req.Auth0ID,
-		Email:          req.Email,
-		Name:           req.Name,
-		ProfilePicture: req.ProfilePicture,
+		Auth0ID:        auth0ID,
+		Email:          info.Email,
+		EmailVerified:  info.EmailVerified,
+		Name:           name,
+		ProfilePicture: info.Picture,
 	})
 
+	if errors.Is(err, services.ErrAccountLinkRequired) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":         err.Error(),
+			"link_required": true,
+			"email":         info.Email,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create/update user"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"user":   user,
+	// An unusable invite doesn't stop the sign-in; the member just waits
+	// for an admin like anyone else
+	var inviteError string
+	if req.InviteToken != "" && user.MembershipStatus == models.MembershipPending {
+		use, err := h.inviteService.Accept(user.ID, req.InviteToken)
+		switch {
+		case err == nil:
+			if use.Approved {
+				if approved, err := h.userService.GetUserByID(user.ID); err == nil {
+					user = approved
+				}
+			}
+		case errors.Is(err, services.ErrInviteInvalid), errors.Is(err, services.ErrInviteExpired),
+			errors.Is(err, services.ErrAlreadyUsedInvite):
+			inviteError = err.Error()
+		default:
+			log.Printf("Failed to accept invite for %s: %v", user.ID, err)
+			inviteError = "Failed to use invite link"
+		}
+	}
+
+	if user.MembershipStatus == models.MembershipPending {
+		approval, err := h.joinRuleService.AutoApprove(user.ID, info.EmailVerified, req.ReferralCode)
+		if err != nil && !errors.Is(err, services.ErrReferralCodeInvalid) {
+			// Sign-in still works; the request waits for an admin
+			log.Printf("Failed to check join rules for %s: %v", user.ID, err)
+		}
+		if approval != nil {
+			if approved, err := h.userService.GetUserByID(user.ID); err == nil {
+				user = approved
+			}
+		}
+	}
+
+	response := gin.H{
+		"user":   dto.User(user, user),
 		"is_new": isNew,
-	})
+	}
+	if inviteError != "" {
+		response["invite_error"] = inviteError
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type RequestAccountLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// RequestAccountLink emails a code to the existing account the caller's new
+// login wants to claim. The response is the same whether or not the account
+// exists.
+func (h *AuthHandler) RequestAccountLink(c *gin.Context) {
+	var req RequestAccountLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.accountLinkService.RequestLink(c.Request.Context(), c.GetString("auth0ID"), req.Email); err != nil {
+		log.Printf("Account link request error: %v", err)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If that email belongs to a member, a code is on its way"})
+}
+
+type ConfirmAccountLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Code  string `json:"code" binding:"required"`
+}
+
+// ConfirmAccountLink moves the existing account onto the caller's login once
+// they enter the emailed code
+func (h *AuthHandler) ConfirmAccountLink(c *gin.Context) {
+	var req ConfirmAccountLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.accountLinkService.ConfirmLink(c.GetString("auth0ID"), req.Email, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidLinkCode):
+			respondError(c, http.StatusBadRequest, err)
+		case errors.Is(err, services.ErrLinkIdentityInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Printf("Account link confirm error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link account"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": dto.User(user, user)})
 }
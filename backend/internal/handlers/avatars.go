@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/storage"
+)
+
+type AvatarHandler struct {
+	avatarService AvatarService
+}
+
+func NewAvatarHandler(avatarService AvatarService) *AvatarHandler {
+	return &AvatarHandler{avatarService: avatarService}
+}
+
+// UploadMyAvatar sets the current user's photo. Expects a multipart "file",
+// a JPEG or PNG, which is cropped square and stored in each size.
+func (h *AvatarHandler) UploadMyAvatar(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, storage.Avatars.RequestLimit())
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required"})
+		return
+	}
+	if err := storage.Avatars.CheckSize(header.Size); err != nil {
+		respondError(c, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+		return
+	}
+	defer file.Close()
+
+	updated, err := h.avatarService.SetAvatar(c.Request.Context(), user.ID, header.Size, file)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.User(updated, user))
+}
+
+// DeleteMyAvatar removes the current user's photo
+func (h *AvatarHandler) DeleteMyAvatar(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.avatarService.RemoveAvatar(user.ID); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Photo removed"})
+}
+
+// ServeAvatar streams one size of a member's photo. It's public so image
+// tags can load it; the avatar ID in the path is only handed out with the
+// member, and stops working once the photo is replaced.
+func (h *AvatarHandler) ServeAvatar(c *gin.Context) {
+	avatarID, err := uuid.Parse(c.Param("avatarId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid avatar ID"})
+		return
+	}
+	size := models.AvatarSize(c.Param("size"))
+	if !size.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Size must be sm, md or lg"})
+		return
+	}
+
+	body, err := h.avatarService.OpenAvatar(c.Request.Context(), avatarID, size)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+	defer body.Close()
+
+	c.Header("Content-Type", "image/jpeg")
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, body)
+}
+
+type ReportAvatarRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ReportAvatar reports a member's photo to the admins
+func (h *AvatarHandler) ReportAvatar(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req ReportAvatarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Reason is optional, so we don't error if body is empty
+		req.Reason = ""
+	}
+
+	report, err := h.avatarService.ReportAvatar(userID, user.ID, req.Reason)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, avatarReportResponse(report, user))
+}
+
+// AvatarReportResponse is a photo report with the member and reporter
+type AvatarReportResponse struct {
+	models.AvatarReport
+	User     *dto.UserResponse `json:"user,omitempty"`
+	Reporter *dto.UserResponse `json:"reporter,omitempty"`
+}
+
+func avatarReportResponse(report *models.AvatarReport, viewer *models.User) AvatarReportResponse {
+	return AvatarReportResponse{
+		AvatarReport: *report,
+		User:         dto.User(report.User, viewer),
+		Reporter:     dto.User(report.Reporter, viewer),
+	}
+}
+
+// ListReports returns the open photo reports (admin only)
+func (h *AvatarHandler) ListReports(c *gin.Context) {
+	reports, err := h.avatarService.ListAvatarReports()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list reports"})
+		return
+	}
+
+	admin := currentUser(c)
+	response := make([]AvatarReportResponse, len(reports))
+	for i := range reports {
+		response[i] = avatarReportResponse(&reports[i], admin)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type ResolveAvatarReportRequest struct {
+	Action string `json:"action" binding:"required,oneof=delete dismiss"`
+	Note   string `json:"note"`
+}
+
+// ResolveReport deletes a reported photo or dismisses the report (admin only)
+func (h *AvatarHandler) ResolveReport(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return
+	}
+
+	var req ResolveAvatarReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := h.avatarService.ResolveAvatarReport(c.Request.Context(), reportID, user.ID, models.ModerationAction(req.Action), req.Note)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, avatarReportResponse(report, user))
+}
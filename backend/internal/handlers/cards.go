@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type CardHandler struct {
+	cardService CardService
+}
+
+func NewCardHandler(cardService CardService) *CardHandler {
+	return &CardHandler{cardService: cardService}
+}
+
+// GetMyCard returns the current member's membership card with its QR code
+func (h *CardHandler) GetMyCard(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	card, err := h.cardService.IssueCard(user)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, card)
+}
+
+type VerifyCardRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// VerifyCard checks a scanned membership card (admin only). Bad cards are
+// reported with valid false rather than an error status, so a scanner only
+// has to read the body.
+func (h *CardHandler) VerifyCard(c *gin.Context) {
+	var req VerifyCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := h.cardService.VerifyCard(req.Token)
+	if errors.Is(err, services.ErrCardsDisabled) {
+		respondError(c, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify card"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
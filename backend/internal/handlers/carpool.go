@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type CarpoolHandler struct {
+	carpoolService CarpoolService
+}
+
+func NewCarpoolHandler(carpoolService CarpoolService) *CarpoolHandler {
+	return &CarpoolHandler{carpoolService: carpoolService}
+}
+
+// CarpoolOfferResponse is a driver's offer and the riders in it
+type CarpoolOfferResponse struct {
+	ID           uuid.UUID                `json:"id"`
+	Driver       *dto.UserResponse        `json:"driver"`
+	OriginSuburb string                   `json:"origin_suburb"`
+	Seats        int                      `json:"seats"`
+	SeatsLeft    int                      `json:"seats_left"`
+	Notes        string                   `json:"notes"`
+	Riders       []CarpoolRequestResponse `json:"riders"`
+}
+
+// CarpoolRequestResponse is a rider's request for a lift
+type CarpoolRequestResponse struct {
+	ID           uuid.UUID                   `json:"id"`
+	Rider        *dto.UserResponse           `json:"rider"`
+	OriginSuburb string                      `json:"origin_suburb"`
+	Notes        string                      `json:"notes"`
+	Status       models.CarpoolRequestStatus `json:"status"`
+	OfferID      *uuid.UUID                  `json:"offer_id,omitempty"`
+	ConfirmedAt  *time.Time                  `json:"confirmed_at,omitempty"`
+}
+
+// CarpoolBoardResponse is a session's carpools for the current user
+type CarpoolBoardResponse struct {
+	Offers            []CarpoolOfferResponse   `json:"offers"`
+	OpenRequests      []CarpoolRequestResponse `json:"open_requests"`
+	MyOfferID         *uuid.UUID               `json:"my_offer_id,omitempty"`
+	MyRequest         *CarpoolRequestResponse  `json:"my_request,omitempty"`
+	SuggestedOffers   []CarpoolOfferResponse   `json:"suggested_offers"`
+	SuggestedRequests []CarpoolRequestResponse `json:"suggested_requests"`
+}
+
+func carpoolRequestResponse(request *models.CarpoolRequest, viewer *models.User) CarpoolRequestResponse {
+	return CarpoolRequestResponse{
+		ID:           request.ID,
+		Rider:        dto.User(request.Rider, viewer),
+		OriginSuburb: request.OriginSuburb,
+		Notes:        request.Notes,
+		Status:       request.Status,
+		OfferID:      request.OfferID,
+		ConfirmedAt:  request.ConfirmedAt,
+	}
+}
+
+func carpoolRequestResponses(requests []models.CarpoolRequest, viewer *models.User) []CarpoolRequestResponse {
+	response := make([]CarpoolRequestResponse, len(requests))
+	for i := range requests {
+		response[i] = carpoolRequestResponse(&requests[i], viewer)
+	}
+	return response
+}
+
+func carpoolOfferResponses(offers []services.CarpoolOfferView, viewer *models.User) []CarpoolOfferResponse {
+	response := make([]CarpoolOfferResponse, len(offers))
+	for i := range offers {
+		view := &offers[i]
+		response[i] = CarpoolOfferResponse{
+			ID:           view.Offer.ID,
+			Driver:       dto.User(view.Offer.Driver, viewer),
+			OriginSuburb: view.Offer.OriginSuburb,
+			Seats:        view.Offer.Seats,
+			SeatsLeft:    view.SeatsLeft,
+			Notes:        view.Offer.Notes,
+			Riders:       carpoolRequestResponses(view.Riders, viewer),
+		}
+	}
+	return response
+}
+
+// GetCarpool returns a session's carpools, with suggested matches for the
+// current user
+func (h *CarpoolHandler) GetCarpool(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	board, err := h.carpoolService.Board(id, user.ID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	response := CarpoolBoardResponse{
+		Offers:            carpoolOfferResponses(board.Offers, user),
+		OpenRequests:      carpoolRequestResponses(board.OpenRequests, user),
+		SuggestedOffers:   carpoolOfferResponses(board.SuggestedOffers, user),
+		SuggestedRequests: carpoolRequestResponses(board.SuggestedRequests, user),
+	}
+	if board.MyOffer != nil {
+		response.MyOfferID = &board.MyOffer.ID
+	}
+	if board.MyRequest != nil {
+		mine := carpoolRequestResponse(board.MyRequest, user)
+		response.MyRequest = &mine
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type SaveCarpoolOfferRequest struct {
+	OriginSuburb string `json:"origin_suburb" binding:"required,max=100"`
+	Seats        int    `json:"seats" binding:"required,min=1"`
+	Notes        string `json:"notes" binding:"max=1000"`
+}
+
+// SaveOffer offers seats to a session, or changes the current user's offer
+func (h *CarpoolHandler) SaveOffer(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req SaveCarpoolOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	suburb := strings.TrimSpace(req.OriginSuburb)
+	if suburb == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "origin_suburb is required"})
+		return
+	}
+
+	if _, err := h.carpoolService.SaveOffer(id, user.ID, services.SaveCarpoolOfferInput{
+		OriginSuburb: suburb,
+		Seats:        req.Seats,
+		Notes:        strings.TrimSpace(req.Notes),
+	}); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.GetCarpool(c)
+}
+
+// DeleteOffer withdraws the current user's offer
+func (h *CarpoolHandler) DeleteOffer(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	if err := h.carpoolService.DeleteOffer(c.Request.Context(), id, user.ID); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Offer withdrawn"})
+}
+
+type SaveCarpoolRideRequest struct {
+	OriginSuburb string  `json:"origin_suburb" binding:"required,max=100"`
+	Notes        string  `json:"notes" binding:"max=1000"`
+	OfferID      *string `json:"offer_id"` // the driver to ask; omit for any driver
+}
+
+// SaveRequest asks for a ride to a session, or changes the current user's
+// request
+func (h *CarpoolHandler) SaveRequest(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req SaveCarpoolRideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	suburb := strings.TrimSpace(req.OriginSuburb)
+	if suburb == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "origin_suburb is required"})
+		return
+	}
+	input := services.SaveCarpoolRequestInput{
+		OriginSuburb: suburb,
+		Notes:        strings.TrimSpace(req.Notes),
+	}
+	if req.OfferID != nil && *req.OfferID != "" {
+		offerID, err := uuid.Parse(*req.OfferID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+			return
+		}
+		input.OfferID = &offerID
+	}
+
+	if _, err := h.carpoolService.SaveRequest(c.Request.Context(), id, user.ID, input); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.GetCarpool(c)
+}
+
+// DeleteRequest withdraws the current user's request for a ride
+func (h *CarpoolHandler) DeleteRequest(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	if err := h.carpoolService.DeleteRequest(c.Request.Context(), id, user.ID); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Request withdrawn"})
+}
+
+// ConfirmRider gives a rider a seat in the current user's car
+func (h *CarpoolHandler) ConfirmRider(c *gin.Context) {
+	h.answerRider(c, true)
+}
+
+// DeclineRider turns a rider down, or drops a confirmed rider
+func (h *CarpoolHandler) DeclineRider(c *gin.Context) {
+	h.answerRider(c, false)
+}
+
+func (h *CarpoolHandler) answerRider(c *gin.Context, confirm bool) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+	requestID, err := uuid.Parse(c.Param("requestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
+	}
+
+	if confirm {
+		_, err = h.carpoolService.Confirm(c.Request.Context(), id, requestID, user.ID)
+	} else {
+		err = h.carpoolService.Decline(c.Request.Context(), id, requestID, user.ID)
+	}
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.GetCarpool(c)
+}
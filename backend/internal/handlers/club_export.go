@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/middleware"
+)
+
+type ClubExportHandler struct {
+	clubExportService ClubExportService
+}
+
+func NewClubExportHandler(clubExportService ClubExportService) *ClubExportHandler {
+	return &ClubExportHandler{clubExportService: clubExportService}
+}
+
+// Export downloads the whole club as a JSON bundle for `server import` on
+// another deployment. The bundle holds members' personal details in plain
+// text, so every export is recorded in the audit log.
+func (h *ClubExportHandler) Export(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	bundle, err := h.clubExportService.Export()
+	if err != nil {
+		log.Printf("Club export failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export club"})
+		return
+	}
+
+	if err := h.clubExportService.RecordExport(bundle, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record export"})
+		return
+	}
+
+	filename := fmt.Sprintf("club-export-%s.json", bundle.ExportedAt.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.JSON(http.StatusOK, bundle)
+}
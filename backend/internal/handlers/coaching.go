@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type CoachingHandler struct {
+	coachingService CoachingService
+}
+
+func NewCoachingHandler(coachingService CoachingService) *CoachingHandler {
+	return &CoachingHandler{coachingService: coachingService}
+}
+
+// GetMyProgress returns the current user's training record
+func (h *CoachingHandler) GetMyProgress(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.coachingService.Progress(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get training progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ListSessions returns the coach's training sessions (coaches only)
+func (h *CoachingHandler) ListSessions(c *gin.Context) {
+	coach, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessions, err := h.coachingService.ListTrainingSessions(coach)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list training sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Sessions(sessions, coach))
+}
+
+type UpdateCurriculumRequest struct {
+	CurriculumNotes string `json:"curriculum_notes" binding:"max=5000"`
+}
+
+// UpdateCurriculum sets what a training session covers (its coach only)
+func (h *CoachingHandler) UpdateCurriculum(c *gin.Context) {
+	coach, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req UpdateCurriculumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	session, err := h.coachingService.UpdateCurriculum(id, coach, strings.TrimSpace(req.CurriculumNotes))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Session(session, coach))
+}
+
+// TrainingRosterResponse is a player at a training session
+type TrainingRosterResponse struct {
+	User        *dto.UserResponse  `json:"user"`
+	RSVPStatus  *models.RSVPStatus `json:"rsvp_status"`
+	Waitlisted  bool               `json:"waitlisted"`
+	Attended    bool               `json:"attended"`
+	FromCheckIn bool               `json:"from_check_in"`
+}
+
+// GetAttendance returns a training session's players and who attended (its coach only)
+func (h *CoachingHandler) GetAttendance(c *gin.Context) {
+	coach, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	roster, err := h.coachingService.Roster(id, coach)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	response := make([]TrainingRosterResponse, len(roster))
+	for i := range roster {
+		entry := &roster[i]
+		response[i] = TrainingRosterResponse{
+			User:        dto.User(&entry.User, coach),
+			RSVPStatus:  entry.RSVPStatus,
+			Waitlisted:  entry.Waitlisted,
+			Attended:    entry.Attended,
+			FromCheckIn: entry.FromCheckIn,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type RecordAttendanceRequest struct {
+	UserIDs  []string `json:"user_ids" binding:"required,min=1,max=100"`
+	Attended *bool    `json:"attended"` // default true; false takes the mark off
+}
+
+// RecordAttendance marks who attended a training session (its coach only)
+func (h *CoachingHandler) RecordAttendance(c *gin.Context) {
+	coach, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req RecordAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	userIDs := make([]uuid.UUID, len(req.UserIDs))
+	for i, s := range req.UserIDs {
+		if userIDs[i], err = uuid.Parse(s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+	}
+	attended := req.Attended == nil || *req.Attended
+
+	if err := h.coachingService.RecordAttendance(id, coach, userIDs, attended); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.GetAttendance(c)
+}
+
+// TraineeResponse is a player with a training record
+type TraineeResponse struct {
+	User             *dto.UserResponse `json:"user"`
+	SessionsAttended int               `json:"sessions_attended"`
+	LastAttendedAt   *time.Time        `json:"last_attended_at,omitempty"`
+	LastAssessedAt   *time.Time        `json:"last_assessed_at,omitempty"`
+}
+
+// ListPlayers returns every player with a training record (coaches only)
+func (h *CoachingHandler) ListPlayers(c *gin.Context) {
+	trainees, err := h.coachingService.ListTrainees()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list players"})
+		return
+	}
+
+	viewer := currentUser(c)
+	response := make([]TraineeResponse, len(trainees))
+	for i := range trainees {
+		trainee := &trainees[i]
+		response[i] = TraineeResponse{
+			User:             dto.User(&trainee.User, viewer),
+			SessionsAttended: trainee.SessionsAttended,
+			LastAttendedAt:   trainee.LastAttendedAt,
+			LastAssessedAt:   trainee.LastAssessedAt,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPlayerProgress returns a player's training record (coaches only)
+func (h *CoachingHandler) GetPlayerProgress(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	report, err := h.coachingService.Progress(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get training progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+type UpdateGoalsRequest struct {
+	Goals string `json:"goals" binding:"max=2000"`
+}
+
+// UpdatePlayerGoals sets what a player is working towards (coaches only)
+func (h *CoachingHandler) UpdatePlayerGoals(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UpdateGoalsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	progress, err := h.coachingService.UpdateGoals(id, req.Goals)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+type RecordAssessmentRequest struct {
+	Skill      string     `json:"skill" binding:"required"`
+	Score      int        `json:"score" binding:"required"`
+	Notes      string     `json:"notes" binding:"max=2000"`
+	SessionID  string     `json:"session_id"`
+	AssessedAt *time.Time `json:"assessed_at"`
+}
+
+// RecordAssessment scores one of a player's skills (coaches only)
+func (h *CoachingHandler) RecordAssessment(c *gin.Context) {
+	coach, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req RecordAssessmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	input := services.SkillAssessmentInput{
+		Skill:      req.Skill,
+		Score:      req.Score,
+		Notes:      req.Notes,
+		AssessedAt: req.AssessedAt,
+	}
+	if req.SessionID != "" {
+		sessionID, err := uuid.Parse(req.SessionID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+			return
+		}
+		input.SessionID = &sessionID
+	}
+
+	assessment, err := h.coachingService.RecordAssessment(coach, id, input)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, assessment)
+}
+
+// DeleteAssessment removes an assessment made in error (its coach or an admin)
+func (h *CoachingHandler) DeleteAssessment(c *gin.Context) {
+	coach, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assessment ID"})
+		return
+	}
+
+	if err := h.coachingService.DeleteAssessment(id, coach); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Assessment deleted"})
+}
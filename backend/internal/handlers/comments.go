@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+type CommentHandler struct {
+	commentService    CommentService
+	moderationService ModerationService
+}
+
+func NewCommentHandler(commentService CommentService, moderationService ModerationService) *CommentHandler {
+	return &CommentHandler{
+		commentService:    commentService,
+		moderationService: moderationService,
+	}
+}
+
+// CommentResponse is a comment with its author as seen by the viewer
+type CommentResponse struct {
+	models.Comment
+	User *dto.UserResponse `json:"user,omitempty"`
+}
+
+func commentResponse(comment *models.Comment, viewer *models.User) *CommentResponse {
+	if comment == nil {
+		return nil
+	}
+	return &CommentResponse{Comment: *comment, User: dto.User(comment.User, viewer)}
+}
+
+// CommentReportResponse is a moderation report with its reporter and comment
+type CommentReportResponse struct {
+	models.CommentReport
+	Comment  *CommentResponse  `json:"comment,omitempty"`
+	Reporter *dto.UserResponse `json:"reporter,omitempty"`
+}
+
+func commentReportResponse(report *models.CommentReport, viewer *models.User) CommentReportResponse {
+	return CommentReportResponse{
+		CommentReport: *report,
+		Comment:       commentResponse(report.Comment, viewer),
+		Reporter:      dto.User(report.Reporter, viewer),
+	}
+}
+
+// ListComments returns the comments on a session
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	comments, err := h.commentService.ListComments(sessionID, user.IsAdmin())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list comments"})
+		return
+	}
+
+	response := make([]CommentResponse, len(comments))
+	for i := range comments {
+		response[i] = *commentResponse(&comments[i], user)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type CreateCommentRequest struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
+
+// CreateComment posts a comment on a session
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	comment, err := h.commentService.CreateComment(sessionID, user.ID, req.Body)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, commentResponse(comment, user))
+}
+
+// DeleteComment removes a comment (own comments, or any comment for admins)
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	commentID, err := uuid.Parse(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	if err := h.commentService.DeleteComment(commentID, user.ID, user.IsAdmin()); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted"})
+}
+
+// GetCommentNotifications returns which comments on a session the current
+// user is notified of
+func (h *CommentHandler) GetCommentNotifications(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	setting, err := h.commentService.GetNotificationSetting(sessionID, user.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+type UpdateCommentNotificationsRequest struct {
+	Level models.CommentNotificationLevel `json:"level" binding:"required,oneof=all mentions none"`
+}
+
+// UpdateCommentNotifications sets the current user's comment notification
+// level for a session, overriding their default
+func (h *CommentHandler) UpdateCommentNotifications(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req UpdateCommentNotificationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	setting, err := h.commentService.SetNotificationSetting(sessionID, user.ID, req.Level)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+// ResetCommentNotifications goes back to the current user's default comment
+// notification level for a session
+func (h *CommentHandler) ResetCommentNotifications(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	setting, err := h.commentService.ResetNotificationSetting(sessionID, user.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setting)
+}
+
+type ReportCommentRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ReportComment flags a comment for admin review
+func (h *CommentHandler) ReportComment(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	commentID, err := uuid.Parse(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var req ReportCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Reason is optional, so we don't error if body is empty
+		req.Reason = ""
+	}
+
+	report, err := h.moderationService.ReportComment(commentID, user.ID, req.Reason)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, commentReportResponse(report, user))
+}
+
+// ListReports returns the open moderation queue (admin only)
+func (h *CommentHandler) ListReports(c *gin.Context) {
+	reports, err := h.moderationService.ListOpenReports()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list reports"})
+		return
+	}
+
+	admin := currentUser(c)
+	response := make([]CommentReportResponse, len(reports))
+	for i := range reports {
+		response[i] = commentReportResponse(&reports[i], admin)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type ResolveReportRequest struct {
+	Action string `json:"action" binding:"required,oneof=hide delete warn dismiss"`
+	Note   string `json:"note"`
+}
+
+// ResolveReport applies a moderation action to a reported comment (admin only)
+func (h *CommentHandler) ResolveReport(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return
+	}
+
+	var req ResolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := h.moderationService.ResolveReport(c.Request.Context(), reportID, user.ID, models.ModerationAction(req.Action), req.Note)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, commentReportResponse(report, user))
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+type CommitteeHandler struct {
+	committeeService    CommitteeService
+	announcementService AnnouncementService
+	moderationService   ModerationService
+}
+
+func NewCommitteeHandler(committeeService CommitteeService, announcementService AnnouncementService, moderationService ModerationService) *CommitteeHandler {
+	return &CommitteeHandler{
+		committeeService:    committeeService,
+		announcementService: announcementService,
+		moderationService:   moderationService,
+	}
+}
+
+// ListCommittee returns who sits on the committee, for any member
+func (h *CommitteeHandler) ListCommittee(c *gin.Context) {
+	users, err := h.committeeService.ListCommittee()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list the committee"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Users(users, currentUser(c)))
+}
+
+type UpdateCommitteeRoleRequest struct {
+	CommitteeRole string `json:"committee_role" binding:"omitempty,oneof=president treasurer secretary member"`
+}
+
+// UpdateCommitteeRole gives a member a committee role, or an empty one to
+// take them off the committee (admin only)
+func (h *CommitteeHandler) UpdateCommitteeRole(c *gin.Context) {
+	admin, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req UpdateCommitteeRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := h.committeeService.SetCommitteeRole(id, models.CommitteeRole(req.CommitteeRole), admin.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.User(user, admin))
+}
+
+type MinutesRequest struct {
+	MeetingDate string `json:"meeting_date" binding:"required"` // YYYY-MM-DD
+	Title       string `json:"title" binding:"required,max=255"`
+	Body        string `json:"body" binding:"required"`
+}
+
+// bindMinutes reads a MinutesRequest, responding and returning false if it's invalid
+func bindMinutes(c *gin.Context) (services.MinutesInput, bool) {
+	var req MinutesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return services.MinutesInput{}, false
+	}
+	meetingDate, err := utils.ParseDateInSydney(req.MeetingDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return services.MinutesInput{}, false
+	}
+	return services.MinutesInput{MeetingDate: meetingDate, Title: req.Title, Body: req.Body}, true
+}
+
+// MinutesResponse is a meeting's minutes with their author as seen by the viewer
+type MinutesResponse struct {
+	models.CommitteeMinutes
+	Creator *dto.UserResponse `json:"creator,omitempty"`
+}
+
+func minutesResponse(minutes *models.CommitteeMinutes, viewer *models.User) MinutesResponse {
+	return MinutesResponse{CommitteeMinutes: *minutes, Creator: dto.User(minutes.Creator, viewer)}
+}
+
+// ListMinutes returns committee meeting minutes, most recent first
+func (h *CommitteeHandler) ListMinutes(c *gin.Context) {
+	minutes, err := h.committeeService.ListMinutes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list minutes"})
+		return
+	}
+
+	viewer := currentUser(c)
+	response := make([]MinutesResponse, len(minutes))
+	for i := range minutes {
+		response[i] = minutesResponse(&minutes[i], viewer)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMinutes returns one meeting's minutes
+func (h *CommitteeHandler) GetMinutes(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid minutes ID"})
+		return
+	}
+
+	minutes, err := h.committeeService.GetMinutes(id)
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, minutesResponse(minutes, currentUser(c)))
+}
+
+// CreateMinutes records a meeting's minutes
+func (h *CommitteeHandler) CreateMinutes(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	input, ok := bindMinutes(c)
+	if !ok {
+		return
+	}
+
+	minutes, err := h.committeeService.CreateMinutes(input, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save minutes"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, minutesResponse(minutes, user))
+}
+
+// UpdateMinutes corrects a meeting's minutes; only their author, the
+// secretary or an admin can
+func (h *CommitteeHandler) UpdateMinutes(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid minutes ID"})
+		return
+	}
+
+	input, ok := bindMinutes(c)
+	if !ok {
+		return
+	}
+
+	minutes, err := h.committeeService.UpdateMinutes(id, input, user)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, minutesResponse(minutes, user))
+}
+
+// DeleteMinutes removes a meeting's minutes; only their author, the
+// secretary or an admin can
+func (h *CommitteeHandler) DeleteMinutes(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid minutes ID"})
+		return
+	}
+
+	if err := h.committeeService.DeleteMinutes(id, user); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Minutes deleted"})
+}
+
+type CommitteeAnnouncementRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Body        string `json:"body" binding:"required"`
+	RequiresAck bool   `json:"requires_ack"`
+}
+
+// SendAnnouncement sends an announcement to the committee and admins only.
+// It's moderated like any announcement but not counted against the limit.
+func (h *CommitteeHandler) SendAnnouncement(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req CommitteeAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.moderationService.CheckText(req.Title, req.Body); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	announcement, err := h.announcementService.SendCommitteeAnnouncement(context.Background(), req.Title, req.Body, req.RequiresAck, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send announcement"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, AnnouncementView{Announcement: *announcement, Creator: dto.User(announcement.Creator, user)})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/config"
+)
+
+type ConfigHandler struct {
+	live *config.Live
+}
+
+func NewConfigHandler(live *config.Live) *ConfigHandler {
+	return &ConfigHandler{live: live}
+}
+
+// GetConfig returns the currently applied reloadable settings
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.live.Current())
+}
+
+// ReloadConfig re-reads the reloadable settings and applies them if valid
+func (h *ConfigHandler) ReloadConfig(c *gin.Context) {
+	if err := h.live.Apply(config.LoadReloadable()); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.live.Current())
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type ContactLogHandler struct {
+	contactLogService ContactLogService
+}
+
+func NewContactLogHandler(contactLogService ContactLogService) *ContactLogHandler {
+	return &ContactLogHandler{contactLogService: contactLogService}
+}
+
+// ContactLogResponse is a contact log entry with the people involved
+type ContactLogResponse struct {
+	models.ContactLog
+	User     *dto.UserResponse `json:"user,omitempty"`
+	Logger   *dto.UserResponse `json:"logger,omitempty"`
+	Assignee *dto.UserResponse `json:"assignee,omitempty"`
+}
+
+// AuditLogResponse is an admin change with the admin who made it
+type AuditLogResponse struct {
+	models.AuditLog
+	Actor *dto.UserResponse `json:"actor,omitempty"`
+}
+
+// TimelineEntryResponse is a contact or an admin change on a member's timeline
+type TimelineEntryResponse struct {
+	Kind    string              `json:"kind"` // "contact" or "audit"
+	At      time.Time           `json:"at"`
+	Contact *ContactLogResponse `json:"contact,omitempty"`
+	Audit   *AuditLogResponse   `json:"audit,omitempty"`
+}
+
+func contactLogResponse(entry *models.ContactLog, viewer *models.User) *ContactLogResponse {
+	return &ContactLogResponse{
+		ContactLog: *entry,
+		User:       dto.User(entry.User, viewer),
+		Logger:     dto.User(entry.Logger, viewer),
+		Assignee:   dto.User(entry.Assignee, viewer),
+	}
+}
+
+type ContactLogRequest struct {
+	Method      string     `json:"method" binding:"required,oneof=phone email sms in_person other"`
+	Subject     string     `json:"subject" binding:"required,max=255"`
+	Notes       string     `json:"notes" binding:"max=5000"`
+	ContactedAt *time.Time `json:"contacted_at"` // now if omitted
+	FollowUpAt  *time.Time `json:"follow_up_at"`
+	AssignedTo  *uuid.UUID `json:"assigned_to"` // the logging admin if omitted
+}
+
+// bindContactLog reads a ContactLogRequest, responding and returning false if it's invalid
+func bindContactLog(c *gin.Context) (services.ContactLogInput, bool) {
+	var req ContactLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return services.ContactLogInput{}, false
+	}
+	return services.ContactLogInput{
+		Method:      models.ContactMethod(req.Method),
+		Subject:     req.Subject,
+		Notes:       req.Notes,
+		ContactedAt: req.ContactedAt,
+		FollowUpAt:  req.FollowUpAt,
+		AssignedTo:  req.AssignedTo,
+	}, true
+}
+
+// GetUserTimeline returns a member's contact log and the admin changes made
+// to their account, newest first
+func (h *ContactLogHandler) GetUserTimeline(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	timeline, err := h.contactLogService.UserTimeline(id)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	admin := currentUser(c)
+	response := make([]TimelineEntryResponse, len(timeline))
+	for i, entry := range timeline {
+		response[i] = TimelineEntryResponse{Kind: entry.Kind, At: entry.At}
+		if entry.Contact != nil {
+			response[i].Contact = contactLogResponse(entry.Contact, admin)
+		}
+		if entry.Audit != nil {
+			response[i].Audit = &AuditLogResponse{AuditLog: *entry.Audit, Actor: dto.User(entry.Audit.Actor, admin)}
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// LogContact records an off-platform contact with a member, optionally
+// with a follow-up
+func (h *ContactLogHandler) LogContact(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	input, ok := bindContactLog(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.contactLogService.LogContact(id, input, currentUser(c).ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, contactLogResponse(entry, currentUser(c)))
+}
+
+// UpdateContact corrects a contact log entry or reassigns its follow-up
+func (h *ContactLogHandler) UpdateContact(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	input, ok := bindContactLog(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.contactLogService.UpdateContact(id, input, currentUser(c).ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, contactLogResponse(entry, currentUser(c)))
+}
+
+// DeleteContact removes a contact log entry
+func (h *ContactLogHandler) DeleteContact(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	if err := h.contactLogService.DeleteContact(id); err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Contact deleted"})
+}
+
+// CompleteFollowUp marks a contact's follow-up done
+func (h *ContactLogHandler) CompleteFollowUp(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	entry, err := h.contactLogService.CompleteFollowUp(id, currentUser(c).ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, contactLogResponse(entry, currentUser(c)))
+}
+
+// ListFollowUps returns open follow-ups, soonest due first; ?mine=true
+// shows only the current admin's
+func (h *ContactLogHandler) ListFollowUps(c *gin.Context) {
+	var assignedTo *uuid.UUID
+	if c.Query("mine") == "true" {
+		assignedTo = &currentUser(c).ID
+	}
+
+	entries, err := h.contactLogService.OpenFollowUps(assignedTo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list follow-ups"})
+		return
+	}
+
+	admin := currentUser(c)
+	response := make([]ContactLogResponse, len(entries))
+	for i := range entries {
+		response[i] = *contactLogResponse(&entries[i], admin)
+	}
+	c.JSON(http.StatusOK, response)
+}
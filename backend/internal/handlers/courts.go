@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+type CourtHandler struct {
+	courtService CourtService
+}
+
+func NewCourtHandler(courtService CourtService) *CourtHandler {
+	return &CourtHandler{courtService: courtService}
+}
+
+// CourtBoardResponse is a session's courts and queue as seen by the viewer
+type CourtBoardResponse struct {
+	SessionID uuid.UUID             `json:"session_id"`
+	Courts    []CourtStatusResponse `json:"courts"`
+	Queue     []dto.UserResponse    `json:"queue"`
+}
+
+// CourtStatusResponse is who is playing on a court
+type CourtStatusResponse struct {
+	CourtNumber int                       `json:"court_number"`
+	Players     []CourtAssignmentResponse `json:"players"`
+}
+
+// CourtAssignmentResponse is a player's place on a court
+type CourtAssignmentResponse struct {
+	models.CourtAssignment
+	User *dto.UserResponse `json:"user,omitempty"`
+}
+
+func courtAssignmentResponses(assignments []models.CourtAssignment, viewer *models.User) []CourtAssignmentResponse {
+	response := make([]CourtAssignmentResponse, len(assignments))
+	for i := range assignments {
+		response[i] = CourtAssignmentResponse{CourtAssignment: assignments[i], User: dto.User(assignments[i].User, viewer)}
+	}
+	return response
+}
+
+// GetBoard returns the live court assignment board for a session
+func (h *CourtHandler) GetBoard(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	board, err := h.courtService.GetBoard(sessionID)
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	viewer := currentUser(c)
+	response := CourtBoardResponse{
+		SessionID: board.SessionID,
+		Courts:    make([]CourtStatusResponse, len(board.Courts)),
+		Queue:     dto.Users(board.Queue, viewer),
+	}
+	for i, court := range board.Courts {
+		response.Courts[i] = CourtStatusResponse{
+			CourtNumber: court.CourtNumber,
+			Players:     courtAssignmentResponses(court.Players, viewer),
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// AssignNextUp fills free courts with the next players in the queue (admin only)
+func (h *CourtHandler) AssignNextUp(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	assignments, err := h.courtService.AssignNextUp(c.Request.Context(), sessionID, user.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, courtAssignmentResponses(assignments, user))
+}
+
+type AssignCourtRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids" binding:"required,min=1"`
+}
+
+// AssignCourt puts specific players on a court (admin only)
+func (h *CourtHandler) AssignCourt(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	court, err := strconv.Atoi(c.Param("court"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid court number"})
+		return
+	}
+
+	var req AssignCourtRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	assignments, err := h.courtService.AssignPlayers(c.Request.Context(), sessionID, court, req.UserIDs, user.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, courtAssignmentResponses(assignments, user))
+}
+
+// ReleaseCourt frees a court when its game finishes (admin only)
+func (h *CourtHandler) ReleaseCourt(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	court, err := strconv.Atoi(c.Param("court"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid court number"})
+		return
+	}
+
+	if err := h.courtService.ReleaseCourt(sessionID, court); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to release court"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Court released"})
+}
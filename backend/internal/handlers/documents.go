@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/storage"
+)
+
+type DocumentHandler struct {
+	documentService DocumentService
+}
+
+func NewDocumentHandler(documentService DocumentService) *DocumentHandler {
+	return &DocumentHandler{documentService: documentService}
+}
+
+// ListDocuments returns club documents with the current user's acknowledgements
+func (h *DocumentHandler) ListDocuments(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	docs, err := h.documentService.ListDocuments(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list documents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, docs)
+}
+
+// DownloadDocument streams a document's file
+func (h *DocumentHandler) DownloadDocument(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	doc, body, err := h.documentService.OpenDocument(c.Request.Context(), id, user)
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+	defer body.Close()
+
+	c.DataFromReader(http.StatusOK, doc.SizeBytes, doc.ContentType, body, map[string]string{
+		"Content-Disposition": fmt.Sprintf("inline; filename=%q", doc.FileName),
+	})
+}
+
+// AcknowledgeDocument records that the current user has read a document
+func (h *DocumentHandler) AcknowledgeDocument(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	ack, err := h.documentService.AcknowledgeDocument(id, user)
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ack)
+}
+
+type UploadDocumentRequest struct {
+	Title       string `form:"title" binding:"required,max=255"`
+	Description string `form:"description"`
+	Category    string `form:"category" binding:"omitempty,oneof=rules constitution induction other"`
+	Required    bool   `form:"required"`
+
+	CommitteeOnly bool `form:"committee_only"`
+}
+
+// UploadDocument stores a new club document (admin only). Expects a multipart
+// form with a "file" field holding the PDF.
+func (h *DocumentHandler) UploadDocument(c *gin.Context) {
+	h.upload(c, false)
+}
+
+// UploadCommitteeDocument stores a paper for the committee only, in the same
+// form as UploadDocument
+func (h *DocumentHandler) UploadCommitteeDocument(c *gin.Context) {
+	h.upload(c, true)
+}
+
+func (h *DocumentHandler) upload(c *gin.Context, committeeOnly bool) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, storage.Documents.RequestLimit())
+
+	var req UploadDocumentRequest
+	if err := c.ShouldBind(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A PDF file is required"})
+		return
+	}
+	if err := storage.Documents.CheckSize(header.Size); err != nil {
+		respondError(c, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+		return
+	}
+	defer file.Close()
+
+	category := models.DocumentCategory(req.Category)
+	if category == "" {
+		category = models.DocumentCategoryOther
+	}
+
+	doc, err := h.documentService.UploadDocument(c.Request.Context(), services.DocumentInput{
+		Title:       req.Title,
+		Description: req.Description,
+		Category:    category,
+		Required:    req.Required,
+		FileName:    header.Filename,
+
+		CommitteeOnly: committeeOnly || req.CommitteeOnly,
+		SizeBytes:     header.Size,
+	}, file, user.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, doc)
+}
+
+// DeleteDocument removes a club document (admin only)
+func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	if err := h.documentService.DeleteDocument(c.Request.Context(), id); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Document deleted"})
+}
+
+// ListCommitteeDocuments returns the committee's papers
+func (h *DocumentHandler) ListCommitteeDocuments(c *gin.Context) {
+	docs, err := h.documentService.ListCommitteeDocuments()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list committee documents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, docs)
+}
+
+// DeleteCommitteeDocument removes one of the committee's papers
+func (h *DocumentHandler) DeleteCommitteeDocument(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	if err := h.documentService.DeleteCommitteeDocument(c.Request.Context(), id); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Document deleted"})
+}
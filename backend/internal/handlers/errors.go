@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/storage"
+	"gorm.io/gorm"
+)
+
+// domainErrorStatuses maps each kind of services.DomainError, and the upload
+// errors of storage.UploadPolicy, to the status it's answered with
+var domainErrorStatuses = []struct {
+	kind   error
+	status int
+}{
+	{services.ErrNotFound, http.StatusNotFound},
+	{services.ErrForbidden, http.StatusForbidden},
+	{services.ErrDeadlinePassed, http.StatusConflict},
+	{services.ErrSessionFull, http.StatusConflict},
+	{services.ErrConflict, http.StatusConflict},
+	{services.ErrInvalid, http.StatusBadRequest},
+	{services.ErrRejected, http.StatusUnprocessableEntity},
+	{services.ErrUnavailable, http.StatusServiceUnavailable},
+	{storage.ErrTooLarge, http.StatusRequestEntityTooLarge},
+	{storage.ErrUnsupportedType, http.StatusUnsupportedMediaType},
+}
+
+// errorStatus picks the HTTP status for err. Errors the services haven't
+// typed get fallback, which is usually 400 for a failed action and 500 for
+// a failed read.
+func errorStatus(err error, fallback int) int {
+	for _, m := range domainErrorStatuses {
+		if errors.Is(err, m.kind) {
+			return m.status
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusNotFound
+	}
+	return fallback
+}
+
+// errorCode is the machine-readable code sent alongside the message: the
+// domain error's own code, or one derived from the status ("bad_request",
+// "not_found", ...)
+func errorCode(err error, status int) string {
+	var domainErr *services.DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+// respondError answers with err's message, its status and its error code.
+// Untyped errors answered 500 are internal (a database or storage failure,
+// say), so they're logged and the client gets a generic message instead.
+func respondError(c *gin.Context, fallback int, err error) {
+	status := errorStatus(err, fallback)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Request.Method, c.FullPath(), err)
+		message = "Something went wrong; please try again"
+	}
+	c.JSON(status, gin.H{"error": message, "code": errorCode(err, status)})
+}
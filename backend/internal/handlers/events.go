@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type EventHandler struct {
+	eventService EventService
+}
+
+func NewEventHandler(eventService EventService) *EventHandler {
+	return &EventHandler{eventService: eventService}
+}
+
+// eventEntry is a domain event with its data as JSON rather than a string
+type eventEntry struct {
+	models.DomainEvent
+	Data json.RawMessage `json:"data"`
+}
+
+// ListEvents returns the domain event changelog, oldest first. Filters:
+// ?type= (comma-separated, "rsvp.*" for a prefix), ?session_id=, ?user_id=,
+// ?actor_id=, ?since= and ?until= (RFC3339). Page on with ?after=<next_after>
+// (?limit=, default 100, max 500; admin only).
+func (h *EventHandler) ListEvents(c *gin.Context) {
+	filter := services.EventFilter{Limit: 100}
+	if t := c.Query("type"); t != "" {
+		filter.Types = strings.Split(t, ",")
+	}
+
+	ids := map[string]**uuid.UUID{
+		"session_id": &filter.SessionID,
+		"user_id":    &filter.UserID,
+		"actor_id":   &filter.ActorID,
+	}
+	for param, target := range ids {
+		if v := c.Query(param); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+				return
+			}
+			*target = &id
+		}
+	}
+
+	times := map[string]**time.Time{"since": &filter.Since, "until": &filter.Until}
+	for param, target := range times {
+		if v := c.Query(param); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " timestamp. Use RFC3339"})
+				return
+			}
+			*target = &parsed
+		}
+	}
+
+	if a := c.Query("after"); a != "" {
+		after, err := strconv.ParseInt(a, 10, 64)
+		if err != nil || after < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid after"})
+			return
+		}
+		filter.AfterSeq = after
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		filter.Limit = l
+	}
+
+	events, err := h.eventService.ListEvents(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list events"})
+		return
+	}
+
+	entries := make([]eventEntry, len(events))
+	nextAfter := filter.AfterSeq
+	for i, e := range events {
+		entries[i] = eventEntry{DomainEvent: e, Data: json.RawMessage(e.Data)}
+		nextAfter = e.Seq
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events":     entries,
+		"next_after": nextAfter,
+	})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listWithFields responds with a list, trimmed to the fields the client
+// asked for with ?fields=id,title,starts_at so a client on a slow connection
+// only downloads what it shows. Fields are the top-level JSON keys of each
+// item; id is always kept, and names the items don't have are ignored.
+// Without ?fields= the list goes out whole.
+func listWithFields(c *gin.Context, list interface{}) {
+	fields := requestedFields(c)
+	if fields == nil {
+		c.JSON(http.StatusOK, list)
+		return
+	}
+
+	raw, err := json.Marshal(list)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response"})
+		return
+	}
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		// Not a list of objects; nothing to trim
+		c.Data(http.StatusOK, "application/json; charset=utf-8", raw)
+		return
+	}
+	for _, item := range items {
+		for key := range item {
+			if !fields[key] {
+				delete(item, key)
+			}
+		}
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// requestedFields reads ?fields= as a set, or nil when it's absent or empty
+func requestedFields(c *gin.Context) map[string]bool {
+	var fields map[string]bool
+	for _, name := range strings.Split(c.Query("fields"), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if fields == nil {
+			fields = map[string]bool{"id": true}
+		}
+		fields[name] = true
+	}
+	return fields
+}
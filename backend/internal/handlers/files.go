@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/storage"
+)
+
+// FileHandler serves the presigned URLs of local-disk storage, which has no
+// server of its own. S3 and Cloud Storage URLs go straight to the bucket.
+type FileHandler struct {
+	store *storage.LocalStore
+}
+
+func NewFileHandler(store *storage.LocalStore) *FileHandler {
+	return &FileHandler{store: store}
+}
+
+// ServeFile streams an object for a link from LocalStore.PresignURL
+func (h *FileHandler) ServeFile(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	body, err := h.store.OpenSigned(c.Request.Context(), key, c.Query("expires"), c.Query("signature"))
+	if errors.Is(err, storage.ErrInvalidSignature) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer body.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, body)
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type GameHandler struct {
+	gameService GameService
+}
+
+func NewGameHandler(gameService GameService) *GameHandler {
+	return &GameHandler{gameService: gameService}
+}
+
+// GameResponse is a game with its players as seen by the viewer
+type GameResponse struct {
+	models.Game
+	Players []GamePlayerResponse `json:"players,omitempty"`
+}
+
+// GamePlayerResponse is one side's player in a game
+type GamePlayerResponse struct {
+	models.GamePlayer
+	User *dto.UserResponse `json:"user,omitempty"`
+}
+
+func gameResponse(game *models.Game, viewer *models.User) GameResponse {
+	players := make([]GamePlayerResponse, len(game.Players))
+	for i := range game.Players {
+		players[i] = GamePlayerResponse{GamePlayer: game.Players[i], User: dto.User(game.Players[i].User, viewer)}
+	}
+	return GameResponse{Game: *game, Players: players}
+}
+
+type RecordGameRequest struct {
+	TeamA      []uuid.UUID `json:"team_a" binding:"required,min=1,max=2"`
+	TeamB      []uuid.UUID `json:"team_b" binding:"required,min=1,max=2"`
+	TeamAScore *int        `json:"team_a_score" binding:"required"`
+	TeamBScore *int        `json:"team_b_score" binding:"required"`
+}
+
+// RecordGame records a casual game score for a session
+func (h *GameHandler) RecordGame(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req RecordGameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	game, err := h.gameService.RecordGame(services.RecordGameInput{
+		SessionID:  sessionID,
+		TeamA:      req.TeamA,
+		TeamB:      req.TeamB,
+		TeamAScore: *req.TeamAScore,
+		TeamBScore: *req.TeamBScore,
+		RecordedBy: user.ID,
+	})
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gameResponse(game, user))
+}
+
+// ListGames returns the games played in a session (the "games tonight" view)
+func (h *GameHandler) ListGames(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	games, err := h.gameService.ListSessionGames(sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list games"})
+		return
+	}
+
+	viewer := currentUser(c)
+	response := make([]GameResponse, len(games))
+	for i := range games {
+		response[i] = gameResponse(&games[i], viewer)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ConfirmGame confirms a game score recorded by another member
+func (h *GameHandler) ConfirmGame(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID"})
+		return
+	}
+
+	game, err := h.gameService.ConfirmGame(c.Request.Context(), sessionID, gameID, user.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gameResponse(game, user))
+}
+
+// GetMyRating returns the current user's Elo rating
+func (h *GameHandler) GetMyRating(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	rating, err := h.gameService.GetPlayerRating(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get rating"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rating)
+}
+
+type RatePlayersRequest struct {
+	// Levels maps players in the game to their level, 1 to 5
+	Levels map[uuid.UUID]int `json:"levels" binding:"required"`
+}
+
+// RatePlayers records the current user's private view of the other players'
+// levels in a confirmed game
+func (h *GameHandler) RatePlayers(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID"})
+		return
+	}
+
+	var req RatePlayersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	ratings, err := h.gameService.RatePlayers(sessionID, gameID, user.ID, req.Levels)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ratings)
+}
+
+// GetMyGameRatings returns the ratings the current user gave in a game
+func (h *GameHandler) GetMyGameRatings(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	gameID, err := uuid.Parse(c.Param("gameId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid game ID"})
+		return
+	}
+
+	ratings, err := h.gameService.MyRatings(gameID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get ratings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ratings)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/mail"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+// maxInboundEmailSize caps a posted reply; SendGrid itself allows 30 MB but
+// attachments aren't read, so anything this large is dropped
+const maxInboundEmailSize = 20 << 20
+
+type InboundEmailHandler struct {
+	inboundEmailService InboundEmailService
+}
+
+func NewInboundEmailHandler(inboundEmailService InboundEmailService) *InboundEmailHandler {
+	return &InboundEmailHandler{inboundEmailService: inboundEmailService}
+}
+
+// inboundEnvelope is the SMTP envelope SendGrid posts as JSON
+type inboundEnvelope struct {
+	To   []string `json:"to"`
+	From string   `json:"from"`
+}
+
+// ReceiveEmail takes replies to reminder emails from SendGrid Inbound Parse
+// (public, authenticated by the key in the webhook URL). Once the key checks
+// out it answers 200 whatever became of the reply, so SendGrid doesn't
+// retry it.
+func (h *InboundEmailHandler) ReceiveEmail(c *gin.Context) {
+	if !h.inboundEmailService.IsEnabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Email replies are not enabled"})
+		return
+	}
+	if !h.inboundEmailService.CheckWebhookKey(c.Query("key")) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook key"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxInboundEmailSize)
+	if err := c.Request.ParseMultipartForm(1 << 20); err != nil {
+		c.JSON(http.StatusOK, gin.H{"result": services.InboundIgnored})
+		return
+	}
+
+	email := services.InboundEmail{
+		From:    c.PostForm("from"),
+		Subject: c.PostForm("subject"),
+		Text:    c.PostForm("text"),
+	}
+	// The envelope has the address the reply was actually delivered to; the
+	// headers are the fallback
+	var envelope inboundEnvelope
+	if err := json.Unmarshal([]byte(c.PostForm("envelope")), &envelope); err == nil {
+		email.Recipients = append(email.Recipients, envelope.To...)
+	}
+	for _, header := range []string{"to", "cc"} {
+		if addresses, err := mail.ParseAddressList(c.PostForm(header)); err == nil {
+			for _, address := range addresses {
+				email.Recipients = append(email.Recipients, address.Address)
+			}
+		}
+	}
+
+	result, err := h.inboundEmailService.HandleReply(c.Request.Context(), email)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"result": result, "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
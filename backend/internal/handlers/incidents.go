@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/storage"
+)
+
+type IncidentHandler struct {
+	incidentService IncidentService
+}
+
+func NewIncidentHandler(incidentService IncidentService) *IncidentHandler {
+	return &IncidentHandler{incidentService: incidentService}
+}
+
+// IncidentResponse is an incident report with its session and reporter as
+// seen by the viewer
+type IncidentResponse struct {
+	models.Incident
+	Session  *dto.SessionResponse `json:"session,omitempty"`
+	Reporter *dto.UserResponse    `json:"reporter,omitempty"`
+}
+
+func incidentResponse(incident *models.Incident, viewer *models.User) IncidentResponse {
+	return IncidentResponse{
+		Incident: *incident,
+		Session:  dto.Session(incident.Session, viewer),
+		Reporter: dto.User(incident.Reporter, viewer),
+	}
+}
+
+type CreateIncidentRequest struct {
+	Type        string     `json:"type" binding:"required,oneof=injury facility other"`
+	Severity    string     `json:"severity" binding:"required,oneof=low medium high critical"`
+	Title       string     `json:"title" binding:"required,max=255"`
+	Description string     `json:"description" binding:"required,max=5000"`
+	OccurredAt  *time.Time `json:"occurred_at"`
+}
+
+// CreateIncident files an incident report for a session (admins and the session organizer)
+func (h *IncidentHandler) CreateIncident(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req CreateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	incident, err := h.incidentService.CreateIncident(sessionID, user, services.IncidentInput{
+		Type:        models.IncidentType(req.Type),
+		Severity:    models.IncidentSeverity(req.Severity),
+		Title:       req.Title,
+		Description: req.Description,
+		OccurredAt:  req.OccurredAt,
+	})
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, incidentResponse(incident, user))
+}
+
+// GetIncident returns an incident with its attachments (admins and the reporter)
+func (h *IncidentHandler) GetIncident(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+		return
+	}
+
+	incident, err := h.incidentService.GetIncident(id, user)
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, incidentResponse(incident, user))
+}
+
+// UploadAttachment adds a photo or PDF to an incident. Expects a multipart
+// form with a "file" field.
+func (h *IncidentHandler) UploadAttachment(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, storage.IncidentAttachments.RequestLimit())
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required"})
+		return
+	}
+	if err := storage.IncidentAttachments.CheckSize(header.Size); err != nil {
+		respondError(c, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.incidentService.AddAttachment(c.Request.Context(), id, user, header.Filename, header.Size, file)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DownloadAttachment streams an incident attachment
+func (h *IncidentHandler) DownloadAttachment(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+		return
+	}
+	attachmentID, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	attachment, body, err := h.incidentService.OpenAttachment(c.Request.Context(), id, attachmentID, user)
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+	defer body.Close()
+
+	c.Header("Cache-Control", "no-store")
+	c.DataFromReader(http.StatusOK, attachment.SizeBytes, attachment.ContentType, body, map[string]string{
+		"Content-Disposition": fmt.Sprintf("inline; filename=%q", attachment.FileName),
+	})
+}
+
+// ListIncidents returns incident reports, optionally filtered by ?status=open|resolved (admin only)
+func (h *IncidentHandler) ListIncidents(c *gin.Context) {
+	var status *models.IncidentStatus
+	if s := c.Query("status"); s != "" {
+		st := models.IncidentStatus(s)
+		if st != models.IncidentStatusOpen && st != models.IncidentStatusResolved {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open or resolved"})
+			return
+		}
+		status = &st
+	}
+
+	incidents, err := h.incidentService.ListIncidents(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list incidents"})
+		return
+	}
+
+	admin := currentUser(c)
+	response := make([]IncidentResponse, len(incidents))
+	for i := range incidents {
+		response[i] = incidentResponse(&incidents[i], admin)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type ResolveIncidentRequest struct {
+	ResolutionNotes string `json:"resolution_notes" binding:"required,max=5000"`
+}
+
+// ResolveIncident marks an incident as resolved (admin only)
+func (h *IncidentHandler) ResolveIncident(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+		return
+	}
+
+	var req ResolveIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	incident, err := h.incidentService.ResolveIncident(id, user.ID, req.ResolutionNotes)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, incidentResponse(incident, user))
+}
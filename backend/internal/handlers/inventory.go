@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type InventoryHandler struct {
+	inventoryService InventoryService
+}
+
+func NewInventoryHandler(inventoryService InventoryService) *InventoryHandler {
+	return &InventoryHandler{inventoryService: inventoryService}
+}
+
+// ListItems returns the club's inventory (admin only)
+func (h *InventoryHandler) ListItems(c *gin.Context) {
+	items, err := h.inventoryService.ListItems()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list inventory"})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+type SaveInventoryItemRequest struct {
+	Name              string                   `json:"name" binding:"required,max=100"`
+	Category          models.InventoryCategory `json:"category"`
+	Unit              string                   `json:"unit" binding:"max=50"`
+	Quantity          int                      `json:"quantity"` // opening stock; ignored on update
+	LowStockThreshold int                      `json:"low_stock_threshold"`
+	TracksShuttles    bool                     `json:"tracks_shuttles"`
+	Notes             string                   `json:"notes" binding:"max=2000"`
+}
+
+func (r *SaveInventoryItemRequest) input() services.SaveInventoryItemInput {
+	return services.SaveInventoryItemInput{
+		Name:              r.Name,
+		Category:          r.Category,
+		Unit:              r.Unit,
+		Quantity:          r.Quantity,
+		LowStockThreshold: r.LowStockThreshold,
+		TracksShuttles:    r.TracksShuttles,
+		Notes:             r.Notes,
+	}
+}
+
+// CreateItem adds an item to the inventory (admin only)
+func (h *InventoryHandler) CreateItem(c *gin.Context) {
+	admin, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req SaveInventoryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	item, err := h.inventoryService.CreateItem(c.Request.Context(), req.input(), admin.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.respondWithItem(c, item.ID, http.StatusCreated)
+}
+
+// UpdateItem changes an item's details; its quantity changes through
+// adjustments (admin only)
+func (h *InventoryHandler) UpdateItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	var req SaveInventoryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := h.inventoryService.UpdateItem(c.Request.Context(), id, req.input()); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.respondWithItem(c, id, http.StatusOK)
+}
+
+// DeleteItem removes an item and its history (admin only)
+func (h *InventoryHandler) DeleteItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	if err := h.inventoryService.DeleteItem(id); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item deleted"})
+}
+
+type AdjustInventoryRequest struct {
+	Change int                            `json:"change" binding:"required"`
+	Reason models.InventoryMovementReason `json:"reason" binding:"required"`
+	Note   string                         `json:"note" binding:"max=255"`
+}
+
+// AdjustItem restocks an item or corrects its quantity (admin only)
+func (h *InventoryHandler) AdjustItem(c *gin.Context) {
+	admin, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	var req AdjustInventoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := h.inventoryService.Adjust(c.Request.Context(), id, req.Change, req.Reason, req.Note, admin.ID); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	h.respondWithItem(c, id, http.StatusOK)
+}
+
+func (h *InventoryHandler) respondWithItem(c *gin.Context, id uuid.UUID, status int) {
+	item, err := h.inventoryService.GetItem(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get item"})
+		return
+	}
+	c.JSON(status, item)
+}
+
+// ListMovements returns an item's quantity changes, newest first
+// (?limit=, default 50; admin only)
+func (h *InventoryHandler) ListMovements(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+
+	movements, err := h.inventoryService.Movements(id, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list movements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, movements)
+}
+
+// GetConsumptionReport returns what was used and restocked each month
+// (?months=, default 6; admin only)
+func (h *InventoryHandler) GetConsumptionReport(c *gin.Context) {
+	months := 6
+	if m := c.Query("months"); m != "" {
+		parsed, err := strconv.Atoi(m)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid months"})
+			return
+		}
+		months = parsed
+	}
+
+	report, err := h.inventoryService.Consumption(months)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetSessionEquipment returns what's been checked out for a session, and
+// the inventory to check out from (admins and the session organizer)
+func (h *InventoryHandler) GetSessionEquipment(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	checkouts, err := h.inventoryService.SessionEquipment(id, user)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	items, err := h.inventoryService.ListItems()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list inventory"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"checkouts": checkouts, "items": items})
+}
+
+type CheckOutEquipmentRequest struct {
+	ItemID   string `json:"item_id" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+}
+
+// CheckOutEquipment takes some of an item to a session (admins and the
+// session organizer)
+func (h *InventoryHandler) CheckOutEquipment(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req CheckOutEquipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	itemID, err := uuid.Parse(req.ItemID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	checkout, err := h.inventoryService.CheckOut(id, itemID, req.Quantity, user)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, checkout)
+}
+
+type CheckInEquipmentRequest struct {
+	ReturnedQuantity *int `json:"returned_quantity"` // default all of it
+}
+
+// CheckInEquipment brings a checkout back; anything not returned comes off
+// the stock (admins and the session organizer)
+func (h *InventoryHandler) CheckInEquipment(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+	checkoutID, err := uuid.Parse(c.Param("checkoutId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid checkout ID"})
+		return
+	}
+
+	var req CheckInEquipmentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	checkout, err := h.inventoryService.CheckIn(c.Request.Context(), id, checkoutID, req.ReturnedQuantity, user)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checkout)
+}
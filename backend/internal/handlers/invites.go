@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type InviteHandler struct {
+	inviteService InviteService
+}
+
+func NewInviteHandler(inviteService InviteService) *InviteHandler {
+	return &InviteHandler{inviteService: inviteService}
+}
+
+// InviteResponse is an invite link as admins see it
+type InviteResponse struct {
+	models.Invite
+	URL    string              `json:"url"`
+	Usable bool                `json:"usable"`
+	UsedBy []InviteUseResponse `json:"used_by,omitempty"`
+}
+
+// InviteUseResponse is a member who joined with an invite
+type InviteUseResponse struct {
+	User     *dto.UserResponse `json:"user"`
+	Approved bool              `json:"approved"` // by the invite itself
+	UsedAt   time.Time         `json:"used_at"`
+}
+
+func (h *InviteHandler) inviteResponse(invite *models.Invite) InviteResponse {
+	return InviteResponse{
+		Invite: *invite,
+		URL:    h.inviteService.Link(invite),
+		Usable: invite.IsUsable(time.Now()),
+	}
+}
+
+type CreateInviteRequest struct {
+	Label       string    `json:"label" binding:"max=100"`
+	MaxUses     int       `json:"max_uses" binding:"required,min=1"`
+	ExpiresAt   time.Time `json:"expires_at" binding:"required"`
+	AutoApprove bool      `json:"auto_approve"`
+}
+
+// CreateInvite makes an invite link (admin only)
+func (h *InviteHandler) CreateInvite(c *gin.Context) {
+	admin, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	invite, err := h.inviteService.Create(services.CreateInviteInput{
+		Label:       req.Label,
+		MaxUses:     req.MaxUses,
+		ExpiresAt:   req.ExpiresAt,
+		AutoApprove: req.AutoApprove,
+	}, admin.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, h.inviteResponse(invite))
+}
+
+// ListInvites returns every invite link, newest first (admin only)
+func (h *InviteHandler) ListInvites(c *gin.Context) {
+	invites, err := h.inviteService.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list invites"})
+		return
+	}
+
+	response := make([]InviteResponse, len(invites))
+	for i := range invites {
+		response[i] = h.inviteResponse(&invites[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetInvite returns an invite link and who joined with it (admin only)
+func (h *InviteHandler) GetInvite(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite ID"})
+		return
+	}
+
+	invite, uses, err := h.inviteService.Get(id)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	viewer := currentUser(c)
+	response := h.inviteResponse(invite)
+	response.UsedBy = make([]InviteUseResponse, len(uses))
+	for i, use := range uses {
+		response.UsedBy[i] = InviteUseResponse{
+			User:     dto.User(use.User, viewer),
+			Approved: use.Approved,
+			UsedAt:   use.UsedAt,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeInvite stops an invite link working (admin only)
+func (h *InviteHandler) RevokeInvite(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite ID"})
+		return
+	}
+
+	invite, err := h.inviteService.Revoke(id)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.inviteResponse(invite))
+}
+
+// InvitePreviewResponse is what the sign-up page shows about an invite
+// before the visitor signs in
+type InvitePreviewResponse struct {
+	Label       string    `json:"label"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	AutoApprove bool      `json:"auto_approve"`
+	Usable      bool      `json:"usable"`
+}
+
+// PreviewInvite describes the invite behind a link (public)
+func (h *InviteHandler) PreviewInvite(c *gin.Context) {
+	invite, err := h.inviteService.Preview(c.Param("token"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, InvitePreviewResponse{
+		Label:       invite.Label,
+		ExpiresAt:   invite.ExpiresAt,
+		AutoApprove: invite.AutoApprove,
+		Usable:      invite.IsUsable(time.Now()),
+	})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type JobHandler struct {
+	jobService JobService
+	scheduler  SchedulerService
+}
+
+func NewJobHandler(jobService JobService, scheduler SchedulerService) *JobHandler {
+	return &JobHandler{jobService: jobService, scheduler: scheduler}
+}
+
+// ListJobs returns each scheduler job's latest state and recent runs
+// (?name= for one job, ?limit= runs, default 50)
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 500 {
+			limit = parsed
+		}
+	}
+
+	statuses, err := h.jobService.JobStatuses()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job statuses"})
+		return
+	}
+
+	runs, err := h.jobService.ListJobRuns(c.Query("name"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list job runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs": statuses,
+		"runs": runs,
+	})
+}
+
+// RunJob runs a scheduler job now. ?dry_run=true sends, creates and deletes
+// nothing and lists what the run would have done.
+func (h *JobHandler) RunJob(c *gin.Context) {
+	dryRun := false
+	if d := c.Query("dry_run"); d != "" {
+		parsed, err := strconv.ParseBool(d)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+		dryRun = parsed
+	}
+
+	report, err := h.scheduler.RunJob(c.Param("name"), dryRun)
+	if errors.Is(err, services.ErrUnknownJob) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Unknown job; available jobs are " + strings.Join(services.ManualJobs, ", "),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "report": report})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
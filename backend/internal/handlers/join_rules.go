@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type JoinRuleHandler struct {
+	joinRuleService JoinRuleService
+}
+
+func NewJoinRuleHandler(joinRuleService JoinRuleService) *JoinRuleHandler {
+	return &JoinRuleHandler{joinRuleService: joinRuleService}
+}
+
+// GetJoinRules returns the club's auto-approval rules (admin only)
+func (h *JoinRuleHandler) GetJoinRules(c *gin.Context) {
+	rules, err := h.joinRuleService.Rules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get join rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+type UpdateJoinRulesRequest struct {
+	// Off sends every join request to manual review
+	AutoApprove     *bool     `json:"auto_approve"`
+	EmailDomains    *[]string `json:"email_domains" binding:"omitempty,max=50"`
+	ReferralCodes   *bool     `json:"referral_codes"`
+	PreapprovedList *bool     `json:"preapproved_list"`
+}
+
+// UpdateJoinRules changes the club's auto-approval rules (admin only)
+func (h *JoinRuleHandler) UpdateJoinRules(c *gin.Context) {
+	admin, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req UpdateJoinRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	rules, err := h.joinRuleService.UpdateRules(services.JoinRulesInput{
+		AutoApprove:     req.AutoApprove,
+		EmailDomains:    req.EmailDomains,
+		ReferralCodes:   req.ReferralCodes,
+		PreapprovedList: req.PreapprovedList,
+	}, admin.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// JoinApprovalResponse is how a member's join request was approved
+type JoinApprovalResponse struct {
+	ID             uuid.UUID         `json:"id"`
+	User           *dto.UserResponse `json:"user"`
+	Rule           models.JoinRule   `json:"rule"`
+	Detail         string            `json:"detail"`
+	ReferralCodeID *uuid.UUID        `json:"referral_code_id,omitempty"`
+	ApprovedBy     *uuid.UUID        `json:"approved_by,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// ListJoinApprovals returns which rule, or admin, approved each member,
+// newest first (?rule=, ?limit= default 50, ?offset=; admin only)
+func (h *JoinRuleHandler) ListJoinApprovals(c *gin.Context) {
+	rule := models.JoinRule(c.Query("rule"))
+	switch rule {
+	case "", models.JoinRuleEmailDomain, models.JoinRuleReferralCode, models.JoinRulePreapproved, models.JoinRuleManual:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rule must be email_domain, referral_code, preapproved_list or manual"})
+		return
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+	offset := 0
+	if o, err := strconv.Atoi(c.Query("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	approvals, err := h.joinRuleService.ListApprovals(rule, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list join approvals"})
+		return
+	}
+
+	viewer := currentUser(c)
+	response := make([]JoinApprovalResponse, len(approvals))
+	for i, approval := range approvals {
+		response[i] = JoinApprovalResponse{
+			ID:             approval.ID,
+			User:           dto.User(approval.User, viewer),
+			Rule:           approval.Rule,
+			Detail:         approval.Detail,
+			ReferralCodeID: approval.ReferralCodeID,
+			ApprovedBy:     approval.ApprovedBy,
+			CreatedAt:      approval.CreatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ReferralCodeResponse is a member's referral code
+type ReferralCodeResponse struct {
+	ID         uuid.UUID         `json:"id"`
+	Code       string            `json:"code"`
+	Owner      *dto.UserResponse `json:"owner,omitempty"`
+	Uses       int               `json:"uses"`
+	DisabledAt *time.Time        `json:"disabled_at,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+func referralCodeResponse(code *models.ReferralCode, viewer *models.User) ReferralCodeResponse {
+	return ReferralCodeResponse{
+		ID:         code.ID,
+		Code:       code.Code,
+		Owner:      dto.User(code.Owner, viewer),
+		Uses:       code.Uses,
+		DisabledAt: code.DisabledAt,
+		CreatedAt:  code.CreatedAt,
+	}
+}
+
+// GetMyReferralCode returns the current member's code for inviting friends
+func (h *JoinRuleHandler) GetMyReferralCode(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	code, err := h.joinRuleService.MyReferralCode(user)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, referralCodeResponse(code, user))
+}
+
+type RedeemReferralCodeRequest struct {
+	Code string `json:"code" binding:"required,max=20"`
+}
+
+// RedeemReferralCode approves the current pending member with a friend's
+// referral code, when the join rules allow it
+func (h *JoinRuleHandler) RedeemReferralCode(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req RedeemReferralCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := h.joinRuleService.RedeemReferralCode(user.ID, req.Code); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Membership approved"})
+}
+
+// ListReferralCodes returns every member's referral code (admin only)
+func (h *JoinRuleHandler) ListReferralCodes(c *gin.Context) {
+	codes, err := h.joinRuleService.ListReferralCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list referral codes"})
+		return
+	}
+
+	viewer := currentUser(c)
+	response := make([]ReferralCodeResponse, len(codes))
+	for i := range codes {
+		response[i] = referralCodeResponse(&codes[i], viewer)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DisableReferralCode stops a referral code approving anyone (admin only)
+func (h *JoinRuleHandler) DisableReferralCode(c *gin.Context) {
+	h.setReferralCodeDisabled(c, true)
+}
+
+// EnableReferralCode lets a disabled referral code approve members again
+// (admin only)
+func (h *JoinRuleHandler) EnableReferralCode(c *gin.Context) {
+	h.setReferralCodeDisabled(c, false)
+}
+
+func (h *JoinRuleHandler) setReferralCodeDisabled(c *gin.Context, disabled bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid referral code ID"})
+		return
+	}
+
+	code, err := h.joinRuleService.SetReferralCodeDisabled(id, disabled)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, referralCodeResponse(code, currentUser(c)))
+}
+
+// ListPreapprovedMembers returns the imported member list (admin only)
+func (h *JoinRuleHandler) ListPreapprovedMembers(c *gin.Context) {
+	members, err := h.joinRuleService.ListPreapproved()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list pre-approved members"})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+type ImportPreapprovedMembersRequest struct {
+	Members []struct {
+		Email string `json:"email" binding:"required,max=255"`
+		Name  string `json:"name" binding:"max=255"`
+	} `json:"members" binding:"required,min=1,max=5000,dive"`
+}
+
+// ImportPreapprovedMembers adds members to the pre-approved list; emails
+// already on it are skipped (admin only)
+func (h *JoinRuleHandler) ImportPreapprovedMembers(c *gin.Context) {
+	admin, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req ImportPreapprovedMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	entries := make([]services.PreapprovedEntry, len(req.Members))
+	for i, member := range req.Members {
+		entries[i] = services.PreapprovedEntry{Email: member.Email, Name: member.Name}
+	}
+
+	added, err := h.joinRuleService.ImportPreapproved(entries, admin.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"added": added, "skipped": len(entries) - added})
+}
+
+// DeletePreapprovedMember takes an email off the pre-approved list (admin only)
+func (h *JoinRuleHandler) DeletePreapprovedMember(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entry ID"})
+		return
+	}
+
+	if err := h.joinRuleService.DeletePreapproved(id); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Entry removed"})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+type MemberInactivityHandler struct {
+	inactivityService MemberInactivityService
+}
+
+func NewMemberInactivityHandler(inactivityService MemberInactivityService) *MemberInactivityHandler {
+	return &MemberInactivityHandler{inactivityService: inactivityService}
+}
+
+// InactiveMemberResponse is a member awaiting inactivity review
+type InactiveMemberResponse struct {
+	User           *dto.UserResponse `json:"user"`
+	LastActiveAt   *time.Time        `json:"last_active_at,omitempty"`
+	NotifiedAt     *time.Time        `json:"notified_at"`
+	ReviewDueAt    time.Time         `json:"review_due_at"`
+	ReadyToArchive bool              `json:"ready_to_archive"`
+}
+
+// ListInactiveMembers returns the archival review queue
+func (h *MemberInactivityHandler) ListInactiveMembers(c *gin.Context) {
+	queue, err := h.inactivityService.ReviewQueue()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get inactive members"})
+		return
+	}
+
+	viewer := currentUser(c)
+	response := make([]InactiveMemberResponse, len(queue))
+	for i := range queue {
+		member := &queue[i]
+		response[i] = InactiveMemberResponse{
+			User:           dto.User(&member.User, viewer),
+			LastActiveAt:   member.LastActiveAt,
+			NotifiedAt:     member.User.InactiveNotifiedAt,
+			ReviewDueAt:    member.ReviewDueAt,
+			ReadyToArchive: member.ReadyToArchive,
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ArchiveMember archives a member whose grace period is over
+func (h *MemberInactivityHandler) ArchiveMember(c *gin.Context) {
+	h.review(c, h.inactivityService.Archive)
+}
+
+// KeepMember takes a member out of the queue and restarts their inactivity clock
+func (h *MemberInactivityHandler) KeepMember(c *gin.Context) {
+	h.review(c, h.inactivityService.KeepActive)
+}
+
+// RestoreMember makes an archived member an approved member again
+func (h *MemberInactivityHandler) RestoreMember(c *gin.Context) {
+	h.review(c, h.inactivityService.Restore)
+}
+
+func (h *MemberInactivityHandler) review(c *gin.Context, action func(userID, actorID uuid.UUID) (*models.User, error)) {
+	admin, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	user, err := action(id, admin.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.User(user, admin))
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type MessageTemplateHandler struct {
+	catalog MessageCatalogService
+}
+
+func NewMessageTemplateHandler(catalog MessageCatalogService) *MessageTemplateHandler {
+	return &MessageTemplateHandler{catalog: catalog}
+}
+
+type MessageTemplateRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// messageLanguage is the language in ?language=, English if it's left out
+func messageLanguage(c *gin.Context) models.Language {
+	return models.Language(c.DefaultQuery("language", string(models.DefaultLanguage)))
+}
+
+// ListMessageTemplates returns each notification message the club can
+// reword, once per language
+func (h *MessageTemplateHandler) ListMessageTemplates(c *gin.Context) {
+	messages, err := h.catalog.ListMessages()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list message templates"})
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+// PreviewMessageTemplate renders wording in ?language= with sample data
+// without saving it. Omitted title or body preview the current wording.
+func (h *MessageTemplateHandler) PreviewMessageTemplate(c *gin.Context) {
+	var req MessageTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	preview, err := h.catalog.PreviewMessage(models.MessageTemplateKey(c.Param("key")), messageLanguage(c), req.Title, req.Body)
+	if err != nil {
+		respondMessageTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// UpdateMessageTemplate saves the club's wording for a message in ?language=
+func (h *MessageTemplateHandler) UpdateMessageTemplate(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req MessageTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	msg, err := h.catalog.SetMessage(models.MessageTemplateKey(c.Param("key")), messageLanguage(c), req.Title, req.Body, user.ID)
+	if err != nil {
+		respondMessageTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, msg)
+}
+
+// ResetMessageTemplate goes back to the built-in wording for a message in
+// ?language=
+func (h *MessageTemplateHandler) ResetMessageTemplate(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.catalog.ResetMessage(models.MessageTemplateKey(c.Param("key")), messageLanguage(c), user.ID); err != nil {
+		respondMessageTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Message template reset to default"})
+}
+
+// respondMessageTemplateError reports an unknown key as 404 and anything
+// else, such as wording that doesn't render, as a bad request
+func respondMessageTemplateError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrUnknownMessage) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown message template"})
+		return
+	}
+	respondError(c, http.StatusBadRequest, err)
+}
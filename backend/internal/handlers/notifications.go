@@ -2,23 +2,40 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/dto"
 	"github.com/weekday-masters/backend/internal/middleware"
 	"github.com/weekday-masters/backend/internal/models"
 	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/utils"
 )
 
 type NotificationHandler struct {
-	notificationService *services.NotificationService
+	notificationService NotificationService
+	moderationService   ModerationService
+	announcementService AnnouncementService
+	announcementLimit   int
+	announcementWindow  time.Duration
 }
 
-func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
-	return &NotificationHandler{notificationService: notificationService}
+// NewNotificationHandler creates the handler. At most announcementLimit
+// announcements may be sent per announcementWindow without an override; 0
+// leaves them uncapped.
+func NewNotificationHandler(notificationService NotificationService, moderationService ModerationService, announcementService AnnouncementService, announcementLimit int, announcementWindow time.Duration) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		moderationService:   moderationService,
+		announcementService: announcementService,
+		announcementLimit:   announcementLimit,
+		announcementWindow:  announcementWindow,
+	}
 }
 
 // GetPreferences returns the current user's notification preferences
@@ -35,21 +52,102 @@ func (h *NotificationHandler) GetPreferences(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, prefs)
+	c.JSON(http.StatusOK, preferencesResponse(prefs))
 }
 
-// UpdatePreferencesRequest represents the request to update notification preferences
-type UpdatePreferencesRequest struct {
-	PushEnabled             *bool `json:"push_enabled,omitempty"`
-	PushSessionReminders    *bool `json:"push_session_reminders,omitempty"`
-	PushRSVPDeadlines       *bool `json:"push_rsvp_deadlines,omitempty"`
-	PushWaitlistUpdates     *bool `json:"push_waitlist_updates,omitempty"`
-	PushAdminAnnouncements  *bool `json:"push_admin_announcements,omitempty"`
-	EmailEnabled            *bool `json:"email_enabled,omitempty"`
+// V1Preferences are the per-type switches of the preferences API before
+// types. Older clients still send and read them, so each maps onto the
+// setting of one type on one channel.
+type V1Preferences struct {
+	PushSessionReminders   *bool `json:"push_session_reminders,omitempty"`
+	PushRSVPDeadlines      *bool `json:"push_rsvp_deadlines,omitempty"`
+	PushWaitlistUpdates    *bool `json:"push_waitlist_updates,omitempty"`
+	PushAdminAnnouncements *bool `json:"push_admin_announcements,omitempty"`
+	PushBadgeAwards        *bool `json:"push_badge_awards,omitempty"`
+	PushCourtAssignments   *bool `json:"push_court_assignments,omitempty"`
+
 	EmailSessionReminders   *bool `json:"email_session_reminders,omitempty"`
 	EmailRSVPDeadlines      *bool `json:"email_rsvp_deadlines,omitempty"`
 	EmailWaitlistUpdates    *bool `json:"email_waitlist_updates,omitempty"`
 	EmailAdminAnnouncements *bool `json:"email_admin_announcements,omitempty"`
+	EmailBadgeAwards        *bool `json:"email_badge_awards,omitempty"`
+}
+
+type v1PreferenceField struct {
+	value   **bool
+	typ     models.NotificationType
+	channel models.NotificationChannel
+}
+
+// fields pairs each switch with the type and channel it stands for
+func (p *V1Preferences) fields() []v1PreferenceField {
+	return []v1PreferenceField{
+		{&p.PushSessionReminders, models.NotificationSessionReminder, models.ChannelPush},
+		{&p.PushRSVPDeadlines, models.NotificationRSVPDeadline, models.ChannelPush},
+		{&p.PushWaitlistUpdates, models.NotificationWaitlistUpdate, models.ChannelPush},
+		{&p.PushAdminAnnouncements, models.NotificationAdminAnnouncement, models.ChannelPush},
+		{&p.PushBadgeAwards, models.NotificationBadgeAwarded, models.ChannelPush},
+		{&p.PushCourtAssignments, models.NotificationCourtAssignment, models.ChannelPush},
+		{&p.EmailSessionReminders, models.NotificationSessionReminder, models.ChannelEmail},
+		{&p.EmailRSVPDeadlines, models.NotificationRSVPDeadline, models.ChannelEmail},
+		{&p.EmailWaitlistUpdates, models.NotificationWaitlistUpdate, models.ChannelEmail},
+		{&p.EmailAdminAnnouncements, models.NotificationAdminAnnouncement, models.ChannelEmail},
+		{&p.EmailBadgeAwards, models.NotificationBadgeAwarded, models.ChannelEmail},
+	}
+}
+
+// PreferencesResponse is a member's notification preferences with the v1
+// switches read from their type settings
+type PreferencesResponse struct {
+	*models.UserNotificationPreferences
+	V1Preferences
+}
+
+func preferencesResponse(prefs *models.UserNotificationPreferences) *PreferencesResponse {
+	response := &PreferencesResponse{UserNotificationPreferences: prefs}
+	for _, f := range response.fields() {
+		enabled := prefs.Settings[f.typ][f.channel]
+		*f.value = &enabled
+	}
+	return response
+}
+
+// UpdatePreferencesRequest represents the request to update notification
+// preferences. Types turns individual notification types on or off per
+// channel, e.g. {"session_reminder": {"email": false}}; the v1 switches are
+// applied the same way, with types winning where both are sent.
+type UpdatePreferencesRequest struct {
+	PushEnabled           *bool                          `json:"push_enabled,omitempty"`
+	EmailEnabled          *bool                          `json:"email_enabled,omitempty"`
+	ReminderShowAttendees *bool                          `json:"reminder_show_attendees,omitempty"`
+	Types                 services.TypePreferenceUpdates `json:"types,omitempty"`
+
+	CommentNotifications *models.CommentNotificationLevel `json:"comment_notifications,omitempty" binding:"omitempty,oneof=all mentions none"`
+
+	V1Preferences
+}
+
+// typeUpdates merges the v1 switches into Types
+func (r *UpdatePreferencesRequest) typeUpdates() services.TypePreferenceUpdates {
+	types := services.TypePreferenceUpdates{}
+	for _, f := range r.fields() {
+		if *f.value == nil {
+			continue
+		}
+		if types[f.typ] == nil {
+			types[f.typ] = map[models.NotificationChannel]bool{}
+		}
+		types[f.typ][f.channel] = **f.value
+	}
+	for t, channels := range r.Types {
+		if types[t] == nil {
+			types[t] = map[models.NotificationChannel]bool{}
+		}
+		for channel, enabled := range channels {
+			types[t][channel] = enabled
+		}
+	}
+	return types
 }
 
 // UpdatePreferences updates the current user's notification preferences
@@ -62,7 +160,7 @@ func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
 
 	var req UpdatePreferencesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -71,46 +169,33 @@ func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
 	if req.PushEnabled != nil {
 		updates["push_enabled"] = *req.PushEnabled
 	}
-	if req.PushSessionReminders != nil {
-		updates["push_session_reminders"] = *req.PushSessionReminders
-	}
-	if req.PushRSVPDeadlines != nil {
-		updates["push_rsvp_deadlines"] = *req.PushRSVPDeadlines
-	}
-	if req.PushWaitlistUpdates != nil {
-		updates["push_waitlist_updates"] = *req.PushWaitlistUpdates
-	}
-	if req.PushAdminAnnouncements != nil {
-		updates["push_admin_announcements"] = *req.PushAdminAnnouncements
-	}
 	if req.EmailEnabled != nil {
 		updates["email_enabled"] = *req.EmailEnabled
 	}
-	if req.EmailSessionReminders != nil {
-		updates["email_session_reminders"] = *req.EmailSessionReminders
+	if req.ReminderShowAttendees != nil {
+		updates["reminder_show_attendees"] = *req.ReminderShowAttendees
 	}
-	if req.EmailRSVPDeadlines != nil {
-		updates["email_rsvp_deadlines"] = *req.EmailRSVPDeadlines
-	}
-	if req.EmailWaitlistUpdates != nil {
-		updates["email_waitlist_updates"] = *req.EmailWaitlistUpdates
-	}
-	if req.EmailAdminAnnouncements != nil {
-		updates["email_admin_announcements"] = *req.EmailAdminAnnouncements
+	if req.CommentNotifications != nil {
+		updates["comment_notifications"] = *req.CommentNotifications
 	}
 
-	if len(updates) == 0 {
+	types := req.typeUpdates()
+	if len(updates) == 0 && len(types) == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No preferences to update"})
 		return
 	}
 
-	prefs, err := h.notificationService.UpdateUserPreferences(user.ID, updates)
+	prefs, err := h.notificationService.UpdateUserPreferences(user.ID, updates, types)
+	if errors.Is(err, services.ErrInvalid) {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
 		return
 	}
 
-	c.JSON(http.StatusOK, prefs)
+	c.JSON(http.StatusOK, preferencesResponse(prefs))
 }
 
 // RegisterTokenRequest represents the request to register a push token
@@ -129,7 +214,7 @@ func (h *NotificationHandler) RegisterPushToken(c *gin.Context) {
 
 	var req RegisterTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -141,6 +226,72 @@ func (h *NotificationHandler) RegisterPushToken(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Push token registered successfully"})
 }
 
+// InitDeviceRequest is what a device sends when it starts up. Token is left
+// out when the user hasn't allowed push notifications on the device.
+type InitDeviceRequest struct {
+	Token        string `json:"token"`
+	DeviceName   string `json:"device_name"`
+	HistoryLimit int    `json:"history_limit"` // default 20, up to 100
+}
+
+// InitDeviceResponse is everything a device needs to show the user's
+// notifications
+type InitDeviceResponse struct {
+	PushTokenRegistered bool                  `json:"push_token_registered"`
+	Preferences         *PreferencesResponse  `json:"preferences"`
+	UnreadCount         int64                 `json:"unread_count"`
+	History             []models.Notification `json:"history"`
+}
+
+// InitDevice registers a device's push token and returns the user's
+// preferences, unread count and recent notifications, so a device can set
+// itself up in one round trip
+func (h *NotificationHandler) InitDevice(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req InitDeviceRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	limit := 20
+	if req.HistoryLimit > 0 && req.HistoryLimit <= 100 {
+		limit = req.HistoryLimit
+	}
+
+	var response InitDeviceResponse
+	if token := strings.TrimSpace(req.Token); token != "" {
+		if err := h.notificationService.RegisterPushToken(user.ID, token, req.DeviceName); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register push token"})
+			return
+		}
+		response.PushTokenRegistered = true
+	}
+
+	prefs, err := h.notificationService.GetUserPreferences(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification preferences"})
+		return
+	}
+	response.Preferences = preferencesResponse(prefs)
+	if response.UnreadCount, err = h.notificationService.CountUnread(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count unread notifications"})
+		return
+	}
+	if response.History, err = h.notificationService.GetUserNotifications(user.ID, services.NotificationFilter{}, limit, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // UnregisterTokenRequest represents the request to unregister a push token
 type UnregisterTokenRequest struct {
 	Token string `json:"token"`
@@ -165,7 +316,9 @@ func (h *NotificationHandler) UnregisterPushToken(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Push token unregistered successfully"})
 }
 
-// GetNotificationHistory returns the user's notification history
+// GetNotificationHistory returns the user's notification history. Supports
+// filtering by session_id, type, read, from and to (YYYY-MM-DD),
+// full-text search with q, and trimming each one with fields.
 func (h *NotificationHandler) GetNotificationHistory(c *gin.Context) {
 	user, err := middleware.GetUserFromContext(c)
 	if err != nil {
@@ -187,13 +340,51 @@ func (h *NotificationHandler) GetNotificationHistory(c *gin.Context) {
 		}
 	}
 
-	notifications, err := h.notificationService.GetUserNotifications(user.ID, limit, offset)
+	filter := services.NotificationFilter{Search: strings.TrimSpace(c.Query("q"))}
+	if sid := c.Query("session_id"); sid != "" {
+		parsed, err := uuid.Parse(sid)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+			return
+		}
+		filter.SessionID = &parsed
+	}
+	if t := c.Query("type"); t != "" {
+		notifType := models.NotificationType(t)
+		filter.Type = &notifType
+	}
+	if r := c.Query("read"); r != "" {
+		read, err := strconv.ParseBool(r)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "read must be true or false"})
+			return
+		}
+		filter.Read = &read
+	}
+	if f := c.Query("from"); f != "" {
+		parsed, err := utils.ParseDateInSydney(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date. Use YYYY-MM-DD"})
+			return
+		}
+		filter.From = &parsed
+	}
+	if t := c.Query("to"); t != "" {
+		parsed, err := utils.ParseDateInSydney(t)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date. Use YYYY-MM-DD"})
+			return
+		}
+		filter.To = &parsed
+	}
+
+	notifications, err := h.notificationService.GetUserNotifications(user.ID, filter, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications"})
 		return
 	}
 
-	c.JSON(http.StatusOK, notifications)
+	listWithFields(c, notifications)
 }
 
 // MarkNotificationRead marks a notification as read
@@ -218,13 +409,26 @@ func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
 }
 
-// SendAnnouncementRequest represents the request to send an admin announcement
+// SendAnnouncementRequest represents the request to send an admin announcement.
+// Override sends past the announcement cap, and must be confirmed. RequiresAck
+// asks members to confirm they've read it.
 type SendAnnouncementRequest struct {
-	Title string `json:"title" binding:"required"`
-	Body  string `json:"body" binding:"required"`
+	Title       string `json:"title" binding:"required"`
+	Body        string `json:"body" binding:"required"`
+	RequiresAck bool   `json:"requires_ack"`
+	Override    bool   `json:"override"`
+	Confirm     bool   `json:"confirm"`
+}
+
+// AnnouncementResponse is a sent announcement with the quota left afterwards
+type AnnouncementResponse struct {
+	AnnouncementView
+	Quota *services.AnnouncementQuota `json:"quota"`
 }
 
-// SendAnnouncement sends an announcement to all approved members (admin only)
+// SendAnnouncement sends an announcement to all approved members (admin only).
+// Once the club has sent its cap for the window it's refused with 429 unless
+// the admin overrides with confirmation.
 func (h *NotificationHandler) SendAnnouncement(c *gin.Context) {
 	user, err := middleware.GetUserFromContext(c)
 	if err != nil {
@@ -234,43 +438,149 @@ func (h *NotificationHandler) SendAnnouncement(c *gin.Context) {
 
 	var req SendAnnouncementRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	// Create announcement record
-	announcement := models.Announcement{
-		Title:     req.Title,
-		Body:      req.Body,
-		CreatedBy: user.ID,
+	if err := h.moderationService.CheckText(req.Title, req.Body); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	quota, err := h.announcementService.Quota(h.announcementLimit, h.announcementWindow)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check announcement quota"})
+		return
+	}
+	var overridden *services.AnnouncementQuota
+	if quota.Exceeded() {
+		if !req.Override {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": "The announcement limit for this period has been reached; send with override and confirm to send anyway",
+				"quota": quota,
+			})
+			return
+		}
+		if !req.Confirm {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Overriding the announcement limit requires confirm: true",
+				"quota": quota,
+			})
+			return
+		}
+		overridden = quota
 	}
-	if err := database.DB.Create(&announcement).Error; err != nil {
+
+	announcement, err := h.announcementService.SendAnnouncement(context.Background(), req.Title, req.Body, req.RequiresAck, user.ID, overridden)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create announcement"})
 		return
 	}
 
-	// Get all approved members
-	var members []models.User
-	if err := database.DB.Where("membership_status = ?", models.MembershipApproved).Find(&members).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get members"})
+	quota, err = h.announcementService.Quota(h.announcementLimit, h.announcementWindow)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check announcement quota"})
+		return
+	}
+	c.JSON(http.StatusCreated, AnnouncementResponse{AnnouncementView: AnnouncementView{Announcement: *announcement, Creator: dto.User(announcement.Creator, user)}, Quota: quota})
+}
+
+// NotificationLogEntry is a notification with its recipient, for the admin log
+type NotificationLogEntry struct {
+	models.Notification
+	Recipient *dto.UserResponse `json:"recipient"`
+}
+
+// ListNotificationLog returns notifications sent to any member (admin only).
+// Supports user_id, type, channel (push or email) and failed=true filters.
+func (h *NotificationHandler) ListNotificationLog(c *gin.Context) {
+	admin, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	var filter services.NotificationLogFilter
+	if uid := c.Query("user_id"); uid != "" {
+		parsed, err := uuid.Parse(uid)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		filter.UserID = &parsed
+	}
+	if t := c.Query("type"); t != "" {
+		notifType := models.NotificationType(t)
+		filter.Type = &notifType
+	}
+	switch ch := c.Query("channel"); ch {
+	case "", "push", "email", "sms":
+		filter.Channel = ch
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "channel must be push, email or sms"})
+		return
+	}
+	if f := c.Query("failed"); f != "" {
+		failed, err := strconv.ParseBool(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed must be true or false"})
+			return
+		}
+		filter.FailedOnly = failed
+	}
+
+	notifications, err := h.notificationService.ListNotificationLog(filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications"})
+		return
+	}
+
+	entries := make([]NotificationLogEntry, len(notifications))
+	for i := range notifications {
+		entries[i] = NotificationLogEntry{
+			Notification: notifications[i],
+			Recipient:    dto.User(notifications[i].User, admin),
+		}
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// ResendNotification retries undelivered channels of a notification (admin only)
+func (h *NotificationHandler) ResendNotification(c *gin.Context) {
+	admin, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Send notifications to all members
-	userIDs := make([]uuid.UUID, len(members))
-	for i, m := range members {
-		userIDs[i] = m.ID
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
 	}
 
-	ctx := context.Background()
-	h.notificationService.SendBulkNotification(
-		ctx,
-		userIDs,
-		models.NotificationAdminAnnouncement,
-		req.Title,
-		req.Body,
-		map[string]string{"type": "admin_announcement", "announcement_id": announcement.ID.String()},
-	)
+	notification, err := h.notificationService.ResendNotification(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
 
-	c.JSON(http.StatusCreated, announcement)
+	c.JSON(http.StatusOK, NotificationLogEntry{
+		Notification: *notification,
+		Recipient:    dto.User(notification.User, admin),
+	})
 }
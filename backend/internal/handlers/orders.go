@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type OrderHandler struct {
+	orderService OrderService
+}
+
+func NewOrderHandler(orderService OrderService) *OrderHandler {
+	return &OrderHandler{orderService: orderService}
+}
+
+// ListWindows returns every group order, open ones first
+func (h *OrderHandler) ListWindows(c *gin.Context) {
+	windows, err := h.orderService.ListWindows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list orders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, windows)
+}
+
+// GetWindow returns a group order with the current user's order in it
+func (h *OrderHandler) GetWindow(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	window, err := h.orderService.GetWindow(id)
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+	order, err := h.orderService.GetOrder(id, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get your order"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"window": window, "my_order": order})
+}
+
+type OrderLineRequest struct {
+	ItemID   string `json:"item_id" binding:"required"`
+	Option   string `json:"option"`
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+}
+
+type SubmitOrderRequest struct {
+	Lines []OrderLineRequest `json:"lines" binding:"required,min=1,dive"`
+	Notes string             `json:"notes" binding:"max=1000"`
+}
+
+// SubmitOrder places or replaces the current user's order
+func (h *OrderHandler) SubmitOrder(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	var req SubmitOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	lines := make([]services.OrderLineInput, len(req.Lines))
+	for i, line := range req.Lines {
+		itemID, err := uuid.Parse(line.ItemID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+		lines[i] = services.OrderLineInput{ItemID: itemID, Option: line.Option, Quantity: line.Quantity}
+	}
+
+	order, err := h.orderService.SubmitOrder(id, user.ID, lines, strings.TrimSpace(req.Notes))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// CancelOrder withdraws the current user's order
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	if err := h.orderService.CancelOrder(id, user.ID); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Order withdrawn"})
+}
+
+type OrderItemRequest struct {
+	Name       string   `json:"name" binding:"required,max=255"`
+	Options    []string `json:"options" binding:"max=30"`
+	PriceCents int      `json:"price_cents" binding:"min=0"`
+}
+
+type CreateOrderWindowRequest struct {
+	Title       string             `json:"title" binding:"required,max=255"`
+	Description string             `json:"description"`
+	ClosesAt    time.Time          `json:"closes_at" binding:"required"`
+	Items       []OrderItemRequest `json:"items" binding:"required,min=1,max=30,dive"`
+}
+
+// CreateWindow opens a group order (admin only)
+func (h *OrderHandler) CreateWindow(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req CreateOrderWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	items := make([]services.OrderItemInput, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = services.OrderItemInput{Name: item.Name, Options: item.Options, PriceCents: item.PriceCents}
+	}
+
+	window, err := h.orderService.CreateWindow(services.CreateOrderWindowInput{
+		Title:       req.Title,
+		Description: req.Description,
+		ClosesAt:    req.ClosesAt,
+		Items:       items,
+		CreatedBy:   user.ID,
+	})
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, window)
+}
+
+type UpdateOrderWindowRequest struct {
+	Title       *string    `json:"title" binding:"omitempty,max=255"`
+	Description *string    `json:"description"`
+	ClosesAt    *time.Time `json:"closes_at"`
+}
+
+// UpdateWindow changes a group order's details or closing time (admin only)
+func (h *OrderHandler) UpdateWindow(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	var req UpdateOrderWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	window, err := h.orderService.UpdateWindow(id, services.UpdateOrderWindowInput{
+		Title:       req.Title,
+		Description: req.Description,
+		ClosesAt:    req.ClosesAt,
+	})
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+// CloseWindow closes a group order before its closing time (admin only)
+func (h *OrderHandler) CloseWindow(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	window, err := h.orderService.CloseWindow(context.Background(), id)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+// OrderWithMember is a member's order in an order summary
+type OrderWithMember struct {
+	models.Order
+	User       *dto.UserResponse `json:"user"`
+	TotalCents int               `json:"total_cents"`
+}
+
+// GetSummary returns a group order's totals and every member's order (admin only)
+func (h *OrderHandler) GetSummary(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	summary, err := h.orderService.Summary(id)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	viewer := currentUser(c)
+	orders := make([]OrderWithMember, len(summary.Orders))
+	for i, order := range summary.Orders {
+		orders[i] = OrderWithMember{Order: order, User: dto.User(order.User, viewer), TotalCents: summary.OrderTotalCents(order)}
+	}
+	totals := summary.Totals
+	if totals == nil {
+		totals = []services.OrderItemTotal{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"window":      summary.Window,
+		"totals":      totals,
+		"orders":      orders,
+		"order_count": summary.OrderCount,
+		"total_cents": summary.TotalCents,
+	})
+}
+
+// ExportOrders downloads a group order as CSV: the totals, or with
+// ?by=member each member's lines (admin only)
+func (h *OrderHandler) ExportOrders(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	summary, err := h.orderService.Summary(id)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	byMember := c.Query("by") == "member"
+	suffix := "totals"
+	if byMember {
+		suffix = "by-member"
+	}
+	filename := fmt.Sprintf("order-%s-%s.csv", slugify(summary.Window.Title), suffix)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	if err := services.WriteOrderCSV(c.Writer, summary, byMember); err != nil {
+		log.Printf("Error writing order export for %s: %v", summary.Window.ID, err)
+	}
+}
+
+// slugify makes s safe for a file name, such as "club-shirts-2026"
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "export"
+	}
+	return slug
+}
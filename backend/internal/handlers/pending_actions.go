@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+// PendingActionResponse is a pending action with the admin who requested it
+type PendingActionResponse struct {
+	models.PendingAction
+	Requester *dto.UserResponse `json:"requester,omitempty"`
+}
+
+// respondPendingApproval tells the caller their action is waiting for a second admin
+func respondPendingApproval(c *gin.Context, action *models.PendingAction) {
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":        "Another admin must approve this action before it takes effect",
+		"pending_action": action,
+	})
+}
+
+// ListPendingActions returns destructive actions awaiting approval, or all
+// actions with ?status=all
+func (h *AdminHandler) ListPendingActions(c *gin.Context) {
+	var status *models.PendingActionStatus
+	switch s := c.DefaultQuery("status", string(models.PendingActionStatusPending)); s {
+	case "all":
+	case string(models.PendingActionStatusPending), string(models.PendingActionStatusApproved), string(models.PendingActionStatusDeclined):
+		st := models.PendingActionStatus(s)
+		status = &st
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, approved, declined or all"})
+		return
+	}
+
+	actions, err := h.pendingActionService.ListPendingActions(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list pending actions"})
+		return
+	}
+
+	viewer := currentUser(c)
+	response := make([]PendingActionResponse, len(actions))
+	for i := range actions {
+		response[i] = PendingActionResponse{
+			PendingAction: actions[i],
+			Requester:     dto.User(actions[i].Requester, viewer),
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ApprovePendingAction carries out an action requested by another admin
+func (h *AdminHandler) ApprovePendingAction(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action ID"})
+		return
+	}
+
+	action, err := h.pendingActionService.ApprovePendingAction(id, user.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, action)
+}
+
+// DeclinePendingAction discards a pending action
+func (h *AdminHandler) DeclinePendingAction(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action ID"})
+		return
+	}
+
+	action, err := h.pendingActionService.DeclinePendingAction(id, user.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, action)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+type ReportHandler struct {
+	reportService ReportService
+}
+
+func NewReportHandler(reportService ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+// GetMonthlyReport returns session and shuttle usage for a month (?month=YYYY-MM, default current)
+func (h *ReportHandler) GetMonthlyReport(c *gin.Context) {
+	month := utils.NowInSydney()
+	if m := c.Query("month"); m != "" {
+		parsed, err := time.ParseInLocation("2006-01", m, utils.SydneyLocation)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month format. Use YYYY-MM"})
+			return
+		}
+		month = parsed
+	}
+
+	report, err := h.reportService.GetMonthlyReport(month)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetAttendanceReport compares confirmed RSVPs with attendance for sessions
+// between ?from= and ?to= (YYYY-MM-DD, default the last 90 days): each
+// member's no-shows and reliability, and the no-show rate by month
+func (h *ReportHandler) GetAttendanceReport(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	if to == nil {
+		today := utils.StartOfDay(utils.NowInSydney())
+		to = &today
+	}
+	if from == nil {
+		start := to.AddDate(0, 0, -90)
+		from = &start
+	}
+
+	report, err := h.reportService.GetAttendanceReport(*from, *to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
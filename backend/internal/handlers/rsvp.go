@@ -2,19 +2,22 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
 	"github.com/weekday-masters/backend/internal/middleware"
 	"github.com/weekday-masters/backend/internal/models"
 	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/utils"
 )
 
 type RSVPHandler struct {
-	rsvpService *services.RSVPService
+	rsvpService RSVPService
 }
 
-func NewRSVPHandler(rsvpService *services.RSVPService) *RSVPHandler {
+func NewRSVPHandler(rsvpService RSVPService) *RSVPHandler {
 	return &RSVPHandler{rsvpService: rsvpService}
 }
 
@@ -39,7 +42,7 @@ func (h *RSVPHandler) CreateRSVP(c *gin.Context) {
 
 	var req RSVPRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -50,11 +53,11 @@ func (h *RSVPHandler) CreateRSVP(c *gin.Context) {
 	}, false)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, rsvp)
+	c.JSON(http.StatusOK, dto.RSVP(rsvp, user))
 }
 
 // UpdateRSVP updates an existing RSVP
@@ -79,13 +82,92 @@ func (h *RSVPHandler) DeleteRSVP(c *gin.Context) {
 	}
 
 	if err := h.rsvpService.DeleteRSVP(sessionID, user.ID, false); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "RSVP removed"})
 }
 
+// CheckIn checks the current user in to a session they're IN for, from 30
+// minutes before it starts until it ends
+func (h *RSVPHandler) CheckIn(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	rsvp, err := h.rsvpService.SelfCheckIn(sessionID, user.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RSVP(rsvp, user))
+}
+
+// ConfirmWaitlistOffer takes up the spot offered to the current user off a
+// session's waitlist
+func (h *RSVPHandler) ConfirmWaitlistOffer(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	rsvp, err := h.rsvpService.ConfirmWaitlistOffer(sessionID, user.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RSVP(rsvp, user))
+}
+
+// LeaveWaitlist takes the current user off a session's waitlist, turning
+// down any spot offered to them
+func (h *RSVPHandler) LeaveWaitlist(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	if err := h.rsvpService.LeaveWaitlist(sessionID, user.ID); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left the waitlist"})
+}
+
+// MyRSVPResponse is the user's RSVP plus their place on the waitlist, if
+// any, and until when a spot offered to them is held
+type MyRSVPResponse struct {
+	*dto.RSVPResponse
+	WaitlistPosition       *int       `json:"waitlist_position,omitempty"`
+	WaitlistOfferExpiresAt *time.Time `json:"waitlist_offer_expires_at,omitempty"`
+}
+
 // GetMyRSVP returns the current user's RSVP for a session
 func (h *RSVPHandler) GetMyRSVP(c *gin.Context) {
 	user, err := middleware.GetUserFromContext(c)
@@ -107,5 +189,53 @@ func (h *RSVPHandler) GetMyRSVP(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, rsvp)
+	response := MyRSVPResponse{RSVPResponse: dto.RSVP(rsvp, user)}
+	if rsvp.Status == models.RSVPStatusWaitlisted {
+		if entry, err := h.rsvpService.GetWaitlistEntry(sessionID, user.ID); err == nil && entry != nil {
+			response.WaitlistPosition = &entry.Position
+			response.WaitlistOfferExpiresAt = entry.OfferExpiresAt
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMyRSVPs returns the current user's RSVPs keyed by session ID (?from=&to= as YYYY-MM-DD)
+func (h *RSVPHandler) GetMyRSVPs(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var from, to *time.Time
+	if f := c.Query("from"); f != "" {
+		parsed, err := utils.ParseDateInSydney(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date. Use YYYY-MM-DD"})
+			return
+		}
+		from = &parsed
+	}
+	if t := c.Query("to"); t != "" {
+		parsed, err := utils.ParseDateInSydney(t)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date. Use YYYY-MM-DD"})
+			return
+		}
+		to = &parsed
+	}
+
+	rsvps, err := h.rsvpService.GetUserRSVPs(user.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get RSVPs"})
+		return
+	}
+
+	bySession := make(map[string]*dto.RSVPResponse, len(rsvps))
+	for i := range rsvps {
+		bySession[rsvps[i].SessionID.String()] = dto.RSVP(&rsvps[i], user)
+	}
+
+	c.JSON(http.StatusOK, bySession)
 }
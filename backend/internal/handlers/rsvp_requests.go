@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+)
+
+// RSVPRequestResponse is a member's request to play, with how often they've played lately
+type RSVPRequestResponse struct {
+	*dto.RSVPResponse
+	RecentAttendance int `json:"recent_attendance"`
+}
+
+// ListRSVPRequests returns requests awaiting approval, members who've played
+// least recently first
+func (h *AdminHandler) ListRSVPRequests(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	requests, err := h.rsvpService.ListRSVPRequests(sessionID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	viewer := currentUser(c)
+	response := make([]RSVPRequestResponse, len(requests))
+	for i := range requests {
+		response[i] = RSVPRequestResponse{
+			RSVPResponse:     dto.RSVP(&requests[i].RSVP, viewer),
+			RecentAttendance: requests[i].RecentAttendance,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ApproveRSVPRequest confirms a member's spot in a session
+func (h *AdminHandler) ApproveRSVPRequest(c *gin.Context) {
+	sessionID, userID, ok := parseSessionAndUser(c)
+	if !ok {
+		return
+	}
+
+	rsvp, err := h.rsvpService.ApproveRSVPRequest(sessionID, userID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RSVP(rsvp, currentUser(c)))
+}
+
+type DeclineRSVPRequestRequest struct {
+	Reason string `json:"reason"`
+}
+
+// DeclineRSVPRequest turns down a member's request to play
+func (h *AdminHandler) DeclineRSVPRequest(c *gin.Context) {
+	sessionID, userID, ok := parseSessionAndUser(c)
+	if !ok {
+		return
+	}
+
+	// Body is optional
+	var req DeclineRSVPRequestRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	rsvp, err := h.rsvpService.DeclineRSVPRequest(sessionID, userID, req.Reason)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.RSVP(rsvp, currentUser(c)))
+}
+
+// parseSessionAndUser reads the :id and :userId params, responding with 400 if either is invalid
+func parseSessionAndUser(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return sessionID, userID, true
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type SearchHandler struct {
+	searchService SearchService
+}
+
+func NewSearchHandler(searchService SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// Search finds members, sessions and announcements matching ?q=, narrowed
+// with ?type= (comma-separated) and capped per type by ?limit= (default
+// 10). Members awaiting approval only find sessions.
+func (h *SearchHandler) Search(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	types, err := services.ParseSearchTypes(c.Query("type"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+	limit := 10
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= services.MaxSearchLimit {
+			limit = parsed
+		}
+	}
+
+	results, err := h.searchService.Search(user, query, types, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrEmptySearch) {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+		return
+	}
+
+	// Only the types searched are in the response
+	response := gin.H{"query": query}
+	if results.Members != nil {
+		response["members"] = dto.Users(results.Members, user)
+	}
+	if results.Sessions != nil {
+		response["sessions"] = dto.Sessions(results.Sessions, user)
+	}
+	if results.Announcements != nil {
+		response["announcements"] = results.Announcements
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+type SeasonRolloverHandler struct {
+	sessionService SessionService
+}
+
+func NewSeasonRolloverHandler(sessionService SessionService) *SeasonRolloverHandler {
+	return &SeasonRolloverHandler{sessionService: sessionService}
+}
+
+type BlackoutRequest struct {
+	Date   string `json:"date" binding:"required"` // YYYY-MM-DD
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+type RolloverRequest struct {
+	Name        string            `json:"name" binding:"required,max=255"`
+	StartDate   string            `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate     string            `json:"end_date" binding:"required"`   // YYYY-MM-DD
+	Blackouts   []BlackoutRequest `json:"blackouts" binding:"dive"`
+	TemplateIDs []uuid.UUID       `json:"template_ids"` // every recurring series if empty
+}
+
+// bindRollover reads a RolloverRequest, responding and returning false if it's invalid
+func bindRollover(c *gin.Context) (services.RolloverInput, bool) {
+	var req RolloverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return services.RolloverInput{}, false
+	}
+
+	input := services.RolloverInput{Name: req.Name, TemplateIDs: req.TemplateIDs}
+	var err error
+	if input.StartDate, err = utils.ParseDateInSydney(req.StartDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format. Use YYYY-MM-DD"})
+		return input, false
+	}
+	if input.EndDate, err = utils.ParseDateInSydney(req.EndDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format. Use YYYY-MM-DD"})
+		return input, false
+	}
+	for _, b := range req.Blackouts {
+		date, err := utils.ParseDateInSydney(b.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid blackout date %q. Use YYYY-MM-DD", b.Date)})
+			return input, false
+		}
+		input.Blackouts = append(input.Blackouts, services.Blackout{Date: date, Reason: b.Reason})
+	}
+	return input, true
+}
+
+// PreviewRollover shows the sessions a season rollover would create and the
+// weeks it would leave out, without creating anything
+func (h *SeasonRolloverHandler) PreviewRollover(c *gin.Context) {
+	input, ok := bindRollover(c)
+	if !ok {
+		return
+	}
+
+	plan, err := h.sessionService.PreviewRollover(input)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// RolloverResponse is a season rollover with the admin who ran it
+type RolloverResponse struct {
+	models.SeasonRollover
+	Creator *dto.UserResponse `json:"creator,omitempty"`
+}
+
+func rolloverResponse(rollover *models.SeasonRollover, viewer *models.User) RolloverResponse {
+	return RolloverResponse{SeasonRollover: *rollover, Creator: dto.User(rollover.Creator, viewer)}
+}
+
+// RollOverSeason creates a season of recurring sessions
+func (h *SeasonRolloverHandler) RollOverSeason(c *gin.Context) {
+	input, ok := bindRollover(c)
+	if !ok {
+		return
+	}
+
+	admin := currentUser(c)
+	rollover, plan, err := h.sessionService.RollOverSeason(input, admin.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"rollover": rolloverResponse(rollover, admin), "plan": plan})
+}
+
+// ListRollovers returns season rollovers, most recent first
+func (h *SeasonRolloverHandler) ListRollovers(c *gin.Context) {
+	rollovers, err := h.sessionService.ListRollovers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list season rollovers"})
+		return
+	}
+
+	admin := currentUser(c)
+	response := make([]RolloverResponse, len(rollovers))
+	for i := range rollovers {
+		response[i] = rolloverResponse(&rollovers[i], admin)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// UndoRollover deletes the sessions a rollover created that nobody has
+// RSVP'd to or commented on, and lifts its blackout dates
+func (h *SeasonRolloverHandler) UndoRollover(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rollover ID"})
+		return
+	}
+
+	result, err := h.sessionService.UndoRollover(id, currentUser(c).ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": result.Deleted,
+		"kept":    dto.Sessions(result.Kept, currentUser(c)),
+	})
+}
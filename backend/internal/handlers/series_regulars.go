@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+// SetRegularsRequest is the complete list of a series' regulars
+type SetRegularsRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids"`
+}
+
+// regularUsers returns the members behind a series' regulars
+func regularUsers(regulars []models.SeriesRegular) []models.User {
+	users := make([]models.User, 0, len(regulars))
+	for _, r := range regulars {
+		if r.User != nil {
+			users = append(users, *r.User)
+		}
+	}
+	return users
+}
+
+// GetSeriesRegulars lists the regulars of the recurring series a session
+// belongs to (admin only)
+func (h *AdminHandler) GetSeriesRegulars(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	regulars, err := h.sessionService.ListRegulars(id)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"regulars": dto.Users(regularUsers(regulars), currentUser(c))})
+}
+
+// SetSeriesRegulars replaces the regulars of the recurring series a session
+// belongs to (admin only). New regulars are put IN to the upcoming sessions
+// still taking RSVPs, and every session generated from then on.
+func (h *AdminHandler) SetSeriesRegulars(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req SetRegularsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	admin := currentUser(c)
+	update, err := h.sessionService.SetRegulars(id, req.UserIDs, admin.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"regulars":    dto.Users(regularUsers(update.Regulars), admin),
+		"rsvps_added": update.RSVPsAdded,
+	})
+}
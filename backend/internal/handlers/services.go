@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+// The handlers take their services as these interfaces, each the part of the
+// services type of the same name that they use. The server passes the real
+// services; the mock server passes stubs over its fixtures.
+
+type AccountLinkService interface {
+	ConfirmLink(auth0ID string, email string, code string) (*models.User, error)
+	RequestLink(ctx context.Context, auth0ID string, email string) error
+}
+
+type AllocationService interface {
+	ListAllocationResults(sessionID uuid.UUID) ([]models.AllocationResult, error)
+}
+
+type AnnouncementService interface {
+	AckSummaries(limit int) ([]services.AnnouncementAckSummary, error)
+	Acknowledge(announcementID uuid.UUID, viewer *models.User) (*models.AnnouncementAcknowledgement, error)
+	GetAcks(announcementID uuid.UUID) (*models.Announcement, *services.AnnouncementAcks, error)
+	ListAnnouncements(viewer *models.User, limit int) ([]services.AnnouncementWithAck, error)
+	Quota(limit int, window time.Duration) (*services.AnnouncementQuota, error)
+	SendAnnouncement(ctx context.Context, title string, body string, requiresAck bool, createdBy uuid.UUID, overridden *services.AnnouncementQuota) (*models.Announcement, error)
+	SendCommitteeAnnouncement(ctx context.Context, title string, body string, requiresAck bool, createdBy uuid.UUID) (*models.Announcement, error)
+}
+
+type ArchiveService interface {
+	Stats() ([]services.ArchiveTableStats, error)
+}
+
+type AvatarService interface {
+	ListAvatarReports() ([]models.AvatarReport, error)
+	OpenAvatar(ctx context.Context, avatarID uuid.UUID, size models.AvatarSize) (io.ReadCloser, error)
+	RemoveAvatar(userID uuid.UUID) error
+	ReportAvatar(userID uuid.UUID, reporterID uuid.UUID, reason string) (*models.AvatarReport, error)
+	ResolveAvatarReport(ctx context.Context, reportID uuid.UUID, adminID uuid.UUID, action models.ModerationAction, note string) (*models.AvatarReport, error)
+	SetAvatar(ctx context.Context, userID uuid.UUID, size int64, r io.Reader) (*models.User, error)
+}
+
+type CardService interface {
+	IssueCard(user *models.User) (*services.MembershipCard, error)
+	VerifyCard(token string) (*services.CardVerification, error)
+}
+
+type CarpoolService interface {
+	Board(sessionID uuid.UUID, viewerID uuid.UUID) (*services.CarpoolBoard, error)
+	Confirm(ctx context.Context, sessionID uuid.UUID, requestID uuid.UUID, driverID uuid.UUID) (*models.CarpoolRequest, error)
+	Decline(ctx context.Context, sessionID uuid.UUID, requestID uuid.UUID, driverID uuid.UUID) error
+	DeleteOffer(ctx context.Context, sessionID uuid.UUID, driverID uuid.UUID) error
+	DeleteRequest(ctx context.Context, sessionID uuid.UUID, riderID uuid.UUID) error
+	SaveOffer(sessionID uuid.UUID, driverID uuid.UUID, input services.SaveCarpoolOfferInput) (*models.CarpoolOffer, error)
+	SaveRequest(ctx context.Context, sessionID uuid.UUID, riderID uuid.UUID, input services.SaveCarpoolRequestInput) (*models.CarpoolRequest, error)
+}
+
+type ClubExportService interface {
+	Export() (*services.ClubBundle, error)
+	RecordExport(bundle *services.ClubBundle, actorID uuid.UUID) error
+}
+
+type ClubService interface {
+	GetClub() (*models.Club, error)
+	SaveClub(club *models.Club) error
+}
+
+type CoachingService interface {
+	DeleteAssessment(id uuid.UUID, coach *models.User) error
+	ListTrainees() ([]services.Trainee, error)
+	ListTrainingSessions(coach *models.User) ([]models.Session, error)
+	Progress(userID uuid.UUID) (*services.TrainingProgressReport, error)
+	RecordAssessment(coach *models.User, userID uuid.UUID, input services.SkillAssessmentInput) (*models.SkillAssessment, error)
+	RecordAttendance(sessionID uuid.UUID, coach *models.User, userIDs []uuid.UUID, attended bool) error
+	Roster(sessionID uuid.UUID, coach *models.User) ([]services.TrainingRosterEntry, error)
+	UpdateCurriculum(sessionID uuid.UUID, coach *models.User, notes string) (*models.Session, error)
+	UpdateGoals(userID uuid.UUID, goals string) (*models.TrainingProgress, error)
+}
+
+type CommentService interface {
+	CreateComment(sessionID uuid.UUID, userID uuid.UUID, body string) (*models.Comment, error)
+	DeleteComment(commentID uuid.UUID, userID uuid.UUID, byAdmin bool) error
+	GetNotificationSetting(sessionID uuid.UUID, userID uuid.UUID) (*services.CommentNotificationSetting, error)
+	ListComments(sessionID uuid.UUID, includeHidden bool) ([]models.Comment, error)
+	ResetNotificationSetting(sessionID uuid.UUID, userID uuid.UUID) (*services.CommentNotificationSetting, error)
+	SetNotificationSetting(sessionID uuid.UUID, userID uuid.UUID, level models.CommentNotificationLevel) (*services.CommentNotificationSetting, error)
+}
+
+type CommitteeService interface {
+	CreateMinutes(input services.MinutesInput, createdBy uuid.UUID) (*models.CommitteeMinutes, error)
+	DeleteMinutes(id uuid.UUID, editor *models.User) error
+	GetMinutes(id uuid.UUID) (*models.CommitteeMinutes, error)
+	ListCommittee() ([]models.User, error)
+	ListMinutes() ([]models.CommitteeMinutes, error)
+	SetCommitteeRole(userID uuid.UUID, role models.CommitteeRole, actorID uuid.UUID) (*models.User, error)
+	UpdateMinutes(id uuid.UUID, input services.MinutesInput, editor *models.User) (*models.CommitteeMinutes, error)
+}
+
+type ContactLogService interface {
+	CompleteFollowUp(id uuid.UUID, actorID uuid.UUID) (*models.ContactLog, error)
+	DeleteContact(id uuid.UUID) error
+	LogContact(userID uuid.UUID, input services.ContactLogInput, actorID uuid.UUID) (*models.ContactLog, error)
+	OpenFollowUps(assignedTo *uuid.UUID) ([]models.ContactLog, error)
+	UpdateContact(id uuid.UUID, input services.ContactLogInput, actorID uuid.UUID) (*models.ContactLog, error)
+	UserTimeline(userID uuid.UUID) ([]services.TimelineEntry, error)
+}
+
+type CourtService interface {
+	AssignNextUp(ctx context.Context, sessionID uuid.UUID, assignedBy uuid.UUID) ([]models.CourtAssignment, error)
+	AssignPlayers(ctx context.Context, sessionID uuid.UUID, courtNumber int, userIDs []uuid.UUID, assignedBy uuid.UUID) ([]models.CourtAssignment, error)
+	GetBoard(sessionID uuid.UUID) (*services.CourtBoard, error)
+	ReleaseCourt(sessionID uuid.UUID, courtNumber int) error
+}
+
+type DocumentService interface {
+	AcknowledgeDocument(documentID uuid.UUID, viewer *models.User) (*models.DocumentAcknowledgement, error)
+	DeleteCommitteeDocument(ctx context.Context, id uuid.UUID) error
+	DeleteDocument(ctx context.Context, id uuid.UUID) error
+	ListCommitteeDocuments() ([]models.Document, error)
+	ListDocuments(viewer *models.User) ([]services.DocumentWithAck, error)
+	OpenDocument(ctx context.Context, id uuid.UUID, viewer *models.User) (*models.Document, io.ReadCloser, error)
+	UploadDocument(ctx context.Context, input services.DocumentInput, r io.Reader, uploadedBy uuid.UUID) (*models.Document, error)
+}
+
+type EventService interface {
+	ListEvents(filter services.EventFilter) ([]models.DomainEvent, error)
+}
+
+type GameService interface {
+	ConfirmGame(ctx context.Context, sessionID uuid.UUID, gameID uuid.UUID, userID uuid.UUID) (*models.Game, error)
+	GetPlayerRating(userID uuid.UUID) (*models.PlayerRating, error)
+	ListSessionGames(sessionID uuid.UUID) ([]models.Game, error)
+	MyRatings(gameID uuid.UUID, raterID uuid.UUID) ([]models.PeerRating, error)
+	RatePlayers(sessionID uuid.UUID, gameID uuid.UUID, raterID uuid.UUID, levels map[uuid.UUID]int) ([]models.PeerRating, error)
+	RecordGame(input services.RecordGameInput) (*models.Game, error)
+}
+
+type InboundEmailService interface {
+	CheckWebhookKey(key string) bool
+	HandleReply(ctx context.Context, email services.InboundEmail) (services.InboundEmailResult, error)
+	IsEnabled() bool
+}
+
+type IncidentService interface {
+	AddAttachment(ctx context.Context, incidentID uuid.UUID, uploader *models.User, fileName string, size int64, r io.Reader) (*models.IncidentAttachment, error)
+	CreateIncident(sessionID uuid.UUID, reporter *models.User, input services.IncidentInput) (*models.Incident, error)
+	GetIncident(id uuid.UUID, viewer *models.User) (*models.Incident, error)
+	ListIncidents(status *models.IncidentStatus) ([]models.Incident, error)
+	OpenAttachment(ctx context.Context, incidentID uuid.UUID, attachmentID uuid.UUID, viewer *models.User) (*models.IncidentAttachment, io.ReadCloser, error)
+	ResolveIncident(id uuid.UUID, resolvedBy uuid.UUID, notes string) (*models.Incident, error)
+}
+
+type InventoryService interface {
+	Adjust(ctx context.Context, id uuid.UUID, change int, reason models.InventoryMovementReason, note string, actorID uuid.UUID) (*models.InventoryItem, error)
+	CheckIn(ctx context.Context, sessionID uuid.UUID, checkoutID uuid.UUID, returned *int, user *models.User) (*models.InventoryCheckout, error)
+	CheckOut(sessionID uuid.UUID, itemID uuid.UUID, quantity int, user *models.User) (*models.InventoryCheckout, error)
+	Consumption(months int) (*services.ConsumptionReport, error)
+	CreateItem(ctx context.Context, input services.SaveInventoryItemInput, actorID uuid.UUID) (*models.InventoryItem, error)
+	DeleteItem(id uuid.UUID) error
+	GetItem(id uuid.UUID) (*services.InventoryItemStock, error)
+	ListItems() ([]services.InventoryItemStock, error)
+	Movements(id uuid.UUID, limit int) ([]models.InventoryMovement, error)
+	SessionEquipment(sessionID uuid.UUID, user *models.User) ([]models.InventoryCheckout, error)
+	UpdateItem(ctx context.Context, id uuid.UUID, input services.SaveInventoryItemInput) (*models.InventoryItem, error)
+}
+
+type InviteService interface {
+	Accept(userID uuid.UUID, token string) (*models.InviteUse, error)
+	Create(input services.CreateInviteInput, adminID uuid.UUID) (*models.Invite, error)
+	Get(id uuid.UUID) (*models.Invite, []models.InviteUse, error)
+	Link(invite *models.Invite) string
+	List() ([]models.Invite, error)
+	Preview(token string) (*models.Invite, error)
+	Revoke(id uuid.UUID) (*models.Invite, error)
+}
+
+type JobService interface {
+	JobStatuses() ([]services.JobStatus, error)
+	ListJobRuns(name string, limit int) ([]models.JobRun, error)
+}
+
+type JoinRuleService interface {
+	AutoApprove(userID uuid.UUID, emailVerified bool, referralCode string) (*models.JoinApproval, error)
+	DeletePreapproved(id uuid.UUID) error
+	ImportPreapproved(entries []services.PreapprovedEntry, adminID uuid.UUID) (int, error)
+	ListApprovals(rule models.JoinRule, limit int, offset int) ([]models.JoinApproval, error)
+	ListPreapproved() ([]models.PreapprovedMember, error)
+	ListReferralCodes() ([]models.ReferralCode, error)
+	MyReferralCode(user *models.User) (*models.ReferralCode, error)
+	RedeemReferralCode(userID uuid.UUID, code string) (*models.JoinApproval, error)
+	Rules() (*models.JoinRules, error)
+	SetReferralCodeDisabled(id uuid.UUID, disabled bool) (*models.ReferralCode, error)
+	UpdateRules(input services.JoinRulesInput, adminID uuid.UUID) (*models.JoinRules, error)
+}
+
+type MemberInactivityService interface {
+	Archive(userID uuid.UUID, actorID uuid.UUID) (*models.User, error)
+	KeepActive(userID uuid.UUID, actorID uuid.UUID) (*models.User, error)
+	Restore(userID uuid.UUID, actorID uuid.UUID) (*models.User, error)
+	ReviewQueue() ([]services.InactiveMember, error)
+}
+
+type MessageCatalogService interface {
+	ListMessages() ([]services.MessageTemplateInfo, error)
+	PreviewMessage(key models.MessageTemplateKey, lang models.Language, title string, body string) (*services.MessagePreview, error)
+	ResetMessage(key models.MessageTemplateKey, lang models.Language, actorID uuid.UUID) error
+	SetMessage(key models.MessageTemplateKey, lang models.Language, title string, body string, actorID uuid.UUID) (*models.MessageTemplate, error)
+}
+
+type ModerationService interface {
+	CheckText(texts ...string) error
+	ListOpenReports() ([]models.CommentReport, error)
+	ReportComment(commentID uuid.UUID, reporterID uuid.UUID, reason string) (*models.CommentReport, error)
+	ResolveReport(ctx context.Context, reportID uuid.UUID, adminID uuid.UUID, action models.ModerationAction, note string) (*models.CommentReport, error)
+}
+
+type NotificationService interface {
+	CountUnread(userID uuid.UUID) (int64, error)
+	GetUserNotifications(userID uuid.UUID, filter services.NotificationFilter, limit int, offset int) ([]models.Notification, error)
+	GetUserPreferences(userID uuid.UUID) (*models.UserNotificationPreferences, error)
+	ListNotificationLog(filter services.NotificationLogFilter, limit int, offset int) ([]models.Notification, error)
+	MarkNotificationRead(notificationID uuid.UUID, userID uuid.UUID) error
+	RegisterPushToken(userID uuid.UUID, token string, deviceName string) error
+	ResendNotification(ctx context.Context, id uuid.UUID) (*models.Notification, error)
+	UnregisterPushToken(userID uuid.UUID, token string) error
+	UpdateUserPreferences(userID uuid.UUID, updates map[string]interface{}, types services.TypePreferenceUpdates) (*models.UserNotificationPreferences, error)
+}
+
+type OrderService interface {
+	CancelOrder(windowID uuid.UUID, userID uuid.UUID) error
+	CloseWindow(ctx context.Context, id uuid.UUID) (*models.OrderWindow, error)
+	CreateWindow(input services.CreateOrderWindowInput) (*models.OrderWindow, error)
+	GetOrder(windowID uuid.UUID, userID uuid.UUID) (*models.Order, error)
+	GetWindow(id uuid.UUID) (*models.OrderWindow, error)
+	ListWindows() ([]models.OrderWindow, error)
+	SubmitOrder(windowID uuid.UUID, userID uuid.UUID, lines []services.OrderLineInput, notes string) (*models.Order, error)
+	Summary(windowID uuid.UUID) (*services.OrderSummary, error)
+	UpdateWindow(id uuid.UUID, input services.UpdateOrderWindowInput) (*models.OrderWindow, error)
+}
+
+type PendingActionService interface {
+	ApprovePendingAction(id uuid.UUID, approverID uuid.UUID) (*models.PendingAction, error)
+	DeclinePendingAction(id uuid.UUID, reviewerID uuid.UUID) (*models.PendingAction, error)
+	ListPendingActions(status *models.PendingActionStatus) ([]models.PendingAction, error)
+	RequestIfRequired(actionType models.PendingActionType, targetID uuid.UUID, payload string, requestedBy uuid.UUID) (*models.PendingAction, error)
+}
+
+type RSVPService interface {
+	AddGuest(sessionID uuid.UUID, invitedBy uuid.UUID, name string) (*models.GuestRSVP, error)
+	AdminRemoveRSVP(sessionID uuid.UUID, userID uuid.UUID, newStatus *models.RSVPStatus, reason string) error
+	ApproveRSVPRequest(sessionID uuid.UUID, userID uuid.UUID) (*models.RSVP, error)
+	CheckIn(sessionID uuid.UUID, userID uuid.UUID) (*models.RSVP, error)
+	ConfirmWaitlistOffer(sessionID uuid.UUID, userID uuid.UUID) (*models.RSVP, error)
+	CreateOrUpdateRSVP(input services.RSVPInput, byAdmin bool) (*models.RSVP, error)
+	DeclineRSVPRequest(sessionID uuid.UUID, userID uuid.UUID, reason string) (*models.RSVP, error)
+	DeleteRSVP(sessionID uuid.UUID, userID uuid.UUID, byAdmin bool) error
+	Forecast(sessionID uuid.UUID) (*services.OccupancyForecast, error)
+	GetRSVPSummary(sessionID uuid.UUID) (*services.RSVPSummary, error)
+	GetSessionAttendance(sessionID uuid.UUID) ([]models.Attendance, error)
+	GetUserRSVPForSession(sessionID uuid.UUID, userID uuid.UUID) (*models.RSVP, error)
+	GetUserRSVPs(userID uuid.UUID, from *time.Time, to *time.Time) ([]models.RSVP, error)
+	GetUserRSVPsForSessions(userID uuid.UUID, sessionIDs []uuid.UUID) (map[uuid.UUID]models.RSVP, error)
+	GetWaitlist(sessionID uuid.UUID) ([]services.WaitlistEntry, error)
+	GetWaitlistEntry(sessionID uuid.UUID, userID uuid.UUID) (*models.WaitlistEntry, error)
+	LeaveWaitlist(sessionID uuid.UUID, userID uuid.UUID) error
+	ListGuests(sessionID uuid.UUID) ([]models.GuestRSVP, error)
+	ListRSVPRequests(sessionID uuid.UUID) ([]services.RSVPRequest, error)
+	RecordAttendance(sessionID uuid.UUID, userID uuid.UUID, status models.AttendanceStatus, note string, recordedBy uuid.UUID) (*models.Attendance, error)
+	RemoveGuest(sessionID uuid.UUID, guestID uuid.UUID, user *models.User) error
+	SelfCheckIn(sessionID uuid.UUID, userID uuid.UUID) (*models.RSVP, error)
+	Timeline(sessionID uuid.UUID) (*services.RSVPTimeline, error)
+}
+
+type ReportService interface {
+	GetAttendanceReport(from time.Time, to time.Time) (*services.AttendanceReport, error)
+	GetMonthlyReport(monthStart time.Time) (*services.MonthlyReport, error)
+}
+
+type SchedulerService interface {
+	RunJob(name string, dryRun bool) (*services.JobReport, error)
+}
+
+type SearchService interface {
+	Search(viewer *models.User, query string, types []services.SearchType, limit int) (*services.SearchResults, error)
+}
+
+type SessionService interface {
+	AddAttachment(ctx context.Context, sessionID uuid.UUID, fileName string, size int64, r io.Reader, uploadedBy uuid.UUID) (*models.SessionAttachment, error)
+	CancelSession(id uuid.UUID, reason string) (*models.Session, error)
+	CreateSession(input services.CreateSessionInput) (*models.Session, error)
+	DeleteAttachment(sessionID uuid.UUID, attachmentID uuid.UUID) error
+	DeleteSession(id uuid.UUID) error
+	ExtendDeadline(id uuid.UUID, deadline time.Time, actorID uuid.UUID, reason string) (*models.Session, error)
+	GetAdminNotes(id uuid.UUID) (string, error)
+	GetSessionByID(id uuid.UUID) (*models.Session, error)
+	ListCancelledUpcomingSessions() ([]models.Session, error)
+	ListPastSessions(from *time.Time, to *time.Time, limit int, offset int) ([]models.Session, error)
+	ListPlayedSessions(userID uuid.UUID, from *time.Time, to *time.Time, limit int, offset int) ([]services.PlayedSession, error)
+	ListRegulars(sessionID uuid.UUID) ([]models.SeriesRegular, error)
+	ListRollovers() ([]models.SeasonRollover, error)
+	ListSessionHistory(from *time.Time, to *time.Time, limit int, offset int) (*services.SessionHistory, error)
+	ListSessions(filter services.SessionListFilter) ([]models.Session, error)
+	MergeSessions(targetID uuid.UUID, sourceID uuid.UUID, conflict services.RSVPConflict, actorID uuid.UUID) (*services.MergeResult, error)
+	OpenAttachment(ctx context.Context, sessionID uuid.UUID, attachmentID uuid.UUID) (*models.SessionAttachment, io.ReadCloser, error)
+	PreviewRecurrence(input services.RecurrencePreviewInput) (*services.RecurrencePreview, error)
+	PreviewRollover(input services.RolloverInput) (*services.RolloverPlan, error)
+	RecordUsage(id uuid.UUID, input services.SessionUsageInput) (*models.Session, error)
+	RefreshRecurringSessions() error
+	RollOverSeason(input services.RolloverInput, createdBy uuid.UUID) (*models.SeasonRollover, *services.RolloverPlan, error)
+	SetRegulars(sessionID uuid.UUID, userIDs []uuid.UUID, adminID uuid.UUID) (*services.RegularsUpdate, error)
+	TrimRecurringSessions() (int, error)
+	UndoRollover(id uuid.UUID, actorID uuid.UUID) (*services.RolloverUndo, error)
+	UpdateAdminNotes(id uuid.UUID, notes string) error
+	UpdateSession(id uuid.UUID, input services.UpdateSessionInput) (*models.Session, error)
+}
+
+type ShareService interface {
+	CreateToken(session *models.Session) (string, time.Time, error)
+	GetPreview(token string) (*services.SessionPreview, error)
+	PreviewImage(preview *services.SessionPreview) ([]byte, error)
+}
+
+type StatsService interface {
+	GetFastestFingers(week time.Time) ([]services.FastestFinger, error)
+	GetRecap(userID uuid.UUID, month time.Time) (*services.MemberRecap, error)
+}
+
+type SyncService interface {
+	GetChangesSince(since time.Time, viewer *models.User) (*services.SyncDelta, error)
+}
+
+type TournamentService interface {
+	CreateTournament(input services.CreateTournamentInput) (*models.Tournament, error)
+	GenerateFixtures(tournamentID uuid.UUID, sessionIDs []uuid.UUID) (*models.Tournament, error)
+	GetStandings(tournamentID uuid.UUID) ([]services.Standing, error)
+	GetTournamentByID(id uuid.UUID) (*models.Tournament, error)
+	ListTournaments() ([]models.Tournament, error)
+	RecordResult(ctx context.Context, tournamentID uuid.UUID, matchID uuid.UUID, player1Score int, player2Score int) (*models.TournamentMatch, error)
+	Register(tournamentID uuid.UUID, userID uuid.UUID) (*models.TournamentEntry, error)
+	Withdraw(tournamentID uuid.UUID, userID uuid.UUID) error
+}
+
+type UsageService interface {
+	Record(userID uuid.UUID)
+	Report(days int) (*services.UsageReport, error)
+}
+
+type UserService interface {
+	ApproveJoinRequest(userID uuid.UUID, adminID uuid.UUID) (*models.User, error)
+	CreateOrUpdateUser(input services.CreateUserInput) (*models.User, bool, error)
+	GetUserByID(id uuid.UUID) (*models.User, error)
+	GetUserProfile(id uuid.UUID) (*models.User, error)
+	ListApprovedMembers() ([]models.User, error)
+	ListPendingJoinRequests() ([]models.User, error)
+	RejectJoinRequest(userID uuid.UUID) (*models.User, error)
+	UpdateProfile(userID uuid.UUID, update services.ProfileUpdate) (*models.User, error)
+	UpdateUserRole(userID uuid.UUID, role models.UserRole) (*models.User, error)
+	UpdateUserTier(userID uuid.UUID, tier models.MembershipTier) (*models.User, error)
+}
+
+type WeatherProposalService interface {
+	Decide(id uuid.UUID, adminID uuid.UUID, approve bool) (*models.CancellationProposal, error)
+	DecideWithToken(id uuid.UUID, adminID uuid.UUID, decision string, token string) (*models.CancellationProposal, error)
+	ListProposals(status models.ProposalStatus) ([]models.CancellationProposal, error)
+}
+
+type WidgetService interface {
+	GetWidget() (*services.Widget, error)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+// SessionHistoryResponse is a session that has ended, with its final RSVPs
+// and who turned up
+type SessionHistoryResponse struct {
+	*dto.SessionResponse
+	RSVPSummary services.RSVPSummary     `json:"rsvp_summary"`
+	Attendance  []dto.AttendanceResponse `json:"attendance"`
+}
+
+// ListSessionHistory returns sessions that have ended, most recent first,
+// each with its final RSVP list, archived RSVPs included, and the attendance
+// taken at it (?from=&to= as YYYY-MM-DD, ?limit=&offset= to page)
+func (h *SessionHandler) ListSessionHistory(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	limit, offset := parsePage(c)
+
+	history, err := h.sessionService.ListSessionHistory(from, to, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session history"})
+		return
+	}
+
+	user := currentUser(c)
+	sessions := dto.Sessions(history.Sessions, user)
+	attendance := dto.SessionAttendance(history.Sessions, history.Attendance, user)
+	response := make([]SessionHistoryResponse, len(sessions))
+	for i := range sessions {
+		response[i] = SessionHistoryResponse{
+			SessionResponse: &sessions[i],
+			RSVPSummary:     services.SummarizeRSVPs(&history.Sessions[i]),
+			Attendance:      attendance[i],
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// PlayedSessionResponse is a session the current user played, with their
+// RSVP to it and their attendance record if one was taken
+type PlayedSessionResponse struct {
+	*dto.SessionResponse
+	MyRSVP       *dto.RSVPResponse       `json:"my_rsvp"`
+	MyAttendance *dto.AttendanceResponse `json:"my_attendance"`
+}
+
+// ListMySessions returns the sessions the current user played, most recent
+// first (?from=&to= as YYYY-MM-DD, ?limit=&offset= to page)
+func (h *SessionHandler) ListMySessions(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	limit, offset := parsePage(c)
+
+	user := currentUser(c)
+	played, err := h.sessionService.ListPlayedSessions(user.ID, from, to, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get your sessions"})
+		return
+	}
+
+	response := make([]PlayedSessionResponse, len(played))
+	for i := range played {
+		p := &played[i]
+		response[i] = PlayedSessionResponse{
+			SessionResponse: dto.Session(&p.Session, user),
+			MyRSVP:          dto.RSVP(p.RSVP, user),
+		}
+		if p.Attendance != nil {
+			records := dto.Attendance([]models.Attendance{*p.Attendance}, user)
+			response[i].MyAttendance = &records[0]
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
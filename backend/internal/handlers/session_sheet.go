@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+var sessionSheetTemplate = template.Must(template.New("sheet").Funcs(template.FuncMap{
+	"inc":   func(i int) int { return i + 1 },
+	"clock": utils.FormatClock,
+	"rows": func(rsvps []models.RSVP, emergency bool) map[string]interface{} {
+		return map[string]interface{}{"Rows": rsvps, "Emergency": emergency}
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Session.Title}} - {{.Date}}</title>
+<style>
+body { font-family: sans-serif; font-size: 12px; margin: 24px; }
+h1 { font-size: 18px; margin: 0 0 4px; }
+p.meta { margin: 0 0 16px; color: #555; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 16px; }
+th, td { border: 1px solid #999; padding: 4px 6px; text-align: left; vertical-align: top; }
+th { background: #eee; }
+td.tick { width: 40px; }
+</style>
+</head>
+<body>
+<h1>{{.Session.Title}}</h1>
+<p class="meta">{{.Date}}, {{clock .Session.StartsAt}}&ndash;{{clock .Session.EndsAt}} &middot; {{.Session.Courts}} court(s) &middot; {{len .Players}}/{{.Session.MaxPlayers}} players</p>
+{{define "rows"}}{{range $i, $r := .Rows}}<tr>
+<td>{{inc $i}}</td><td>{{$r.User.Name}}</td><td>{{$r.User.PhoneNumber}}</td>
+{{if $.Emergency}}<td>{{$r.User.EmergencyContactName}}{{if $r.User.EmergencyContactPhone}} ({{$r.User.EmergencyContactPhone}}){{end}}</td><td>{{$r.User.MedicalNotes}}</td>{{end}}
+<td class="tick"></td>
+</tr>
+{{end}}{{end}}
+<table>
+<tr><th>#</th><th>Player</th><th>Phone</th>{{if .Emergency}}<th>Emergency contact</th><th>Medical notes</th>{{end}}<th>Here</th></tr>
+{{template "rows" (rows .Players .Emergency)}}
+</table>
+{{if .Waitlist}}
+<h2>Waitlist</h2>
+<table>
+<tr><th>#</th><th>Player</th><th>Phone</th>{{if .Emergency}}<th>Emergency contact</th><th>Medical notes</th>{{end}}<th>Here</th></tr>
+{{template "rows" (rows .Waitlist .Emergency)}}
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+// SessionSheet renders a printable attendance sheet for a session. Admins and
+// the session's organizer can add ?emergency=true to include each player's
+// emergency contact and medical notes.
+func (h *SessionHandler) SessionSheet(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	session, err := h.sessionService.GetSessionByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	user := currentUser(c)
+	if user == nil || (!user.IsAdmin() && session.CreatedBy != user.ID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins and the session organizer can print the session sheet"})
+		return
+	}
+
+	// RSVPs are preloaded in timestamp order; the waitlist goes in its own order
+	var players, waitlist []models.RSVP
+	waiting := make(map[uuid.UUID]models.RSVP)
+	for _, r := range session.RSVPs {
+		if r.User == nil {
+			continue
+		}
+		switch r.Status {
+		case models.RSVPStatusIn:
+			players = append(players, r)
+		case models.RSVPStatusWaitlisted:
+			waiting[r.UserID] = r
+		}
+	}
+	if len(waiting) > 0 {
+		entries, err := h.rsvpService.GetWaitlist(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load waitlist"})
+			return
+		}
+		for _, e := range entries {
+			if r, ok := waiting[e.UserID]; ok {
+				waitlist = append(waitlist, r)
+			}
+		}
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	if err := sessionSheetTemplate.Execute(c.Writer, gin.H{
+		"Session":   session,
+		"Date":      session.SessionDate.Format("Monday 2 January 2006"),
+		"Players":   players,
+		"Waitlist":  waitlist,
+		"Emergency": c.Query("emergency") == "true",
+	}); err != nil {
+		c.Status(http.StatusInternalServerError)
+	}
+}
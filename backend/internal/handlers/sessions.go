@@ -2,39 +2,147 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/models"
 	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/utils"
 )
 
 type SessionHandler struct {
-	sessionService *services.SessionService
-	rsvpService    *services.RSVPService
+	sessionService SessionService
+	rsvpService    RSVPService
 }
 
-func NewSessionHandler(sessionService *services.SessionService, rsvpService *services.RSVPService) *SessionHandler {
+func NewSessionHandler(sessionService SessionService, rsvpService RSVPService) *SessionHandler {
 	return &SessionHandler{
 		sessionService: sessionService,
 		rsvpService:    rsvpService,
 	}
 }
 
-// ListSessions returns all upcoming sessions
+// ListSessions returns upcoming sessions, or those within ?from=&to= (YYYY-MM-DD).
+// ?include_past=true lists earlier sessions too, and ?fields= trims each one.
 func (h *SessionHandler) ListSessions(c *gin.Context) {
-	sessions, err := h.sessionService.ListUpcomingSessions()
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	filter := services.SessionListFilter{From: from, To: to}
+	if p := c.Query("include_past"); p != "" {
+		includePast, err := strconv.ParseBool(p)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "include_past must be true or false"})
+			return
+		}
+		filter.IncludePast = includePast
+	}
+
+	sessions, err := h.sessionService.ListSessions(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
 		return
 	}
 
-	// Add RSVP summary to each session
-	type SessionWithSummary struct {
-		*services.SessionService
-		Summary *services.RSVPSummary `json:"rsvp_summary"`
+	listWithFields(c, h.withMyRSVPs(c, sessions))
+}
+
+// ListPastSessions returns sessions before today, most recent first
+// (?from=&to= as YYYY-MM-DD, ?limit=&offset= to page, ?fields= to trim)
+func (h *SessionHandler) ListPastSessions(c *gin.Context) {
+	from, to, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	limit, offset := parsePage(c)
+	sessions, err := h.sessionService.ListPastSessions(from, to, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list past sessions"})
+		return
+	}
+
+	listWithFields(c, h.withMyRSVPs(c, sessions))
+}
+
+// withMyRSVPs serializes sessions with their RSVP summary and the caller's
+// RSVP to each, so a list doesn't need a request per session. Summaries come
+// from the counters on each session's row; the caller's RSVPs take one query.
+func (h *SessionHandler) withMyRSVPs(c *gin.Context, sessions []models.Session) []SessionWithMyRSVP {
+	user := currentUser(c)
+	myRSVPs := map[uuid.UUID]models.RSVP{}
+	if user != nil {
+		ids := make([]uuid.UUID, len(sessions))
+		for i, session := range sessions {
+			ids[i] = session.ID
+		}
+		if rsvps, err := h.rsvpService.GetUserRSVPsForSessions(user.ID, ids); err == nil {
+			myRSVPs = rsvps
+		}
+	}
+
+	serialized := dto.Sessions(sessions, user)
+	response := make([]SessionWithMyRSVP, len(sessions))
+	for i, session := range sessions {
+		response[i] = SessionWithMyRSVP{
+			SessionResponse: &serialized[i],
+			RSVPSummary:     services.SummarizeRSVPs(&sessions[i]),
+		}
+		if rsvp, ok := myRSVPs[session.ID]; ok {
+			response[i].MyRSVP = dto.RSVP(&rsvp, user)
+		}
 	}
+	return response
+}
 
-	c.JSON(http.StatusOK, sessions)
+// parsePage reads ?limit= (default 20, up to 100) and ?offset=, ignoring
+// values out of range
+func parsePage(c *gin.Context) (limit, offset int) {
+	limit = 20
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+	return limit, offset
+}
+
+// parseDateRange reads ?from= and ?to= as YYYY-MM-DD, responding with 400 if either is invalid
+func parseDateRange(c *gin.Context) (from, to *time.Time, ok bool) {
+	if f := c.Query("from"); f != "" {
+		parsed, err := utils.ParseDateInSydney(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date. Use YYYY-MM-DD"})
+			return nil, nil, false
+		}
+		from = &parsed
+	}
+	if t := c.Query("to"); t != "" {
+		parsed, err := utils.ParseDateInSydney(t)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date. Use YYYY-MM-DD"})
+			return nil, nil, false
+		}
+		to = &parsed
+	}
+	return from, to, true
+}
+
+// SessionWithMyRSVP is a session plus its RSVP summary and the current
+// user's RSVP, if any
+type SessionWithMyRSVP struct {
+	*dto.SessionResponse
+	RSVPSummary services.RSVPSummary `json:"rsvp_summary"`
+	MyRSVP      *dto.RSVPResponse    `json:"my_rsvp"`
 }
 
 // GetSession returns a single session with full details
@@ -55,10 +163,25 @@ func (h *SessionHandler) GetSession(c *gin.Context) {
 	// Get RSVP summary
 	summary, _ := h.rsvpService.GetRSVPSummary(id)
 
-	c.JSON(http.StatusOK, gin.H{
-		"session":      session,
+	user := currentUser(c)
+	response := gin.H{
+		"session":      dto.Session(session, user),
 		"rsvp_summary": summary,
-	})
+	}
+
+	// Members can see who is waiting for a spot and the guests members are
+	// bringing, unless the club hides who's coming from them; guests only see
+	// the counts
+	if dto.CanSeeMembers(user) && dto.SeesAttendees(session, user) {
+		if waitlist, err := h.rsvpService.GetWaitlist(id); err == nil {
+			response["waitlist"] = dto.Waitlist(waitlist, user)
+		}
+		if guests, err := h.rsvpService.ListGuests(id); err == nil {
+			response["guests"] = dto.Guests(guests, user)
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // ListCancelledSessions returns upcoming cancelled sessions
@@ -69,5 +192,5 @@ func (h *SessionHandler) ListCancelledSessions(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, sessions)
+	listWithFields(c, dto.Sessions(sessions, currentUser(c)))
 }
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/ogimage"
+)
+
+type ShareHandler struct {
+	shareService   ShareService
+	sessionService SessionService
+	frontendURL    string
+}
+
+func NewShareHandler(shareService ShareService, sessionService SessionService, frontendURL string) *ShareHandler {
+	return &ShareHandler{
+		shareService:   shareService,
+		sessionService: sessionService,
+		frontendURL:    frontendURL,
+	}
+}
+
+// ShareLinkResponse is a public preview link for a session
+type ShareLinkResponse struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ShareSession creates a signed link that non-members can open to preview a session
+func (h *ShareHandler) ShareSession(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	session, err := h.sessionService.GetSessionByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	token, expires, err := h.shareService.CreateToken(session)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ShareLinkResponse{
+		Token:     token,
+		URL:       h.frontendURL + "/share/sessions/" + token,
+		ExpiresAt: expires,
+	})
+}
+
+// GetSharedSession returns the public preview behind a share link, with a
+// link for visitors to join the club
+func (h *ShareHandler) GetSharedSession(c *gin.Context) {
+	preview, err := h.shareService.GetPreview(c.Param("token"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{
+		"session":  preview,
+		"join_url": h.frontendURL + "/",
+	})
+}
+
+var sharePageTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.Description}}">
+<meta property="og:type" content="website">
+<meta property="og:site_name" content="{{.SiteName}}">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+<meta property="og:image" content="{{.ImageURL}}">
+<meta property="og:image:width" content="{{.ImageWidth}}">
+<meta property="og:image:height" content="{{.ImageHeight}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+<meta name="twitter:image" content="{{.ImageURL}}">
+<meta http-equiv="refresh" content="0; url={{.AppURL}}">
+</head>
+<body>
+<p><a href="{{.AppURL}}">{{.Title}}</a></p>
+</body>
+</html>
+`))
+
+// SharePage serves a shared session link: a page whose Open Graph and
+// Twitter tags give chat apps a rich preview, and which sends people on to
+// the session in the app. Expired links still go to the app, which explains.
+func (h *ShareHandler) SharePage(c *gin.Context) {
+	token := c.Param("token")
+	appURL := h.frontendURL + "/share/" + token
+
+	preview, err := h.shareService.GetPreview(token)
+	if err != nil {
+		c.Redirect(http.StatusFound, appURL)
+		return
+	}
+
+	siteName := preview.ClubName
+	if siteName == "" {
+		siteName = "Weekday Masters"
+	}
+	description := []string{preview.Headline(), preview.When()}
+	if preview.VenueName != "" {
+		description = append(description, preview.VenueName)
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "public, max-age=300")
+	if err := sharePageTemplate.Execute(c.Writer, gin.H{
+		"SiteName":    siteName,
+		"Title":       preview.Title,
+		"Description": strings.Join(description, " · "),
+		"URL":         h.frontendURL + "/share/sessions/" + token,
+		"ImageURL":    h.frontendURL + "/share/sessions/" + token + "/image.png",
+		"ImageWidth":  ogimage.Width,
+		"ImageHeight": ogimage.Height,
+		"AppURL":      appURL,
+	}); err != nil {
+		c.Status(http.StatusInternalServerError)
+	}
+}
+
+// SharePreviewImage serves the preview image for a shared session link,
+// showing how many spots are left
+func (h *ShareHandler) SharePreviewImage(c *gin.Context) {
+	preview, err := h.shareService.GetPreview(c.Param("token"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	image, err := h.shareService.PreviewImage(preview)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to draw preview"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "image/png", image)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+type StatsHandler struct {
+	statsService StatsService
+}
+
+func NewStatsHandler(statsService StatsService) *StatsHandler {
+	return &StatsHandler{statsService: statsService}
+}
+
+// GetRecap returns the current user's recap for a month (?month=YYYY-MM,
+// default current) with their streak, and the fastest-RSVP board for a week
+// (?week=YYYY-MM-DD, any day of it, default current)
+func (h *StatsHandler) GetRecap(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	month := utils.NowInSydney()
+	if m := c.Query("month"); m != "" {
+		parsed, err := time.ParseInLocation("2006-01", m, utils.SydneyLocation)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month format. Use YYYY-MM"})
+			return
+		}
+		month = parsed
+	}
+	week := utils.NowInSydney()
+	if w := c.Query("week"); w != "" {
+		parsed, err := utils.ParseDateInSydney(w)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid week format. Use YYYY-MM-DD"})
+			return
+		}
+		week = parsed
+	}
+
+	recap, err := h.statsService.GetRecap(user.ID, month)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build recap"})
+		return
+	}
+	board, err := h.statsService.GetFastestFingers(week)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get fastest RSVPs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recap":           recap,
+		"week":            utils.StartOfWeek(week).Format("2006-01-02"),
+		"fastest_fingers": dto.FastestFingers(board, user),
+	})
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/dto"
+)
+
+type SyncHandler struct {
+	syncService SyncService
+}
+
+func NewSyncHandler(syncService SyncService) *SyncHandler {
+	return &SyncHandler{syncService: syncService}
+}
+
+// GetChanges returns everything changed since ?since=<RFC3339>, or a full snapshot if omitted
+func (h *SyncHandler) GetChanges(c *gin.Context) {
+	var since time.Time
+	if s := c.Query("since"); s != "" {
+		parsed, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since timestamp. Use RFC3339"})
+			return
+		}
+		since = parsed
+	}
+
+	user := currentUser(c)
+	delta, err := h.syncService.GetChangesSince(since, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get changes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":         delta.Since,
+		"server_time":   delta.ServerTime,
+		"sessions":      dto.Sessions(delta.Sessions, user),
+		"rsvps":         dto.AttendeeRSVPs(delta.RSVPs, user),
+		"announcements": announcementViews(delta.Announcements, user),
+		"deleted":       delta.Deleted,
+	})
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type TournamentHandler struct {
+	tournamentService TournamentService
+}
+
+func NewTournamentHandler(tournamentService TournamentService) *TournamentHandler {
+	return &TournamentHandler{tournamentService: tournamentService}
+}
+
+// TournamentResponse is a tournament with its players as seen by the viewer
+type TournamentResponse struct {
+	models.Tournament
+	Entries []TournamentEntryResponse `json:"entries,omitempty"`
+	Matches []TournamentMatchResponse `json:"matches,omitempty"`
+	Winner  *dto.UserResponse         `json:"winner,omitempty"`
+}
+
+// TournamentEntryResponse is a player registered in a tournament
+type TournamentEntryResponse struct {
+	models.TournamentEntry
+	User *dto.UserResponse `json:"user,omitempty"`
+}
+
+// TournamentMatchResponse is a fixture and the players in it
+type TournamentMatchResponse struct {
+	models.TournamentMatch
+	Player1 *dto.UserResponse `json:"player1,omitempty"`
+	Player2 *dto.UserResponse `json:"player2,omitempty"`
+}
+
+func tournamentResponse(tournament *models.Tournament, viewer *models.User) TournamentResponse {
+	response := TournamentResponse{
+		Tournament: *tournament,
+		Winner:     dto.User(tournament.Winner, viewer),
+	}
+	if len(tournament.Entries) > 0 {
+		response.Entries = make([]TournamentEntryResponse, len(tournament.Entries))
+		for i := range tournament.Entries {
+			response.Entries[i] = tournamentEntryResponse(&tournament.Entries[i], viewer)
+		}
+	}
+	if len(tournament.Matches) > 0 {
+		response.Matches = make([]TournamentMatchResponse, len(tournament.Matches))
+		for i := range tournament.Matches {
+			response.Matches[i] = tournamentMatchResponse(&tournament.Matches[i], viewer)
+		}
+	}
+	return response
+}
+
+func tournamentEntryResponse(entry *models.TournamentEntry, viewer *models.User) TournamentEntryResponse {
+	return TournamentEntryResponse{TournamentEntry: *entry, User: dto.User(entry.User, viewer)}
+}
+
+func tournamentMatchResponse(match *models.TournamentMatch, viewer *models.User) TournamentMatchResponse {
+	return TournamentMatchResponse{
+		TournamentMatch: *match,
+		Player1:         dto.User(match.Player1, viewer),
+		Player2:         dto.User(match.Player2, viewer),
+	}
+}
+
+// ListTournaments returns all tournaments
+func (h *TournamentHandler) ListTournaments(c *gin.Context) {
+	tournaments, err := h.tournamentService.ListTournaments()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tournaments"})
+		return
+	}
+
+	viewer := currentUser(c)
+	response := make([]TournamentResponse, len(tournaments))
+	for i := range tournaments {
+		response[i] = tournamentResponse(&tournaments[i], viewer)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTournament returns a tournament with entries and fixtures
+func (h *TournamentHandler) GetTournament(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+
+	tournament, err := h.tournamentService.GetTournamentByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tournamentResponse(tournament, currentUser(c)))
+}
+
+// GetStandings returns the tournament standings
+func (h *TournamentHandler) GetStandings(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+
+	standings, err := h.tournamentService.GetStandings(id)
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Standings(standings, currentUser(c)))
+}
+
+// Register enters the current user into a tournament
+func (h *TournamentHandler) Register(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+
+	entry, err := h.tournamentService.Register(id, user.ID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tournamentEntryResponse(entry, user))
+}
+
+// Withdraw removes the current user from a tournament
+func (h *TournamentHandler) Withdraw(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+
+	if err := h.tournamentService.Withdraw(id, user.ID); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Withdrawn from tournament"})
+}
+
+type CreateTournamentRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Format      string `json:"format" binding:"required,oneof=round_robin knockout"`
+	MaxEntries  int    `json:"max_entries" binding:"min=0"`
+}
+
+// CreateTournament creates a new tournament (admin only)
+func (h *TournamentHandler) CreateTournament(c *gin.Context) {
+	user, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req CreateTournamentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	tournament, err := h.tournamentService.CreateTournament(services.CreateTournamentInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Format:      models.TournamentFormat(req.Format),
+		MaxEntries:  req.MaxEntries,
+		CreatedBy:   user.ID,
+	})
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tournamentResponse(tournament, user))
+}
+
+type GenerateFixturesRequest struct {
+	SessionIDs []uuid.UUID `json:"session_ids"`
+}
+
+// GenerateFixtures closes registration and builds the match schedule (admin only)
+func (h *TournamentHandler) GenerateFixtures(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+
+	var req GenerateFixturesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Sessions are optional, so we don't error if body is empty
+		req.SessionIDs = nil
+	}
+
+	tournament, err := h.tournamentService.GenerateFixtures(id, req.SessionIDs)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tournamentResponse(tournament, currentUser(c)))
+}
+
+type RecordResultRequest struct {
+	Player1Score *int `json:"player1_score" binding:"required"`
+	Player2Score *int `json:"player2_score" binding:"required"`
+}
+
+// RecordResult records the score of a tournament match (admin only)
+func (h *TournamentHandler) RecordResult(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tournament ID"})
+		return
+	}
+
+	matchID, err := uuid.Parse(c.Param("matchId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match ID"})
+		return
+	}
+
+	var req RecordResultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	match, err := h.tournamentService.RecordResult(c.Request.Context(), id, matchID, *req.Player1Score, *req.Player2Score)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tournamentMatchResponse(match, currentUser(c)))
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/dto"
+)
+
+type UsageHandler struct {
+	usageService UsageService
+}
+
+func NewUsageHandler(usageService UsageService) *UsageHandler {
+	return &UsageHandler{usageService: usageService}
+}
+
+type MemberUsageResponse struct {
+	User       *dto.UserResponse `json:"user"`
+	Requests   int               `json:"requests"`
+	ActiveDays int               `json:"active_days"`
+	LastSeenAt *time.Time        `json:"last_seen_at,omitempty"`
+	Level      string            `json:"level"` // active, lapsed or never
+}
+
+// GetUsage shows how much each approved member uses the app over the last
+// ?days= days (default 30), and who hasn't used it lately or at all
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	days := 30
+	if d, err := strconv.Atoi(c.Query("days")); err == nil && d > 0 && d <= 365 {
+		days = d
+	}
+
+	report, err := h.usageService.Report(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get app usage"})
+		return
+	}
+
+	viewer := currentUser(c)
+	members := make([]MemberUsageResponse, len(report.Members))
+	for i := range report.Members {
+		usage := &report.Members[i]
+		members[i] = MemberUsageResponse{
+			User:       dto.User(&usage.User, viewer),
+			Requests:   usage.Requests,
+			ActiveDays: usage.ActiveDays,
+			LastSeenAt: usage.LastSeenAt,
+			Level:      usage.Level,
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"days":    report.Days,
+		"active":  report.Active,
+		"lapsed":  report.Lapsed,
+		"never":   report.Never,
+		"members": members,
+	})
+}
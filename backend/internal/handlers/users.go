@@ -4,18 +4,26 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/dto"
 	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
 	"github.com/weekday-masters/backend/internal/services"
 )
 
 type UserHandler struct {
-	userService *services.UserService
+	userService UserService
 }
 
-func NewUserHandler(userService *services.UserService) *UserHandler {
+func NewUserHandler(userService UserService) *UserHandler {
 	return &UserHandler{userService: userService}
 }
 
+// currentUser returns the authenticated user, or nil on public routes
+func currentUser(c *gin.Context) *models.User {
+	user, _ := middleware.GetUserFromContext(c)
+	return user
+}
+
 // GetMe returns the current user's profile
 func (h *UserHandler) GetMe(c *gin.Context) {
 	user, err := middleware.GetUserFromContext(c)
@@ -24,11 +32,25 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	profile, err := h.userService.GetUserProfile(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.User(profile, user))
 }
 
 type UpdateProfileRequest struct {
-	PhoneNumber string `json:"phone_number"`
+	PhoneNumber           *string `json:"phone_number"`
+	EmergencyContactName  *string `json:"emergency_contact_name" binding:"omitempty,max=255"`
+	EmergencyContactPhone *string `json:"emergency_contact_phone" binding:"omitempty,max=50"`
+	MedicalNotes          *string `json:"medical_notes" binding:"omitempty,max=2000"`
+
+	Privacy *models.PrivacySettings `json:"privacy"`
+
+	// The language notifications are sent in: "en" or "zh"
+	PreferredLanguage *models.Language `json:"preferred_language"`
 }
 
 // UpdateMe updates the current user's profile
@@ -41,20 +63,27 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 
 	var req UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	updatedUser, err := h.userService.UpdateProfile(user.ID, req.PhoneNumber)
+	updatedUser, err := h.userService.UpdateProfile(user.ID, services.ProfileUpdate{
+		PhoneNumber:           req.PhoneNumber,
+		EmergencyContactName:  req.EmergencyContactName,
+		EmergencyContactPhone: req.EmergencyContactPhone,
+		MedicalNotes:          req.MedicalNotes,
+		Privacy:               req.Privacy,
+		PreferredLanguage:     req.PreferredLanguage,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedUser)
+	c.JSON(http.StatusOK, dto.User(updatedUser, user))
 }
 
-// ListMembers returns all approved club members
+// ListMembers returns all approved club members (?fields= trims each one)
 func (h *UserHandler) ListMembers(c *gin.Context) {
 	users, err := h.userService.ListApprovedMembers()
 	if err != nil {
@@ -62,5 +91,5 @@ func (h *UserHandler) ListMembers(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	listWithFields(c, dto.Users(users, currentUser(c)))
 }
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type WeatherProposalHandler struct {
+	proposalService WeatherProposalService
+}
+
+func NewWeatherProposalHandler(proposalService WeatherProposalService) *WeatherProposalHandler {
+	return &WeatherProposalHandler{proposalService: proposalService}
+}
+
+// ProposalResponse is a weather cancellation proposal with its session and
+// the admin who decided it
+type ProposalResponse struct {
+	models.CancellationProposal
+	Session *dto.SessionResponse `json:"session,omitempty"`
+	Decider *dto.UserResponse    `json:"decider,omitempty"`
+}
+
+func proposalResponse(proposal *models.CancellationProposal, viewer *models.User) ProposalResponse {
+	return ProposalResponse{
+		CancellationProposal: *proposal,
+		Session:              dto.Session(proposal.Session, viewer),
+		Decider:              dto.User(proposal.Decider, viewer),
+	}
+}
+
+// ListProposals returns weather cancellation proposals, newest first
+// (?status=pending|approved|declined|lapsed)
+func (h *WeatherProposalHandler) ListProposals(c *gin.Context) {
+	proposals, err := h.proposalService.ListProposals(models.ProposalStatus(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list proposals"})
+		return
+	}
+
+	admin := currentUser(c)
+	response := make([]ProposalResponse, len(proposals))
+	for i := range proposals {
+		response[i] = proposalResponse(&proposals[i], admin)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ApproveProposal cancels the proposal's session
+func (h *WeatherProposalHandler) ApproveProposal(c *gin.Context) {
+	h.decide(c, true)
+}
+
+// DeclineProposal keeps the proposal's session on
+func (h *WeatherProposalHandler) DeclineProposal(c *gin.Context) {
+	h.decide(c, false)
+}
+
+func (h *WeatherProposalHandler) decide(c *gin.Context, approve bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid proposal ID"})
+		return
+	}
+
+	admin := currentUser(c)
+	proposal, err := h.proposalService.Decide(id, admin.ID, approve)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, proposalResponse(proposal, admin))
+}
+
+// DecideFromLink carries out an approve or decline link from an admin's push
+// notification (?admin=&token=), without signing in
+func (h *WeatherProposalHandler) DecideFromLink(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid proposal ID"})
+		return
+	}
+	decision := c.Param("decision")
+	if decision != services.ProposalApprove && decision != services.ProposalDecline {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown decision"})
+		return
+	}
+	adminID, err := uuid.Parse(c.Query("admin"))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": services.ErrProposalLinkInvalid.Error()})
+		return
+	}
+
+	proposal, err := h.proposalService.DecideWithToken(id, adminID, decision, c.Query("token"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": proposal.Status})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+type WidgetHandler struct {
+	widgetService WidgetService
+}
+
+func NewWidgetHandler(widgetService WidgetService) *WidgetHandler {
+	return &WidgetHandler{widgetService: widgetService}
+}
+
+// GetWidget returns the public club summary for embedding on other sites
+func (h *WidgetHandler) GetWidget(c *gin.Context) {
+	widget, err := h.widgetService.GetWidget()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Widget unavailable"})
+		return
+	}
+
+	maxAge := int(services.WidgetCacheTTL.Seconds())
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d", maxAge, maxAge*12))
+	c.JSON(http.StatusOK, widget)
+}
@@ -0,0 +1,45 @@
+package kiosk
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Client calls the kiosk API, for the kiosk service's use
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+func (c *Client) ListSessions(ctx context.Context, req *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	return invoke[ListSessionsResponse](ctx, c.conn, "ListSessions", req, opts)
+}
+
+func (c *Client) GetSession(ctx context.Context, req *GetSessionRequest, opts ...grpc.CallOption) (*GetSessionResponse, error) {
+	return invoke[GetSessionResponse](ctx, c.conn, "GetSession", req, opts)
+}
+
+func (c *Client) CheckIn(ctx context.Context, req *CheckInRequest, opts ...grpc.CallOption) (*CheckInResponse, error) {
+	return invoke[CheckInResponse](ctx, c.conn, "CheckIn", req, opts)
+}
+
+func (c *Client) RecordMatch(ctx context.Context, req *RecordMatchRequest, opts ...grpc.CallOption) (*RecordMatchResponse, error) {
+	return invoke[RecordMatchResponse](ctx, c.conn, "RecordMatch", req, opts)
+}
+
+func (c *Client) VerifyCard(ctx context.Context, req *VerifyCardRequest, opts ...grpc.CallOption) (*VerifyCardResponse, error) {
+	return invoke[VerifyCardResponse](ctx, c.conn, "VerifyCard", req, opts)
+}
+
+func invoke[Resp any](ctx context.Context, conn grpc.ClientConnInterface, method string, req interface{}, opts []grpc.CallOption) (*Resp, error) {
+	out := new(Resp)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(jsonCodec{}.Name())}, opts...)
+	if err := conn.Invoke(ctx, "/"+ServiceName+"/"+method, req, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
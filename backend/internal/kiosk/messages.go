@@ -0,0 +1,91 @@
+package kiosk
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a session as the kiosk shows it
+type Session struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	SessionDate string    `json:"session_date"` // YYYY-MM-DD
+	StartTime   string    `json:"start_time"`
+	EndTime     string    `json:"end_time"`
+	Courts      int       `json:"courts"`
+	MaxPlayers  int       `json:"max_players"`
+	Status      string    `json:"status"`
+}
+
+// Player is someone who RSVP'd IN to a session
+type Player struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	Name        string     `json:"name"`
+	Waitlisted  bool       `json:"waitlisted"` // on the waitlist rather than IN
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
+}
+
+type ListSessionsRequest struct {
+	From string `json:"from,omitempty"` // YYYY-MM-DD, defaults to today
+	To   string `json:"to,omitempty"`   // YYYY-MM-DD
+}
+
+type ListSessionsResponse struct {
+	Sessions []Session `json:"sessions"`
+}
+
+type GetSessionRequest struct {
+	SessionID uuid.UUID `json:"session_id"`
+}
+
+type GetSessionResponse struct {
+	Session Session  `json:"session"`
+	Players []Player `json:"players"`
+}
+
+type CheckInRequest struct {
+	SessionID uuid.UUID `json:"session_id"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+type CheckInResponse struct {
+	Player Player `json:"player"`
+}
+
+// RecordMatchRequest records a game score entered at the kiosk. RecordedBy
+// is one of the players; another member confirms it, as with the app.
+type RecordMatchRequest struct {
+	SessionID  uuid.UUID   `json:"session_id"`
+	TeamA      []uuid.UUID `json:"team_a"`
+	TeamB      []uuid.UUID `json:"team_b"`
+	TeamAScore int         `json:"team_a_score"`
+	TeamBScore int         `json:"team_b_score"`
+	RecordedBy uuid.UUID   `json:"recorded_by"`
+}
+
+type RecordMatchResponse struct {
+	MatchID uuid.UUID `json:"match_id"`
+	Status  string    `json:"status"`
+}
+
+// VerifyCardRequest is a membership card's QR code as scanned at the door
+type VerifyCardRequest struct {
+	Token string `json:"token"`
+}
+
+// Member is who a scanned card belongs to
+type Member struct {
+	UserID           uuid.UUID `json:"user_id"`
+	Name             string    `json:"name"`
+	Tier             string    `json:"tier"`
+	MembershipStatus string    `json:"membership_status"`
+}
+
+// VerifyCardResponse says whether to let the member in. Member is set when
+// the card is genuine, even if the membership has lapsed.
+type VerifyCardResponse struct {
+	Valid  bool    `json:"valid"`
+	Reason string  `json:"reason,omitempty"`
+	Member *Member `json:"member,omitempty"`
+}
@@ -0,0 +1,231 @@
+// Package kiosk is the internal gRPC API for the scoring kiosk at the venue.
+// It covers sessions, check-ins, match results and membership card checks,
+// calling the same services as the REST handlers. Clients authenticate with
+// TLS client certificates.
+package kiosk
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// ServiceName is the gRPC service the kiosk API is served under
+const ServiceName = "weekdaymasters.kiosk.v1.Kiosk"
+
+// KioskServer is the kiosk API
+type KioskServer interface {
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
+	GetSession(context.Context, *GetSessionRequest) (*GetSessionResponse, error)
+	CheckIn(context.Context, *CheckInRequest) (*CheckInResponse, error)
+	RecordMatch(context.Context, *RecordMatchRequest) (*RecordMatchResponse, error)
+	VerifyCard(context.Context, *VerifyCardRequest) (*VerifyCardResponse, error)
+}
+
+// Server implements KioskServer on top of the REST API's services
+type Server struct {
+	sessionService *services.SessionService
+	rsvpService    *services.RSVPService
+	gameService    *services.GameService
+	cardService    *services.CardService
+}
+
+func NewServer(sessionService *services.SessionService, rsvpService *services.RSVPService, gameService *services.GameService, cardService *services.CardService) *Server {
+	return &Server{sessionService: sessionService, rsvpService: rsvpService, gameService: gameService, cardService: cardService}
+}
+
+// NewGRPCServer returns a gRPC server with the kiosk API registered, serving
+// JSON-encoded messages over creds
+func NewGRPCServer(creds credentials.TransportCredentials, kiosk KioskServer) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.Creds(creds),
+		grpc.ForceServerCodec(jsonCodec{}),
+		grpc.UnaryInterceptor(logCalls),
+	)
+	server.RegisterService(&serviceDesc, kiosk)
+	return server
+}
+
+// ListSessions returns sessions that aren't cancelled, from today onwards unless a range is given
+func (s *Server) ListSessions(ctx context.Context, req *ListSessionsRequest) (*ListSessionsResponse, error) {
+	var filter services.SessionListFilter
+	if req.From != "" {
+		from, err := utils.ParseDateInSydney(req.From)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "from must be YYYY-MM-DD")
+		}
+		filter.From = &from
+	}
+	if req.To != "" {
+		to, err := utils.ParseDateInSydney(req.To)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "to must be YYYY-MM-DD")
+		}
+		filter.To = &to
+	}
+
+	sessions, err := s.sessionService.ListSessions(filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &ListSessionsResponse{Sessions: make([]Session, len(sessions))}
+	for i := range sessions {
+		resp.Sessions[i] = toSession(&sessions[i])
+	}
+	return resp, nil
+}
+
+// GetSession returns a session with the players who are IN, in RSVP order,
+// followed by those on the waitlist in their order there
+func (s *Server) GetSession(ctx context.Context, req *GetSessionRequest) (*GetSessionResponse, error) {
+	session, err := s.sessionService.GetSessionByID(req.SessionID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	rsvps, err := s.rsvpService.GetConfirmedPlayers(req.SessionID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	waitlist, err := s.rsvpService.GetWaitlist(req.SessionID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &GetSessionResponse{Session: toSession(session), Players: make([]Player, 0, len(rsvps)+len(waitlist))}
+	for i := range rsvps {
+		resp.Players = append(resp.Players, toPlayer(&rsvps[i]))
+	}
+	for _, entry := range waitlist {
+		resp.Players = append(resp.Players, Player{UserID: entry.UserID, Name: entry.Name, Waitlisted: true})
+	}
+	return resp, nil
+}
+
+// CheckIn records a player's arrival
+func (s *Server) CheckIn(ctx context.Context, req *CheckInRequest) (*CheckInResponse, error) {
+	if _, err := s.rsvpService.CheckIn(req.SessionID, req.UserID); err != nil {
+		return nil, toStatus(err)
+	}
+
+	// Reload with the player's name
+	rsvps, err := s.rsvpService.GetConfirmedPlayers(req.SessionID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	for i := range rsvps {
+		if rsvps[i].UserID == req.UserID {
+			return &CheckInResponse{Player: toPlayer(&rsvps[i])}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "player not found")
+}
+
+// RecordMatch records a game score, pending confirmation by another player
+func (s *Server) RecordMatch(ctx context.Context, req *RecordMatchRequest) (*RecordMatchResponse, error) {
+	game, err := s.gameService.RecordGame(services.RecordGameInput{
+		SessionID:  req.SessionID,
+		TeamA:      req.TeamA,
+		TeamB:      req.TeamB,
+		TeamAScore: req.TeamAScore,
+		TeamBScore: req.TeamBScore,
+		RecordedBy: req.RecordedBy,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &RecordMatchResponse{MatchID: game.ID, Status: string(game.Status)}, nil
+}
+
+// VerifyCard checks a membership card scanned at the door
+func (s *Server) VerifyCard(ctx context.Context, req *VerifyCardRequest) (*VerifyCardResponse, error) {
+	result, err := s.cardService.VerifyCard(req.Token)
+	if errors.Is(err, services.ErrCardsDisabled) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to verify card")
+	}
+
+	resp := &VerifyCardResponse{Valid: result.Valid, Reason: result.Reason}
+	if m := result.Member; m != nil {
+		resp.Member = &Member{
+			UserID:           m.ID,
+			Name:             m.Name,
+			Tier:             string(m.Tier),
+			MembershipStatus: string(m.MembershipStatus),
+		}
+	}
+	return resp, nil
+}
+
+func toSession(s *models.Session) Session {
+	return Session{
+		ID:          s.ID,
+		Title:       s.Title,
+		SessionDate: s.SessionDate.Format("2006-01-02"),
+		StartTime:   utils.FormatClock(s.StartsAt),
+		EndTime:     utils.FormatClock(s.EndsAt),
+		Courts:      s.Courts,
+		MaxPlayers:  s.MaxPlayers,
+		Status:      string(s.Status),
+	}
+}
+
+func toPlayer(r *models.RSVP) Player {
+	player := Player{UserID: r.UserID, Waitlisted: r.Status == models.RSVPStatusWaitlisted, CheckedInAt: r.CheckedInAt}
+	if r.User != nil {
+		player.Name = r.User.Name
+	}
+	return player
+}
+
+// domainErrorCodes maps each kind of services.DomainError to the gRPC code
+// it's answered with, as handlers.domainErrorStatuses does for HTTP
+var domainErrorCodes = []struct {
+	kind error
+	code codes.Code
+}{
+	{services.ErrNotFound, codes.NotFound},
+	{services.ErrForbidden, codes.PermissionDenied},
+	{services.ErrDeadlinePassed, codes.FailedPrecondition},
+	{services.ErrSessionFull, codes.FailedPrecondition},
+	{services.ErrConflict, codes.FailedPrecondition},
+	{services.ErrInvalid, codes.InvalidArgument},
+	{services.ErrRejected, codes.InvalidArgument},
+	{services.ErrUnavailable, codes.Unavailable},
+}
+
+// toStatus maps a service error to a gRPC status. Errors the services
+// haven't typed are internal (a database failure, say), so they're logged
+// and the kiosk gets a generic message.
+func toStatus(err error) error {
+	for _, m := range domainErrorCodes {
+		if errors.Is(err, m.kind) {
+			return status.Error(m.code, err.Error())
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return status.Error(codes.NotFound, "not found")
+	}
+	log.Printf("Kiosk: %v", err)
+	return status.Error(codes.Internal, "something went wrong; please try again")
+}
+
+// logCalls logs each call with the client certificate it came from
+func logCalls(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		log.Printf("Kiosk %s from %s failed: %v", info.FullMethod, clientName(ctx), err)
+	}
+	return resp, err
+}
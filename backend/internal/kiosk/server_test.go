@@ -0,0 +1,44 @@
+package kiosk
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{services.ErrSessionNotFound, codes.NotFound},
+		{gorm.ErrRecordNotFound, codes.NotFound},
+		{fmt.Errorf("checking in: %w", services.ErrCheckInNotIn), codes.FailedPrecondition},
+		{services.ErrRSVPDeadline, codes.FailedPrecondition},
+		{services.ErrCardsDisabled, codes.Unavailable},
+		{errors.New("pq: connection refused"), codes.Internal},
+	}
+	for _, tt := range tests {
+		got := status.Convert(toStatus(tt.err))
+		if got.Code() != tt.code {
+			t.Errorf("toStatus(%v) = %s, want %s", tt.err, got.Code(), tt.code)
+		}
+		if tt.code == codes.Internal && got.Message() == tt.err.Error() {
+			t.Errorf("toStatus(%v) passed on the internal error", tt.err)
+		}
+	}
+}
+
+func TestToPlayerWaitlisted(t *testing.T) {
+	for status, want := range map[models.RSVPStatus]bool{models.RSVPStatusIn: false, models.RSVPStatusWaitlisted: true} {
+		if got := toPlayer(&models.RSVP{Status: status}).Waitlisted; got != want {
+			t.Errorf("%s player waitlisted = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package kiosk
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// serviceDesc is written by hand in place of protoc output, since messages
+// are plain structs encoded as JSON
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*KioskServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("ListSessions", KioskServer.ListSessions),
+		unaryMethod("GetSession", KioskServer.GetSession),
+		unaryMethod("CheckIn", KioskServer.CheckIn),
+		unaryMethod("RecordMatch", KioskServer.RecordMatch),
+		unaryMethod("VerifyCard", KioskServer.VerifyCard),
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "kiosk",
+}
+
+// unaryMethod adapts a KioskServer method to a gRPC method handler
+func unaryMethod[Req, Resp any](name string, call func(KioskServer, context.Context, *Req) (*Resp, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(Req)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(KioskServer), ctx, req.(*Req))
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + name}
+			return interceptor(ctx, in, info, handler)
+		},
+	}
+}
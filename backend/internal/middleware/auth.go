@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/weekday-masters/backend/internal/database"
 	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/resilience"
 )
 
 type Auth0Config struct {
@@ -36,6 +38,10 @@ type JSONWebKey struct {
 var jwksCache *JWKS
 var jwksCacheTime time.Time
 
+// jwksBreaker stops hammering Auth0 during an outage; a stale cached key set
+// is served instead while it is open
+var jwksBreaker = resilience.NewCircuitBreaker("jwks", 3, 30*time.Second)
+
 func getJWKS(domain string) (*JWKS, error) {
 	// Cache JWKS for 1 hour
 	if jwksCache != nil && time.Since(jwksCacheTime) < time.Hour {
@@ -47,19 +53,39 @@ func getJWKS(domain string) (*JWKS, error) {
 	}
 
 	jwksURL := fmt.Sprintf("https://%s/.well-known/jwks.json", domain)
-	resp, err := http.Get(jwksURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch JWKS from %s: %w", jwksURL, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
-	}
 
 	var jwks JWKS
-	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
-		return nil, fmt.Errorf("failed to decode JWKS response: %w", err)
+	err := resilience.Do(context.Background(), resilience.DefaultPolicy, jwksBreaker, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+		if err != nil {
+			return resilience.Permanent(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to fetch JWKS from %s: %w", jwksURL, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			err := fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+			if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+				return resilience.Permanent(err)
+			}
+			return err
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+			return resilience.Permanent(fmt.Errorf("failed to decode JWKS response: %w", err))
+		}
+		return nil
+	})
+	if err != nil {
+		if jwksCache != nil {
+			// Keys rotate rarely; a stale set beats rejecting every request
+			log.Printf("Warning: using stale JWKS cache: %v", err)
+			return jwksCache, nil
+		}
+		return nil, err
 	}
 
 	jwksCache = &jwks
@@ -78,107 +104,232 @@ func getKeyFromJWKS(jwks *JWKS, kid string) (string, error) {
 	return "", errors.New("unable to find key")
 }
 
+// authenticate validates the bearer token and returns its claims. On failure
+// it writes the error response, aborts, and returns false.
+func authenticate(c *gin.Context, config Auth0Config) (jwt.MapClaims, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+		c.Abort()
+		return nil, false
+	}
+
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token required"})
+		c.Abort()
+		return nil, false
+	}
+
+	// Parse token without validation first to get the kid
+	unverifiedToken, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
+		c.Abort()
+		return nil, false
+	}
+
+	kid, ok := unverifiedToken.Header["kid"].(string)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token missing key ID"})
+		c.Abort()
+		return nil, false
+	}
+
+	// Get JWKS
+	jwks, err := getJWKS(config.Domain)
+	if err != nil {
+		log.Printf("JWKS fetch error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch JWKS"})
+		c.Abort()
+		return nil, false
+	}
+
+	// Get the key
+	certPEM, err := getKeyFromJWKS(jwks, kid)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unable to find key"})
+		c.Abort()
+		return nil, false
+	}
+
+	cert, err := jwt.ParseRSAPublicKeyFromPEM([]byte(certPEM))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid certificate"})
+		c.Abort()
+		return nil, false
+	}
+
+	// Validate the token
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return cert, nil
+	}, jwt.WithAudience(config.Audience), jwt.WithIssuer(fmt.Sprintf("https://%s/", config.Domain)))
+
+	if err != nil || !token.Valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return nil, false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid claims"})
+		c.Abort()
+		return nil, false
+	}
+
+	c.Set("accessToken", tokenString)
+	return claims, true
+}
+
 // AuthMiddleware validates JWT tokens from Auth0
 func AuthMiddleware(config Auth0Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+		claims, ok := authenticate(c, config)
+		if !ok {
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token required"})
+		// Extract user info from token
+		sub, _ := claims["sub"].(string)
+
+		// Get user from database
+		var user models.User
+		result := database.DB.Where("auth0_id = ?", sub).First(&user)
+		if result.Error != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found. Please complete registration."})
 			c.Abort()
 			return
 		}
 
-		// Parse token without validation first to get the kid
-		unverifiedToken, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
-			c.Abort()
+		// Store user in context
+		c.Set("user", &user)
+		c.Set("userID", user.ID)
+		c.Set("auth0ID", sub)
+		c.Set("claims", claims)
+
+		c.Next()
+	}
+}
+
+// TokenMiddleware validates the Auth0 token without requiring a registered
+// user, for the registration callback itself
+func TokenMiddleware(config Auth0Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := authenticate(c, config)
+		if !ok {
 			return
 		}
 
-		kid, ok := unverifiedToken.Header["kid"].(string)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token missing key ID"})
+		sub, _ := claims["sub"].(string)
+		if sub == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token missing subject"})
 			c.Abort()
 			return
 		}
 
-		// Get JWKS
-		jwks, err := getJWKS(config.Domain)
-		if err != nil {
-			// Log the actual error for debugging
-			fmt.Printf("JWKS fetch error: %v\n", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch JWKS", "details": err.Error()})
+		c.Set("auth0ID", sub)
+		c.Next()
+	}
+}
+
+// Auth0UserInfo is the profile returned by Auth0's /userinfo endpoint
+type Auth0UserInfo struct {
+	Sub           string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+}
+
+// GetAuth0UserInfo fetches the token holder's profile from Auth0 so identity
+// details come from Auth0 rather than the client
+func GetAuth0UserInfo(ctx context.Context, domain, accessToken string) (*Auth0UserInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s/userinfo", domain), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("userinfo endpoint returned status %d", resp.StatusCode)
+	}
+
+	var info Auth0UserInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode user info: %w", err)
+	}
+	return &info, nil
+}
+
+// RequireApproved ensures the user has approved membership
+func RequireApproved() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
 			c.Abort()
 			return
 		}
 
-		// Get the key
-		certPEM, err := getKeyFromJWKS(jwks, kid)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unable to find key"})
+		u, ok := user.(*models.User)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type"})
 			c.Abort()
 			return
 		}
 
-		cert, err := jwt.ParseRSAPublicKeyFromPEM([]byte(certPEM))
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid certificate"})
+		if !u.IsApproved() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Membership not approved"})
 			c.Abort()
 			return
 		}
 
-		// Validate the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return cert, nil
-		}, jwt.WithAudience(config.Audience), jwt.WithIssuer(fmt.Sprintf("https://%s/", config.Domain)))
+		c.Next()
+	}
+}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+// RequireAdmin ensures the user has admin role
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
+		u, ok := user.(*models.User)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid claims"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type"})
 			c.Abort()
 			return
 		}
 
-		// Extract user info from token
-		sub, _ := claims["sub"].(string)
-
-		// Get user from database
-		var user models.User
-		result := database.DB.Where("auth0_id = ?", sub).First(&user)
-		if result.Error != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found. Please complete registration."})
+		if !u.IsAdmin() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
 			c.Abort()
 			return
 		}
 
-		// Store user in context
-		c.Set("user", &user)
-		c.Set("userID", user.ID)
-		c.Set("auth0ID", sub)
-
 		c.Next()
 	}
 }
 
-// RequireApproved ensures the user has approved membership
-func RequireApproved() gin.HandlerFunc {
+// RequireCoach ensures the user is a coach or an admin
+func RequireCoach() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, exists := c.Get("user")
 		if !exists {
@@ -194,8 +345,8 @@ func RequireApproved() gin.HandlerFunc {
 			return
 		}
 
-		if !u.IsApproved() {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Membership not approved"})
+		if !u.IsCoach() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Coach access required"})
 			c.Abort()
 			return
 		}
@@ -204,8 +355,8 @@ func RequireApproved() gin.HandlerFunc {
 	}
 }
 
-// RequireAdmin ensures the user has admin role
-func RequireAdmin() gin.HandlerFunc {
+// RequireCommittee ensures the user sits on the committee or is an admin
+func RequireCommittee() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, exists := c.Get("user")
 		if !exists {
@@ -221,8 +372,8 @@ func RequireAdmin() gin.HandlerFunc {
 			return
 		}
 
-		if !u.IsAdmin() {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		if !u.IsCommittee() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Committee access required"})
 			c.Abort()
 			return
 		}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testAuth0 = Auth0Config{Domain: "club.example.auth0.com", Audience: "https://api.example.com"}
+
+const testKid = "test-key"
+
+// fakeJWKS serves a key set holding a new self-signed certificate in place
+// of Auth0's, returning its private key for signing tokens
+func fakeJWKS(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: testAuth0.Domain},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwksCache = &JWKS{Keys: []JSONWebKey{{Kty: "RSA", Kid: testKid, Use: "sig", X5c: []string{base64.StdEncoding.EncodeToString(der)}}}}
+	jwksCacheTime = time.Now()
+	t.Cleanup(func() { jwksCache, jwksCacheTime = nil, time.Time{} })
+	return key
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": "auth0|member",
+		"aud": testAuth0.Audience,
+		"iss": "https://" + testAuth0.Domain + "/",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// callWithToken passes a request bearing token through TokenMiddleware
+func callWithToken(token string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", TokenMiddleware(testAuth0), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("auth0ID"))
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestTokenMiddlewareAcceptsValidToken(t *testing.T) {
+	key := fakeJWKS(t)
+	w := callWithToken(sign(t, jwt.SigningMethodRS256, key, testKid, validClaims()))
+	if w.Code != http.StatusOK || w.Body.String() != "auth0|member" {
+		t.Errorf("got %d %q, want 200 for auth0|member", w.Code, w.Body.String())
+	}
+}
+
+func TestTokenMiddlewareRejectsBadTokens(t *testing.T) {
+	key := fakeJWKS(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	with := func(name string, value interface{}) jwt.MapClaims {
+		claims := validClaims()
+		claims[name] = value
+		return claims
+	}
+	certDER, _ := base64.StdEncoding.DecodeString(jwksCache.Keys[0].X5c[0])
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"no token", ""},
+		{"not a JWT", "not-a-token"},
+		{"no key ID", sign(t, jwt.SigningMethodRS256, key, "", validClaims())},
+		{"unknown key ID", sign(t, jwt.SigningMethodRS256, key, "other-key", validClaims())},
+		{"signed by another key", sign(t, jwt.SigningMethodRS256, otherKey, testKid, validClaims())},
+		{"wrong audience", sign(t, jwt.SigningMethodRS256, key, testKid, with("aud", "https://other.example.com"))},
+		{"wrong issuer", sign(t, jwt.SigningMethodRS256, key, testKid, with("iss", "https://evil.example.com/"))},
+		{"expired", sign(t, jwt.SigningMethodRS256, key, testKid, with("exp", time.Now().Add(-time.Minute).Unix()))},
+		// The public certificate used as an HMAC secret
+		{"HS256 with the public key", sign(t, jwt.SigningMethodHS256, certDER, testKid, validClaims())},
+		{"alg none", sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, testKid, validClaims())},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := callWithToken(tt.token); w.Code != http.StatusUnauthorized {
+				t.Errorf("got %d, want 401", w.Code)
+			}
+		})
+	}
+}
+
+// TestTokenMiddlewareHidesJWKSErrors checks a failed key fetch answers 500
+// without passing on why
+func TestTokenMiddlewareHidesJWKSErrors(t *testing.T) {
+	key := fakeJWKS(t)
+	token := sign(t, jwt.SigningMethodRS256, key, testKid, validClaims())
+	jwksCache = nil
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", TokenMiddleware(Auth0Config{Audience: testAuth0.Audience}), func(c *gin.Context) {})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("got %d, want 500", w.Code)
+	}
+	if body := w.Body.String(); strings.Contains(body, "AUTH0_DOMAIN") || strings.Contains(body, "details") {
+		t.Errorf("response %s gives away the cause", body)
+	}
+}
@@ -1,18 +1,60 @@
 package middleware
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-func CORS(frontendURL string) gin.HandlerFunc {
+// IsPublicPath reports whether path is under /api/public/ (or a versioned
+// /api/v1/public/), where endpoints meant for embedding on other sites live.
+// They're served to any origin by PublicCORS instead of the app's CORS policy.
+func IsPublicPath(path string) bool {
+	rest, ok := strings.CutPrefix(path, "/api/")
+	if !ok {
+		return false
+	}
+	if version, after, found := strings.Cut(rest, "/"); found && IsSupportedAPIVersion(version) {
+		rest = after
+	}
+	return strings.HasPrefix(rest, "public/")
+}
+
+// CORS allows requests from origins accepted by allowOrigin, which is
+// consulted per request so the allowed list can change at runtime
+func CORS(allowOrigin func(origin string) bool) gin.HandlerFunc {
 	config := cors.Config{
-		AllowOrigins:     []string{frontendURL},
+		AllowOriginFunc:  allowOrigin,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", APIVersionHeader},
+		ExposeHeaders:    []string{"Content-Length", APIVersionHeader, "Deprecation", "Sunset", "Link"},
 		AllowCredentials: true,
 	}
 
-	return cors.New(config)
+	handler := cors.New(config)
+	return func(c *gin.Context) {
+		if IsPublicPath(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		handler(c)
+	}
+}
+
+// PublicCORS allows read-only, credential-less requests from any origin
+func PublicCORS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Header("Access-Control-Allow-Methods", "GET, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Accept, Content-Type, "+APIVersionHeader)
+		c.Header("Access-Control-Expose-Headers", APIVersionHeader+", Deprecation, Sunset, Link")
+		c.Header("Access-Control-Max-Age", "86400")
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
 }
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireFeature answers 404 while enabled reports feature turned off. It's
+// consulted per request so features can be switched by a config reload.
+func RequireFeature(enabled func(feature string) bool, feature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled(feature) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "This feature is turned off"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type rateWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// Limit is the number of requests a rate limit allows, which can be changed
+// while it's in use so limits can be reloaded from config
+type Limit struct {
+	n atomic.Int64
+}
+
+func NewLimit(n int) *Limit {
+	l := &Limit{}
+	l.Set(n)
+	return l
+}
+
+// Set changes the limit; windows already started count against the new one
+func (l *Limit) Set(n int) {
+	l.n.Store(int64(n))
+}
+
+func (l *Limit) get() int {
+	return int(l.n.Load())
+}
+
+// RateLimitByIP allows at most limit requests per client IP in each window
+func RateLimitByIP(limit *Limit, window time.Duration) gin.HandlerFunc {
+	return rateLimit(limit, window, func(c *gin.Context) string { return c.ClientIP() })
+}
+
+// RateLimitByAccount allows at most limit requests per Auth0 login in each
+// window, however many addresses they come from. It must follow
+// TokenMiddleware or AuthMiddleware, which identify the login.
+func RateLimitByAccount(limit *Limit, window time.Duration) gin.HandlerFunc {
+	return rateLimit(limit, window, func(c *gin.Context) string { return c.GetString("auth0ID") })
+}
+
+// rateLimit allows at most limit requests per key in each window
+func rateLimit(limit *Limit, window time.Duration, keyOf func(*gin.Context) string) gin.HandlerFunc {
+	var mu sync.Mutex
+	windows := make(map[string]*rateWindow)
+	lastSweep := time.Now()
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		key := keyOf(c)
+
+		mu.Lock()
+		// Drop expired entries now and then so the map doesn't grow forever
+		if now.Sub(lastSweep) > window {
+			for k, w := range windows {
+				if now.After(w.resetAt) {
+					delete(windows, k)
+				}
+			}
+			lastSweep = now
+		}
+
+		w, ok := windows[key]
+		if !ok || now.After(w.resetAt) {
+			w = &rateWindow{resetAt: now.Add(window)}
+			windows[key] = w
+		}
+		w.count++
+		exceeded := w.count > limit.get()
+		retryAfter := w.resetAt.Sub(now)
+		mu.Unlock()
+
+		if exceeded {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
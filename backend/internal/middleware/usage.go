@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UsageRecorder counts a signed-in member's API request
+type UsageRecorder interface {
+	Record(userID uuid.UUID)
+}
+
+// TrackUsage counts each request by the signed-in member against them. Must
+// run after AuthMiddleware.
+func TrackUsage(recorder UsageRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID, ok := c.Get("userID"); ok {
+			if id, ok := userID.(uuid.UUID); ok {
+				recorder.Record(id)
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API versions. Routes are served under /api/<version>, and under plain /api
+// for clients that predate versioning. A breaking change to an endpoint goes
+// behind a new version: add it here and branch on APIVersionFrom in the
+// handler, leaving the older versions' responses alone.
+const (
+	APIVersion1 = "v1"
+
+	// DefaultAPIVersion serves unversioned requests, so the existing PWA keeps working
+	DefaultAPIVersion = APIVersion1
+
+	// APIVersionHeader lets clients of unversioned paths ask for a version,
+	// and reports the version that served every response
+	APIVersionHeader = "API-Version"
+)
+
+// SupportedAPIVersions lists the versions mounted under /api, oldest first
+var SupportedAPIVersions = []string{APIVersion1}
+
+const apiVersionKey = "api_version"
+
+// IsSupportedAPIVersion reports whether version is mounted
+func IsSupportedAPIVersion(version string) bool {
+	return containsVersion(SupportedAPIVersions, version)
+}
+
+// APIVersion pins requests on a versioned prefix such as /api/v1 to that version
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setAPIVersion(c, version)
+		c.Next()
+	}
+}
+
+// NegotiateAPIVersion picks the version for unversioned /api requests from the
+// API-Version header, falling back to DefaultAPIVersion
+func NegotiateAPIVersion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := strings.ToLower(strings.TrimSpace(c.GetHeader(APIVersionHeader)))
+		if version == "" {
+			version = DefaultAPIVersion
+		}
+		if !IsSupportedAPIVersion(version) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":              fmt.Sprintf("Unsupported API version %q", version),
+				"supported_versions": SupportedAPIVersions,
+			})
+			return
+		}
+		setAPIVersion(c, version)
+		c.Next()
+	}
+}
+
+func setAPIVersion(c *gin.Context, version string) {
+	c.Set(apiVersionKey, version)
+	c.Header(APIVersionHeader, version)
+}
+
+// APIVersionFrom returns the version serving the request
+func APIVersionFrom(c *gin.Context) string {
+	if version := c.GetString(apiVersionKey); version != "" {
+		return version
+	}
+	return DefaultAPIVersion
+}
+
+// Deprecation describes an endpoint that is going away
+type Deprecation struct {
+	// Versions the deprecation applies to; empty means all of them
+	Versions []string
+	// Since is when the endpoint was deprecated
+	Since time.Time
+	// Sunset, if set, is when the endpoint will stop working
+	Sunset *time.Time
+	// Successor, if set, is the URL of the replacement
+	Successor string
+}
+
+// Deprecated adds Deprecation, Sunset and Link headers (RFC 9745 and RFC 8594)
+// to responses from an endpoint, so clients can find out before it's removed
+func Deprecated(d Deprecation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(d.Versions) > 0 && !containsVersion(d.Versions, APIVersionFrom(c)) {
+			c.Next()
+			return
+		}
+		c.Header("Deprecation", fmt.Sprintf("@%d", d.Since.Unix()))
+		if d.Sunset != nil {
+			c.Header("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if d.Successor != "" {
+			c.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, d.Successor))
+		}
+		c.Next()
+	}
+}
+
+func containsVersion(versions []string, version string) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
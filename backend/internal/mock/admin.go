@@ -0,0 +1,783 @@
+package mock
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+// Archive thresholds, the real server's defaults
+const (
+	archiveNotificationsAfterMonths = 6
+	archiveRSVPsAfterMonths         = 24
+)
+
+// clubBundleVersion is the bundle version the real export writes
+const clubBundleVersion = 1
+
+// activeUsageDays is how recently a member must have used the app to count
+// as active in the usage report
+const activeUsageDays = 14
+
+// today is the start of the current day in Sydney
+func today() time.Time {
+	return utils.StartOfDay(utils.NowInSydney())
+}
+
+// confirmed counts the IN RSVPs to a session. The caller holds s.mu.
+func (s *Server) confirmed(sessionID uuid.UUID) int {
+	return len(filter(s.rsvps[sessionID], func(r models.RSVP) bool { return r.Status == models.RSVPStatusIn }))
+}
+
+// spotsLeft is how many more can RSVP IN to a session. The caller holds
+// s.mu.
+func (s *Server) spotsLeft(session *models.Session) int {
+	return max(session.RSVPCapacity()-s.confirmed(session.ID)-len(s.guests[session.ID]), 0)
+}
+
+// memberName is a member's name, or a stand-in for one who has left. The
+// caller holds s.mu.
+func (s *Server) memberName(userID uuid.UUID) string {
+	if u := s.user(userID); u != nil && u.Name != "" {
+		return u.Name
+	}
+	return "A member"
+}
+
+// reportService stands in for services.ReportService
+type reportService struct{ *Server }
+
+// GetMonthlyReport returns session and shuttle usage for month, with a
+// shuttle forecast for the month after
+func (s reportService) GetMonthlyReport(monthStart time.Time) (*services.MonthlyReport, error) {
+	monthStart = monthStart.In(utils.SydneyLocation)
+	start := time.Date(monthStart.Year(), monthStart.Month(), 1, 0, 0, 0, 0, utils.SydneyLocation)
+	end := start.AddDate(0, 1, 0)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report := &services.MonthlyReport{Month: start.Format("2006-01"), Sessions: []services.SessionUsage{}}
+	for _, session := range s.sessionViews(func(*models.Session) bool { return true }) {
+		if session.Status == models.SessionStatusCancelled {
+			if !session.SessionDate.Before(start) && session.SessionDate.Before(end) {
+				report.SessionsCancelled++
+			}
+			continue
+		}
+		if !session.SessionDate.Before(end) && session.SessionDate.Before(end.AddDate(0, 1, 0)) {
+			report.UpcomingSessions++
+		}
+		if session.SessionDate.Before(start) || !session.SessionDate.Before(end) {
+			continue
+		}
+		usage := services.SessionUsage{
+			SessionID:       session.ID,
+			Title:           session.Title,
+			SessionDate:     session.SessionDate,
+			Courts:          session.Courts,
+			Attendees:       session.ConfirmedCount,
+			ShuttlesUsed:    session.ShuttlesUsed,
+			DurationMinutes: session.ActualDurationMinutes(),
+		}
+		if session.ShuttlesUsed != nil {
+			report.TotalShuttlesUsed += *session.ShuttlesUsed
+			report.SessionsWithUsage++
+			if usage.Attendees > 0 {
+				usage.ShuttlesPerHead = float64(*session.ShuttlesUsed) / float64(usage.Attendees)
+			}
+		}
+		report.SessionsHeld++
+		report.TotalAttendees += usage.Attendees
+		report.TotalMinutesPlayed += usage.DurationMinutes
+		report.Sessions = append(report.Sessions, usage)
+	}
+	if report.SessionsWithUsage > 0 {
+		report.AvgShuttlesPerSession = float64(report.TotalShuttlesUsed) / float64(report.SessionsWithUsage)
+	}
+	report.ForecastShuttlesNeeded = int(report.AvgShuttlesPerSession*float64(report.UpcomingSessions) + 0.5)
+	return report, nil
+}
+
+// GetAttendanceReport compares confirmed RSVPs with attendance at the
+// sessions held between from and to. Sessions nobody took attendance at
+// are counted but left out.
+func (s reportService) GetAttendanceReport(from, to time.Time) (*services.AttendanceReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report := &services.AttendanceReport{Members: []services.MemberAttendance{}, Months: []services.MonthAttendance{}}
+	members := map[uuid.UUID]*services.MemberAttendance{}
+	member := func(userID uuid.UUID) *services.MemberAttendance {
+		if m, ok := members[userID]; ok {
+			return m
+		}
+		m := &services.MemberAttendance{UserID: userID, Name: s.memberName(userID)}
+		members[userID] = m
+		return m
+	}
+
+	now := time.Now()
+	held := s.sessionViews(func(session *models.Session) bool {
+		return inRange(session.SessionDate, &from, &to) && session.Status != models.SessionStatusCancelled && session.EndsAt.Before(now)
+	})
+	for _, session := range held {
+		records := s.attendance[session.ID]
+		if len(records) == 0 {
+			report.SessionsUntracked++
+			continue
+		}
+		report.SessionsTracked++
+		monthName := session.SessionDate.Format("2006-01")
+		if len(report.Months) == 0 || report.Months[len(report.Months)-1].Month != monthName {
+			report.Months = append(report.Months, services.MonthAttendance{Month: monthName})
+		}
+		month := &report.Months[len(report.Months)-1]
+		month.SessionsTracked++
+
+		recorded := make(map[uuid.UUID]models.AttendanceStatus, len(records))
+		for _, a := range records {
+			recorded[a.UserID] = a.Status
+		}
+		confirmed := make(map[uuid.UUID]bool)
+		for _, rsvp := range s.rsvps[session.ID] {
+			if rsvp.Status != models.RSVPStatusIn {
+				continue
+			}
+			confirmed[rsvp.UserID] = true
+			m := member(rsvp.UserID)
+			m.Confirmed++
+			month.Confirmed++
+			switch recorded[rsvp.UserID] {
+			case models.AttendancePresent:
+				m.Attended++
+			case models.AttendanceExcused:
+				m.Excused++
+			default:
+				m.NoShows++
+				month.NoShows++
+				if m.LastNoShow == nil || session.StartsAt.After(*m.LastNoShow) {
+					startsAt := session.StartsAt
+					m.LastNoShow = &startsAt
+				}
+			}
+		}
+		for _, a := range records {
+			if a.Status == models.AttendancePresent && !confirmed[a.UserID] {
+				member(a.UserID).WalkIns++
+			}
+		}
+	}
+
+	for i := range report.Months {
+		if report.Months[i].Confirmed > 0 {
+			report.Months[i].NoShowRate = float64(report.Months[i].NoShows) / float64(report.Months[i].Confirmed)
+		}
+	}
+	for _, m := range members {
+		if m.Confirmed > 0 {
+			m.Reliability = float64(m.Attended+m.Excused) / float64(m.Confirmed)
+		}
+		report.Members = append(report.Members, *m)
+	}
+	sort.Slice(report.Members, func(i, j int) bool {
+		a, b := report.Members[i], report.Members[j]
+		if a.NoShows != b.NoShows {
+			return a.NoShows > b.NoShows
+		}
+		return a.Name < b.Name
+	})
+	return report, nil
+}
+
+// statsService stands in for services.StatsService
+type statsService struct{ *Server }
+
+// GetRecap is what a member did in month's calendar month, so far for the
+// current one
+func (s statsService) GetRecap(userID uuid.UUID, month time.Time) (*services.MemberRecap, error) {
+	month = month.In(utils.SydneyLocation)
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, utils.SydneyLocation)
+	end := start.AddDate(0, 1, 0)
+	inMonth := func(session *models.Session) bool {
+		return !session.SessionDate.Before(start) && session.SessionDate.Before(end)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recap := &services.MemberRecap{Month: start.Format("2006-01"), BadgesEarned: []models.UserBadge{}}
+	var dates []time.Time
+	for _, session := range s.sessionViews(func(*models.Session) bool { return true }) {
+		rsvp := s.rsvpFor(session.ID, userID)
+		if rsvp == nil || rsvp.Status != models.RSVPStatusIn || session.Status == models.SessionStatusCancelled {
+			continue
+		}
+		if session.SessionDate.Before(today()) {
+			if session.SessionDate.Before(end) {
+				dates = append(dates, session.SessionDate)
+			}
+			if inMonth(&session) {
+				recap.SessionsPlayed++
+			}
+		}
+		if inMonth(&session) && !rsvp.AddedByAdmin {
+			seconds := int(rsvp.RSVPTimestamp.Sub(session.CreatedAt).Seconds())
+			if recap.FastestRSVPSeconds == nil || seconds < *recap.FastestRSVPSeconds {
+				recap.FastestRSVPSeconds = &seconds
+			}
+		}
+	}
+	for _, g := range s.games {
+		session := s.sessionByID(g.SessionID)
+		if g.Status != models.GameStatusConfirmed || session == nil || !inMonth(session) {
+			continue
+		}
+		for _, p := range g.Players {
+			if p.UserID == userID {
+				recap.GamesPlayed++
+				if g.WinningTeam() == p.Team {
+					recap.GamesWon++
+				}
+			}
+		}
+	}
+	if user := s.user(userID); user != nil {
+		for _, badge := range user.Badges {
+			if !badge.AwardedAt.Before(start) && badge.AwardedAt.Before(end) {
+				recap.BadgesEarned = append(recap.BadgesEarned, badge)
+			}
+		}
+	}
+
+	asOf := end.AddDate(0, 0, -1)
+	if now := utils.NowInSydney(); now.Before(asOf) {
+		asOf = now
+	}
+	recap.Streak = streak(dates, utils.StartOfWeek(asOf))
+	return recap, nil
+}
+
+// streak works out weekly streaks from the dates of sessions played, in
+// order, as the stats service does: the current run counts while its last
+// week is this week or the one before
+func streak(dates []time.Time, thisWeek time.Time) services.Streak {
+	var weeks []time.Time
+	for _, d := range dates {
+		if week := utils.StartOfWeek(d); len(weeks) == 0 || !weeks[len(weeks)-1].Equal(week) {
+			weeks = append(weeks, week)
+		}
+	}
+
+	var streak services.Streak
+	run := 0
+	for i, week := range weeks {
+		if i > 0 && weeks[i-1].AddDate(0, 0, 7).Equal(week) {
+			run++
+		} else {
+			run = 1
+		}
+		streak.LongestWeeks = max(streak.LongestWeeks, run)
+	}
+	if len(weeks) > 0 {
+		if last := weeks[len(weeks)-1]; last.Equal(thisWeek) || last.AddDate(0, 0, 7).Equal(thisWeek) {
+			streak.CurrentWeeks = run
+		}
+	}
+	return streak
+}
+
+// GetFastestFingers ranks the five members who RSVP'd IN soonest after the
+// sessions of week's week were posted
+func (s statsService) GetFastestFingers(week time.Time) ([]services.FastestFinger, error) {
+	weekStart := utils.StartOfWeek(week)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	quickest := map[uuid.UUID]int{}
+	for _, session := range s.sessions {
+		if session.Status == models.SessionStatusCancelled || session.SessionDate.Before(weekStart) || !session.SessionDate.Before(weekStart.AddDate(0, 0, 7)) {
+			continue
+		}
+		for _, rsvp := range s.rsvps[session.ID] {
+			if rsvp.Status != models.RSVPStatusIn || rsvp.AddedByAdmin {
+				continue
+			}
+			seconds := int(rsvp.RSVPTimestamp.Sub(session.CreatedAt).Seconds())
+			if best, ok := quickest[rsvp.UserID]; !ok || seconds < best {
+				quickest[rsvp.UserID] = seconds
+			}
+		}
+	}
+
+	board := []services.FastestFinger{}
+	for userID, seconds := range quickest {
+		entry := services.FastestFinger{UserID: userID, Name: "A former member", Seconds: seconds, User: s.user(userID)}
+		if entry.User != nil {
+			entry.Name = entry.User.Name
+		}
+		board = append(board, entry)
+	}
+	sort.Slice(board, func(i, j int) bool {
+		if board[i].Seconds != board[j].Seconds {
+			return board[i].Seconds < board[j].Seconds
+		}
+		return board[i].Name < board[j].Name
+	})
+	return page(board, 5, 0), nil
+}
+
+// usageService stands in for services.UsageService
+type usageService struct{ *Server }
+
+func (s usageService) Record(userID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	day, now := today(), time.Now()
+	i := indexOf(s.apiUsage, func(u models.APIUsage) bool { return u.UserID == userID && u.Day.Equal(day) })
+	if i < 0 {
+		s.apiUsage = append(s.apiUsage, models.APIUsage{UserID: userID, Day: day})
+		i = len(s.apiUsage) - 1
+	}
+	s.apiUsage[i].Requests++
+	s.apiUsage[i].LastSeenAt = now
+}
+
+// Report sums each approved member's use over the last days days, most
+// recently seen first
+func (s usageService) Report(days int) (*services.UsageReport, error) {
+	since := today().AddDate(0, 0, -(days - 1))
+	activeSince := time.Now().AddDate(0, 0, -activeUsageDays)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	members := s.usersWhere(func(u models.User) bool { return u.MembershipStatus == models.MembershipApproved })
+	sort.SliceStable(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+	report := &services.UsageReport{Days: days, Members: make([]services.MemberUsage, len(members))}
+	for i, member := range members {
+		usage := services.MemberUsage{User: member, Level: services.UsageNever}
+		for _, day := range s.apiUsage {
+			if day.UserID != member.ID {
+				continue
+			}
+			if !day.Day.Before(since) {
+				usage.Requests += day.Requests
+				usage.ActiveDays++
+			}
+			if usage.LastSeenAt == nil || day.LastSeenAt.After(*usage.LastSeenAt) {
+				lastSeen := day.LastSeenAt
+				usage.LastSeenAt = &lastSeen
+			}
+		}
+		switch {
+		case usage.LastSeenAt == nil:
+			report.Never++
+		case usage.LastSeenAt.After(activeSince):
+			usage.Level = services.UsageActive
+			report.Active++
+		default:
+			usage.Level = services.UsageLapsed
+			report.Lapsed++
+		}
+		report.Members[i] = usage
+	}
+	sort.SliceStable(report.Members, func(i, j int) bool {
+		a, b := report.Members[i].LastSeenAt, report.Members[j].LastSeenAt
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.After(*b)
+	})
+	return report, nil
+}
+
+// jobService stands in for services.JobService
+type jobService struct{ *Server }
+
+func (s jobService) JobStatuses() ([]services.JobStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byName := map[string]*services.JobStatus{}
+	for _, run := range s.jobRuns {
+		status, ok := byName[run.JobName]
+		if !ok {
+			status = &services.JobStatus{Name: run.JobName}
+			byName[run.JobName] = status
+		}
+		if status.LastRun == nil || run.StartedAt.After(status.LastRun.StartedAt) {
+			last := run
+			status.LastRun = &last
+		}
+		if run.Status == models.JobRunStatusSucceeded && run.FinishedAt != nil &&
+			(status.LastSucceededAt == nil || run.FinishedAt.After(*status.LastSucceededAt)) {
+			status.LastSucceededAt = run.FinishedAt
+		}
+	}
+	statuses := []services.JobStatus{}
+	for _, status := range byName {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+func (s jobService) ListJobRuns(name string, limit int) ([]models.JobRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := filter(s.jobRuns, func(r models.JobRun) bool { return name == "" || r.JobName == name })
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartedAt.After(runs[j].StartedAt) })
+	return page(runs, limit, 0), nil
+}
+
+// schedulerService stands in for services.SchedulerService. Only the
+// recurring sessions job does anything; the rest report no actions.
+type schedulerService struct{ *Server }
+
+func (s schedulerService) RunJob(name string, dryRun bool) (*services.JobReport, error) {
+	if !slices.Contains(services.ManualJobs, name) {
+		return nil, services.ErrUnknownJob
+	}
+	report := &services.JobReport{Job: name, DryRun: dryRun, Actions: []services.JobAction{}}
+	if dryRun {
+		return report, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	started := time.Now()
+	if name == services.JobRecurringSessions {
+		before := len(s.sessions)
+		s.generateSeries()
+		for _, session := range s.sessions[before:] {
+			id := session.ID
+			report.Actions = append(report.Actions, services.JobAction{
+				Kind: "create_session", SessionID: &id, Title: session.Title,
+				Detail: utils.FormatDateForDisplay(session.SessionDate),
+			})
+		}
+	}
+	finished := time.Now()
+	s.jobRuns = append(s.jobRuns, models.JobRun{
+		ID: uuid.New(), JobName: name, StartedAt: started, FinishedAt: &finished, Status: models.JobRunStatusSucceeded,
+	})
+	return report, nil
+}
+
+// eventService stands in for services.EventService. The stubs record no
+// domain events, so the log is always empty.
+type eventService struct{ *Server }
+
+func (s eventService) ListEvents(services.EventFilter) ([]models.DomainEvent, error) {
+	return []models.DomainEvent{}, nil
+}
+
+// archiveService stands in for services.ArchiveService. Nothing is ever
+// archived; the stats show what a run would move.
+type archiveService struct{ *Server }
+
+func (s archiveService) Stats() ([]services.ArchiveTableStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	notifications := services.ArchiveTableStats{Table: "notifications", AfterMonths: archiveNotificationsAfterMonths}
+	cutoff := now.AddDate(0, -archiveNotificationsAfterMonths, 0)
+	for _, n := range s.notifications {
+		tally(&notifications, n.CreatedAt)
+		if n.CreatedAt.Before(cutoff) {
+			notifications.Due++
+		}
+	}
+	rsvps := services.ArchiveTableStats{Table: "rsvps", AfterMonths: archiveRSVPsAfterMonths}
+	cutoff = now.AddDate(0, -archiveRSVPsAfterMonths, 0)
+	for _, session := range s.sessions {
+		for _, r := range s.rsvps[session.ID] {
+			tally(&rsvps, r.CreatedAt)
+			if session.StartsAt.Before(cutoff) {
+				rsvps.Due++
+			}
+		}
+	}
+	return []services.ArchiveTableStats{notifications, rsvps}, nil
+}
+
+// tally counts a live row created at into stats
+func tally(stats *services.ArchiveTableStats, at time.Time) {
+	stats.Live++
+	if stats.OldestLive == nil || at.Before(*stats.OldestLive) {
+		oldest := at
+		stats.OldestLive = &oldest
+	}
+}
+
+// clubExportService stands in for services.ClubExportService, bundling the
+// fixtures as the real export bundles the database
+type clubExportService struct{ *Server }
+
+func (s clubExportService) Export() (*services.ClubBundle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bundle := &services.ClubBundle{
+		Version: clubBundleVersion, ExportedAt: time.Now(), Club: s.club,
+		CourtAssignments: s.courts, Comments: s.comments, CommentSettings: s.commentSettings,
+		Announcements: s.announcements, AnnouncementAcks: s.acks, CommitteeMinutes: s.minutes,
+		SeasonRollovers: s.rollovers, ContactLogs: s.contacts, MessageTemplates: s.templates,
+		PeerRatings: s.ratings, SkillAssessments: s.assessments, TrainingProgress: s.progress, TrainingAttendance: s.trainingAttended,
+		CarpoolOffers: s.carpoolOffers, CarpoolRequests: s.carpoolAsks, InventoryItems: s.inventory,
+		InventoryCheckouts: s.checkouts, InventoryMovements: s.movements, JoinRules: []models.JoinRules{s.joinRules},
+		ReferralCodes: s.referralCodes, PreapprovedMembers: s.preapproved, Invites: s.invites, InviteUses: s.inviteUses,
+	}
+	for _, u := range s.users {
+		bundle.Badges = append(bundle.Badges, u.Badges...)
+		u.Badges = nil
+		bundle.Users = append(bundle.Users, services.ExportedUser{
+			User: u, Auth0ID: u.Auth0ID, Email: u.Email, PhoneNumber: u.PhoneNumber,
+			EmergencyContactName: u.EmergencyContactName, EmergencyContactPhone: u.EmergencyContactPhone, MedicalNotes: u.MedicalNotes,
+		})
+	}
+	for _, prefs := range s.prefs {
+		bundle.NotificationTypePrefs = append(bundle.NotificationTypePrefs, prefs.Types...)
+		p := *prefs
+		p.Types, p.Settings = nil, nil
+		bundle.NotificationPreferences = append(bundle.NotificationPreferences, p)
+	}
+	for _, session := range s.sessions {
+		bundle.Sessions = append(bundle.Sessions, services.ExportedSession{Session: session, AdminNotes: session.AdminNotes})
+		bundle.RSVPs = append(bundle.RSVPs, s.rsvps[session.ID]...)
+		bundle.GuestRSVPs = append(bundle.GuestRSVPs, s.guests[session.ID]...)
+		bundle.SeriesRegulars = append(bundle.SeriesRegulars, s.regulars[session.ID]...)
+	}
+	for _, g := range s.games {
+		bundle.GamePlayers = append(bundle.GamePlayers, g.Players...)
+		g.Players = nil
+		bundle.Games = append(bundle.Games, g)
+	}
+	for _, t := range s.tournaments {
+		bundle.TournamentEntries = append(bundle.TournamentEntries, t.Entries...)
+		bundle.TournamentMatches = append(bundle.TournamentMatches, t.Matches...)
+		t.Entries, t.Matches = nil, nil
+		bundle.Tournaments = append(bundle.Tournaments, t)
+	}
+	for _, w := range s.orderWindows {
+		bundle.OrderItems = append(bundle.OrderItems, w.Items...)
+		w.Items = nil
+		bundle.OrderWindows = append(bundle.OrderWindows, w)
+	}
+	for _, o := range s.orders {
+		bundle.OrderLines = append(bundle.OrderLines, o.Lines...)
+		o.Lines = nil
+		bundle.Orders = append(bundle.Orders, o)
+	}
+	return bundle, nil
+}
+
+func (s clubExportService) RecordExport(*services.ClubBundle, uuid.UUID) error {
+	return nil
+}
+
+// searchService stands in for services.SearchService. Each word of the
+// query matches the start of a word, as the real prefix search does,
+// without Postgres's stemming or ranking.
+type searchService struct{ *Server }
+
+// words splits text into lower-case words of letters and digits
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// matches reports whether every term starts a word in one of texts
+func matches(terms []string, texts ...string) bool {
+	textWords := words(strings.Join(texts, " "))
+	for _, term := range terms {
+		if !slices.ContainsFunc(textWords, func(word string) bool { return strings.HasPrefix(word, term) }) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s searchService) Search(viewer *models.User, query string, types []services.SearchType, limit int) (*services.SearchResults, error) {
+	terms := words(query)
+	if len(terms) == 0 {
+		return nil, services.ErrEmptySearch
+	}
+	if limit < 1 || limit > services.MaxSearchLimit {
+		limit = services.MaxSearchLimit
+	}
+	allowed := services.SearchableTypes(viewer)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	results := &services.SearchResults{}
+	for _, t := range types {
+		if !slices.Contains(allowed, t) {
+			continue
+		}
+		switch t {
+		case services.SearchMembers:
+			members := s.usersWhere(func(u models.User) bool {
+				return (viewer.IsAdmin() || u.MembershipStatus == models.MembershipApproved) && matches(terms, u.Name)
+			})
+			sort.SliceStable(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+			results.Members = page(members, limit, 0)
+		case services.SearchSessions:
+			sessions := filter(s.sessions, func(session models.Session) bool { return matches(terms, session.Title, session.Description) })
+			sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartsAt.After(sessions[j].StartsAt) })
+			results.Sessions = page(sessions, limit, 0)
+		case services.SearchAnnouncements:
+			announcements := filter(s.visibleAnnouncements(viewer), func(a models.Announcement) bool { return matches(terms, a.Title, a.Body) })
+			results.Announcements = page(announcements, limit, 0)
+		}
+	}
+	return results, nil
+}
+
+// syncService stands in for services.SyncService. Deletions aren't
+// tracked, so no tombstones are sent.
+type syncService struct{ *Server }
+
+func (s syncService) GetChangesSince(since time.Time, viewer *models.User) (*services.SyncDelta, error) {
+	delta := &services.SyncDelta{
+		Since: since, ServerTime: time.Now(), Sessions: []models.Session{}, RSVPs: []models.RSVP{},
+		Announcements: []models.Announcement{}, Deleted: []models.Tombstone{},
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, session := range s.sessions {
+		if session.UpdatedAt.After(since) {
+			delta.Sessions = append(delta.Sessions, session)
+		}
+		for _, r := range s.rsvps[session.ID] {
+			if r.UpdatedAt.After(since) {
+				delta.RSVPs = append(delta.RSVPs, s.rsvpView(r))
+			}
+		}
+	}
+	sort.SliceStable(delta.Sessions, func(i, j int) bool { return delta.Sessions[i].UpdatedAt.Before(delta.Sessions[j].UpdatedAt) })
+	sort.SliceStable(delta.RSVPs, func(i, j int) bool { return delta.RSVPs[i].UpdatedAt.Before(delta.RSVPs[j].UpdatedAt) })
+	for _, a := range s.visibleAnnouncements(viewer) {
+		if a.CreatedAt.After(since) {
+			delta.Announcements = append(delta.Announcements, a)
+		}
+	}
+	reverse(delta.Announcements)
+	return delta, nil
+}
+
+// widgetService stands in for services.WidgetService, without its cache
+type widgetService struct{ *Server }
+
+func (s widgetService) GetWidget() (*services.Widget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	widget := &services.Widget{GeneratedAt: time.Now(), ClubName: s.club.Name}
+	widget.MemberCount = int64(len(s.usersWhere(func(u models.User) bool { return u.MembershipStatus == models.MembershipApproved })))
+	from := today()
+	upcoming := s.sessionViews(func(session *models.Session) bool {
+		return !session.SessionDate.Before(from) && session.Status == models.SessionStatusOpen
+	})
+	if len(upcoming) > 0 {
+		next := &upcoming[0]
+		widget.NextSession = &services.WidgetSession{
+			Title:       next.Title,
+			SessionDate: next.SessionDate.Format("2006-01-02"),
+			StartTime:   utils.FormatClock(next.StartsAt),
+			EndTime:     utils.FormatClock(next.EndsAt),
+			MaxPlayers:  next.MaxPlayers,
+			SpotsLeft:   s.spotsLeft(next),
+		}
+	}
+	return widget, nil
+}
+
+// shareService stands in for services.ShareService, signing and drawing
+// with the real one
+type shareService struct{ *Server }
+
+func (s shareService) CreateToken(session *models.Session) (string, time.Time, error) {
+	return s.share.CreateToken(session)
+}
+
+func (s shareService) GetPreview(token string) (*services.SessionPreview, error) {
+	sessionID, err := s.share.ParseToken(token)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(sessionID)
+	if session == nil {
+		return nil, services.ErrInvalidShareToken
+	}
+	return &services.SessionPreview{
+		ClubName:     s.club.Name,
+		Title:        session.Title,
+		Description:  session.Description,
+		SessionDate:  session.SessionDate,
+		StartTime:    utils.FormatClock(session.StartsAt),
+		EndTime:      utils.FormatClock(session.EndsAt),
+		Status:       session.Status,
+		VenueName:    s.club.VenueName,
+		VenueAddress: s.club.VenueAddress,
+		MaxPlayers:   session.MaxPlayers,
+		SpotsLeft:    s.spotsLeft(session),
+	}, nil
+}
+
+func (s shareService) PreviewImage(preview *services.SessionPreview) ([]byte, error) {
+	return s.share.PreviewImage(preview)
+}
+
+// inboundEmailService stands in for services.InboundEmailService. Email
+// replies are off, as they are without a webhook key.
+type inboundEmailService struct{ *Server }
+
+func (s inboundEmailService) CheckWebhookKey(string) bool { return false }
+
+func (s inboundEmailService) HandleReply(context.Context, services.InboundEmail) (services.InboundEmailResult, error) {
+	return services.InboundIgnored, nil
+}
+
+func (s inboundEmailService) IsEnabled() bool { return false }
+
+// attendeeLookup answers dto's questions about who may see a session's
+// attendees from the fixtures
+type attendeeLookup struct{ *Server }
+
+func (s attendeeLookup) AttendeeVisibility() (models.AttendeeVisibility, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.club.AttendeeVisibility, nil
+}
+
+func (s attendeeLookup) JoinedSessions(userID uuid.UUID, sessionIDs []uuid.UUID) ([]uuid.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	joined := []uuid.UUID{}
+	for _, id := range sessionIDs {
+		if r := s.rsvpFor(id, userID); r != nil {
+			switch r.Status {
+			case models.RSVPStatusIn, models.RSVPStatusWaitlisted, models.RSVPStatusMaybe, models.RSVPStatusRequested:
+				joined = append(joined, id)
+			}
+		}
+	}
+	return joined, nil
+}
+
+func (s attendeeLookup) SessionStaff(sessionIDs []uuid.UUID) ([]models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	staff := []models.Session{}
+	for _, id := range sessionIDs {
+		if session := s.sessionByID(id); session != nil {
+			staff = append(staff, models.Session{ID: session.ID, CreatedBy: session.CreatedBy, CoachID: session.CoachID})
+		}
+	}
+	return staff, nil
+}
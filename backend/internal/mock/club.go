@@ -0,0 +1,965 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/storage"
+)
+
+// clubService stands in for services.ClubService
+type clubService struct{ *Server }
+
+func (s clubService) GetClub() (*models.Club, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	club := s.club
+	return &club, nil
+}
+
+func (s clubService) SaveClub(club *models.Club) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	club.UpdatedAt = time.Now()
+	s.club = *club
+	return nil
+}
+
+// avatarService stands in for services.AvatarService, cropping photos with
+// the real image code
+type avatarService struct{ *Server }
+
+// avatarKey is where one size of a photo is kept, as the avatar service
+// stores it
+func avatarKey(userID, avatarID uuid.UUID, size models.AvatarSize) string {
+	return "avatars/" + userID.String() + "/" + avatarID.String() + "-" + string(size) + ".jpg"
+}
+
+// removeAvatar clears the member's photo and its stored sizes, closing the
+// reports on it with action, if an admin took one. The caller holds s.mu.
+func (s *Server) removeAvatar(userID uuid.UUID, action *models.ModerationAction, adminID *uuid.UUID) error {
+	i := s.userIndex(userID)
+	if i < 0 || s.users[i].AvatarID == nil {
+		return services.ErrAvatarNotFound
+	}
+	avatarID := *s.users[i].AvatarID
+	for _, size := range models.AvatarSizes {
+		delete(s.files, avatarKey(userID, avatarID, size.Size))
+	}
+	s.users[i].AvatarID, s.users[i].UpdatedAt = nil, time.Now()
+	s.closeAvatarReports(avatarID, action, adminID)
+	return nil
+}
+
+// closeAvatarReports resolves the open reports on a photo. The caller holds
+// s.mu.
+func (s *Server) closeAvatarReports(avatarID uuid.UUID, action *models.ModerationAction, adminID *uuid.UUID) {
+	now := time.Now()
+	for i := range s.avatarReports {
+		if r := &s.avatarReports[i]; r.AvatarID == avatarID && r.Status == models.ReportStatusOpen {
+			r.Status, r.Action, r.ResolvedBy, r.ResolvedAt = models.ReportStatusResolved, action, adminID, &now
+		}
+	}
+}
+
+// avatarReportView fills in a photo report's members. The caller holds s.mu.
+func (s *Server) avatarReportView(r models.AvatarReport) models.AvatarReport {
+	r.User, r.Reporter = s.user(r.UserID), s.user(r.ReportedBy)
+	return r
+}
+
+func (s avatarService) ListAvatarReports() ([]models.AvatarReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reports := []models.AvatarReport{}
+	for _, r := range s.avatarReports {
+		if r.Status == models.ReportStatusOpen {
+			reports = append(reports, s.avatarReportView(r))
+		}
+	}
+	return reports, nil
+}
+
+func (s avatarService) OpenAvatar(_ context.Context, avatarID uuid.UUID, size models.AvatarSize) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.users, func(u models.User) bool { return u.AvatarID != nil && *u.AvatarID == avatarID })
+	if i < 0 {
+		return nil, services.ErrAvatarNotFound
+	}
+	key := avatarKey(s.users[i].ID, avatarID, size)
+	if _, ok := s.files[key]; !ok {
+		return nil, services.ErrAvatarNotFound
+	}
+	return s.openFile(key), nil
+}
+
+func (s avatarService) RemoveAvatar(userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.removeAvatar(userID, nil, nil)
+}
+
+func (s avatarService) ReportAvatar(userID, reporterID uuid.UUID, reason string) (*models.AvatarReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := s.user(userID)
+	switch {
+	case userID == reporterID:
+		return nil, services.ErrOwnAvatar
+	case user == nil:
+		return nil, services.ErrUserNotFound
+	case user.AvatarID == nil:
+		return nil, services.ErrAvatarNotFound
+	case indexOf(s.avatarReports, func(r models.AvatarReport) bool {
+		return r.AvatarID == *user.AvatarID && r.ReportedBy == reporterID
+	}) >= 0:
+		return nil, services.ErrAvatarReported
+	}
+	report := models.AvatarReport{
+		ID: uuid.New(), UserID: userID, AvatarID: *user.AvatarID, ReportedBy: reporterID, Reason: reason,
+		Status: models.ReportStatusOpen, CreatedAt: time.Now(),
+	}
+	s.avatarReports = append(s.avatarReports, report)
+	return &report, nil
+}
+
+func (s avatarService) ResolveAvatarReport(_ context.Context, reportID, adminID uuid.UUID, action models.ModerationAction, _ string) (*models.AvatarReport, error) {
+	if action != models.ModerationDelete && action != models.ModerationDismiss {
+		return nil, services.ErrAvatarAction
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.avatarReports, func(r models.AvatarReport) bool { return r.ID == reportID })
+	if i < 0 {
+		return nil, services.ErrAvatarReportNotFound
+	}
+	report := s.avatarReports[i]
+	if report.Status != models.ReportStatusOpen {
+		return nil, errors.New("report is already resolved")
+	}
+	user := s.user(report.UserID)
+	if action == models.ModerationDelete && user != nil && user.AvatarID != nil && *user.AvatarID == report.AvatarID {
+		s.removeAvatar(user.ID, &action, &adminID)
+	} else {
+		s.closeAvatarReports(report.AvatarID, &action, &adminID)
+	}
+	report = s.avatarReportView(s.avatarReports[i])
+	return &report, nil
+}
+
+func (s avatarService) SetAvatar(_ context.Context, userID uuid.UUID, size int64, r io.Reader) (*models.User, error) {
+	_, data, err := readUpload(storage.Avatars, size, r)
+	if err != nil {
+		return nil, err
+	}
+	pixels := make([]int, len(models.AvatarSizes))
+	for i, size := range models.AvatarSizes {
+		pixels[i] = size.Pixels
+	}
+	images, err := storage.SquareJPEGs(data, pixels)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if user := s.user(userID); user != nil && user.AvatarID != nil {
+		s.removeAvatar(userID, nil, nil)
+	}
+	avatarID := uuid.New()
+	for i, size := range models.AvatarSizes {
+		s.files[avatarKey(userID, avatarID, size.Size)] = images[i]
+	}
+	return s.updateUser(userID, func(u *models.User) { u.AvatarID = &avatarID })
+}
+
+// notificationService stands in for services.NotificationService. Nothing
+// is delivered; the history is the fixtures'.
+type notificationService struct{ *Server }
+
+// preferences returns the member's notification preferences with their
+// effective type settings, creating the defaults on first use. The caller
+// holds s.mu.
+func (s *Server) preferences(userID uuid.UUID) *models.UserNotificationPreferences {
+	prefs, ok := s.prefs[userID]
+	if !ok {
+		now := time.Now()
+		prefs = &models.UserNotificationPreferences{
+			ID: uuid.New(), UserID: userID, PushEnabled: true, EmailEnabled: true,
+			CommentNotifications: models.CommentNotifyAll, CreatedAt: now, UpdatedAt: now,
+		}
+		s.prefs[userID] = prefs
+	}
+	prefs.Settings = prefs.TypeSettings()
+	return prefs
+}
+
+func (s notificationService) CountUnread(userID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var unread int64
+	for _, n := range s.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			unread++
+		}
+	}
+	return unread, nil
+}
+
+// GetUserNotifications filters the member's history newest first. The
+// search matches words of the title or body rather than ranking them as
+// Postgres does.
+func (s notificationService) GetUserNotifications(userID uuid.UUID, filter services.NotificationFilter, limit, offset int) ([]models.Notification, error) {
+	terms := strings.Fields(strings.ToLower(filter.Search))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	history := []models.Notification{}
+	for i := len(s.notifications) - 1; i >= 0; i-- {
+		n := s.notifications[i]
+		sessionID, hasSession := n.Data.SessionID()
+		if n.UserID == userID &&
+			(filter.SessionID == nil || hasSession && sessionID == *filter.SessionID) &&
+			(filter.Type == nil || n.NotificationType == *filter.Type) &&
+			(filter.Read == nil || (n.ReadAt != nil) == *filter.Read) &&
+			(filter.From == nil || !n.CreatedAt.Before(*filter.From)) &&
+			(filter.To == nil || n.CreatedAt.Before(filter.To.AddDate(0, 0, 1))) &&
+			containsAll(n.Title+" "+n.Body, terms) {
+			history = append(history, n)
+		}
+	}
+	return page(history, limit, offset), nil
+}
+
+// containsAll reports whether text holds every term, ignoring case
+func containsAll(text string, terms []string) bool {
+	text = strings.ToLower(text)
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s notificationService) GetUserPreferences(userID uuid.UUID) (*models.UserNotificationPreferences, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefs := *s.preferences(userID)
+	return &prefs, nil
+}
+
+func (s notificationService) ListNotificationLog(filter services.NotificationLogFilter, limit, offset int) ([]models.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log := []models.Notification{}
+	for i := len(s.notifications) - 1; i >= 0; i-- {
+		n := s.notifications[i]
+		if (filter.UserID == nil || n.UserID == *filter.UserID) &&
+			(filter.Type == nil || n.NotificationType == *filter.Type) &&
+			onChannel(n, filter.Channel, filter.FailedOnly) {
+			n.User = s.user(n.UserID)
+			log = append(log, n)
+		}
+	}
+	return page(log, limit, offset), nil
+}
+
+// onChannel reports whether n was sent or attempted on channel, or any
+// channel if it's empty; with failedOnly, whether it failed there
+func onChannel(n models.Notification, channel string, failedOnly bool) bool {
+	type delivery struct {
+		sent   bool
+		failed string
+	}
+	deliveries := map[string]delivery{
+		"push":  {n.PushSent, n.PushError},
+		"email": {n.EmailSent, n.EmailError},
+		"sms":   {n.SMSSent, n.SMSError},
+	}
+	if channel == "" {
+		if !failedOnly {
+			return true
+		}
+		for _, d := range deliveries {
+			if !d.sent && d.failed != "" {
+				return true
+			}
+		}
+		return false
+	}
+	d := deliveries[channel]
+	if failedOnly {
+		return !d.sent && d.failed != ""
+	}
+	return d.sent || d.failed != ""
+}
+
+func (s notificationService) MarkNotificationRead(notificationID, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for i := range s.notifications {
+		if n := &s.notifications[i]; n.ID == notificationID && n.UserID == userID {
+			n.ReadAt = &now
+		}
+	}
+	return nil
+}
+
+func (s notificationService) RegisterPushToken(uuid.UUID, string, string) error {
+	return nil
+}
+
+// ResendNotification marks the push that failed as sent
+func (s notificationService) ResendNotification(_ context.Context, id uuid.UUID) (*models.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.notifications, func(n models.Notification) bool { return n.ID == id })
+	if i < 0 {
+		return nil, domainError(services.ErrNotFound, "notification_not_found", "notification not found")
+	}
+	n := &s.notifications[i]
+	if n.PushSent && n.EmailSent {
+		return nil, errors.New("nothing to resend: every enabled channel has already been delivered")
+	}
+	now := time.Now()
+	n.PushSent, n.PushSentAt, n.PushError = true, &now, ""
+	resent := *n
+	resent.User = s.user(n.UserID)
+	return &resent, nil
+}
+
+func (s notificationService) UnregisterPushToken(uuid.UUID, string) error {
+	return nil
+}
+
+// UpdateUserPreferences applies the general settings the handler passes by
+// column name and the per-type choices, refusing types that follow another
+// or can't be muted on a channel, as the real service does
+func (s notificationService) UpdateUserPreferences(userID uuid.UUID, updates map[string]interface{}, types services.TypePreferenceUpdates) (*models.UserNotificationPreferences, error) {
+	for t, channels := range types {
+		defaults, ok := models.NotificationDefaults[t]
+		if !ok {
+			return nil, &services.DomainError{Kind: services.ErrInvalid, Code: "unknown_notification_type",
+				Message: fmt.Sprintf("unknown notification type %q", t)}
+		}
+		for channel := range channels {
+			if defaults.Inherits != "" || !defaults.IsConfigurable(channel) {
+				return nil, &services.DomainError{Kind: services.ErrInvalid, Code: "preference_not_configurable",
+					Message: fmt.Sprintf("%s notifications can't be changed for %s", channel, t)}
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefs := s.preferences(userID)
+	for column, value := range updates {
+		switch column {
+		case "push_enabled":
+			prefs.PushEnabled = value.(bool)
+		case "email_enabled":
+			prefs.EmailEnabled = value.(bool)
+		case "reminder_show_attendees":
+			prefs.ReminderShowAttendees = value.(bool)
+		case "comment_notifications":
+			prefs.CommentNotifications = value.(models.CommentNotificationLevel)
+		}
+	}
+	now := time.Now()
+	for t, channels := range types {
+		for channel, enabled := range channels {
+			i := indexOf(prefs.Types, func(p models.NotificationTypePreference) bool { return p.Type == t && p.Channel == channel })
+			if i < 0 {
+				prefs.Types = append(prefs.Types, models.NotificationTypePreference{
+					ID: uuid.New(), UserID: userID, Type: t, Channel: channel, CreatedAt: now,
+				})
+				i = len(prefs.Types) - 1
+			}
+			prefs.Types[i].Enabled, prefs.Types[i].UpdatedAt = enabled, now
+		}
+	}
+	prefs.UpdatedAt = now
+	updated := *s.preferences(userID)
+	return &updated, nil
+}
+
+// announcementService stands in for services.AnnouncementService. Nothing
+// is pushed to the audience.
+type announcementService struct{ *Server }
+
+// visibleAnnouncements returns the announcements viewer may see, newest
+// first; committee announcements are left out for anyone not on the
+// committee. The caller holds s.mu.
+func (s *Server) visibleAnnouncements(viewer *models.User) []models.Announcement {
+	visible := []models.Announcement{}
+	for i := len(s.announcements) - 1; i >= 0; i-- {
+		if a := s.announcements[i]; a.Audience == models.AudienceMembers || viewer.IsCommittee() {
+			a.Creator = s.user(a.CreatedBy)
+			visible = append(visible, a)
+		}
+	}
+	return visible
+}
+
+// audience returns the approved members an announcement reaches. The
+// caller holds s.mu.
+func (s *Server) audience(audience models.AnnouncementAudience) []models.User {
+	return s.usersWhere(func(u models.User) bool {
+		return u.MembershipStatus == models.MembershipApproved && (audience == models.AudienceMembers || u.IsCommittee())
+	})
+}
+
+// ackFor returns the member's acknowledgement of an announcement, or nil.
+// The caller holds s.mu.
+func (s *Server) ackFor(announcementID, userID uuid.UUID) *models.AnnouncementAcknowledgement {
+	if i := indexOf(s.acks, func(a models.AnnouncementAcknowledgement) bool {
+		return a.AnnouncementID == announcementID && a.UserID == userID
+	}); i >= 0 {
+		return &s.acks[i]
+	}
+	return nil
+}
+
+func (s announcementService) AckSummaries(limit int) ([]services.AnnouncementAckSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summaries := []services.AnnouncementAckSummary{}
+	for i := len(s.announcements) - 1; i >= 0 && len(summaries) < limit; i-- {
+		a := s.announcements[i]
+		if !a.RequiresAck {
+			continue
+		}
+		acked := 0
+		for _, ack := range s.acks {
+			if u := s.user(ack.UserID); ack.AnnouncementID == a.ID && u != nil && u.MembershipStatus == models.MembershipApproved {
+				acked++
+			}
+		}
+		summaries = append(summaries, services.AnnouncementAckSummary{
+			Announcement: a, Acknowledged: acked, Outstanding: max(len(s.audience(a.Audience))-acked, 0),
+		})
+	}
+	return summaries, nil
+}
+
+func (s announcementService) Acknowledge(announcementID uuid.UUID, viewer *models.User) (*models.AnnouncementAcknowledgement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	visible := s.visibleAnnouncements(viewer)
+	i := indexOf(visible, func(a models.Announcement) bool { return a.ID == announcementID })
+	if i < 0 {
+		return nil, services.ErrAnnouncementNotFound
+	}
+	if !visible[i].RequiresAck {
+		return nil, errors.New("this announcement doesn't ask for acknowledgement")
+	}
+	ack := s.ackFor(announcementID, viewer.ID)
+	if ack == nil {
+		s.acks = append(s.acks, models.AnnouncementAcknowledgement{
+			ID: uuid.New(), AnnouncementID: announcementID, UserID: viewer.ID, AcknowledgedAt: time.Now(),
+		})
+		ack = &s.acks[len(s.acks)-1]
+	}
+	acked := *ack
+	return &acked, nil
+}
+
+func (s announcementService) GetAcks(announcementID uuid.UUID) (*models.Announcement, *services.AnnouncementAcks, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.announcements, func(a models.Announcement) bool { return a.ID == announcementID })
+	if i < 0 {
+		return nil, nil, services.ErrAnnouncementNotFound
+	}
+	announcement := s.announcements[i]
+	acks := &services.AnnouncementAcks{Acknowledged: []models.AnnouncementAcknowledgement{}, Outstanding: []models.User{}}
+	for _, ack := range s.acks {
+		if ack.AnnouncementID == announcementID {
+			ack.User = s.user(ack.UserID)
+			acks.Acknowledged = append(acks.Acknowledged, ack)
+		}
+	}
+	sort.SliceStable(acks.Acknowledged, func(i, j int) bool {
+		return acks.Acknowledged[i].AcknowledgedAt.Before(acks.Acknowledged[j].AcknowledgedAt)
+	})
+	for _, u := range s.audience(announcement.Audience) {
+		if s.ackFor(announcementID, u.ID) == nil {
+			acks.Outstanding = append(acks.Outstanding, u)
+		}
+	}
+	return &announcement, acks, nil
+}
+
+func (s announcementService) ListAnnouncements(viewer *models.User, limit int) ([]services.AnnouncementWithAck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []services.AnnouncementWithAck{}
+	for _, a := range page(s.visibleAnnouncements(viewer), limit, 0) {
+		entry := services.AnnouncementWithAck{Announcement: a}
+		if ack := s.ackFor(a.ID, viewer.ID); ack != nil {
+			at := ack.AcknowledgedAt
+			entry.AcknowledgedAt = &at
+		}
+		list = append(list, entry)
+	}
+	return list, nil
+}
+
+func (s announcementService) Quota(limit int, window time.Duration) (*services.AnnouncementQuota, error) {
+	quota := &services.AnnouncementQuota{Limit: max(limit, 0)}
+	if limit <= 0 {
+		return quota, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := time.Now().Add(-window)
+	sent := filter(s.announcements, func(a models.Announcement) bool {
+		return a.Audience == models.AudienceMembers && a.CreatedAt.After(cutoff)
+	})
+	quota.Used = len(sent)
+	if quota.Used < limit {
+		quota.Remaining = limit - quota.Used
+	} else {
+		resetsAt := sent[quota.Used-limit].CreatedAt.Add(window)
+		quota.ResetsAt = &resetsAt
+	}
+	return quota, nil
+}
+
+// addAnnouncement saves an announcement. The caller holds s.mu.
+func (s *Server) addAnnouncement(title, body string, requiresAck bool, audience models.AnnouncementAudience, createdBy uuid.UUID) *models.Announcement {
+	now := time.Now()
+	announcement := models.Announcement{
+		ID: uuid.New(), Title: title, Body: body, CreatedBy: createdBy, SentAt: now, CreatedAt: now,
+		Audience: audience, RequiresAck: requiresAck,
+	}
+	s.announcements = append(s.announcements, announcement)
+	announcement.Creator = s.user(createdBy)
+	return &announcement
+}
+
+func (s announcementService) SendAnnouncement(_ context.Context, title, body string, requiresAck bool, createdBy uuid.UUID, _ *services.AnnouncementQuota) (*models.Announcement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addAnnouncement(title, body, requiresAck, models.AudienceMembers, createdBy), nil
+}
+
+func (s announcementService) SendCommitteeAnnouncement(_ context.Context, title, body string, requiresAck bool, createdBy uuid.UUID) (*models.Announcement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addAnnouncement(title, body, requiresAck, models.AudienceCommittee, createdBy), nil
+}
+
+// documentService stands in for services.DocumentService
+type documentService struct{ *Server }
+
+// visibleDocument returns the document with id if viewer may see it, or
+// nil. The caller holds s.mu.
+func (s *Server) visibleDocument(id uuid.UUID, viewer *models.User) *models.Document {
+	if i := indexOf(s.documents, func(d models.Document) bool {
+		return d.ID == id && (!d.CommitteeOnly || viewer.IsCommittee())
+	}); i >= 0 {
+		return &s.documents[i]
+	}
+	return nil
+}
+
+func (s documentService) AcknowledgeDocument(documentID uuid.UUID, viewer *models.User) (*models.DocumentAcknowledgement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.visibleDocument(documentID, viewer) == nil {
+		return nil, services.ErrDocumentNotFound
+	}
+	i := indexOf(s.documentAcks, func(a models.DocumentAcknowledgement) bool {
+		return a.DocumentID == documentID && a.UserID == viewer.ID
+	})
+	if i < 0 {
+		s.documentAcks = append(s.documentAcks, models.DocumentAcknowledgement{
+			ID: uuid.New(), DocumentID: documentID, UserID: viewer.ID, AcknowledgedAt: time.Now(),
+		})
+		i = len(s.documentAcks) - 1
+	}
+	ack := s.documentAcks[i]
+	return &ack, nil
+}
+
+// deleteDocument removes a document with its acknowledgements and file;
+// committeeOnly limits it to the committee's papers
+func (s documentService) deleteDocument(id uuid.UUID, committeeOnly bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.documents, func(d models.Document) bool { return d.ID == id && (d.CommitteeOnly || !committeeOnly) })
+	if i < 0 {
+		return services.ErrDocumentNotFound
+	}
+	delete(s.files, s.documents[i].StorageKey)
+	s.documents = append(s.documents[:i], s.documents[i+1:]...)
+	s.documentAcks = filter(s.documentAcks, func(a models.DocumentAcknowledgement) bool { return a.DocumentID != id })
+	return nil
+}
+
+func (s documentService) DeleteCommitteeDocument(_ context.Context, id uuid.UUID) error {
+	return s.deleteDocument(id, true)
+}
+
+func (s documentService) DeleteDocument(_ context.Context, id uuid.UUID) error {
+	return s.deleteDocument(id, false)
+}
+
+func (s documentService) ListCommitteeDocuments() ([]models.Document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	docs := filter(s.documents, func(d models.Document) bool { return d.CommitteeOnly })
+	reverse(docs)
+	return docs, nil
+}
+
+func (s documentService) ListDocuments(viewer *models.User) ([]services.DocumentWithAck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	docs := []services.DocumentWithAck{}
+	for _, d := range s.documents {
+		if d.CommitteeOnly && !viewer.IsCommittee() {
+			continue
+		}
+		doc := services.DocumentWithAck{Document: d}
+		if i := indexOf(s.documentAcks, func(a models.DocumentAcknowledgement) bool { return a.DocumentID == d.ID && a.UserID == viewer.ID }); i >= 0 {
+			at := s.documentAcks[i].AcknowledgedAt
+			doc.AcknowledgedAt = &at
+		}
+		docs = append(docs, doc)
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		a, b := docs[i], docs[j]
+		if a.Required != b.Required {
+			return a.Required
+		}
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		return a.Title < b.Title
+	})
+	return docs, nil
+}
+
+func (s documentService) OpenDocument(_ context.Context, id uuid.UUID, viewer *models.User) (*models.Document, io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc := s.visibleDocument(id, viewer)
+	if doc == nil {
+		return nil, nil, services.ErrDocumentNotFound
+	}
+	found := *doc
+	return &found, s.openFile(doc.StorageKey), nil
+}
+
+func (s documentService) UploadDocument(_ context.Context, input services.DocumentInput, r io.Reader, uploadedBy uuid.UUID) (*models.Document, error) {
+	upload, data, err := readUpload(storage.Documents, input.SizeBytes, r)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	doc := models.Document{
+		ID: uuid.New(), Title: input.Title, Description: input.Description, Category: input.Category,
+		FileName: input.FileName, ContentType: upload.ContentType, SizeBytes: input.SizeBytes,
+		Required: input.Required && !input.CommitteeOnly, UploadedBy: uploadedBy, CommitteeOnly: input.CommitteeOnly,
+		CreatedAt: now, UpdatedAt: now,
+	}
+	doc.StorageKey = "documents/" + doc.ID.String() + upload.Extension
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[doc.StorageKey] = data
+	s.documents = append(s.documents, doc)
+	return &doc, nil
+}
+
+// commentService stands in for services.CommentService, checking comments
+// with the real content filter. Nobody is told of new comments.
+type commentService struct{ *Server }
+
+// errCommentNotFound is what the comment services return for an unknown
+// comment
+var errCommentNotFound = domainError(services.ErrNotFound, "comment_not_found", "comment not found")
+
+// commentIndex is the index of the comment with id in s.comments, or -1
+func (s *Server) commentIndex(id uuid.UUID) int {
+	return indexOf(s.comments, func(c models.Comment) bool { return c.ID == id })
+}
+
+// commentView fills in a comment's author. The caller holds s.mu.
+func (s *Server) commentView(c models.Comment) models.Comment {
+	c.User = s.user(c.UserID)
+	return c
+}
+
+// commentLevel is the member's comment notification level for a session,
+// falling back to their preference. The caller holds s.mu.
+func (s *Server) commentLevel(sessionID, userID uuid.UUID) *services.CommentNotificationSetting {
+	if i := s.commentSettingIndex(sessionID, userID); i >= 0 {
+		return &services.CommentNotificationSetting{SessionID: sessionID, Level: s.commentSettings[i].Level}
+	}
+	return &services.CommentNotificationSetting{
+		SessionID: sessionID, Level: s.preferences(userID).CommentNotifications, IsDefault: true,
+	}
+}
+
+func (s *Server) commentSettingIndex(sessionID, userID uuid.UUID) int {
+	return indexOf(s.commentSettings, func(c models.SessionCommentSetting) bool {
+		return c.SessionID == sessionID && c.UserID == userID
+	})
+}
+
+func (s commentService) CreateComment(sessionID, userID uuid.UUID, body string) (*models.Comment, error) {
+	if err := s.moderation.CheckText(body); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(sessionID) == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	now := time.Now()
+	comment := models.Comment{ID: uuid.New(), SessionID: sessionID, UserID: userID, Body: body, CreatedAt: now, UpdatedAt: now}
+	s.comments = append(s.comments, comment)
+	comment = s.commentView(comment)
+	return &comment, nil
+}
+
+func (s commentService) DeleteComment(commentID, userID uuid.UUID, byAdmin bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.commentIndex(commentID)
+	if i < 0 {
+		return errCommentNotFound
+	}
+	if !byAdmin && s.comments[i].UserID != userID {
+		return errors.New("you can only delete your own comments")
+	}
+	s.removeComment(commentID)
+	return nil
+}
+
+// removeComment deletes a comment with its reports. The caller holds s.mu.
+func (s *Server) removeComment(id uuid.UUID) {
+	s.comments = filter(s.comments, func(c models.Comment) bool { return c.ID != id })
+	s.commentReports = filter(s.commentReports, func(r models.CommentReport) bool { return r.CommentID != id })
+}
+
+func (s commentService) GetNotificationSetting(sessionID, userID uuid.UUID) (*services.CommentNotificationSetting, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(sessionID) == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	return s.commentLevel(sessionID, userID), nil
+}
+
+func (s commentService) ListComments(sessionID uuid.UUID, includeHidden bool) ([]models.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	comments := []models.Comment{}
+	for _, c := range s.comments {
+		if c.SessionID == sessionID && (includeHidden || !c.Hidden) {
+			comments = append(comments, s.commentView(c))
+		}
+	}
+	return comments, nil
+}
+
+func (s commentService) ResetNotificationSetting(sessionID, userID uuid.UUID) (*services.CommentNotificationSetting, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(sessionID) == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	if i := s.commentSettingIndex(sessionID, userID); i >= 0 {
+		s.commentSettings = append(s.commentSettings[:i], s.commentSettings[i+1:]...)
+	}
+	return s.commentLevel(sessionID, userID), nil
+}
+
+func (s commentService) SetNotificationSetting(sessionID, userID uuid.UUID, level models.CommentNotificationLevel) (*services.CommentNotificationSetting, error) {
+	if !level.IsValid() {
+		return nil, errors.New("level must be all, mentions or none")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(sessionID) == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	now := time.Now()
+	if i := s.commentSettingIndex(sessionID, userID); i >= 0 {
+		s.commentSettings[i].Level, s.commentSettings[i].UpdatedAt = level, now
+	} else {
+		s.commentSettings = append(s.commentSettings, models.SessionCommentSetting{
+			ID: uuid.New(), SessionID: sessionID, UserID: userID, Level: level, CreatedAt: now, UpdatedAt: now,
+		})
+	}
+	return s.commentLevel(sessionID, userID), nil
+}
+
+// moderationService stands in for services.ModerationService, filtering
+// text with the real one
+type moderationService struct{ *Server }
+
+// commentReportView fills in a comment report's comment and reporter. The
+// caller holds s.mu.
+func (s *Server) commentReportView(r models.CommentReport) models.CommentReport {
+	if i := s.commentIndex(r.CommentID); i >= 0 {
+		comment := s.commentView(s.comments[i])
+		r.Comment = &comment
+	}
+	r.Reporter = s.user(r.ReportedBy)
+	return r
+}
+
+func (s moderationService) CheckText(texts ...string) error {
+	return s.moderation.CheckText(texts...)
+}
+
+func (s moderationService) ListOpenReports() ([]models.CommentReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reports := []models.CommentReport{}
+	for _, r := range s.commentReports {
+		if r.Status == models.ReportStatusOpen {
+			reports = append(reports, s.commentReportView(r))
+		}
+	}
+	return reports, nil
+}
+
+func (s moderationService) ReportComment(commentID, reporterID uuid.UUID, reason string) (*models.CommentReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.commentIndex(commentID)
+	switch {
+	case i < 0:
+		return nil, errCommentNotFound
+	case s.comments[i].UserID == reporterID:
+		return nil, errors.New("cannot report your own comment")
+	case indexOf(s.commentReports, func(r models.CommentReport) bool { return r.CommentID == commentID && r.ReportedBy == reporterID }) >= 0:
+		return nil, errors.New("you have already reported this comment")
+	}
+	report := models.CommentReport{
+		ID: uuid.New(), CommentID: commentID, ReportedBy: reporterID, Reason: reason, Status: models.ReportStatusOpen, CreatedAt: time.Now(),
+	}
+	s.commentReports = append(s.commentReports, report)
+	return &report, nil
+}
+
+// ResolveReport hides or deletes the reported comment, or warns its author
+// without telling them, and closes every open report on it
+func (s moderationService) ResolveReport(_ context.Context, reportID, adminID uuid.UUID, action models.ModerationAction, _ string) (*models.CommentReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := indexOf(s.commentReports, func(report models.CommentReport) bool { return report.ID == reportID })
+	if r < 0 {
+		return nil, domainError(services.ErrNotFound, "report_not_found", "report not found")
+	}
+	if s.commentReports[r].Status != models.ReportStatusOpen {
+		return nil, errors.New("report is already resolved")
+	}
+	commentID := s.commentReports[r].CommentID
+	i := s.commentIndex(commentID)
+	if i < 0 {
+		return nil, errors.New("reported comment no longer exists")
+	}
+
+	now := time.Now()
+	for j := range s.commentReports {
+		if other := &s.commentReports[j]; other.CommentID == commentID && other.Status == models.ReportStatusOpen {
+			other.Status, other.Action, other.ResolvedBy, other.ResolvedAt = models.ReportStatusResolved, &action, &adminID, &now
+		}
+	}
+	report := s.commentReportView(s.commentReports[r])
+	switch action {
+	case models.ModerationHide:
+		s.comments[i].Hidden, s.comments[i].UpdatedAt = true, now
+		report.Comment.Hidden = true
+	case models.ModerationDelete:
+		s.removeComment(commentID)
+	}
+	return &report, nil
+}
+
+// messageService stands in for services.MessageCatalogService. It lists
+// only the club's rewordings, since the built-in catalog lives behind the
+// database-backed service, and checks wording with the real renderer.
+type messageService struct{ *Server }
+
+func (s messageService) template(key models.MessageTemplateKey, lang models.Language) int {
+	return indexOf(s.templates, func(m models.MessageTemplate) bool { return m.Key == key && m.Language == lang })
+}
+
+func (s messageService) ListMessages() ([]services.MessageTemplateInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	infos := []services.MessageTemplateInfo{}
+	for _, m := range s.templates {
+		updatedAt := m.UpdatedAt
+		infos = append(infos, services.MessageTemplateInfo{
+			Key: m.Key, Language: m.Language, Title: m.Title, Body: m.Body, Customized: true, UpdatedAt: &updatedAt,
+		})
+	}
+	return infos, nil
+}
+
+// PreviewMessage fills a missing title or body from the club's wording;
+// the mock has no built-in wording to fall back on
+func (s messageService) PreviewMessage(key models.MessageTemplateKey, lang models.Language, title, body string) (*services.MessagePreview, error) {
+	s.mu.Lock()
+	if i := s.template(key, lang); i >= 0 {
+		if title == "" {
+			title = s.templates[i].Title
+		}
+		if body == "" {
+			body = s.templates[i].Body
+		}
+	}
+	s.mu.Unlock()
+	if title == "" || body == "" {
+		return nil, errors.New("the mock server can only preview a title and body together")
+	}
+	return s.messages.PreviewMessage(key, lang, title, body)
+}
+
+func (s messageService) ResetMessage(key models.MessageTemplateKey, lang models.Language, _ uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.templates = filter(s.templates, func(m models.MessageTemplate) bool { return m.Key != key || m.Language != lang })
+	return nil
+}
+
+func (s messageService) SetMessage(key models.MessageTemplateKey, lang models.Language, title, body string, actorID uuid.UUID) (*models.MessageTemplate, error) {
+	title, body = strings.TrimSpace(title), strings.TrimSpace(body)
+	if title == "" || body == "" {
+		return nil, errors.New("title and body are required")
+	}
+	if _, err := s.messages.PreviewMessage(key, lang, title, body); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	i := s.template(key, lang)
+	if i < 0 {
+		s.templates = append(s.templates, models.MessageTemplate{ID: uuid.New(), Key: key, Language: lang, CreatedAt: now})
+		i = len(s.templates) - 1
+	}
+	m := &s.templates[i]
+	m.Title, m.Body, m.UpdatedByID, m.UpdatedAt = title, body, actorID, now
+	msg := *m
+	return &msg, nil
+}
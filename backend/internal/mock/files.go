@@ -0,0 +1,27 @@
+package mock
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/weekday-masters/backend/internal/storage"
+)
+
+// readUpload checks a file against policy, as the real services do before
+// storing it, and reads it whole
+func readUpload(policy storage.UploadPolicy, size int64, r io.Reader) (*storage.Upload, []byte, error) {
+	upload, err := policy.Check(size, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := io.ReadAll(upload.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return upload, data, nil
+}
+
+// openFile opens a stored upload. The caller holds s.mu.
+func (s *Server) openFile(key string) io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(s.files[key]))
+}
@@ -0,0 +1,473 @@
+package mock
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+// fixtureNamespace makes fixture IDs the same on every run
+var fixtureNamespace = uuid.MustParse("6f1d2c8e-4b0a-4e63-9a57-2f7c1d9e8b40")
+
+// fixtureID is the ID of the fixture of kind with handle
+func fixtureID(kind, handle string) uuid.UUID {
+	return uuid.NewSHA1(fixtureNamespace, []byte(kind+"/"+handle))
+}
+
+type fixtureUser struct {
+	handle string
+	name   string
+	role   models.UserRole
+	status models.MembershipStatus
+	// optional
+	committee models.CommitteeRole
+	privacy   models.PrivacySettings
+	inactive  bool // told they're inactive and due to be archived
+}
+
+// fixtureUsers are the club's members; X-Mock-User takes a handle or ID
+var fixtureUsers = []fixtureUser{
+	{handle: "admin", name: "Alex Admin", role: models.RoleAdmin, status: models.MembershipApproved, committee: models.CommitteePresident},
+	{handle: "member", name: "Sam Smash", role: models.RolePlayer, status: models.MembershipApproved},
+	{handle: "coach", name: "Casey Coach", role: models.RoleCoach, status: models.MembershipApproved},
+	{handle: "pending", name: "Pat Pending", role: models.RolePending, status: models.MembershipPending},
+	{handle: "jordan", name: "Jordan Drop", role: models.RolePlayer, status: models.MembershipApproved, committee: models.CommitteeTreasurer},
+	{handle: "priya", name: "Priya Net", role: models.RolePlayer, status: models.MembershipApproved},
+	{handle: "wei", name: "Chen Wei", role: models.RolePlayer, status: models.MembershipApproved},
+	{handle: "maria", name: "Maria Lob", role: models.RolePlayer, status: models.MembershipApproved,
+		privacy: models.PrivacySettings{HideFromWaitlist: true}},
+	{handle: "tom", name: "Tom Rally", role: models.RolePlayer, status: models.MembershipApproved},
+	{handle: "aisha", name: "Aisha Serve", role: models.RolePlayer, status: models.MembershipApproved},
+	{handle: "lee", name: "Lee Clear", role: models.RoleAdmin, status: models.MembershipApproved, committee: models.CommitteeSecretary},
+	{handle: "rob", name: "Rob Idle", role: models.RolePlayer, status: models.MembershipApproved, inactive: true},
+}
+
+// fixtureSession is a session some days from today
+type fixtureSession struct {
+	handle    string
+	title     string
+	day       int // days from today; negative is in the past
+	startHour int
+	hours     int
+	courts    int
+	// optional
+	outdoor   bool
+	training  bool
+	spots     int  // training sessions only
+	recurring bool // a weekly series; its next weeks are generated
+	guests    bool
+	approval  bool
+	cancelled string
+	going     []string // handles RSVP'd IN, in order; past capacity they're waitlisted
+	maybe     []string
+	out       []string
+}
+
+// fixtureSessions cover the fortnight either side of today: past sessions,
+// one whose RSVPs have closed, open ones, a full one with a waitlist, a
+// training session, an outdoor session, a weekly series and a cancelled one
+var fixtureSessions = []fixtureSession{
+	{handle: "past-social", title: "Evening Social", day: -7, startHour: 19, hours: 2, courts: 2,
+		going: []string{"admin", "member", "jordan", "priya", "wei", "tom"}, out: []string{"maria"}},
+	{handle: "past-smash", title: "Smash Night", day: -3, startHour: 19, hours: 2, courts: 1,
+		going: []string{"member", "jordan", "aisha", "wei"}},
+	{handle: "closed-social", title: "Evening Social", day: 1, startHour: 19, hours: 2, courts: 2,
+		going: []string{"admin", "jordan", "priya", "tom"}, maybe: []string{"member"}},
+	{handle: "full-smash", title: "Smash Night", day: 4, startHour: 19, hours: 2, courts: 1,
+		going: []string{"jordan", "priya", "wei", "tom", "aisha", "admin", "maria", "member"}},
+	{handle: "training", title: "Training: Net Play", day: 5, startHour: 18, hours: 1, courts: 1, training: true, spots: 4,
+		going: []string{"member", "wei"}},
+	{handle: "outdoor", title: "Outdoor Hit", day: 6, startHour: 9, hours: 2, courts: 2, outdoor: true,
+		going: []string{"aisha", "maria"}, out: []string{"tom"}},
+	{handle: "next-social", title: "Evening Social", day: 8, startHour: 19, hours: 2, courts: 2, guests: true,
+		going: []string{"admin"}},
+	{handle: "next-smash", title: "Smash Night", day: 11, startHour: 19, hours: 2, courts: 1, approval: true},
+	{handle: "series", title: "Weeknight Doubles", day: 2, startHour: 18, hours: 2, courts: 2, recurring: true,
+		going: []string{"lee", "priya"}},
+	{handle: "cancelled-outdoor", title: "Outdoor Hit", day: 13, startHour: 9, hours: 2, courts: 2, outdoor: true,
+		cancelled: "The venue is closed for floor resurfacing"},
+}
+
+// rsvpDeadlineBefore is how long before a session its RSVPs close
+const rsvpDeadlineBefore = 3 * 24 * time.Hour
+
+// loadFixtures builds the club, members, sessions and RSVPs, and what
+// members have done around them. IDs, names and RSVPs are the same on every
+// run; dates are laid out around today so there is always something past,
+// closed and open.
+func (s *Server) loadFixtures() {
+	now := time.Now()
+	created := now.AddDate(0, -6, 0)
+	s.guests = make(map[uuid.UUID][]models.GuestRSVP)
+	s.waitlists = make(map[uuid.UUID][]models.WaitlistEntry)
+	s.attendance = make(map[uuid.UUID][]models.Attendance)
+	s.attachments = make(map[uuid.UUID][]models.SessionAttachment)
+	s.regulars = make(map[uuid.UUID][]models.SeriesRegular)
+	s.files = make(map[string][]byte)
+	s.issuedCards = make(map[string]services.MembershipCard)
+	s.prefs = make(map[uuid.UUID]*models.UserNotificationPreferences)
+
+	s.club = models.Club{
+		ID:                  fixtureID("club", "club"),
+		Name:                "Weekday Masters",
+		VenueName:           "Northside Badminton Centre",
+		VenueAddress:        "12 Shuttle Lane, Sydney NSW 2000",
+		RecurringWeeksAhead: 4,
+		AttendeeVisibility:  models.AttendeeNames,
+		WaitlistOfferHours:  2,
+		WeatherRainChance:   70,
+		WeatherWindKmh:      40,
+		WeatherTemperature:  38,
+		CreatedAt:           created,
+		UpdatedAt:           created,
+	}
+
+	s.users = make([]models.User, len(fixtureUsers))
+	for i, f := range fixtureUsers {
+		s.users[i] = models.User{
+			ID:                fixtureID("user", f.handle),
+			Auth0ID:           "mock|" + f.handle,
+			Email:             f.handle + "@example.com",
+			Name:              f.name,
+			PhoneNumber:       fmt.Sprintf("0400 000 %03d", i+1),
+			Role:              f.role,
+			IsPlayer:          true,
+			MembershipStatus:  f.status,
+			Tier:              models.TierUnlimited,
+			CommitteeRole:     f.committee,
+			PreferredLanguage: models.DefaultLanguage,
+			Privacy:           f.privacy,
+			CreatedAt:         created,
+			UpdatedAt:         created,
+		}
+		if f.inactive {
+			notified := now.AddDate(0, 0, -20)
+			s.users[i].InactiveNotifiedAt = &notified
+		}
+	}
+	s.joinRules = models.JoinRules{
+		ID:            fixtureID("join-rules", "club"),
+		ClubID:        s.club.ID,
+		EmailDomains:  []string{},
+		ReferralCodes: true,
+		CreatedAt:     created,
+		UpdatedAt:     created,
+	}
+
+	today := utils.StartOfDay(utils.NowInSydney())
+	admin := fixtureID("user", "admin")
+	coach := fixtureID("user", "coach")
+
+	s.sessions = make([]models.Session, len(fixtureSessions))
+	s.rsvps = make(map[uuid.UUID][]models.RSVP, len(fixtureSessions))
+	for i, f := range fixtureSessions {
+		date := today.AddDate(0, 0, f.day)
+		startsAt := time.Date(date.Year(), date.Month(), date.Day(), f.startHour, 0, 0, 0, utils.SydneyLocation)
+		session := models.Session{
+			ID:           fixtureID("session", f.handle),
+			Title:        f.title,
+			Description:  "Doubles rotation, all levels welcome. Shuttles provided.",
+			SessionDate:  date,
+			StartsAt:     startsAt,
+			EndsAt:       startsAt.Add(time.Duration(f.hours) * time.Hour),
+			Courts:       f.courts,
+			MaxPlayers:   models.MaxPlayersForCourts(f.courts),
+			RSVPDeadline: startsAt.Add(-rsvpDeadlineBefore),
+			Status:       models.SessionStatusOpen,
+			IsOutdoor:    f.outdoor,
+			AllowGuests:  f.guests,
+			SessionType:  models.SessionTypeSocial,
+			CreatedBy:    admin,
+			CreatedAt:    startsAt.AddDate(0, 0, -21),
+			UpdatedAt:    startsAt.AddDate(0, 0, -21),
+		}
+		if f.training {
+			session.SessionType = models.SessionTypeTraining
+			session.MaxPlayers = f.spots
+			session.CoachID = &coach
+			session.CurriculumNotes = "Net kills, tight net shots and interceptions at the front."
+		}
+		session.RequiresApproval = f.approval
+		if f.recurring {
+			weekday := int(date.Weekday())
+			session.IsRecurring = true
+			session.RecurringDayOfWeek = &weekday
+		}
+		if f.cancelled != "" {
+			session.Status = models.SessionStatusCancelled
+			session.CancellationReason = f.cancelled
+		}
+		if startsAt.Before(now) {
+			session.Status = models.SessionStatusClosed
+			used := 4 * f.courts
+			session.ShuttlesUsed = &used
+		}
+		s.sessions[i] = session
+
+		// RSVPs trickle in over the week after the session was scheduled
+		at := session.CreatedAt
+		add := func(handle string, status models.RSVPStatus) {
+			at = at.Add(7 * time.Hour)
+			s.rsvps[session.ID] = append(s.rsvps[session.ID], models.RSVP{
+				ID:            fixtureID("rsvp", f.handle+"/"+handle),
+				SessionID:     session.ID,
+				UserID:        fixtureID("user", handle),
+				Status:        status,
+				RSVPTimestamp: at,
+				CreatedAt:     at,
+				UpdatedAt:     at,
+			})
+		}
+		for j, handle := range f.going {
+			status := models.RSVPStatusIn
+			if j >= session.MaxPlayers {
+				status = models.RSVPStatusWaitlisted
+			}
+			add(handle, status)
+			if status == models.RSVPStatusWaitlisted {
+				s.waitlists[session.ID] = append(s.waitlists[session.ID], models.WaitlistEntry{
+					ID:        fixtureID("waitlist", f.handle+"/"+handle),
+					SessionID: session.ID,
+					UserID:    fixtureID("user", handle),
+					Position:  len(s.waitlists[session.ID]) + 1,
+					CreatedAt: at,
+				})
+			}
+		}
+		for _, handle := range f.maybe {
+			add(handle, models.RSVPStatusMaybe)
+		}
+		for _, handle := range f.out {
+			add(handle, models.RSVPStatusOut)
+		}
+	}
+
+	s.loadActivity(now, today)
+}
+
+// loadActivity adds what members have done around the sessions: the weekly
+// series and its regulars, attendance, results, comments, announcements,
+// notifications, join paths, equipment, a tournament, an order window and
+// job history
+func (s *Server) loadActivity(now, today time.Time) {
+	admin := fixtureID("user", "admin")
+	lee := fixtureID("user", "lee")
+	member := fixtureID("user", "member")
+	approved := []uuid.UUID{}
+	for _, u := range s.users {
+		if u.MembershipStatus == models.MembershipApproved {
+			approved = append(approved, u.ID)
+		}
+	}
+
+	// The series' regulars are put in to each week it generates
+	series := fixtureID("session", "series")
+	for _, handle := range []string{"lee", "priya"} {
+		s.regulars[series] = append(s.regulars[series], models.SeriesRegular{
+			ID: fixtureID("regular", handle), SeriesID: series, UserID: fixtureID("user", handle), AddedBy: admin, CreatedAt: now.AddDate(0, -1, 0),
+		})
+	}
+	s.generateSeries()
+
+	// Attendance at past sessions: Tom didn't turn up to the social, Wei
+	// said in time he couldn't make the smash night
+	missed := map[string]models.AttendanceStatus{"past-social/tom": models.AttendanceAbsent, "past-smash/wei": models.AttendanceExcused}
+	for _, handle := range []string{"past-social", "past-smash"} {
+		session := s.sessionByID(fixtureID("session", handle))
+		for _, rsvp := range s.rsvps[session.ID] {
+			if rsvp.Status != models.RSVPStatusIn {
+				continue
+			}
+			status, ok := missed[handle+"/"+s.handle(rsvp.UserID)]
+			if !ok {
+				status = models.AttendancePresent
+			}
+			record := models.Attendance{
+				ID: fixtureID("attendance", rsvp.ID.String()), SessionID: session.ID, UserID: rsvp.UserID, Status: status,
+				RecordedBy: &admin, CreatedAt: session.EndsAt, UpdatedAt: session.EndsAt,
+			}
+			if status == models.AttendancePresent {
+				record.CheckedInAt = &session.StartsAt
+			}
+			s.attendance[session.ID] = append(s.attendance[session.ID], record)
+		}
+	}
+
+	pastSocial := s.sessionByID(fixtureID("session", "past-social"))
+	gameID := fixtureID("game", "past-social/1")
+	confirmedAt := pastSocial.EndsAt
+	s.games = append(s.games, models.Game{
+		ID: gameID, SessionID: pastSocial.ID, TeamAScore: 21, TeamBScore: 17, Status: models.GameStatusConfirmed,
+		RecordedBy: admin, ConfirmedBy: &member, ConfirmedAt: &confirmedAt, CreatedAt: pastSocial.EndsAt, UpdatedAt: pastSocial.EndsAt,
+		Players: []models.GamePlayer{
+			{ID: fixtureID("game-player", "past-social/1/admin"), GameID: gameID, UserID: admin, Team: models.GameTeamA},
+			{ID: fixtureID("game-player", "past-social/1/member"), GameID: gameID, UserID: member, Team: models.GameTeamA},
+			{ID: fixtureID("game-player", "past-social/1/jordan"), GameID: gameID, UserID: fixtureID("user", "jordan"), Team: models.GameTeamB},
+			{ID: fixtureID("game-player", "past-social/1/priya"), GameID: gameID, UserID: fixtureID("user", "priya"), Team: models.GameTeamB},
+		},
+	})
+
+	nextSocial := fixtureID("session", "next-social")
+	s.comments = append(s.comments, models.Comment{
+		ID: fixtureID("comment", "next-social/1"), SessionID: nextSocial, UserID: fixtureID("user", "jordan"),
+		Body: "Is anyone bringing the new feather shuttles?", CreatedAt: now.Add(-5 * time.Hour), UpdatedAt: now.Add(-5 * time.Hour),
+	})
+
+	announced := now.AddDate(0, 0, -2)
+	announcement := models.Announcement{
+		ID: fixtureID("announcement", "fees"), Title: "Season fees are due",
+		Body:      "Fees for the new season are due by the end of the month. Pay at the desk or by bank transfer.",
+		CreatedBy: admin, SentAt: announced, CreatedAt: announced, Audience: models.AudienceMembers, RequiresAck: true,
+	}
+	s.announcements = append(s.announcements, announcement)
+	for _, handle := range []string{"admin", "jordan", "priya"} {
+		s.acks = append(s.acks, models.AnnouncementAcknowledgement{
+			ID: fixtureID("announcement-ack", "fees/"+handle), AnnouncementID: announcement.ID, UserID: fixtureID("user", handle), AcknowledgedAt: announced.Add(3 * time.Hour),
+		})
+	}
+
+	// Members have been told of their sessions; one push to Sam failed
+	sent := now.Add(-26 * time.Hour)
+	s.notifications = append(s.notifications,
+		models.Notification{
+			ID: fixtureID("notification", "announcement/member"), UserID: member, NotificationType: models.NotificationAdminAnnouncement,
+			Title: announcement.Title, Body: announcement.Body, Data: models.NotificationData{"announcement_id": announcement.ID.String()},
+			PushSent: true, PushSentAt: &announced, EmailSent: true, EmailSentAt: &announced, CreatedAt: announced,
+		},
+		models.Notification{
+			ID: fixtureID("notification", "reminder/member"), UserID: member, NotificationType: models.NotificationSessionReminder,
+			Title: "Evening Social tomorrow", Body: "Evening Social starts at 7:00 PM tomorrow.",
+			Data:      models.NotificationData{"session_id": fixtureID("session", "closed-social").String()},
+			PushError: "push subscription has expired", EmailSent: true, EmailSentAt: &sent, CreatedAt: sent,
+		},
+	)
+
+	// The club rules must be read before a first RSVP; every fixture member
+	// already has
+	rules := models.Document{
+		ID: fixtureID("document", "rules"), Title: "Club Rules", Description: "Court etiquette, fees and the code of conduct.",
+		Category: models.DocumentCategoryRules, FileName: "club-rules.pdf", ContentType: "application/pdf",
+		Required: true, UploadedBy: admin, CreatedAt: now.AddDate(0, -6, 0), UpdatedAt: now.AddDate(0, -6, 0),
+	}
+	rules.StorageKey = fmt.Sprintf("documents/%s.pdf", rules.ID)
+	s.files[rules.StorageKey] = []byte("%PDF-1.4\n% Weekday Masters club rules\n%%EOF\n")
+	rules.SizeBytes = int64(len(s.files[rules.StorageKey]))
+	s.documents = append(s.documents, rules)
+	for _, userID := range approved {
+		s.documentAcks = append(s.documentAcks, models.DocumentAcknowledgement{
+			ID: fixtureID("document-ack", userID.String()), DocumentID: rules.ID, UserID: userID, AcknowledgedAt: rules.CreatedAt,
+		})
+	}
+
+	s.referralCodes = append(s.referralCodes, models.ReferralCode{
+		ID: fixtureID("referral-code", "member"), Code: "SAMSMASH", OwnerID: member, Uses: 1, CreatedAt: now.AddDate(0, -2, 0),
+	})
+	s.invites = append(s.invites, models.Invite{
+		ID: fixtureID("invite", "open-day"), Token: "open-day-flyer", Label: "Open day flyer", MaxUses: 20, Uses: 3,
+		ExpiresAt: now.AddDate(0, 0, 30), CreatedBy: admin, CreatedAt: now.AddDate(0, 0, -10),
+	})
+
+	rob := fixtureID("user", "rob")
+	followUp := now.AddDate(0, 0, 3)
+	contacted := now.AddDate(0, 0, -4)
+	s.contacts = append(s.contacts, models.ContactLog{
+		ID: fixtureID("contact", "rob"), UserID: rob, Method: models.ContactPhone, Subject: "Haven't seen you in a while",
+		Notes: "Rob has a knee injury and hopes to be back next month.", ContactedAt: contacted, LoggedBy: admin,
+		FollowUpAt: &followUp, AssignedTo: &lee, CreatedAt: contacted, UpdatedAt: contacted,
+	})
+
+	s.proposals = append(s.proposals, models.CancellationProposal{
+		ID: fixtureID("proposal", "outdoor"), SessionID: fixtureID("session", "outdoor"), Status: models.ProposalPending,
+		Reason: "80% chance of rain", Forecast: "Showers, 80% chance of rain, 17°C, wind 22 km/h",
+		Secret: "6d6f636b2d70726f706f73616c2d736563726574", CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now.Add(-2 * time.Hour),
+	})
+
+	stocked := now.AddDate(0, -1, 0)
+	s.inventory = append(s.inventory,
+		models.InventoryItem{
+			ID: fixtureID("inventory", "shuttles"), Name: "Feather shuttles", Category: models.InventoryConsumable, Unit: "shuttles",
+			Quantity: 96, LowStockThreshold: 24, TracksShuttles: true, CreatedAt: stocked, UpdatedAt: stocked,
+		},
+		models.InventoryItem{
+			ID: fixtureID("inventory", "net"), Name: "Portable net", Category: models.InventoryEquipment, Unit: "nets",
+			Quantity: 2, Notes: "For outdoor sessions", CreatedAt: stocked, UpdatedAt: stocked,
+		},
+	)
+
+	ladder := fixtureID("tournament", "ladder")
+	opened := now.AddDate(0, 0, -5)
+	tournament := models.Tournament{
+		ID: ladder, Name: "Winter Ladder", Description: "Round robin singles over the winter season.",
+		Format: models.TournamentRoundRobin, Status: models.TournamentStatusRegistration, MaxEntries: 8,
+		CreatedBy: admin, CreatedAt: opened, UpdatedAt: opened,
+	}
+	for i, handle := range []string{"member", "jordan", "priya"} {
+		tournament.Entries = append(tournament.Entries, models.TournamentEntry{
+			ID: fixtureID("tournament-entry", "ladder/"+handle), TournamentID: ladder, UserID: fixtureID("user", handle), Seed: i + 1, CreatedAt: opened,
+		})
+	}
+	s.tournaments = append(s.tournaments, tournament)
+
+	shirts := fixtureID("order-window", "shirts")
+	s.orderWindows = append(s.orderWindows, models.OrderWindow{
+		ID: shirts, Title: "Club shirts", Description: "Orders go to the printer when the window closes.",
+		Status: models.OrderWindowOpen, ClosesAt: today.AddDate(0, 0, 10), CreatedBy: admin, CreatedAt: opened, UpdatedAt: opened,
+		Items: []models.OrderItem{
+			{ID: fixtureID("order-item", "shirts/shirt"), WindowID: shirts, Name: "Club shirt", Options: []string{"S", "M", "L", "XL"}, PriceCents: 3500, Position: 0},
+			{ID: fixtureID("order-item", "shirts/cap"), WindowID: shirts, Name: "Cap", Options: []string{}, PriceCents: 2000, Position: 1},
+		},
+	})
+
+	s.templates = append(s.templates, models.MessageTemplate{
+		ID: fixtureID("message-template", "session_reminder/en"), Key: models.MessageSessionReminder, Language: models.DefaultLanguage,
+		Title:       "{{.Session.Title}} is coming up ({{.Label}})",
+		Body:        "See you on court at {{clock .Session.StartsAt}} on {{.Date}}. Bring water and a spare grip.",
+		UpdatedByID: admin, CreatedAt: stocked, UpdatedAt: stocked,
+	})
+
+	s.progress = append(s.progress, models.TrainingProgress{
+		ID: fixtureID("training-progress", "member"), UserID: member, Goals: "Hold the net tape; recover to base after every net shot.",
+		CreatedAt: stocked, UpdatedAt: stocked,
+	})
+
+	// How often members have opened the app: Sam daily, Jordan not lately
+	for day := 0; day < 5; day++ {
+		seen := today.AddDate(0, 0, -day).Add(19 * time.Hour)
+		s.apiUsage = append(s.apiUsage, models.APIUsage{UserID: member, Day: today.AddDate(0, 0, -day), Requests: 12 + day, LastSeenAt: seen})
+	}
+	s.apiUsage = append(s.apiUsage, models.APIUsage{
+		UserID: fixtureID("user", "jordan"), Day: today.AddDate(0, 0, -20), Requests: 4, LastSeenAt: today.AddDate(0, 0, -20).Add(8 * time.Hour),
+	})
+
+	ran := today.Add(-time.Hour)
+	for _, run := range []struct {
+		job    string
+		failed string
+	}{
+		{job: services.JobRecurringSessions},
+		{job: services.JobSessionReminders},
+		{job: services.JobDatabaseBackup, failed: "uploading backup: bucket not configured"},
+	} {
+		finished := ran.Add(2 * time.Second)
+		jobRun := models.JobRun{ID: fixtureID("job-run", run.job), JobName: run.job, StartedAt: ran, FinishedAt: &finished, Status: models.JobRunStatusSucceeded}
+		if run.failed != "" {
+			jobRun.Status, jobRun.Error = models.JobRunStatusFailed, run.failed
+		}
+		s.jobRuns = append(s.jobRuns, jobRun)
+		ran = ran.Add(time.Minute)
+	}
+}
+
+// handle is the fixture handle of a member, or "" for anyone else
+func (s *Server) handle(userID uuid.UUID) string {
+	for _, f := range fixtureUsers {
+		if fixtureID("user", f.handle) == userID {
+			return f.handle
+		}
+	}
+	return ""
+}
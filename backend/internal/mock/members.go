@@ -0,0 +1,744 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"gorm.io/gorm"
+)
+
+// updateUser applies change to the member with id and returns a copy. The
+// caller holds s.mu.
+func (s *Server) updateUser(id uuid.UUID, change func(*models.User)) (*models.User, error) {
+	i := s.userIndex(id)
+	if i < 0 {
+		return nil, services.ErrUserNotFound
+	}
+	change(&s.users[i])
+	s.users[i].UpdatedAt = time.Now()
+	user := s.users[i]
+	return &user, nil
+}
+
+// usersWhere returns copies of the members keep accepts, by name. The caller
+// holds s.mu.
+func (s *Server) usersWhere(keep func(models.User) bool) []models.User {
+	return filter(s.users, keep)
+}
+
+// userService stands in for services.UserService
+type userService struct{ *Server }
+
+func (s userService) ApproveJoinRequest(userID, _ uuid.UUID) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateUser(userID, func(u *models.User) {
+		u.MembershipStatus, u.Role = models.MembershipApproved, models.RolePlayer
+	})
+}
+
+// CreateOrUpdateUser signs in a fixture member, or adds a new one waiting
+// for approval
+func (s userService) CreateOrUpdateUser(input services.CreateUserInput) (*models.User, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := indexOf(s.users, func(u models.User) bool { return u.Auth0ID == input.Auth0ID }); i >= 0 {
+		user := s.users[i]
+		return &user, false, nil
+	}
+	if indexOf(s.users, func(u models.User) bool { return strings.EqualFold(u.Email, input.Email) }) >= 0 {
+		return nil, false, services.ErrAccountLinkRequired
+	}
+	now := time.Now()
+	user := models.User{
+		ID: uuid.New(), Auth0ID: input.Auth0ID, Email: input.Email, Name: input.Name, ProfilePicture: input.ProfilePicture,
+		Role: models.RolePending, IsPlayer: true, MembershipStatus: models.MembershipPending, Tier: models.TierUnlimited,
+		PreferredLanguage: models.DefaultLanguage, CreatedAt: now, UpdatedAt: now,
+	}
+	s.users = append(s.users, user)
+	return &user, true, nil
+}
+
+func (s userService) GetUserByID(id uuid.UUID) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if user := s.user(id); user != nil {
+		return user, nil
+	}
+	return nil, services.ErrUserNotFound
+}
+
+func (s userService) GetUserProfile(id uuid.UUID) (*models.User, error) {
+	return s.GetUserByID(id)
+}
+
+func (s userService) ListApprovedMembers() ([]models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usersWhere(func(u models.User) bool { return u.MembershipStatus == models.MembershipApproved }), nil
+}
+
+func (s userService) ListPendingJoinRequests() ([]models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usersWhere(func(u models.User) bool { return u.MembershipStatus == models.MembershipPending }), nil
+}
+
+func (s userService) RejectJoinRequest(userID uuid.UUID) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateUser(userID, func(u *models.User) { u.MembershipStatus = models.MembershipRejected })
+}
+
+func (s userService) UpdateProfile(userID uuid.UUID, update services.ProfileUpdate) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateUser(userID, func(u *models.User) {
+		set(&u.PhoneNumber, update.PhoneNumber)
+		set(&u.EmergencyContactName, update.EmergencyContactName)
+		set(&u.EmergencyContactPhone, update.EmergencyContactPhone)
+		set(&u.MedicalNotes, update.MedicalNotes)
+		set(&u.Privacy, update.Privacy)
+		set(&u.PreferredLanguage, update.PreferredLanguage)
+	})
+}
+
+func (s userService) UpdateUserRole(userID uuid.UUID, role models.UserRole) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateUser(userID, func(u *models.User) { u.Role = role })
+}
+
+func (s userService) UpdateUserTier(userID uuid.UUID, tier models.MembershipTier) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateUser(userID, func(u *models.User) { u.Tier = tier })
+}
+
+// pendingActionService stands in for services.PendingActionService. As on
+// the real server, rejecting a member, deleting a session with RSVPs and
+// changing another admin's role wait for a second admin.
+type pendingActionService struct{ *Server }
+
+func (s pendingActionService) RequestIfRequired(actionType models.PendingActionType, targetID uuid.UUID, payload string, requestedBy uuid.UUID) (*models.PendingAction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch actionType {
+	case models.PendingActionDeleteSession:
+		if len(s.rsvps[targetID]) == 0 {
+			return nil, nil
+		}
+	case models.PendingActionChangeAdminRole:
+		target := s.user(targetID)
+		if target == nil {
+			return nil, services.ErrUserNotFound
+		}
+		if !target.IsAdmin() || target.ID == requestedBy {
+			return nil, nil
+		}
+	}
+	if indexOf(s.pendingActions, func(a models.PendingAction) bool {
+		return a.ActionType == actionType && a.TargetID == targetID && a.Status == models.PendingActionStatusPending
+	}) >= 0 {
+		return nil, services.ErrActionAwaitingApproval
+	}
+
+	now := time.Now()
+	action := models.PendingAction{
+		ID: uuid.New(), ActionType: actionType, TargetID: targetID, Payload: payload,
+		Status: models.PendingActionStatusPending, RequestedBy: requestedBy, CreatedAt: now, UpdatedAt: now,
+	}
+	s.pendingActions = append(s.pendingActions, action)
+	return &action, nil
+}
+
+func (s pendingActionService) ListPendingActions(status *models.PendingActionStatus) ([]models.PendingAction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	actions := []models.PendingAction{}
+	for i := len(s.pendingActions) - 1; i >= 0; i-- {
+		if action := s.pendingActions[i]; status == nil || action.Status == *status {
+			action.Requester = s.user(action.RequestedBy)
+			actions = append(actions, action)
+		}
+	}
+	return actions, nil
+}
+
+// ApprovePendingAction carries out the action through the other stubs
+func (s pendingActionService) ApprovePendingAction(id, approverID uuid.UUID) (*models.PendingAction, error) {
+	action, err := s.review(id, approverID, models.PendingActionStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+	switch action.ActionType {
+	case models.PendingActionDeleteSession:
+		err = sessionService(s).DeleteSession(action.TargetID)
+	case models.PendingActionRejectMember:
+		_, err = userService(s).RejectJoinRequest(action.TargetID)
+	case models.PendingActionChangeAdminRole:
+		_, err = userService(s).UpdateUserRole(action.TargetID, models.UserRole(action.Payload))
+	}
+	return action, err
+}
+
+func (s pendingActionService) DeclinePendingAction(id, reviewerID uuid.UUID) (*models.PendingAction, error) {
+	return s.review(id, reviewerID, models.PendingActionStatusDeclined)
+}
+
+func (s pendingActionService) review(id, reviewerID uuid.UUID, status models.PendingActionStatus) (*models.PendingAction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.pendingActions, func(a models.PendingAction) bool { return a.ID == id })
+	if i < 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	action := &s.pendingActions[i]
+	if action.Status != models.PendingActionStatusPending {
+		return nil, services.ErrActionReviewed
+	}
+	if status == models.PendingActionStatusApproved && action.RequestedBy == reviewerID {
+		return nil, errors.New("another admin must approve this action")
+	}
+	now := time.Now()
+	action.Status, action.ReviewedBy, action.ReviewedAt, action.UpdatedAt = status, &reviewerID, &now, now
+	reviewed := *action
+	return &reviewed, nil
+}
+
+// accountLinkService stands in for services.AccountLinkService. The code is
+// always 123456.
+type accountLinkService struct{ *Server }
+
+const mockLinkCode = "123456"
+
+func (s accountLinkService) RequestLink(_ context.Context, auth0ID, email string) error {
+	return nil
+}
+
+func (s accountLinkService) ConfirmLink(auth0ID, email, code string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.users, func(u models.User) bool { return strings.EqualFold(u.Email, email) })
+	if i < 0 || code != mockLinkCode {
+		return nil, services.ErrInvalidLinkCode
+	}
+	return s.updateUser(s.users[i].ID, func(u *models.User) { u.Auth0ID = auth0ID })
+}
+
+// joinRuleService stands in for services.JoinRuleService
+type joinRuleService struct{ *Server }
+
+// AutoApprove approves members who come with a referral code or whose email
+// is on the preapproved list
+func (s joinRuleService) AutoApprove(userID uuid.UUID, _ bool, referralCode string) (*models.JoinApproval, error) {
+	if referralCode != "" {
+		return s.RedeemReferralCode(userID, referralCode)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := s.user(userID)
+	if user == nil {
+		return nil, services.ErrUserNotFound
+	}
+	i := indexOf(s.preapproved, func(p models.PreapprovedMember) bool {
+		return strings.EqualFold(p.Email, user.Email) && p.UsedBy == nil
+	})
+	if !s.joinRules.PreapprovedList || i < 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	s.preapproved[i].UsedBy, s.preapproved[i].UsedAt = &userID, &now
+	return s.approveJoin(userID, models.JoinRulePreapproved, user.Email, nil)
+}
+
+// approveJoin approves a member under rule. The caller holds s.mu.
+func (s *Server) approveJoin(userID uuid.UUID, rule models.JoinRule, detail string, codeID *uuid.UUID) (*models.JoinApproval, error) {
+	user, err := s.updateUser(userID, func(u *models.User) {
+		u.MembershipStatus, u.Role = models.MembershipApproved, models.RolePlayer
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.JoinApproval{
+		ID: uuid.New(), UserID: userID, Rule: rule, Detail: detail, ReferralCodeID: codeID, CreatedAt: time.Now(), User: user,
+	}, nil
+}
+
+func (s joinRuleService) DeletePreapproved(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if indexOf(s.preapproved, func(p models.PreapprovedMember) bool { return p.ID == id }) < 0 {
+		return gorm.ErrRecordNotFound
+	}
+	s.preapproved = filter(s.preapproved, func(p models.PreapprovedMember) bool { return p.ID != id })
+	return nil
+}
+
+func (s joinRuleService) ImportPreapproved(entries []services.PreapprovedEntry, adminID uuid.UUID) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range entries {
+		s.preapproved = append(s.preapproved, models.PreapprovedMember{
+			ID: uuid.New(), Email: e.Email, Name: e.Name, AddedBy: adminID, CreatedAt: time.Now(),
+		})
+	}
+	return len(entries), nil
+}
+
+// ListApprovals lists the approved fixture members as approved by hand
+func (s joinRuleService) ListApprovals(rule models.JoinRule, limit, offset int) ([]models.JoinApproval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	approvals := []models.JoinApproval{}
+	if rule != "" && rule != models.JoinRuleManual {
+		return approvals, nil
+	}
+	admin := fixtureID("user", "admin")
+	for _, u := range s.usersWhere(func(u models.User) bool { return u.MembershipStatus == models.MembershipApproved }) {
+		user := u
+		approvals = append(approvals, models.JoinApproval{
+			ID: fixtureID("join-approval", u.ID.String()), UserID: u.ID, Rule: models.JoinRuleManual,
+			ApprovedBy: &admin, CreatedAt: u.CreatedAt, User: &user,
+		})
+	}
+	return page(approvals, limit, offset), nil
+}
+
+func (s joinRuleService) ListPreapproved() ([]models.PreapprovedMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.PreapprovedMember{}, s.preapproved...), nil
+}
+
+func (s joinRuleService) ListReferralCodes() ([]models.ReferralCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	codes := []models.ReferralCode{}
+	for _, c := range s.referralCodes {
+		c.Owner = s.user(c.OwnerID)
+		codes = append(codes, c)
+	}
+	return codes, nil
+}
+
+func (s joinRuleService) MyReferralCode(user *models.User) (*models.ReferralCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := indexOf(s.referralCodes, func(c models.ReferralCode) bool { return c.OwnerID == user.ID }); i >= 0 {
+		code := s.referralCodes[i]
+		return &code, nil
+	}
+	code := models.ReferralCode{
+		ID: uuid.New(), Code: strings.ToUpper(strings.ReplaceAll(user.Name, " ", ""))[:min(8, len(user.Name))],
+		OwnerID: user.ID, CreatedAt: time.Now(),
+	}
+	s.referralCodes = append(s.referralCodes, code)
+	return &code, nil
+}
+
+func (s joinRuleService) RedeemReferralCode(userID uuid.UUID, code string) (*models.JoinApproval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.referralCodes, func(c models.ReferralCode) bool { return strings.EqualFold(c.Code, code) && c.DisabledAt == nil })
+	if !s.joinRules.ReferralCodes || i < 0 {
+		return nil, services.ErrReferralCodeInvalid
+	}
+	s.referralCodes[i].Uses++
+	return s.approveJoin(userID, models.JoinRuleReferralCode, s.referralCodes[i].Code, &s.referralCodes[i].ID)
+}
+
+func (s joinRuleService) Rules() (*models.JoinRules, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rules := s.joinRules
+	return &rules, nil
+}
+
+func (s joinRuleService) SetReferralCodeDisabled(id uuid.UUID, disabled bool) (*models.ReferralCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.referralCodes, func(c models.ReferralCode) bool { return c.ID == id })
+	if i < 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	s.referralCodes[i].DisabledAt = nil
+	if disabled {
+		now := time.Now()
+		s.referralCodes[i].DisabledAt = &now
+	}
+	code := s.referralCodes[i]
+	return &code, nil
+}
+
+func (s joinRuleService) UpdateRules(input services.JoinRulesInput, adminID uuid.UUID) (*models.JoinRules, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	set(&s.joinRules.AutoApprove, input.AutoApprove)
+	set(&s.joinRules.EmailDomains, input.EmailDomains)
+	set(&s.joinRules.ReferralCodes, input.ReferralCodes)
+	set(&s.joinRules.PreapprovedList, input.PreapprovedList)
+	s.joinRules.UpdatedBy, s.joinRules.UpdatedAt = &adminID, time.Now()
+	rules := s.joinRules
+	return &rules, nil
+}
+
+// inviteService stands in for services.InviteService
+type inviteService struct{ *Server }
+
+// invite returns the invite keep accepts, or nil. The caller holds s.mu.
+func (s *Server) invite(keep func(models.Invite) bool) *models.Invite {
+	if i := indexOf(s.invites, keep); i >= 0 {
+		return &s.invites[i]
+	}
+	return nil
+}
+
+func (s inviteService) Accept(userID uuid.UUID, token string) (*models.InviteUse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	invite := s.invite(func(i models.Invite) bool { return i.Token == token })
+	switch {
+	case invite == nil || invite.RevokedAt != nil:
+		return nil, services.ErrInviteInvalid
+	case time.Now().After(invite.ExpiresAt) || invite.MaxUses > 0 && invite.Uses >= invite.MaxUses:
+		return nil, services.ErrInviteExpired
+	}
+	invite.Uses++
+	use := models.InviteUse{ID: uuid.New(), InviteID: invite.ID, UserID: userID, Approved: invite.AutoApprove, UsedAt: time.Now()}
+	s.inviteUses = append(s.inviteUses, use)
+	if use.Approved {
+		if _, err := s.approveJoin(userID, models.JoinRuleInvite, invite.Label, nil); err != nil {
+			return nil, err
+		}
+	}
+	return &use, nil
+}
+
+func (s inviteService) Create(input services.CreateInviteInput, adminID uuid.UUID) (*models.Invite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	invite := models.Invite{
+		ID: uuid.New(), Token: strings.ReplaceAll(uuid.NewString(), "-", ""), Label: input.Label, MaxUses: input.MaxUses,
+		ExpiresAt: input.ExpiresAt, AutoApprove: input.AutoApprove, CreatedBy: adminID, CreatedAt: time.Now(),
+	}
+	s.invites = append(s.invites, invite)
+	return &invite, nil
+}
+
+func (s inviteService) Get(id uuid.UUID) (*models.Invite, []models.InviteUse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	invite := s.invite(func(i models.Invite) bool { return i.ID == id })
+	if invite == nil {
+		return nil, nil, services.ErrInviteNotFound
+	}
+	uses := []models.InviteUse{}
+	for _, u := range s.inviteUses {
+		if u.InviteID == id {
+			u.User = s.user(u.UserID)
+			uses = append(uses, u)
+		}
+	}
+	found := *invite
+	return &found, uses, nil
+}
+
+func (s inviteService) Link(invite *models.Invite) string {
+	return strings.TrimRight(s.cfg.FrontendURL, "/") + "/join?invite=" + invite.Token
+}
+
+func (s inviteService) List() ([]models.Invite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	invites := append([]models.Invite{}, s.invites...)
+	reverse(invites)
+	return invites, nil
+}
+
+func (s inviteService) Preview(token string) (*models.Invite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	invite := s.invite(func(i models.Invite) bool { return i.Token == token })
+	if invite == nil || invite.RevokedAt != nil {
+		return nil, services.ErrInviteInvalid
+	}
+	found := *invite
+	return &found, nil
+}
+
+func (s inviteService) Revoke(id uuid.UUID) (*models.Invite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	invite := s.invite(func(i models.Invite) bool { return i.ID == id })
+	if invite == nil {
+		return nil, services.ErrInviteNotFound
+	}
+	now := time.Now()
+	invite.RevokedAt = &now
+	revoked := *invite
+	return &revoked, nil
+}
+
+// inactivityService stands in for services.MemberInactivityService
+type inactivityService struct{ *Server }
+
+func (s inactivityService) Archive(userID, _ uuid.UUID) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateUser(userID, func(u *models.User) {
+		now := time.Now()
+		u.MembershipStatus, u.ArchivedAt = models.MembershipArchived, &now
+	})
+}
+
+func (s inactivityService) KeepActive(userID, _ uuid.UUID) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateUser(userID, func(u *models.User) {
+		now := time.Now()
+		u.InactiveNotifiedAt, u.KeptActiveAt = nil, &now
+	})
+}
+
+func (s inactivityService) Restore(userID, _ uuid.UUID) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateUser(userID, func(u *models.User) {
+		u.MembershipStatus, u.ArchivedAt, u.InactiveNotifiedAt = models.MembershipApproved, nil, nil
+	})
+}
+
+// ReviewQueue lists members told they're inactive, due for review a month
+// after they were told
+func (s inactivityService) ReviewQueue() ([]services.InactiveMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue := []services.InactiveMember{}
+	for _, u := range s.usersWhere(func(u models.User) bool {
+		return u.InactiveNotifiedAt != nil && u.MembershipStatus == models.MembershipApproved
+	}) {
+		due := u.InactiveNotifiedAt.AddDate(0, 0, 30)
+		queue = append(queue, services.InactiveMember{User: u, ReviewDueAt: due, ReadyToArchive: time.Now().After(due)})
+	}
+	return queue, nil
+}
+
+// contactLogService stands in for services.ContactLogService
+type contactLogService struct{ *Server }
+
+// contactView fills in a contact log entry's members. The caller holds s.mu.
+func (s *Server) contactView(c models.ContactLog) models.ContactLog {
+	c.User, c.Logger = s.user(c.UserID), s.user(c.LoggedBy)
+	if c.AssignedTo != nil {
+		c.Assignee = s.user(*c.AssignedTo)
+	}
+	return c
+}
+
+func (s contactLogService) updateContact(id uuid.UUID, change func(*models.ContactLog)) (*models.ContactLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.contacts, func(c models.ContactLog) bool { return c.ID == id })
+	if i < 0 {
+		return nil, services.ErrContactNotFound
+	}
+	change(&s.contacts[i])
+	s.contacts[i].UpdatedAt = time.Now()
+	contact := s.contactView(s.contacts[i])
+	return &contact, nil
+}
+
+func (s contactLogService) CompleteFollowUp(id, actorID uuid.UUID) (*models.ContactLog, error) {
+	return s.updateContact(id, func(c *models.ContactLog) {
+		now := time.Now()
+		c.FollowUpDoneAt, c.FollowUpDoneBy = &now, &actorID
+	})
+}
+
+func (s contactLogService) DeleteContact(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if indexOf(s.contacts, func(c models.ContactLog) bool { return c.ID == id }) < 0 {
+		return services.ErrContactNotFound
+	}
+	s.contacts = filter(s.contacts, func(c models.ContactLog) bool { return c.ID != id })
+	return nil
+}
+
+func (s contactLogService) LogContact(userID uuid.UUID, input services.ContactLogInput, actorID uuid.UUID) (*models.ContactLog, error) {
+	s.mu.Lock()
+	now := time.Now()
+	contact := models.ContactLog{ID: uuid.New(), UserID: userID, LoggedBy: actorID, ContactedAt: now, CreatedAt: now}
+	s.contacts = append(s.contacts, contact)
+	s.mu.Unlock()
+	return s.UpdateContact(contact.ID, input, actorID)
+}
+
+func (s contactLogService) UpdateContact(id uuid.UUID, input services.ContactLogInput, _ uuid.UUID) (*models.ContactLog, error) {
+	return s.updateContact(id, func(c *models.ContactLog) {
+		c.Method, c.Subject, c.Notes = input.Method, input.Subject, input.Notes
+		set(&c.ContactedAt, input.ContactedAt)
+		c.FollowUpAt, c.AssignedTo = input.FollowUpAt, input.AssignedTo
+	})
+}
+
+func (s contactLogService) OpenFollowUps(assignedTo *uuid.UUID) ([]models.ContactLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	open := []models.ContactLog{}
+	for _, c := range s.contacts {
+		if c.FollowUpAt != nil && c.FollowUpDoneAt == nil && (assignedTo == nil || c.AssignedTo != nil && *c.AssignedTo == *assignedTo) {
+			open = append(open, s.contactView(c))
+		}
+	}
+	return open, nil
+}
+
+func (s contactLogService) UserTimeline(userID uuid.UUID) ([]services.TimelineEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	timeline := []services.TimelineEntry{}
+	for i := len(s.contacts) - 1; i >= 0; i-- {
+		if c := s.contacts[i]; c.UserID == userID {
+			contact := s.contactView(c)
+			timeline = append(timeline, services.TimelineEntry{Kind: "contact", At: c.ContactedAt, Contact: &contact})
+		}
+	}
+	return timeline, nil
+}
+
+// cardService stands in for services.CardService, signing cards with the
+// real one. A card scans while its token is one the mock issued and hasn't
+// expired.
+type cardService struct{ *Server }
+
+func (s cardService) IssueCard(user *models.User) (*services.MembershipCard, error) {
+	card, err := s.cards.IssueCard(user)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for token, issued := range s.issuedCards {
+		if now.After(issued.ExpiresAt) {
+			delete(s.issuedCards, token)
+		}
+	}
+	s.issuedCards[card.Token] = *card
+	return card, nil
+}
+
+func (s cardService) VerifyCard(token string) (*services.CardVerification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	card, ok := s.issuedCards[strings.TrimSpace(token)]
+	if !ok || time.Now().After(card.ExpiresAt) {
+		return &services.CardVerification{Reason: services.ErrInvalidCard.Error()}, nil
+	}
+	user := s.user(card.MemberID)
+	if user == nil {
+		return &services.CardVerification{Reason: "member no longer exists"}, nil
+	}
+	result := &services.CardVerification{
+		Valid: user.MembershipStatus == models.MembershipApproved,
+		Member: &services.CardVerifiedMember{
+			ID:               user.ID,
+			Name:             user.Name,
+			ProfilePicture:   user.ProfilePicture,
+			AvatarURL:        services.AvatarURL(user, models.AvatarMedium),
+			Tier:             user.Tier,
+			MembershipStatus: user.MembershipStatus,
+			MemberSince:      user.CreatedAt,
+		},
+	}
+	if !result.Valid {
+		result.Reason = "membership is " + string(user.MembershipStatus)
+	}
+	return result, nil
+}
+
+// committeeService stands in for services.CommitteeService
+type committeeService struct{ *Server }
+
+func (s committeeService) minutesIndex(id uuid.UUID) int {
+	return indexOf(s.minutes, func(m models.CommitteeMinutes) bool { return m.ID == id })
+}
+
+func (s committeeService) CreateMinutes(input services.MinutesInput, createdBy uuid.UUID) (*models.CommitteeMinutes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	minutes := models.CommitteeMinutes{
+		ID: uuid.New(), MeetingDate: input.MeetingDate, Title: input.Title, Body: input.Body, CreatedBy: createdBy, CreatedAt: now, UpdatedAt: now,
+	}
+	s.minutes = append(s.minutes, minutes)
+	minutes.Creator = s.user(createdBy)
+	return &minutes, nil
+}
+
+func (s committeeService) DeleteMinutes(id uuid.UUID, _ *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.minutesIndex(id) < 0 {
+		return services.ErrMinutesNotFound
+	}
+	s.minutes = filter(s.minutes, func(m models.CommitteeMinutes) bool { return m.ID != id })
+	return nil
+}
+
+func (s committeeService) GetMinutes(id uuid.UUID) (*models.CommitteeMinutes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.minutesIndex(id)
+	if i < 0 {
+		return nil, services.ErrMinutesNotFound
+	}
+	minutes := s.minutes[i]
+	minutes.Creator = s.user(minutes.CreatedBy)
+	return &minutes, nil
+}
+
+func (s committeeService) ListCommittee() ([]models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usersWhere(func(u models.User) bool { return u.CommitteeRole != models.CommitteeNone }), nil
+}
+
+func (s committeeService) ListMinutes() ([]models.CommitteeMinutes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	minutes := []models.CommitteeMinutes{}
+	for i := len(s.minutes) - 1; i >= 0; i-- {
+		m := s.minutes[i]
+		m.Creator = s.user(m.CreatedBy)
+		minutes = append(minutes, m)
+	}
+	return minutes, nil
+}
+
+func (s committeeService) SetCommitteeRole(userID uuid.UUID, role models.CommitteeRole, _ uuid.UUID) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateUser(userID, func(u *models.User) { u.CommitteeRole = role })
+}
+
+func (s committeeService) UpdateMinutes(id uuid.UUID, input services.MinutesInput, _ *models.User) (*models.CommitteeMinutes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.minutesIndex(id)
+	if i < 0 {
+		return nil, services.ErrMinutesNotFound
+	}
+	m := &s.minutes[i]
+	m.MeetingDate, m.Title, m.Body, m.UpdatedAt = input.MeetingDate, input.Title, input.Body, time.Now()
+	minutes := *m
+	minutes.Creator = s.user(minutes.CreatedBy)
+	return &minutes, nil
+}
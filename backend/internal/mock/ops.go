@@ -0,0 +1,911 @@
+package mock
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/storage"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+// carpoolService stands in for services.CarpoolService. Drivers and riders
+// aren't told of each other's changes.
+type carpoolService struct{ *Server }
+
+// carpoolSession returns a session that is open for carpooling. The caller
+// holds s.mu.
+func (s *Server) carpoolSession(sessionID uuid.UUID) (*models.Session, error) {
+	session := s.sessionByID(sessionID)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	if session.Status == models.SessionStatusCancelled || !session.StartsAt.After(time.Now()) {
+		return nil, services.ErrCarpoolClosed
+	}
+	return session, nil
+}
+
+// carpoolOffer returns the driver's offer for a session, or nil. The caller
+// holds s.mu.
+func (s *Server) carpoolOffer(sessionID, driverID uuid.UUID) *models.CarpoolOffer {
+	if i := indexOf(s.carpoolOffers, func(o models.CarpoolOffer) bool { return o.SessionID == sessionID && o.DriverID == driverID }); i >= 0 {
+		return &s.carpoolOffers[i]
+	}
+	return nil
+}
+
+// carpoolAsk returns a request for a session matching match, or nil. The
+// caller holds s.mu.
+func (s *Server) carpoolAsk(sessionID uuid.UUID, match func(models.CarpoolRequest) bool) *models.CarpoolRequest {
+	if i := indexOf(s.carpoolAsks, func(r models.CarpoolRequest) bool { return r.SessionID == sessionID && match(r) }); i >= 0 {
+		return &s.carpoolAsks[i]
+	}
+	return nil
+}
+
+// riders are the requests confirmed on an offer. The caller holds s.mu.
+func (s *Server) riders(offerID uuid.UUID) []models.CarpoolRequest {
+	return filter(s.carpoolAsks, func(r models.CarpoolRequest) bool {
+		return r.OfferID != nil && *r.OfferID == offerID && r.Status == models.CarpoolRequestConfirmed
+	})
+}
+
+// sameSuburb compares suburbs as the real service does, ignoring case and
+// spacing
+func sameSuburb(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
+
+func (s carpoolService) Board(sessionID, viewerID uuid.UUID) (*services.CarpoolBoard, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(sessionID)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	seatsLeft := 0
+	board := &services.CarpoolBoard{
+		Offers: []services.CarpoolOfferView{}, OpenRequests: []models.CarpoolRequest{},
+		SuggestedOffers: []services.CarpoolOfferView{}, SuggestedRequests: []models.CarpoolRequest{},
+	}
+	for _, offer := range s.carpoolOffers {
+		if offer.SessionID != sessionID {
+			continue
+		}
+		offer.Driver = s.user(offer.DriverID)
+		view := services.CarpoolOfferView{Offer: offer, Riders: []models.CarpoolRequest{}}
+		for _, r := range s.riders(offer.ID) {
+			r.Rider = s.user(r.RiderID)
+			view.Riders = append(view.Riders, r)
+		}
+		view.SeatsLeft = offer.Seats - len(view.Riders)
+		board.Offers = append(board.Offers, view)
+		if offer.DriverID == viewerID {
+			mine := offer
+			board.MyOffer, seatsLeft = &mine, view.SeatsLeft
+		}
+	}
+	for _, r := range s.carpoolAsks {
+		if r.SessionID != sessionID {
+			continue
+		}
+		r.Rider = s.user(r.RiderID)
+		if r.OfferID == nil {
+			board.OpenRequests = append(board.OpenRequests, r)
+		}
+		if r.RiderID == viewerID {
+			mine := r
+			board.MyRequest = &mine
+		}
+	}
+	if session.Status == models.SessionStatusCancelled || !session.StartsAt.After(time.Now()) {
+		return board, nil
+	}
+
+	// A driver with seats is shown the riders still looking, and a rider
+	// without a seat the drivers with room; their own suburb first
+	switch {
+	case board.MyOffer != nil && seatsLeft > 0:
+		board.SuggestedRequests = append(board.SuggestedRequests, board.OpenRequests...)
+		suburb := board.MyOffer.OriginSuburb
+		sort.SliceStable(board.SuggestedRequests, func(i, j int) bool {
+			return sameSuburb(board.SuggestedRequests[i].OriginSuburb, suburb) && !sameSuburb(board.SuggestedRequests[j].OriginSuburb, suburb)
+		})
+	case board.MyRequest != nil && board.MyRequest.Status != models.CarpoolRequestConfirmed:
+		for _, offer := range board.Offers {
+			if offer.SeatsLeft > 0 {
+				board.SuggestedOffers = append(board.SuggestedOffers, offer)
+			}
+		}
+		suburb := board.MyRequest.OriginSuburb
+		sort.SliceStable(board.SuggestedOffers, func(i, j int) bool {
+			a, b := board.SuggestedOffers[i], board.SuggestedOffers[j]
+			if sameSuburb(a.Offer.OriginSuburb, suburb) != sameSuburb(b.Offer.OriginSuburb, suburb) {
+				return sameSuburb(a.Offer.OriginSuburb, suburb)
+			}
+			return a.SeatsLeft > b.SeatsLeft
+		})
+	}
+	return board, nil
+}
+
+func (s carpoolService) SaveOffer(sessionID, driverID uuid.UUID, input services.SaveCarpoolOfferInput) (*models.CarpoolOffer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.carpoolSession(sessionID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	offer := s.carpoolOffer(sessionID, driverID)
+	if offer == nil {
+		s.carpoolOffers = append(s.carpoolOffers, models.CarpoolOffer{ID: uuid.New(), SessionID: sessionID, DriverID: driverID, CreatedAt: now})
+		offer = &s.carpoolOffers[len(s.carpoolOffers)-1]
+	}
+	offer.OriginSuburb, offer.Seats, offer.Notes = strings.TrimSpace(input.OriginSuburb), input.Seats, strings.TrimSpace(input.Notes)
+	offer.UpdatedAt = now
+	saved := *offer
+	return &saved, nil
+}
+
+func (s carpoolService) DeleteOffer(_ context.Context, sessionID, driverID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	offer := s.carpoolOffer(sessionID, driverID)
+	if offer == nil {
+		return services.ErrCarpoolNotFound
+	}
+	id := offer.ID
+	for i := range s.carpoolAsks {
+		if r := &s.carpoolAsks[i]; r.OfferID != nil && *r.OfferID == id {
+			r.Status, r.OfferID, r.ConfirmedAt, r.UpdatedAt = models.CarpoolRequestOpen, nil, nil, time.Now()
+		}
+	}
+	s.carpoolOffers = filter(s.carpoolOffers, func(o models.CarpoolOffer) bool { return o.ID != id })
+	return nil
+}
+
+func (s carpoolService) SaveRequest(_ context.Context, sessionID, riderID uuid.UUID, input services.SaveCarpoolRequestInput) (*models.CarpoolRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.carpoolSession(sessionID); err != nil {
+		return nil, err
+	}
+	if input.OfferID != nil && indexOf(s.carpoolOffers, func(o models.CarpoolOffer) bool {
+		return o.ID == *input.OfferID && o.SessionID == sessionID
+	}) < 0 {
+		return nil, services.ErrCarpoolNotFound
+	}
+	now := time.Now()
+	request := s.carpoolAsk(sessionID, func(r models.CarpoolRequest) bool { return r.RiderID == riderID })
+	if request == nil {
+		s.carpoolAsks = append(s.carpoolAsks, models.CarpoolRequest{
+			ID: uuid.New(), SessionID: sessionID, RiderID: riderID, Status: models.CarpoolRequestOpen, CreatedAt: now,
+		})
+		request = &s.carpoolAsks[len(s.carpoolAsks)-1]
+	}
+	request.OriginSuburb, request.Notes, request.UpdatedAt = strings.TrimSpace(input.OriginSuburb), strings.TrimSpace(input.Notes), now
+	sameOffer := (request.OfferID == nil && input.OfferID == nil) ||
+		(request.OfferID != nil && input.OfferID != nil && *request.OfferID == *input.OfferID)
+	if !sameOffer {
+		request.OfferID, request.ConfirmedAt, request.Status = input.OfferID, nil, models.CarpoolRequestOpen
+		if input.OfferID != nil {
+			request.Status = models.CarpoolRequestAsked
+		}
+	}
+	saved := *request
+	return &saved, nil
+}
+
+func (s carpoolService) DeleteRequest(_ context.Context, sessionID, riderID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	request := s.carpoolAsk(sessionID, func(r models.CarpoolRequest) bool { return r.RiderID == riderID })
+	if request == nil {
+		return services.ErrCarpoolNotFound
+	}
+	id := request.ID
+	s.carpoolAsks = filter(s.carpoolAsks, func(r models.CarpoolRequest) bool { return r.ID != id })
+	return nil
+}
+
+func (s carpoolService) Confirm(_ context.Context, sessionID, requestID, driverID uuid.UUID) (*models.CarpoolRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	offer := s.carpoolOffer(sessionID, driverID)
+	if offer == nil {
+		return nil, services.ErrNotYourCarpool
+	}
+	request := s.carpoolAsk(sessionID, func(r models.CarpoolRequest) bool { return r.ID == requestID })
+	if request == nil {
+		return nil, services.ErrCarpoolNotFound
+	}
+	if request.Status != models.CarpoolRequestConfirmed || request.OfferID == nil || *request.OfferID != offer.ID {
+		now, offerID := time.Now(), offer.ID
+		request.OfferID, request.Status, request.ConfirmedAt, request.UpdatedAt = &offerID, models.CarpoolRequestConfirmed, &now, now
+	}
+	confirmed := *request
+	return &confirmed, nil
+}
+
+func (s carpoolService) Decline(_ context.Context, sessionID, requestID, driverID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	offer := s.carpoolOffer(sessionID, driverID)
+	if offer == nil {
+		return services.ErrNotYourCarpool
+	}
+	request := s.carpoolAsk(sessionID, func(r models.CarpoolRequest) bool {
+		return r.ID == requestID && r.OfferID != nil && *r.OfferID == offer.ID
+	})
+	if request == nil {
+		return services.ErrCarpoolNotFound
+	}
+	request.Status, request.OfferID, request.ConfirmedAt, request.UpdatedAt = models.CarpoolRequestOpen, nil, nil, time.Now()
+	return nil
+}
+
+// inventoryService stands in for services.InventoryService. Admins aren't
+// alerted to low stock, and recorded shuttle usage doesn't draw stock down.
+type inventoryService struct{ *Server }
+
+var errItemNotFound = domainError(services.ErrNotFound, "item_not_found", "item not found")
+
+// inventoryItem returns the item with id, or nil. The caller holds s.mu.
+func (s *Server) inventoryItem(id uuid.UUID) *models.InventoryItem {
+	if i := indexOf(s.inventory, func(item models.InventoryItem) bool { return item.ID == id }); i >= 0 {
+		return &s.inventory[i]
+	}
+	return nil
+}
+
+// itemStock returns an item with how many are out at sessions. The caller
+// holds s.mu.
+func (s *Server) itemStock(item models.InventoryItem) services.InventoryItemStock {
+	out := 0
+	for _, c := range s.checkouts {
+		if c.ItemID == item.ID && c.CheckedInAt == nil {
+			out += c.Quantity
+		}
+	}
+	return services.InventoryItemStock{InventoryItem: item, CheckedOut: out, Available: item.Quantity - out, LowStock: item.IsLowStock()}
+}
+
+// changeStock changes an item's quantity and records the movement. The
+// caller holds s.mu.
+func (s *Server) changeStock(item *models.InventoryItem, change int, reason models.InventoryMovementReason, sessionID, actorID *uuid.UUID, note string) {
+	item.Quantity += change
+	item.UpdatedAt = time.Now()
+	s.movements = append(s.movements, models.InventoryMovement{
+		ID: uuid.New(), ItemID: item.ID, Change: change, Reason: reason, SessionID: sessionID, ActorID: actorID, Note: note, CreatedAt: item.UpdatedAt,
+	})
+}
+
+// saveItem copies input onto item. The caller holds s.mu.
+func (s *Server) saveItem(item *models.InventoryItem, input services.SaveInventoryItemInput) {
+	if input.Category == "" {
+		input.Category = models.InventoryEquipment
+	}
+	item.Name, item.Category, item.Unit = strings.TrimSpace(input.Name), input.Category, strings.TrimSpace(input.Unit)
+	item.LowStockThreshold, item.TracksShuttles, item.Notes = input.LowStockThreshold, input.TracksShuttles, strings.TrimSpace(input.Notes)
+	item.UpdatedAt = time.Now()
+	if item.TracksShuttles {
+		for i := range s.inventory {
+			if s.inventory[i].ID != item.ID {
+				s.inventory[i].TracksShuttles = false
+			}
+		}
+	}
+}
+
+func (s inventoryService) ListItems() ([]services.InventoryItemStock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stock := []services.InventoryItemStock{}
+	for _, item := range s.inventory {
+		stock = append(stock, s.itemStock(item))
+	}
+	sort.SliceStable(stock, func(i, j int) bool { return stock[i].Name < stock[j].Name })
+	return stock, nil
+}
+
+func (s inventoryService) GetItem(id uuid.UUID) (*services.InventoryItemStock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item := s.inventoryItem(id)
+	if item == nil {
+		return nil, errItemNotFound
+	}
+	stock := s.itemStock(*item)
+	return &stock, nil
+}
+
+func (s inventoryService) CreateItem(_ context.Context, input services.SaveInventoryItemInput, actorID uuid.UUID) (*models.InventoryItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inventory = append(s.inventory, models.InventoryItem{ID: uuid.New(), CreatedAt: time.Now()})
+	item := &s.inventory[len(s.inventory)-1]
+	s.saveItem(item, input)
+	if input.Quantity != 0 {
+		s.changeStock(item, input.Quantity, models.InventoryAdjustment, nil, &actorID, "opening stock")
+	}
+	created := *item
+	return &created, nil
+}
+
+func (s inventoryService) UpdateItem(_ context.Context, id uuid.UUID, input services.SaveInventoryItemInput) (*models.InventoryItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item := s.inventoryItem(id)
+	if item == nil {
+		return nil, errItemNotFound
+	}
+	s.saveItem(item, input)
+	updated := *item
+	return &updated, nil
+}
+
+func (s inventoryService) DeleteItem(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item := s.inventoryItem(id)
+	if item == nil {
+		return errItemNotFound
+	}
+	if s.itemStock(*item).CheckedOut > 0 {
+		return services.ErrItemCheckedOut
+	}
+	s.inventory = filter(s.inventory, func(item models.InventoryItem) bool { return item.ID != id })
+	s.checkouts = filter(s.checkouts, func(c models.InventoryCheckout) bool { return c.ItemID != id })
+	s.movements = filter(s.movements, func(m models.InventoryMovement) bool { return m.ItemID != id })
+	return nil
+}
+
+func (s inventoryService) Adjust(_ context.Context, id uuid.UUID, change int, reason models.InventoryMovementReason, note string, actorID uuid.UUID) (*models.InventoryItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item := s.inventoryItem(id)
+	if item == nil {
+		return nil, errItemNotFound
+	}
+	s.changeStock(item, change, reason, nil, &actorID, strings.TrimSpace(note))
+	adjusted := *item
+	return &adjusted, nil
+}
+
+func (s inventoryService) Movements(id uuid.UUID, limit int) ([]models.InventoryMovement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	movements := filter(s.movements, func(m models.InventoryMovement) bool { return m.ItemID == id })
+	reverse(movements)
+	return page(movements, limit, 0), nil
+}
+
+// checkoutView fills in a checkout's item. The caller holds s.mu.
+func (s *Server) checkoutView(c models.InventoryCheckout) models.InventoryCheckout {
+	if item := s.inventoryItem(c.ItemID); item != nil {
+		copied := *item
+		c.Item = &copied
+	}
+	return c
+}
+
+func (s inventoryService) SessionEquipment(sessionID uuid.UUID, _ *models.User) ([]models.InventoryCheckout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(sessionID) == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	checkouts := []models.InventoryCheckout{}
+	for _, c := range s.checkouts {
+		if c.SessionID == sessionID {
+			checkouts = append(checkouts, s.checkoutView(c))
+		}
+	}
+	return checkouts, nil
+}
+
+func (s inventoryService) CheckOut(sessionID, itemID uuid.UUID, quantity int, user *models.User) (*models.InventoryCheckout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(sessionID) == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	if s.inventoryItem(itemID) == nil {
+		return nil, errItemNotFound
+	}
+	s.checkouts = append(s.checkouts, models.InventoryCheckout{
+		ID: uuid.New(), ItemID: itemID, SessionID: sessionID, Quantity: quantity, CheckedOutBy: user.ID, CheckedOutAt: time.Now(),
+	})
+	checkout := s.checkoutView(s.checkouts[len(s.checkouts)-1])
+	return &checkout, nil
+}
+
+func (s inventoryService) CheckIn(_ context.Context, sessionID, checkoutID uuid.UUID, returned *int, user *models.User) (*models.InventoryCheckout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.checkouts, func(c models.InventoryCheckout) bool { return c.ID == checkoutID && c.SessionID == sessionID })
+	if i < 0 {
+		return nil, domainError(services.ErrNotFound, "checkout_not_found", "checkout not found")
+	}
+	checkout := &s.checkouts[i]
+	if checkout.CheckedInAt != nil {
+		return nil, errors.New("already checked in")
+	}
+	back := checkout.Quantity
+	if returned != nil {
+		back = *returned
+	}
+	now, userID := time.Now(), user.ID
+	checkout.ReturnedQuantity, checkout.CheckedInBy, checkout.CheckedInAt = &back, &userID, &now
+	if item := s.inventoryItem(checkout.ItemID); item != nil && back < checkout.Quantity {
+		s.changeStock(item, back-checkout.Quantity, models.InventoryNotReturned, &sessionID, &userID, "")
+	}
+	checkedIn := s.checkoutView(*checkout)
+	return &checkedIn, nil
+}
+
+func (s inventoryService) Consumption(months int) (*services.ConsumptionReport, error) {
+	if months < 1 || months > 24 {
+		return nil, errors.New("months must be between 1 and 24")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := utils.NowInSydney()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, utils.SydneyLocation).AddDate(0, 1-months, 0)
+	report := &services.ConsumptionReport{Months: make([]services.MonthlyConsumption, months)}
+	byMonth := map[string]*services.MonthlyConsumption{}
+	for i := range report.Months {
+		month := start.AddDate(0, i, 0).Format("2006-01")
+		report.Months[i] = services.MonthlyConsumption{Month: month, Items: []services.ItemConsumption{}}
+		byMonth[month] = &report.Months[i]
+	}
+
+	var shuttles, sessionsWithUsage int
+	for _, session := range s.sessions {
+		month, ok := byMonth[session.SessionDate.Format("2006-01")]
+		if !ok || session.ShuttlesUsed == nil || session.Status == models.SessionStatusCancelled {
+			continue
+		}
+		month.ShuttlesUsed += *session.ShuttlesUsed
+		month.SessionsWithUsage++
+		shuttles += *session.ShuttlesUsed
+		sessionsWithUsage++
+	}
+	if sessionsWithUsage > 0 {
+		report.AvgShuttlesPerSession = float64(shuttles) / float64(sessionsWithUsage)
+	}
+	for _, item := range s.inventory {
+		if item.TracksShuttles {
+			onHand := item.Quantity
+			report.ShuttlesOnHand = &onHand
+			if report.AvgShuttlesPerSession > 0 {
+				left := int(float64(onHand) / report.AvgShuttlesPerSession)
+				report.SessionsOfStockLeft = &left
+			}
+		}
+	}
+
+	for _, m := range s.movements {
+		month, ok := byMonth[m.CreatedAt.In(utils.SydneyLocation).Format("2006-01")]
+		item := s.inventoryItem(m.ItemID)
+		if !ok || item == nil || m.Reason == models.InventoryAdjustment {
+			continue
+		}
+		j := indexOf(month.Items, func(line services.ItemConsumption) bool { return line.ItemID == item.ID })
+		if j < 0 {
+			month.Items = append(month.Items, services.ItemConsumption{ItemID: item.ID, Name: item.Name, Unit: item.Unit})
+			j = len(month.Items) - 1
+		}
+		if m.Reason == models.InventoryRestock {
+			month.Items[j].Restocked += m.Change
+		} else {
+			month.Items[j].Used -= m.Change
+		}
+	}
+	for i := range report.Months {
+		items := report.Months[i].Items
+		sort.SliceStable(items, func(a, b int) bool { return items[a].Name < items[b].Name })
+	}
+	return report, nil
+}
+
+// orderService stands in for services.OrderService. Members aren't told a
+// window has opened, and windows close only when an admin closes them.
+type orderService struct{ *Server }
+
+// orderWindow returns the window with id, or nil. The caller holds s.mu.
+func (s *Server) orderWindow(id uuid.UUID) *models.OrderWindow {
+	if i := indexOf(s.orderWindows, func(w models.OrderWindow) bool { return w.ID == id }); i >= 0 {
+		return &s.orderWindows[i]
+	}
+	return nil
+}
+
+// orderIndex is the index of a member's order in a window, or -1. The
+// caller holds s.mu.
+func (s *Server) orderIndex(windowID, userID uuid.UUID) int {
+	return indexOf(s.orders, func(o models.Order) bool { return o.WindowID == windowID && o.UserID == userID })
+}
+
+func (s orderService) CreateWindow(input services.CreateOrderWindowInput) (*models.OrderWindow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	window := models.OrderWindow{
+		ID: uuid.New(), Title: strings.TrimSpace(input.Title), Description: input.Description, Status: models.OrderWindowOpen,
+		ClosesAt: input.ClosesAt, CreatedBy: input.CreatedBy, CreatedAt: now, UpdatedAt: now,
+	}
+	for i, item := range input.Items {
+		window.Items = append(window.Items, models.OrderItem{
+			ID: uuid.New(), WindowID: window.ID, Name: strings.TrimSpace(item.Name), Options: item.Options, PriceCents: item.PriceCents, Position: i,
+		})
+	}
+	s.orderWindows = append(s.orderWindows, window)
+	return &window, nil
+}
+
+func (s orderService) ListWindows() ([]models.OrderWindow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	windows := append([]models.OrderWindow{}, s.orderWindows...)
+	sort.SliceStable(windows, func(i, j int) bool {
+		if open := windows[i].Status == models.OrderWindowOpen; open != (windows[j].Status == models.OrderWindowOpen) {
+			return open
+		}
+		return windows[i].ClosesAt.After(windows[j].ClosesAt)
+	})
+	return windows, nil
+}
+
+func (s orderService) GetWindow(id uuid.UUID) (*models.OrderWindow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	window := s.orderWindow(id)
+	if window == nil {
+		return nil, services.ErrOrderWindowNotFound
+	}
+	found := *window
+	return &found, nil
+}
+
+func (s orderService) GetOrder(windowID, userID uuid.UUID) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.orderIndex(windowID, userID)
+	if i < 0 {
+		return nil, nil
+	}
+	order := s.orders[i]
+	return &order, nil
+}
+
+func (s orderService) SubmitOrder(windowID, userID uuid.UUID, lines []services.OrderLineInput, notes string) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	window := s.orderWindow(windowID)
+	if window == nil {
+		return nil, services.ErrOrderWindowNotFound
+	}
+	if !window.IsOpen(time.Now()) {
+		return nil, services.ErrOrderWindowClosed
+	}
+	now := time.Now()
+	i := s.orderIndex(windowID, userID)
+	if i < 0 {
+		s.orders = append(s.orders, models.Order{ID: uuid.New(), WindowID: windowID, UserID: userID, CreatedAt: now})
+		i = len(s.orders) - 1
+	}
+	order := &s.orders[i]
+	order.Notes, order.UpdatedAt, order.Lines = notes, now, []models.OrderLine{}
+	for _, line := range lines {
+		order.Lines = append(order.Lines, models.OrderLine{
+			ID: uuid.New(), OrderID: order.ID, ItemID: line.ItemID, Option: strings.TrimSpace(line.Option), Quantity: line.Quantity,
+		})
+	}
+	submitted := *order
+	return &submitted, nil
+}
+
+func (s orderService) CancelOrder(windowID, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	window := s.orderWindow(windowID)
+	if window == nil {
+		return services.ErrOrderWindowNotFound
+	}
+	if !window.IsOpen(time.Now()) {
+		return services.ErrOrderWindowClosed
+	}
+	i := s.orderIndex(windowID, userID)
+	if i < 0 {
+		return services.ErrOrderNotFound
+	}
+	s.orders = append(s.orders[:i], s.orders[i+1:]...)
+	return nil
+}
+
+func (s orderService) UpdateWindow(id uuid.UUID, input services.UpdateOrderWindowInput) (*models.OrderWindow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	window := s.orderWindow(id)
+	if window == nil {
+		return nil, services.ErrOrderWindowNotFound
+	}
+	if input.Title != nil {
+		window.Title = strings.TrimSpace(*input.Title)
+	}
+	set(&window.Description, input.Description)
+	if input.ClosesAt != nil {
+		window.ClosesAt, window.Status, window.ClosedAt = *input.ClosesAt, models.OrderWindowOpen, nil
+	}
+	window.UpdatedAt = time.Now()
+	updated := *window
+	return &updated, nil
+}
+
+func (s orderService) CloseWindow(_ context.Context, id uuid.UUID) (*models.OrderWindow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	window := s.orderWindow(id)
+	if window == nil {
+		return nil, services.ErrOrderWindowNotFound
+	}
+	if window.Status == models.OrderWindowClosed {
+		return nil, errors.New("this order is already closed")
+	}
+	now := time.Now()
+	window.Status, window.ClosedAt, window.UpdatedAt = models.OrderWindowClosed, &now, now
+	closed := *window
+	return &closed, nil
+}
+
+func (s orderService) Summary(windowID uuid.UUID) (*services.OrderSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	window := s.orderWindow(windowID)
+	if window == nil {
+		return nil, services.ErrOrderWindowNotFound
+	}
+	summary := &services.OrderSummary{Window: *window, Orders: []models.Order{}}
+	quantities := map[string]int{}
+	for _, order := range s.orders {
+		if order.WindowID != windowID {
+			continue
+		}
+		order.User = s.user(order.UserID)
+		summary.Orders = append(summary.Orders, order)
+		for _, line := range order.Lines {
+			quantities[line.ItemID.String()+"/"+line.Option] += line.Quantity
+		}
+	}
+	sort.SliceStable(summary.Orders, func(i, j int) bool {
+		return s.memberName(summary.Orders[i].UserID) < s.memberName(summary.Orders[j].UserID)
+	})
+	summary.OrderCount = len(summary.Orders)
+	for _, item := range window.Items {
+		options := item.Options
+		if len(options) == 0 {
+			options = []string{""}
+		}
+		for _, option := range options {
+			if quantity := quantities[item.ID.String()+"/"+option]; quantity > 0 {
+				total := services.OrderItemTotal{
+					ItemID: item.ID, Item: item.Name, Option: option, Quantity: quantity, PriceCents: item.PriceCents, TotalCents: quantity * item.PriceCents,
+				}
+				summary.Totals = append(summary.Totals, total)
+				summary.TotalCents += total.TotalCents
+			}
+		}
+	}
+	return summary, nil
+}
+
+// incidentService stands in for services.IncidentService. Admins aren't
+// alerted to new incidents.
+type incidentService struct{ *Server }
+
+var errIncidentNotFound = domainError(services.ErrNotFound, "incident_not_found", "incident not found")
+
+// visibleIncident returns the incident with id if viewer may see it: admins
+// and its reporter. The caller holds s.mu.
+func (s *Server) visibleIncident(id uuid.UUID, viewer *models.User) *models.Incident {
+	i := indexOf(s.incidents, func(incident models.Incident) bool { return incident.ID == id })
+	if i < 0 || (!viewer.IsAdmin() && s.incidents[i].ReportedBy != viewer.ID) {
+		return nil
+	}
+	return &s.incidents[i]
+}
+
+// incidentView fills in an incident's session and reporter. The caller
+// holds s.mu.
+func (s *Server) incidentView(incident models.Incident) models.Incident {
+	incident.Reporter = s.user(incident.ReportedBy)
+	if session := s.sessionByID(incident.SessionID); session != nil {
+		copied := *session
+		incident.Session = &copied
+	}
+	incident.Attachments = append([]models.IncidentAttachment{}, incident.Attachments...)
+	return incident
+}
+
+func (s incidentService) CreateIncident(sessionID uuid.UUID, reporter *models.User, input services.IncidentInput) (*models.Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(sessionID) == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	now := time.Now()
+	incident := models.Incident{
+		ID: uuid.New(), SessionID: sessionID, ReportedBy: reporter.ID, Type: input.Type, Severity: input.Severity,
+		Title: input.Title, Description: input.Description, OccurredAt: input.OccurredAt, Status: models.IncidentStatusOpen,
+		CreatedAt: now, UpdatedAt: now,
+	}
+	s.incidents = append(s.incidents, incident)
+	return &incident, nil
+}
+
+func (s incidentService) GetIncident(id uuid.UUID, viewer *models.User) (*models.Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	incident := s.visibleIncident(id, viewer)
+	if incident == nil {
+		return nil, errIncidentNotFound
+	}
+	view := s.incidentView(*incident)
+	return &view, nil
+}
+
+func (s incidentService) ListIncidents(status *models.IncidentStatus) ([]models.Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	incidents := []models.Incident{}
+	for i := len(s.incidents) - 1; i >= 0; i-- {
+		if status == nil || s.incidents[i].Status == *status {
+			view := s.incidentView(s.incidents[i])
+			view.Attachments = nil
+			incidents = append(incidents, view)
+		}
+	}
+	return incidents, nil
+}
+
+func (s incidentService) ResolveIncident(id, resolvedBy uuid.UUID, notes string) (*models.Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.incidents, func(incident models.Incident) bool { return incident.ID == id })
+	if i < 0 {
+		return nil, errIncidentNotFound
+	}
+	incident := &s.incidents[i]
+	if incident.Status == models.IncidentStatusResolved {
+		return nil, errors.New("incident is already resolved")
+	}
+	now := time.Now()
+	incident.Status, incident.ResolutionNotes, incident.ResolvedBy, incident.ResolvedAt, incident.UpdatedAt =
+		models.IncidentStatusResolved, notes, &resolvedBy, &now, now
+	resolved := *incident
+	return &resolved, nil
+}
+
+func (s incidentService) AddAttachment(_ context.Context, incidentID uuid.UUID, uploader *models.User, fileName string, size int64, r io.Reader) (*models.IncidentAttachment, error) {
+	upload, data, err := readUpload(storage.IncidentAttachments, size, r)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	incident := s.visibleIncident(incidentID, uploader)
+	if incident == nil {
+		return nil, errIncidentNotFound
+	}
+	attachment := models.IncidentAttachment{
+		ID: uuid.New(), IncidentID: incidentID, FileName: fileName, ContentType: upload.ContentType, SizeBytes: size,
+		UploadedBy: uploader.ID, CreatedAt: time.Now(),
+	}
+	attachment.StorageKey = "incidents/" + incidentID.String() + "/" + attachment.ID.String() + upload.Extension
+	s.files[attachment.StorageKey] = data
+	incident.Attachments = append(incident.Attachments, attachment)
+	return &attachment, nil
+}
+
+func (s incidentService) OpenAttachment(_ context.Context, incidentID, attachmentID uuid.UUID, viewer *models.User) (*models.IncidentAttachment, io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	incident := s.visibleIncident(incidentID, viewer)
+	if incident == nil {
+		return nil, nil, errIncidentNotFound
+	}
+	i := indexOf(incident.Attachments, func(a models.IncidentAttachment) bool { return a.ID == attachmentID })
+	if i < 0 {
+		return nil, nil, domainError(services.ErrNotFound, "attachment_not_found", "attachment not found")
+	}
+	attachment := incident.Attachments[i]
+	return &attachment, s.openFile(attachment.StorageKey), nil
+}
+
+// weatherProposalService stands in for services.WeatherProposalService.
+// Members aren't told when a proposal cancels their session.
+type weatherProposalService struct{ *Server }
+
+// proposalToken signs a decision on a proposal for an admin, as the real
+// service does for the links in its push notifications
+func proposalToken(proposal models.CancellationProposal, adminID uuid.UUID, decision string) string {
+	mac := hmac.New(sha256.New, []byte(proposal.Secret))
+	mac.Write([]byte(adminID.String() + ":" + decision))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s weatherProposalService) ListProposals(status models.ProposalStatus) ([]models.CancellationProposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	proposals := []models.CancellationProposal{}
+	for i := len(s.proposals) - 1; i >= 0; i-- {
+		if p := s.proposals[i]; status == "" || p.Status == status {
+			if session := s.sessionByID(p.SessionID); session != nil {
+				copied := *session
+				p.Session = &copied
+			}
+			if p.DecidedBy != nil {
+				p.Decider = s.user(*p.DecidedBy)
+			}
+			proposals = append(proposals, p)
+		}
+	}
+	return proposals, nil
+}
+
+func (s weatherProposalService) DecideWithToken(id, adminID uuid.UUID, decision, token string) (*models.CancellationProposal, error) {
+	s.mu.Lock()
+	i := indexOf(s.proposals, func(p models.CancellationProposal) bool { return p.ID == id })
+	if i < 0 {
+		s.mu.Unlock()
+		return nil, services.ErrProposalNotFound
+	}
+	valid := hmac.Equal([]byte(token), []byte(proposalToken(s.proposals[i], adminID, decision)))
+	admin := s.user(adminID)
+	s.mu.Unlock()
+	if !valid || admin == nil || !admin.IsAdmin() {
+		return nil, services.ErrProposalLinkInvalid
+	}
+	return s.Decide(id, adminID, decision == services.ProposalApprove)
+}
+
+func (s weatherProposalService) Decide(id, adminID uuid.UUID, approve bool) (*models.CancellationProposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.proposals, func(p models.CancellationProposal) bool { return p.ID == id })
+	if i < 0 {
+		return nil, services.ErrProposalNotFound
+	}
+	proposal := &s.proposals[i]
+	if proposal.Status != models.ProposalPending {
+		return nil, services.ErrProposalDecided
+	}
+	session := s.sessionByID(proposal.SessionID)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	now := time.Now()
+	proposal.Status, proposal.DecidedBy, proposal.DecidedAt, proposal.UpdatedAt = models.ProposalDeclined, &adminID, &now, now
+	switch {
+	case session.Status == models.SessionStatusCancelled || !now.Before(session.StartsAt):
+		proposal.Status = models.ProposalLapsed
+		return nil, services.ErrProposalLapsed
+	case approve:
+		proposal.Status = models.ProposalApproved
+		session.Status, session.CancellationReason, session.UpdatedAt = models.SessionStatusCancelled, "Forecast: "+proposal.Reason, now
+	}
+	decided := *proposal
+	return &decided, nil
+}
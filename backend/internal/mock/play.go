@@ -0,0 +1,756 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+// eloKFactor controls how much a single game moves a rating, as in the real
+// game service
+const eloKFactor = 32
+
+// gameService stands in for services.GameService. Badges aren't awarded.
+type gameService struct{ *Server }
+
+// gameView fills in a game's players. The caller holds s.mu.
+func (s *Server) gameView(g models.Game) models.Game {
+	g.Players = append([]models.GamePlayer{}, g.Players...)
+	for i := range g.Players {
+		g.Players[i].User = s.user(g.Players[i].UserID)
+	}
+	return g
+}
+
+// sessionGame returns the session's game with id, or nil. The caller holds
+// s.mu.
+func (s *Server) sessionGame(sessionID, gameID uuid.UUID) *models.Game {
+	if i := indexOf(s.games, func(g models.Game) bool { return g.ID == gameID && g.SessionID == sessionID }); i >= 0 {
+		return &s.games[i]
+	}
+	return nil
+}
+
+// playerRating returns a member's stored rating, adding the default one if
+// they haven't played. The caller holds s.mu.
+func (s *Server) playerRating(userID uuid.UUID) *models.PlayerRating {
+	if i := indexOf(s.playerRatings, func(r models.PlayerRating) bool { return r.UserID == userID }); i >= 0 {
+		return &s.playerRatings[i]
+	}
+	s.playerRatings = append(s.playerRatings, models.PlayerRating{
+		ID: uuid.New(), UserID: userID, Rating: models.DefaultEloRating, UpdatedAt: time.Now(),
+	})
+	return &s.playerRatings[len(s.playerRatings)-1]
+}
+
+func (s gameService) ConfirmGame(_ context.Context, sessionID, gameID, userID uuid.UUID) (*models.Game, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	game := s.sessionGame(sessionID, gameID)
+	switch {
+	case game == nil:
+		return nil, services.ErrGameNotFound
+	case game.Status == models.GameStatusConfirmed:
+		return nil, services.ErrGameConfirmed
+	case game.RecordedBy == userID:
+		return nil, services.ErrOwnGameScore
+	}
+	now := time.Now()
+	game.Status, game.ConfirmedBy, game.ConfirmedAt, game.UpdatedAt = models.GameStatusConfirmed, &userID, &now, now
+
+	// Move each player's rating by how their team did against the expected
+	// result
+	totals := map[models.GameTeam]float64{}
+	sizes := map[models.GameTeam]int{}
+	for _, p := range game.Players {
+		totals[p.Team] += s.playerRating(p.UserID).Rating
+		sizes[p.Team]++
+	}
+	avgA := totals[models.GameTeamA] / float64(sizes[models.GameTeamA])
+	avgB := totals[models.GameTeamB] / float64(sizes[models.GameTeamB])
+	expectedA := 1 / (1 + math.Pow(10, (avgB-avgA)/400))
+	winner := game.WinningTeam()
+	for _, p := range game.Players {
+		rating := s.playerRating(p.UserID)
+		expected, actual := expectedA, 0.0
+		if p.Team == models.GameTeamB {
+			expected = 1 - expectedA
+		}
+		if p.Team == winner {
+			actual = 1
+			rating.GamesWon++
+		}
+		rating.Rating += eloKFactor * (actual - expected)
+		rating.GamesPlayed++
+		rating.UpdatedAt = now
+	}
+	confirmed := s.gameView(*game)
+	return &confirmed, nil
+}
+
+func (s gameService) GetPlayerRating(userID uuid.UUID) (*models.PlayerRating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := indexOf(s.playerRatings, func(r models.PlayerRating) bool { return r.UserID == userID }); i >= 0 {
+		rating := s.playerRatings[i]
+		return &rating, nil
+	}
+	return &models.PlayerRating{UserID: userID, Rating: models.DefaultEloRating}, nil
+}
+
+func (s gameService) ListSessionGames(sessionID uuid.UUID) ([]models.Game, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	games := []models.Game{}
+	for i := len(s.games) - 1; i >= 0; i-- {
+		if s.games[i].SessionID == sessionID {
+			games = append(games, s.gameView(s.games[i]))
+		}
+	}
+	return games, nil
+}
+
+// myRatings are the ratings a player gave in a game. The caller holds s.mu.
+func (s *Server) myRatings(gameID, raterID uuid.UUID) []models.PeerRating {
+	ratings := filter(s.ratings, func(r models.PeerRating) bool { return r.GameID == gameID && r.RaterID == raterID })
+	if ratings == nil {
+		ratings = []models.PeerRating{}
+	}
+	return ratings
+}
+
+func (s gameService) MyRatings(gameID, raterID uuid.UUID) ([]models.PeerRating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.myRatings(gameID, raterID), nil
+}
+
+func (s gameService) RatePlayers(sessionID, gameID, raterID uuid.UUID, levels map[uuid.UUID]int) ([]models.PeerRating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	game := s.sessionGame(sessionID, gameID)
+	if game == nil {
+		return nil, services.ErrGameNotFound
+	}
+	if game.Status != models.GameStatusConfirmed {
+		return nil, services.ErrGameNotConfirmed
+	}
+	inGame := map[uuid.UUID]bool{}
+	for _, p := range game.Players {
+		inGame[p.UserID] = true
+	}
+	if !inGame[raterID] {
+		return nil, services.ErrNotInGame
+	}
+	for rateeID, level := range levels {
+		switch {
+		case rateeID == raterID:
+			return nil, domainError(services.ErrInvalid, "self_rating", "you can't rate yourself")
+		case !inGame[rateeID]:
+			return nil, domainError(services.ErrInvalid, "ratee_not_in_game", "you can only rate players in this game")
+		case level < models.MinPeerLevel || level > models.MaxPeerLevel:
+			return nil, domainError(services.ErrInvalid, "invalid_level",
+				fmt.Sprintf("levels run from %d to %d", models.MinPeerLevel, models.MaxPeerLevel))
+		}
+	}
+
+	now := time.Now()
+	for rateeID, level := range levels {
+		if i := indexOf(s.ratings, func(r models.PeerRating) bool {
+			return r.GameID == gameID && r.RaterID == raterID && r.RateeID == rateeID
+		}); i >= 0 {
+			s.ratings[i].Level, s.ratings[i].UpdatedAt = level, now
+			continue
+		}
+		s.ratings = append(s.ratings, models.PeerRating{
+			ID: uuid.New(), GameID: gameID, RaterID: raterID, RateeID: rateeID, Level: level, CreatedAt: now, UpdatedAt: now,
+		})
+	}
+	return s.myRatings(gameID, raterID), nil
+}
+
+func (s gameService) RecordGame(input services.RecordGameInput) (*models.Game, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(input.SessionID)
+	switch {
+	case session == nil:
+		return nil, services.ErrSessionNotFound
+	case session.Status == models.SessionStatusCancelled:
+		return nil, domainError(services.ErrConflict, "session_cancelled", "cannot record games for a cancelled session")
+	case len(input.TeamA) < 1 || len(input.TeamA) > 2 || len(input.TeamB) != len(input.TeamA):
+		return nil, domainError(services.ErrInvalid, "uneven_teams", "teams must have the same number of players (1 or 2)")
+	case input.TeamAScore < 0 || input.TeamBScore < 0:
+		return nil, domainError(services.ErrInvalid, "negative_score", "scores cannot be negative")
+	case input.TeamAScore == input.TeamBScore:
+		return nil, domainError(services.ErrInvalid, "drawn_game", "games cannot end in a draw")
+	}
+
+	now := time.Now()
+	game := models.Game{
+		ID: uuid.New(), SessionID: input.SessionID, TeamAScore: input.TeamAScore, TeamBScore: input.TeamBScore,
+		Status: models.GameStatusPending, RecordedBy: input.RecordedBy, CreatedAt: now, UpdatedAt: now,
+	}
+	seen := map[uuid.UUID]bool{}
+	for _, team := range []struct {
+		ids  []uuid.UUID
+		team models.GameTeam
+	}{{input.TeamA, models.GameTeamA}, {input.TeamB, models.GameTeamB}} {
+		for _, id := range team.ids {
+			if seen[id] {
+				return nil, domainError(services.ErrInvalid, "duplicate_player", "a player cannot appear more than once in a game")
+			}
+			seen[id] = true
+			game.Players = append(game.Players, models.GamePlayer{ID: uuid.New(), GameID: game.ID, UserID: id, Team: team.team})
+		}
+	}
+	for id := range seen {
+		if u := s.user(id); u == nil || u.MembershipStatus != models.MembershipApproved {
+			return nil, domainError(services.ErrInvalid, "players_not_members", "all players must be approved members")
+		}
+	}
+	s.games = append(s.games, game)
+	game = s.gameView(game)
+	return &game, nil
+}
+
+// tournamentService stands in for services.TournamentService. Badges
+// aren't awarded.
+type tournamentService struct{ *Server }
+
+// tournament returns the tournament with id, or nil. The caller holds s.mu.
+func (s *Server) tournament(id uuid.UUID) *models.Tournament {
+	if i := indexOf(s.tournaments, func(t models.Tournament) bool { return t.ID == id }); i >= 0 {
+		return &s.tournaments[i]
+	}
+	return nil
+}
+
+// tournamentView fills in a tournament's winner and, if full, its entries
+// and fixtures in order. The caller holds s.mu.
+func (s *Server) tournamentView(t *models.Tournament, full bool) models.Tournament {
+	view := *t
+	view.Entries, view.Matches = nil, nil
+	if t.WinnerID != nil {
+		view.Winner = s.user(*t.WinnerID)
+	}
+	if !full {
+		return view
+	}
+	view.Entries = append([]models.TournamentEntry{}, t.Entries...)
+	sort.SliceStable(view.Entries, func(i, j int) bool {
+		if view.Entries[i].Seed != view.Entries[j].Seed {
+			return view.Entries[i].Seed < view.Entries[j].Seed
+		}
+		return view.Entries[i].CreatedAt.Before(view.Entries[j].CreatedAt)
+	})
+	for i := range view.Entries {
+		view.Entries[i].User = s.user(view.Entries[i].UserID)
+	}
+	view.Matches = append([]models.TournamentMatch{}, t.Matches...)
+	sort.SliceStable(view.Matches, func(i, j int) bool {
+		if view.Matches[i].Round != view.Matches[j].Round {
+			return view.Matches[i].Round < view.Matches[j].Round
+		}
+		return view.Matches[i].MatchNumber < view.Matches[j].MatchNumber
+	})
+	for i := range view.Matches {
+		if m := &view.Matches[i]; m.Player1ID != nil {
+			m.Player1 = s.user(*m.Player1ID)
+		}
+		if m := &view.Matches[i]; m.Player2ID != nil {
+			m.Player2 = s.user(*m.Player2ID)
+		}
+	}
+	return view
+}
+
+func (s tournamentService) CreateTournament(input services.CreateTournamentInput) (*models.Tournament, error) {
+	if input.Format != models.TournamentRoundRobin && input.Format != models.TournamentKnockout {
+		return nil, domainError(services.ErrInvalid, "invalid_format", "format must be round_robin or knockout")
+	}
+	if input.MaxEntries < 0 {
+		return nil, domainError(services.ErrInvalid, "invalid_max_entries", "max entries cannot be negative")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	tournament := models.Tournament{
+		ID: uuid.New(), Name: input.Name, Description: input.Description, Format: input.Format,
+		MaxEntries: input.MaxEntries, Status: models.TournamentStatusRegistration, CreatedBy: input.CreatedBy,
+		CreatedAt: now, UpdatedAt: now,
+	}
+	s.tournaments = append(s.tournaments, tournament)
+	return &tournament, nil
+}
+
+// GenerateFixtures closes registration, seeds the entries by rating and
+// draws the matches, spreading the rounds across sessionIDs in order
+func (s tournamentService) GenerateFixtures(tournamentID uuid.UUID, sessionIDs []uuid.UUID) (*models.Tournament, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range sessionIDs {
+		if s.sessionByID(id) == nil {
+			return nil, domainError(services.ErrNotFound, "session_not_found", "one or more sessions not found")
+		}
+	}
+	tournament := s.tournament(tournamentID)
+	switch {
+	case tournament == nil:
+		return nil, services.ErrTournamentNotFound
+	case tournament.Status != models.TournamentStatusRegistration:
+		return nil, services.ErrFixturesGenerated
+	case len(tournament.Entries) < 2:
+		return nil, domainError(services.ErrConflict, "not_enough_entries", "at least 2 players must be registered")
+	}
+
+	entries := tournament.Entries
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+	rating := func(userID uuid.UUID) float64 {
+		if i := indexOf(s.playerRatings, func(r models.PlayerRating) bool { return r.UserID == userID }); i >= 0 {
+			return s.playerRatings[i].Rating
+		}
+		return models.DefaultEloRating
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return rating(entries[i].UserID) > rating(entries[j].UserID) })
+	players := make([]*uuid.UUID, len(entries))
+	for i := range entries {
+		entries[i].Seed = i + 1
+		players[i] = &entries[i].UserID
+	}
+
+	var matches []models.TournamentMatch
+	if tournament.Format == models.TournamentKnockout {
+		matches = knockoutFixtures(tournamentID, players)
+	} else {
+		matches = roundRobinFixtures(tournamentID, players)
+	}
+	now := time.Now()
+	for i := range matches {
+		matches[i].ID, matches[i].CreatedAt, matches[i].UpdatedAt = uuid.New(), now, now
+		if len(sessionIDs) > 0 {
+			sessionID := sessionIDs[(matches[i].Round-1)%len(sessionIDs)]
+			matches[i].SessionID = &sessionID
+		}
+	}
+	tournament.Matches = matches
+	if tournament.Format == models.TournamentKnockout {
+		for i := range tournament.Matches {
+			if tournament.Matches[i].Status == models.MatchStatusCompleted {
+				advanceKnockoutWinner(tournament, &tournament.Matches[i])
+			}
+		}
+	}
+	tournament.Status, tournament.UpdatedAt = models.TournamentStatusInProgress, now
+	view := s.tournamentView(tournament, true)
+	return &view, nil
+}
+
+// roundRobinFixtures pairs every player with every other by the circle
+// method, adding a bye for an odd number
+func roundRobinFixtures(tournamentID uuid.UUID, players []*uuid.UUID) []models.TournamentMatch {
+	slots := append([]*uuid.UUID{}, players...)
+	if len(slots)%2 == 1 {
+		slots = append(slots, nil)
+	}
+	n := len(slots)
+
+	var matches []models.TournamentMatch
+	for round := 1; round < n; round++ {
+		matchNumber := 1
+		for i := 0; i < n/2; i++ {
+			p1, p2 := slots[i], slots[n-1-i]
+			if p1 == nil || p2 == nil {
+				continue
+			}
+			matches = append(matches, models.TournamentMatch{
+				TournamentID: tournamentID, Round: round, MatchNumber: matchNumber,
+				Player1ID: p1, Player2ID: p2, Status: models.MatchStatusScheduled,
+			})
+			matchNumber++
+		}
+		last := slots[n-1]
+		copy(slots[2:], slots[1:n-1])
+		slots[1] = last
+	}
+	return matches
+}
+
+// knockoutFixtures draws a seeded bracket padded to a power of two; top
+// seeds get the byes, which count as won
+func knockoutFixtures(tournamentID uuid.UUID, players []*uuid.UUID) []models.TournamentMatch {
+	size := 1
+	for size < len(players) {
+		size *= 2
+	}
+	slots := make([]*uuid.UUID, size)
+	copy(slots, players)
+
+	var matches []models.TournamentMatch
+	for i := 0; i < size/2; i++ {
+		match := models.TournamentMatch{
+			TournamentID: tournamentID, Round: 1, MatchNumber: i + 1,
+			Player1ID: slots[i], Player2ID: slots[size-1-i], Status: models.MatchStatusScheduled,
+		}
+		if match.Player2ID == nil {
+			match.WinnerID, match.Status = match.Player1ID, models.MatchStatusCompleted
+		}
+		matches = append(matches, match)
+	}
+	round := 2
+	for count := size / 4; count >= 1; count /= 2 {
+		for i := 0; i < count; i++ {
+			matches = append(matches, models.TournamentMatch{
+				TournamentID: tournamentID, Round: round, MatchNumber: i + 1, Status: models.MatchStatusScheduled,
+			})
+		}
+		round++
+	}
+	return matches
+}
+
+// advanceKnockoutWinner puts a match's winner into their next-round slot
+func advanceKnockoutWinner(tournament *models.Tournament, match *models.TournamentMatch) {
+	i := indexOf(tournament.Matches, func(m models.TournamentMatch) bool {
+		return m.Round == match.Round+1 && m.MatchNumber == (match.MatchNumber+1)/2
+	})
+	if i < 0 {
+		return
+	}
+	next := &tournament.Matches[i]
+	if match.MatchNumber%2 == 1 {
+		next.Player1ID = match.WinnerID
+	} else {
+		next.Player2ID = match.WinnerID
+	}
+	next.UpdatedAt = time.Now()
+}
+
+// standings is the tournament table by wins, then points difference. The
+// caller holds s.mu.
+func (s *Server) standings(tournament *models.Tournament) []services.Standing {
+	standings := make([]services.Standing, len(tournament.Entries))
+	table := make(map[uuid.UUID]*services.Standing, len(tournament.Entries))
+	for i, e := range tournament.Entries {
+		standings[i] = services.Standing{UserID: e.UserID}
+		if u := s.user(e.UserID); u != nil {
+			standings[i].Name, standings[i].User = u.Name, u
+		}
+		table[e.UserID] = &standings[i]
+	}
+	for _, m := range tournament.Matches {
+		if m.Status != models.MatchStatusCompleted || m.Player1Score == nil {
+			continue
+		}
+		p1, ok1 := table[*m.Player1ID]
+		p2, ok2 := table[*m.Player2ID]
+		if !ok1 || !ok2 {
+			continue
+		}
+		p1.Played++
+		p2.Played++
+		p1.PointsFor += *m.Player1Score
+		p1.PointsAgainst += *m.Player2Score
+		p2.PointsFor += *m.Player2Score
+		p2.PointsAgainst += *m.Player1Score
+		if *m.WinnerID == *m.Player1ID {
+			p1.Wins++
+			p2.Losses++
+		} else {
+			p2.Wins++
+			p1.Losses++
+		}
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Wins != standings[j].Wins {
+			return standings[i].Wins > standings[j].Wins
+		}
+		di := standings[i].PointsFor - standings[i].PointsAgainst
+		dj := standings[j].PointsFor - standings[j].PointsAgainst
+		if di != dj {
+			return di > dj
+		}
+		return standings[i].Name < standings[j].Name
+	})
+	return standings
+}
+
+func (s tournamentService) GetStandings(tournamentID uuid.UUID) ([]services.Standing, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tournament := s.tournament(tournamentID)
+	if tournament == nil {
+		return nil, services.ErrTournamentNotFound
+	}
+	return s.standings(tournament), nil
+}
+
+func (s tournamentService) GetTournamentByID(id uuid.UUID) (*models.Tournament, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tournament := s.tournament(id)
+	if tournament == nil {
+		return nil, services.ErrTournamentNotFound
+	}
+	view := s.tournamentView(tournament, true)
+	return &view, nil
+}
+
+func (s tournamentService) ListTournaments() ([]models.Tournament, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tournaments := make([]models.Tournament, len(s.tournaments))
+	for i := range s.tournaments {
+		tournaments[i] = s.tournamentView(&s.tournaments[i], false)
+	}
+	sort.SliceStable(tournaments, func(i, j int) bool { return tournaments[i].CreatedAt.After(tournaments[j].CreatedAt) })
+	return tournaments, nil
+}
+
+// RecordResult records a match score and advances the tournament, naming
+// the winner once the final or the last round-robin match is played
+func (s tournamentService) RecordResult(_ context.Context, tournamentID, matchID uuid.UUID, player1Score, player2Score int) (*models.TournamentMatch, error) {
+	if player1Score < 0 || player2Score < 0 {
+		return nil, domainError(services.ErrInvalid, "negative_score", "scores cannot be negative")
+	}
+	if player1Score == player2Score {
+		return nil, domainError(services.ErrInvalid, "drawn_match", "matches cannot end in a draw")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tournament := s.tournament(tournamentID)
+	if tournament == nil {
+		return nil, services.ErrTournamentNotFound
+	}
+	if tournament.Status != models.TournamentStatusInProgress {
+		return nil, domainError(services.ErrConflict, "tournament_not_in_progress", "tournament is not in progress")
+	}
+	i := indexOf(tournament.Matches, func(m models.TournamentMatch) bool { return m.ID == matchID })
+	if i < 0 {
+		return nil, domainError(services.ErrNotFound, "match_not_found", "match not found")
+	}
+	match := &tournament.Matches[i]
+	if match.Player1ID == nil || match.Player2ID == nil {
+		return nil, domainError(services.ErrConflict, "match_players_undecided", "match players are not yet decided")
+	}
+	if tournament.Format == models.TournamentKnockout && match.Status == models.MatchStatusCompleted {
+		return nil, domainError(services.ErrConflict, "knockout_result_recorded", "knockout results cannot be changed once recorded")
+	}
+
+	match.Player1Score, match.Player2Score = &player1Score, &player2Score
+	match.WinnerID = match.Player2ID
+	if player1Score > player2Score {
+		match.WinnerID = match.Player1ID
+	}
+	match.Status, match.UpdatedAt = models.MatchStatusCompleted, time.Now()
+
+	var winnerID *uuid.UUID
+	switch tournament.Format {
+	case models.TournamentKnockout:
+		advanceKnockoutWinner(tournament, match)
+		final := 0
+		for _, m := range tournament.Matches {
+			final = max(final, m.Round)
+		}
+		if match.Round == final {
+			winnerID = match.WinnerID
+		}
+	case models.TournamentRoundRobin:
+		if indexOf(tournament.Matches, func(m models.TournamentMatch) bool { return m.Status != models.MatchStatusCompleted }) < 0 {
+			if standings := s.standings(tournament); len(standings) > 0 {
+				winnerID = &standings[0].UserID
+			}
+		}
+	}
+	if winnerID != nil {
+		tournament.WinnerID, tournament.Status, tournament.UpdatedAt = winnerID, models.TournamentStatusCompleted, time.Now()
+	}
+	recorded := *match
+	return &recorded, nil
+}
+
+func (s tournamentService) Register(tournamentID, userID uuid.UUID) (*models.TournamentEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tournament := s.tournament(tournamentID)
+	switch {
+	case tournament == nil:
+		return nil, services.ErrTournamentNotFound
+	case tournament.Status != models.TournamentStatusRegistration:
+		return nil, services.ErrRegistrationClosed
+	case indexOf(tournament.Entries, func(e models.TournamentEntry) bool { return e.UserID == userID }) >= 0:
+		return nil, services.ErrAlreadyRegistered
+	case tournament.MaxEntries > 0 && len(tournament.Entries) >= tournament.MaxEntries:
+		return nil, services.ErrTournamentFull
+	}
+	entry := models.TournamentEntry{ID: uuid.New(), TournamentID: tournamentID, UserID: userID, CreatedAt: time.Now()}
+	tournament.Entries = append(tournament.Entries, entry)
+	return &entry, nil
+}
+
+func (s tournamentService) Withdraw(tournamentID, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tournament := s.tournament(tournamentID)
+	if tournament == nil {
+		return services.ErrTournamentNotFound
+	}
+	if tournament.Status != models.TournamentStatusRegistration {
+		return services.ErrWithdrawClosed
+	}
+	i := indexOf(tournament.Entries, func(e models.TournamentEntry) bool { return e.UserID == userID })
+	if i < 0 {
+		return services.ErrNotRegistered
+	}
+	tournament.Entries = append(tournament.Entries[:i:i], tournament.Entries[i+1:]...)
+	return nil
+}
+
+// courtService stands in for services.CourtService. Players go on court in
+// queue order rather than grouped by level, and nobody is told.
+type courtService struct{ *Server }
+
+func (s courtService) GetBoard(sessionID uuid.UUID) (*services.CourtBoard, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.courtBoard(sessionID)
+}
+
+// courtBoard lays out who is on each of a session's courts and who is next up:
+// confirmed players who haven't played in RSVP order, then the rest by
+// when they came off. The caller holds s.mu.
+func (s *Server) courtBoard(sessionID uuid.UUID) (*services.CourtBoard, error) {
+	session := s.sessionByID(sessionID)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	board := &services.CourtBoard{SessionID: sessionID, Queue: []models.User{}}
+	onCourt := map[uuid.UUID]bool{}
+	lastPlayed := map[uuid.UUID]time.Time{}
+	for court := 1; court <= session.Courts; court++ {
+		board.Courts = append(board.Courts, services.CourtStatus{CourtNumber: court, Players: []models.CourtAssignment{}})
+	}
+	for _, a := range s.courts {
+		switch {
+		case a.SessionID != sessionID:
+		case a.ReleasedAt == nil:
+			onCourt[a.UserID] = true
+			if a.CourtNumber >= 1 && a.CourtNumber <= session.Courts {
+				a.User = s.user(a.UserID)
+				board.Courts[a.CourtNumber-1].Players = append(board.Courts[a.CourtNumber-1].Players, a)
+			}
+		case a.ReleasedAt.After(lastPlayed[a.UserID]):
+			lastPlayed[a.UserID] = *a.ReleasedAt
+		}
+	}
+
+	rsvps := filter(s.rsvps[sessionID], func(r models.RSVP) bool { return r.Status == models.RSVPStatusIn })
+	sort.SliceStable(rsvps, func(i, j int) bool { return rsvps[i].RSVPTimestamp.Before(rsvps[j].RSVPTimestamp) })
+	var rested []models.User
+	for _, r := range rsvps {
+		u := s.user(r.UserID)
+		if u == nil || onCourt[r.UserID] {
+			continue
+		}
+		if _, played := lastPlayed[r.UserID]; played {
+			rested = append(rested, *u)
+		} else {
+			board.Queue = append(board.Queue, *u)
+		}
+	}
+	sort.SliceStable(rested, func(i, j int) bool { return lastPlayed[rested[i].ID].Before(lastPlayed[rested[j].ID]) })
+	board.Queue = append(board.Queue, rested...)
+	return board, nil
+}
+
+// assign puts players on a court. The caller holds s.mu.
+func (s *Server) assign(sessionID uuid.UUID, courtNumber int, userIDs []uuid.UUID, assignedBy uuid.UUID) []models.CourtAssignment {
+	now := time.Now()
+	assignments := make([]models.CourtAssignment, len(userIDs))
+	for i, id := range userIDs {
+		assignments[i] = models.CourtAssignment{
+			ID: uuid.New(), SessionID: sessionID, CourtNumber: courtNumber, UserID: id, AssignedBy: assignedBy, AssignedAt: now,
+		}
+	}
+	s.courts = append(s.courts, assignments...)
+	return assignments
+}
+
+// releasePlayers marks the players on a session's courts keep picks as
+// finished. The caller holds s.mu.
+func (s *Server) releasePlayers(sessionID uuid.UUID, keep func(models.CourtAssignment) bool) {
+	now := time.Now()
+	for i := range s.courts {
+		if a := &s.courts[i]; a.SessionID == sessionID && a.ReleasedAt == nil && keep(*a) {
+			a.ReleasedAt = &now
+		}
+	}
+}
+
+func (s courtService) AssignNextUp(_ context.Context, sessionID, assignedBy uuid.UUID) ([]models.CourtAssignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	board, err := s.courtBoard(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	queue := board.Queue
+	created := []models.CourtAssignment{}
+	for _, court := range board.Courts {
+		if len(court.Players) > 0 {
+			continue
+		}
+		if len(queue) < models.PlayersPerCourt {
+			break
+		}
+		ids := make([]uuid.UUID, models.PlayersPerCourt)
+		for i, p := range queue[:models.PlayersPerCourt] {
+			ids[i] = p.ID
+		}
+		queue = queue[models.PlayersPerCourt:]
+		created = append(created, s.assign(sessionID, court.CourtNumber, ids, assignedBy)...)
+	}
+	return created, nil
+}
+
+func (s courtService) AssignPlayers(_ context.Context, sessionID uuid.UUID, courtNumber int, userIDs []uuid.UUID, assignedBy uuid.UUID) ([]models.CourtAssignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(sessionID)
+	switch {
+	case session == nil:
+		return nil, services.ErrSessionNotFound
+	case courtNumber < 1 || courtNumber > session.Courts:
+		return nil, fmt.Errorf("court must be between 1 and %d", session.Courts)
+	case len(userIDs) == 0 || len(userIDs) > models.PlayersPerCourt:
+		return nil, fmt.Errorf("a court takes between 1 and %d players", models.PlayersPerCourt)
+	}
+	placing := map[uuid.UUID]bool{}
+	for _, id := range userIDs {
+		placing[id] = true
+	}
+	s.releasePlayers(sessionID, func(a models.CourtAssignment) bool { return a.CourtNumber == courtNumber || placing[a.UserID] })
+	return s.assign(sessionID, courtNumber, userIDs, assignedBy), nil
+}
+
+func (s courtService) ReleaseCourt(sessionID uuid.UUID, courtNumber int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releasePlayers(sessionID, func(a models.CourtAssignment) bool { return a.CourtNumber == courtNumber })
+	return nil
+}
+
+// allocationService stands in for services.AllocationService. No fixture
+// session is filled by ballot, so there are never any results.
+type allocationService struct{ *Server }
+
+func (s allocationService) ListAllocationResults(uuid.UUID) ([]models.AllocationResult, error) {
+	return []models.AllocationResult{}, nil
+}
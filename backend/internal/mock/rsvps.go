@@ -0,0 +1,403 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+// rsvpFor returns the member's RSVP to a session, or nil. The caller holds
+// s.mu.
+func (s *Server) rsvpFor(sessionID, userID uuid.UUID) *models.RSVP {
+	rsvps := s.rsvps[sessionID]
+	if i := indexOf(rsvps, func(r models.RSVP) bool { return r.UserID == userID }); i >= 0 {
+		return &rsvps[i]
+	}
+	return nil
+}
+
+// setRSVP gives the member an RSVP with status, moving them on or off the
+// waitlist to match. The caller holds s.mu.
+func (s *Server) setRSVP(sessionID, userID uuid.UUID, status models.RSVPStatus) *models.RSVP {
+	now := time.Now()
+	rsvp := s.rsvpFor(sessionID, userID)
+	if rsvp == nil {
+		s.rsvps[sessionID] = append(s.rsvps[sessionID], models.RSVP{
+			ID: uuid.New(), SessionID: sessionID, UserID: userID, RSVPTimestamp: now, CreatedAt: now,
+		})
+		rsvp = &s.rsvps[sessionID][len(s.rsvps[sessionID])-1]
+	}
+	rsvp.Status, rsvp.UpdatedAt = status, now
+
+	waitlist := s.waitlists[sessionID]
+	i := indexOf(waitlist, func(w models.WaitlistEntry) bool { return w.UserID == userID })
+	switch {
+	case status == models.RSVPStatusWaitlisted && i < 0:
+		s.waitlists[sessionID] = append(waitlist, models.WaitlistEntry{
+			ID: uuid.New(), SessionID: sessionID, UserID: userID, Position: len(waitlist) + 1, CreatedAt: now,
+		})
+	case status != models.RSVPStatusWaitlisted && i >= 0:
+		waitlist = append(waitlist[:i], waitlist[i+1:]...)
+		for j := range waitlist {
+			waitlist[j].Position = j + 1
+		}
+		s.waitlists[sessionID] = waitlist
+	}
+	return rsvp
+}
+
+// removeRSVP drops the member's RSVP to a session. The caller holds s.mu.
+func (s *Server) removeRSVP(sessionID, userID uuid.UUID) error {
+	if s.rsvpFor(sessionID, userID) == nil {
+		return services.ErrRSVPNotFound
+	}
+	s.setRSVP(sessionID, userID, models.RSVPStatusOut)
+	s.rsvps[sessionID] = filter(s.rsvps[sessionID], func(r models.RSVP) bool { return r.UserID != userID })
+	return nil
+}
+
+// attendanceView returns a session's attendance records with their members.
+// The caller holds s.mu.
+func (s *Server) attendanceView(sessionID uuid.UUID) []models.Attendance {
+	records := []models.Attendance{}
+	for _, a := range s.attendance[sessionID] {
+		a.User = s.user(a.UserID)
+		records = append(records, a)
+	}
+	return records
+}
+
+// recordAttendance sets the member's attendance at a session. The caller
+// holds s.mu.
+func (s *Server) recordAttendance(sessionID, userID uuid.UUID, status models.AttendanceStatus, note string, recordedBy *uuid.UUID) *models.Attendance {
+	now := time.Now()
+	records := s.attendance[sessionID]
+	i := indexOf(records, func(a models.Attendance) bool { return a.UserID == userID })
+	if i < 0 {
+		records = append(records, models.Attendance{ID: uuid.New(), SessionID: sessionID, UserID: userID, CreatedAt: now})
+		i = len(records) - 1
+	}
+	record := &records[i]
+	record.Status, record.Note, record.RecordedBy, record.UpdatedAt = status, note, recordedBy, now
+	if status == models.AttendancePresent && record.CheckedInAt == nil {
+		record.CheckedInAt = &now
+	}
+	s.attendance[sessionID] = records
+	view := *record
+	view.User = s.user(userID)
+	return &view
+}
+
+// rsvpService stands in for services.RSVPService
+type rsvpService struct{ *Server }
+
+func (s rsvpService) AddGuest(sessionID, invitedBy uuid.UUID, name string) (*models.GuestRSVP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(sessionID) == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	now := time.Now()
+	guest := models.GuestRSVP{ID: uuid.New(), SessionID: sessionID, InvitedBy: invitedBy, Name: name, CreatedAt: now, UpdatedAt: now}
+	s.guests[sessionID] = append(s.guests[sessionID], guest)
+	guest.Inviter = s.user(invitedBy)
+	return &guest, nil
+}
+
+func (s rsvpService) AdminRemoveRSVP(sessionID, userID uuid.UUID, newStatus *models.RSVPStatus, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if newStatus == nil {
+		return s.removeRSVP(sessionID, userID)
+	}
+	if s.rsvpFor(sessionID, userID) == nil {
+		return services.ErrRSVPNotFound
+	}
+	s.setRSVP(sessionID, userID, *newStatus)
+	return nil
+}
+
+func (s rsvpService) ApproveRSVPRequest(sessionID, userID uuid.UUID) (*models.RSVP, error) {
+	return s.answerRequest(sessionID, userID, models.RSVPStatusIn)
+}
+
+func (s rsvpService) DeclineRSVPRequest(sessionID, userID uuid.UUID, _ string) (*models.RSVP, error) {
+	return s.answerRequest(sessionID, userID, models.RSVPStatusDeclined)
+}
+
+func (s rsvpService) answerRequest(sessionID, userID uuid.UUID, status models.RSVPStatus) (*models.RSVP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rsvp := s.rsvpFor(sessionID, userID); rsvp == nil || rsvp.Status != models.RSVPStatusRequested {
+		return nil, services.ErrRSVPNotFound
+	}
+	rsvp := s.rsvpView(*s.setRSVP(sessionID, userID, status))
+	return &rsvp, nil
+}
+
+func (s rsvpService) CheckIn(sessionID, userID uuid.UUID) (*models.RSVP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rsvp := s.rsvpFor(sessionID, userID)
+	if rsvp == nil {
+		return nil, services.ErrRSVPNotFound
+	}
+	now := time.Now()
+	rsvp.CheckedInAt = &now
+	s.recordAttendance(sessionID, userID, models.AttendancePresent, "", nil)
+	view := s.rsvpView(*rsvp)
+	return &view, nil
+}
+
+func (s rsvpService) SelfCheckIn(sessionID, userID uuid.UUID) (*models.RSVP, error) {
+	return s.CheckIn(sessionID, userID)
+}
+
+func (s rsvpService) ConfirmWaitlistOffer(sessionID, userID uuid.UUID) (*models.RSVP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rsvp := s.rsvpFor(sessionID, userID); rsvp == nil || rsvp.Status != models.RSVPStatusWaitlisted {
+		return nil, services.ErrRSVPNotFound
+	}
+	rsvp := s.rsvpView(*s.setRSVP(sessionID, userID, models.RSVPStatusIn))
+	return &rsvp, nil
+}
+
+// CreateOrUpdateRSVP records the answer, putting members on the waitlist
+// when the session is full and holding their IN for approval where the
+// session asks for it; nothing else is checked
+func (s rsvpService) CreateOrUpdateRSVP(input services.RSVPInput, byAdmin bool) (*models.RSVP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(input.SessionID)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	status := input.Status
+	current := s.rsvpFor(input.SessionID, input.UserID)
+	alreadyIn := current != nil && current.Status == models.RSVPStatusIn
+	if status == models.RSVPStatusIn && !alreadyIn {
+		view := s.sessionView(session)
+		switch {
+		case session.RequiresApproval && !byAdmin:
+			status = models.RSVPStatusRequested
+		case view.ConfirmedCount+view.GuestCount >= view.RSVPCapacity():
+			status = models.RSVPStatusWaitlisted
+		}
+	}
+	rsvp := s.setRSVP(input.SessionID, input.UserID, status)
+	rsvp.AddedByAdmin = byAdmin
+	view := s.rsvpView(*rsvp)
+	return &view, nil
+}
+
+func (s rsvpService) DeleteRSVP(sessionID, userID uuid.UUID, _ bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.removeRSVP(sessionID, userID)
+}
+
+func (s rsvpService) Forecast(sessionID uuid.UUID) (*services.OccupancyForecast, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(sessionID)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	view := s.sessionView(session)
+	const dropRate, noShowRate = 0.1, 0.05
+	expected := float64(view.ConfirmedCount) * (1 - dropRate)
+	return &services.OccupancyForecast{
+		SessionID: sessionID, MaxPlayers: view.MaxPlayers, Overbooking: view.Overbooking,
+		Confirmed: view.ConfirmedCount, Waitlisted: view.WaitlistCount, RSVPsOpen: time.Now().Before(view.RSVPDeadline),
+		Basis: "club", SessionsSampled: 12, DropRate: dropRate, NoShowRate: noShowRate,
+		ExpectedDrops: float64(view.ConfirmedCount) * dropRate, ExpectedPlayers: expected,
+		ExpectedAttendance: expected * (1 - noShowRate), SuggestedOverbooking: 1,
+	}, nil
+}
+
+func (s rsvpService) GetRSVPSummary(sessionID uuid.UUID) (*services.RSVPSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(sessionID)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	summary := services.SummarizeRSVPs(s.sessionView(session))
+	return &summary, nil
+}
+
+func (s rsvpService) GetSessionAttendance(sessionID uuid.UUID) ([]models.Attendance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(sessionID) == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	return s.attendanceView(sessionID), nil
+}
+
+func (s rsvpService) GetUserRSVPForSession(sessionID, userID uuid.UUID) (*models.RSVP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rsvp := s.rsvpFor(sessionID, userID)
+	if rsvp == nil {
+		return nil, services.ErrRSVPNotFound
+	}
+	view := s.rsvpView(*rsvp)
+	return &view, nil
+}
+
+func (s rsvpService) GetUserRSVPs(userID uuid.UUID, from, to *time.Time) ([]models.RSVP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rsvps := []models.RSVP{}
+	for i := range s.sessions {
+		session := &s.sessions[i]
+		if rsvp := s.rsvpFor(session.ID, userID); rsvp != nil && inRange(session.SessionDate, from, to) {
+			view := s.rsvpView(*rsvp)
+			sessionCopy := *session
+			view.Session = &sessionCopy
+			rsvps = append(rsvps, view)
+		}
+	}
+	return rsvps, nil
+}
+
+func (s rsvpService) GetUserRSVPsForSessions(userID uuid.UUID, sessionIDs []uuid.UUID) (map[uuid.UUID]models.RSVP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rsvps := map[uuid.UUID]models.RSVP{}
+	for _, id := range sessionIDs {
+		if rsvp := s.rsvpFor(id, userID); rsvp != nil {
+			rsvps[id] = *rsvp
+		}
+	}
+	return rsvps, nil
+}
+
+func (s rsvpService) GetWaitlist(sessionID uuid.UUID) ([]services.WaitlistEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := []services.WaitlistEntry{}
+	for _, w := range s.waitlists[sessionID] {
+		user := s.user(w.UserID)
+		entry := services.WaitlistEntry{Position: w.Position, UserID: w.UserID, OfferExpiresAt: w.OfferExpiresAt, User: user}
+		if user != nil {
+			entry.Name = user.Name
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// GetWaitlistEntry returns nil if the member isn't waiting, as the real
+// service does
+func (s rsvpService) GetWaitlistEntry(sessionID, userID uuid.UUID) (*models.WaitlistEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	waitlist := s.waitlists[sessionID]
+	if i := indexOf(waitlist, func(w models.WaitlistEntry) bool { return w.UserID == userID }); i >= 0 {
+		entry := waitlist[i]
+		return &entry, nil
+	}
+	return nil, nil
+}
+
+func (s rsvpService) LeaveWaitlist(sessionID, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rsvp := s.rsvpFor(sessionID, userID); rsvp == nil || rsvp.Status != models.RSVPStatusWaitlisted {
+		return services.ErrRSVPNotFound
+	}
+	s.setRSVP(sessionID, userID, models.RSVPStatusOut)
+	return nil
+}
+
+func (s rsvpService) ListGuests(sessionID uuid.UUID) ([]models.GuestRSVP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	guests := []models.GuestRSVP{}
+	for _, g := range s.guests[sessionID] {
+		g.Inviter = s.user(g.InvitedBy)
+		guests = append(guests, g)
+	}
+	return guests, nil
+}
+
+func (s rsvpService) ListRSVPRequests(sessionID uuid.UUID) ([]services.RSVPRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(sessionID) == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	requests := []services.RSVPRequest{}
+	for _, r := range s.rsvps[sessionID] {
+		if r.Status != models.RSVPStatusRequested {
+			continue
+		}
+		attended := 0
+		for _, records := range s.attendance {
+			attended += len(filter(records, func(a models.Attendance) bool {
+				return a.UserID == r.UserID && a.Status == models.AttendancePresent
+			}))
+		}
+		requests = append(requests, services.RSVPRequest{RSVP: s.rsvpView(r), RecentAttendance: attended})
+	}
+	return requests, nil
+}
+
+func (s rsvpService) RecordAttendance(sessionID, userID uuid.UUID, status models.AttendanceStatus, note string, recordedBy uuid.UUID) (*models.Attendance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(sessionID) == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	return s.recordAttendance(sessionID, userID, status, note, &recordedBy), nil
+}
+
+func (s rsvpService) RemoveGuest(sessionID, guestID uuid.UUID, _ *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	guests := s.guests[sessionID]
+	if indexOf(guests, func(g models.GuestRSVP) bool { return g.ID == guestID }) < 0 {
+		return services.ErrGuestNotFound
+	}
+	s.guests[sessionID] = filter(guests, func(g models.GuestRSVP) bool { return g.ID != guestID })
+	return nil
+}
+
+// Timeline counts the session's current INs by when they answered
+func (s rsvpService) Timeline(sessionID uuid.UUID) (*services.RSVPTimeline, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(sessionID)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	timeline := &services.RSVPTimeline{
+		SessionID: sessionID, StartsAt: session.StartsAt, MaxPlayers: session.MaxPlayers, Approximate: true,
+		Points: []services.RSVPTimelinePoint{},
+	}
+	in := 0
+	for _, r := range s.rsvps[sessionID] {
+		if r.Status != models.RSVPStatusIn {
+			continue
+		}
+		hour := r.RSVPTimestamp.Truncate(time.Hour)
+		if timeline.FirstRSVPAt == nil {
+			timeline.FirstRSVPAt = &hour
+		}
+		in++
+		if n := len(timeline.Points); n > 0 && timeline.Points[n-1].Hour.Equal(hour) {
+			timeline.Points[n-1].In, timeline.Points[n-1].Joined = in, timeline.Points[n-1].Joined+1
+		} else {
+			timeline.Points = append(timeline.Points, services.RSVPTimelinePoint{Hour: hour, In: in, Joined: 1})
+		}
+		if in == session.MaxPlayers {
+			timeline.FilledAt = &hour
+		}
+	}
+	return timeline, nil
+}
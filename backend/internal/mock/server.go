@@ -0,0 +1,409 @@
+// Package mock serves the API from in-memory fixtures, for developing the
+// frontend without Postgres or Auth0. It's the real router and handlers, so
+// every route, access check and response shape is the server's own, built
+// on stub services that read and change the fixtures instead of the
+// database. The stubs keep changes until the server stops but don't enforce
+// the business rules behind them (capacity, deadlines, who may do what
+// beyond the route's role checks), and nothing is pushed, emailed or run in
+// the background.
+//
+// Requests can pick who they're signed in as, and latency and errors can be
+// injected for all requests or one at a time (see Config and the X-Mock-*
+// headers).
+package mock
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/config"
+	"github.com/weekday-masters/backend/internal/dto"
+	"github.com/weekday-masters/backend/internal/handlers"
+	"github.com/weekday-masters/backend/internal/middleware"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+// Headers a request can set to steer the mock server
+const (
+	HeaderUser  = "X-Mock-User"  // admin (default), member, coach, pending, or a fixture user's ID
+	HeaderError = "X-Mock-Error" // answer with this status instead
+	HeaderDelay = "X-Mock-Delay" // wait this long first, as a Go duration; overrides Config.Latency
+)
+
+// BannedWords are rejected in comments and announcements, so the frontend
+// can show what happens
+var BannedWords = []string{"bananas"}
+
+// Config shapes the mock server's behaviour
+type Config struct {
+	Latency     time.Duration // added to every request
+	Jitter      time.Duration // up to this much more, at random
+	ErrorRate   float64       // share of requests, 0 to 1, answered with ErrorStatus
+	ErrorStatus int           // 500 if zero
+	Seed        int64         // seeds jitter and errors, so a run can be repeated
+	FrontendURL string        // base of share and invite links
+}
+
+// Server holds the fixtures the stub services share
+type Server struct {
+	cfg Config
+	rng *rand.Rand
+
+	// The real services that need no database
+	moderation *services.ModerationService
+	share      *services.ShareService
+	cards      *services.CardService
+	messages   *services.MessageCatalogService
+
+	mu          sync.Mutex
+	club        models.Club
+	users       []models.User
+	sessions    []models.Session
+	rsvps       map[uuid.UUID][]models.RSVP // by session, oldest first
+	guests      map[uuid.UUID][]models.GuestRSVP
+	waitlists   map[uuid.UUID][]models.WaitlistEntry // by session, in line
+	attendance  map[uuid.UUID][]models.Attendance
+	attachments map[uuid.UUID][]models.SessionAttachment
+	regulars    map[uuid.UUID][]models.SeriesRegular // by series
+	files       map[string][]byte                    // stored uploads, by key
+	issuedCards map[string]services.MembershipCard   // by token
+
+	proposals      []models.CancellationProposal
+	rollovers      []models.SeasonRollover
+	courts         []models.CourtAssignment
+	events         []models.DomainEvent // oldest first
+	apiUsage       []models.APIUsage
+	jobRuns        []models.JobRun
+	pendingActions []models.PendingAction
+
+	notifications []models.Notification
+	prefs         map[uuid.UUID]*models.UserNotificationPreferences // by member
+	announcements []models.Announcement                             // oldest first
+	acks          []models.AnnouncementAcknowledgement
+	templates     []models.MessageTemplate
+
+	avatarReports []models.AvatarReport
+	joinRules     models.JoinRules
+	referralCodes []models.ReferralCode
+	preapproved   []models.PreapprovedMember
+	invites       []models.Invite
+	inviteUses    []models.InviteUse
+	contacts      []models.ContactLog
+
+	games           []models.Game
+	ratings         []models.PeerRating
+	playerRatings   []models.PlayerRating
+	comments        []models.Comment
+	commentReports  []models.CommentReport
+	commentSettings []models.SessionCommentSetting
+	carpoolOffers   []models.CarpoolOffer
+	carpoolAsks     []models.CarpoolRequest
+
+	documents        []models.Document
+	documentAcks     []models.DocumentAcknowledgement
+	minutes          []models.CommitteeMinutes
+	incidents        []models.Incident
+	inventory        []models.InventoryItem
+	checkouts        []models.InventoryCheckout
+	movements        []models.InventoryMovement
+	tournaments      []models.Tournament
+	orderWindows     []models.OrderWindow
+	orders           []models.Order
+	progress         []models.TrainingProgress
+	assessments      []models.SkillAssessment
+	trainingAttended []models.TrainingAttendance
+}
+
+// New creates a mock server with freshly built fixtures
+func New(cfg Config) *Server {
+	if cfg.ErrorStatus == 0 {
+		cfg.ErrorStatus = http.StatusInternalServerError
+	}
+	s := &Server{
+		cfg:        cfg,
+		rng:        rand.New(rand.NewSource(cfg.Seed)),
+		moderation: services.NewModerationService(BannedWords, nil),
+		share:      services.NewShareService("mock-share-secret"),
+		cards:      services.NewCardService("mock-card-secret", 30*24*time.Hour),
+		messages:   services.NewMessageCatalogService(),
+	}
+	s.loadFixtures()
+	return s
+}
+
+// services are the stubs the API is built on
+func (s *Server) services() handlers.Services {
+	return handlers.Services{
+		Users:            userService{s},
+		AccountLinks:     accountLinkService{s},
+		JoinRules:        joinRuleService{s},
+		Invites:          inviteService{s},
+		Avatars:          avatarService{s},
+		Sessions:         sessionService{s},
+		RSVPs:            rsvpService{s},
+		PendingActions:   pendingActionService{s},
+		Club:             clubService{s},
+		Notifications:    notificationService{s},
+		Moderation:       moderationService{s},
+		Announcements:    announcementService{s},
+		Tournaments:      tournamentService{s},
+		Games:            gameService{s},
+		Courts:           courtService{s},
+		Reports:          reportService{s},
+		Stats:            statsService{s},
+		Comments:         commentService{s},
+		Sync:             syncService{s},
+		Documents:        documentService{s},
+		Incidents:        incidentService{s},
+		Widget:           widgetService{s},
+		Allocation:       allocationService{s},
+		Jobs:             jobService{s},
+		Scheduler:        schedulerService{s},
+		Events:           eventService{s},
+		Archive:          archiveService{s},
+		ClubExport:       clubExportService{s},
+		Inactivity:       inactivityService{s},
+		ContactLogs:      contactLogService{s},
+		Usage:            usageService{s},
+		Orders:           orderService{s},
+		Coaching:         coachingService{s},
+		Carpools:         carpoolService{s},
+		Inventory:        inventoryService{s},
+		Cards:            cardService{s},
+		Messages:         messageService{s},
+		Share:            shareService{s},
+		Search:           searchService{s},
+		WeatherProposals: weatherProposalService{s},
+		Committee:        committeeService{s},
+		InboundEmail:     inboundEmailService{s},
+	}
+}
+
+// Router returns the real API under /api and each pinned version, served
+// from the fixtures and open to any origin
+func (s *Server) Router() *gin.Engine {
+	dto.Attendees = attendeeLookup{s}
+
+	origin := s.cfg.FrontendURL
+	if origin == "" {
+		origin = "http://localhost:3000"
+	}
+	api := handlers.NewAPI(s.services(), handlers.APIConfig{
+		UserInfo:           s.userInfo,
+		FrontendURL:        s.cfg.FrontendURL,
+		AnnouncementLimit:  2,
+		AnnouncementWindow: 24 * time.Hour,
+		Live: config.NewLive(config.Reloadable{
+			SessionReminderHours24: 24,
+			SessionReminderHours12: 12,
+			DeadlineReminderHours:  6,
+			CORSOrigins:            []string{origin},
+			BannedWords:            BannedWords,
+		}),
+	}, handlers.Middleware{
+		Token:        s.token,
+		Authenticate: s.authenticate,
+
+		AdminIPAllowlist:         pass,
+		RecentMFA:                pass,
+		AuthCallbackLimit:        pass,
+		AuthCallbackAccountLimit: pass,
+		AccountLinkLimit:         pass,
+		AccountLinkAccountLimit:  pass,
+		InvitePreviewLimit:       pass,
+	})
+
+	r := gin.Default()
+	r.Use(cors.New(cors.Config{
+		AllowAllOrigins: true,
+		AllowMethods:    []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", middleware.APIVersionHeader,
+			HeaderUser, HeaderError, HeaderDelay},
+		ExposeHeaders: []string{"Content-Length", middleware.APIVersionHeader, "Deprecation", "Sunset", "Link"},
+	}))
+
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "mock": true})
+	})
+	api.RegisterPages(r)
+
+	for _, version := range middleware.SupportedAPIVersions {
+		versioned := r.Group("/api/" + version)
+		versioned.Use(middleware.APIVersion(version), s.inject)
+		api.Register(versioned)
+	}
+	unversioned := r.Group("/api")
+	unversioned.Use(middleware.NegotiateAPIVersion(), s.inject)
+	api.Register(unversioned)
+
+	r.NoRoute(func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found", "code": "not_found"})
+	})
+	return r
+}
+
+// pass stands in for the rate limits, admin IP allowlist and MFA check
+func pass(c *gin.Context) {
+	c.Next()
+}
+
+// inject delays the request and fails it, as configured or asked for by its
+// headers
+func (s *Server) inject(c *gin.Context) {
+	delay := s.cfg.Latency
+	if d := c.GetHeader(HeaderDelay); d != "" {
+		parsed, err := time.ParseDuration(d)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid " + HeaderDelay + "; use a duration like 500ms"})
+			return
+		}
+		delay = parsed
+	}
+
+	s.mu.Lock()
+	if s.cfg.Jitter > 0 {
+		delay += time.Duration(s.rng.Int63n(int64(s.cfg.Jitter)))
+	}
+	status := 0
+	if s.cfg.ErrorRate > 0 && s.rng.Float64() < s.cfg.ErrorRate {
+		status = s.cfg.ErrorStatus
+	}
+	s.mu.Unlock()
+
+	if e := c.GetHeader(HeaderError); e != "" {
+		parsed, err := strconv.Atoi(e)
+		if err != nil || parsed < 400 || parsed > 599 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid " + HeaderError + "; use a status from 400 to 599"})
+			return
+		}
+		status = parsed
+	}
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-c.Request.Context().Done():
+			c.Abort()
+			return
+		}
+	}
+	if status != 0 {
+		c.AbortWithStatusJSON(status, gin.H{"error": "Injected error", "code": "mock_injected"})
+		return
+	}
+	c.Next()
+}
+
+// token stands in for verifying an Auth0 login that may have no account
+// yet. X-Mock-User names a fixture user, or any other handle to sign up as
+// someone new.
+func (s *Server) token(c *gin.Context) {
+	who := c.GetHeader(HeaderUser)
+	if who == "" {
+		who = "admin"
+	}
+	auth0ID := "mock|" + who
+	s.mu.Lock()
+	if user := s.userByHandle(who); user != nil {
+		auth0ID = user.Auth0ID
+	}
+	s.mu.Unlock()
+
+	c.Set("auth0ID", auth0ID)
+	c.Set("accessToken", auth0ID)
+	c.Next()
+}
+
+// authenticate signs the request in as the fixture user its X-Mock-User
+// header names, the admin by default. Any bearer token, or none, is
+// accepted.
+func (s *Server) authenticate(c *gin.Context) {
+	who := c.GetHeader(HeaderUser)
+	if who == "" {
+		who = "admin"
+	}
+
+	s.mu.Lock()
+	user := s.userByHandle(who)
+	s.mu.Unlock()
+	if user == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unknown " + HeaderUser + " " + strconv.Quote(who)})
+		return
+	}
+	c.Set("user", user)
+	c.Set("userID", user.ID)
+	c.Set("auth0ID", user.Auth0ID)
+	c.Next()
+}
+
+// userInfo stands in for Auth0's profile of the login token signed in as
+func (s *Server) userInfo(_ context.Context, accessToken string) (*middleware.Auth0UserInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.Auth0ID == accessToken {
+			return &middleware.Auth0UserInfo{Sub: u.Auth0ID, Email: u.Email, EmailVerified: true, Name: u.Name}, nil
+		}
+	}
+	handle := strings.TrimPrefix(accessToken, "mock|")
+	return &middleware.Auth0UserInfo{Sub: accessToken, Email: handle + "@example.com", EmailVerified: true, Name: handle}, nil
+}
+
+// userByHandle returns a copy of the fixture user with handle or ID who.
+// The caller holds s.mu.
+func (s *Server) userByHandle(who string) *models.User {
+	id, err := uuid.Parse(who)
+	if err != nil {
+		id = fixtureID("user", who)
+	}
+	return s.user(id)
+}
+
+// user returns a copy of the member with id, or nil. The caller holds s.mu.
+func (s *Server) user(id uuid.UUID) *models.User {
+	if i := s.userIndex(id); i >= 0 {
+		user := s.users[i]
+		return &user
+	}
+	return nil
+}
+
+// userIndex is the index of the member with id in s.users, or -1
+func (s *Server) userIndex(id uuid.UUID) int {
+	return indexOf(s.users, func(u models.User) bool { return u.ID == id })
+}
+
+// indexOf returns the index of the first item match accepts, or -1
+func indexOf[T any](items []T, match func(T) bool) int {
+	for i := range items {
+		if match(items[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// filter returns the items keep accepts
+func filter[T any](items []T, keep func(T) bool) []T {
+	kept := []T{}
+	for _, item := range items {
+		if keep(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// domainError builds a coded error like the ones the services return
+func domainError(kind error, code, message string) *services.DomainError {
+	return &services.DomainError{Kind: kind, Code: code, Message: message}
+}
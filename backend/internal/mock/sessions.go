@@ -0,0 +1,643 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+	"github.com/weekday-masters/backend/internal/storage"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+// sessionIndex is the index of the session with id in s.sessions, or -1
+func (s *Server) sessionIndex(id uuid.UUID) int {
+	return indexOf(s.sessions, func(session models.Session) bool { return session.ID == id })
+}
+
+// sessionByID returns the session with id, or nil. The caller holds s.mu.
+func (s *Server) sessionByID(id uuid.UUID) *models.Session {
+	if i := s.sessionIndex(id); i >= 0 {
+		return &s.sessions[i]
+	}
+	return nil
+}
+
+// sessionView returns a copy of session with its RSVP counts, RSVPs,
+// creator, coach and attachments filled in, as the real services load them.
+// The caller holds s.mu.
+func (s *Server) sessionView(session *models.Session) *models.Session {
+	view := *session
+	view.RSVPCounts = models.RSVPCounts{GuestCount: len(s.guests[session.ID])}
+	view.RSVPs = nil
+	for _, r := range s.rsvps[session.ID] {
+		switch r.Status {
+		case models.RSVPStatusIn:
+			view.ConfirmedCount++
+		case models.RSVPStatusMaybe:
+			view.MaybeCount++
+		case models.RSVPStatusOut:
+			view.OutCount++
+		case models.RSVPStatusRequested:
+			view.RequestedCount++
+		case models.RSVPStatusWaitlisted:
+			view.WaitlistCount++
+		}
+		view.RSVPs = append(view.RSVPs, s.rsvpView(r))
+	}
+	view.Creator = s.user(session.CreatedBy)
+	if session.CoachID != nil {
+		view.Coach = s.user(*session.CoachID)
+	}
+	view.Attachments = s.attachments[session.ID]
+	return &view
+}
+
+// sessionViews returns views of the sessions keep accepts, soonest first.
+// The caller holds s.mu.
+func (s *Server) sessionViews(keep func(*models.Session) bool) []models.Session {
+	views := []models.Session{}
+	for i := range s.sessions {
+		if keep(&s.sessions[i]) {
+			views = append(views, *s.sessionView(&s.sessions[i]))
+		}
+	}
+	sort.SliceStable(views, func(i, j int) bool { return views[i].StartsAt.Before(views[j].StartsAt) })
+	return views
+}
+
+// rsvpView fills in an RSVP's member. The caller holds s.mu.
+func (s *Server) rsvpView(r models.RSVP) models.RSVP {
+	r.User = s.user(r.UserID)
+	return r
+}
+
+// page returns the limit items from offset
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// inRange reports whether date falls within [from, to]; either may be nil
+func inRange(date time.Time, from, to *time.Time) bool {
+	return (from == nil || !date.Before(*from)) && (to == nil || !date.After(*to))
+}
+
+// newSession lays out a session on date from start to end, with RSVPs
+// closing rsvpDeadlineBefore it starts
+func newSession(title string, date time.Time, start, end utils.Clock, courts int, createdBy uuid.UUID) models.Session {
+	now := time.Now()
+	startsAt := start.On(date)
+	return models.Session{
+		ID:           uuid.New(),
+		Title:        title,
+		SessionDate:  utils.StartOfDay(startsAt),
+		StartsAt:     startsAt,
+		EndsAt:       end.On(date),
+		Courts:       courts,
+		MaxPlayers:   models.MaxPlayersForCourts(courts),
+		RSVPDeadline: startsAt.Add(-rsvpDeadlineBefore),
+		Status:       models.SessionStatusOpen,
+		SessionType:  models.SessionTypeSocial,
+		CreatedBy:    createdBy,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// generateSeries adds the weeks of each recurring series up to the club's
+// look-ahead, with the series' regulars IN, and returns how many it added.
+// The caller holds s.mu.
+func (s *Server) generateSeries() int {
+	added := 0
+	for i := range s.sessions {
+		parent := s.sessions[i]
+		if !parent.IsRecurring || parent.RecurringParentID != nil || parent.Status == models.SessionStatusCancelled {
+			continue
+		}
+		for week := 1; week < s.club.RecurringWeeksAhead; week++ {
+			id := fixtureID("session", fmt.Sprintf("%s/%d", parent.ID, week))
+			if s.sessionIndex(id) >= 0 {
+				continue
+			}
+			child := parent
+			child.ID = id
+			child.RecurringParentID = &parent.ID
+			child.SessionDate = parent.SessionDate.AddDate(0, 0, 7*week)
+			child.StartsAt = parent.StartsAt.AddDate(0, 0, 7*week)
+			child.EndsAt = parent.EndsAt.AddDate(0, 0, 7*week)
+			child.RSVPDeadline = parent.RSVPDeadline.AddDate(0, 0, 7*week)
+			s.sessions = append(s.sessions, child)
+			for _, regular := range s.regulars[parent.ID] {
+				s.rsvps[id] = append(s.rsvps[id], models.RSVP{
+					ID: uuid.New(), SessionID: id, UserID: regular.UserID, Status: models.RSVPStatusIn, AsRegular: true,
+					RSVPTimestamp: child.CreatedAt, CreatedAt: child.CreatedAt, UpdatedAt: child.CreatedAt,
+				})
+			}
+			added++
+		}
+	}
+	return added
+}
+
+// deleteSession removes a session and everything hung off it. The caller
+// holds s.mu.
+func (s *Server) deleteSession(id uuid.UUID) {
+	if i := s.sessionIndex(id); i >= 0 {
+		s.sessions = append(s.sessions[:i], s.sessions[i+1:]...)
+	}
+	delete(s.rsvps, id)
+	delete(s.guests, id)
+	delete(s.waitlists, id)
+	delete(s.attendance, id)
+	delete(s.attachments, id)
+}
+
+// sessionService stands in for services.SessionService
+type sessionService struct{ *Server }
+
+func (s sessionService) AddAttachment(_ context.Context, sessionID uuid.UUID, fileName string, size int64, r io.Reader, uploadedBy uuid.UUID) (*models.SessionAttachment, error) {
+	upload, data, err := readUpload(storage.SessionAttachments, size, r)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(sessionID) == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	attachment := models.SessionAttachment{
+		ID: uuid.New(), SessionID: sessionID, FileName: fileName, ContentType: upload.ContentType,
+		SizeBytes: int64(len(data)), UploadedBy: uploadedBy, CreatedAt: time.Now(),
+	}
+	attachment.StorageKey = "sessions/" + sessionID.String() + "/" + attachment.ID.String() + upload.Extension
+	s.files[attachment.StorageKey] = data
+	s.attachments[sessionID] = append(s.attachments[sessionID], attachment)
+	return &attachment, nil
+}
+
+func (s sessionService) CancelSession(id uuid.UUID, reason string) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(id)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	session.Status, session.CancellationReason, session.UpdatedAt = models.SessionStatusCancelled, reason, time.Now()
+	return s.sessionView(session), nil
+}
+
+func (s sessionService) CreateSession(input services.CreateSessionInput) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := newSession(input.Title, input.SessionDate, input.StartTime, input.EndTime, input.Courts, input.CreatedBy)
+	session.Description = input.Description
+	session.IsOutdoor = input.IsOutdoor
+	session.RequiresApproval = input.RequiresApproval
+	session.FairShare = input.FairShare
+	session.AllowGuests = input.AllowGuests
+	session.IsRecurring = input.IsRecurring
+	session.RecurringDayOfWeek = input.RecurringDayOfWeek
+	session.Overbooking = input.Overbooking
+	if input.SessionType != "" {
+		session.SessionType = input.SessionType
+	}
+	session.CoachID = input.CoachID
+	session.CurriculumNotes = input.CurriculumNotes
+	if input.Spots != nil {
+		session.MaxPlayers = *input.Spots
+	}
+	s.sessions = append(s.sessions, session)
+	s.generateSeries()
+	return s.sessionView(&s.sessions[s.sessionIndex(session.ID)]), nil
+}
+
+func (s sessionService) DeleteAttachment(sessionID, attachmentID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attachments := s.attachments[sessionID]
+	i := indexOf(attachments, func(a models.SessionAttachment) bool { return a.ID == attachmentID })
+	if i < 0 {
+		return services.ErrSessionAttachmentNotFound
+	}
+	delete(s.files, attachments[i].StorageKey)
+	s.attachments[sessionID] = append(attachments[:i], attachments[i+1:]...)
+	return nil
+}
+
+func (s sessionService) DeleteSession(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionByID(id) == nil {
+		return services.ErrSessionNotFound
+	}
+	s.deleteSession(id)
+	return nil
+}
+
+func (s sessionService) ExtendDeadline(id uuid.UUID, deadline time.Time, _ uuid.UUID, _ string) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(id)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	session.RSVPDeadline, session.UpdatedAt = deadline, time.Now()
+	return s.sessionView(session), nil
+}
+
+func (s sessionService) GetAdminNotes(id uuid.UUID) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(id)
+	if session == nil {
+		return "", services.ErrSessionNotFound
+	}
+	return session.AdminNotes, nil
+}
+
+func (s sessionService) GetSessionByID(id uuid.UUID) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(id)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	return s.sessionView(session), nil
+}
+
+func (s sessionService) ListCancelledUpcomingSessions() ([]models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	return s.sessionViews(func(session *models.Session) bool {
+		return session.Status == models.SessionStatusCancelled && session.StartsAt.After(now)
+	}), nil
+}
+
+func (s sessionService) ListPastSessions(from, to *time.Time, limit, offset int) ([]models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	today := utils.StartOfDay(utils.NowInSydney())
+	past := s.sessionViews(func(session *models.Session) bool {
+		return session.SessionDate.Before(today) && inRange(session.SessionDate, from, to)
+	})
+	reverse(past)
+	return page(past, limit, offset), nil
+}
+
+func (s sessionService) ListPlayedSessions(userID uuid.UUID, from, to *time.Time, limit, offset int) ([]services.PlayedSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	played := []services.PlayedSession{}
+	for _, session := range s.sessionViews(func(session *models.Session) bool {
+		return session.EndsAt.Before(now) && session.Status != models.SessionStatusCancelled && inRange(session.SessionDate, from, to)
+	}) {
+		entry := services.PlayedSession{Session: session}
+		for _, r := range s.rsvps[session.ID] {
+			if r.UserID == userID && r.Status == models.RSVPStatusIn {
+				rsvp := r
+				entry.RSVP = &rsvp
+			}
+		}
+		for _, a := range s.attendance[session.ID] {
+			if a.UserID == userID {
+				record := a
+				entry.Attendance = &record
+			}
+		}
+		if entry.Attendance != nil && entry.Attendance.Status == models.AttendancePresent ||
+			entry.RSVP != nil && len(s.attendance[session.ID]) == 0 {
+			played = append(played, entry)
+		}
+	}
+	reverse(played)
+	return page(played, limit, offset), nil
+}
+
+// seriesOf is the parent session of the series sessionID belongs to. The
+// caller holds s.mu.
+func (s *Server) seriesOf(sessionID uuid.UUID) (*models.Session, error) {
+	session := s.sessionByID(sessionID)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	if session.RecurringParentID != nil {
+		if parent := s.sessionByID(*session.RecurringParentID); parent != nil {
+			return parent, nil
+		}
+	}
+	return session, nil
+}
+
+func (s sessionService) ListRegulars(sessionID uuid.UUID) ([]models.SeriesRegular, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	series, err := s.seriesOf(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	regulars := []models.SeriesRegular{}
+	for _, r := range s.regulars[series.ID] {
+		r.User = s.user(r.UserID)
+		regulars = append(regulars, r)
+	}
+	return regulars, nil
+}
+
+func (s sessionService) ListRollovers() ([]models.SeasonRollover, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rollovers := []models.SeasonRollover{}
+	for i := len(s.rollovers) - 1; i >= 0; i-- {
+		r := s.rollovers[i]
+		r.Creator = s.user(r.CreatedBy)
+		rollovers = append(rollovers, r)
+	}
+	return rollovers, nil
+}
+
+func (s sessionService) ListSessionHistory(from, to *time.Time, limit, offset int) (*services.SessionHistory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	ended := s.sessionViews(func(session *models.Session) bool {
+		return !session.EndsAt.After(now) && inRange(session.SessionDate, from, to)
+	})
+	reverse(ended)
+	history := &services.SessionHistory{Sessions: page(ended, limit, offset), Attendance: map[uuid.UUID][]models.Attendance{}}
+	for _, session := range history.Sessions {
+		history.Attendance[session.ID] = s.attendanceView(session.ID)
+	}
+	return history, nil
+}
+
+func (s sessionService) ListSessions(filter services.SessionListFilter) ([]models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	from := filter.From
+	if from == nil && !filter.IncludePast {
+		today := utils.StartOfDay(utils.NowInSydney())
+		from = &today
+	}
+	return s.sessionViews(func(session *models.Session) bool {
+		return session.Status != models.SessionStatusCancelled && inRange(session.SessionDate, from, filter.To)
+	}), nil
+}
+
+func (s sessionService) MergeSessions(targetID, sourceID uuid.UUID, _ services.RSVPConflict, _ uuid.UUID) (*services.MergeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	target, source := s.sessionByID(targetID), s.sessionByID(sourceID)
+	if target == nil || source == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	result := &services.MergeResult{MergedRSVPs: []services.MergedRSVP{}}
+	for _, r := range s.rsvps[sourceID] {
+		if indexOf(s.rsvps[targetID], func(t models.RSVP) bool { return t.UserID == r.UserID }) >= 0 {
+			continue
+		}
+		r.SessionID = targetID
+		s.rsvps[targetID] = append(s.rsvps[targetID], r)
+		result.MovedRSVPs++
+	}
+	delete(s.rsvps, sourceID)
+	source.Status, source.CancellationReason = models.SessionStatusCancelled, "Merged into "+target.Title
+	result.Session = s.sessionView(target)
+	return result, nil
+}
+
+func (s sessionService) OpenAttachment(_ context.Context, sessionID, attachmentID uuid.UUID) (*models.SessionAttachment, io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.attachments[sessionID], func(a models.SessionAttachment) bool { return a.ID == attachmentID })
+	if i < 0 {
+		return nil, nil, services.ErrSessionAttachmentNotFound
+	}
+	attachment := s.attachments[sessionID][i]
+	return &attachment, s.openFile(attachment.StorageKey), nil
+}
+
+func (s sessionService) PreviewRecurrence(input services.RecurrencePreviewInput) (*services.RecurrencePreview, error) {
+	s.mu.Lock()
+	weeks := s.club.RecurringWeeksAhead
+	s.mu.Unlock()
+	if input.Occurrences != nil {
+		weeks = *input.Occurrences
+	}
+	preview := &services.RecurrencePreview{Occurrences: []services.RecurrenceOccurrence{}, Continues: input.Occurrences == nil, Warnings: []string{}}
+	for week := 0; week < weeks; week++ {
+		date := input.SessionDate.AddDate(0, 0, 7*week)
+		startsAt := input.StartTime.On(date)
+		preview.Occurrences = append(preview.Occurrences, services.RecurrenceOccurrence{
+			SessionDate: date.Format("2006-01-02"), Title: input.Title, StartsAt: startsAt, EndsAt: input.EndTime.On(date),
+			RSVPDeadline: startsAt.Add(-rsvpDeadlineBefore),
+		})
+		preview.Created++
+		preview.Until = date.Format("2006-01-02")
+	}
+	return preview, nil
+}
+
+// planRollover schedules each week of the chosen series within the season.
+// The caller holds s.mu.
+func (s *Server) planRollover(input services.RolloverInput) *services.RolloverPlan {
+	plan := &services.RolloverPlan{Sessions: []services.PlannedSession{}, Skipped: []services.SkippedDate{}}
+	for i := range s.sessions {
+		template := s.sessions[i]
+		chosen := indexOf(input.TemplateIDs, func(id uuid.UUID) bool { return id == template.ID }) >= 0
+		if len(input.TemplateIDs) > 0 && !chosen || len(input.TemplateIDs) == 0 && (!template.IsRecurring || template.RecurringParentID != nil) {
+			continue
+		}
+		plan.Templates++
+		start, end := utils.ClockOf(template.StartsAt), utils.ClockOf(template.EndsAt)
+		for date := input.StartDate; !date.After(input.EndDate); date = date.AddDate(0, 0, 1) {
+			if date.Weekday() != template.SessionDate.Weekday() {
+				continue
+			}
+			day := date.Format("2006-01-02")
+			if b := indexOf(input.Blackouts, func(b services.Blackout) bool { return b.Date.Format("2006-01-02") == day }); b >= 0 {
+				plan.Skipped = append(plan.Skipped, services.SkippedDate{
+					TemplateID: template.ID, Template: template.Title, SessionDate: day, Reason: "blackout", Detail: input.Blackouts[b].Reason,
+				})
+				continue
+			}
+			plan.Sessions = append(plan.Sessions, services.PlannedSession{
+				TemplateID: template.ID, Template: template.Title, Title: template.Title, SessionDate: day,
+				StartsAt: start.On(date), EndsAt: end.On(date),
+			})
+		}
+	}
+	return plan
+}
+
+func (s sessionService) PreviewRollover(input services.RolloverInput) (*services.RolloverPlan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.planRollover(input), nil
+}
+
+func (s sessionService) RecordUsage(id uuid.UUID, input services.SessionUsageInput) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(id)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	session.ShuttlesUsed, session.ActualStartAt, session.ActualEndAt = input.ShuttlesUsed, input.ActualStartAt, input.ActualEndAt
+	return s.sessionView(session), nil
+}
+
+func (s sessionService) RefreshRecurringSessions() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generateSeries()
+	return nil
+}
+
+func (s sessionService) RollOverSeason(input services.RolloverInput, createdBy uuid.UUID) (*models.SeasonRollover, *services.RolloverPlan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	plan := s.planRollover(input)
+	rollover := models.SeasonRollover{
+		ID: uuid.New(), Name: input.Name, StartDate: input.StartDate, EndDate: input.EndDate,
+		SessionsCreated: len(plan.Sessions), CreatedBy: createdBy, CreatedAt: time.Now(),
+	}
+	for i, planned := range plan.Sessions {
+		session := *s.sessionByID(planned.TemplateID)
+		session.ID = uuid.New()
+		session.IsRecurring, session.RecurringParentID, session.SeasonRolloverID = false, nil, &rollover.ID
+		session.SessionDate = utils.StartOfDay(planned.StartsAt)
+		session.StartsAt, session.EndsAt = planned.StartsAt, planned.EndsAt
+		session.RSVPDeadline = planned.StartsAt.Add(-rsvpDeadlineBefore)
+		session.Status = models.SessionStatusOpen
+		s.sessions = append(s.sessions, session)
+		plan.Sessions[i].SessionID = &session.ID
+	}
+	s.rollovers = append(s.rollovers, rollover)
+	return &rollover, plan, nil
+}
+
+func (s sessionService) SetRegulars(sessionID uuid.UUID, userIDs []uuid.UUID, adminID uuid.UUID) (*services.RegularsUpdate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	series, err := s.seriesOf(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	update := &services.RegularsUpdate{Regulars: []models.SeriesRegular{}}
+	for _, userID := range userIDs {
+		regular := models.SeriesRegular{ID: uuid.New(), SeriesID: series.ID, UserID: userID, AddedBy: adminID, CreatedAt: time.Now()}
+		if i := indexOf(s.regulars[series.ID], func(r models.SeriesRegular) bool { return r.UserID == userID }); i >= 0 {
+			regular = s.regulars[series.ID][i]
+		}
+		update.Regulars = append(update.Regulars, regular)
+	}
+	s.regulars[series.ID] = update.Regulars
+	for i := range update.Regulars {
+		update.Regulars[i].User = s.user(update.Regulars[i].UserID)
+	}
+	return update, nil
+}
+
+func (s sessionService) TrimRecurringSessions() (int, error) {
+	return 0, nil
+}
+
+func (s sessionService) UndoRollover(id, actorID uuid.UUID) (*services.RolloverUndo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := indexOf(s.rollovers, func(r models.SeasonRollover) bool { return r.ID == id })
+	if i < 0 {
+		return nil, services.ErrRolloverNotFound
+	}
+	undo := &services.RolloverUndo{Kept: []models.Session{}}
+	for _, session := range s.sessionViews(func(session *models.Session) bool {
+		return session.SeasonRolloverID != nil && *session.SeasonRolloverID == id
+	}) {
+		if len(session.RSVPs) > 0 {
+			undo.Kept = append(undo.Kept, session)
+			continue
+		}
+		s.deleteSession(session.ID)
+		undo.Deleted++
+	}
+	now := time.Now()
+	s.rollovers[i].UndoneAt, s.rollovers[i].UndoneBy = &now, &actorID
+	return undo, nil
+}
+
+func (s sessionService) UpdateAdminNotes(id uuid.UUID, notes string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(id)
+	if session == nil {
+		return services.ErrSessionNotFound
+	}
+	session.AdminNotes = notes
+	return nil
+}
+
+func (s sessionService) UpdateSession(id uuid.UUID, input services.UpdateSessionInput) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessionByID(id)
+	if session == nil {
+		return nil, services.ErrSessionNotFound
+	}
+	set(&session.Title, input.Title)
+	set(&session.Description, input.Description)
+	set(&session.Courts, input.Courts)
+	set(&session.IsOutdoor, input.IsOutdoor)
+	set(&session.RequiresApproval, input.RequiresApproval)
+	set(&session.FairShare, input.FairShare)
+	set(&session.AllowGuests, input.AllowGuests)
+	set(&session.Status, input.Status)
+	set(&session.SessionType, input.SessionType)
+	set(&session.CurriculumNotes, input.CurriculumNotes)
+	set(&session.Overbooking, input.Overbooking)
+	if input.Courts != nil {
+		session.MaxPlayers = models.MaxPlayersForCourts(*input.Courts)
+	}
+	set(&session.MaxPlayers, input.Spots)
+	if input.CoachID != nil {
+		session.CoachID = input.CoachID
+	}
+	date := session.SessionDate
+	set(&date, input.SessionDate)
+	start, end := utils.ClockOf(session.StartsAt), utils.ClockOf(session.EndsAt)
+	set(&start, input.StartTime)
+	set(&end, input.EndTime)
+	if input.SessionDate != nil || input.StartTime != nil || input.EndTime != nil {
+		session.StartsAt, session.EndsAt = start.On(date), end.On(date)
+		session.SessionDate = utils.StartOfDay(session.StartsAt)
+		session.RSVPDeadline = session.StartsAt.Add(-rsvpDeadlineBefore)
+	}
+	session.UpdatedAt = time.Now()
+	return s.sessionView(session), nil
+}
+
+// set copies an optional update into field
+func set[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+// reverse reverses items in place
+func reverse[T any](items []T) {
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+}
@@ -0,0 +1,258 @@
+package mock
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/services"
+)
+
+// coachingService stands in for services.CoachingService
+type coachingService struct{ *Server }
+
+var errPlayerNotFound = domainError(services.ErrNotFound, "player_not_found", "player not found")
+
+// coachedSession returns a training session coach runs, or any training
+// session for admins. The caller holds s.mu.
+func (s *Server) coachedSession(sessionID uuid.UUID, coach *models.User) (*models.Session, error) {
+	session := s.sessionByID(sessionID)
+	switch {
+	case session == nil:
+		return nil, services.ErrSessionNotFound
+	case session.SessionType != models.SessionTypeTraining:
+		return nil, services.ErrNotTrainingSession
+	case !coach.IsAdmin() && (session.CoachID == nil || *session.CoachID != coach.ID):
+		return nil, services.ErrNotYourSession
+	}
+	return session, nil
+}
+
+// progressRecord returns a player's progress record, starting one if they
+// don't have one yet. The caller holds s.mu.
+func (s *Server) progressRecord(userID uuid.UUID) *models.TrainingProgress {
+	if i := indexOf(s.progress, func(p models.TrainingProgress) bool { return p.UserID == userID }); i >= 0 {
+		return &s.progress[i]
+	}
+	now := time.Now()
+	s.progress = append(s.progress, models.TrainingProgress{ID: uuid.New(), UserID: userID, CreatedAt: now, UpdatedAt: now})
+	return &s.progress[len(s.progress)-1]
+}
+
+// trainingAttendance returns who attended a training session, by member.
+// The caller holds s.mu.
+func (s *Server) trainingAttendance(sessionID uuid.UUID) map[uuid.UUID]models.TrainingAttendance {
+	attended := map[uuid.UUID]models.TrainingAttendance{}
+	for _, a := range s.trainingAttended {
+		if a.SessionID == sessionID {
+			attended[a.UserID] = a
+		}
+	}
+	return attended
+}
+
+func (s coachingService) ListTrainingSessions(coach *models.User) ([]models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	since := time.Now().Add(-14 * 24 * time.Hour)
+	return s.sessionViews(func(session *models.Session) bool {
+		return session.SessionType == models.SessionTypeTraining && !session.StartsAt.Before(since) &&
+			(coach.IsAdmin() || (session.CoachID != nil && *session.CoachID == coach.ID))
+	}), nil
+}
+
+func (s coachingService) UpdateCurriculum(sessionID uuid.UUID, coach *models.User, notes string) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, err := s.coachedSession(sessionID, coach)
+	if err != nil {
+		return nil, err
+	}
+	session.CurriculumNotes, session.UpdatedAt = notes, time.Now()
+	updated := *session
+	return &updated, nil
+}
+
+func (s coachingService) Roster(sessionID uuid.UUID, coach *models.User) ([]services.TrainingRosterEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.coachedSession(sessionID, coach); err != nil {
+		return nil, err
+	}
+	attended := s.trainingAttendance(sessionID)
+	roster := []services.TrainingRosterEntry{}
+	add := func(userID uuid.UUID, status *models.RSVPStatus, waitlisted bool) {
+		user := s.user(userID)
+		if user == nil {
+			return
+		}
+		a, ok := attended[userID]
+		roster = append(roster, services.TrainingRosterEntry{
+			User: *user, RSVPStatus: status, Waitlisted: waitlisted, Attended: ok, FromCheckIn: ok && a.RecordedBy == nil,
+		})
+		delete(attended, userID)
+	}
+	for _, r := range s.rsvps[sessionID] {
+		if r.Status == models.RSVPStatusIn {
+			status := r.Status
+			add(r.UserID, &status, false)
+		}
+	}
+	for _, entry := range s.waitlists[sessionID] {
+		status := models.RSVPStatusWaitlisted
+		add(entry.UserID, &status, true)
+	}
+
+	// Walk-ins, by name
+	walkIns := s.usersWhere(func(u models.User) bool { _, ok := attended[u.ID]; return ok })
+	sort.SliceStable(walkIns, func(i, j int) bool { return walkIns[i].Name < walkIns[j].Name })
+	for _, user := range walkIns {
+		add(user.ID, nil, false)
+	}
+	return roster, nil
+}
+
+func (s coachingService) RecordAttendance(sessionID uuid.UUID, coach *models.User, userIDs []uuid.UUID, attended bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.coachedSession(sessionID, coach); err != nil {
+		return err
+	}
+	marked := s.trainingAttendance(sessionID)
+	for _, userID := range userIDs {
+		if _, ok := marked[userID]; ok || !attended {
+			continue
+		}
+		if user := s.user(userID); user == nil || !user.IsApproved() {
+			return errors.New("attendance can only be recorded for approved members")
+		}
+		recordedBy := coach.ID
+		s.trainingAttended = append(s.trainingAttended, models.TrainingAttendance{
+			ID: uuid.New(), ProgressID: s.progressRecord(userID).ID, SessionID: sessionID, UserID: userID,
+			RecordedBy: &recordedBy, CreatedAt: time.Now(),
+		})
+		marked[userID] = s.trainingAttended[len(s.trainingAttended)-1]
+	}
+	if !attended {
+		s.trainingAttended = filter(s.trainingAttended, func(a models.TrainingAttendance) bool {
+			return a.SessionID != sessionID || indexOf(userIDs, func(id uuid.UUID) bool { return id == a.UserID }) < 0
+		})
+	}
+	return nil
+}
+
+func (s coachingService) RecordAssessment(coach *models.User, userID uuid.UUID, input services.SkillAssessmentInput) (*models.SkillAssessment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.user(userID) == nil {
+		return nil, errPlayerNotFound
+	}
+	skill := strings.ToLower(strings.Join(strings.Fields(input.Skill), " "))
+	if skill == "" {
+		return nil, errors.New("skill is required")
+	}
+	now := time.Now()
+	assessedAt := now
+	if input.AssessedAt != nil {
+		assessedAt = *input.AssessedAt
+	}
+	s.assessments = append(s.assessments, models.SkillAssessment{
+		ID: uuid.New(), ProgressID: s.progressRecord(userID).ID, UserID: userID, Skill: skill, Score: input.Score,
+		Notes: strings.TrimSpace(input.Notes), SessionID: input.SessionID, AssessedBy: coach.ID, AssessedAt: assessedAt, CreatedAt: now,
+	})
+	assessment := s.assessments[len(s.assessments)-1]
+	return &assessment, nil
+}
+
+func (s coachingService) DeleteAssessment(id uuid.UUID, _ *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if indexOf(s.assessments, func(a models.SkillAssessment) bool { return a.ID == id }) < 0 {
+		return domainError(services.ErrNotFound, "assessment_not_found", "assessment not found")
+	}
+	s.assessments = filter(s.assessments, func(a models.SkillAssessment) bool { return a.ID != id })
+	return nil
+}
+
+func (s coachingService) UpdateGoals(userID uuid.UUID, goals string) (*models.TrainingProgress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.user(userID) == nil {
+		return nil, errPlayerNotFound
+	}
+	progress := s.progressRecord(userID)
+	progress.Goals, progress.UpdatedAt = strings.TrimSpace(goals), time.Now()
+	updated := *progress
+	return &updated, nil
+}
+
+func (s coachingService) Progress(userID uuid.UUID) (*services.TrainingProgressReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report := &services.TrainingProgressReport{UserID: userID, Attendance: []services.TrainingSessionAttended{}, Skills: []services.SkillProgress{}}
+	i := indexOf(s.progress, func(p models.TrainingProgress) bool { return p.UserID == userID })
+	if i < 0 {
+		return report, nil
+	}
+	report.Goals = s.progress[i].Goals
+	for _, a := range s.trainingAttended {
+		if session := s.sessionByID(a.SessionID); a.UserID == userID && session != nil {
+			report.Attendance = append(report.Attendance, services.TrainingSessionAttended{
+				SessionID: a.SessionID, Title: session.Title, StartsAt: session.StartsAt, FromCheckIn: a.RecordedBy == nil,
+			})
+		}
+	}
+	sort.SliceStable(report.Attendance, func(i, j int) bool { return report.Attendance[i].StartsAt.After(report.Attendance[j].StartsAt) })
+	report.SessionsAttended = len(report.Attendance)
+	if len(report.Attendance) > 0 {
+		report.LastAttendedAt = &report.Attendance[0].StartsAt
+	}
+
+	assessments := filter(s.assessments, func(a models.SkillAssessment) bool { return a.UserID == userID })
+	sort.SliceStable(assessments, func(i, j int) bool { return assessments[i].AssessedAt.Before(assessments[j].AssessedAt) })
+	bySkill := map[string][]models.SkillAssessment{}
+	for _, a := range assessments {
+		bySkill[a.Skill] = append(bySkill[a.Skill], a)
+	}
+	for skill, history := range bySkill {
+		report.Skills = append(report.Skills, services.SkillProgress{
+			Skill: skill, FirstScore: history[0].Score, LatestScore: history[len(history)-1].Score, Assessments: history,
+		})
+	}
+	sort.Slice(report.Skills, func(i, j int) bool { return report.Skills[i].Skill < report.Skills[j].Skill })
+	return report, nil
+}
+
+func (s coachingService) ListTrainees() ([]services.Trainee, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	trainees := []services.Trainee{}
+	for _, p := range s.progress {
+		user := s.user(p.UserID)
+		if user == nil || !user.IsApproved() {
+			continue
+		}
+		trainee := services.Trainee{User: *user}
+		for _, a := range s.trainingAttended {
+			if session := s.sessionByID(a.SessionID); a.UserID == p.UserID && session != nil {
+				trainee.SessionsAttended++
+				if trainee.LastAttendedAt == nil || session.StartsAt.After(*trainee.LastAttendedAt) {
+					startsAt := session.StartsAt
+					trainee.LastAttendedAt = &startsAt
+				}
+			}
+		}
+		for _, a := range s.assessments {
+			if a.UserID == p.UserID && (trainee.LastAssessedAt == nil || a.AssessedAt.After(*trainee.LastAssessedAt)) {
+				assessedAt := a.AssessedAt
+				trainee.LastAssessedAt = &assessedAt
+			}
+		}
+		trainees = append(trainees, trainee)
+	}
+	sort.SliceStable(trainees, func(i, j int) bool { return trainees[i].User.Name < trainees[j].User.Name })
+	return trainees, nil
+}
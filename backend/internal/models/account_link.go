@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccountLinkCode is a one-time code emailed to an existing member so a new
+// login identity (say, email login after Google) can claim their account
+type AccountLinkCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"` // the account being claimed
+	Auth0ID   string     `gorm:"size:255;not null;index" json:"auth0_id"` // the identity claiming it
+	CodeHash  string     `gorm:"size:64;not null" json:"-"`               // SHA-256 of the code, never the code itself
+	Attempts  int        `gorm:"not null;default:0" json:"attempts"`      // wrong guesses so far
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (a *AccountLinkCode) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AllocationAlgorithm chooses who gets spots in a fair-share session
+type AllocationAlgorithm string
+
+const (
+	// AllocationFewestRecent picks members who've played least lately, earliest request first on ties
+	AllocationFewestRecent AllocationAlgorithm = "fewest_recent"
+	// AllocationWeightedLottery draws at random, weighting members who've played less lately
+	AllocationWeightedLottery AllocationAlgorithm = "weighted_lottery"
+	// AllocationFirstCome picks the earliest requests, as if the session weren't fair-share
+	AllocationFirstCome AllocationAlgorithm = "first_come"
+)
+
+// AllocationResult records how one request fared when a fair-share session's
+// spots were allocated, so the outcome can be checked afterwards
+type AllocationResult struct {
+	ID               uuid.UUID           `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID        uuid.UUID           `gorm:"type:uuid;not null;index" json:"session_id"`
+	UserID           uuid.UUID           `gorm:"type:uuid;not null" json:"user_id"`
+	Algorithm        AllocationAlgorithm `gorm:"size:50;not null" json:"algorithm"`
+	RecentAttendance int                 `gorm:"not null" json:"recent_attendance"`
+	Score            float64             `json:"score"` // algorithm-specific; higher ranks first
+	Rank             int                 `gorm:"not null" json:"rank"`
+	Selected         bool                `gorm:"not null" json:"selected"`
+	CreatedAt        time.Time           `json:"created_at"`
+
+	// Associations
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (a *AllocationResult) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
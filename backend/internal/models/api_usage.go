@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIUsage is how many API requests a member made on one day (Sydney time)
+// and when they last made one. Counts are kept in memory and added here
+// every minute, so this lags the app a little.
+type APIUsage struct {
+	UserID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Day        time.Time `gorm:"type:date;primaryKey;index" json:"day"`
+	Requests   int       `gorm:"not null;default:0" json:"requests"`
+	LastSeenAt time.Time `gorm:"not null" json:"last_seen_at"`
+}
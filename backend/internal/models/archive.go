@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchivedNotification is a notification the archive job has moved out of
+// the notifications table. Its columns mirror Notification's; a column added
+// there must be added here too or it's dropped on archiving.
+type ArchivedNotification struct {
+	ID               uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID           uuid.UUID        `gorm:"type:uuid;not null;index" json:"user_id"`
+	NotificationType NotificationType `gorm:"type:text;not null" json:"notification_type"`
+	Title            string           `gorm:"type:text;not null" json:"title"`
+	Body             string           `gorm:"type:text;not null" json:"body"`
+	Data             NotificationData `gorm:"type:jsonb" json:"data,omitempty"`
+
+	PushSent    bool       `json:"push_sent"`
+	PushSentAt  *time.Time `json:"push_sent_at,omitempty"`
+	PushError   string     `gorm:"type:text" json:"push_error,omitempty"`
+	EmailSent   bool       `json:"email_sent"`
+	EmailSentAt *time.Time `json:"email_sent_at,omitempty"`
+	EmailError  string     `gorm:"type:text" json:"email_error,omitempty"`
+	SMSSent     bool       `json:"sms_sent"`
+	SMSSentAt   *time.Time `json:"sms_sent_at,omitempty"`
+	SMSError    string     `gorm:"type:text" json:"sms_error,omitempty"`
+	Urgent      bool       `json:"urgent"`
+
+	EmailQueuedUntil *time.Time `json:"email_queued_until,omitempty"`
+
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"index" json:"created_at"`
+	ArchivedAt time.Time  `gorm:"not null" json:"archived_at"`
+}
+
+func (ArchivedNotification) TableName() string {
+	return "notifications_archive"
+}
+
+// ArchivedRSVP is an RSVP to a long-past session that the archive job has
+// moved out of the rsvps table. Its columns mirror RSVP's.
+type ArchivedRSVP struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	SessionID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"session_id"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Status        RSVPStatus `gorm:"size:50;not null" json:"status"`
+	RSVPTimestamp time.Time  `gorm:"not null" json:"rsvp_timestamp"`
+	IsLateRSVP    bool       `json:"is_late_rsvp"`
+	AddedByAdmin  bool       `json:"added_by_admin"`
+	AsRegular     bool       `json:"as_regular"`
+	CheckedInAt   *time.Time `json:"checked_in_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	ArchivedAt    time.Time  `gorm:"not null" json:"archived_at"`
+}
+
+func (ArchivedRSVP) TableName() string {
+	return "rsvps_archive"
+}
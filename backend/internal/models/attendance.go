@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AttendanceStatus string
+
+const (
+	AttendancePresent AttendanceStatus = "present"
+	AttendanceAbsent  AttendanceStatus = "absent"
+	AttendanceExcused AttendanceStatus = "excused" // absent, but told the club in time
+)
+
+// Attendance is whether a member turned up to a session, from their own
+// check-in or an admin's record. A confirmed player with no record at a
+// session where attendance was taken counts as a no-show.
+type Attendance struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID   uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_attendance_session_user" json:"session_id"`
+	UserID      uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_attendance_session_user;index" json:"user_id"`
+	Status      AttendanceStatus `gorm:"size:20;not null" json:"status"`
+	CheckedInAt *time.Time       `json:"checked_in_at,omitempty"`                // arrival, when present
+	RecordedBy  *uuid.UUID       `gorm:"type:uuid" json:"recorded_by,omitempty"` // nil for a check-in
+	Note        string           `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+
+	// Associations
+	Session *Session `gorm:"foreignKey:SessionID" json:"session,omitempty"`
+	User    *User    `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (a *Attendance) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditAction string
+
+const (
+	AuditActionDeadlineExtended            AuditAction = "deadline_extended"
+	AuditActionSessionMerged               AuditAction = "session_merged"
+	AuditActionAccountLinked               AuditAction = "account_linked"
+	AuditActionAnnouncementLimitOverridden AuditAction = "announcement_limit_overridden"
+	AuditActionMessageTemplateChanged      AuditAction = "message_template_changed"
+	AuditActionClubExported                AuditAction = "club_exported"
+	AuditActionMemberArchived              AuditAction = "member_archived"
+	AuditActionMemberKeptActive            AuditAction = "member_kept_active"
+	AuditActionMemberRestored              AuditAction = "member_restored"
+	AuditActionCommitteeRoleChanged        AuditAction = "committee_role_changed"
+)
+
+// AuditLog records an admin change to an entity, with its previous and new values
+type AuditLog struct {
+	ID         uuid.UUID   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	EntityType string      `gorm:"size:50;not null;index:idx_audit_entity" json:"entity_type"`
+	EntityID   uuid.UUID   `gorm:"type:uuid;not null;index:idx_audit_entity" json:"entity_id"`
+	Action     AuditAction `gorm:"size:50;not null" json:"action"`
+	ActorID    uuid.UUID   `gorm:"type:uuid;not null" json:"actor_id"`
+	OldValue   string      `gorm:"type:text" json:"old_value,omitempty"`
+	NewValue   string      `gorm:"type:text" json:"new_value,omitempty"`
+	Reason     string      `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+
+	// Associations
+	Actor *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
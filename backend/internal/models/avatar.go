@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AvatarSize is one of the sizes an uploaded photo is stored in
+type AvatarSize string
+
+const (
+	AvatarSmall  AvatarSize = "sm" // lists and chips
+	AvatarMedium AvatarSize = "md" // profiles
+	AvatarLarge  AvatarSize = "lg" // high-density screens
+)
+
+// AvatarSizes are the stored sizes and their width and height in pixels
+var AvatarSizes = []struct {
+	Size   AvatarSize
+	Pixels int
+}{
+	{AvatarSmall, 64},
+	{AvatarMedium, 256},
+	{AvatarLarge, 512},
+}
+
+// IsValid reports whether s is one of AvatarSizes
+func (s AvatarSize) IsValid() bool {
+	for _, size := range AvatarSizes {
+		if size.Size == s {
+			return true
+		}
+	}
+	return false
+}
+
+// AvatarReport is a member's report of another member's photo, queued for
+// admin moderation. It names the photo reported, so a report on a photo
+// already replaced is closed without touching the new one.
+type AvatarReport struct {
+	ID         uuid.UUID         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID     uuid.UUID         `gorm:"type:uuid;not null;index" json:"user_id"` // whose photo
+	AvatarID   uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_avatar_reporter" json:"avatar_id"`
+	ReportedBy uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_avatar_reporter" json:"reported_by"`
+	Reason     string            `gorm:"type:text" json:"reason"`
+	Status     ReportStatus      `gorm:"size:50;not null;default:'open'" json:"status"`
+	Action     *ModerationAction `gorm:"size:50" json:"action,omitempty"`
+	ResolvedBy *uuid.UUID        `gorm:"type:uuid" json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time        `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+
+	// Associations
+	User     *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Reporter *User `gorm:"foreignKey:ReportedBy" json:"reporter,omitempty"`
+}
+
+func (r *AvatarReport) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
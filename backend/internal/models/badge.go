@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BadgeType string
+
+const (
+	BadgeSessions50         BadgeType = "sessions_50"
+	BadgeOneYearMember      BadgeType = "one_year_member"
+	BadgeFirstTournamentWin BadgeType = "first_tournament_win"
+	BadgeGames100           BadgeType = "games_100"
+)
+
+// UserBadge records a milestone badge awarded to a user
+type UserBadge struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_badge" json:"user_id"`
+	BadgeType BadgeType `gorm:"size:50;not null;uniqueIndex:idx_user_badge" json:"badge_type"`
+	AwardedAt time.Time `gorm:"not null;default:now()" json:"awarded_at"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Association
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (b *UserBadge) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	if b.AwardedAt.IsZero() {
+		b.AwardedAt = time.Now()
+	}
+	return nil
+}
+
+// IsAttendance reports whether the badge gives away how often its holder plays
+func (t BadgeType) IsAttendance() bool {
+	return t == BadgeSessions50 || t == BadgeGames100
+}
+
+// DisplayName returns a human readable name for the badge
+func (t BadgeType) DisplayName() string {
+	switch t {
+	case BadgeSessions50:
+		return "50 Sessions"
+	case BadgeOneYearMember:
+		return "One Year Member"
+	case BadgeFirstTournamentWin:
+		return "First Tournament Win"
+	case BadgeGames100:
+		return "100 Games"
+	default:
+		return string(t)
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProposalStatus string
+
+const (
+	ProposalPending  ProposalStatus = "pending"
+	ProposalApproved ProposalStatus = "approved" // the session was cancelled
+	ProposalDeclined ProposalStatus = "declined" // the session goes ahead
+	ProposalLapsed   ProposalStatus = "lapsed"   // undecided when the session started or was cancelled otherwise
+)
+
+// CancellationProposal suggests cancelling an outdoor session because of its
+// forecast. Nothing is cancelled until an admin approves it, and a session
+// only ever gets one proposal.
+type CancellationProposal struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"session_id"`
+	Status    ProposalStatus `gorm:"size:20;not null;default:'pending';index" json:"status"`
+	Reason    string         `gorm:"type:text;not null" json:"reason"`   // the thresholds crossed: "80% chance of rain"
+	Forecast  string         `gorm:"type:text;not null" json:"forecast"` // the forecast when proposed
+	Secret    string         `gorm:"size:64;not null" json:"-"`          // signs the approve and decline links sent to admins
+	DecidedBy *uuid.UUID     `gorm:"type:uuid" json:"decided_by,omitempty"`
+	DecidedAt *time.Time     `json:"decided_at,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+
+	// Associations
+	Session *Session `gorm:"foreignKey:SessionID" json:"session,omitempty"`
+	Decider *User    `gorm:"foreignKey:DecidedBy" json:"decider,omitempty"`
+}
+
+func (p *CancellationProposal) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxCarpoolSeats bounds the seats a driver can offer, to catch typos
+const MaxCarpoolSeats = 8
+
+// CarpoolOffer is a member driving to a session with seats to spare
+type CarpoolOffer struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_carpool_offer_driver" json:"session_id"`
+	DriverID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_carpool_offer_driver" json:"driver_id"`
+	OriginSuburb string    `gorm:"size:100;not null" json:"origin_suburb"`
+	Seats        int       `gorm:"not null" json:"seats"`
+	Notes        string    `gorm:"type:text" json:"notes"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Association
+	Driver *User `gorm:"foreignKey:DriverID" json:"driver,omitempty"`
+}
+
+func (o *CarpoolOffer) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+type CarpoolRequestStatus string
+
+const (
+	CarpoolRequestOpen      CarpoolRequestStatus = "open"      // looking for a ride
+	CarpoolRequestAsked     CarpoolRequestStatus = "asked"     // asked a driver, who hasn't answered
+	CarpoolRequestConfirmed CarpoolRequestStatus = "confirmed" // has a seat
+)
+
+// CarpoolRequest is a member looking for a ride to a session. OfferID is the
+// driver they've asked or been confirmed with.
+type CarpoolRequest struct {
+	ID           uuid.UUID            `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID    uuid.UUID            `gorm:"type:uuid;not null;uniqueIndex:idx_carpool_request_rider" json:"session_id"`
+	RiderID      uuid.UUID            `gorm:"type:uuid;not null;uniqueIndex:idx_carpool_request_rider" json:"rider_id"`
+	OriginSuburb string               `gorm:"size:100;not null" json:"origin_suburb"`
+	Notes        string               `gorm:"type:text" json:"notes"`
+	Status       CarpoolRequestStatus `gorm:"size:50;not null;default:'open'" json:"status"`
+	OfferID      *uuid.UUID           `gorm:"type:uuid;index" json:"offer_id,omitempty"`
+	ConfirmedAt  *time.Time           `json:"confirmed_at,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+
+	// Association
+	Rider *User `gorm:"foreignKey:RiderID" json:"rider,omitempty"`
+}
+
+func (r *CarpoolRequest) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
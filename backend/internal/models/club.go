@@ -7,13 +7,118 @@ import (
 	"gorm.io/gorm"
 )
 
+// DefaultRecurringWeeksAhead is the look-ahead window for recurring sessions
+// when the club hasn't set one
+const DefaultRecurringWeeksAhead = 4
+
+// AttendeeVisibility is how much members see of who's coming to a session
+type AttendeeVisibility string
+
+const (
+	AttendeeNames     AttendeeVisibility = "names"      // everyone who RSVP'd, by name
+	AttendeeCount     AttendeeVisibility = "count"      // only how many are coming
+	AttendeeAfterRSVP AttendeeVisibility = "after_rsvp" // names once the member has RSVP'd themselves
+)
+
+// IsValid reports whether v is a known setting
+func (v AttendeeVisibility) IsValid() bool {
+	switch v {
+	case AttendeeNames, AttendeeCount, AttendeeAfterRSVP:
+		return true
+	}
+	return false
+}
+
 type Club struct {
 	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	Name         string    `gorm:"size:255;not null" json:"name"`
 	VenueName    string    `gorm:"size:255" json:"venue_name"`
 	VenueAddress string    `gorm:"type:text" json:"venue_address"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+
+	// A session's capacity is courts × PlayersPerCourt + ExtraPlayers. With
+	// PlayersPerCourt 0 the original 6/10/16 rotation table applies.
+	PlayersPerCourt int `gorm:"not null;default:0" json:"players_per_court"`
+	ExtraPlayers    int `gorm:"not null;default:0" json:"extra_players"`
+
+	// Non-urgent emails only go out from EmailWindowStart up to
+	// EmailWindowEnd (hours in the club's timezone); the rest wait for the
+	// window to open. Equal hours mean emails go out at any time.
+	EmailWindowStart int `gorm:"not null;default:0" json:"email_window_start"`
+	EmailWindowEnd   int `gorm:"not null;default:0" json:"email_window_end"`
+
+	// Recurring sessions are generated this many weeks ahead
+	RecurringWeeksAhead int `gorm:"not null;default:4" json:"recurring_weeks_ahead"`
+
+	// Club-wide RSVP caps on top of members' tiers; 0 means no cap.
+	// MaxRSVPsPerWeek limits the sessions a member is in for in a calendar
+	// week, and MaxRSVPsPerSeries the upcoming sessions of one recurring series.
+	MaxRSVPsPerWeek   int `gorm:"not null;default:0" json:"max_rsvps_per_week"`
+	MaxRSVPsPerSeries int `gorm:"not null;default:0" json:"max_rsvps_per_series"`
+
+	// The RSVP summary sent to admins when a session's deadline passes also
+	// goes to the member who organised the session
+	RSVPSummaryToOrganizer bool `gorm:"not null;default:false" json:"rsvp_summary_to_organizer"`
+
+	// Whether members see who else is coming to a session. Admins, the
+	// session's organizer and its coach always do.
+	AttendeeVisibility AttendeeVisibility `gorm:"size:20;not null;default:'names'" json:"attendee_visibility"`
+
+	// Members still on MAYBE are nudged to commit this many hours before a
+	// session's RSVP deadline; 0 turns the nudge off
+	MaybeNudgeHours int `gorm:"not null;default:0" json:"maybe_nudge_hours"`
+
+	// With ExpireMaybes, MAYBE RSVPs left this many hours before the deadline
+	// (0 is at the deadline) are changed to OUT, so the confirmed count can be
+	// planned around
+	ExpireMaybes     bool `gorm:"not null;default:false" json:"expire_maybes"`
+	MaybeExpiryHours int  `gorm:"not null;default:0" json:"maybe_expiry_hours"`
+
+	// A spot offered to the next member on a session's waitlist is held this
+	// many hours (or until the session starts) for them to confirm before it
+	// moves on down the line
+	WaitlistOfferHours int `gorm:"not null;default:2" json:"waitlist_offer_hours"`
+
+	// Admins are asked whether to cancel an outdoor session when its forecast
+	// reaches any of these: chance of rain (percent), wind (km/h) or
+	// temperature (°C). 0 ignores that measure.
+	WeatherRainChance  int `gorm:"not null;default:70" json:"weather_rain_chance"`
+	WeatherWindKmh     int `gorm:"not null;default:40" json:"weather_wind_kmh"`
+	WeatherTemperature int `gorm:"not null;default:38" json:"weather_temperature"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MaxPlayersForCourts returns a session's capacity under the club's settings
+func (c *Club) MaxPlayersForCourts(courts int) int {
+	if c == nil || c.PlayersPerCourt == 0 {
+		return MaxPlayersForCourts(courts)
+	}
+	return courts*c.PlayersPerCourt + c.ExtraPlayers
+}
+
+// EmailWindowOpensAt returns t if a non-urgent email may go out at t, or
+// else the next time the sending window opens. t should be in the club's
+// timezone. Windows may run past midnight (say 22 to 6).
+func (c *Club) EmailWindowOpensAt(t time.Time) time.Time {
+	if c == nil || c.EmailWindowStart == c.EmailWindowEnd {
+		return t
+	}
+
+	start, end, hour := c.EmailWindowStart, c.EmailWindowEnd, t.Hour()
+	open := hour >= start && hour < end
+	if start > end {
+		open = hour >= start || hour < end
+	}
+	if open {
+		return t
+	}
+
+	opens := time.Date(t.Year(), t.Month(), t.Day(), start, 0, 0, 0, t.Location())
+	if !opens.After(t) {
+		opens = opens.AddDate(0, 0, 1)
+	}
+	return opens
 }
 
 func (c *Club) BeforeCreate(tx *gorm.DB) error {
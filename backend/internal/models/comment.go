@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Comment is a member's message on a session
+type Comment struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null;index" json:"session_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
+	Hidden    bool      `gorm:"default:false" json:"hidden"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Association
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (c *Comment) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// CommentNotificationLevel is which comments on a session a member is notified of
+type CommentNotificationLevel string
+
+const (
+	CommentNotifyAll      CommentNotificationLevel = "all"      // every comment
+	CommentNotifyMentions CommentNotificationLevel = "mentions" // only comments that @mention them
+	CommentNotifyNone     CommentNotificationLevel = "none"
+)
+
+// IsValid reports whether l is a known level
+func (l CommentNotificationLevel) IsValid() bool {
+	switch l {
+	case CommentNotifyAll, CommentNotifyMentions, CommentNotifyNone:
+		return true
+	}
+	return false
+}
+
+// SessionCommentSetting overrides a member's default comment notification
+// level for one session. Setting "all" on a session they haven't RSVP'd to
+// follows its comments.
+type SessionCommentSetting struct {
+	ID        uuid.UUID                `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID uuid.UUID                `gorm:"type:uuid;not null;uniqueIndex:idx_session_comment_setting" json:"session_id"`
+	UserID    uuid.UUID                `gorm:"type:uuid;not null;uniqueIndex:idx_session_comment_setting;index" json:"user_id"`
+	Level     CommentNotificationLevel `gorm:"size:20;not null" json:"level"`
+	CreatedAt time.Time                `json:"created_at"`
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+func (s *SessionCommentSetting) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen     ReportStatus = "open"
+	ReportStatusResolved ReportStatus = "resolved"
+)
+
+type ModerationAction string
+
+const (
+	ModerationHide    ModerationAction = "hide"
+	ModerationDelete  ModerationAction = "delete"
+	ModerationWarn    ModerationAction = "warn"
+	ModerationDismiss ModerationAction = "dismiss"
+)
+
+// CommentReport is a member's abuse report on a comment, queued for admin moderation
+type CommentReport struct {
+	ID         uuid.UUID         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	CommentID  uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_comment_reporter" json:"comment_id"`
+	ReportedBy uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_comment_reporter" json:"reported_by"`
+	Reason     string            `gorm:"type:text" json:"reason"`
+	Status     ReportStatus      `gorm:"size:50;not null;default:'open'" json:"status"`
+	Action     *ModerationAction `gorm:"size:50" json:"action,omitempty"`
+	ResolvedBy *uuid.UUID        `gorm:"type:uuid" json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time        `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+
+	// Associations
+	Comment  *Comment `gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE" json:"comment,omitempty"`
+	Reporter *User    `gorm:"foreignKey:ReportedBy" json:"reporter,omitempty"`
+}
+
+func (r *CommentReport) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
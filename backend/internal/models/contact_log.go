@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ContactMethod string
+
+const (
+	ContactPhone    ContactMethod = "phone"
+	ContactEmail    ContactMethod = "email"
+	ContactSMS      ContactMethod = "sms"
+	ContactInPerson ContactMethod = "in_person"
+	ContactOther    ContactMethod = "other"
+)
+
+// ContactLog records an admin getting in touch with a member off the
+// platform, like a call about their absence or an email about fees, so
+// whoever takes over from them knows what was said. A follow-up can be set,
+// owned by one admin, who is reminded when it's due.
+type ContactLog struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID         uuid.UUID     `gorm:"type:uuid;not null;index" json:"user_id"`
+	Method         ContactMethod `gorm:"size:20;not null" json:"method"`
+	Subject        string        `gorm:"size:255;not null" json:"subject"`
+	Notes          string        `gorm:"type:text" json:"notes,omitempty"`
+	ContactedAt    time.Time     `gorm:"not null" json:"contacted_at"`
+	LoggedBy       uuid.UUID     `gorm:"type:uuid;not null" json:"logged_by"`
+	FollowUpAt     *time.Time    `gorm:"index" json:"follow_up_at,omitempty"`
+	AssignedTo     *uuid.UUID    `gorm:"type:uuid" json:"assigned_to,omitempty"` // who follows up
+	FollowUpDoneAt *time.Time    `json:"follow_up_done_at,omitempty"`
+	FollowUpDoneBy *uuid.UUID    `gorm:"type:uuid" json:"follow_up_done_by,omitempty"`
+	RemindedAt     *time.Time    `json:"-"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+
+	// Associations
+	User     *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Logger   *User `gorm:"foreignKey:LoggedBy" json:"logger,omitempty"`
+	Assignee *User `gorm:"foreignKey:AssignedTo" json:"assignee,omitempty"`
+}
+
+func (l *ContactLog) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
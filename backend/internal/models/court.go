@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PlayersPerCourt is the number of players assigned to a court for doubles
+const PlayersPerCourt = 4
+
+// CourtAssignment records a player being sent to a court during a live session.
+// An assignment is active until ReleasedAt is set.
+type CourtAssignment struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"session_id"`
+	CourtNumber int        `gorm:"not null" json:"court_number"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	AssignedBy  uuid.UUID  `gorm:"type:uuid;not null" json:"assigned_by"`
+	AssignedAt  time.Time  `gorm:"not null;default:now()" json:"assigned_at"`
+	ReleasedAt  *time.Time `json:"released_at,omitempty"`
+
+	// Association
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (a *CourtAssignment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	if a.AssignedAt.IsZero() {
+		a.AssignedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DocumentCategory string
+
+const (
+	DocumentCategoryRules        DocumentCategory = "rules"
+	DocumentCategoryConstitution DocumentCategory = "constitution"
+	DocumentCategoryInduction    DocumentCategory = "induction"
+	DocumentCategoryOther        DocumentCategory = "other"
+)
+
+// Document is a club file such as the rules or venue induction, held in object storage
+type Document struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Title       string           `gorm:"size:255;not null" json:"title"`
+	Description string           `gorm:"type:text" json:"description"`
+	Category    DocumentCategory `gorm:"size:50;not null;default:'other'" json:"category"`
+	FileName    string           `gorm:"size:255;not null" json:"file_name"`
+	ContentType string           `gorm:"size:100;not null" json:"content_type"`
+	SizeBytes   int64            `gorm:"not null" json:"size_bytes"`
+	StorageKey  string           `gorm:"size:255;not null" json:"-"`
+	Required    bool             `gorm:"default:false" json:"required"` // Must be acknowledged before a first RSVP
+	UploadedBy  uuid.UUID        `gorm:"type:uuid;not null" json:"uploaded_by"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+
+	// Committee papers are shown only to the committee, and never required
+	CommitteeOnly bool `gorm:"not null;default:false;index" json:"committee_only"`
+}
+
+func (d *Document) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// DocumentAcknowledgement records that a member has read a document
+type DocumentAcknowledgement struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	DocumentID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_document_user" json:"document_id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_document_user" json:"user_id"`
+	AcknowledgedAt time.Time `gorm:"not null" json:"acknowledged_at"`
+
+	// Association
+	Document *Document `gorm:"foreignKey:DocumentID;constraint:OnDelete:CASCADE" json:"document,omitempty"`
+}
+
+func (a *DocumentAcknowledgement) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrDomainEventImmutable is returned by attempts to change or delete a recorded event
+var ErrDomainEventImmutable = errors.New("domain events are append-only")
+
+type EventType string
+
+const (
+	EventSessionCreated      EventType = "session.created"
+	EventSessionUpdated      EventType = "session.updated"
+	EventSessionCancelled    EventType = "session.cancelled"
+	EventRSVPChanged         EventType = "rsvp.changed" // a member answered, changed or withdrew their RSVP
+	EventRSVPRemoved         EventType = "rsvp.removed" // an admin removed or changed a player's RSVP
+	EventRSVPRequestApproved EventType = "rsvp.request_approved"
+	EventRSVPRequestDeclined EventType = "rsvp.request_declined"
+	EventWaitlistOffered     EventType = "waitlist.offered"    // a spot was offered to the next member in line
+	EventWaitlistLapsed      EventType = "waitlist.lapsed"     // an offer ran out unconfirmed
+	EventWaitlistOverbooked  EventType = "waitlist.overbooked" // an overbooked IN went back to the waitlist as RSVPs closed
+	EventMemberApproved      EventType = "member.approved"
+	EventMemberRejected      EventType = "member.rejected"
+)
+
+// DomainEvent is something that happened in the club, recorded in the same
+// transaction as the change itself. The table is append-only; Seq numbers
+// events as they're recorded, for consumers to read on from where they left off.
+type DomainEvent struct {
+	Seq        int64      `gorm:"primaryKey;autoIncrement" json:"seq"`
+	ID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"id"`
+	Type       EventType  `gorm:"size:50;not null;index" json:"type"`
+	SessionID  *uuid.UUID `gorm:"type:uuid;index" json:"session_id,omitempty"`
+	UserID     *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"`  // the member it's about
+	ActorID    *uuid.UUID `gorm:"type:uuid;index" json:"actor_id,omitempty"` // who did it; empty for the system or when not known
+	Data       string     `gorm:"type:jsonb;not null" json:"data"`
+	OccurredAt time.Time  `gorm:"not null;index" json:"occurred_at"`
+}
+
+func (e *DomainEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	return nil
+}
+
+func (e *DomainEvent) BeforeUpdate(tx *gorm.DB) error {
+	return ErrDomainEventImmutable
+}
+
+func (e *DomainEvent) BeforeDelete(tx *gorm.DB) error {
+	return ErrDomainEventImmutable
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GameStatus string
+
+const (
+	GameStatusPending   GameStatus = "pending"
+	GameStatusConfirmed GameStatus = "confirmed"
+)
+
+type GameTeam string
+
+const (
+	GameTeamA GameTeam = "a"
+	GameTeamB GameTeam = "b"
+)
+
+// Game is a casual singles or doubles game played during a session
+type Game struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"session_id"`
+	TeamAScore  int        `gorm:"not null" json:"team_a_score"`
+	TeamBScore  int        `gorm:"not null" json:"team_b_score"`
+	Status      GameStatus `gorm:"size:50;not null;default:'pending'" json:"status"`
+	RecordedBy  uuid.UUID  `gorm:"type:uuid;not null" json:"recorded_by"`
+	ConfirmedBy *uuid.UUID `gorm:"type:uuid" json:"confirmed_by,omitempty"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Associations
+	Players []GamePlayer `gorm:"foreignKey:GameID" json:"players,omitempty"`
+}
+
+func (g *Game) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	return nil
+}
+
+// WinningTeam returns the team with the higher score
+func (g *Game) WinningTeam() GameTeam {
+	if g.TeamAScore > g.TeamBScore {
+		return GameTeamA
+	}
+	return GameTeamB
+}
+
+// GamePlayer links a player to a team in a game
+type GamePlayer struct {
+	ID     uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	GameID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_game_user" json:"game_id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_game_user;index" json:"user_id"`
+	Team   GameTeam  `gorm:"size:1;not null" json:"team"`
+
+	// Association
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (p *GamePlayer) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// DefaultEloRating is the starting rating for new players
+const DefaultEloRating = 1000
+
+// PlayerRating holds a player's Elo rating from confirmed games
+type PlayerRating struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;uniqueIndex;not null" json:"user_id"`
+	Rating      float64   `gorm:"not null;default:1000" json:"rating"`
+	GamesPlayed int       `gorm:"not null;default:0" json:"games_played"`
+	GamesWon    int       `gorm:"not null;default:0" json:"games_won"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Association
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (r *PlayerRating) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Peer levels run from MinPeerLevel (beginner) to MaxPeerLevel (strongest
+// in the club)
+const (
+	MinPeerLevel = 1
+	MaxPeerLevel = 5
+)
+
+// PeerRating is one member's private view of another's level after they
+// played in the same game. Ratings are never shown, even in aggregate; they
+// only help put evenly matched players on a court together.
+type PeerRating struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	GameID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_peer_rating" json:"game_id"`
+	RaterID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_peer_rating" json:"rater_id"`
+	RateeID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_peer_rating;index" json:"ratee_id"`
+	Level     int       `gorm:"not null;check:chk_peer_ratings_level,level BETWEEN 1 AND 5" json:"level"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r *PeerRating) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
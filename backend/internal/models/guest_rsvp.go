@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxGuestsPerMember is how many guests a member can bring to a session
+const MaxGuestsPerMember = 1
+
+// GuestRSVP is a non-member a member is bringing to a session. A guest takes
+// one of the session's spots like an IN RSVP, and is counted in the
+// session's GuestCount.
+type GuestRSVP struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null;index" json:"session_id"`
+	InvitedBy uuid.UUID `gorm:"type:uuid;not null;index" json:"invited_by"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Associations
+	Session *Session `gorm:"foreignKey:SessionID" json:"session,omitempty"`
+	Inviter *User    `gorm:"foreignKey:InvitedBy" json:"inviter,omitempty"`
+}
+
+func (g *GuestRSVP) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterCreate and AfterDelete recount the session's spots taken
+func (g *GuestRSVP) AfterCreate(tx *gorm.DB) error {
+	return RefreshRSVPCounts(tx, g.SessionID)
+}
+
+func (g *GuestRSVP) AfterDelete(tx *gorm.DB) error {
+	if g.SessionID == uuid.Nil {
+		return nil
+	}
+	return RefreshRSVPCounts(tx, g.SessionID)
+}
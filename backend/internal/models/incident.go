@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IncidentType string
+
+const (
+	IncidentTypeInjury   IncidentType = "injury"
+	IncidentTypeFacility IncidentType = "facility"
+	IncidentTypeOther    IncidentType = "other"
+)
+
+type IncidentSeverity string
+
+const (
+	IncidentSeverityLow      IncidentSeverity = "low"
+	IncidentSeverityMedium   IncidentSeverity = "medium"
+	IncidentSeverityHigh     IncidentSeverity = "high"
+	IncidentSeverityCritical IncidentSeverity = "critical"
+)
+
+type IncidentStatus string
+
+const (
+	IncidentStatusOpen     IncidentStatus = "open"
+	IncidentStatusResolved IncidentStatus = "resolved"
+)
+
+// Incident is an injury or facility issue at a session, kept for insurance records
+type Incident struct {
+	ID              uuid.UUID        `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID       uuid.UUID        `gorm:"type:uuid;not null;index" json:"session_id"`
+	ReportedBy      uuid.UUID        `gorm:"type:uuid;not null" json:"reported_by"`
+	Type            IncidentType     `gorm:"size:50;not null" json:"type"`
+	Severity        IncidentSeverity `gorm:"size:50;not null" json:"severity"`
+	Title           string           `gorm:"size:255;not null" json:"title"`
+	Description     string           `gorm:"type:text;not null" json:"description"`
+	OccurredAt      *time.Time       `json:"occurred_at,omitempty"`
+	Status          IncidentStatus   `gorm:"size:50;not null;default:'open';index" json:"status"`
+	ResolutionNotes string           `gorm:"type:text" json:"resolution_notes,omitempty"`
+	ResolvedBy      *uuid.UUID       `gorm:"type:uuid" json:"resolved_by,omitempty"`
+	ResolvedAt      *time.Time       `json:"resolved_at,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+
+	// Associations
+	Session     *Session             `gorm:"foreignKey:SessionID" json:"session,omitempty"`
+	Reporter    *User                `gorm:"foreignKey:ReportedBy" json:"reporter,omitempty"`
+	Attachments []IncidentAttachment `gorm:"foreignKey:IncidentID" json:"attachments,omitempty"`
+}
+
+func (i *Incident) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// IncidentAttachment is a photo or document attached to an incident, held in object storage
+type IncidentAttachment struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	IncidentID  uuid.UUID `gorm:"type:uuid;not null;index" json:"incident_id"`
+	FileName    string    `gorm:"size:255;not null" json:"file_name"`
+	ContentType string    `gorm:"size:100;not null" json:"content_type"`
+	SizeBytes   int64     `gorm:"not null" json:"size_bytes"`
+	StorageKey  string    `gorm:"size:255;not null" json:"-"`
+	UploadedBy  uuid.UUID `gorm:"type:uuid;not null" json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (a *IncidentAttachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type InventoryCategory string
+
+const (
+	InventoryEquipment  InventoryCategory = "equipment"  // nets, first aid kits: checked out and back in
+	InventoryConsumable InventoryCategory = "consumable" // shuttles, tape: used up
+)
+
+// InventoryItem is something the club owns, with how many it has on hand.
+// Quantity includes any checked out to a session. The one item that
+// TracksShuttles is drawn down by each session's recorded shuttle usage.
+type InventoryItem struct {
+	ID                 uuid.UUID         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Name               string            `gorm:"size:100;not null;uniqueIndex" json:"name"`
+	Category           InventoryCategory `gorm:"size:50;not null;default:'equipment'" json:"category"`
+	Unit               string            `gorm:"size:50" json:"unit"` // such as "shuttles" or "nets"
+	Quantity           int               `gorm:"not null;default:0" json:"quantity"`
+	LowStockThreshold  int               `gorm:"not null;default:0" json:"low_stock_threshold"` // 0 never alerts
+	TracksShuttles     bool              `gorm:"default:false" json:"tracks_shuttles"`
+	Notes              string            `gorm:"type:text" json:"notes"`
+	LowStockNotifiedAt *time.Time        `json:"low_stock_notified_at,omitempty"` // cleared when restocked above the threshold
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+}
+
+func (i *InventoryItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsLowStock reports whether the item is at or below its alert threshold
+func (i *InventoryItem) IsLowStock() bool {
+	return i.LowStockThreshold > 0 && i.Quantity <= i.LowStockThreshold
+}
+
+// InventoryCheckout is equipment an organizer took to a session. It is out
+// until checked back in; anything not returned comes off the quantity.
+type InventoryCheckout struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ItemID           uuid.UUID  `gorm:"type:uuid;not null;index" json:"item_id"`
+	SessionID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"session_id"`
+	Quantity         int        `gorm:"not null" json:"quantity"`
+	CheckedOutBy     uuid.UUID  `gorm:"type:uuid;not null" json:"checked_out_by"`
+	CheckedOutAt     time.Time  `gorm:"not null" json:"checked_out_at"`
+	ReturnedQuantity *int       `json:"returned_quantity,omitempty"`
+	CheckedInBy      *uuid.UUID `gorm:"type:uuid" json:"checked_in_by,omitempty"`
+	CheckedInAt      *time.Time `json:"checked_in_at,omitempty"`
+
+	// Association
+	Item *InventoryItem `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+}
+
+func (c *InventoryCheckout) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+type InventoryMovementReason string
+
+const (
+	InventoryRestock      InventoryMovementReason = "restock"
+	InventoryAdjustment   InventoryMovementReason = "adjustment"    // a stocktake correction
+	InventorySessionUsage InventoryMovementReason = "session_usage" // shuttles recorded as used at a session
+	InventoryNotReturned  InventoryMovementReason = "not_returned"  // checked out and not brought back
+)
+
+// InventoryMovement is one change to an item's quantity, for its history and
+// the consumption report
+type InventoryMovement struct {
+	ID        uuid.UUID               `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ItemID    uuid.UUID               `gorm:"type:uuid;not null;index" json:"item_id"`
+	Change    int                     `gorm:"not null" json:"change"`
+	Reason    InventoryMovementReason `gorm:"size:50;not null" json:"reason"`
+	SessionID *uuid.UUID              `gorm:"type:uuid;index" json:"session_id,omitempty"`
+	ActorID   *uuid.UUID              `gorm:"type:uuid" json:"actor_id,omitempty"` // nil when recorded by the system
+	Note      string                  `gorm:"size:255" json:"note"`
+	CreatedAt time.Time               `gorm:"index" json:"created_at"`
+}
+
+func (m *InventoryMovement) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Invite is a sign-up link an admin hands out. Up to MaxUses people can join
+// with it before ExpiresAt; with AutoApprove they're approved straight away,
+// otherwise their join requests go to the front of the queue.
+type Invite struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Token       string     `gorm:"size:64;not null;uniqueIndex" json:"token"`
+	Label       string     `gorm:"size:100" json:"label"` // such as "Open day flyer"
+	MaxUses     int        `gorm:"not null" json:"max_uses"`
+	Uses        int        `gorm:"not null;default:0" json:"uses"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	AutoApprove bool       `gorm:"default:false" json:"auto_approve"`
+	CreatedBy   uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (i *Invite) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsUsable reports whether someone can still join with the invite at now
+func (i *Invite) IsUsable(now time.Time) bool {
+	return i.RevokedAt == nil && now.Before(i.ExpiresAt) && i.Uses < i.MaxUses
+}
+
+// InviteUse records a member who joined with an invite
+type InviteUse struct {
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	InviteID uuid.UUID `gorm:"type:uuid;not null;index" json:"invite_id"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"` // one invite per member
+	Approved bool      `gorm:"default:false" json:"approved"`                 // by the invite itself
+	UsedAt   time.Time `gorm:"not null" json:"used_at"`
+
+	// Association
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (u *InviteUse) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type JobRunStatus string
+
+const (
+	JobRunStatusRunning   JobRunStatus = "running"
+	JobRunStatusSucceeded JobRunStatus = "succeeded"
+	JobRunStatusFailed    JobRunStatus = "failed"
+)
+
+// JobRun records one run of a scheduler job, so a job that stops running or
+// keeps failing shows up
+type JobRun struct {
+	ID         uuid.UUID    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	JobName    string       `gorm:"size:100;not null;index:idx_job_runs_name_started" json:"job_name"`
+	StartedAt  time.Time    `gorm:"not null;index:idx_job_runs_name_started" json:"started_at"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+	Status     JobRunStatus `gorm:"size:20;not null" json:"status"`
+	Error      string       `gorm:"type:text" json:"error,omitempty"`
+}
+
+func (j *JobRun) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JoinRules decide which join requests are approved without an admin. With
+// AutoApprove off every request waits for manual review, whatever the rules
+// below say.
+type JoinRules struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ClubID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"club_id"`
+	AutoApprove bool      `gorm:"default:false" json:"auto_approve"`
+
+	// Verified emails at these domains (lower case, without the @) are approved
+	EmailDomains []string `gorm:"type:text;serializer:json" json:"email_domains"`
+	// Sign-ups with an approved member's referral code are approved
+	ReferralCodes bool `gorm:"default:false" json:"referral_codes"`
+	// Verified emails on the imported member list are approved
+	PreapprovedList bool `gorm:"default:false" json:"preapproved_list"`
+
+	UpdatedBy *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (r *JoinRules) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// JoinRule is how a member's join request came to be approved
+type JoinRule string
+
+const (
+	JoinRuleEmailDomain  JoinRule = "email_domain"
+	JoinRuleReferralCode JoinRule = "referral_code"
+	JoinRulePreapproved  JoinRule = "preapproved_list"
+	JoinRuleInvite       JoinRule = "invite" // an admin's invite link that approves on sign-up
+	JoinRuleManual       JoinRule = "manual"
+)
+
+// JoinApproval records which rule approved a member, or the admin who did
+type JoinApproval struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Rule           JoinRule   `gorm:"size:50;not null;index" json:"rule"`
+	Detail         string     `gorm:"size:255" json:"detail"` // the domain, code or list entry that matched
+	ReferralCodeID *uuid.UUID `gorm:"type:uuid;index" json:"referral_code_id,omitempty"`
+	InviteID       *uuid.UUID `gorm:"type:uuid;index" json:"invite_id,omitempty"`
+	ApprovedBy     *uuid.UUID `gorm:"type:uuid" json:"approved_by,omitempty"` // the admin, or who made the invite
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// Association
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (a *JoinApproval) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// ReferralCode is an approved member's code for inviting friends. Each
+// member has one; an admin can disable it.
+type ReferralCode struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Code       string     `gorm:"size:20;not null;uniqueIndex" json:"code"`
+	OwnerID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"owner_id"`
+	Uses       int        `gorm:"not null;default:0" json:"uses"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Association
+	Owner *User `gorm:"foreignKey:OwnerID" json:"owner,omitempty"`
+}
+
+func (c *ReferralCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// PreapprovedMember is an entry on the imported member list. Email is
+// stored lower case; UsedBy is set once someone joins with it.
+type PreapprovedMember struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Email     string     `gorm:"size:255;not null;uniqueIndex" json:"email"`
+	Name      string     `gorm:"size:255" json:"name"`
+	AddedBy   uuid.UUID  `gorm:"type:uuid;not null" json:"added_by"`
+	UsedBy    *uuid.UUID `gorm:"type:uuid" json:"used_by,omitempty"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (m *PreapprovedMember) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
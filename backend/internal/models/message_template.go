@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MessageTemplateKey names a notification message the club can reword
+type MessageTemplateKey string
+
+const (
+	MessageSessionReminder MessageTemplateKey = "session_reminder"
+	MessageRSVPDeadline    MessageTemplateKey = "rsvp_deadline"
+	MessageWaitlistUpdate  MessageTemplateKey = "waitlist_update"
+	MessageRSVPSummary     MessageTemplateKey = "rsvp_summary"
+	MessageMaybeNudge      MessageTemplateKey = "maybe_nudge"
+	MessageMaybeExpired    MessageTemplateKey = "maybe_expired"
+	MessageMonthlyRecap    MessageTemplateKey = "monthly_recap"
+)
+
+// MessageTemplate is the club's own wording for a notification in one
+// language, used in place of the built-in default. Title and Body are Go
+// text/template source.
+type MessageTemplate struct {
+	ID          uuid.UUID          `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Key         MessageTemplateKey `gorm:"size:50;not null;uniqueIndex:idx_message_template_key_language" json:"key"`
+	Language    Language           `gorm:"size:10;not null;default:'en';uniqueIndex:idx_message_template_key_language" json:"language"`
+	Title       string             `gorm:"type:text;not null" json:"title"`
+	Body        string             `gorm:"type:text;not null" json:"body"`
+	UpdatedByID uuid.UUID          `gorm:"type:uuid;not null" json:"updated_by_id"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+func (m *MessageTemplate) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
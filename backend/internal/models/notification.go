@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,30 +17,60 @@ const (
 	NotificationRSVPDeadline      NotificationType = "rsvp_deadline"
 	NotificationWaitlistUpdate    NotificationType = "waitlist_update"
 	NotificationAdminAnnouncement NotificationType = "admin_announcement"
+	NotificationBadgeAwarded      NotificationType = "badge_awarded"
+	NotificationCourtAssignment   NotificationType = "court_assignment"
+	NotificationModeration        NotificationType = "moderation"
+	NotificationRSVPChanged       NotificationType = "rsvp_changed"
+	NotificationIncidentReported  NotificationType = "incident_reported"
+	NotificationSessionChanged    NotificationType = "session_changed"
+	NotificationAccountLink       NotificationType = "account_link"
+	NotificationSessionComment    NotificationType = "session_comment"
+	NotificationMemberInactive    NotificationType = "member_inactive"
+	NotificationOrderWindow       NotificationType = "order_window"
+	NotificationCarpool           NotificationType = "carpool"
+	NotificationLowStock          NotificationType = "low_stock"
+	NotificationEmailReply        NotificationType = "email_reply"
+	NotificationRSVPSummary       NotificationType = "rsvp_summary"
+	NotificationMaybeNudge        NotificationType = "maybe_nudge"
+	NotificationMonthlyRecap      NotificationType = "monthly_recap"
+	NotificationWeatherProposal   NotificationType = "weather_proposal"
+	NotificationContactFollowUp   NotificationType = "contact_follow_up"
 )
 
+// IsUrgent reports whether emails of this type go out straight away rather
+// than waiting for the club's email window
+func (t NotificationType) IsUrgent() bool {
+	switch t {
+	case NotificationWaitlistUpdate, NotificationIncidentReported, NotificationSessionChanged, NotificationAccountLink, NotificationCarpool, NotificationWeatherProposal:
+		return true
+	}
+	return false
+}
+
 // UserNotificationPreferences stores per-user notification settings
 type UserNotificationPreferences struct {
 	ID     uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	UserID uuid.UUID `gorm:"type:uuid;uniqueIndex;not null" json:"user_id"`
 
-	// Push notification preferences
-	PushEnabled            bool `gorm:"default:true" json:"push_enabled"`
-	PushSessionReminders   bool `gorm:"default:true" json:"push_session_reminders"`
-	PushRSVPDeadlines      bool `gorm:"default:true" json:"push_rsvp_deadlines"`
-	PushWaitlistUpdates    bool `gorm:"default:true" json:"push_waitlist_updates"`
-	PushAdminAnnouncements bool `gorm:"default:true" json:"push_admin_announcements"`
+	// Master switches; each type's own choice is in Types
+	PushEnabled  bool `gorm:"default:true" json:"push_enabled"`
+	EmailEnabled bool `gorm:"default:true" json:"email_enabled"`
+
+	// Reminder content
+	ReminderShowAttendees bool `gorm:"default:false" json:"reminder_show_attendees"` // opt-in: list who else is coming
 
-	// Email notification preferences
-	EmailEnabled            bool `gorm:"default:true" json:"email_enabled"`
-	EmailSessionReminders   bool `gorm:"default:true" json:"email_session_reminders"`
-	EmailRSVPDeadlines      bool `gorm:"default:true" json:"email_rsvp_deadlines"`
-	EmailWaitlistUpdates    bool `gorm:"default:true" json:"email_waitlist_updates"`
-	EmailAdminAnnouncements bool `gorm:"default:true" json:"email_admin_announcements"`
+	// Session comments (push only); sessions can override it
+	CommentNotifications CommentNotificationLevel `gorm:"size:20;not null;default:'all'" json:"comment_notifications"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
+	// Types holds the member's choices per type and channel, loaded by the
+	// notification service; Settings is every configurable type with its
+	// effective value, for the API
+	Types    []NotificationTypePreference                      `gorm:"-" json:"-"`
+	Settings map[NotificationType]map[NotificationChannel]bool `gorm:"-" json:"types"`
+
 	// Association
 	User *User `gorm:"foreignKey:UserID" json:"-"`
 }
@@ -79,12 +112,23 @@ type Notification struct {
 	NotificationType NotificationType `gorm:"type:text;not null" json:"notification_type"`
 	Title            string           `gorm:"type:text;not null" json:"title"`
 	Body             string           `gorm:"type:text;not null" json:"body"`
-	Data             string           `gorm:"type:jsonb" json:"data,omitempty"` // JSON string for additional payload
+	Data             NotificationData `gorm:"type:jsonb;index:idx_notifications_data,type:gin" json:"data,omitempty"`
 
 	PushSent    bool       `gorm:"default:false" json:"push_sent"`
 	PushSentAt  *time.Time `json:"push_sent_at,omitempty"`
+	PushError   string     `gorm:"type:text" json:"push_error,omitempty"` // last failed attempt
 	EmailSent   bool       `gorm:"default:false" json:"email_sent"`
 	EmailSentAt *time.Time `json:"email_sent_at,omitempty"`
+	EmailError  string     `gorm:"type:text" json:"email_error,omitempty"` // last failed attempt
+	SMSSent     bool       `gorm:"default:false" json:"sms_sent"`
+	SMSSentAt   *time.Time `json:"sms_sent_at,omitempty"`
+	SMSError    string     `gorm:"type:text" json:"sms_error,omitempty"` // last failed attempt
+
+	// Urgent notifications go out on every channel, including SMS, whatever
+	// the member's preferences and the club's email window
+	Urgent bool `gorm:"default:false" json:"urgent"`
+
+	EmailQueuedUntil *time.Time `gorm:"index" json:"email_queued_until,omitempty"` // held for the club's email window
 
 	ReadAt    *time.Time `json:"read_at,omitempty"`
 	CreatedAt time.Time  `gorm:"index" json:"created_at"`
@@ -100,7 +144,62 @@ func (n *Notification) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// NotificationData is the additional payload sent with a notification, stored as JSONB
+type NotificationData map[string]string
+
+// Value implements driver.Valuer
+func (d NotificationData) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (d *NotificationData) Scan(value interface{}) error {
+	if value == nil {
+		*d = nil
+		return nil
+	}
+	var b []byte
+	switch v := value.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return errors.New("unsupported type for NotificationData")
+	}
+	return json.Unmarshal(b, d)
+}
+
+// Get returns the value for a key, or "" if it isn't set
+func (d NotificationData) Get(key string) string {
+	return d[key]
+}
+
+// SessionID returns the session the notification refers to, if any
+func (d NotificationData) SessionID() (uuid.UUID, bool) {
+	id, err := uuid.Parse(d["session_id"])
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
 // Announcement represents an admin-sent announcement to all members
+// AnnouncementAudience is who an announcement is sent to
+type AnnouncementAudience string
+
+const (
+	AudienceMembers   AnnouncementAudience = "members"   // every approved member
+	AudienceCommittee AnnouncementAudience = "committee" // the committee and admins only
+)
+
 type Announcement struct {
 	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	Title     string    `gorm:"type:text;not null" json:"title"`
@@ -109,6 +208,13 @@ type Announcement struct {
 	SentAt    time.Time `gorm:"default:now()" json:"sent_at"`
 	CreatedAt time.Time `json:"created_at"`
 
+	Audience AnnouncementAudience `gorm:"size:20;not null;default:'members';index" json:"audience"`
+
+	// Members are asked to confirm they've read it, and nudged until they do
+	RequiresAck  bool       `gorm:"default:false" json:"requires_ack"`
+	NudgesSent   int        `gorm:"not null;default:0" json:"nudges_sent"`
+	LastNudgedAt *time.Time `json:"last_nudged_at,omitempty"`
+
 	// Association
 	Creator *User `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
 }
@@ -120,43 +226,81 @@ func (a *Announcement) BeforeCreate(tx *gorm.DB) error {
 	if a.SentAt.IsZero() {
 		a.SentAt = time.Now()
 	}
+	if a.Audience == "" {
+		a.Audience = AudienceMembers
+	}
 	return nil
 }
 
-// IsPushEnabledForType checks if push notifications are enabled for a specific notification type
-func (p *UserNotificationPreferences) IsPushEnabledForType(t NotificationType) bool {
-	if !p.PushEnabled {
-		return false
+// AnnouncementAcknowledgement records that a member has read an announcement
+type AnnouncementAcknowledgement struct {
+	ID             uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	AnnouncementID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_announcement_user" json:"announcement_id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_announcement_user" json:"user_id"`
+	AcknowledgedAt time.Time `gorm:"not null" json:"acknowledged_at"`
+
+	// Associations
+	Announcement *Announcement `gorm:"foreignKey:AnnouncementID;constraint:OnDelete:CASCADE" json:"-"`
+	User         *User         `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (a *AnnouncementAcknowledgement) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
 	}
-	switch t {
-	case NotificationSessionReminder:
-		return p.PushSessionReminders
-	case NotificationRSVPDeadline:
-		return p.PushRSVPDeadlines
-	case NotificationWaitlistUpdate:
-		return p.PushWaitlistUpdates
-	case NotificationAdminAnnouncement:
-		return p.PushAdminAnnouncements
+	return nil
+}
+
+// IsEnabled reports whether a notification of type t goes out on channel:
+// the channel's master switch must be on, then the member's choice for the
+// type applies, or the type's default when they haven't made one. Types
+// members can't configure always get their default. Types must be loaded.
+func (p *UserNotificationPreferences) IsEnabled(t NotificationType, channel NotificationChannel) bool {
+	switch channel {
+	case ChannelPush:
+		if !p.PushEnabled {
+			return false
+		}
+	case ChannelEmail:
+		if !p.EmailEnabled {
+			return false
+		}
 	default:
 		return false
 	}
+	return p.typeEnabled(t, channel)
 }
 
-// IsEmailEnabledForType checks if email notifications are enabled for a specific notification type
-func (p *UserNotificationPreferences) IsEmailEnabledForType(t NotificationType) bool {
-	if !p.EmailEnabled {
+// typeEnabled is the member's setting for t on channel, ignoring the master switches
+func (p *UserNotificationPreferences) typeEnabled(t NotificationType, channel NotificationChannel) bool {
+	defaults, ok := NotificationDefaults[t]
+	if !ok {
 		return false
 	}
-	switch t {
-	case NotificationSessionReminder:
-		return p.EmailSessionReminders
-	case NotificationRSVPDeadline:
-		return p.EmailRSVPDeadlines
-	case NotificationWaitlistUpdate:
-		return p.EmailWaitlistUpdates
-	case NotificationAdminAnnouncement:
-		return p.EmailAdminAnnouncements
-	default:
-		return false
+	if defaults.Inherits != "" {
+		return p.typeEnabled(defaults.Inherits, channel)
+	}
+	if defaults.IsConfigurable(channel) {
+		for _, tp := range p.Types {
+			if tp.Type == t && tp.Channel == channel {
+				return tp.Enabled
+			}
+		}
+	}
+	return defaults.Default(channel)
+}
+
+// TypeSettings returns the member's effective setting for every type and
+// channel they can configure
+func (p *UserNotificationPreferences) TypeSettings() map[NotificationType]map[NotificationChannel]bool {
+	settings := make(map[NotificationType]map[NotificationChannel]bool)
+	for t, defaults := range NotificationDefaults {
+		for _, channel := range defaults.Configurable {
+			if settings[t] == nil {
+				settings[t] = make(map[NotificationChannel]bool)
+			}
+			settings[t][channel] = p.typeEnabled(t, channel)
+		}
 	}
+	return settings
 }
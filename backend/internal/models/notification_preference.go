@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationChannel string
+
+const (
+	ChannelPush  NotificationChannel = "push"
+	ChannelEmail NotificationChannel = "email"
+)
+
+// NotificationTypeDefaults says how a notification type is delivered to
+// members who haven't chosen otherwise, and which channels they may change
+type NotificationTypeDefaults struct {
+	Push  bool
+	Email bool
+
+	// Configurable lists the channels members can turn the type on or off
+	// for; the rest always use the default
+	Configurable []NotificationChannel
+
+	// Inherits makes the type follow another type's setting, so a new kind
+	// of notice can ride on an existing choice instead of adding its own
+	Inherits NotificationType
+}
+
+// Default is the type's setting on channel when the member hasn't chosen
+func (d NotificationTypeDefaults) Default(channel NotificationChannel) bool {
+	switch channel {
+	case ChannelPush:
+		return d.Push
+	case ChannelEmail:
+		return d.Email
+	}
+	return false
+}
+
+// IsConfigurable reports whether members can change the type on channel
+func (d NotificationTypeDefaults) IsConfigurable(channel NotificationChannel) bool {
+	for _, c := range d.Configurable {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+var pushAndEmail = []NotificationChannel{ChannelPush, ChannelEmail}
+
+// mandatory is for account, safety, schedule-change, carpool, stock, email
+// reply, weather and follow-up notices, which can't be muted
+var mandatory = NotificationTypeDefaults{Push: true, Email: true}
+
+// NotificationDefaults has an entry for every notification type; a type
+// without one is never sent. Adding a type only needs an entry here.
+var NotificationDefaults = map[NotificationType]NotificationTypeDefaults{
+	NotificationSessionReminder:   {Push: true, Email: true, Configurable: pushAndEmail},
+	NotificationRSVPDeadline:      {Push: true, Email: true, Configurable: pushAndEmail},
+	NotificationWaitlistUpdate:    {Push: true, Email: true, Configurable: pushAndEmail},
+	NotificationAdminAnnouncement: {Push: true, Email: true, Configurable: pushAndEmail},
+	NotificationOrderWindow:       {Inherits: NotificationAdminAnnouncement},
+	NotificationBadgeAwarded:      {Configurable: pushAndEmail}, // opt-in
+	NotificationCourtAssignment:   {Push: true, Configurable: []NotificationChannel{ChannelPush}},
+	NotificationRSVPSummary:       {Email: true, Configurable: pushAndEmail}, // admins and organizers
+	NotificationMaybeNudge:        {Inherits: NotificationRSVPDeadline},
+	NotificationMonthlyRecap:      {Email: true, Configurable: pushAndEmail},
+	// Recipients are already filtered by their comment notification level
+	NotificationSessionComment:   {Push: true},
+	NotificationModeration:       mandatory,
+	NotificationRSVPChanged:      mandatory,
+	NotificationIncidentReported: mandatory,
+	NotificationSessionChanged:   mandatory,
+	NotificationAccountLink:      mandatory,
+	NotificationMemberInactive:   mandatory,
+	NotificationCarpool:          mandatory,
+	NotificationLowStock:         mandatory,
+	NotificationEmailReply:       mandatory,
+	NotificationWeatherProposal:  mandatory,
+	NotificationContactFollowUp:  mandatory,
+}
+
+// NotificationTypePreference is a member's choice for one notification type
+// on one channel. Only choices are stored; everything else is the default.
+type NotificationTypePreference struct {
+	ID        uuid.UUID           `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID           `gorm:"type:uuid;not null;uniqueIndex:idx_notification_type_pref" json:"user_id"`
+	Type      NotificationType    `gorm:"type:text;not null;uniqueIndex:idx_notification_type_pref" json:"type"`
+	Channel   NotificationChannel `gorm:"size:10;not null;uniqueIndex:idx_notification_type_pref" json:"channel"`
+	Enabled   bool                `gorm:"not null" json:"enabled"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+func (p *NotificationTypePreference) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OrderWindowStatus string
+
+const (
+	OrderWindowOpen   OrderWindowStatus = "open"
+	OrderWindowClosed OrderWindowStatus = "closed"
+)
+
+// OrderWindow is a group order, such as club shirts or a box of shuttles,
+// that members can add to until it closes
+type OrderWindow struct {
+	ID          uuid.UUID         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Title       string            `gorm:"size:255;not null" json:"title"`
+	Description string            `gorm:"type:text" json:"description"`
+	Status      OrderWindowStatus `gorm:"size:50;not null;default:'open';index" json:"status"`
+	ClosesAt    time.Time         `gorm:"not null" json:"closes_at"`
+	ClosedAt    *time.Time        `json:"closed_at,omitempty"`
+	CreatedBy   uuid.UUID         `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+
+	// Association
+	Items []OrderItem `gorm:"foreignKey:WindowID" json:"items,omitempty"`
+}
+
+func (w *OrderWindow) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsOpen reports whether members can still order at now
+func (w *OrderWindow) IsOpen(now time.Time) bool {
+	return w.Status == OrderWindowOpen && now.Before(w.ClosesAt)
+}
+
+// OrderItem is something on offer in an order window. Members pick one of
+// its options, such as a shirt size, on each line they order.
+type OrderItem struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	WindowID   uuid.UUID `gorm:"type:uuid;not null;index" json:"window_id"`
+	Name       string    `gorm:"size:255;not null" json:"name"`
+	Options    []string  `gorm:"type:text;serializer:json" json:"options"`
+	PriceCents int       `gorm:"not null" json:"price_cents"`
+	Position   int       `gorm:"not null;default:0" json:"position"`
+}
+
+func (i *OrderItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// Order is a member's order in a window; each member has at most one, which
+// they can change until the window closes
+type Order struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	WindowID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_order_window_user" json:"window_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_order_window_user" json:"user_id"`
+	Notes     string    `gorm:"type:text" json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Associations
+	Lines []OrderLine `gorm:"foreignKey:OrderID" json:"lines"`
+	User  *User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (o *Order) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+// OrderLine is a quantity of one item and option in an order
+type OrderLine struct {
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrderID  uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`
+	ItemID   uuid.UUID `gorm:"type:uuid;not null" json:"item_id"`
+	Option   string    `gorm:"size:100" json:"option"`
+	Quantity int       `gorm:"not null" json:"quantity"`
+}
+
+func (l *OrderLine) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
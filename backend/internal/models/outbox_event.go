@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OutboxEvent is the delivery of a domain event, written in the same
+// transaction as the event so the notifications and webhooks it triggers go
+// out even if the server stops straight after the commit. Delivery is at
+// least once.
+type OutboxEvent struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	EventID        uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"event_id"`
+	Notifications  string     `gorm:"type:jsonb;not null;default:'[]'" json:"-"` // batches for the notification pipeline
+	NotifiedAt     *time.Time `json:"notified_at"`                               // notifications handed to SendBatch
+	WebhooksSentAt *time.Time `json:"webhooks_sent_at"`                          // every webhook answered 2xx
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`        // dispatches started, including the current one
+	LastError      string     `json:"last_error,omitempty"`                      // from the latest failed attempt
+	DispatchAt     time.Time  `gorm:"not null;index" json:"dispatch_at"`         // next attempt, or when a claim lapses
+	DispatchedAt   *time.Time `gorm:"index" json:"dispatched_at"`                // fully delivered
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// Associations
+	Event *DomainEvent `gorm:"foreignKey:EventID;references:ID" json:"event,omitempty"`
+}
+
+func (e *OutboxEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
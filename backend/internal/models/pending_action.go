@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PendingActionType string
+
+const (
+	PendingActionDeleteSession   PendingActionType = "delete_session"
+	PendingActionRejectMember    PendingActionType = "reject_member"
+	PendingActionChangeAdminRole PendingActionType = "change_admin_role"
+)
+
+type PendingActionStatus string
+
+const (
+	PendingActionStatusPending  PendingActionStatus = "pending"
+	PendingActionStatusApproved PendingActionStatus = "approved"
+	PendingActionStatusDeclined PendingActionStatus = "declined"
+)
+
+// PendingAction is a destructive admin action waiting for a second admin's approval
+type PendingAction struct {
+	ID          uuid.UUID           `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ActionType  PendingActionType   `gorm:"size:50;not null;index:idx_pending_action_target" json:"action_type"`
+	TargetID    uuid.UUID           `gorm:"type:uuid;not null;index:idx_pending_action_target" json:"target_id"`
+	Payload     string              `gorm:"size:255" json:"payload,omitempty"` // e.g. the new role
+	Status      PendingActionStatus `gorm:"size:50;not null;default:'pending';index" json:"status"`
+	RequestedBy uuid.UUID           `gorm:"type:uuid;not null" json:"requested_by"`
+	ReviewedBy  *uuid.UUID          `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time          `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+
+	// Associations
+	Requester *User `gorm:"foreignKey:RequestedBy" json:"-"`
+}
+
+func (a *PendingAction) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PendingSessionChange is a member's session_changed notice waiting out the
+// debounce window, so several quick edits reach them as one message
+type PendingSessionChange struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_pending_change_user_session" json:"user_id"`
+	SessionID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_pending_change_user_session" json:"session_id"`
+	Changes    string    `gorm:"type:jsonb;not null" json:"changes"` // each changed field with its value before the first edit and after the latest
+	DispatchAt time.Time `gorm:"not null;index" json:"dispatch_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (p *PendingSessionChange) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
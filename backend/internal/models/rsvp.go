@@ -13,6 +13,15 @@ const (
 	RSVPStatusIn    RSVPStatus = "in"
 	RSVPStatusOut   RSVPStatus = "out"
 	RSVPStatusMaybe RSVPStatus = "maybe"
+
+	// Sessions that require approval hold members' IN RSVPs as requested
+	// until an admin approves (IN) or declines them
+	RSVPStatusRequested RSVPStatus = "requested"
+	RSVPStatusDeclined  RSVPStatus = "declined"
+
+	// An IN RSVP to a full session waits in line, with a WaitlistEntry,
+	// until a spot is offered and the member confirms it
+	RSVPStatusWaitlisted RSVPStatus = "waitlisted"
 )
 
 type RSVP struct {
@@ -23,6 +32,8 @@ type RSVP struct {
 	RSVPTimestamp time.Time  `gorm:"not null;default:now()" json:"rsvp_timestamp"`
 	IsLateRSVP    bool       `gorm:"default:false" json:"is_late_rsvp"`
 	AddedByAdmin  bool       `gorm:"default:false" json:"added_by_admin"`
+	AsRegular     bool       `gorm:"default:false" json:"as_regular"` // put IN as one of the series' regulars
+	CheckedInAt   *time.Time `json:"checked_in_at,omitempty"`         // arrival at the venue
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
 
@@ -40,3 +51,125 @@ func (r *RSVP) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// AfterSave logs status changes made through a loaded RSVP and recounts the
+// session's RSVPs. Bulk updates that don't load one call RecordRSVPEvent and
+// RefreshRSVPCounts themselves.
+func (r *RSVP) AfterSave(tx *gorm.DB) error {
+	if r.ID == uuid.Nil || r.SessionID == uuid.Nil || r.UserID == uuid.Nil {
+		return nil
+	}
+	if err := RecordRSVPEvent(tx, r.SessionID, r.UserID, r.Status); err != nil {
+		return err
+	}
+	return RefreshRSVPCounts(tx, r.SessionID)
+}
+
+func (r *RSVP) AfterDelete(tx *gorm.DB) error {
+	if err := recordTombstone(tx, "rsvp", r.ID); err != nil {
+		return err
+	}
+	if r.ID == uuid.Nil || r.SessionID == uuid.Nil || r.UserID == uuid.Nil {
+		return nil
+	}
+	if err := RecordRSVPEvent(tx, r.SessionID, r.UserID, ""); err != nil {
+		return err
+	}
+	return RefreshRSVPCounts(tx, r.SessionID)
+}
+
+// RSVPCounts are a session's RSVPs by status, archived ones included, and
+// its guests, kept on the session row so summaries don't count RSVPs on every
+// read. They're only ever written by RefreshRSVPCounts, never by saving a
+// session.
+type RSVPCounts struct {
+	ConfirmedCount int `gorm:"->;not null;default:0" json:"confirmed_count"`
+	MaybeCount     int `gorm:"->;not null;default:0" json:"maybe_count"`
+	OutCount       int `gorm:"->;not null;default:0" json:"out_count"`
+	RequestedCount int `gorm:"->;not null;default:0" json:"requested_count"` // awaiting approval
+	WaitlistCount  int `gorm:"->;not null;default:0" json:"waitlist_count"`
+	GuestCount     int `gorm:"->;not null;default:0" json:"guest_count"` // each takes a spot too
+}
+
+// guestTally stands in for a guest's status when guests are counted with RSVPs
+const guestTally = "guest"
+
+// RSVPTallies counts RSVPs by status, and guests, for each session that has
+// any, with its session_id and the columns of RSVPCounts
+func RSVPTallies(db *gorm.DB) *gorm.DB {
+	return db.Table(`(SELECT session_id, status FROM rsvps
+			UNION ALL SELECT session_id, status FROM rsvps_archive
+			UNION ALL SELECT session_id, ? FROM guest_rsvps) r`, guestTally).
+		Select(`session_id,
+			COUNT(*) FILTER (WHERE status = ?) AS confirmed_count,
+			COUNT(*) FILTER (WHERE status = ?) AS maybe_count,
+			COUNT(*) FILTER (WHERE status = ?) AS out_count,
+			COUNT(*) FILTER (WHERE status = ?) AS requested_count,
+			COUNT(*) FILTER (WHERE status = ?) AS waitlist_count,
+			COUNT(*) FILTER (WHERE status = ?) AS guest_count`,
+			RSVPStatusIn, RSVPStatusMaybe, RSVPStatusOut, RSVPStatusRequested, RSVPStatusWaitlisted, guestTally).
+		Group("session_id")
+}
+
+// TallyRSVPs counts a session's RSVPs by status, as RSVPCounts should hold them
+func TallyRSVPs(db *gorm.DB, sessionID uuid.UUID) (RSVPCounts, error) {
+	var counts RSVPCounts
+	err := RSVPTallies(db).Where("session_id = ?", sessionID).Scan(&counts).Error
+	return counts, err
+}
+
+// RefreshRSVPCounts recounts a session's RSVPs and guests into its counters,
+// in the transaction that changed them. The session row is locked before
+// counting, so the count sees every RSVP change committed ahead of this one
+// and concurrent changes can't leave a stale count behind.
+func RefreshRSVPCounts(tx *gorm.DB, sessionID uuid.UUID) error {
+	if err := tx.Exec(`SELECT id FROM sessions WHERE id = ? FOR UPDATE`, sessionID).Error; err != nil {
+		return err
+	}
+	counts, err := TallyRSVPs(tx, sessionID)
+	if err != nil {
+		return err
+	}
+	return tx.Exec(`UPDATE sessions SET confirmed_count = ?, maybe_count = ?, out_count = ?,
+		requested_count = ?, waitlist_count = ?, guest_count = ? WHERE id = ?`,
+		counts.ConfirmedCount, counts.MaybeCount, counts.OutCount,
+		counts.RequestedCount, counts.WaitlistCount, counts.GuestCount, sessionID).Error
+}
+
+// RSVPEvent is one change to a member's RSVP for a session, kept so the
+// session's RSVP history can be replayed. Status is empty when the RSVP was
+// removed.
+type RSVPEvent struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID  uuid.UUID  `gorm:"type:uuid;not null;index:idx_rsvp_event_session" json:"session_id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	Status     RSVPStatus `gorm:"size:50" json:"status"`
+	OccurredAt time.Time  `gorm:"not null;index:idx_rsvp_event_session" json:"occurred_at"`
+}
+
+func (e *RSVPEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// RecordRSVPEvent logs a member's RSVP status for a session, unless it's the
+// status last logged for them
+func RecordRSVPEvent(tx *gorm.DB, sessionID, userID uuid.UUID, status RSVPStatus) error {
+	var last RSVPEvent
+	err := tx.Where("session_id = ? AND user_id = ?", sessionID, userID).
+		Order("occurred_at DESC").Limit(1).Find(&last).Error
+	if err != nil {
+		return err
+	}
+	if last.ID != uuid.Nil && last.Status == status {
+		return nil
+	}
+	return tx.Create(&RSVPEvent{
+		SessionID:  sessionID,
+		UserID:     userID,
+		Status:     status,
+		OccurredAt: time.Now(),
+	}).Error
+}
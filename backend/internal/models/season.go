@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SeasonRollover is a season of recurring sessions generated in one go from
+// the club's recurring series, so members can see and plan for the whole
+// season. Undoing it deletes the sessions it made that nobody has RSVP'd to.
+type SeasonRollover struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Name            string     `gorm:"size:255;not null" json:"name"`
+	StartDate       time.Time  `gorm:"type:date;not null" json:"start_date"`
+	EndDate         time.Time  `gorm:"type:date;not null" json:"end_date"`
+	SessionsCreated int        `gorm:"not null;default:0" json:"sessions_created"`
+	CreatedBy       uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UndoneAt        *time.Time `json:"undone_at,omitempty"`
+	UndoneBy        *uuid.UUID `gorm:"type:uuid" json:"undone_by,omitempty"`
+
+	// Associations
+	Blackouts []BlackoutDate `gorm:"foreignKey:RolloverID" json:"blackouts,omitempty"`
+	Creator   *User          `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+}
+
+func (r *SeasonRollover) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BlackoutDate is a day in a season with no recurring sessions, such as a
+// public holiday or the venue being closed. The nightly top-up of recurring
+// series skips it too, until its rollover is undone.
+type BlackoutDate struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	RolloverID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_blackout_rollover_date" json:"rollover_id"`
+	Date       time.Time `gorm:"type:date;not null;uniqueIndex:idx_blackout_rollover_date;index" json:"date"`
+	Reason     string    `gorm:"size:255" json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (b *BlackoutDate) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
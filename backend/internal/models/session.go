@@ -15,50 +15,147 @@ const (
 	SessionStatusCancelled SessionStatus = "cancelled"
 )
 
+type SessionType string
+
+const (
+	SessionTypeSocial   SessionType = "social"
+	SessionTypeTraining SessionType = "training" // run by a coach, for a set number of spots
+)
+
 type Session struct {
 	ID                 uuid.UUID     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	Title              string        `gorm:"size:255;not null" json:"title"`
 	Description        string        `gorm:"type:text" json:"description"`
-	SessionDate        time.Time     `gorm:"type:date;not null" json:"session_date"`
-	StartTime          string        `gorm:"size:10;not null" json:"start_time"` // HH:MM format
-	EndTime            string        `gorm:"size:10;not null" json:"end_time"`   // HH:MM format
-	Courts             int           `gorm:"not null;check:courts >= 1 AND courts <= 3" json:"courts"`
+	SessionDate        time.Time     `gorm:"type:date;not null" json:"session_date"` // StartsAt's day in Sydney, for date queries
+	StartsAt           time.Time     `gorm:"type:timestamptz;not null;index" json:"starts_at"`
+	EndsAt             time.Time     `gorm:"type:timestamptz;not null" json:"ends_at"`
+	Courts             int           `gorm:"not null;check:chk_sessions_courts_min,courts >= 1" json:"courts"`
 	MaxPlayers         int           `gorm:"not null" json:"max_players"`
+	Overbooking        int           `gorm:"not null;default:0" json:"overbooking"` // IN RSVPs taken past MaxPlayers until RSVPs close, for the members expected to drop out
+	OverbookSettledAt  *time.Time    `json:"-"`                                     // when INs still past MaxPlayers as RSVPs closed went back to the waitlist
 	RSVPDeadline       time.Time     `gorm:"not null" json:"rsvp_deadline"`
 	IsRecurring        bool          `gorm:"default:false" json:"is_recurring"`
 	RecurringDayOfWeek *int          `json:"recurring_day_of_week"` // 0=Sunday, 1=Monday, etc.
 	RecurringParentID  *uuid.UUID    `gorm:"type:uuid" json:"recurring_parent_id"`
+	SeasonRolloverID   *uuid.UUID    `gorm:"type:uuid;index" json:"season_rollover_id,omitempty"` // set on sessions a season rollover scheduled
 	Status             SessionStatus `gorm:"size:50;default:'open'" json:"status"`
+	IsOutdoor          bool          `gorm:"default:false" json:"is_outdoor"`        // reminders include a weather forecast
+	RequiresApproval   bool          `gorm:"default:false" json:"requires_approval"` // members' RSVPs wait for an admin to confirm them
+	FairShare          bool          `gorm:"default:false" json:"fair_share"`        // spots are allocated automatically when RSVPs close
+	AllowGuests        bool          `gorm:"default:false" json:"allow_guests"`      // members who are IN can bring a guest
+	SessionType        SessionType   `gorm:"size:50;not null;default:'social'" json:"session_type"`
+	CoachID            *uuid.UUID    `gorm:"type:uuid;index" json:"coach_id,omitempty"`   // training sessions only
+	CurriculumNotes    string        `gorm:"type:text" json:"curriculum_notes,omitempty"` // what a training session covers
+	AllocatedAt        *time.Time    `json:"allocated_at,omitempty"`
+	RSVPSummarySentAt  *time.Time    `json:"-"` // when admins were sent the summary as RSVPs closed
+	MaybeNudgeSentAt   *time.Time    `json:"-"` // when MAYBE RSVPs were nudged to commit
 	CancellationReason string        `gorm:"type:text" json:"cancellation_reason,omitempty"`
+	ShuttlesUsed       *int          `json:"shuttles_used,omitempty"`
+	ActualStartAt      *time.Time    `json:"actual_start_at,omitempty"`
+	ActualEndAt        *time.Time    `json:"actual_end_at,omitempty"`
+	AdminNotes         string        `gorm:"type:text" json:"-"` // admin-only, served by its own endpoint
 	CreatedBy          uuid.UUID     `gorm:"type:uuid" json:"created_by"`
 	CreatedAt          time.Time     `json:"created_at"`
 	UpdatedAt          time.Time     `json:"updated_at"`
+	RSVPCounts
 
 	// Associations
-	RSVPs   []RSVP `gorm:"foreignKey:SessionID" json:"rsvps,omitempty"`
-	Creator *User  `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+	RSVPs       []RSVP              `gorm:"foreignKey:SessionID" json:"rsvps,omitempty"`
+	Creator     *User               `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+	Coach       *User               `gorm:"foreignKey:CoachID" json:"coach,omitempty"`
+	Attachments []SessionAttachment `gorm:"foreignKey:SessionID" json:"attachments,omitempty"`
 }
 
 func (s *Session) BeforeCreate(tx *gorm.DB) error {
 	if s.ID == uuid.Nil {
 		s.ID = uuid.New()
 	}
-	s.MaxPlayers = MaxPlayersForCourts(s.Courts)
+	if s.SessionType == "" {
+		s.SessionType = SessionTypeSocial
+	}
+	if s.MaxPlayers == 0 {
+		s.MaxPlayers = MaxPlayersForCourts(s.Courts)
+	}
 	return nil
 }
 
-// MaxPlayersForCourts returns the maximum number of players based on court count
+func (s *Session) AfterDelete(tx *gorm.DB) error {
+	return recordTombstone(tx, "session", s.ID)
+}
+
+// RSVPCapacity is how many members can be IN: MaxPlayers, plus the
+// overbooking margin until RSVPs close
+func (s *Session) RSVPCapacity() int {
+	if s.Overbooking > 0 && time.Now().Before(s.RSVPDeadline) {
+		return s.MaxPlayers + s.Overbooking
+	}
+	return s.MaxPlayers
+}
+
+// SessionAttachment is a file for a session, such as a court map or a
+// tournament draw, held in object storage. Only approved members see it.
+type SessionAttachment struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID   uuid.UUID `gorm:"type:uuid;not null;index" json:"session_id"`
+	FileName    string    `gorm:"size:255;not null" json:"file_name"`
+	ContentType string    `gorm:"size:100;not null" json:"content_type"`
+	SizeBytes   int64     `gorm:"not null" json:"size_bytes"`
+	StorageKey  string    `gorm:"size:255;not null" json:"-"`
+	UploadedBy  uuid.UUID `gorm:"type:uuid;not null" json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (a *SessionAttachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// SeriesRegular is a member an admin has down as a regular for a recurring
+// series: each new occurrence starts with them RSVP'd IN, and they opt out
+// of the weeks they can't make. SeriesID is the series' parent session.
+type SeriesRegular struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SeriesID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_series_regular" json:"series_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_series_regular" json:"user_id"`
+	AddedBy   uuid.UUID `gorm:"type:uuid;not null" json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Association
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (r *SeriesRegular) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// MaxCourts bounds how many courts a session can book, to catch typos
+const MaxCourts = 20
+
+// MaxPlayersForCourts returns the default capacity for a court count: the
+// original rotation table, with each court past the third adding six players
+// as the third does. Clubs can set their own formula (Club.MaxPlayersForCourts).
 func MaxPlayersForCourts(courts int) int {
-	switch courts {
-	case 1:
+	switch {
+	case courts <= 1:
 		return 6
-	case 2:
+	case courts == 2:
 		return 10
-	case 3:
-		return 16
 	default:
-		return 6
+		return 16 + (courts-3)*6
+	}
+}
+
+// ActualDurationMinutes returns the recorded playing time, or 0 if not recorded
+func (s *Session) ActualDurationMinutes() int {
+	if s.ActualStartAt == nil || s.ActualEndAt == nil || !s.ActualEndAt.After(*s.ActualStartAt) {
+		return 0
 	}
+	return int(s.ActualEndAt.Sub(*s.ActualStartAt).Minutes())
 }
 
 // IsRSVPOpen returns true if the RSVP deadline has not passed
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tombstone records a hard-deleted row so offline clients can remove it on sync
+type Tombstone struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"-"`
+	EntityType string    `gorm:"size:50;not null" json:"entity_type"`
+	EntityID   uuid.UUID `gorm:"type:uuid;not null" json:"entity_id"`
+	DeletedAt  time.Time `gorm:"not null;index" json:"deleted_at"`
+}
+
+func (t *Tombstone) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if t.DeletedAt.IsZero() {
+		t.DeletedAt = time.Now()
+	}
+	return nil
+}
+
+// recordTombstone is called from AfterDelete hooks; deletes without a loaded ID are skipped
+func recordTombstone(tx *gorm.DB, entityType string, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return nil
+	}
+	return tx.Create(&Tombstone{EntityType: entityType, EntityID: id}).Error
+}
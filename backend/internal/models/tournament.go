@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TournamentFormat string
+
+const (
+	TournamentRoundRobin TournamentFormat = "round_robin"
+	TournamentKnockout   TournamentFormat = "knockout"
+)
+
+type TournamentStatus string
+
+const (
+	TournamentStatusRegistration TournamentStatus = "registration"
+	TournamentStatusInProgress   TournamentStatus = "in_progress"
+	TournamentStatusCompleted    TournamentStatus = "completed"
+)
+
+type MatchStatus string
+
+const (
+	MatchStatusScheduled MatchStatus = "scheduled"
+	MatchStatusCompleted MatchStatus = "completed"
+)
+
+// Tournament is a multi-session competition between registered players
+type Tournament struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	Name        string           `gorm:"size:255;not null" json:"name"`
+	Description string           `gorm:"type:text" json:"description"`
+	Format      TournamentFormat `gorm:"size:50;not null" json:"format"`
+	Status      TournamentStatus `gorm:"size:50;not null;default:'registration'" json:"status"`
+	MaxEntries  int              `gorm:"not null;default:0" json:"max_entries"` // 0 for no limit
+	WinnerID    *uuid.UUID       `gorm:"type:uuid" json:"winner_id,omitempty"`
+	CreatedBy   uuid.UUID        `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+
+	// Associations
+	Entries []TournamentEntry `gorm:"foreignKey:TournamentID" json:"entries,omitempty"`
+	Matches []TournamentMatch `gorm:"foreignKey:TournamentID" json:"matches,omitempty"`
+	Winner  *User             `gorm:"foreignKey:WinnerID" json:"winner,omitempty"`
+}
+
+func (t *Tournament) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// TournamentEntry is a player's registration in a tournament
+type TournamentEntry struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TournamentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tournament_user" json:"tournament_id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tournament_user" json:"user_id"`
+	Seed         int       `gorm:"default:0" json:"seed"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Association
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (e *TournamentEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// TournamentMatch is a single fixture; a nil player slot is a bye or a
+// knockout slot waiting on an earlier result
+type TournamentMatch struct {
+	ID           uuid.UUID   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TournamentID uuid.UUID   `gorm:"type:uuid;not null;index" json:"tournament_id"`
+	Round        int         `gorm:"not null" json:"round"`
+	MatchNumber  int         `gorm:"not null" json:"match_number"`
+	SessionID    *uuid.UUID  `gorm:"type:uuid;index" json:"session_id,omitempty"`
+	Player1ID    *uuid.UUID  `gorm:"type:uuid" json:"player1_id,omitempty"`
+	Player2ID    *uuid.UUID  `gorm:"type:uuid" json:"player2_id,omitempty"`
+	Player1Score *int        `json:"player1_score,omitempty"`
+	Player2Score *int        `json:"player2_score,omitempty"`
+	WinnerID     *uuid.UUID  `gorm:"type:uuid" json:"winner_id,omitempty"`
+	Status       MatchStatus `gorm:"size:50;not null;default:'scheduled'" json:"status"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+
+	// Associations
+	Player1 *User `gorm:"foreignKey:Player1ID" json:"player1,omitempty"`
+	Player2 *User `gorm:"foreignKey:Player2ID" json:"player2,omitempty"`
+}
+
+func (m *TournamentMatch) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Skill assessment scores run from MinSkillScore (just starting) to
+// MaxSkillScore (match ready)
+const (
+	MinSkillScore = 1
+	MaxSkillScore = 5
+)
+
+// TrainingProgress is a player's record in the training program, started
+// the first time they attend a training session or are assessed
+type TrainingProgress struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Goals     string    `gorm:"type:text" json:"goals"` // set by coaches
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Associations
+	Attendance  []TrainingAttendance `gorm:"foreignKey:ProgressID" json:"attendance,omitempty"`
+	Assessments []SkillAssessment    `gorm:"foreignKey:ProgressID" json:"assessments,omitempty"`
+}
+
+func (p *TrainingProgress) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// TrainingAttendance is a player's attendance at a training session
+type TrainingAttendance struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ProgressID uuid.UUID  `gorm:"type:uuid;not null;index" json:"progress_id"`
+	SessionID  uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_training_attendance" json:"session_id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_training_attendance" json:"user_id"`
+	RecordedBy *uuid.UUID `gorm:"type:uuid" json:"recorded_by,omitempty"` // nil when it came from a check-in
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Association
+	Session *Session `gorm:"foreignKey:SessionID" json:"session,omitempty"`
+}
+
+func (a *TrainingAttendance) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// SkillAssessment is a coach's score for one of a player's skills at a point
+// in time; a skill's assessments over time show the player's progress
+type SkillAssessment struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	ProgressID uuid.UUID  `gorm:"type:uuid;not null;index" json:"progress_id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index:idx_skill_assessment_user_skill" json:"user_id"`
+	Skill      string     `gorm:"size:100;not null;index:idx_skill_assessment_user_skill" json:"skill"` // lower case, such as "footwork"
+	Score      int        `gorm:"not null" json:"score"`
+	Notes      string     `gorm:"type:text" json:"notes"`
+	SessionID  *uuid.UUID `gorm:"type:uuid" json:"session_id,omitempty"` // the training session it was made at, if any
+	AssessedBy uuid.UUID  `gorm:"type:uuid;not null" json:"assessed_by"`
+	AssessedAt time.Time  `gorm:"not null" json:"assessed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (a *SkillAssessment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
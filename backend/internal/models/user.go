@@ -12,6 +12,7 @@ type UserRole string
 const (
 	RolePending UserRole = "pending"
 	RolePlayer  UserRole = "player"
+	RoleCoach   UserRole = "coach" // a player who also runs training sessions
 	RoleAdmin   UserRole = "admin"
 )
 
@@ -21,20 +22,149 @@ const (
 	MembershipPending  MembershipStatus = "pending"
 	MembershipApproved MembershipStatus = "approved"
 	MembershipRejected MembershipStatus = "rejected"
+	MembershipArchived MembershipStatus = "archived" // inactive; off the member list and reminders until they return
 )
 
+// CommitteeRole is a member's office on the club committee, if any. The
+// president, treasurer and secretary are each held by one member at a time;
+// any number can sit as ordinary committee members.
+type CommitteeRole string
+
+const (
+	CommitteeNone      CommitteeRole = ""
+	CommitteePresident CommitteeRole = "president"
+	CommitteeTreasurer CommitteeRole = "treasurer"
+	CommitteeSecretary CommitteeRole = "secretary"
+	CommitteeMember    CommitteeRole = "member"
+)
+
+// IsValid reports whether r is a known committee role, or none
+func (r CommitteeRole) IsValid() bool {
+	switch r {
+	case CommitteeNone, CommitteePresident, CommitteeTreasurer, CommitteeSecretary, CommitteeMember:
+		return true
+	}
+	return false
+}
+
+// IsOffice reports whether only one member can hold r at a time
+func (r CommitteeRole) IsOffice() bool {
+	return r == CommitteePresident || r == CommitteeTreasurer || r == CommitteeSecretary
+}
+
+// MembershipTier sets how many sessions a member can RSVP in to each week
+type MembershipTier string
+
+const (
+	TierRegular    MembershipTier = "regular"      // one session a week
+	TierTwiceAWeek MembershipTier = "twice_a_week" // two sessions a week
+	TierUnlimited  MembershipTier = "unlimited"
+)
+
+// WeeklyRSVPQuota returns how many sessions a week the tier allows, or 0 for no limit
+func (t MembershipTier) WeeklyRSVPQuota() int {
+	switch t {
+	case TierRegular:
+		return 1
+	case TierTwiceAWeek:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// IsValid reports whether t is a known tier
+func (t MembershipTier) IsValid() bool {
+	switch t {
+	case TierRegular, TierTwiceAWeek, TierUnlimited:
+		return true
+	}
+	return false
+}
+
+// Language is a language notifications are written in, as a two-letter code
+type Language string
+
+const (
+	LanguageEnglish Language = "en"
+	LanguageChinese Language = "zh"
+)
+
+// DefaultLanguage is what members get until they choose, and what any
+// message not yet translated is sent in
+const DefaultLanguage = LanguageEnglish
+
+// SupportedLanguages are the languages members can choose, in the order
+// they're offered
+var SupportedLanguages = []Language{LanguageEnglish, LanguageChinese}
+
+// IsValid reports whether l is a supported language
+func (l Language) IsValid() bool {
+	for _, supported := range SupportedLanguages {
+		if l == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Fallbacks lists the languages to try for a message, best first: l itself,
+// then the default
+func (l Language) Fallbacks() []Language {
+	if l == DefaultLanguage || !l.IsValid() {
+		return []Language{DefaultLanguage}
+	}
+	return []Language{l, DefaultLanguage}
+}
+
+// User is a club member. The login ID and contact details are left out of
+// its JSON; responses go through dto.User, which shows them only to the
+// member and admins.
 type User struct {
 	ID               uuid.UUID        `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	Auth0ID          string           `gorm:"size:255;uniqueIndex;not null" json:"auth0_id"`
-	Email            string           `gorm:"size:255;uniqueIndex;not null" json:"email"`
+	Auth0ID          string           `gorm:"size:255;uniqueIndex;not null" json:"-"`
+	Email            string           `gorm:"size:255;uniqueIndex;not null" json:"-"`
 	Name             string           `gorm:"size:255;not null" json:"name"`
-	ProfilePicture   string           `gorm:"type:text" json:"profile_picture"`
-	PhoneNumber      string           `gorm:"size:50" json:"phone_number"`
+	ProfilePicture   string           `gorm:"type:text" json:"profile_picture"`        // from Auth0, refreshed at each sign-in
+	AvatarID         *uuid.UUID       `gorm:"type:uuid;uniqueIndex" json:"avatar_id"`  // the photo the member uploaded, if any
+	PhoneNumber      string           `gorm:"type:text;serializer:encrypted" json:"-"` // encrypted at rest
 	Role             UserRole         `gorm:"size:50;not null;default:'pending'" json:"role"`
 	IsPlayer         bool             `gorm:"default:true" json:"is_player"`
 	MembershipStatus MembershipStatus `gorm:"size:50;default:'pending'" json:"membership_status"`
-	CreatedAt        time.Time        `json:"created_at"`
-	UpdatedAt        time.Time        `json:"updated_at"`
+	Tier             MembershipTier   `gorm:"size:50;not null;default:'unlimited'" json:"tier"`
+	CommitteeRole    CommitteeRole    `gorm:"size:20;not null;default:'';index" json:"committee_role,omitempty"`
+
+	// The language notifications and emails are sent in
+	PreferredLanguage Language `gorm:"size:10;not null;default:'en'" json:"preferred_language"`
+
+	// Emergency details, encrypted at rest and shown only on the printed
+	// session sheet for admins and the session's organizer
+	EmergencyContactName  string `gorm:"type:text;serializer:encrypted" json:"-"`
+	EmergencyContactPhone string `gorm:"type:text;serializer:encrypted" json:"-"`
+	MedicalNotes          string `gorm:"type:text;serializer:encrypted" json:"-"`
+
+	Privacy PrivacySettings `gorm:"embedded" json:"privacy"`
+
+	// Inactivity: when the member was told they'd stopped playing, when an
+	// admin last chose to keep them anyway (inactivity counts from then), and
+	// when they were archived
+	InactiveNotifiedAt *time.Time `gorm:"index" json:"inactive_notified_at,omitempty"`
+	KeptActiveAt       *time.Time `json:"kept_active_at,omitempty"`
+	ArchivedAt         *time.Time `json:"archived_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Associations
+	Badges []UserBadge `gorm:"foreignKey:UserID" json:"badges,omitempty"`
+}
+
+// PrivacySettings are what a member has chosen to keep from other members.
+// Admins and the member themselves still see everything.
+type PrivacySettings struct {
+	HideFromWaitlist     bool `gorm:"not null;default:false" json:"hide_from_waitlist"`     // name left off session waitlists
+	HideFromLeaderboards bool `gorm:"not null;default:false" json:"hide_from_leaderboards"` // name left off tournament standings
+	HideAttendance       bool `gorm:"not null;default:false" json:"hide_attendance"`        // check-ins and attendance badges hidden
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
@@ -51,3 +181,14 @@ func (u *User) IsApproved() bool {
 func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
+
+// IsCoach reports whether the user can run training sessions; admins can too
+func (u *User) IsCoach() bool {
+	return u.Role == RoleCoach || u.Role == RoleAdmin
+}
+
+// IsCommittee reports whether the user can see committee minutes, documents
+// and announcements; admins can too
+func (u *User) IsCommittee() bool {
+	return u.CommitteeRole != CommitteeNone || u.Role == RoleAdmin
+}
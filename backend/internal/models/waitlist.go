@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WaitlistEntry is a member's place in line for a full session; their RSVP
+// is waitlisted meanwhile. Positions run from 1 with no gaps. When a spot
+// opens the first member without an offer is offered it, and the spot is
+// held for them until OfferExpiresAt.
+type WaitlistEntry struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SessionID      uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_waitlist_session_user;index:idx_waitlist_position" json:"session_id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_waitlist_session_user" json:"user_id"`
+	Position       int        `gorm:"not null;index:idx_waitlist_position" json:"position"`
+	OfferedAt      *time.Time `json:"offered_at,omitempty"`
+	OfferExpiresAt *time.Time `gorm:"index" json:"offer_expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// Associations
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (w *WaitlistEntry) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
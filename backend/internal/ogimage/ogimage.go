@@ -0,0 +1,119 @@
+// Package ogimage draws the preview images that chat apps show for shared
+// session links: a few lines of large text on the club's colours, at the
+// 1200×630 size Open Graph and Twitter cards expect. Text is drawn with a
+// built-in bitmap font, so rendering needs no font files.
+package ogimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"unicode"
+)
+
+// Width and Height are the image size in pixels
+const (
+	Width  = 1200
+	Height = 630
+)
+
+// margin is the space left around the text
+const margin = 72
+
+var (
+	background = color.RGBA{0x08, 0x91, 0xb2, 0xff} // primary-600
+	band       = color.RGBA{0x15, 0x5e, 0x75, 0xff} // primary-800
+	headline   = color.RGBA{0xfb, 0xbf, 0x24, 0xff} // secondary-400
+	text       = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	muted      = color.RGBA{0xcf, 0xfa, 0xfe, 0xff} // primary-100
+)
+
+// Card is the text on a preview image
+type Card struct {
+	Headline string   // largest, such as "3 spots left"
+	Lines    []string // the session's title, date, time and venue
+	Footer   string   // the club's name, along the bottom
+}
+
+// Render draws card as a PNG. Text too long for the image is cut short.
+func Render(card Card) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{background}, image.Point{}, draw.Src)
+
+	const bandHeight = 96
+	draw.Draw(img, image.Rect(0, Height-bandHeight, Width, Height), &image.Uniform{band}, image.Point{}, draw.Src)
+
+	y := margin
+	if card.Headline != "" {
+		y += drawLine(img, card.Headline, margin, y, 16, 8, headline) + 40
+	}
+	for _, line := range card.Lines {
+		if y > Height-bandHeight-margin {
+			break
+		}
+		y += drawLine(img, line, margin, y, 7, 4, text) + 24
+	}
+	if card.Footer != "" {
+		drawLine(img, card.Footer, margin, Height-bandHeight+(bandHeight-7*5)/2, 5, 4, muted)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLine draws s at (x, y) at the largest scale up to maxScale that fits
+// the width, or at minScale cut short, and returns the height drawn
+func drawLine(img *image.RGBA, s string, x, y, maxScale, minScale int, c color.Color) int {
+	runes := []rune(strings.ToUpper(strings.Join(strings.Fields(s), " ")))
+	available := Width - x - margin
+
+	scale := maxScale
+	for scale > minScale && textWidth(len(runes), scale) > available {
+		scale--
+	}
+	if textWidth(len(runes), scale) > available {
+		fit := (available/scale + 1) / (glyphWidth + 1)
+		runes = append(runes[:fit-3], '.', '.', '.')
+	}
+
+	fill := &image.Uniform{c}
+	for i, r := range runes {
+		glyph, ok := glyphFor(r)
+		if !ok {
+			continue
+		}
+		left := x + i*(glyphWidth+1)*scale
+		for row, bits := range glyph {
+			for col, bit := range bits {
+				if bit != '#' {
+					continue
+				}
+				px := image.Rect(left+col*scale, y+row*scale, left+(col+1)*scale, y+(row+1)*scale)
+				draw.Draw(img, px, fill, image.Point{}, draw.Src)
+			}
+		}
+	}
+	return glyphHeight * scale
+}
+
+// textWidth is the width in pixels of n glyphs at scale
+func textWidth(n, scale int) int {
+	if n == 0 {
+		return 0
+	}
+	return (n*(glyphWidth+1) - 1) * scale
+}
+
+func glyphFor(r rune) ([glyphHeight]string, bool) {
+	if alias, ok := glyphAliases[r]; ok {
+		r = alias
+	}
+	glyph, ok := glyphs[unicode.ToUpper(r)]
+	return glyph, ok
+}
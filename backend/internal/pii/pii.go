@@ -0,0 +1,218 @@
+// Package pii encrypts personal data at rest with AES-GCM. Fields opt in with
+// the `serializer:encrypted` GORM tag; values are stored as
+// "enc:<key id>:<base64 nonce+ciphertext>" so older keys can still be read
+// after rotation.
+package pii
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+const prefix = "enc:"
+
+type key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+var (
+	mu      sync.RWMutex
+	current *key
+	keys    = map[string]*key{}
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", Serializer{})
+}
+
+// Configure sets the key used for new writes and any older keys still needed
+// to read existing data. Keys are base64-encoded 32-byte values. With no
+// current key, values are stored in plaintext.
+func Configure(currentKey string, oldKeys []string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	current = nil
+	keys = map[string]*key{}
+
+	for _, k := range oldKeys {
+		parsed, err := parseKey(k)
+		if err != nil {
+			return fmt.Errorf("invalid old encryption key: %w", err)
+		}
+		keys[parsed.id] = parsed
+	}
+
+	if currentKey == "" {
+		return nil
+	}
+	parsed, err := parseKey(currentKey)
+	if err != nil {
+		return fmt.Errorf("invalid encryption key: %w", err)
+	}
+	keys[parsed.id] = parsed
+	current = parsed
+	return nil
+}
+
+// SetCurrentKey makes key the one used for new writes, as when it's rotated
+// in the secret manager. Every key used before stays readable until the next
+// Configure, so rows written with the previous key keep decrypting; add it to
+// the old keys before restarting.
+func SetCurrentKey(currentKey string) error {
+	parsed, err := parseKey(currentKey)
+	if err != nil {
+		return fmt.Errorf("invalid encryption key: %w", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	keys[parsed.id] = parsed
+	current = parsed
+	return nil
+}
+
+// Enabled reports whether new values will be encrypted
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current != nil
+}
+
+// CurrentKeyID returns the ID of the key used for new writes
+func CurrentKeyID() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current == nil {
+		return ""
+	}
+	return current.id
+}
+
+func parseKey(encoded string) (*key, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != 32 {
+		return nil, errors.New("key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(raw)
+	return &key{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+// Encrypt encrypts a value with the current key. Empty values and values
+// written without a configured key are returned unchanged.
+func Encrypt(plaintext string) (string, error) {
+	mu.RLock()
+	k := current
+	mu.RUnlock()
+
+	if plaintext == "" || k == nil {
+		return plaintext, nil
+	}
+
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := k.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + k.id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt. Values without the encrypted prefix are treated
+// as legacy plaintext and returned as-is.
+func Decrypt(stored string) (string, error) {
+	if !strings.HasPrefix(stored, prefix) {
+		return stored, nil
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(stored, prefix), ":", 2)
+	if len(parts) != 2 {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	mu.RLock()
+	k, ok := keys[parts[0]]
+	mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("no encryption key with id %s", parts[0])
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < k.aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:k.aead.NonceSize()], sealed[k.aead.NonceSize():]
+	plaintext, err := k.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether a stored value is plaintext or encrypted with an old key
+func NeedsRotation(stored string) bool {
+	id := CurrentKeyID()
+	if stored == "" || id == "" {
+		return false
+	}
+	return !strings.HasPrefix(stored, prefix+id+":")
+}
+
+// Serializer is the GORM serializer for string fields tagged `serializer:encrypted`
+type Serializer struct{}
+
+// Scan decrypts the stored value into the field
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		stored = v
+	case []byte:
+		stored = string(v)
+	default:
+		return fmt.Errorf("unsupported type %T for encrypted field %s", dbValue, field.Name)
+	}
+
+	plaintext, err := Decrypt(stored)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", field.Name, err)
+	}
+	field.ReflectValueOf(ctx, dst).SetString(plaintext)
+	return nil
+}
+
+// Value encrypts the field for storage
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("encrypted field %s must be a string", field.Name)
+	}
+	return Encrypt(plaintext)
+}
@@ -0,0 +1,140 @@
+package pii
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func newKey(t *testing.T) string {
+	t.Helper()
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func configure(t *testing.T, currentKey string, oldKeys ...string) {
+	t.Helper()
+	if err := Configure(currentKey, oldKeys); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	t.Cleanup(func() { Configure("", nil) })
+}
+
+func encrypt(t *testing.T, plaintext string) string {
+	t.Helper()
+	stored, err := Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	return stored
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	configure(t, newKey(t))
+
+	stored := encrypt(t, "0400 000 000")
+	if !strings.HasPrefix(stored, prefix+CurrentKeyID()+":") || strings.Contains(stored, "0400") {
+		t.Fatalf("stored %q, want it encrypted with the current key", stored)
+	}
+	if again := encrypt(t, "0400 000 000"); again == stored {
+		t.Error("encrypting the same value twice gave the same ciphertext")
+	}
+	if got, err := Decrypt(stored); err != nil || got != "0400 000 000" {
+		t.Errorf("Decrypt = %q, %v", got, err)
+	}
+}
+
+func TestWithoutKeyStoresPlaintext(t *testing.T) {
+	configure(t, "")
+
+	if stored := encrypt(t, "Jo"); stored != "Jo" {
+		t.Errorf("stored %q without a key, want plaintext", stored)
+	}
+	if NeedsRotation("Jo") {
+		t.Error("plaintext needs rotation without a key")
+	}
+}
+
+func TestDecryptLegacyPlaintext(t *testing.T) {
+	configure(t, newKey(t))
+
+	if got, err := Decrypt("written before encryption"); err != nil || got != "written before encryption" {
+		t.Errorf("Decrypt = %q, %v; want the plaintext back", got, err)
+	}
+	if !NeedsRotation("written before encryption") {
+		t.Error("plaintext doesn't need rotation once a key is set")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	oldKey, newerKey := newKey(t), newKey(t)
+	configure(t, oldKey)
+	stored := encrypt(t, "secret")
+
+	configure(t, newerKey, oldKey)
+	if got, err := Decrypt(stored); err != nil || got != "secret" {
+		t.Fatalf("Decrypt with the old key kept = %q, %v", got, err)
+	}
+	if !NeedsRotation(stored) {
+		t.Error("value under the old key doesn't need rotation")
+	}
+	if NeedsRotation(encrypt(t, "secret")) {
+		t.Error("value under the current key needs rotation")
+	}
+
+	configure(t, newerKey)
+	if _, err := Decrypt(stored); err == nil {
+		t.Error("decrypted a value after its key was dropped")
+	}
+}
+
+func TestSetCurrentKeyKeepsPreviousReadable(t *testing.T) {
+	configure(t, newKey(t))
+	stored := encrypt(t, "secret")
+
+	if err := SetCurrentKey(newKey(t)); err != nil {
+		t.Fatal(err)
+	}
+	if !NeedsRotation(stored) {
+		t.Error("value under the previous key doesn't need rotation")
+	}
+	if got, err := Decrypt(stored); err != nil || got != "secret" {
+		t.Errorf("Decrypt after SetCurrentKey = %q, %v", got, err)
+	}
+	if err := SetCurrentKey("not a key"); err == nil {
+		t.Error("SetCurrentKey accepted an invalid key")
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	configure(t, newKey(t))
+	stored := encrypt(t, "secret")
+
+	id, sealed, _ := strings.Cut(strings.TrimPrefix(stored, prefix), ":")
+	raw, _ := base64.StdEncoding.DecodeString(sealed)
+	raw[len(raw)-1] ^= 1
+	tampered := prefix + id + ":" + base64.StdEncoding.EncodeToString(raw)
+
+	for _, value := range []string{tampered, prefix + id, prefix + id + ":AAAA"} {
+		if _, err := Decrypt(value); err == nil {
+			t.Errorf("Decrypt(%q) succeeded", value)
+		}
+	}
+}
+
+func TestConfigureRejectsBadKeys(t *testing.T) {
+	short := base64.StdEncoding.EncodeToString(make([]byte, 16))
+	for _, k := range []string{"not base64!", short} {
+		if err := Configure(k, nil); err == nil {
+			t.Errorf("Configure(%q) accepted a bad key", k)
+		}
+		if err := Configure(newKey(t), []string{k}); err == nil {
+			t.Errorf("Configure accepted a bad old key %q", k)
+		}
+	}
+	Configure("", nil)
+}
@@ -0,0 +1,217 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the provider while its breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Policy controls retries and timeouts for calls to an external provider
+type Policy struct {
+	MaxAttempts    int           // Total attempts including the first
+	BaseDelay      time.Duration // Backoff before the second attempt
+	MaxDelay       time.Duration // Upper bound for a single backoff
+	AttemptTimeout time.Duration // Deadline for each individual attempt
+	Budget         time.Duration // Deadline for all attempts combined
+}
+
+// DefaultPolicy suits short synchronous calls made while serving a request
+var DefaultPolicy = Policy{
+	MaxAttempts:    3,
+	BaseDelay:      200 * time.Millisecond,
+	MaxDelay:       2 * time.Second,
+	AttemptTimeout: 5 * time.Second,
+	Budget:         10 * time.Second,
+}
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error as not worth retrying (e.g. a 4xx response).
+// Permanent errors don't count against the circuit breaker.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// Do calls fn until it succeeds, returns a permanent error, the attempts run
+// out, or the budget is spent. Backoff uses full jitter. If breaker is
+// non-nil, calls are short-circuited while it is open.
+func Do(ctx context.Context, policy Policy, breaker *CircuitBreaker, fn func(ctx context.Context) error) error {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.Budget)
+		defer cancel()
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := backoff(policy, attempt)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return lastErr
+			}
+		}
+
+		if breaker != nil {
+			if err := breaker.Allow(); err != nil {
+				if lastErr != nil {
+					return lastErr
+				}
+				return err
+			}
+		}
+
+		attemptCtx := ctx
+		cancel := func() {}
+		if policy.AttemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, policy.AttemptTimeout)
+		}
+		err := fn(attemptCtx)
+		cancel()
+
+		if err == nil {
+			if breaker != nil {
+				breaker.Success()
+			}
+			return nil
+		}
+		if IsPermanent(err) {
+			if breaker != nil {
+				breaker.Success() // the provider is reachable
+			}
+			return errors.Unwrap(err)
+		}
+		if breaker != nil {
+			breaker.Failure()
+		}
+		lastErr = err
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return lastErr
+}
+
+var (
+	jitterMu  sync.Mutex
+	jitterRnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// backoff returns a random delay in [0, min(MaxDelay, BaseDelay*2^(attempt-1))]
+func backoff(policy Policy, attempt int) time.Duration {
+	ceiling := policy.BaseDelay << uint(attempt-1)
+	if policy.MaxDelay > 0 && (ceiling > policy.MaxDelay || ceiling <= 0) {
+		ceiling = policy.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return time.Duration(jitterRnd.Int63n(int64(ceiling) + 1))
+}
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+// CircuitBreaker stops calling a provider after consecutive failures and
+// lets a single trial call through once the cooldown has elapsed
+type CircuitBreaker struct {
+	name             string
+	failureThreshold int
+	cooldown         time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreaker creates a breaker that opens after failureThreshold
+// consecutive failures and stays open for cooldown
+func NewCircuitBreaker(name string, failureThreshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+	}
+}
+
+// Name returns the provider name the breaker guards
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+// Allow returns ErrCircuitOpen if calls should not be attempted right now
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = stateHalfOpen
+		return nil
+	case stateHalfOpen:
+		// Only the trial call is allowed through
+		return ErrCircuitOpen
+	default:
+		return nil
+	}
+}
+
+// Success records a successful call and closes the breaker
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = stateClosed
+	b.failures = 0
+}
+
+// Failure records a failed call, opening the breaker if the threshold is reached
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == stateHalfOpen || b.failures >= b.failureThreshold {
+		b.state = stateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// IsOpen reports whether the breaker is currently rejecting calls
+func (b *CircuitBreaker) IsOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state == stateOpen && time.Since(b.openedAt) < b.cooldown
+}
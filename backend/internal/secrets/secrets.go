@@ -0,0 +1,137 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when a secret does not exist in the backend
+var ErrNotFound = errors.New("secret not found")
+
+// Provider fetches secret values from an external secret manager
+type Provider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+	Name() string
+}
+
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// Cache wraps a Provider, keeping values for a TTL and refreshing them in the
+// background. If a refresh fails the last known value keeps being served.
+// Consumers that hold a secret, like API clients, register with OnChange to
+// pick up a rotated value without a restart.
+type Cache struct {
+	provider Provider
+	ttl      time.Duration
+
+	mu        sync.RWMutex
+	secrets   map[string]cachedSecret
+	listeners map[string][]func(value string)
+
+	stop chan struct{}
+}
+
+func NewCache(provider Provider, ttl time.Duration) *Cache {
+	return &Cache{
+		provider:  provider,
+		ttl:       ttl,
+		secrets:   make(map[string]cachedSecret),
+		listeners: make(map[string][]func(value string)),
+	}
+}
+
+// OnChange calls apply with a secret's new value whenever a fetch finds it
+// has changed since it was last read
+func (c *Cache) OnChange(name string, apply func(value string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners[name] = append(c.listeners[name], apply)
+}
+
+// Get returns a secret, fetching it if it isn't cached or has expired
+func (c *Cache) Get(ctx context.Context, name string) (string, error) {
+	c.mu.RLock()
+	cached, ok := c.secrets[name]
+	c.mu.RUnlock()
+	if ok && time.Since(cached.fetchedAt) < c.ttl {
+		return cached.value, nil
+	}
+
+	value, err := c.provider.GetSecret(ctx, name)
+	if err != nil {
+		if ok {
+			log.Printf("Failed to refresh secret %s from %s, using cached value: %v", name, c.provider.Name(), err)
+			return cached.value, nil
+		}
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.secrets[name] = cachedSecret{value: value, fetchedAt: time.Now()}
+	listeners := c.listeners[name]
+	c.mu.Unlock()
+
+	if ok && cached.value != value {
+		if len(listeners) == 0 {
+			log.Printf("Secret %s changed in %s; restart to apply it", name, c.provider.Name())
+		} else {
+			log.Printf("Secret %s changed in %s, applying it", name, c.provider.Name())
+		}
+		for _, apply := range listeners {
+			apply(value)
+		}
+	}
+	return value, nil
+}
+
+// Refresh re-fetches every cached secret
+func (c *Cache) Refresh(ctx context.Context) {
+	c.mu.Lock()
+	names := make([]string, 0, len(c.secrets))
+	for name, s := range c.secrets {
+		names = append(names, name)
+		// Expire so Get goes to the provider
+		c.secrets[name] = cachedSecret{value: s.value}
+	}
+	c.mu.Unlock()
+
+	for _, name := range names {
+		c.Get(ctx, name)
+	}
+}
+
+// StartRefresh refreshes cached secrets every interval until Stop is called
+func (c *Cache) StartRefresh(interval time.Duration) {
+	if interval <= 0 || c.stop != nil {
+		return
+	}
+	c.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				c.Refresh(ctx)
+				cancel()
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends background refreshing
+func (c *Cache) Stop() {
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
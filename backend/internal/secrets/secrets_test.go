@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeProvider struct {
+	values map[string]string
+	err    error
+}
+
+func (p *fakeProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+	value, ok := p.values[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func TestRefreshAppliesRotatedSecret(t *testing.T) {
+	provider := &fakeProvider{values: map[string]string{"API_KEY": "old"}}
+	cache := NewCache(provider, time.Hour)
+	ctx := context.Background()
+	if _, err := cache.Get(ctx, "API_KEY"); err != nil {
+		t.Fatal(err)
+	}
+
+	var applied []string
+	cache.OnChange("API_KEY", func(value string) { applied = append(applied, value) })
+
+	cache.Refresh(ctx)
+	if len(applied) != 0 {
+		t.Fatalf("applied %v before the secret changed", applied)
+	}
+
+	provider.values["API_KEY"] = "new"
+	cache.Refresh(ctx)
+	if len(applied) != 1 || applied[0] != "new" {
+		t.Fatalf("applied %v, want [new]", applied)
+	}
+}
+
+func TestRefreshKeepsValueOnError(t *testing.T) {
+	provider := &fakeProvider{values: map[string]string{"API_KEY": "old"}}
+	cache := NewCache(provider, time.Hour)
+	ctx := context.Background()
+	if _, err := cache.Get(ctx, "API_KEY"); err != nil {
+		t.Fatal(err)
+	}
+	cache.OnChange("API_KEY", func(value string) { t.Errorf("applied %q after a failed refresh", value) })
+
+	provider.err = errors.New("unavailable")
+	cache.Refresh(ctx)
+	if value, err := cache.Get(ctx, "API_KEY"); err != nil || value != "old" {
+		t.Errorf("Get = %q, %v; want the cached value", value, err)
+	}
+}
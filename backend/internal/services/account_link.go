@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Errors from linking a new login identity to an existing account
+var (
+	ErrAccountLinkRequired = domainError(ErrConflict, "account_link_required", "this email belongs to an existing account; link this login to it")
+	ErrInvalidLinkCode     = domainError(ErrInvalid, "invalid_link_code", "invalid or expired code")
+	ErrLinkIdentityInUse   = domainError(ErrConflict, "link_identity_in_use", "this login already has its own club account and can't be linked")
+)
+
+const (
+	accountLinkCodeTTL        = 15 * time.Minute
+	accountLinkMaxAttempts    = 5 // guesses per code
+	accountLinkResendInterval = time.Minute
+
+	// Guesses per account across all its codes, so asking for a new code
+	// doesn't buy more guesses. Once spent, the account can't be
+	// linked until the window passes.
+	accountLinkMaxFailures   = 10
+	accountLinkFailureWindow = time.Hour
+)
+
+// AccountLinkService lets a member who signs in with a new login (Google to
+// email, say) claim their existing account by proving they own its email,
+// so their RSVPs, badges and history follow them
+type AccountLinkService struct {
+	notificationService *NotificationService
+}
+
+func NewAccountLinkService(notificationService *NotificationService) *AccountLinkService {
+	return &AccountLinkService{notificationService: notificationService}
+}
+
+// RequestLink emails a one-time code to the account with email so auth0ID can
+// claim it. Nothing is sent, and no error returned, when no account has that
+// email, a code went out moments ago or the account is locked after too many
+// wrong codes, so callers can't probe for members.
+func (s *AccountLinkService) RequestLink(ctx context.Context, auth0ID, email string) error {
+	user, err := findUserByEmail(database.DB, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Auth0ID == auth0ID {
+		return nil // already linked
+	}
+
+	var recent int64
+	if err := database.DB.Model(&models.AccountLinkCode{}).
+		Where("user_id = ? AND created_at > ?", user.ID, time.Now().Add(-accountLinkResendInterval)).
+		Count(&recent).Error; err != nil {
+		return err
+	}
+	if recent > 0 {
+		return nil
+	}
+	locked, err := accountLinkLocked(database.DB, user.ID)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return nil
+	}
+
+	code, err := newAccountLinkCode()
+	if err != nil {
+		return err
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the latest code for an account works. Older ones are expired
+		// rather than deleted so their wrong guesses still count.
+		if err := tx.Model(&models.AccountLinkCode{}).
+			Where("user_id = ? AND used_at IS NULL AND expires_at > ?", user.ID, time.Now()).
+			Update("expires_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AccountLinkCode{
+			UserID:    user.ID,
+			Auth0ID:   auth0ID,
+			CodeHash:  hashAccountLinkCode(code),
+			ExpiresAt: time.Now().Add(accountLinkCodeTTL),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.notificationService.SendAccountLinkCode(ctx, user.Email, user.Name, code, user.PreferredLanguage); err != nil {
+		return fmt.Errorf("sending account link code: %w", err)
+	}
+	return nil
+}
+
+// ConfirmLink checks the code emailed to the account with email and, if it
+// matches, moves that account onto auth0ID. Every code is refused while the
+// account is locked after too many wrong ones. Everything keyed by the account
+// stays put; only the login changes. An empty pending account the new login
+// created on first sign-in is removed to free the identity.
+func (s *AccountLinkService) ConfirmLink(auth0ID, email, code string) (*models.User, error) {
+	user, err := findUserByEmail(database.DB, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidLinkCode
+		}
+		return nil, err
+	}
+
+	var link models.AccountLinkCode
+	if err := database.DB.
+		Where("user_id = ? AND auth0_id = ? AND used_at IS NULL AND expires_at > ?", user.ID, auth0ID, time.Now()).
+		First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidLinkCode
+		}
+		return nil, err
+	}
+	if err := countLinkAttempt(user.ID, link.ID); err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAccountLinkCode(strings.TrimSpace(code))), []byte(link.CodeHash)) != 1 {
+		return nil, ErrInvalidLinkCode
+	}
+
+	oldAuth0ID := user.Auth0ID
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Claim the code so it can't be used twice
+		now := time.Now()
+		claim := tx.Model(&models.AccountLinkCode{}).
+			Where("id = ? AND used_at IS NULL", link.ID).
+			Update("used_at", now)
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			return ErrInvalidLinkCode
+		}
+
+		if err := releaseStubAccount(tx, auth0ID); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Updates(map[string]interface{}{"auth0_id": auth0ID, "updated_at": now}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.AuditLog{
+			EntityType: "user",
+			EntityID:   user.ID,
+			Action:     models.AuditActionAccountLinked,
+			ActorID:    user.ID,
+			OldValue:   oldAuth0ID,
+			NewValue:   auth0ID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user.Auth0ID = auth0ID
+	return user, nil
+}
+
+// countLinkAttempt spends one of a code's guesses before it's checked, and
+// refuses once the code or the account has none left. The account is locked
+// while counting so simultaneous guesses can't each see one left, and the
+// count is committed on its own so a wrong guess always sticks.
+func countLinkAttempt(userID, linkID uuid.UUID) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			First(&models.User{}, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		locked, err := accountLinkLocked(tx, userID)
+		if err != nil {
+			return err
+		}
+		if locked {
+			return ErrInvalidLinkCode
+		}
+		counted := tx.Model(&models.AccountLinkCode{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ? AND attempts < ?", linkID, time.Now(), accountLinkMaxAttempts).
+			Update("attempts", gorm.Expr("attempts + 1"))
+		if counted.Error != nil {
+			return counted.Error
+		}
+		if counted.RowsAffected == 0 {
+			return ErrInvalidLinkCode
+		}
+		return nil
+	})
+}
+
+// accountLinkLocked reports whether userID's link codes have had too many
+// wrong guesses within the failure window
+func accountLinkLocked(db *gorm.DB, userID uuid.UUID) (bool, error) {
+	var failures int64
+	if err := db.Model(&models.AccountLinkCode{}).
+		Where("user_id = ? AND created_at > ?", userID, time.Now().Add(-accountLinkFailureWindow)).
+		Select("COALESCE(SUM(attempts), 0)").Scan(&failures).Error; err != nil {
+		return false, err
+	}
+	return failures >= accountLinkMaxFailures, nil
+}
+
+// releaseStubAccount deletes the pending account auth0ID created on first
+// sign-in, if any, so the identity can move onto the account being claimed.
+// Accounts that have been approved or used are left alone.
+func releaseStubAccount(tx *gorm.DB, auth0ID string) error {
+	var stub models.User
+	if err := tx.Where("auth0_id = ?", auth0ID).First(&stub).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if stub.MembershipStatus != models.MembershipPending || stub.Role != models.RolePending {
+		return ErrLinkIdentityInUse
+	}
+
+	var rsvps, comments int64
+	if err := tx.Model(&models.RSVP{}).Where("user_id = ?", stub.ID).Count(&rsvps).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Comment{}).Where("user_id = ?", stub.ID).Count(&comments).Error; err != nil {
+		return err
+	}
+	if rsvps > 0 || comments > 0 {
+		return ErrLinkIdentityInUse
+	}
+
+	for _, model := range []interface{}{
+		&models.UserNotificationPreferences{},
+		&models.NotificationTypePreference{},
+		&models.UserPushToken{},
+		&models.Notification{},
+		&models.PendingSessionChange{},
+		&models.AccountLinkCode{},
+	} {
+		if err := tx.Where("user_id = ?", stub.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Delete(&models.User{}, "id = ?", stub.ID).Error
+}
+
+func findUserByEmail(db *gorm.DB, email string) (*models.User, error) {
+	var user models.User
+	if err := db.Where("LOWER(email) = LOWER(?)", strings.TrimSpace(email)).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// newAccountLinkCode returns a random six-digit code
+func newAccountLinkCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func hashAccountLinkCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+const testLinkCode = "123456"
+
+// newLinkCode stands in for RequestLink, issuing testLinkCode for auth0ID to
+// claim member's account
+func newLinkCode(t *testing.T, member *models.User, auth0ID string, attempts int) *models.AccountLinkCode {
+	t.Helper()
+	link := models.AccountLinkCode{
+		UserID:    member.ID,
+		Auth0ID:   auth0ID,
+		CodeHash:  hashAccountLinkCode(testLinkCode),
+		ExpiresAt: time.Now().Add(accountLinkCodeTTL),
+		Attempts:  attempts,
+	}
+	if err := database.DB.Create(&link).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &link
+}
+
+func TestConfirmLinkMovesLogin(t *testing.T) {
+	testDB(t)
+	member := newMember(t, "Member")
+	newLogin := "google-oauth2|" + uuid.NewString()
+	newLinkCode(t, member, newLogin, 0)
+	service := NewAccountLinkService(nil)
+
+	if _, err := service.ConfirmLink(newLogin, member.Email, "654321"); !errors.Is(err, ErrInvalidLinkCode) {
+		t.Fatalf("wrong code = %v, want ErrInvalidLinkCode", err)
+	}
+	user, err := service.ConfirmLink(newLogin, member.Email, " "+testLinkCode+" ")
+	if err != nil {
+		t.Fatalf("ConfirmLink: %v", err)
+	}
+	if user.ID != member.ID || user.Auth0ID != newLogin {
+		t.Errorf("linked %s to %s, want %s to %s", user.ID, user.Auth0ID, member.ID, newLogin)
+	}
+	if _, err := service.ConfirmLink(newLogin, member.Email, testLinkCode); !errors.Is(err, ErrInvalidLinkCode) {
+		t.Errorf("reusing the code = %v, want ErrInvalidLinkCode", err)
+	}
+}
+
+// TestConfirmLinkConcurrentGuesses makes many wrong guesses at once; no more
+// than a code's allowance may be counted, and the right code is refused after
+func TestConfirmLinkConcurrentGuesses(t *testing.T) {
+	testDB(t)
+	member := newMember(t, "Member")
+	newLogin := "google-oauth2|" + uuid.NewString()
+	link := newLinkCode(t, member, newLogin, 0)
+	service := NewAccountLinkService(nil)
+
+	errs := concurrently(4*accountLinkMaxAttempts, func(i int) error {
+		_, err := service.ConfirmLink(newLogin, member.Email, "000000")
+		return err
+	})
+	for _, err := range errs {
+		if !errors.Is(err, ErrInvalidLinkCode) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	var got models.AccountLinkCode
+	if err := database.DB.First(&got, "id = ?", link.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Attempts != accountLinkMaxAttempts {
+		t.Errorf("%d guesses counted, want %d", got.Attempts, accountLinkMaxAttempts)
+	}
+	if _, err := service.ConfirmLink(newLogin, member.Email, testLinkCode); !errors.Is(err, ErrInvalidLinkCode) {
+		t.Errorf("right code after the guesses ran out = %v, want ErrInvalidLinkCode", err)
+	}
+}
+
+// TestConfirmLinkAccountLocked spends the account's guesses over earlier
+// codes; a new code can't be used until the window passes
+func TestConfirmLinkAccountLocked(t *testing.T) {
+	testDB(t)
+	member := newMember(t, "Member")
+	newLogin := "google-oauth2|" + uuid.NewString()
+	for spent := 0; spent < accountLinkMaxFailures; spent += accountLinkMaxAttempts {
+		expired := newLinkCode(t, member, newLogin, accountLinkMaxAttempts)
+		database.DB.Model(expired).Update("expires_at", time.Now())
+	}
+	newLinkCode(t, member, newLogin, 0)
+
+	if _, err := NewAccountLinkService(nil).ConfirmLink(newLogin, member.Email, testLinkCode); !errors.Is(err, ErrInvalidLinkCode) {
+		t.Errorf("ConfirmLink on a locked account = %v, want ErrInvalidLinkCode", err)
+	}
+}
+
+// TestConfirmLinkKeepsUsedLogin refuses to move a login that already has
+// an account of its own in use
+func TestConfirmLinkKeepsUsedLogin(t *testing.T) {
+	testDB(t)
+	member, other := newMember(t, "Member"), newMember(t, "Other")
+	newLinkCode(t, member, other.Auth0ID, 0)
+
+	if _, err := NewAccountLinkService(nil).ConfirmLink(other.Auth0ID, member.Email, testLinkCode); !errors.Is(err, ErrLinkIdentityInUse) {
+		t.Errorf("linking an approved member's login = %v, want ErrLinkIdentityInUse", err)
+	}
+}
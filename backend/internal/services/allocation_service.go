@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrAlreadyAllocated is returned when a session's spots have already been allocated
+var ErrAlreadyAllocated = domainError(ErrConflict, "already_allocated", "session has already been allocated")
+
+// AllocationService fills fair-share sessions from their requests once RSVPs
+// close, so spots go round instead of to whoever clicks fastest
+type AllocationService struct {
+	rsvpService *RSVPService
+	algorithm   models.AllocationAlgorithm
+	window      time.Duration
+}
+
+// NewAllocationService creates an allocator using algorithm, counting
+// attendance over the windowDays before each session
+func NewAllocationService(rsvpService *RSVPService, algorithm models.AllocationAlgorithm, windowDays int) (*AllocationService, error) {
+	switch algorithm {
+	case models.AllocationFewestRecent, models.AllocationWeightedLottery, models.AllocationFirstCome:
+	default:
+		return nil, fmt.Errorf("unknown allocation algorithm %q", algorithm)
+	}
+	if windowDays < 1 {
+		return nil, errors.New("allocation window must be at least one day")
+	}
+	return &AllocationService{
+		rsvpService: rsvpService,
+		algorithm:   algorithm,
+		window:      time.Duration(windowDays) * 24 * time.Hour,
+	}, nil
+}
+
+// AllocateClosedSessions allocates every open fair-share session whose RSVP
+// deadline has passed and that hasn't been allocated yet
+func (s *AllocationService) AllocateClosedSessions(ctx context.Context) error {
+	var sessions []models.Session
+	if err := database.DB.WithContext(ctx).
+		Where("fair_share = ? AND status = ? AND allocated_at IS NULL AND rsvp_deadline <= ?", true, models.SessionStatusOpen, time.Now()).
+		Find(&sessions).Error; err != nil {
+		return fmt.Errorf("fetching sessions to allocate: %w", err)
+	}
+
+	var errs []error
+	for _, session := range sessions {
+		results, err := s.AllocateSession(session.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("allocating session %s: %w", session.ID, err))
+			continue
+		}
+		log.Printf("Allocated session %s (%s): %d requests", session.ID, s.algorithm, len(results))
+	}
+	return errors.Join(errs...)
+}
+
+// AllocateSession approves the top-ranked requests up to the session's free
+// spots and declines the rest, recording each decision. Members are told the
+// outcome either way.
+func (s *AllocationService) AllocateSession(sessionID uuid.UUID) ([]models.AllocationResult, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if !session.FairShare {
+		return nil, errors.New("session does not use fair-share allocation")
+	}
+
+	requests, err := s.rsvpService.pendingRequests(session, s.window)
+	if err != nil {
+		return nil, err
+	}
+	ranked := s.rank(requests)
+
+	var results []models.AllocationResult
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Claim the session so a concurrent run can't allocate it twice
+		now := time.Now()
+		claim := tx.Model(&models.Session{}).
+			Where("id = ? AND allocated_at IS NULL", sessionID).
+			Update("allocated_at", now)
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			return ErrAlreadyAllocated
+		}
+
+		held, err := spotsHeld(tx, sessionID)
+		if err != nil {
+			return err
+		}
+		spots := session.MaxPlayers - held
+
+		results = make([]models.AllocationResult, len(ranked))
+		for i, r := range ranked {
+			selected := i < spots
+			status := models.RSVPStatusDeclined
+			if selected {
+				status = models.RSVPStatusIn
+			}
+			if err := tx.Model(&models.RSVP{}).Where("id = ?", r.request.RSVP.ID).
+				Updates(map[string]interface{}{"status": status, "updated_at": now}).Error; err != nil {
+				return err
+			}
+			if err := models.RecordRSVPEvent(tx, sessionID, r.request.RSVP.UserID, status); err != nil {
+				return err
+			}
+			reason := ""
+			if !selected {
+				reason = "The session was oversubscribed, so spots were shared out by rotation."
+			}
+			if err := s.rsvpService.addRequestOutcome(tx, session, r.request.RSVP.UserID, selected, reason); err != nil {
+				return err
+			}
+
+			results[i] = models.AllocationResult{
+				SessionID:        sessionID,
+				UserID:           r.request.RSVP.UserID,
+				Algorithm:        s.algorithm,
+				RecentAttendance: r.request.RecentAttendance,
+				Score:            r.score,
+				Rank:             i + 1,
+				Selected:         selected,
+			}
+		}
+		if err := models.RefreshRSVPCounts(tx, sessionID); err != nil {
+			return err
+		}
+		if len(results) > 0 {
+			return tx.Create(&results).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.rsvpService.outbox.Dispatch()
+
+	return results, nil
+}
+
+type rankedRequest struct {
+	request RSVPRequest
+	score   float64
+}
+
+// rank orders requests best first according to the configured algorithm.
+// requests arrive in request order, which breaks ties.
+func (s *AllocationService) rank(requests []RSVPRequest) []rankedRequest {
+	ranked := make([]rankedRequest, len(requests))
+	for i, r := range requests {
+		var score float64
+		switch s.algorithm {
+		case models.AllocationFewestRecent:
+			score = -float64(r.RecentAttendance)
+		case models.AllocationWeightedLottery:
+			// Weighted sampling without replacement (Efraimidis-Spirakis): each
+			// draw is u^(1/w), with weight 1/(1+recent sessions)
+			weight := 1 / float64(1+r.RecentAttendance)
+			score = math.Pow(rand.Float64(), 1/weight)
+		case models.AllocationFirstCome:
+			score = -float64(i)
+		}
+		ranked[i] = rankedRequest{request: r, score: score}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+	return ranked
+}
+
+// ListAllocationResults returns how each request fared in a session's allocation, best ranked first
+func (s *AllocationService) ListAllocationResults(sessionID uuid.UUID) ([]models.AllocationResult, error) {
+	var results []models.AllocationResult
+	if err := database.DB.Where("session_id = ?", sessionID).
+		Preload("User").
+		Order("rank ASC").
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
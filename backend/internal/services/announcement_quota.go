@@ -0,0 +1,51 @@
+package services
+
+import (
+	"time"
+
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+// AnnouncementQuota is how many announcements the club can still send in the
+// current rolling window. Limit 0 means announcements aren't capped.
+type AnnouncementQuota struct {
+	Limit     int        `json:"limit"`
+	Used      int        `json:"used"`
+	Remaining int        `json:"remaining"`
+	ResetsAt  *time.Time `json:"resets_at,omitempty"` // when the oldest counted announcement leaves the window
+}
+
+// Exceeded reports whether sending another announcement would go over the cap
+func (q *AnnouncementQuota) Exceeded() bool {
+	return q.Limit > 0 && q.Remaining == 0
+}
+
+// Quota counts announcements sent in the last window against limit. It's a
+// soft cap to spare members announcement fatigue, so admins can still
+// override it.
+func (s *AnnouncementService) Quota(limit int, window time.Duration) (*AnnouncementQuota, error) {
+	quota := &AnnouncementQuota{Limit: limit}
+	if limit <= 0 {
+		quota.Limit = 0
+		return quota, nil
+	}
+
+	var sent []models.Announcement
+	if err := database.DB.Select("id", "created_at").
+		Where("created_at > ? AND audience = ?", time.Now().Add(-window), models.AudienceMembers).
+		Order("created_at").
+		Find(&sent).Error; err != nil {
+		return nil, err
+	}
+
+	quota.Used = len(sent)
+	if quota.Used < limit {
+		quota.Remaining = limit - quota.Used
+	} else {
+		// A slot frees up once enough of the oldest announcements age out
+		resetsAt := sent[quota.Used-limit].CreatedAt.Add(window)
+		quota.ResetsAt = &resetsAt
+	}
+	return quota, nil
+}
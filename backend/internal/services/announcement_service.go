@@ -0,0 +1,357 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AnnouncementService tracks which members have confirmed reading the
+// announcements that ask for it, and nudges those who haven't
+type AnnouncementService struct {
+	notificationService *NotificationService
+	nudgeAfter          time.Duration
+	maxNudges           int
+}
+
+// NewAnnouncementService creates an announcement service. Members who haven't
+// acknowledged are nudged every nudgeAfter, up to maxNudges times; 0 disables nudges.
+func NewAnnouncementService(notificationService *NotificationService, nudgeAfter time.Duration, maxNudges int) *AnnouncementService {
+	return &AnnouncementService{
+		notificationService: notificationService,
+		nudgeAfter:          nudgeAfter,
+		maxNudges:           maxNudges,
+	}
+}
+
+// AnnouncementWithAck is an announcement with when the viewer acknowledged it
+type AnnouncementWithAck struct {
+	models.Announcement
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// AnnouncementAckSummary counts how many approved members have acknowledged
+// an announcement and how many are still outstanding
+type AnnouncementAckSummary struct {
+	models.Announcement
+	Acknowledged int `json:"acknowledged"`
+	Outstanding  int `json:"outstanding"`
+}
+
+// AnnouncementAcks lists who has and hasn't acknowledged an announcement
+type AnnouncementAcks struct {
+	Acknowledged []models.AnnouncementAcknowledgement
+	Outstanding  []models.User
+}
+
+var ErrAnnouncementNotFound = domainError(ErrNotFound, "announcement_not_found", "announcement not found")
+
+// visibleAnnouncements narrows db to the announcements viewer may see;
+// committee announcements are left out for anyone not on the committee
+func visibleAnnouncements(db *gorm.DB, viewer *models.User) *gorm.DB {
+	if viewer.IsCommittee() {
+		return db
+	}
+	return db.Where("audience = ?", models.AudienceMembers)
+}
+
+// audienceMembers narrows db to the approved members an audience reaches
+func audienceMembers(db *gorm.DB, audience models.AnnouncementAudience) *gorm.DB {
+	db = db.Where("membership_status = ?", models.MembershipApproved)
+	if audience == models.AudienceCommittee {
+		db = db.Where("committee_role != ? OR role = ?", models.CommitteeNone, models.RoleAdmin)
+	}
+	return db
+}
+
+// ListAnnouncements returns the most recent announcements viewer may see,
+// with whether they've acknowledged each
+func (s *AnnouncementService) ListAnnouncements(viewer *models.User, limit int) ([]AnnouncementWithAck, error) {
+	var announcements []models.Announcement
+	if err := visibleAnnouncements(database.DB, viewer).Preload("Creator").
+		Order("sent_at DESC").
+		Limit(limit).
+		Find(&announcements).Error; err != nil {
+		return nil, err
+	}
+
+	var acks []models.AnnouncementAcknowledgement
+	if err := database.DB.Where("user_id = ?", viewer.ID).Find(&acks).Error; err != nil {
+		return nil, err
+	}
+	acked := make(map[uuid.UUID]time.Time, len(acks))
+	for _, a := range acks {
+		acked[a.AnnouncementID] = a.AcknowledgedAt
+	}
+
+	result := make([]AnnouncementWithAck, len(announcements))
+	for i, a := range announcements {
+		result[i] = AnnouncementWithAck{Announcement: a}
+		if at, ok := acked[a.ID]; ok {
+			result[i].AcknowledgedAt = &at
+		}
+	}
+	return result, nil
+}
+
+// Acknowledge records that viewer has read an announcement; repeat calls keep the first time
+func (s *AnnouncementService) Acknowledge(announcementID uuid.UUID, viewer *models.User) (*models.AnnouncementAcknowledgement, error) {
+	var announcement models.Announcement
+	if err := visibleAnnouncements(database.DB, viewer).First(&announcement, "id = ?", announcementID).Error; err != nil {
+		return nil, ErrAnnouncementNotFound
+	}
+	if !announcement.RequiresAck {
+		return nil, errors.New("this announcement doesn't ask for acknowledgement")
+	}
+
+	ack := models.AnnouncementAcknowledgement{AnnouncementID: announcementID, UserID: viewer.ID}
+	if err := database.DB.Where(ack).
+		Attrs(models.AnnouncementAcknowledgement{AcknowledgedAt: time.Now()}).
+		FirstOrCreate(&ack).Error; err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
+
+// AckSummaries returns announcements that ask for acknowledgement, newest
+// first, with acknowledged and outstanding counts among the approved members
+// each was sent to
+func (s *AnnouncementService) AckSummaries(limit int) ([]AnnouncementAckSummary, error) {
+	var announcements []models.Announcement
+	if err := database.DB.Where("requires_ack = ?", true).
+		Order("sent_at DESC").
+		Limit(limit).
+		Find(&announcements).Error; err != nil {
+		return nil, err
+	}
+	if len(announcements) == 0 {
+		return []AnnouncementAckSummary{}, nil
+	}
+
+	members := map[models.AnnouncementAudience]int64{}
+	for _, audience := range []models.AnnouncementAudience{models.AudienceMembers, models.AudienceCommittee} {
+		var count int64
+		if err := audienceMembers(database.DB.Model(&models.User{}), audience).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		members[audience] = count
+	}
+
+	ids := make([]uuid.UUID, len(announcements))
+	for i, a := range announcements {
+		ids[i] = a.ID
+	}
+	var counts []struct {
+		AnnouncementID uuid.UUID
+		Count          int
+	}
+	if err := database.DB.Model(&models.AnnouncementAcknowledgement{}).
+		Select("announcement_acknowledgements.announcement_id, COUNT(*) AS count").
+		Joins("JOIN users ON users.id = announcement_acknowledgements.user_id").
+		Where("announcement_acknowledgements.announcement_id IN ? AND users.membership_status = ?", ids, models.MembershipApproved).
+		Group("announcement_acknowledgements.announcement_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	acked := make(map[uuid.UUID]int, len(counts))
+	for _, c := range counts {
+		acked[c.AnnouncementID] = c.Count
+	}
+
+	summaries := make([]AnnouncementAckSummary, len(announcements))
+	for i, a := range announcements {
+		summaries[i] = AnnouncementAckSummary{
+			Announcement: a,
+			Acknowledged: acked[a.ID],
+			// Someone who acknowledged and has since left the committee
+			// would otherwise count twice
+			Outstanding: max(int(members[a.Audience])-acked[a.ID], 0),
+		}
+	}
+	return summaries, nil
+}
+
+// GetAcks returns who has acknowledged an announcement and which of the
+// approved members it was sent to haven't yet
+func (s *AnnouncementService) GetAcks(announcementID uuid.UUID) (*models.Announcement, *AnnouncementAcks, error) {
+	var announcement models.Announcement
+	if err := database.DB.First(&announcement, "id = ?", announcementID).Error; err != nil {
+		return nil, nil, ErrAnnouncementNotFound
+	}
+
+	acks := &AnnouncementAcks{}
+	if err := database.DB.Preload("User").
+		Where("announcement_id = ?", announcementID).
+		Order("acknowledged_at ASC").
+		Find(&acks.Acknowledged).Error; err != nil {
+		return nil, nil, err
+	}
+
+	outstanding, err := s.outstandingMembers(announcement)
+	if err != nil {
+		return nil, nil, err
+	}
+	acks.Outstanding = outstanding
+	return &announcement, acks, nil
+}
+
+// outstandingMembers returns members of an announcement's audience who
+// haven't acknowledged it
+func (s *AnnouncementService) outstandingMembers(announcement models.Announcement) ([]models.User, error) {
+	var users []models.User
+	err := audienceMembers(database.DB, announcement.Audience).
+		Where("id NOT IN (?)", database.DB.Model(&models.AnnouncementAcknowledgement{}).
+			Select("user_id").Where("announcement_id = ?", announcement.ID)).
+		Order("name ASC").
+		Find(&users).Error
+	return users, err
+}
+
+// SendAckNudges reminds members who haven't acknowledged an announcement
+// once nudgeAfter has passed since it was sent or last nudged, until
+// maxNudges have gone out
+func (s *AnnouncementService) SendAckNudges(ctx context.Context, report *JobReport) error {
+	if s.maxNudges <= 0 || s.nudgeAfter <= 0 {
+		return nil
+	}
+
+	cutoff := time.Now().Add(-s.nudgeAfter)
+	var announcements []models.Announcement
+	if err := database.DB.
+		Where("requires_ack = ? AND nudges_sent < ?", true, s.maxNudges).
+		Where("COALESCE(last_nudged_at, sent_at) <= ?", cutoff).
+		Find(&announcements).Error; err != nil {
+		return fmt.Errorf("fetching announcements to nudge: %w", err)
+	}
+
+	var errs []error
+	for _, announcement := range announcements {
+		errs = append(errs, s.nudge(ctx, announcement, report))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *AnnouncementService) nudge(ctx context.Context, announcement models.Announcement, report *JobReport) error {
+	members, err := s.outstandingMembers(announcement)
+	if err != nil {
+		return fmt.Errorf("fetching members to nudge for announcement %s: %w", announcement.ID, err)
+	}
+
+	title := "Please confirm: " + announcement.Title
+	body := "You haven't yet confirmed you've read this announcement. " + announcement.Body
+	data := map[string]string{
+		"type":            string(models.NotificationAdminAnnouncement),
+		"announcement_id": announcement.ID.String(),
+		"requires_ack":    "true",
+	}
+	messages := make([]NotificationMessage, len(members))
+	for i, member := range members {
+		messages[i] = NotificationMessage{UserID: member.ID, Title: title, Body: body, Data: data}
+		userID := member.ID
+		report.add(JobAction{Kind: "notification", UserID: &userID, Title: title, Detail: string(models.NotificationAdminAnnouncement) + ": " + body})
+	}
+	if report.isDryRun() {
+		return nil
+	}
+
+	// Count the nudge first so a failed send isn't retried every run
+	now := time.Now()
+	if err := database.DB.Model(&models.Announcement{}).Where("id = ?", announcement.ID).
+		Updates(map[string]interface{}{
+			"nudges_sent":    gorm.Expr("nudges_sent + 1"),
+			"last_nudged_at": now,
+		}).Error; err != nil {
+		return fmt.Errorf("recording nudge for announcement %s: %w", announcement.ID, err)
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	sent, err := s.notificationService.SendBatch(ctx, models.NotificationAdminAnnouncement, messages)
+	if err != nil {
+		return fmt.Errorf("sending nudges for announcement %s: %w", announcement.ID, err)
+	}
+	log.Printf("Nudged %d members to acknowledge announcement %s", sent, announcement.Title)
+	return nil
+}
+
+// SendAnnouncement creates an announcement from an admin and pushes it to
+// every approved member. overridden is the quota the admin sent past, if
+// they did, and is recorded in the audit log.
+func (s *AnnouncementService) SendAnnouncement(ctx context.Context, title, body string, requiresAck bool, createdBy uuid.UUID, overridden *AnnouncementQuota) (*models.Announcement, error) {
+	announcement := models.Announcement{
+		Title:       title,
+		Body:        body,
+		CreatedBy:   createdBy,
+		RequiresAck: requiresAck,
+	}
+	if err := database.DB.Create(&announcement).Error; err != nil {
+		return nil, err
+	}
+	if overridden != nil {
+		if err := database.DB.Create(&models.AuditLog{
+			EntityType: "announcement",
+			EntityID:   announcement.ID,
+			Action:     models.AuditActionAnnouncementLimitOverridden,
+			ActorID:    createdBy,
+			OldValue:   strconv.Itoa(overridden.Used),
+			NewValue:   strconv.Itoa(overridden.Used + 1),
+		}).Error; err != nil {
+			log.Printf("Failed to audit announcement limit override: %v", err)
+		}
+	}
+
+	var userIDs []uuid.UUID
+	if err := database.DB.Model(&models.User{}).Where("membership_status = ?", models.MembershipApproved).
+		Pluck("id", &userIDs).Error; err != nil {
+		return nil, fmt.Errorf("finding members for announcement %s: %w", announcement.ID, err)
+	}
+
+	data := map[string]string{"type": "admin_announcement", "announcement_id": announcement.ID.String()}
+	if requiresAck {
+		data["requires_ack"] = "true"
+	}
+	s.notificationService.SendBulkNotification(ctx, userIDs, models.NotificationAdminAnnouncement, title, body, data)
+	return &announcement, nil
+}
+
+// SendCommitteeAnnouncement sends an announcement to the committee and
+// admins only. It doesn't count against the club's announcement limit,
+// which is there to spare the wider membership.
+func (s *AnnouncementService) SendCommitteeAnnouncement(ctx context.Context, title, body string, requiresAck bool, createdBy uuid.UUID) (*models.Announcement, error) {
+	announcement := models.Announcement{
+		Title:       title,
+		Body:        body,
+		CreatedBy:   createdBy,
+		RequiresAck: requiresAck,
+		Audience:    models.AudienceCommittee,
+	}
+	if err := database.DB.Create(&announcement).Error; err != nil {
+		return nil, err
+	}
+
+	var userIDs []uuid.UUID
+	if err := audienceMembers(database.DB.Model(&models.User{}), models.AudienceCommittee).
+		Pluck("id", &userIDs).Error; err != nil {
+		return nil, fmt.Errorf("finding the committee for announcement %s: %w", announcement.ID, err)
+	}
+
+	data := map[string]string{
+		"type":            string(models.NotificationAdminAnnouncement),
+		"announcement_id": announcement.ID.String(),
+		"audience":        string(models.AudienceCommittee),
+	}
+	if requiresAck {
+		data["requires_ack"] = "true"
+	}
+	s.notificationService.SendBulkNotification(ctx, userIDs, models.NotificationAdminAnnouncement, title, body, data)
+	return &announcement, nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// archiveBatchSize bounds the rows moved per statement, so the hot tables
+// are never locked for long
+const archiveBatchSize = 5000
+
+// ArchiveService moves old notifications, and RSVPs to long-past sessions,
+// into archive tables so the tables the app queries on every page stay small.
+// Archived rows are kept for reporting but no longer appear in the app.
+type ArchiveService struct {
+	notificationsAfterMonths int // 0 keeps notifications in place
+	rsvpsAfterMonths         int // 0 keeps RSVPs in place
+}
+
+// NewArchiveService creates an archive service that moves notifications
+// older than notificationsAfterMonths, and RSVPs to sessions that started
+// more than rsvpsAfterMonths ago; 0 disables either
+func NewArchiveService(notificationsAfterMonths, rsvpsAfterMonths int) *ArchiveService {
+	return &ArchiveService{
+		notificationsAfterMonths: notificationsAfterMonths,
+		rsvpsAfterMonths:         rsvpsAfterMonths,
+	}
+}
+
+// ArchiveTableStats describes one archived table
+type ArchiveTableStats struct {
+	Table          string     `json:"table"`
+	AfterMonths    int        `json:"after_months"` // 0 when archiving is off
+	Live           int64      `json:"live"`
+	Archived       int64      `json:"archived"`
+	Due            int64      `json:"due"` // live rows the next run will move
+	OldestLive     *time.Time `json:"oldest_live,omitempty"`
+	LastArchivedAt *time.Time `json:"last_archived_at,omitempty"`
+}
+
+// archivedTable is a hot table, its archive, and which of its rows are old
+// enough to move
+type archivedTable struct {
+	table       string
+	archive     string
+	model       interface{} // archive model, whose columns are the ones copied
+	afterMonths int
+	due         func(cutoff time.Time) *gorm.DB
+}
+
+func (s *ArchiveService) tables() []archivedTable {
+	return []archivedTable{
+		{
+			table:       "notifications",
+			archive:     models.ArchivedNotification{}.TableName(),
+			model:       &models.ArchivedNotification{},
+			afterMonths: s.notificationsAfterMonths,
+			due: func(cutoff time.Time) *gorm.DB {
+				return database.DB.Table("notifications").Where("created_at < ?", cutoff)
+			},
+		},
+		{
+			table:       "rsvps",
+			archive:     models.ArchivedRSVP{}.TableName(),
+			model:       &models.ArchivedRSVP{},
+			afterMonths: s.rsvpsAfterMonths,
+			due: func(cutoff time.Time) *gorm.DB {
+				return database.DB.Table("rsvps").Where("session_id IN (?)",
+					database.DB.Table("sessions").Select("id").Where("starts_at < ?", cutoff))
+			},
+		},
+	}
+}
+
+// Stats returns row counts for each archived table
+func (s *ArchiveService) Stats() ([]ArchiveTableStats, error) {
+	var stats []ArchiveTableStats
+	for _, t := range s.tables() {
+		st := ArchiveTableStats{Table: t.table, AfterMonths: t.afterMonths}
+		if err := database.DB.Table(t.table).Count(&st.Live).Error; err != nil {
+			return nil, err
+		}
+		if err := database.DB.Table(t.archive).Count(&st.Archived).Error; err != nil {
+			return nil, err
+		}
+		if t.afterMonths > 0 {
+			if err := t.due(archiveCutoff(t.afterMonths)).Count(&st.Due).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		var oldest, last *time.Time
+		if err := database.DB.Table(t.table).Select("MIN(created_at)").Scan(&oldest).Error; err != nil {
+			return nil, err
+		}
+		if err := database.DB.Table(t.archive).Select("MAX(archived_at)").Scan(&last).Error; err != nil {
+			return nil, err
+		}
+		st.OldestLive, st.LastArchivedAt = oldest, last
+		stats = append(stats, st)
+	}
+	return stats, nil
+}
+
+// Archive moves every row that's old enough into its archive table. Moved
+// RSVPs leave no sync tombstones; clients don't keep sessions that old.
+func (s *ArchiveService) Archive(report *JobReport) error {
+	var errs []error
+	for _, t := range s.tables() {
+		if t.afterMonths <= 0 {
+			continue
+		}
+		errs = append(errs, s.archiveTable(t, report))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *ArchiveService) archiveTable(t archivedTable, report *JobReport) error {
+	cutoff := archiveCutoff(t.afterMonths)
+
+	if report.isDryRun() {
+		var due int64
+		if err := t.due(cutoff).Count(&due).Error; err != nil {
+			return fmt.Errorf("counting %s to archive: %w", t.table, err)
+		}
+		report.add(JobAction{Kind: "archive", Title: t.table, Detail: fmt.Sprintf("%d rows from before %s", due, cutoff.Format("2006-01-02"))})
+		return nil
+	}
+
+	columns, err := archiveColumns(t.model)
+	if err != nil {
+		return err
+	}
+	cols := strings.Join(columns, ", ")
+	ids := t.due(cutoff).Select(t.table + ".id").Limit(archiveBatchSize)
+
+	var moved int64
+	for {
+		// Delete and insert in one statement, so a row is never in both
+		// tables or neither
+		result := database.DB.Exec(
+			"WITH moved AS (DELETE FROM "+t.table+" WHERE id IN (?) RETURNING "+cols+") "+
+				"INSERT INTO "+t.archive+" ("+cols+", archived_at) SELECT "+cols+", ? FROM moved",
+			ids, time.Now(),
+		)
+		if result.Error != nil {
+			return fmt.Errorf("archiving %s: %w", t.table, result.Error)
+		}
+		moved += result.RowsAffected
+		if result.RowsAffected < archiveBatchSize {
+			break
+		}
+	}
+
+	report.add(JobAction{Kind: "archive", Title: t.table, Detail: fmt.Sprintf("%d rows from before %s", moved, cutoff.Format("2006-01-02"))})
+	if moved > 0 {
+		log.Printf("Archived %d %s from before %s", moved, t.table, cutoff.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// archiveColumns lists the columns an archive model copies from its hot table
+func archiveColumns(model interface{}) ([]string, error) {
+	stmt := &gorm.Statement{DB: database.DB}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	var columns []string
+	for _, name := range stmt.Schema.DBNames {
+		if name != "archived_at" {
+			columns = append(columns, name)
+		}
+	}
+	return columns, nil
+}
+
+// archiveCutoff is the start of the Sydney day months ago
+func archiveCutoff(months int) time.Time {
+	return utils.StartOfDay(utils.NowInSydney()).AddDate(0, -months, 0)
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// checkInOpensBefore is how long before a session starts members can check
+// themselves in. Self check-in closes when the session ends.
+const checkInOpensBefore = 30 * time.Minute
+
+var (
+	ErrSessionCancelled = domainError(ErrConflict, "session_cancelled", "session has been cancelled")
+	ErrCheckInNotIn     = domainError(ErrConflict, "not_confirmed", "only players who are IN can check in")
+	ErrCheckInClosed    = domainError(ErrConflict, "check_in_closed", "check-in opens 30 minutes before the session starts and closes when it ends")
+)
+
+// CheckIn records a confirmed player's arrival at the venue, from the kiosk
+// or an admin. Checking in again keeps the first arrival time. At a training
+// session it also counts as attending, on the player's training record.
+func (s *RSVPService) CheckIn(sessionID, userID uuid.UUID) (*models.RSVP, error) {
+	if err := s.checkIn(sessionID, userID); err != nil {
+		return nil, err
+	}
+	return s.GetUserRSVPForSession(sessionID, userID)
+}
+
+// SelfCheckIn is a member checking themselves in, which they can do from
+// checkInOpensBefore the start until the session ends
+func (s *RSVPService) SelfCheckIn(sessionID, userID uuid.UUID) (*models.RSVP, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+	now := time.Now()
+	if now.Before(session.StartsAt.Add(-checkInOpensBefore)) || now.After(session.EndsAt) {
+		return nil, ErrCheckInClosed
+	}
+	return s.CheckIn(sessionID, userID)
+}
+
+func (s *RSVPService) checkIn(sessionID, userID uuid.UUID) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := attendedSession(tx, sessionID)
+		if err != nil {
+			return err
+		}
+
+		var rsvp models.RSVP
+		if err := tx.Where("session_id = ? AND user_id = ?", sessionID, userID).First(&rsvp).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRSVPNotFound
+			}
+			return err
+		}
+		if rsvp.Status != models.RSVPStatusIn {
+			return ErrCheckInNotIn
+		}
+
+		_, err = markAttendance(tx, session, userID, models.AttendancePresent, "", nil)
+		return err
+	})
+}
+
+// RecordAttendance is an admin marking whether a member came to a session.
+// Present members needn't have been IN, so walk-ins can be recorded; marking
+// someone absent or excused clears their check-in.
+func (s *RSVPService) RecordAttendance(sessionID, userID uuid.UUID, status models.AttendanceStatus, note string, recordedBy uuid.UUID) (*models.Attendance, error) {
+	var attendance *models.Attendance
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := attendedSession(tx, sessionID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Select("id").First(&models.User{}, "id = ?", userID).Error; err != nil {
+			return ErrUserNotFound
+		}
+
+		attendance, err = markAttendance(tx, session, userID, status, note, &recordedBy)
+		return err
+	})
+	return attendance, err
+}
+
+// GetSessionAttendance lists the attendance recorded for a session
+func (s *RSVPService) GetSessionAttendance(sessionID uuid.UUID) ([]models.Attendance, error) {
+	var attendance []models.Attendance
+	err := database.DB.Where("session_id = ?", sessionID).
+		Preload("User").
+		Order("created_at ASC").
+		Find(&attendance).Error
+	return attendance, err
+}
+
+func attendedSession(tx *gorm.DB, sessionID uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	if err := tx.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if session.Status == models.SessionStatusCancelled {
+		return nil, ErrSessionCancelled
+	}
+	return &session, nil
+}
+
+// markAttendance upserts a member's attendance and keeps their RSVP's
+// check-in time in step. A member marked present again keeps their first
+// arrival time.
+func markAttendance(tx *gorm.DB, session *models.Session, userID uuid.UUID, status models.AttendanceStatus, note string, recordedBy *uuid.UUID) (*models.Attendance, error) {
+	now := time.Now()
+	attendance := models.Attendance{
+		SessionID:  session.ID,
+		UserID:     userID,
+		Status:     status,
+		RecordedBy: recordedBy,
+		Note:       note,
+	}
+	checkedIn := gorm.Expr("NULL")
+	if status == models.AttendancePresent {
+		attendance.CheckedInAt = &now
+		checkedIn = gorm.Expr("COALESCE(attendances.checked_in_at, excluded.checked_in_at)")
+	}
+
+	err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "session_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"status":        status,
+			"checked_in_at": checkedIn,
+			"recorded_by":   recordedBy,
+			"note":          note,
+			"updated_at":    now,
+		}),
+	}).Create(&attendance).Error
+	if err != nil {
+		return nil, err
+	}
+	// Re-read it, since an existing record keeps its own ID and arrival time
+	var saved models.Attendance
+	if err := tx.Where("session_id = ? AND user_id = ?", session.ID, userID).First(&saved).Error; err != nil {
+		return nil, err
+	}
+
+	rsvps := tx.Model(&models.RSVP{}).Where("session_id = ? AND user_id = ?", session.ID, userID)
+	if status == models.AttendancePresent {
+		err = rsvps.Where("status = ? AND checked_in_at IS NULL", models.RSVPStatusIn).
+			Updates(map[string]interface{}{"checked_in_at": saved.CheckedInAt, "updated_at": now}).Error
+	} else {
+		err = rsvps.Where("checked_in_at IS NOT NULL").
+			Updates(map[string]interface{}{"checked_in_at": nil, "updated_at": now}).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status == models.AttendancePresent && session.SessionType == models.SessionTypeTraining {
+		if err := recordTrainingAttendance(tx, session.ID, userID, recordedBy); err != nil {
+			return nil, err
+		}
+	}
+	return &saved, nil
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/storage"
+	"gorm.io/gorm"
+)
+
+// AvatarPath is where uploaded photos are served, relative to the API's
+// host: AvatarPath + "<avatar ID>/<size>". The avatar ID changes with every
+// upload, so a photo's URL can be cached for good and stops working once
+// the photo is replaced or removed.
+const AvatarPath = "/api/avatars/"
+
+var (
+	ErrAvatarNotFound       = domainError(ErrNotFound, "avatar_not_found", "photo not found")
+	ErrAvatarReportNotFound = domainError(ErrNotFound, "report_not_found", "report not found")
+	ErrOwnAvatar            = domainError(ErrInvalid, "own_avatar", "cannot report your own photo")
+	ErrAvatarReported       = domainError(ErrConflict, "already_reported", "you have already reported this photo")
+	ErrAvatarAction         = domainError(ErrInvalid, "invalid_action", "a reported photo can only be deleted or the report dismissed")
+)
+
+type AvatarService struct {
+	store               storage.Store // nil when no storage backend is configured
+	notificationService *NotificationService
+}
+
+func NewAvatarService(store storage.Store, notificationService *NotificationService) *AvatarService {
+	return &AvatarService{store: store, notificationService: notificationService}
+}
+
+// avatarKey is where one size of an uploaded photo is stored
+func avatarKey(userID, avatarID uuid.UUID, size models.AvatarSize) string {
+	return fmt.Sprintf("avatars/%s/%s-%s.jpg", userID, avatarID, size)
+}
+
+// AvatarURL is the picture to show for a member at size: the photo they
+// uploaded, else their Auth0 picture, else a generated one of their initials
+func AvatarURL(u *models.User, size models.AvatarSize) string {
+	if u.AvatarID != nil {
+		return AvatarPath + u.AvatarID.String() + "/" + string(size)
+	}
+	if u.ProfilePicture != "" {
+		return u.ProfilePicture
+	}
+	return InitialsAvatar(u)
+}
+
+// avatarColours are the backgrounds of initials avatars, dark enough for
+// white text
+var avatarColours = []string{"#0f766e", "#1d4ed8", "#7c3aed", "#be185d", "#b45309", "#15803d", "#4338ca", "#b91c1c"}
+
+// InitialsAvatar is an SVG data URL of a member's initials on a colour
+// picked from their ID, so it stays the same when they change their name
+func InitialsAvatar(u *models.User) string {
+	h := fnv.New32a()
+	h.Write(u.ID[:])
+	colour := avatarColours[h.Sum32()%uint32(len(avatarColours))]
+
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="256" height="256" viewBox="0 0 100 100">`+
+		`<rect width="100" height="100" fill="%s"/>`+
+		`<text x="50" y="50" dy=".35em" text-anchor="middle" font-family="sans-serif" font-size="40" fill="#fff">%s</text></svg>`,
+		colour, html.EscapeString(initials(u.Name)))
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg))
+}
+
+// initials are the first letters of a name's first and last words: "Chen
+// Wei" is "CW", "Priya" is "P", and no name at all is "?"
+func initials(name string) string {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return "?"
+	}
+	first := []rune(words[0])[0]
+	if len(words) == 1 {
+		return string(unicode.ToUpper(first))
+	}
+	last := []rune(words[len(words)-1])[0]
+	return string(unicode.ToUpper(first)) + string(unicode.ToUpper(last))
+}
+
+// SetAvatar stores a photo as a member's avatar in each of
+// models.AvatarSizes, cropped square, replacing any they had. The content is
+// sniffed rather than trusting the client's content type.
+func (s *AvatarService) SetAvatar(ctx context.Context, userID uuid.UUID, size int64, r io.Reader) (*models.User, error) {
+	if s.store == nil {
+		return nil, ErrStorageDisabled
+	}
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	upload, err := storage.Avatars.Check(size, r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(upload.Body)
+	if err != nil {
+		return nil, err
+	}
+	pixels := make([]int, len(models.AvatarSizes))
+	for i, size := range models.AvatarSizes {
+		pixels[i] = size.Pixels
+	}
+	images, err := storage.SquareJPEGs(data, pixels)
+	if err != nil {
+		return nil, err
+	}
+
+	avatarID := uuid.New()
+	for i, size := range models.AvatarSizes {
+		key := avatarKey(userID, avatarID, size.Size)
+		if err := s.store.Put(ctx, key, "image/jpeg", bytes.NewReader(images[i])); err != nil {
+			s.removeStoredAvatar(userID, avatarID)
+			return nil, storeError("photo", err)
+		}
+	}
+
+	previous := user.AvatarID
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("avatar_id", avatarID).Error; err != nil {
+			return err
+		}
+		if previous != nil {
+			return closeAvatarReports(tx, *previous, nil, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		s.removeStoredAvatar(userID, avatarID)
+		return nil, err
+	}
+	user.AvatarID = &avatarID
+	if previous != nil {
+		s.removeStoredAvatar(userID, *previous)
+	}
+	return &user, nil
+}
+
+// RemoveAvatar removes the photo a member uploaded, leaving them with their
+// Auth0 picture or initials
+func (s *AvatarService) RemoveAvatar(userID uuid.UUID) error {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return ErrUserNotFound
+	}
+	if user.AvatarID == nil {
+		return ErrAvatarNotFound
+	}
+	avatarID := *user.AvatarID
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("avatar_id", nil).Error; err != nil {
+			return err
+		}
+		return closeAvatarReports(tx, avatarID, nil, nil)
+	})
+	if err != nil {
+		return err
+	}
+	s.removeStoredAvatar(userID, avatarID)
+	return nil
+}
+
+// OpenAvatar returns one size of a member's current photo; the caller must
+// close it. Photos that have been replaced or removed aren't found.
+func (s *AvatarService) OpenAvatar(ctx context.Context, avatarID uuid.UUID, size models.AvatarSize) (io.ReadCloser, error) {
+	if s.store == nil {
+		return nil, ErrStorageDisabled
+	}
+	var user models.User
+	if err := database.DB.Select("id").First(&user, "avatar_id = ?", avatarID).Error; err != nil {
+		return nil, ErrAvatarNotFound
+	}
+
+	body, err := s.store.Get(ctx, avatarKey(user.ID, avatarID, size))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrAvatarNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read photo: %w", err)
+	}
+	return body, nil
+}
+
+// removeStoredAvatar deletes every size of a photo, logging rather than
+// failing since the member no longer uses it
+func (s *AvatarService) removeStoredAvatar(userID, avatarID uuid.UUID) {
+	if s.store == nil {
+		return
+	}
+	for _, size := range models.AvatarSizes {
+		key := avatarKey(userID, avatarID, size.Size)
+		if err := s.store.Delete(context.Background(), key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Failed to remove stored photo %s: %v", key, err)
+		}
+	}
+}
+
+// ReportAvatar files a report against a member's current photo
+func (s *AvatarService) ReportAvatar(userID, reporterID uuid.UUID, reason string) (*models.AvatarReport, error) {
+	if userID == reporterID {
+		return nil, ErrOwnAvatar
+	}
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.AvatarID == nil {
+		return nil, ErrAvatarNotFound
+	}
+
+	var existing int64
+	if err := database.DB.Model(&models.AvatarReport{}).
+		Where("avatar_id = ? AND reported_by = ?", *user.AvatarID, reporterID).
+		Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, ErrAvatarReported
+	}
+
+	report := models.AvatarReport{
+		UserID:     userID,
+		AvatarID:   *user.AvatarID,
+		ReportedBy: reporterID,
+		Reason:     reason,
+		Status:     models.ReportStatusOpen,
+	}
+	if err := database.DB.Create(&report).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListAvatarReports returns the open photo reports, oldest first
+func (s *AvatarService) ListAvatarReports() ([]models.AvatarReport, error) {
+	var reports []models.AvatarReport
+	if err := database.DB.Where("status = ?", models.ReportStatusOpen).
+		Preload("User").
+		Preload("Reporter").
+		Order("created_at ASC").
+		Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// ResolveAvatarReport deletes the reported photo or dismisses the report,
+// closing every open report on the same photo. A member whose photo is
+// deleted is told why.
+func (s *AvatarService) ResolveAvatarReport(ctx context.Context, reportID, adminID uuid.UUID, action models.ModerationAction, note string) (*models.AvatarReport, error) {
+	if action != models.ModerationDelete && action != models.ModerationDismiss {
+		return nil, ErrAvatarAction
+	}
+	var report models.AvatarReport
+	if err := database.DB.First(&report, "id = ?", reportID).Error; err != nil {
+		return nil, ErrAvatarReportNotFound
+	}
+	if report.Status != models.ReportStatusOpen {
+		return nil, errors.New("report is already resolved")
+	}
+
+	removed := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if action == models.ModerationDelete {
+			result := tx.Model(&models.User{}).
+				Where("id = ? AND avatar_id = ?", report.UserID, report.AvatarID).
+				Updates(map[string]interface{}{"avatar_id": nil, "updated_at": time.Now()})
+			if result.Error != nil {
+				return result.Error
+			}
+			removed = result.RowsAffected > 0
+		}
+		return closeAvatarReports(tx, report.AvatarID, &action, &adminID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if removed {
+		s.removeStoredAvatar(report.UserID, report.AvatarID)
+		if s.notificationService != nil {
+			title := "Profile Photo Removed"
+			body := "An admin reviewed your profile photo and removed it. You can upload a different one from your profile."
+			if note != "" {
+				body += " Note from the admin: " + note
+			}
+			data := map[string]string{"type": string(models.NotificationModeration)}
+			if err := s.notificationService.SendNotification(ctx, report.UserID, models.NotificationModeration, title, body, data); err != nil {
+				log.Printf("Error sending photo removal notice to user %s: %v", report.UserID, err)
+			}
+		}
+	}
+
+	now := time.Now()
+	report.Status = models.ReportStatusResolved
+	report.Action = &action
+	report.ResolvedBy = &adminID
+	report.ResolvedAt = &now
+	return &report, nil
+}
+
+// closeAvatarReports resolves the open reports on a photo, with the action
+// an admin took, or with neither when the photo was replaced or removed by
+// its member
+func closeAvatarReports(tx *gorm.DB, avatarID uuid.UUID, action *models.ModerationAction, adminID *uuid.UUID) error {
+	return tx.Model(&models.AvatarReport{}).
+		Where("avatar_id = ? AND status = ?", avatarID, models.ReportStatusOpen).
+		Updates(map[string]interface{}{
+			"status":      models.ReportStatusResolved,
+			"action":      action,
+			"resolved_by": adminID,
+			"resolved_at": time.Now(),
+		}).Error
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/weekday-masters/backend/internal/storage"
+)
+
+// backupPrefix is where backups are kept in the store
+const backupPrefix = "backups/"
+
+// ErrNoBackups is returned when asked to restore the latest backup and there is none
+var ErrNoBackups = domainError(ErrNotFound, "no_backups", "no backups found")
+
+// BackupService dumps the database to object storage with pg_dump and
+// restores it with pg_restore, so a server without managed backups can
+// still recover. Both tools must be on the PATH.
+type BackupService struct {
+	store         storage.Store
+	databaseURL   string
+	retentionDays int
+}
+
+// NewBackupService creates a backup service. Backups older than
+// retentionDays are deleted after each backup, always keeping the newest.
+func NewBackupService(store storage.Store, databaseURL string, retentionDays int) (*BackupService, error) {
+	if store == nil {
+		return nil, ErrStorageDisabled
+	}
+	if retentionDays < 1 {
+		return nil, errors.New("backup retention must be at least one day")
+	}
+	return &BackupService{store: store, databaseURL: databaseURL, retentionDays: retentionDays}, nil
+}
+
+// Backup dumps the database and uploads it, then prunes old backups. On a
+// dry run it only reports the key it would write and the backups it would prune.
+func (s *BackupService) Backup(ctx context.Context, report *JobReport) (string, error) {
+	key := backupPrefix + "weekday-masters-" + time.Now().UTC().Format("20060102T150405Z") + ".dump"
+	if report.isDryRun() {
+		report.add(JobAction{Kind: "create_backup", Title: key})
+		return key, s.prune(ctx, report)
+	}
+
+	file, err := os.CreateTemp("", "backup-*.dump")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	// Custom format is compressed and lets pg_restore clean up before restoring
+	cmd := exec.CommandContext(ctx, "pg_dump", "--format=custom", "--no-owner", "--no-acl",
+		"--file="+file.Name(), "--dbname="+s.databaseURL)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("pg_dump failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	if err := s.store.Put(ctx, key, "application/octet-stream", file); err != nil {
+		return "", fmt.Errorf("uploading backup: %w", err)
+	}
+	log.Printf("Database backed up to %s:%s", s.store.Name(), key)
+	report.add(JobAction{Kind: "create_backup", Title: key})
+
+	return key, s.prune(ctx, report)
+}
+
+// Restore replaces the database's contents with a backup. key "latest"
+// restores the newest backup.
+func (s *BackupService) Restore(ctx context.Context, key string) error {
+	if key == "latest" {
+		backups, err := s.ListBackups(ctx)
+		if err != nil {
+			return err
+		}
+		if len(backups) == 0 {
+			return ErrNoBackups
+		}
+		key = backups[0].Key
+	}
+
+	body, err := s.store.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("downloading backup %s: %w", key, err)
+	}
+	defer body.Close()
+
+	file, err := os.CreateTemp("", "restore-*.dump")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	if _, err := io.Copy(file, body); err != nil {
+		return fmt.Errorf("downloading backup %s: %w", key, err)
+	}
+
+	cmd := exec.CommandContext(ctx, "pg_restore", "--clean", "--if-exists", "--no-owner", "--no-acl",
+		"--single-transaction", "--dbname="+s.databaseURL, file.Name())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("pg_restore failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	log.Printf("Database restored from %s:%s", s.store.Name(), key)
+	return nil
+}
+
+// ListBackups returns the stored backups, newest first
+func (s *BackupService) ListBackups(ctx context.Context) ([]storage.Object, error) {
+	backups, err := s.store.List(ctx, backupPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing backups: %w", err)
+	}
+	// Keys embed the UTC time they were taken, so they sort chronologically
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Key > backups[j].Key
+	})
+	return backups, nil
+}
+
+// prune deletes backups past the retention period, always keeping the newest
+func (s *BackupService) prune(ctx context.Context, report *JobReport) error {
+	backups, err := s.ListBackups(ctx)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -s.retentionDays)
+	var errs []error
+	for i, backup := range backups {
+		if i == 0 || backup.UpdatedAt.After(cutoff) {
+			continue
+		}
+		report.add(JobAction{Kind: "delete_backup", Title: backup.Key})
+		if report.isDryRun() {
+			continue
+		}
+		if err := s.store.Delete(ctx, backup.Key); err != nil {
+			errs = append(errs, fmt.Errorf("deleting backup %s: %w", backup.Key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm/clause"
+)
+
+// Milestone thresholds
+const (
+	sessionsMilestone = 50
+	gamesMilestone    = 100
+)
+
+type BadgeService struct {
+	notificationService *NotificationService
+}
+
+func NewBadgeService(notificationService *NotificationService) *BadgeService {
+	return &BadgeService{notificationService: notificationService}
+}
+
+// EvaluateBadges checks all approved members against the milestone rules
+// and awards any badges they have newly earned
+func (s *BadgeService) EvaluateBadges(ctx context.Context) error {
+	awarded := 0
+
+	// Sessions attended: past, non-cancelled sessions where the member was IN,
+	// including RSVPs that have been archived
+	type userCount struct {
+		UserID uuid.UUID
+		Count  int
+	}
+	var counts []userCount
+	today := utils.StartOfDay(utils.NowInSydney())
+	allRSVPs := database.DB.Raw("SELECT session_id, user_id, status FROM rsvps UNION ALL SELECT session_id, user_id, status FROM " +
+		models.ArchivedRSVP{}.TableName())
+	if err := database.DB.Table("(?) AS rsvps", allRSVPs).
+		Select("rsvps.user_id, COUNT(*) AS count").
+		Joins("JOIN sessions ON sessions.id = rsvps.session_id").
+		Where("rsvps.status = ? AND sessions.status != ? AND sessions.session_date < ?",
+			models.RSVPStatusIn, models.SessionStatusCancelled, today).
+		Group("rsvps.user_id").
+		Having("COUNT(*) >= ?", sessionsMilestone).
+		Scan(&counts).Error; err != nil {
+		return fmt.Errorf("failed to count attendance: %w", err)
+	}
+	for _, c := range counts {
+		if s.award(ctx, c.UserID, models.BadgeSessions50) {
+			awarded++
+		}
+	}
+
+	// Confirmed casual games played
+	var gameCounts []userCount
+	if err := database.DB.Model(&models.GamePlayer{}).
+		Select("game_players.user_id, COUNT(*) AS count").
+		Joins("JOIN games ON games.id = game_players.game_id").
+		Where("games.status = ?", models.GameStatusConfirmed).
+		Group("game_players.user_id").
+		Having("COUNT(*) >= ?", gamesMilestone).
+		Scan(&gameCounts).Error; err != nil {
+		return fmt.Errorf("failed to count games: %w", err)
+	}
+	for _, c := range gameCounts {
+		if s.award(ctx, c.UserID, models.BadgeGames100) {
+			awarded++
+		}
+	}
+
+	// Membership anniversary
+	var veterans []models.User
+	if err := database.DB.Where("membership_status = ? AND created_at <= ?",
+		models.MembershipApproved, time.Now().AddDate(-1, 0, 0)).
+		Find(&veterans).Error; err != nil {
+		return fmt.Errorf("failed to fetch members: %w", err)
+	}
+	for _, u := range veterans {
+		if s.award(ctx, u.ID, models.BadgeOneYearMember) {
+			awarded++
+		}
+	}
+
+	if awarded > 0 {
+		log.Printf("Awarded %d new badges", awarded)
+	}
+	return nil
+}
+
+// AwardBadge awards a badge to a user if they don't already hold it
+func (s *BadgeService) AwardBadge(ctx context.Context, userID uuid.UUID, badge models.BadgeType) bool {
+	return s.award(ctx, userID, badge)
+}
+
+// award inserts the badge and announces it; returns true if it was newly awarded
+func (s *BadgeService) award(ctx context.Context, userID uuid.UUID, badge models.BadgeType) bool {
+	userBadge := models.UserBadge{
+		UserID:    userID,
+		BadgeType: badge,
+	}
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&userBadge)
+	if result.Error != nil {
+		log.Printf("Failed to award badge %s to user %s: %v", badge, userID, result.Error)
+		return false
+	}
+	if result.RowsAffected == 0 {
+		return false
+	}
+
+	if s.notificationService != nil {
+		title := "New Badge Earned!"
+		body := fmt.Sprintf("Congratulations! You've earned the %s badge.", badge.DisplayName())
+		data := map[string]string{
+			"type":       string(models.NotificationBadgeAwarded),
+			"badge_type": string(badge),
+		}
+		if err := s.notificationService.SendNotification(ctx, userID, models.NotificationBadgeAwarded, title, body, data); err != nil {
+			log.Printf("Error sending badge notification to user %s: %v", userID, err)
+		}
+	}
+
+	return true
+}
+
+// GetUserBadges returns the badges a user has earned, most recent first
+func (s *BadgeService) GetUserBadges(userID uuid.UUID) ([]models.UserBadge, error) {
+	var badges []models.UserBadge
+	if err := database.DB.Where("user_id = ?", userID).
+		Order("awarded_at DESC").
+		Find(&badges).Error; err != nil {
+		return nil, err
+	}
+	return badges, nil
+}
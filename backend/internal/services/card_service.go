@@ -0,0 +1,206 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/qr"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCardsDisabled is returned when no membership card secret is configured
+	ErrCardsDisabled = domainError(ErrUnavailable, "cards_disabled", "membership cards are not configured")
+	// ErrInvalidCard is returned for card tokens that are malformed, tampered with or expired
+	ErrInvalidCard = domainError(ErrInvalid, "invalid_card", "membership card is invalid or has expired")
+)
+
+// cardTokenPrefix marks card tokens, so a scanner can tell them from other QR codes
+const cardTokenPrefix = "wmcard:"
+
+// cardQRScale is pixels per QR module in card images
+const cardQRScale = 8
+
+// CardService issues digital membership cards: a QR code of the member's ID
+// and an expiry, signed with HMAC-SHA256 like share links, that door staff
+// scan to check someone is a current member. The app refetches the card
+// before it expires, so a screenshot stops working after validFor.
+type CardService struct {
+	secret   *signingKey
+	validFor time.Duration
+}
+
+// NewCardService creates a card service; an empty secret disables cards
+func NewCardService(secret string, validFor time.Duration) *CardService {
+	return &CardService{secret: newSigningKey(secret), validFor: validFor}
+}
+
+// IsEnabled reports whether cards can be issued
+func (s *CardService) IsEnabled() bool {
+	return len(s.secret.get()) > 0
+}
+
+// SetSecret switches to a rotated secret. Cards signed with the old one stop
+// scanning until the app refetches them.
+func (s *CardService) SetSecret(secret string) {
+	s.secret.set(secret)
+}
+
+// MembershipCard is what the member shows at the door
+type MembershipCard struct {
+	MemberID    uuid.UUID             `json:"member_id"`
+	Name        string                `json:"name"`
+	Tier        models.MembershipTier `json:"tier"`
+	MemberSince time.Time             `json:"member_since"`
+	Token       string                `json:"token"`
+	QRCode      string                `json:"qr_code"` // PNG data URL of Token
+	ExpiresAt   time.Time             `json:"expires_at"`
+}
+
+// CardVerification is the result of scanning a card. Member is set whenever
+// the token was genuine, so door staff can see who it belongs to even when
+// the membership has lapsed.
+type CardVerification struct {
+	Valid  bool                `json:"valid"`
+	Reason string              `json:"reason,omitempty"`
+	Member *CardVerifiedMember `json:"member,omitempty"`
+}
+
+// CardVerifiedMember is what door staff see about a scanned member
+type CardVerifiedMember struct {
+	ID               uuid.UUID               `json:"id"`
+	Name             string                  `json:"name"`
+	ProfilePicture   string                  `json:"profile_picture"`
+	AvatarURL        string                  `json:"avatar_url"` // for matching the member's face
+	Tier             models.MembershipTier   `json:"tier"`
+	MembershipStatus models.MembershipStatus `json:"membership_status"`
+	MemberSince      time.Time               `json:"member_since"`
+}
+
+// IssueCard returns a freshly signed card for an approved member
+func (s *CardService) IssueCard(user *models.User) (*MembershipCard, error) {
+	if !s.IsEnabled() {
+		return nil, ErrCardsDisabled
+	}
+	if user.MembershipStatus != models.MembershipApproved {
+		return nil, errors.New("only approved members have a membership card")
+	}
+
+	expires := time.Now().Add(s.validFor).Truncate(time.Second)
+	payload := make([]byte, 24)
+	copy(payload, user.ID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expires.Unix()))
+
+	enc := base64.RawURLEncoding
+	token := cardTokenPrefix + enc.EncodeToString(payload) + "." + enc.EncodeToString(s.sign(payload))
+
+	code, err := qr.Encode([]byte(token))
+	if err != nil {
+		return nil, err
+	}
+	image, err := code.PNG(cardQRScale)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MembershipCard{
+		MemberID:    user.ID,
+		Name:        user.Name,
+		Tier:        user.Tier,
+		MemberSince: user.CreatedAt,
+		Token:       token,
+		QRCode:      "data:image/png;base64," + base64.StdEncoding.EncodeToString(image),
+		ExpiresAt:   expires,
+	}, nil
+}
+
+// VerifyCard checks a scanned token and that its member is still approved.
+// Only a disabled service or a database failure is returned as an error;
+// a bad card is reported in the verification.
+func (s *CardService) VerifyCard(token string) (*CardVerification, error) {
+	userID, err := s.parseToken(strings.TrimSpace(token))
+	if errors.Is(err, ErrCardsDisabled) {
+		return nil, err
+	}
+	if err != nil {
+		return &CardVerification{Reason: err.Error()}, nil
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &CardVerification{Reason: "member no longer exists"}, nil
+		}
+		return nil, err
+	}
+
+	result := &CardVerification{
+		Valid: user.MembershipStatus == models.MembershipApproved,
+		Member: &CardVerifiedMember{
+			ID:               user.ID,
+			Name:             user.Name,
+			ProfilePicture:   user.ProfilePicture,
+			AvatarURL:        AvatarURL(&user, models.AvatarMedium),
+			Tier:             user.Tier,
+			MembershipStatus: user.MembershipStatus,
+			MemberSince:      user.CreatedAt,
+		},
+	}
+	if !result.Valid {
+		result.Reason = "membership is " + string(user.MembershipStatus)
+	}
+	return result, nil
+}
+
+// parseToken returns the member a card token was issued to
+func (s *CardService) parseToken(token string) (uuid.UUID, error) {
+	if !s.IsEnabled() {
+		return uuid.Nil, ErrCardsDisabled
+	}
+
+	rest, ok := strings.CutPrefix(token, cardTokenPrefix)
+	if !ok {
+		return uuid.Nil, ErrInvalidCard
+	}
+	encPayload, encSig, ok := strings.Cut(rest, ".")
+	if !ok {
+		return uuid.Nil, ErrInvalidCard
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(encPayload)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, ErrInvalidCard
+	}
+	sig, err := enc.DecodeString(encSig)
+	if err != nil || !hmac.Equal(sig, s.sign(payload)) {
+		return uuid.Nil, ErrInvalidCard
+	}
+
+	expires := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0)
+	if time.Now().After(expires) {
+		return uuid.Nil, ErrInvalidCard
+	}
+
+	id, err := uuid.FromBytes(payload[:16])
+	if err != nil {
+		return uuid.Nil, ErrInvalidCard
+	}
+	return id, nil
+}
+
+// sign is keyed for cards alone, so a token signed for another purpose with
+// the same secret can't pass as a card
+func (s *CardService) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret.get())
+	mac.Write([]byte(cardTokenPrefix))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
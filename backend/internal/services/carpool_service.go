@@ -0,0 +1,501 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCarpoolClosed    = domainError(ErrConflict, "carpool_closed", "carpools can only be arranged for upcoming sessions")
+	ErrNotYourCarpool   = domainError(ErrForbidden, "not_your_carpool", "only the driver can do that")
+	ErrCarpoolFull      = domainError(ErrConflict, "carpool_full", "that car has no seats left")
+	ErrAlreadyDriving   = domainError(ErrConflict, "already_driving", "you're offering seats for this session; withdraw your offer to ask for a ride")
+	ErrAlreadyRiding    = domainError(ErrConflict, "already_riding", "you've asked for a ride to this session; withdraw your request to offer seats")
+	ErrCarpoolNotFound  = domainError(ErrNotFound, "carpool_not_found", "carpool not found")
+	ErrSeatsBelowRiders = domainError(ErrConflict, "seats_below_riders", "you've already confirmed more riders than that")
+)
+
+// CarpoolService lets members share lifts to a session. Drivers offer seats
+// from their suburb, riders ask for a lift, and each side is shown the
+// other's best matches. A rider asks a driver, the driver confirms or
+// declines, and both are notified along the way.
+type CarpoolService struct {
+	notificationService *NotificationService
+}
+
+func NewCarpoolService(notificationService *NotificationService) *CarpoolService {
+	return &CarpoolService{notificationService: notificationService}
+}
+
+// CarpoolOfferView is an offer with the riders it has taken on
+type CarpoolOfferView struct {
+	Offer     models.CarpoolOffer
+	SeatsLeft int
+	Riders    []models.CarpoolRequest // confirmed and asked, oldest first
+}
+
+// CarpoolBoard is a session's carpools as one member sees them. Suggestions
+// are the offers with room for a rider, or the open requests for a driver,
+// from the member's suburb first.
+type CarpoolBoard struct {
+	Offers            []CarpoolOfferView
+	OpenRequests      []models.CarpoolRequest
+	MyOffer           *models.CarpoolOffer
+	MyRequest         *models.CarpoolRequest
+	SuggestedOffers   []CarpoolOfferView
+	SuggestedRequests []models.CarpoolRequest
+}
+
+// carpoolSession returns a session carpools can still be arranged for
+func carpoolSession(db *gorm.DB, sessionID uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	if err := db.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if session.Status == models.SessionStatusCancelled || !session.StartsAt.After(time.Now()) {
+		return nil, ErrCarpoolClosed
+	}
+	return &session, nil
+}
+
+// sameSuburb compares suburbs as members type them
+func sameSuburb(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
+
+// Board returns a session's offers and open requests, with suggestions for
+// viewerID
+func (s *CarpoolService) Board(sessionID, viewerID uuid.UUID) (*CarpoolBoard, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	var offers []models.CarpoolOffer
+	if err := database.DB.Preload("Driver").
+		Where("session_id = ?", sessionID).
+		Order("created_at ASC").
+		Find(&offers).Error; err != nil {
+		return nil, err
+	}
+	var requests []models.CarpoolRequest
+	if err := database.DB.Preload("Rider").
+		Where("session_id = ?", sessionID).
+		Order("created_at ASC").
+		Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	board := &CarpoolBoard{Offers: make([]CarpoolOfferView, len(offers))}
+	byOffer := make(map[uuid.UUID]int, len(offers))
+	for i, offer := range offers {
+		board.Offers[i] = CarpoolOfferView{Offer: offer, SeatsLeft: offer.Seats}
+		byOffer[offer.ID] = i
+		if offer.DriverID == viewerID {
+			board.MyOffer = &board.Offers[i].Offer
+		}
+	}
+	for i := range requests {
+		request := requests[i]
+		if request.RiderID == viewerID {
+			board.MyRequest = &requests[i]
+		}
+		if request.OfferID == nil {
+			board.OpenRequests = append(board.OpenRequests, request)
+			continue
+		}
+		if j, ok := byOffer[*request.OfferID]; ok {
+			board.Offers[j].Riders = append(board.Offers[j].Riders, request)
+			if request.Status == models.CarpoolRequestConfirmed {
+				board.Offers[j].SeatsLeft--
+			}
+		}
+	}
+
+	upcoming := session.Status != models.SessionStatusCancelled && session.StartsAt.After(time.Now())
+	switch {
+	case !upcoming:
+	case board.MyOffer != nil && board.Offers[byOffer[board.MyOffer.ID]].SeatsLeft > 0:
+		suburb := board.MyOffer.OriginSuburb
+		board.SuggestedRequests = append(board.SuggestedRequests, board.OpenRequests...)
+		sort.SliceStable(board.SuggestedRequests, func(i, j int) bool {
+			return sameSuburb(board.SuggestedRequests[i].OriginSuburb, suburb) &&
+				!sameSuburb(board.SuggestedRequests[j].OriginSuburb, suburb)
+		})
+	case board.MyRequest != nil && board.MyRequest.Status != models.CarpoolRequestConfirmed:
+		suburb := board.MyRequest.OriginSuburb
+		for _, offer := range board.Offers {
+			if offer.SeatsLeft > 0 {
+				board.SuggestedOffers = append(board.SuggestedOffers, offer)
+			}
+		}
+		sort.SliceStable(board.SuggestedOffers, func(i, j int) bool {
+			a, b := board.SuggestedOffers[i], board.SuggestedOffers[j]
+			if near := sameSuburb(a.Offer.OriginSuburb, suburb); near != sameSuburb(b.Offer.OriginSuburb, suburb) {
+				return near
+			}
+			return a.SeatsLeft > b.SeatsLeft
+		})
+	}
+	return board, nil
+}
+
+// SaveCarpoolOfferInput is a driver's offer of seats
+type SaveCarpoolOfferInput struct {
+	OriginSuburb string
+	Seats        int
+	Notes        string
+}
+
+// SaveOffer offers seats to a session, or changes the driver's offer
+func (s *CarpoolService) SaveOffer(sessionID, driverID uuid.UUID, input SaveCarpoolOfferInput) (*models.CarpoolOffer, error) {
+	if input.Seats < 1 || input.Seats > models.MaxCarpoolSeats {
+		return nil, fmt.Errorf("seats must be between 1 and %d", models.MaxCarpoolSeats)
+	}
+
+	var offer models.CarpoolOffer
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := carpoolSession(tx, sessionID); err != nil {
+			return err
+		}
+		var riding int64
+		if err := tx.Model(&models.CarpoolRequest{}).
+			Where("session_id = ? AND rider_id = ?", sessionID, driverID).
+			Count(&riding).Error; err != nil {
+			return err
+		}
+		if riding > 0 {
+			return ErrAlreadyRiding
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("session_id = ? AND driver_id = ?", sessionID, driverID).
+			First(&offer).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			offer = models.CarpoolOffer{
+				SessionID:    sessionID,
+				DriverID:     driverID,
+				OriginSuburb: input.OriginSuburb,
+				Seats:        input.Seats,
+				Notes:        input.Notes,
+			}
+			return tx.Create(&offer).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		confirmed, err := confirmedRiders(tx, offer.ID)
+		if err != nil {
+			return err
+		}
+		if int64(input.Seats) < confirmed {
+			return ErrSeatsBelowRiders
+		}
+		return tx.Model(&offer).Updates(map[string]interface{}{
+			"origin_suburb": input.OriginSuburb,
+			"seats":         input.Seats,
+			"notes":         input.Notes,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// confirmedRiders counts the riders an offer has confirmed
+func confirmedRiders(tx *gorm.DB, offerID uuid.UUID) (int64, error) {
+	var n int64
+	err := tx.Model(&models.CarpoolRequest{}).
+		Where("offer_id = ? AND status = ?", offerID, models.CarpoolRequestConfirmed).
+		Count(&n).Error
+	return n, err
+}
+
+// DeleteOffer withdraws a driver's offer. Its riders go back to looking for
+// a ride and are told so.
+func (s *CarpoolService) DeleteOffer(ctx context.Context, sessionID, driverID uuid.UUID) error {
+	var offer models.CarpoolOffer
+	var riders []models.CarpoolRequest
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ? AND driver_id = ?", sessionID, driverID).First(&offer).Error; err != nil {
+			return ErrCarpoolNotFound
+		}
+		if err := tx.Where("offer_id = ?", offer.ID).Find(&riders).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.CarpoolRequest{}).Where("offer_id = ?", offer.ID).
+			Updates(map[string]interface{}{
+				"offer_id":     nil,
+				"status":       models.CarpoolRequestOpen,
+				"confirmed_at": nil,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&offer).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if session, err := carpoolSession(database.DB, sessionID); err == nil {
+		driver := memberName(driverID)
+		for _, rider := range riders {
+			s.notify(ctx, rider.RiderID, session, "Your Lift Has Fallen Through",
+				fmt.Sprintf("%s can no longer drive to %s. Your request is open again for other drivers.", driver, carpoolWhen(session)))
+		}
+	}
+	return nil
+}
+
+// SaveCarpoolRequestInput is a rider's request for a lift. OfferID asks a
+// particular driver; without it the request is open for any driver.
+type SaveCarpoolRequestInput struct {
+	OriginSuburb string
+	Notes        string
+	OfferID      *uuid.UUID
+}
+
+// SaveRequest asks for a ride to a session, or changes the rider's request.
+// Asking a new driver tells them, and tells any driver the rider is leaving.
+// Keeping the same driver keeps a confirmed seat.
+func (s *CarpoolService) SaveRequest(ctx context.Context, sessionID, riderID uuid.UUID, input SaveCarpoolRequestInput) (*models.CarpoolRequest, error) {
+	var session *models.Session
+	var request models.CarpoolRequest
+	var previous *uuid.UUID
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if session, err = carpoolSession(tx, sessionID); err != nil {
+			return err
+		}
+		var driving int64
+		if err := tx.Model(&models.CarpoolOffer{}).
+			Where("session_id = ? AND driver_id = ?", sessionID, riderID).
+			Count(&driving).Error; err != nil {
+			return err
+		}
+		if driving > 0 {
+			return ErrAlreadyDriving
+		}
+
+		err = tx.Where("session_id = ? AND rider_id = ?", sessionID, riderID).First(&request).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		previous = request.OfferID
+		sameOffer := (previous == nil && input.OfferID == nil) ||
+			(previous != nil && input.OfferID != nil && *previous == *input.OfferID)
+
+		if input.OfferID != nil && !sameOffer {
+			var offer models.CarpoolOffer
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND session_id = ?", *input.OfferID, sessionID).
+				First(&offer).Error; err != nil {
+				return ErrCarpoolNotFound
+			}
+			confirmed, err := confirmedRiders(tx, offer.ID)
+			if err != nil {
+				return err
+			}
+			if confirmed >= int64(offer.Seats) {
+				return ErrCarpoolFull
+			}
+		}
+
+		request.OriginSuburb = input.OriginSuburb
+		request.Notes = input.Notes
+		if !sameOffer {
+			request.OfferID = input.OfferID
+			request.ConfirmedAt = nil
+			request.Status = models.CarpoolRequestOpen
+			if input.OfferID != nil {
+				request.Status = models.CarpoolRequestAsked
+			}
+		}
+		if request.ID == uuid.Nil {
+			request.SessionID = sessionID
+			request.RiderID = riderID
+			return tx.Create(&request).Error
+		}
+		return tx.Model(&request).Updates(map[string]interface{}{
+			"origin_suburb": request.OriginSuburb,
+			"notes":         request.Notes,
+			"offer_id":      request.OfferID,
+			"status":        request.Status,
+			"confirmed_at":  request.ConfirmedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changed := (previous == nil) != (request.OfferID == nil) ||
+		(previous != nil && request.OfferID != nil && *previous != *request.OfferID)
+	if changed {
+		rider := memberName(riderID)
+		if previous != nil {
+			s.notifyDriver(ctx, *previous, session, "Rider No Longer Needs a Lift",
+				fmt.Sprintf("%s has made other plans for %s.", rider, carpoolWhen(session)))
+		}
+		if request.OfferID != nil {
+			s.notifyDriver(ctx, *request.OfferID, session, "Someone Needs a Lift",
+				fmt.Sprintf("%s from %s has asked to ride with you to %s. Confirm or decline in the app.",
+					rider, request.OriginSuburb, carpoolWhen(session)))
+		}
+	}
+	return &request, nil
+}
+
+// DeleteRequest withdraws a rider's request, telling the driver they'd asked
+func (s *CarpoolService) DeleteRequest(ctx context.Context, sessionID, riderID uuid.UUID) error {
+	var request models.CarpoolRequest
+	if err := database.DB.Where("session_id = ? AND rider_id = ?", sessionID, riderID).First(&request).Error; err != nil {
+		return ErrCarpoolNotFound
+	}
+	if err := database.DB.Delete(&request).Error; err != nil {
+		return err
+	}
+
+	if request.OfferID != nil {
+		if session, err := carpoolSession(database.DB, sessionID); err == nil {
+			s.notifyDriver(ctx, *request.OfferID, session, "Rider No Longer Needs a Lift",
+				fmt.Sprintf("%s has withdrawn their request for a lift to %s.", memberName(riderID), carpoolWhen(session)))
+		}
+	}
+	return nil
+}
+
+// Confirm gives a rider a seat in the driver's car. Drivers can confirm
+// riders who asked them or take on an open request.
+func (s *CarpoolService) Confirm(ctx context.Context, sessionID, requestID, driverID uuid.UUID) (*models.CarpoolRequest, error) {
+	var session *models.Session
+	var offer models.CarpoolOffer
+	var request models.CarpoolRequest
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if session, err = carpoolSession(tx, sessionID); err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("session_id = ? AND driver_id = ?", sessionID, driverID).
+			First(&offer).Error; err != nil {
+			return ErrNotYourCarpool
+		}
+		if err := tx.Where("id = ? AND session_id = ?", requestID, sessionID).First(&request).Error; err != nil {
+			return ErrCarpoolNotFound
+		}
+		if request.OfferID != nil && *request.OfferID != offer.ID {
+			return errors.New("that rider has asked another driver")
+		}
+		if request.Status == models.CarpoolRequestConfirmed {
+			return nil
+		}
+		confirmed, err := confirmedRiders(tx, offer.ID)
+		if err != nil {
+			return err
+		}
+		if confirmed >= int64(offer.Seats) {
+			return ErrCarpoolFull
+		}
+
+		now := time.Now()
+		request.OfferID = &offer.ID
+		request.Status = models.CarpoolRequestConfirmed
+		request.ConfirmedAt = &now
+		return tx.Model(&request).Updates(map[string]interface{}{
+			"offer_id":     offer.ID,
+			"status":       request.Status,
+			"confirmed_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify(ctx, request.RiderID, session, "Lift Confirmed",
+		fmt.Sprintf("%s is driving you to %s from %s.", memberName(driverID), carpoolWhen(session), offer.OriginSuburb))
+	return &request, nil
+}
+
+// Decline turns a rider down, or drops a confirmed rider. Their request is
+// open again for other drivers.
+func (s *CarpoolService) Decline(ctx context.Context, sessionID, requestID, driverID uuid.UUID) error {
+	var session *models.Session
+	var request models.CarpoolRequest
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if session, err = carpoolSession(tx, sessionID); err != nil {
+			return err
+		}
+		var offer models.CarpoolOffer
+		if err := tx.Where("session_id = ? AND driver_id = ?", sessionID, driverID).First(&offer).Error; err != nil {
+			return ErrNotYourCarpool
+		}
+		if err := tx.Where("id = ? AND offer_id = ?", requestID, offer.ID).First(&request).Error; err != nil {
+			return ErrCarpoolNotFound
+		}
+		return tx.Model(&request).Updates(map[string]interface{}{
+			"offer_id":     nil,
+			"status":       models.CarpoolRequestOpen,
+			"confirmed_at": nil,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notify(ctx, request.RiderID, session, "Lift Unavailable",
+		fmt.Sprintf("%s can't take you to %s. Your request is open again for other drivers.", memberName(driverID), carpoolWhen(session)))
+	return nil
+}
+
+// carpoolWhen names a session in carpool notices
+func carpoolWhen(session *models.Session) string {
+	return fmt.Sprintf("%s (%s)", session.Title, session.StartsAt.In(utils.SydneyLocation).Format("Mon 2 Jan, 3:04 PM"))
+}
+
+// memberName is a member's name for notices, or "A member" if they can't be
+// found
+func memberName(userID uuid.UUID) string {
+	var user models.User
+	if err := database.DB.Select("name").First(&user, "id = ?", userID).Error; err != nil || user.Name == "" {
+		return "A member"
+	}
+	return user.Name
+}
+
+// notifyDriver notifies the driver of an offer
+func (s *CarpoolService) notifyDriver(ctx context.Context, offerID uuid.UUID, session *models.Session, title, body string) {
+	var offer models.CarpoolOffer
+	if err := database.DB.Select("driver_id").First(&offer, "id = ?", offerID).Error; err != nil {
+		log.Printf("Error finding carpool offer %s to notify its driver: %v", offerID, err)
+		return
+	}
+	s.notify(ctx, offer.DriverID, session, title, body)
+}
+
+func (s *CarpoolService) notify(ctx context.Context, userID uuid.UUID, session *models.Session, title, body string) {
+	if s.notificationService == nil {
+		return
+	}
+	data := map[string]string{
+		"type":       string(models.NotificationCarpool),
+		"session_id": session.ID.String(),
+	}
+	if err := s.notificationService.SendNotification(ctx, userID, models.NotificationCarpool, title, body, data); err != nil {
+		log.Printf("Error sending carpool notice to user %s: %v", userID, err)
+	}
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// clubBundleVersion is bumped when the bundle changes in a way older
+// servers can't import
+const clubBundleVersion = 1
+
+// importBatchSize bounds the rows inserted per statement on import
+const importBatchSize = 500
+
+// ErrImportNotEmpty is returned when importing into a database that already
+// has members or sessions
+var ErrImportNotEmpty = domainError(ErrConflict, "import_not_empty", "the database already has members or sessions; import only into a new deployment")
+
+// ClubBundle is a portable copy of the club: its settings, members,
+// sessions and history, as JSON that another deployment can import.
+// Personal details are in plain text so the new deployment can encrypt them
+// under its own key. Login secrets, push tokens, notification history,
+// uploaded files and the audit log stay behind.
+type ClubBundle struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+
+	Club                    models.Club                          `json:"club"`
+	Users                   []ExportedUser                       `json:"users"`
+	NotificationPreferences []models.UserNotificationPreferences `json:"notification_preferences"`
+	NotificationTypePrefs   []models.NotificationTypePreference  `json:"notification_type_preferences"`
+	Badges                  []models.UserBadge                   `json:"badges"`
+	Sessions                []ExportedSession                    `json:"sessions"`
+	SeriesRegulars          []models.SeriesRegular               `json:"series_regulars"`
+	RSVPs                   []models.RSVP                        `json:"rsvps"`
+	ArchivedRSVPs           []models.ArchivedRSVP                `json:"archived_rsvps"`
+	GuestRSVPs              []models.GuestRSVP                   `json:"guest_rsvps"`
+	CourtAssignments        []models.CourtAssignment             `json:"court_assignments"`
+	Comments                []models.Comment                     `json:"comments"`
+	CommentSettings         []models.SessionCommentSetting       `json:"comment_settings"`
+	Announcements           []models.Announcement                `json:"announcements"`
+	AnnouncementAcks        []models.AnnouncementAcknowledgement `json:"announcement_acknowledgements"`
+	CommitteeMinutes        []models.CommitteeMinutes            `json:"committee_minutes"`
+	SeasonRollovers         []models.SeasonRollover              `json:"season_rollovers"`
+	BlackoutDates           []models.BlackoutDate                `json:"blackout_dates"`
+	ContactLogs             []models.ContactLog                  `json:"contact_logs"`
+	MessageTemplates        []models.MessageTemplate             `json:"message_templates"`
+	Games                   []models.Game                        `json:"games"`
+	GamePlayers             []models.GamePlayer                  `json:"game_players"`
+	PlayerRatings           []models.PlayerRating                `json:"player_ratings"`
+	PeerRatings             []models.PeerRating                  `json:"peer_ratings"`
+	Tournaments             []models.Tournament                  `json:"tournaments"`
+	TournamentEntries       []models.TournamentEntry             `json:"tournament_entries"`
+	TournamentMatches       []models.TournamentMatch             `json:"tournament_matches"`
+	OrderWindows            []models.OrderWindow                 `json:"order_windows"`
+	OrderItems              []models.OrderItem                   `json:"order_items"`
+	Orders                  []models.Order                       `json:"orders"`
+	OrderLines              []models.OrderLine                   `json:"order_lines"`
+	TrainingProgress        []models.TrainingProgress            `json:"training_progress"`
+	TrainingAttendance      []models.TrainingAttendance          `json:"training_attendance"`
+	SkillAssessments        []models.SkillAssessment             `json:"skill_assessments"`
+	CarpoolOffers           []models.CarpoolOffer                `json:"carpool_offers"`
+	CarpoolRequests         []models.CarpoolRequest              `json:"carpool_requests"`
+	InventoryItems          []models.InventoryItem               `json:"inventory_items"`
+	InventoryCheckouts      []models.InventoryCheckout           `json:"inventory_checkouts"`
+	InventoryMovements      []models.InventoryMovement           `json:"inventory_movements"`
+	JoinRules               []models.JoinRules                   `json:"join_rules"`
+	JoinApprovals           []models.JoinApproval                `json:"join_approvals"`
+	ReferralCodes           []models.ReferralCode                `json:"referral_codes"`
+	PreapprovedMembers      []models.PreapprovedMember           `json:"preapproved_members"`
+	Invites                 []models.Invite                      `json:"invites"`
+	InviteUses              []models.InviteUse                   `json:"invite_uses"`
+	RSVPEvents              []models.RSVPEvent                   `json:"rsvp_events"`
+}
+
+// ExportedUser is a member with the contact and emergency details and login
+// the API otherwise leaves out
+type ExportedUser struct {
+	models.User
+	Auth0ID               string `json:"auth0_id"`
+	Email                 string `json:"email"`
+	PhoneNumber           string `json:"phone_number,omitempty"`
+	EmergencyContactName  string `json:"emergency_contact_name,omitempty"`
+	EmergencyContactPhone string `json:"emergency_contact_phone,omitempty"`
+	MedicalNotes          string `json:"medical_notes,omitempty"`
+}
+
+// ExportedSession is a session with the admin notes the API otherwise leaves out
+type ExportedSession struct {
+	models.Session
+	AdminNotes string `json:"admin_notes,omitempty"`
+}
+
+// ClubImportSummary counts the rows an import created, by bundle section
+type ClubImportSummary map[string]int
+
+// ClubExportService copies the club between deployments
+type ClubExportService struct{}
+
+func NewClubExportService() *ClubExportService {
+	return &ClubExportService{}
+}
+
+// Export reads the whole club into a bundle
+func (s *ClubExportService) Export() (*ClubBundle, error) {
+	bundle := &ClubBundle{Version: clubBundleVersion, ExportedAt: time.Now()}
+
+	if err := database.DB.First(&bundle.Club).Error; err != nil {
+		return nil, fmt.Errorf("reading club: %w", err)
+	}
+
+	var users []models.User
+	var sessions []models.Session
+	for _, section := range []struct {
+		name string
+		dest interface{}
+	}{
+		{"users", &users},
+		{"notification preferences", &bundle.NotificationPreferences},
+		{"notification type preferences", &bundle.NotificationTypePrefs},
+		{"badges", &bundle.Badges},
+		{"sessions", &sessions},
+		{"series regulars", &bundle.SeriesRegulars},
+		{"rsvps", &bundle.RSVPs},
+		{"archived rsvps", &bundle.ArchivedRSVPs},
+		{"guest rsvps", &bundle.GuestRSVPs},
+		{"court assignments", &bundle.CourtAssignments},
+		{"comments", &bundle.Comments},
+		{"comment settings", &bundle.CommentSettings},
+		{"announcements", &bundle.Announcements},
+		{"announcement acknowledgements", &bundle.AnnouncementAcks},
+		{"committee minutes", &bundle.CommitteeMinutes},
+		{"season rollovers", &bundle.SeasonRollovers},
+		{"blackout dates", &bundle.BlackoutDates},
+		{"contact logs", &bundle.ContactLogs},
+		{"message templates", &bundle.MessageTemplates},
+		{"games", &bundle.Games},
+		{"game players", &bundle.GamePlayers},
+		{"player ratings", &bundle.PlayerRatings},
+		{"peer ratings", &bundle.PeerRatings},
+		{"tournaments", &bundle.Tournaments},
+		{"tournament entries", &bundle.TournamentEntries},
+		{"tournament matches", &bundle.TournamentMatches},
+		{"order windows", &bundle.OrderWindows},
+		{"order items", &bundle.OrderItems},
+		{"orders", &bundle.Orders},
+		{"order lines", &bundle.OrderLines},
+		{"training progress", &bundle.TrainingProgress},
+		{"training attendance", &bundle.TrainingAttendance},
+		{"skill assessments", &bundle.SkillAssessments},
+		{"carpool offers", &bundle.CarpoolOffers},
+		{"carpool requests", &bundle.CarpoolRequests},
+		{"inventory items", &bundle.InventoryItems},
+		{"inventory checkouts", &bundle.InventoryCheckouts},
+		{"inventory movements", &bundle.InventoryMovements},
+		{"join rules", &bundle.JoinRules},
+		{"join approvals", &bundle.JoinApprovals},
+		{"referral codes", &bundle.ReferralCodes},
+		{"pre-approved members", &bundle.PreapprovedMembers},
+		{"invites", &bundle.Invites},
+		{"invite uses", &bundle.InviteUses},
+		{"RSVP history", &bundle.RSVPEvents},
+	} {
+		if err := database.DB.Order("id").Find(section.dest).Error; err != nil {
+			return nil, fmt.Errorf("reading %s: %w", section.name, err)
+		}
+	}
+	bundle.Users = make([]ExportedUser, len(users))
+	for i, user := range users {
+		bundle.Users[i] = ExportedUser{
+			User:                  user,
+			Auth0ID:               user.Auth0ID,
+			Email:                 user.Email,
+			PhoneNumber:           user.PhoneNumber,
+			EmergencyContactName:  user.EmergencyContactName,
+			EmergencyContactPhone: user.EmergencyContactPhone,
+			MedicalNotes:          user.MedicalNotes,
+		}
+	}
+	bundle.Sessions = make([]ExportedSession, len(sessions))
+	for i, session := range sessions {
+		bundle.Sessions[i] = ExportedSession{Session: session, AdminNotes: session.AdminNotes}
+	}
+	return bundle, nil
+}
+
+// RecordExport notes in the audit log that actorID exported bundle, which
+// holds members' personal details in plain text
+func (s *ClubExportService) RecordExport(bundle *ClubBundle, actorID uuid.UUID) error {
+	return database.DB.Create(&models.AuditLog{
+		EntityType: "club",
+		EntityID:   bundle.Club.ID,
+		Action:     models.AuditActionClubExported,
+		ActorID:    actorID,
+		NewValue:   fmt.Sprintf("%d members, %d sessions", len(bundle.Users), len(bundle.Sessions)),
+	}).Error
+}
+
+// Import recreates a bundle's club in an empty database, keeping every ID so
+// references between rows survive. Members sign in with the same login as
+// before when the deployment uses the same Auth0 tenant; otherwise they claim
+// their account with an emailed link code on first sign-in.
+func (s *ClubExportService) Import(bundle *ClubBundle) (ClubImportSummary, error) {
+	if bundle.Version < 1 || bundle.Version > clubBundleVersion {
+		return nil, fmt.Errorf("bundle version %d is not supported (this server reads up to %d)", bundle.Version, clubBundleVersion)
+	}
+	if bundle.Club.ID == uuid.Nil {
+		return nil, errors.New("bundle has no club")
+	}
+
+	members := make([]models.User, len(bundle.Users))
+	for i, exported := range bundle.Users {
+		members[i] = exported.User
+		members[i].Auth0ID = exported.Auth0ID
+		members[i].Email = exported.Email
+		members[i].PhoneNumber = exported.PhoneNumber
+		members[i].EmergencyContactName = exported.EmergencyContactName
+		members[i].EmergencyContactPhone = exported.EmergencyContactPhone
+		members[i].MedicalNotes = exported.MedicalNotes
+	}
+	sessions := make([]models.Session, len(bundle.Sessions))
+	for i, exported := range bundle.Sessions {
+		sessions[i] = exported.Session
+		sessions[i].AdminNotes = exported.AdminNotes
+	}
+	// Recurring series' parents before the sessions generated from them
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].RecurringParentID == nil && sessions[j].RecurringParentID != nil
+	})
+
+	summary := ClubImportSummary{}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var users, existingSessions int64
+		if err := tx.Model(&models.User{}).Count(&users).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Session{}).Count(&existingSessions).Error; err != nil {
+			return err
+		}
+		if users > 0 || existingSessions > 0 {
+			return ErrImportNotEmpty
+		}
+
+		// The new deployment's default club gives way to the imported one
+		if err := tx.Where("1 = 1").Delete(&models.Club{}).Error; err != nil {
+			return err
+		}
+
+		for _, section := range []struct {
+			name string
+			rows interface{}
+			n    int
+		}{
+			{"club", &bundle.Club, 1},
+			{"users", &members, len(members)},
+			{"notification_preferences", &bundle.NotificationPreferences, len(bundle.NotificationPreferences)},
+			{"notification_type_preferences", &bundle.NotificationTypePrefs, len(bundle.NotificationTypePrefs)},
+			{"badges", &bundle.Badges, len(bundle.Badges)},
+			{"sessions", &sessions, len(sessions)},
+			{"series_regulars", &bundle.SeriesRegulars, len(bundle.SeriesRegulars)},
+			{"rsvps", &bundle.RSVPs, len(bundle.RSVPs)},
+			{"archived_rsvps", &bundle.ArchivedRSVPs, len(bundle.ArchivedRSVPs)},
+			{"guest_rsvps", &bundle.GuestRSVPs, len(bundle.GuestRSVPs)},
+			{"court_assignments", &bundle.CourtAssignments, len(bundle.CourtAssignments)},
+			{"comments", &bundle.Comments, len(bundle.Comments)},
+			{"comment_settings", &bundle.CommentSettings, len(bundle.CommentSettings)},
+			{"announcements", &bundle.Announcements, len(bundle.Announcements)},
+			{"announcement_acknowledgements", &bundle.AnnouncementAcks, len(bundle.AnnouncementAcks)},
+			{"committee_minutes", &bundle.CommitteeMinutes, len(bundle.CommitteeMinutes)},
+			{"season_rollovers", &bundle.SeasonRollovers, len(bundle.SeasonRollovers)},
+			{"blackout_dates", &bundle.BlackoutDates, len(bundle.BlackoutDates)},
+			{"contact_logs", &bundle.ContactLogs, len(bundle.ContactLogs)},
+			{"message_templates", &bundle.MessageTemplates, len(bundle.MessageTemplates)},
+			{"games", &bundle.Games, len(bundle.Games)},
+			{"game_players", &bundle.GamePlayers, len(bundle.GamePlayers)},
+			{"player_ratings", &bundle.PlayerRatings, len(bundle.PlayerRatings)},
+			{"peer_ratings", &bundle.PeerRatings, len(bundle.PeerRatings)},
+			{"tournaments", &bundle.Tournaments, len(bundle.Tournaments)},
+			{"tournament_entries", &bundle.TournamentEntries, len(bundle.TournamentEntries)},
+			{"tournament_matches", &bundle.TournamentMatches, len(bundle.TournamentMatches)},
+			{"order_windows", &bundle.OrderWindows, len(bundle.OrderWindows)},
+			{"order_items", &bundle.OrderItems, len(bundle.OrderItems)},
+			{"orders", &bundle.Orders, len(bundle.Orders)},
+			{"order_lines", &bundle.OrderLines, len(bundle.OrderLines)},
+			{"training_progress", &bundle.TrainingProgress, len(bundle.TrainingProgress)},
+			{"training_attendance", &bundle.TrainingAttendance, len(bundle.TrainingAttendance)},
+			{"skill_assessments", &bundle.SkillAssessments, len(bundle.SkillAssessments)},
+			{"carpool_offers", &bundle.CarpoolOffers, len(bundle.CarpoolOffers)},
+			{"carpool_requests", &bundle.CarpoolRequests, len(bundle.CarpoolRequests)},
+			{"inventory_items", &bundle.InventoryItems, len(bundle.InventoryItems)},
+			{"inventory_checkouts", &bundle.InventoryCheckouts, len(bundle.InventoryCheckouts)},
+			{"inventory_movements", &bundle.InventoryMovements, len(bundle.InventoryMovements)},
+			{"join_rules", &bundle.JoinRules, len(bundle.JoinRules)},
+			{"join_approvals", &bundle.JoinApprovals, len(bundle.JoinApprovals)},
+			{"referral_codes", &bundle.ReferralCodes, len(bundle.ReferralCodes)},
+			{"preapproved_members", &bundle.PreapprovedMembers, len(bundle.PreapprovedMembers)},
+			{"invites", &bundle.Invites, len(bundle.Invites)},
+			{"invite_uses", &bundle.InviteUses, len(bundle.InviteUses)},
+			{"rsvp_events", &bundle.RSVPEvents, len(bundle.RSVPEvents)},
+		} {
+			if section.n == 0 {
+				continue
+			}
+			if err := insertRows(tx, section.rows); err != nil {
+				return fmt.Errorf("importing %s: %w", section.name, err)
+			}
+			summary[section.name] = section.n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Imported club %q with %d members and %d sessions", bundle.Club.Name, len(bundle.Users), len(sessions))
+	return summary, nil
+}
+
+// insertRows inserts a model or slice of models exactly as they are. GORM's
+// Create would swap a false or zero for the column default (a member who
+// turned push off would get it back on), so the rows go in as column maps.
+func insertRows(tx *gorm.DB, rows interface{}) error {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(rows); err != nil {
+		return err
+	}
+
+	var records []map[string]interface{}
+	add := func(row reflect.Value) {
+		record := make(map[string]interface{}, len(stmt.Schema.DBNames))
+		for _, name := range stmt.Schema.DBNames {
+			// Encrypted fields come back wrapped in their serializer
+			record[name], _ = stmt.Schema.FieldsByDBName[name].ValueOf(tx.Statement.Context, row)
+		}
+		records = append(records, record)
+	}
+	value := reflect.Indirect(reflect.ValueOf(rows))
+	if value.Kind() == reflect.Slice {
+		for i := 0; i < value.Len(); i++ {
+			add(value.Index(i))
+		}
+	} else {
+		add(value)
+	}
+	return tx.Table(stmt.Schema.Table).CreateInBatches(records, importBatchSize).Error
+}
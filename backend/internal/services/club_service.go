@@ -0,0 +1,27 @@
+package services
+
+import (
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+// ClubService reads and saves the club's settings
+type ClubService struct{}
+
+func NewClubService() *ClubService {
+	return &ClubService{}
+}
+
+// GetClub returns the club
+func (s *ClubService) GetClub() (*models.Club, error) {
+	var club models.Club
+	if err := database.DB.First(&club).Error; err != nil {
+		return nil, err
+	}
+	return &club, nil
+}
+
+// SaveClub saves changes to the club's settings
+func (s *ClubService) SaveClub(club *models.Club) error {
+	return database.DB.Save(club).Error
+}
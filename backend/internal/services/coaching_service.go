@@ -0,0 +1,491 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotTrainingSession = domainError(ErrInvalid, "not_training_session", "not a training session")
+	ErrNotYourSession     = domainError(ErrForbidden, "not_your_session", "only the session's coach or an admin can do that")
+)
+
+// trainingLookback is how far back a coach's list of sessions goes, so
+// attendance and assessments can be caught up after the session
+const trainingLookback = 14 * 24 * time.Hour
+
+// CoachingService runs the training program: the training sessions coaches
+// run, who attended them, and coaches' assessments of players' skills.
+// Each player's attendance and assessments hang off their progress record.
+type CoachingService struct{}
+
+func NewCoachingService() *CoachingService {
+	return &CoachingService{}
+}
+
+// ListTrainingSessions returns training sessions from the last fortnight
+// onwards, soonest first: the coach's own, or every one for admins
+func (s *CoachingService) ListTrainingSessions(coach *models.User) ([]models.Session, error) {
+	query := database.DB.Preload("Coach").
+		Where("session_type = ? AND starts_at >= ?", models.SessionTypeTraining, time.Now().Add(-trainingLookback)).
+		Order("starts_at ASC")
+	if !coach.IsAdmin() {
+		query = query.Where("coach_id = ?", coach.ID)
+	}
+
+	var sessions []models.Session
+	if err := query.Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// coachedSession returns a training session that coach runs; admins can
+// act on any training session
+func coachedSession(sessionID uuid.UUID, coach *models.User) (*models.Session, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if session.SessionType != models.SessionTypeTraining {
+		return nil, ErrNotTrainingSession
+	}
+	if !coach.IsAdmin() && (session.CoachID == nil || *session.CoachID != coach.ID) {
+		return nil, ErrNotYourSession
+	}
+	return &session, nil
+}
+
+// UpdateCurriculum sets what a training session covers
+func (s *CoachingService) UpdateCurriculum(sessionID uuid.UUID, coach *models.User, notes string) (*models.Session, error) {
+	session, err := coachedSession(sessionID, coach)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.DB.Model(session).Updates(map[string]interface{}{
+		"curriculum_notes": notes,
+		"updated_at":       time.Now(),
+	}).Error; err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// TrainingRosterEntry is a player at a training session: RSVP'd in, attended
+// or both
+type TrainingRosterEntry struct {
+	User        models.User
+	RSVPStatus  *models.RSVPStatus // nil for a walk-in
+	Waitlisted  bool
+	Attended    bool
+	FromCheckIn bool // attendance came from a check-in rather than the coach
+}
+
+// Roster returns the players RSVP'd in to a training session, in RSVP
+// order, then its waitlist, followed by anyone else recorded as attending
+func (s *CoachingService) Roster(sessionID uuid.UUID, coach *models.User) ([]TrainingRosterEntry, error) {
+	if _, err := coachedSession(sessionID, coach); err != nil {
+		return nil, err
+	}
+
+	rsvps, err := confirmedPlayers(database.DB, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	waitlist, err := waitlistFor(database.DB, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var attendance []models.TrainingAttendance
+	if err := database.DB.Where("session_id = ?", sessionID).Find(&attendance).Error; err != nil {
+		return nil, err
+	}
+	attended := make(map[uuid.UUID]models.TrainingAttendance, len(attendance))
+	for _, a := range attendance {
+		attended[a.UserID] = a
+	}
+
+	roster := make([]TrainingRosterEntry, 0, len(rsvps)+len(waitlist)+len(attendance))
+	for _, rsvp := range rsvps {
+		if rsvp.User == nil {
+			continue
+		}
+		status := rsvp.Status
+		a, ok := attended[rsvp.UserID]
+		roster = append(roster, TrainingRosterEntry{
+			User:        *rsvp.User,
+			RSVPStatus:  &status,
+			Attended:    ok,
+			FromCheckIn: ok && a.RecordedBy == nil,
+		})
+		delete(attended, rsvp.UserID)
+	}
+	for _, entry := range waitlist {
+		if entry.User == nil {
+			continue
+		}
+		status := models.RSVPStatusWaitlisted
+		a, ok := attended[entry.UserID]
+		roster = append(roster, TrainingRosterEntry{
+			User:        *entry.User,
+			RSVPStatus:  &status,
+			Waitlisted:  true,
+			Attended:    ok,
+			FromCheckIn: ok && a.RecordedBy == nil,
+		})
+		delete(attended, entry.UserID)
+	}
+
+	// Walk-ins, by name
+	var walkInIDs []uuid.UUID
+	for id := range attended {
+		walkInIDs = append(walkInIDs, id)
+	}
+	if len(walkInIDs) > 0 {
+		var walkIns []models.User
+		if err := database.DB.Where("id IN ?", walkInIDs).Order("name ASC").Find(&walkIns).Error; err != nil {
+			return nil, err
+		}
+		for _, user := range walkIns {
+			roster = append(roster, TrainingRosterEntry{User: user, Attended: true, FromCheckIn: attended[user.ID].RecordedBy == nil})
+		}
+	}
+	return roster, nil
+}
+
+// RecordAttendance marks players as having attended a training session, or
+// with attended false takes the mark off
+func (s *CoachingService) RecordAttendance(sessionID uuid.UUID, coach *models.User, userIDs []uuid.UUID, attended bool) error {
+	session, err := coachedSession(sessionID, coach)
+	if err != nil {
+		return err
+	}
+	if session.Status == models.SessionStatusCancelled {
+		return errors.New("the session was cancelled")
+	}
+	if utils.NowInSydney().Before(utils.StartOfDay(session.StartsAt)) {
+		return errors.New("attendance can be recorded from the day of the session")
+	}
+
+	if !attended {
+		return database.DB.Where("session_id = ? AND user_id IN ?", sessionID, userIDs).
+			Delete(&models.TrainingAttendance{}).Error
+	}
+
+	var players int64
+	if err := database.DB.Model(&models.User{}).
+		Where("id IN ? AND membership_status = ?", userIDs, models.MembershipApproved).
+		Count(&players).Error; err != nil {
+		return err
+	}
+	if int(players) != len(uniqueUUIDs(userIDs)) {
+		return errors.New("attendance can only be recorded for approved members")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, userID := range userIDs {
+			if err := recordTrainingAttendance(tx, sessionID, userID, &coach.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func uniqueUUIDs(ids []uuid.UUID) map[uuid.UUID]bool {
+	set := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// recordTrainingAttendance adds a training session to a player's progress
+// record, starting the record if need be. recordedBy is nil when it comes
+// from a check-in. Recording twice keeps the first.
+func recordTrainingAttendance(tx *gorm.DB, sessionID, userID uuid.UUID, recordedBy *uuid.UUID) error {
+	progress, err := progressRecord(tx, userID)
+	if err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.TrainingAttendance{
+		ProgressID: progress.ID,
+		SessionID:  sessionID,
+		UserID:     userID,
+		RecordedBy: recordedBy,
+	}).Error
+}
+
+// progressRecord returns a player's progress record, starting one if they
+// don't have one yet
+func progressRecord(tx *gorm.DB, userID uuid.UUID) (*models.TrainingProgress, error) {
+	if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}}, DoNothing: true}).
+		Create(&models.TrainingProgress{UserID: userID}).Error; err != nil {
+		return nil, err
+	}
+	var progress models.TrainingProgress
+	if err := tx.Where("user_id = ?", userID).First(&progress).Error; err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+type SkillAssessmentInput struct {
+	Skill      string
+	Score      int
+	Notes      string
+	SessionID  *uuid.UUID // the training session it was made at, if any
+	AssessedAt *time.Time // defaults to now
+}
+
+// RecordAssessment records a coach's score for one of a player's skills
+func (s *CoachingService) RecordAssessment(coach *models.User, userID uuid.UUID, input SkillAssessmentInput) (*models.SkillAssessment, error) {
+	var player models.User
+	if err := database.DB.First(&player, "id = ?", userID).Error; err != nil {
+		return nil, domainError(ErrNotFound, "player_not_found", "player not found")
+	}
+	if !player.IsApproved() {
+		return nil, errors.New("only approved members can be assessed")
+	}
+
+	skill := normalizeSkill(input.Skill)
+	if skill == "" {
+		return nil, errors.New("skill is required")
+	}
+	if len(skill) > 100 {
+		return nil, errors.New("skill must be at most 100 characters")
+	}
+	if input.Score < models.MinSkillScore || input.Score > models.MaxSkillScore {
+		return nil, fmt.Errorf("score must be between %d and %d", models.MinSkillScore, models.MaxSkillScore)
+	}
+	assessedAt := time.Now()
+	if input.AssessedAt != nil {
+		if input.AssessedAt.After(assessedAt) {
+			return nil, errors.New("assessed_at can't be in the future")
+		}
+		assessedAt = *input.AssessedAt
+	}
+	if input.SessionID != nil {
+		var session models.Session
+		if err := database.DB.First(&session, "id = ?", *input.SessionID).Error; err != nil {
+			return nil, ErrSessionNotFound
+		}
+		if session.SessionType != models.SessionTypeTraining {
+			return nil, ErrNotTrainingSession
+		}
+	}
+
+	assessment := models.SkillAssessment{
+		UserID:     userID,
+		Skill:      skill,
+		Score:      input.Score,
+		Notes:      strings.TrimSpace(input.Notes),
+		SessionID:  input.SessionID,
+		AssessedBy: coach.ID,
+		AssessedAt: assessedAt,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		progress, err := progressRecord(tx, userID)
+		if err != nil {
+			return err
+		}
+		assessment.ProgressID = progress.ID
+		return tx.Create(&assessment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &assessment, nil
+}
+
+// normalizeSkill lower-cases a skill name and tidies its spacing, so
+// "Net  play" and "net play" are the same skill over time
+func normalizeSkill(skill string) string {
+	return strings.ToLower(strings.Join(strings.Fields(skill), " "))
+}
+
+// DeleteAssessment removes an assessment made in error; only the coach who
+// made it or an admin can
+func (s *CoachingService) DeleteAssessment(id uuid.UUID, coach *models.User) error {
+	var assessment models.SkillAssessment
+	if err := database.DB.First(&assessment, "id = ?", id).Error; err != nil {
+		return domainError(ErrNotFound, "assessment_not_found", "assessment not found")
+	}
+	if !coach.IsAdmin() && assessment.AssessedBy != coach.ID {
+		return errors.New("only the coach who made an assessment or an admin can delete it")
+	}
+	return database.DB.Delete(&assessment).Error
+}
+
+// UpdateGoals sets what a player is working towards
+func (s *CoachingService) UpdateGoals(userID uuid.UUID, goals string) (*models.TrainingProgress, error) {
+	var player models.User
+	if err := database.DB.First(&player, "id = ?", userID).Error; err != nil {
+		return nil, domainError(ErrNotFound, "player_not_found", "player not found")
+	}
+
+	var progress *models.TrainingProgress
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if progress, err = progressRecord(tx, userID); err != nil {
+			return err
+		}
+		return tx.Model(progress).Updates(map[string]interface{}{"goals": strings.TrimSpace(goals), "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// TrainingSessionAttended is a training session on a player's record
+type TrainingSessionAttended struct {
+	SessionID   uuid.UUID `json:"session_id"`
+	Title       string    `json:"title"`
+	StartsAt    time.Time `json:"starts_at"`
+	FromCheckIn bool      `json:"from_check_in"`
+}
+
+// SkillProgress is one skill's assessments, oldest first
+type SkillProgress struct {
+	Skill       string                   `json:"skill"`
+	FirstScore  int                      `json:"first_score"`
+	LatestScore int                      `json:"latest_score"`
+	Assessments []models.SkillAssessment `json:"assessments"`
+}
+
+// TrainingProgressReport is a player's training record
+type TrainingProgressReport struct {
+	UserID           uuid.UUID                 `json:"user_id"`
+	Goals            string                    `json:"goals"`
+	SessionsAttended int                       `json:"sessions_attended"`
+	LastAttendedAt   *time.Time                `json:"last_attended_at,omitempty"`
+	Attendance       []TrainingSessionAttended `json:"attendance"` // newest first
+	Skills           []SkillProgress           `json:"skills"`     // by skill
+}
+
+// Progress returns a player's training record, empty if they haven't
+// trained or been assessed yet
+func (s *CoachingService) Progress(userID uuid.UUID) (*TrainingProgressReport, error) {
+	report := &TrainingProgressReport{UserID: userID, Attendance: []TrainingSessionAttended{}, Skills: []SkillProgress{}}
+
+	var progress models.TrainingProgress
+	err := database.DB.Where("user_id = ?", userID).First(&progress).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.Goals = progress.Goals
+
+	var attendance []models.TrainingAttendance
+	if err := database.DB.Preload("Session").
+		Joins("JOIN sessions ON sessions.id = training_attendances.session_id").
+		Where("training_attendances.progress_id = ?", progress.ID).
+		Order("sessions.starts_at DESC").
+		Find(&attendance).Error; err != nil {
+		return nil, err
+	}
+	for _, a := range attendance {
+		if a.Session == nil {
+			continue
+		}
+		report.Attendance = append(report.Attendance, TrainingSessionAttended{
+			SessionID:   a.SessionID,
+			Title:       a.Session.Title,
+			StartsAt:    a.Session.StartsAt,
+			FromCheckIn: a.RecordedBy == nil,
+		})
+	}
+	report.SessionsAttended = len(report.Attendance)
+	if len(report.Attendance) > 0 {
+		report.LastAttendedAt = &report.Attendance[0].StartsAt
+	}
+
+	var assessments []models.SkillAssessment
+	if err := database.DB.Where("progress_id = ?", progress.ID).
+		Order("assessed_at ASC").
+		Find(&assessments).Error; err != nil {
+		return nil, err
+	}
+	bySkill := make(map[string][]models.SkillAssessment)
+	for _, a := range assessments {
+		bySkill[a.Skill] = append(bySkill[a.Skill], a)
+	}
+	for skill, history := range bySkill {
+		report.Skills = append(report.Skills, SkillProgress{
+			Skill:       skill,
+			FirstScore:  history[0].Score,
+			LatestScore: history[len(history)-1].Score,
+			Assessments: history,
+		})
+	}
+	sort.Slice(report.Skills, func(i, j int) bool { return report.Skills[i].Skill < report.Skills[j].Skill })
+	return report, nil
+}
+
+// Trainee is a player with a training record, for coaches' player list
+type Trainee struct {
+	User             models.User
+	SessionsAttended int
+	LastAttendedAt   *time.Time
+	LastAssessedAt   *time.Time
+}
+
+// ListTrainees returns every player with a training record, by name
+func (s *CoachingService) ListTrainees() ([]Trainee, error) {
+	var rows []struct {
+		UserID           uuid.UUID
+		SessionsAttended int
+		LastAttendedAt   *time.Time
+		LastAssessedAt   *time.Time
+	}
+	if err := database.DB.Raw(`SELECT p.user_id,
+			(SELECT COUNT(*) FROM training_attendances a WHERE a.progress_id = p.id) AS sessions_attended,
+			(SELECT MAX(s.starts_at) FROM training_attendances a JOIN sessions s ON s.id = a.session_id WHERE a.progress_id = p.id) AS last_attended_at,
+			(SELECT MAX(sa.assessed_at) FROM skill_assessments sa WHERE sa.progress_id = p.id) AS last_assessed_at
+		FROM training_progresses p`).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []Trainee{}, nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.UserID
+	}
+	var users []models.User
+	if err := database.DB.Where("id IN ? AND membership_status = ?", ids, models.MembershipApproved).
+		Order("name ASC").
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	byUser := make(map[uuid.UUID]int, len(rows))
+	for i, row := range rows {
+		byUser[row.UserID] = i
+	}
+
+	trainees := make([]Trainee, len(users))
+	for i, user := range users {
+		row := rows[byUser[user.ID]]
+		trainees[i] = Trainee{
+			User:             user,
+			SessionsAttended: row.SessionsAttended,
+			LastAttendedAt:   row.LastAttendedAt,
+			LastAssessedAt:   row.LastAssessedAt,
+		}
+	}
+	return trainees, nil
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// commentPreviewLength bounds how much of a comment its notification quotes
+const commentPreviewLength = 140
+
+type CommentService struct {
+	moderationService   *ModerationService
+	notificationService *NotificationService
+}
+
+func NewCommentService(moderationService *ModerationService, notificationService *NotificationService) *CommentService {
+	return &CommentService{moderationService: moderationService, notificationService: notificationService}
+}
+
+// CommentNotificationSetting is a member's comment notification level for a
+// session, and whether it comes from their default preference
+type CommentNotificationSetting struct {
+	SessionID uuid.UUID                       `json:"session_id"`
+	Level     models.CommentNotificationLevel `json:"level"`
+	IsDefault bool                            `json:"is_default"`
+}
+
+// CreateComment adds a comment to a session after running the content filter
+func (s *CommentService) CreateComment(sessionID, userID uuid.UUID, body string) (*models.Comment, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	if err := s.moderationService.CheckText(body); err != nil {
+		return nil, err
+	}
+
+	comment := models.Comment{
+		SessionID: sessionID,
+		UserID:    userID,
+		Body:      body,
+	}
+	if err := database.DB.Create(&comment).Error; err != nil {
+		return nil, err
+	}
+
+	database.DB.Preload("User").First(&comment, "id = ?", comment.ID)
+
+	s.notifyComment(session, comment)
+
+	return &comment, nil
+}
+
+// notifyComment tells members about a new comment according to their level
+// for the session: confirmed players and followers on "all", and anyone it
+// @mentions unless they chose "none"
+func (s *CommentService) notifyComment(session models.Session, comment models.Comment) {
+	if s.notificationService == nil {
+		return
+	}
+
+	var members []models.User
+	if err := database.DB.Select("id", "name").
+		Where("membership_status = ?", models.MembershipApproved).
+		Find(&members).Error; err != nil {
+		log.Printf("Error fetching members for comment mentions: %v", err)
+		return
+	}
+	mentioned := make(map[uuid.UUID]bool)
+	for _, id := range parseMentions(comment.Body, members) {
+		mentioned[id] = true
+	}
+
+	var confirmed []uuid.UUID
+	if err := database.DB.Model(&models.RSVP{}).
+		Where("session_id = ? AND status = ?", session.ID, models.RSVPStatusIn).
+		Pluck("user_id", &confirmed).Error; err != nil {
+		log.Printf("Error fetching players for comment notification: %v", err)
+		return
+	}
+	var settings []models.SessionCommentSetting
+	if err := database.DB.Where("session_id = ?", session.ID).Find(&settings).Error; err != nil {
+		log.Printf("Error fetching comment settings for session %s: %v", session.ID, err)
+		return
+	}
+
+	levels := make(map[uuid.UUID]models.CommentNotificationLevel)
+	for _, setting := range settings {
+		levels[setting.UserID] = setting.Level
+	}
+	candidates := make(map[uuid.UUID]bool)
+	for _, id := range confirmed {
+		candidates[id] = true
+	}
+	for id := range mentioned {
+		candidates[id] = true
+	}
+	for id, level := range levels {
+		if level == models.CommentNotifyAll {
+			candidates[id] = true
+		}
+	}
+	delete(candidates, comment.UserID)
+	if len(candidates) == 0 {
+		return
+	}
+
+	// Members without a setting for the session fall back to their preference
+	var defaults []uuid.UUID
+	for id := range candidates {
+		if _, ok := levels[id]; !ok {
+			defaults = append(defaults, id)
+		}
+	}
+	if len(defaults) > 0 {
+		var prefs []models.UserNotificationPreferences
+		if err := database.DB.Select("user_id", "comment_notifications").
+			Where("user_id IN ?", defaults).Find(&prefs).Error; err != nil {
+			log.Printf("Error fetching comment preferences: %v", err)
+			return
+		}
+		for _, p := range prefs {
+			levels[p.UserID] = p.CommentNotifications
+		}
+	}
+
+	author := "Someone"
+	if comment.User != nil {
+		author = comment.User.Name
+	}
+	preview := []rune(comment.Body)
+	if len(preview) > commentPreviewLength {
+		preview = append(preview[:commentPreviewLength-1], '…')
+	}
+
+	var messages []NotificationMessage
+	for id := range candidates {
+		level, ok := levels[id]
+		if !ok {
+			level = models.CommentNotifyAll
+		}
+		if level == models.CommentNotifyNone || (level == models.CommentNotifyMentions && !mentioned[id]) {
+			continue
+		}
+
+		title := "New comment on " + session.Title
+		data := map[string]string{
+			"type":       string(models.NotificationSessionComment),
+			"session_id": session.ID.String(),
+			"comment_id": comment.ID.String(),
+		}
+		if mentioned[id] {
+			title = author + " mentioned you on " + session.Title
+			data["mentioned"] = "true"
+		}
+		messages = append(messages, NotificationMessage{
+			UserID: id,
+			Title:  title,
+			Body:   author + ": " + string(preview),
+			Data:   data,
+		})
+	}
+	if len(messages) == 0 {
+		return
+	}
+
+	// Sent in the background, so don't tie it to the request context
+	go func() {
+		if _, err := s.notificationService.SendBatch(context.Background(), models.NotificationSessionComment, messages); err != nil {
+			log.Printf("Failed to send comment notifications for session %s: %v", session.ID, err)
+		}
+	}()
+}
+
+// GetNotificationSetting returns a member's comment notification level for a
+// session, falling back to their default preference
+func (s *CommentService) GetNotificationSetting(sessionID, userID uuid.UUID) (*CommentNotificationSetting, error) {
+	if err := database.DB.Select("id").First(&models.Session{}, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	var setting models.SessionCommentSetting
+	err := database.DB.Where("session_id = ? AND user_id = ?", sessionID, userID).Limit(1).Find(&setting).Error
+	if err != nil {
+		return nil, err
+	}
+	if setting.ID != uuid.Nil {
+		return &CommentNotificationSetting{SessionID: sessionID, Level: setting.Level}, nil
+	}
+
+	prefs, err := s.notificationService.GetUserPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &CommentNotificationSetting{SessionID: sessionID, Level: prefs.CommentNotifications, IsDefault: true}, nil
+}
+
+// SetNotificationSetting sets a member's comment notification level for a session
+func (s *CommentService) SetNotificationSetting(sessionID, userID uuid.UUID, level models.CommentNotificationLevel) (*CommentNotificationSetting, error) {
+	if !level.IsValid() {
+		return nil, errors.New("level must be all, mentions or none")
+	}
+	if err := database.DB.Select("id").First(&models.Session{}, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	setting := models.SessionCommentSetting{SessionID: sessionID, UserID: userID, Level: level}
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "session_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"level": level, "updated_at": time.Now()}),
+	}).Create(&setting).Error; err != nil {
+		return nil, err
+	}
+	return &CommentNotificationSetting{SessionID: sessionID, Level: level}, nil
+}
+
+// ResetNotificationSetting removes a member's override for a session, so
+// their default preference applies again
+func (s *CommentService) ResetNotificationSetting(sessionID, userID uuid.UUID) (*CommentNotificationSetting, error) {
+	if err := database.DB.Where("session_id = ? AND user_id = ?", sessionID, userID).
+		Delete(&models.SessionCommentSetting{}).Error; err != nil {
+		return nil, err
+	}
+	return s.GetNotificationSetting(sessionID, userID)
+}
+
+// ListComments returns a session's comments oldest first; hidden comments are
+// only included for admins
+func (s *CommentService) ListComments(sessionID uuid.UUID, includeHidden bool) ([]models.Comment, error) {
+	var comments []models.Comment
+	query := database.DB.Where("session_id = ?", sessionID)
+	if !includeHidden {
+		query = query.Where("hidden = ?", false)
+	}
+	if err := query.Preload("User").
+		Order("created_at ASC").
+		Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// DeleteComment removes a comment; members can only delete their own
+func (s *CommentService) DeleteComment(commentID, userID uuid.UUID, byAdmin bool) error {
+	var comment models.Comment
+	if err := database.DB.First(&comment, "id = ?", commentID).Error; err != nil {
+		return domainError(ErrNotFound, "comment_not_found", "comment not found")
+	}
+	if !byAdmin && comment.UserID != userID {
+		return errors.New("you can only delete your own comments")
+	}
+	return database.DB.Delete(&comment).Error
+}
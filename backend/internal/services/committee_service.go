@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMinutesNotFound      = domainError(ErrNotFound, "minutes_not_found", "minutes not found")
+	ErrCommitteeNotApproved = domainError(ErrInvalid, "not_approved", "only approved members can sit on the committee")
+	ErrMinutesNotEditable   = domainError(ErrForbidden, "minutes_not_editable", "only the minutes' author, the secretary or an admin can change them")
+)
+
+// CommitteeService keeps the club committee: who holds which role, and the
+// minutes of its meetings. Committee documents and announcements live with
+// the rest, marked for the committee only.
+type CommitteeService struct{}
+
+func NewCommitteeService() *CommitteeService {
+	return &CommitteeService{}
+}
+
+// MinutesInput is the content of a meeting's minutes
+type MinutesInput struct {
+	MeetingDate time.Time
+	Title       string
+	Body        string
+}
+
+// ListCommittee returns the members on the committee, office holders first
+func (s *CommitteeService) ListCommittee() ([]models.User, error) {
+	var users []models.User
+	err := database.DB.Where("committee_role != ? AND membership_status = ?", models.CommitteeNone, models.MembershipApproved).
+		Order(gorm.Expr("CASE committee_role WHEN ? THEN 0 WHEN ? THEN 1 WHEN ? THEN 2 ELSE 3 END",
+			models.CommitteePresident, models.CommitteeTreasurer, models.CommitteeSecretary)).
+		Order("name ASC").
+		Find(&users).Error
+	return users, err
+}
+
+// SetCommitteeRole gives a member a committee role, or takes theirs away
+// with CommitteeNone. The president, treasurer and secretary are held by one
+// member at a time, so giving someone an office moves it from whoever held
+// it. Each change is audited.
+func (s *CommitteeService) SetCommitteeRole(userID uuid.UUID, role models.CommitteeRole, actorID uuid.UUID) (*models.User, error) {
+	if !role.IsValid() {
+		return nil, domainError(ErrInvalid, "invalid_committee_role", "unknown committee role")
+	}
+
+	var user models.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return ErrUserNotFound
+		}
+		if role != models.CommitteeNone && !user.IsApproved() {
+			return ErrCommitteeNotApproved
+		}
+		if user.CommitteeRole == role {
+			return nil
+		}
+
+		if role.IsOffice() {
+			var holders []models.User
+			if err := tx.Where("committee_role = ? AND id != ?", role, userID).Find(&holders).Error; err != nil {
+				return err
+			}
+			for _, holder := range holders {
+				if err := setCommitteeRole(tx, &holder, models.CommitteeNone, actorID); err != nil {
+					return err
+				}
+			}
+		}
+		return setCommitteeRole(tx, &user, role, actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func setCommitteeRole(tx *gorm.DB, user *models.User, role models.CommitteeRole, actorID uuid.UUID) error {
+	old := user.CommitteeRole
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"committee_role": role,
+		"updated_at":     time.Now(),
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.AuditLog{
+		EntityType: "user",
+		EntityID:   user.ID,
+		Action:     models.AuditActionCommitteeRoleChanged,
+		ActorID:    actorID,
+		OldValue:   string(old),
+		NewValue:   string(role),
+	}).Error
+}
+
+// ListMinutes returns meeting minutes, most recent meeting first
+func (s *CommitteeService) ListMinutes() ([]models.CommitteeMinutes, error) {
+	var minutes []models.CommitteeMinutes
+	err := database.DB.Preload("Creator").
+		Order("meeting_date DESC, created_at DESC").
+		Find(&minutes).Error
+	return minutes, err
+}
+
+// GetMinutes returns one meeting's minutes
+func (s *CommitteeService) GetMinutes(id uuid.UUID) (*models.CommitteeMinutes, error) {
+	var minutes models.CommitteeMinutes
+	if err := database.DB.Preload("Creator").First(&minutes, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMinutesNotFound
+		}
+		return nil, err
+	}
+	return &minutes, nil
+}
+
+// CreateMinutes records the minutes of a meeting
+func (s *CommitteeService) CreateMinutes(input MinutesInput, createdBy uuid.UUID) (*models.CommitteeMinutes, error) {
+	minutes := models.CommitteeMinutes{
+		MeetingDate: input.MeetingDate,
+		Title:       input.Title,
+		Body:        input.Body,
+		CreatedBy:   createdBy,
+	}
+	if err := database.DB.Create(&minutes).Error; err != nil {
+		return nil, err
+	}
+	return s.GetMinutes(minutes.ID)
+}
+
+// UpdateMinutes corrects a meeting's minutes
+func (s *CommitteeService) UpdateMinutes(id uuid.UUID, input MinutesInput, editor *models.User) (*models.CommitteeMinutes, error) {
+	minutes, err := s.editableMinutes(id, editor)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.DB.Model(minutes).Updates(map[string]interface{}{
+		"meeting_date": input.MeetingDate,
+		"title":        input.Title,
+		"body":         input.Body,
+		"updated_at":   time.Now(),
+	}).Error; err != nil {
+		return nil, err
+	}
+	return s.GetMinutes(id)
+}
+
+// DeleteMinutes removes a meeting's minutes
+func (s *CommitteeService) DeleteMinutes(id uuid.UUID, editor *models.User) error {
+	minutes, err := s.editableMinutes(id, editor)
+	if err != nil {
+		return err
+	}
+	return database.DB.Delete(minutes).Error
+}
+
+// editableMinutes returns minutes editor may change: the author's own, and
+// any as secretary or admin
+func (s *CommitteeService) editableMinutes(id uuid.UUID, editor *models.User) (*models.CommitteeMinutes, error) {
+	minutes, err := s.GetMinutes(id)
+	if err != nil {
+		return nil, err
+	}
+	if minutes.CreatedBy != editor.ID && editor.CommitteeRole != models.CommitteeSecretary && !editor.IsAdmin() {
+		return nil, ErrMinutesNotEditable
+	}
+	return minutes, nil
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrContactNotFound      = domainError(ErrNotFound, "contact_not_found", "contact log entry not found")
+	ErrContactInFuture      = domainError(ErrInvalid, "contact_in_future", "a contact can't be logged for a time that hasn't happened yet")
+	ErrAssigneeNotAdmin     = domainError(ErrInvalid, "assignee_not_admin", "follow-ups can only be assigned to an admin")
+	ErrNoFollowUp           = domainError(ErrConflict, "no_follow_up", "this contact has no follow-up")
+	ErrFollowUpDone         = domainError(ErrConflict, "follow_up_done", "this follow-up has already been done")
+	ErrInvalidContactMethod = domainError(ErrInvalid, "invalid_contact_method", "unknown contact method")
+)
+
+// ContactLogService keeps the log of admins' off-platform contact with
+// members, and reminds admins of the follow-ups they've taken on
+type ContactLogService struct {
+	notificationService *NotificationService
+}
+
+func NewContactLogService(notificationService *NotificationService) *ContactLogService {
+	return &ContactLogService{notificationService: notificationService}
+}
+
+// ContactLogInput is a contact with a member. ContactedAt defaults to now.
+// A follow-up without AssignedTo is the logging admin's.
+type ContactLogInput struct {
+	Method      models.ContactMethod
+	Subject     string
+	Notes       string
+	ContactedAt *time.Time
+	FollowUpAt  *time.Time
+	AssignedTo  *uuid.UUID
+}
+
+// TimelineEntry is one thing on a member's timeline: a contact with them,
+// or an admin change to their account
+type TimelineEntry struct {
+	Kind    string             `json:"kind"` // "contact" or "audit"
+	At      time.Time          `json:"at"`
+	Contact *models.ContactLog `json:"contact,omitempty"`
+	Audit   *models.AuditLog   `json:"audit,omitempty"`
+}
+
+// apply checks input and copies it onto entry, defaulting the follow-up to
+// actorID
+func (input ContactLogInput) apply(tx *gorm.DB, entry *models.ContactLog, actorID uuid.UUID) error {
+	switch input.Method {
+	case models.ContactPhone, models.ContactEmail, models.ContactSMS, models.ContactInPerson, models.ContactOther:
+	default:
+		return ErrInvalidContactMethod
+	}
+	now := time.Now()
+	contactedAt := now
+	if input.ContactedAt != nil {
+		contactedAt = *input.ContactedAt
+	}
+	if contactedAt.After(now.Add(time.Minute)) {
+		return ErrContactInFuture
+	}
+
+	var assignedTo *uuid.UUID
+	if input.FollowUpAt != nil {
+		assignedTo = input.AssignedTo
+		if assignedTo == nil {
+			assignedTo = &actorID
+		}
+		var assignee models.User
+		if err := tx.First(&assignee, "id = ?", *assignedTo).Error; err != nil || !assignee.IsAdmin() {
+			return ErrAssigneeNotAdmin
+		}
+	}
+
+	// A new due date or owner gets a new reminder
+	if !sameTime(entry.FollowUpAt, input.FollowUpAt) || !sameID(entry.AssignedTo, assignedTo) {
+		entry.RemindedAt = nil
+	}
+	entry.Method = input.Method
+	entry.Subject = input.Subject
+	entry.Notes = input.Notes
+	entry.ContactedAt = contactedAt
+	entry.FollowUpAt = input.FollowUpAt
+	entry.AssignedTo = assignedTo
+	return nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func sameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// LogContact records a contact with a member
+func (s *ContactLogService) LogContact(userID uuid.UUID, input ContactLogInput, actorID uuid.UUID) (*models.ContactLog, error) {
+	entry := models.ContactLog{UserID: userID, LoggedBy: actorID}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return ErrUserNotFound
+		}
+		if err := input.apply(tx, &entry, actorID); err != nil {
+			return err
+		}
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetContact(entry.ID)
+}
+
+// GetContact returns a contact log entry with who it's about, who logged
+// it and who follows it up
+func (s *ContactLogService) GetContact(id uuid.UUID) (*models.ContactLog, error) {
+	var entry models.ContactLog
+	if err := database.DB.Preload("User").Preload("Logger").Preload("Assignee").
+		First(&entry, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// UpdateContact corrects a contact log entry, or changes its follow-up's
+// due date or owner, as when an admin hands over to another. Any admin can,
+// so the log outlives whoever wrote it.
+func (s *ContactLogService) UpdateContact(id uuid.UUID, input ContactLogInput, actorID uuid.UUID) (*models.ContactLog, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var entry models.ContactLog
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entry, "id = ?", id).Error; err != nil {
+			return ErrContactNotFound
+		}
+		if err := input.apply(tx, &entry, actorID); err != nil {
+			return err
+		}
+		return tx.Save(&entry).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetContact(id)
+}
+
+// DeleteContact removes a contact log entry logged by mistake
+func (s *ContactLogService) DeleteContact(id uuid.UUID) error {
+	result := database.DB.Delete(&models.ContactLog{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrContactNotFound
+	}
+	return nil
+}
+
+// CompleteFollowUp marks a contact's follow-up done. Whatever came of it is
+// best logged as a contact of its own.
+func (s *ContactLogService) CompleteFollowUp(id, actorID uuid.UUID) (*models.ContactLog, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var entry models.ContactLog
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entry, "id = ?", id).Error; err != nil {
+			return ErrContactNotFound
+		}
+		if entry.FollowUpAt == nil {
+			return ErrNoFollowUp
+		}
+		if entry.FollowUpDoneAt != nil {
+			return ErrFollowUpDone
+		}
+		return tx.Model(&entry).Updates(map[string]interface{}{
+			"follow_up_done_at": time.Now(),
+			"follow_up_done_by": actorID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetContact(id)
+}
+
+// OpenFollowUps returns follow-ups nobody has done, soonest due first,
+// optionally only those assigned to one admin. It's the list to go through
+// when handing the role over.
+func (s *ContactLogService) OpenFollowUps(assignedTo *uuid.UUID) ([]models.ContactLog, error) {
+	query := database.DB.Preload("User").Preload("Logger").Preload("Assignee").
+		Where("follow_up_at IS NOT NULL AND follow_up_done_at IS NULL")
+	if assignedTo != nil {
+		query = query.Where("assigned_to = ?", *assignedTo)
+	}
+	var entries []models.ContactLog
+	err := query.Order("follow_up_at ASC").Find(&entries).Error
+	return entries, err
+}
+
+// UserTimeline returns a member's contact log and the admin changes made to
+// their account, newest first
+func (s *ContactLogService) UserTimeline(userID uuid.UUID) ([]TimelineEntry, error) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var contacts []models.ContactLog
+	if err := database.DB.Preload("Logger").Preload("Assignee").
+		Where("user_id = ?", userID).Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+	var audits []models.AuditLog
+	if err := database.DB.Preload("Actor").
+		Where("entity_type = ? AND entity_id = ?", "user", userID).Find(&audits).Error; err != nil {
+		return nil, err
+	}
+
+	timeline := make([]TimelineEntry, 0, len(contacts)+len(audits))
+	for i := range contacts {
+		timeline = append(timeline, TimelineEntry{Kind: "contact", At: contacts[i].ContactedAt, Contact: &contacts[i]})
+	}
+	for i := range audits {
+		timeline = append(timeline, TimelineEntry{Kind: "audit", At: audits[i].CreatedAt, Audit: &audits[i]})
+	}
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].At.After(timeline[j].At)
+	})
+	return timeline, nil
+}
+
+// SendFollowUpReminders reminds admins of follow-ups that have fallen due,
+// once each. A follow-up whose owner is no longer an admin goes to every
+// admin, so it isn't lost in a handover.
+func (s *ContactLogService) SendFollowUpReminders(ctx context.Context, report *JobReport) error {
+	var due []models.ContactLog
+	if err := database.DB.Preload("User").Preload("Assignee").
+		Where("follow_up_at <= ? AND follow_up_done_at IS NULL AND reminded_at IS NULL", time.Now()).
+		Order("follow_up_at ASC").
+		Find(&due).Error; err != nil {
+		return fmt.Errorf("fetching due follow-ups: %w", err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	var adminIDs []uuid.UUID
+	if err := database.DB.Model(&models.User{}).
+		Where("role = ?", models.RoleAdmin).
+		Pluck("id", &adminIDs).Error; err != nil {
+		return fmt.Errorf("finding admins for follow-ups: %w", err)
+	}
+
+	var messages []NotificationMessage
+	ids := make([]uuid.UUID, len(due))
+	for i, entry := range due {
+		ids[i] = entry.ID
+		member := "a member"
+		if entry.User != nil {
+			member = entry.User.Name
+		}
+		recipients := adminIDs
+		if entry.Assignee != nil && entry.Assignee.IsAdmin() {
+			recipients = []uuid.UUID{entry.Assignee.ID}
+		}
+
+		title := "Follow up with " + member
+		body := fmt.Sprintf("Due %s, after a %s contact on %s: %s",
+			utils.FormatDateForDisplay(*entry.FollowUpAt), contactMethodLabel(entry.Method),
+			utils.FormatDateForDisplay(entry.ContactedAt), entry.Subject)
+		for _, adminID := range recipients {
+			messages = append(messages, NotificationMessage{
+				UserID: adminID,
+				Title:  title,
+				Body:   body,
+				Data: map[string]string{
+					"type":       string(models.NotificationContactFollowUp),
+					"contact_id": entry.ID.String(),
+					"user_id":    entry.UserID.String(),
+				},
+			})
+		}
+		report.add(JobAction{Kind: "notification", UserID: &due[i].UserID, Title: title,
+			Detail: fmt.Sprintf("%s to %d admin(s)", models.NotificationContactFollowUp, len(recipients))})
+	}
+	if report.isDryRun() {
+		return nil
+	}
+
+	// Mark first so a failed send isn't repeated every hour
+	if err := database.DB.Model(&models.ContactLog{}).Where("id IN ?", ids).
+		Update("reminded_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("marking follow-ups reminded: %w", err)
+	}
+	sent, err := s.notificationService.SendBatch(ctx, models.NotificationContactFollowUp, messages)
+	if err != nil {
+		return fmt.Errorf("sending follow-up reminders: %w", err)
+	}
+	log.Printf("Sent %d reminders for %d due follow-ups", sent, len(due))
+	return nil
+}
+
+func contactMethodLabel(method models.ContactMethod) string {
+	switch method {
+	case models.ContactPhone:
+		return "phone"
+	case models.ContactEmail:
+		return "email"
+	case models.ContactSMS:
+		return "text message"
+	case models.ContactInPerson:
+		return "in-person"
+	}
+	return "other"
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+type CourtService struct {
+	notificationService *NotificationService
+}
+
+func NewCourtService(notificationService *NotificationService) *CourtService {
+	return &CourtService{notificationService: notificationService}
+}
+
+// CourtBoard is the live view of who is on which court and who is next up
+type CourtBoard struct {
+	SessionID uuid.UUID     `json:"session_id"`
+	Courts    []CourtStatus `json:"courts"`
+	Queue     []models.User `json:"queue"`
+}
+
+// CourtStatus is the current occupancy of a single court
+type CourtStatus struct {
+	CourtNumber int                      `json:"court_number"`
+	Players     []models.CourtAssignment `json:"players"`
+}
+
+// GetBoard returns the current court assignments and the waiting queue for a session
+func (s *CourtService) GetBoard(sessionID uuid.UUID) (*CourtBoard, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	var active []models.CourtAssignment
+	if err := database.DB.Where("session_id = ? AND released_at IS NULL", sessionID).
+		Preload("User").
+		Order("court_number ASC, assigned_at ASC").
+		Find(&active).Error; err != nil {
+		return nil, err
+	}
+
+	board := &CourtBoard{SessionID: sessionID}
+	for court := 1; court <= session.Courts; court++ {
+		status := CourtStatus{CourtNumber: court, Players: []models.CourtAssignment{}}
+		for _, a := range active {
+			if a.CourtNumber == court {
+				status.Players = append(status.Players, a)
+			}
+		}
+		board.Courts = append(board.Courts, status)
+	}
+
+	queue, err := s.nextUp(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	board.Queue = queue
+
+	return board, nil
+}
+
+// nextUp returns confirmed players not currently on a court, ordered so that
+// players who have waited longest (or not played yet) come first
+func (s *CourtService) nextUp(sessionID uuid.UUID) ([]models.User, error) {
+	var rsvps []models.RSVP
+	if err := database.DB.Where("session_id = ? AND status = ?", sessionID, models.RSVPStatusIn).
+		Preload("User").
+		Order("rsvp_timestamp ASC").
+		Find(&rsvps).Error; err != nil {
+		return nil, err
+	}
+
+	var assignments []models.CourtAssignment
+	if err := database.DB.Where("session_id = ?", sessionID).
+		Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+
+	onCourt := make(map[uuid.UUID]bool)
+	lastPlayed := make(map[uuid.UUID]time.Time)
+	for _, a := range assignments {
+		if a.ReleasedAt == nil {
+			onCourt[a.UserID] = true
+		} else if a.ReleasedAt.After(lastPlayed[a.UserID]) {
+			lastPlayed[a.UserID] = *a.ReleasedAt
+		}
+	}
+
+	// Players who haven't played keep RSVP order; the rest queue by when they came off court
+	var fresh, rested []models.User
+	for _, r := range rsvps {
+		if r.User == nil || onCourt[r.UserID] {
+			continue
+		}
+		if _, played := lastPlayed[r.UserID]; played {
+			rested = append(rested, *r.User)
+		} else {
+			fresh = append(fresh, *r.User)
+		}
+	}
+	sort.SliceStable(rested, func(i, j int) bool {
+		return lastPlayed[rested[i].ID].Before(lastPlayed[rested[j].ID])
+	})
+
+	return append(fresh, rested...), nil
+}
+
+// AssignNextUp fills every free court with the next players in the queue and
+// notifies them. When several courts are free, the players going on are
+// grouped by their peer level so each court is evenly matched. Returns the
+// new assignments.
+func (s *CourtService) AssignNextUp(ctx context.Context, sessionID, assignedBy uuid.UUID) ([]models.CourtAssignment, error) {
+	board, err := s.GetBoard(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	free := 0
+	for _, court := range board.Courts {
+		if len(court.Players) == 0 {
+			free++
+		}
+	}
+	queue := board.Queue
+	if n := min(free, len(queue)/models.PlayersPerCourt) * models.PlayersPerCourt; n > models.PlayersPerCourt {
+		ids := make([]uuid.UUID, n)
+		for i, p := range queue[:n] {
+			ids[i] = p.ID
+		}
+		levels, err := peerLevels(ids)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(groupByLevel(queue[:n], levels), queue[n:]...)
+	}
+
+	var created []models.CourtAssignment
+	for _, court := range board.Courts {
+		if len(court.Players) > 0 {
+			continue
+		}
+		if len(queue) < models.PlayersPerCourt {
+			break
+		}
+		players := queue[:models.PlayersPerCourt]
+		queue = queue[models.PlayersPerCourt:]
+
+		ids := make([]uuid.UUID, len(players))
+		for i, p := range players {
+			ids[i] = p.ID
+		}
+		assignments, err := s.assign(sessionID, court.CourtNumber, ids, assignedBy)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, assignments...)
+	}
+
+	s.notifyAssigned(ctx, sessionID, created)
+	return created, nil
+}
+
+// AssignPlayers puts specific players on a court, replacing anyone currently on it
+func (s *CourtService) AssignPlayers(ctx context.Context, sessionID uuid.UUID, courtNumber int, userIDs []uuid.UUID, assignedBy uuid.UUID) ([]models.CourtAssignment, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if courtNumber < 1 || courtNumber > session.Courts {
+		return nil, fmt.Errorf("court must be between 1 and %d", session.Courts)
+	}
+	if len(userIDs) == 0 || len(userIDs) > models.PlayersPerCourt {
+		return nil, fmt.Errorf("a court takes between 1 and %d players", models.PlayersPerCourt)
+	}
+
+	if err := s.ReleaseCourt(sessionID, courtNumber); err != nil {
+		return nil, err
+	}
+
+	// Players can only be on one court at a time
+	now := time.Now()
+	if err := database.DB.Model(&models.CourtAssignment{}).
+		Where("session_id = ? AND user_id IN ? AND released_at IS NULL", sessionID, userIDs).
+		Update("released_at", &now).Error; err != nil {
+		return nil, err
+	}
+
+	created, err := s.assign(sessionID, courtNumber, userIDs, assignedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyAssigned(ctx, sessionID, created)
+	return created, nil
+}
+
+func (s *CourtService) assign(sessionID uuid.UUID, courtNumber int, userIDs []uuid.UUID, assignedBy uuid.UUID) ([]models.CourtAssignment, error) {
+	assignments := make([]models.CourtAssignment, len(userIDs))
+	for i, id := range userIDs {
+		assignments[i] = models.CourtAssignment{
+			SessionID:   sessionID,
+			CourtNumber: courtNumber,
+			UserID:      id,
+			AssignedBy:  assignedBy,
+		}
+	}
+	if err := database.DB.Create(&assignments).Error; err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// ReleaseCourt marks everyone on a court as finished
+func (s *CourtService) ReleaseCourt(sessionID uuid.UUID, courtNumber int) error {
+	now := time.Now()
+	return database.DB.Model(&models.CourtAssignment{}).
+		Where("session_id = ? AND court_number = ? AND released_at IS NULL", sessionID, courtNumber).
+		Update("released_at", &now).Error
+}
+
+// notifyAssigned tells players which court they're on
+func (s *CourtService) notifyAssigned(ctx context.Context, sessionID uuid.UUID, assignments []models.CourtAssignment) {
+	if s.notificationService == nil || len(assignments) == 0 {
+		return
+	}
+
+	for _, a := range assignments {
+		title := "You're Up!"
+		body := fmt.Sprintf("Head to court %d - your game is ready.", a.CourtNumber)
+		data := map[string]string{
+			"type":         string(models.NotificationCourtAssignment),
+			"session_id":   sessionID.String(),
+			"court_number": fmt.Sprintf("%d", a.CourtNumber),
+		}
+		if err := s.notificationService.SendNotification(ctx, a.UserID, models.NotificationCourtAssignment, title, body, data); err != nil {
+			log.Printf("Error sending court assignment to user %s: %v", a.UserID, err)
+		}
+	}
+}
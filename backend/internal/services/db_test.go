@@ -0,0 +1,124 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Tests that need Postgres use the database named by TEST_DATABASE_URL and
+// are skipped without it. It's migrated once and emptied before each test,
+// so don't point it at one you want to keep.
+const testDatabaseEnv = "TEST_DATABASE_URL"
+
+var (
+	testDBOnce sync.Once
+	testDBConn *gorm.DB
+	testDBErr  error
+)
+
+// testDB points database.DB at an empty, migrated test database with just
+// the default club in it
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	url := os.Getenv(testDatabaseEnv)
+	if url == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+
+	testDBOnce.Do(func() {
+		testDBConn, testDBErr = gorm.Open(postgres.Open(url), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if testDBErr != nil {
+			return
+		}
+		database.DB = testDBConn
+		testDBErr = database.Migrate()
+	})
+	if testDBErr != nil {
+		t.Fatalf("test database: %v", testDBErr)
+	}
+	database.DB = testDBConn
+
+	var tables []string
+	if err := testDBConn.Raw(`SELECT tablename FROM pg_tables WHERE schemaname = current_schema()`).
+		Scan(&tables).Error; err != nil {
+		t.Fatalf("listing tables: %v", err)
+	}
+	for i, table := range tables {
+		tables[i] = `"` + table + `"`
+	}
+	if err := testDBConn.Exec(`TRUNCATE ` + strings.Join(tables, ", ") + ` CASCADE`).Error; err != nil {
+		t.Fatalf("emptying the test database: %v", err)
+	}
+	if err := testDBConn.Create(&models.Club{Name: "Test Club"}).Error; err != nil {
+		t.Fatalf("creating the club: %v", err)
+	}
+	return testDBConn
+}
+
+// newMember creates an approved member
+func newMember(t *testing.T, name string) *models.User {
+	t.Helper()
+	user := models.User{
+		Auth0ID:          "auth0|" + uuid.NewString(),
+		Email:            fmt.Sprintf("%s-%s@example.com", strings.ToLower(name), uuid.NewString()[:8]),
+		Name:             name,
+		Role:             models.RolePlayer,
+		IsPlayer:         true,
+		MembershipStatus: models.MembershipApproved,
+	}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatalf("creating %s: %v", name, err)
+	}
+	return &user
+}
+
+// newSession creates an open session starting in startsIn, with RSVPs
+// closing an hour before it starts
+func newSession(t *testing.T, startsIn time.Duration, maxPlayers int) *models.Session {
+	t.Helper()
+	startsAt := time.Now().Add(startsIn).Truncate(time.Minute)
+	session := models.Session{
+		Title:        "Test session",
+		SessionDate:  utils.StartOfDay(startsAt),
+		StartsAt:     startsAt,
+		EndsAt:       startsAt.Add(2 * time.Hour),
+		Courts:       1,
+		MaxPlayers:   maxPlayers,
+		RSVPDeadline: startsAt.Add(-time.Hour),
+		Status:       models.SessionStatusOpen,
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		t.Fatalf("creating session: %v", err)
+	}
+	return &session
+}
+
+// concurrently runs f n times at once and returns each call's error
+func concurrently(n int, f func(i int) error) []error {
+	errs := make([]error, n)
+	var start, done sync.WaitGroup
+	start.Add(1)
+	for i := 0; i < n; i++ {
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			start.Wait()
+			errs[i] = f(i)
+		}(i)
+	}
+	start.Done()
+	done.Wait()
+	return errs
+}
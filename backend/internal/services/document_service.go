@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/storage"
+	"gorm.io/gorm"
+)
+
+// ErrDocumentsNotAcknowledged is returned when a member tries their first RSVP
+// before acknowledging every required club document
+var ErrDocumentsNotAcknowledged = domainError(ErrForbidden, "documents_not_acknowledged", "please read and acknowledge the required club documents before your first RSVP")
+
+var ErrDocumentNotFound = domainError(ErrNotFound, "document_not_found", "document not found")
+
+type DocumentService struct {
+	store storage.Store // nil when no storage backend is configured
+}
+
+func NewDocumentService(store storage.Store) *DocumentService {
+	return &DocumentService{store: store}
+}
+
+type DocumentInput struct {
+	Title       string
+	Description string
+	Category    models.DocumentCategory
+	Required    bool
+	// CommitteeOnly keeps the document to the committee; it can't also be required
+	CommitteeOnly bool
+	FileName      string
+	SizeBytes     int64
+}
+
+// DocumentWithAck is a document with the viewing member's acknowledgement
+type DocumentWithAck struct {
+	models.Document
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+}
+
+// UploadDocument stores a PDF and records it. The content is sniffed rather
+// than trusting the client's content type.
+func (s *DocumentService) UploadDocument(ctx context.Context, input DocumentInput, r io.Reader, uploadedBy uuid.UUID) (*models.Document, error) {
+	if s.store == nil {
+		return nil, ErrStorageDisabled
+	}
+
+	upload, err := storage.Documents.Check(input.SizeBytes, r)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := models.Document{
+		ID:          uuid.New(),
+		Title:       input.Title,
+		Description: input.Description,
+		Category:    input.Category,
+		FileName:    input.FileName,
+		ContentType: upload.ContentType,
+		SizeBytes:   input.SizeBytes,
+		Required:    input.Required && !input.CommitteeOnly,
+		UploadedBy:  uploadedBy,
+
+		CommitteeOnly: input.CommitteeOnly,
+	}
+	doc.StorageKey = fmt.Sprintf("documents/%s%s", doc.ID, upload.Extension)
+
+	if err := s.store.Put(ctx, doc.StorageKey, doc.ContentType, upload.Body); err != nil {
+		return nil, storeError("document", err)
+	}
+
+	if err := database.DB.Create(&doc).Error; err != nil {
+		if delErr := s.store.Delete(ctx, doc.StorageKey); delErr != nil {
+			log.Printf("Failed to remove orphaned document %s: %v", doc.StorageKey, delErr)
+		}
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+// visibleDocuments narrows db to the documents viewer may see; committee
+// papers are left out for anyone not on the committee
+func visibleDocuments(db *gorm.DB, viewer *models.User) *gorm.DB {
+	if viewer.IsCommittee() {
+		return db
+	}
+	return db.Where("committee_only = ?", false)
+}
+
+// ListDocuments returns the documents viewer may see, required ones first,
+// with whether they've acknowledged each
+func (s *DocumentService) ListDocuments(viewer *models.User) ([]DocumentWithAck, error) {
+	var docs []models.Document
+	if err := visibleDocuments(database.DB, viewer).Order("required DESC, category ASC, title ASC").Find(&docs).Error; err != nil {
+		return nil, err
+	}
+
+	var acks []models.DocumentAcknowledgement
+	if err := database.DB.Where("user_id = ?", viewer.ID).Find(&acks).Error; err != nil {
+		return nil, err
+	}
+	acked := make(map[uuid.UUID]time.Time, len(acks))
+	for _, a := range acks {
+		acked[a.DocumentID] = a.AcknowledgedAt
+	}
+
+	result := make([]DocumentWithAck, len(docs))
+	for i, d := range docs {
+		result[i] = DocumentWithAck{Document: d}
+		if at, ok := acked[d.ID]; ok {
+			result[i].AcknowledgedAt = &at
+		}
+	}
+	return result, nil
+}
+
+// ListCommitteeDocuments returns the committee's papers, newest first
+func (s *DocumentService) ListCommitteeDocuments() ([]models.Document, error) {
+	var docs []models.Document
+	err := database.DB.Where("committee_only = ?", true).
+		Order("created_at DESC").
+		Find(&docs).Error
+	return docs, err
+}
+
+// OpenDocument returns a document viewer may see and its contents; the
+// caller must close them
+func (s *DocumentService) OpenDocument(ctx context.Context, id uuid.UUID, viewer *models.User) (*models.Document, io.ReadCloser, error) {
+	var doc models.Document
+	if err := visibleDocuments(database.DB, viewer).First(&doc, "id = ?", id).Error; err != nil {
+		return nil, nil, ErrDocumentNotFound
+	}
+	if s.store == nil {
+		return nil, nil, ErrStorageDisabled
+	}
+
+	body, err := s.store.Get(ctx, doc.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	return &doc, body, nil
+}
+
+// DeleteDocument removes a document, its acknowledgements and its stored file
+func (s *DocumentService) DeleteDocument(ctx context.Context, id uuid.UUID) error {
+	var doc models.Document
+	if err := database.DB.First(&doc, "id = ?", id).Error; err != nil {
+		return ErrDocumentNotFound
+	}
+	return s.deleteDocument(ctx, doc)
+}
+
+// DeleteCommitteeDocument removes one of the committee's papers; other club
+// documents are left to admins
+func (s *DocumentService) DeleteCommitteeDocument(ctx context.Context, id uuid.UUID) error {
+	var doc models.Document
+	if err := database.DB.First(&doc, "id = ? AND committee_only = ?", id, true).Error; err != nil {
+		return ErrDocumentNotFound
+	}
+	return s.deleteDocument(ctx, doc)
+}
+
+func (s *DocumentService) deleteDocument(ctx context.Context, doc models.Document) error {
+	id := doc.ID
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("document_id = ?", id).Delete(&models.DocumentAcknowledgement{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&doc).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.store != nil {
+		if err := s.store.Delete(ctx, doc.StorageKey); err != nil {
+			log.Printf("Failed to remove stored document %s: %v", doc.StorageKey, err)
+		}
+	}
+	return nil
+}
+
+// AcknowledgeDocument records that viewer has read a document; repeat calls keep the first time
+func (s *DocumentService) AcknowledgeDocument(documentID uuid.UUID, viewer *models.User) (*models.DocumentAcknowledgement, error) {
+	var doc models.Document
+	if err := visibleDocuments(database.DB, viewer).First(&doc, "id = ?", documentID).Error; err != nil {
+		return nil, ErrDocumentNotFound
+	}
+
+	ack := models.DocumentAcknowledgement{DocumentID: documentID, UserID: viewer.ID}
+	if err := database.DB.Where(ack).
+		Attrs(models.DocumentAcknowledgement{AcknowledgedAt: time.Now()}).
+		FirstOrCreate(&ack).Error; err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
+
+// PendingRequiredDocuments returns required documents userID has not acknowledged
+func (s *DocumentService) PendingRequiredDocuments(userID uuid.UUID) ([]models.Document, error) {
+	var docs []models.Document
+	err := database.DB.Where("required = ? AND committee_only = ?", true, false).
+		Where("id NOT IN (?)", database.DB.Model(&models.DocumentAcknowledgement{}).
+			Select("document_id").Where("user_id = ?", userID)).
+		Order("title ASC").
+		Find(&docs).Error
+	return docs, err
+}
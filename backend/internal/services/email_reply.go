@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+// replyHint is added to emails members can RSVP to by replying
+const replyHint = "You can RSVP by replying to this email with IN, OUT or MAYBE."
+
+// replyAddressPrefix starts the local part of every reply-to address
+const replyAddressPrefix = "rsvp-"
+
+// replyMACLength keeps the local part within the 64 characters email allows;
+// the sender also has to match the member, so it needn't be longer
+const replyMACLength = 4
+
+var replyEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// replyAddresses signs and reads the reply-to addresses on emails members
+// can RSVP to by replying. An address carries the session and member, signed
+// with HMAC-SHA256, so it needs no storage and can't be altered to RSVP
+// someone else.
+type replyAddresses struct {
+	domain string
+	secret *signingKey
+}
+
+// newReplyAddresses returns nil, turning replies off, unless both the
+// domain and the secret are set
+func newReplyAddresses(domain, secret string) *replyAddresses {
+	domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+	if domain == "" || secret == "" {
+		return nil
+	}
+	return &replyAddresses{domain: domain, secret: newSigningKey(secret)}
+}
+
+// address returns the reply-to address for a member's emails about a session
+func (r *replyAddresses) address(sessionID, userID uuid.UUID) string {
+	payload := make([]byte, 32, 32+replyMACLength)
+	copy(payload, sessionID[:])
+	copy(payload[16:], userID[:])
+	payload = append(payload, r.sign(payload)...)
+	return replyAddressPrefix + strings.ToLower(replyEncoding.EncodeToString(payload)) + "@" + r.domain
+}
+
+// parse returns the session and member an address was made for
+func (r *replyAddresses) parse(address string) (sessionID, userID uuid.UUID, ok bool) {
+	local, domain, found := strings.Cut(strings.ToLower(strings.TrimSpace(address)), "@")
+	if !found || domain != r.domain || !strings.HasPrefix(local, replyAddressPrefix) {
+		return uuid.Nil, uuid.Nil, false
+	}
+	payload, err := replyEncoding.DecodeString(strings.ToUpper(strings.TrimPrefix(local, replyAddressPrefix)))
+	if err != nil || len(payload) != 32+replyMACLength {
+		return uuid.Nil, uuid.Nil, false
+	}
+	if !hmac.Equal(payload[32:], r.sign(payload[:32])) {
+		return uuid.Nil, uuid.Nil, false
+	}
+	copy(sessionID[:], payload[:16])
+	copy(userID[:], payload[16:32])
+	return sessionID, userID, true
+}
+
+func (r *replyAddresses) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, r.secret.get())
+	mac.Write(payload)
+	return mac.Sum(nil)[:replyMACLength]
+}
+
+// replyAddressFor returns the reply-to address for an email, or "" if the
+// member can't RSVP to it by replying. Only reminders to RSVP get one.
+func (s *NotificationService) replyAddressFor(userID uuid.UUID, notifType models.NotificationType, data map[string]string) string {
+	if s.replies == nil {
+		return ""
+	}
+	switch notifType {
+	case models.NotificationSessionReminder, models.NotificationRSVPDeadline:
+	default:
+		return ""
+	}
+	sessionID, err := uuid.Parse(data["session_id"])
+	if err != nil {
+		return ""
+	}
+	return s.replies.address(sessionID, userID)
+}
+
+// SetInboundEmailSecret switches reply-to addresses to a rotated secret
+func (s *NotificationService) SetInboundEmailSecret(secret string) {
+	if s.replies != nil {
+		s.replies.secret.set(secret)
+	}
+}
+
+// InboundEmail is a reply as SendGrid Inbound Parse passes it on
+type InboundEmail struct {
+	From       string   // the From header
+	Recipients []string // envelope recipients, or the To and Cc headers
+	Subject    string
+	Text       string // plain text body
+}
+
+// InboundEmailResult says what became of a reply
+type InboundEmailResult string
+
+const (
+	InboundRSVPRecorded InboundEmailResult = "rsvp_recorded"
+	InboundForwarded    InboundEmailResult = "forwarded_to_admins"
+	InboundIgnored      InboundEmailResult = "ignored" // not a reply to one of our addresses, or not from the member
+)
+
+// maxForwardedReply is how much of a reply is passed on to admins
+const maxForwardedReply = 1000
+
+// InboundEmailService handles replies to reminder emails: a reply that says
+// IN, OUT or MAYBE is recorded as the member's RSVP, and anything else is
+// passed on to the admins
+type InboundEmailService struct {
+	replies             *replyAddresses
+	webhookKey          *signingKey
+	rsvpService         *RSVPService
+	notificationService *NotificationService
+}
+
+func NewInboundEmailService(domain, secret string, rsvpService *RSVPService, notificationService *NotificationService) *InboundEmailService {
+	return &InboundEmailService{
+		replies:             newReplyAddresses(domain, secret),
+		webhookKey:          newSigningKey(secret),
+		rsvpService:         rsvpService,
+		notificationService: notificationService,
+	}
+}
+
+// IsEnabled reports whether replies are being taken
+func (s *InboundEmailService) IsEnabled() bool {
+	return s.replies != nil
+}
+
+// CheckWebhookKey reports whether key is the one the webhook URL was
+// configured with. Inbound Parse doesn't sign its posts, so the key in the
+// URL is what keeps others from posting replies.
+func (s *InboundEmailService) CheckWebhookKey(key string) bool {
+	return s.IsEnabled() && subtle.ConstantTimeCompare([]byte(key), s.webhookKey.get()) == 1
+}
+
+// SetSecret switches to a rotated secret. Replies to emails sent with the
+// old one are ignored, and Inbound Parse must post with the new key.
+func (s *InboundEmailService) SetSecret(secret string) {
+	if !s.IsEnabled() {
+		log.Println("INBOUND_EMAIL_SECRET set; restart to take email replies")
+		return
+	}
+	s.replies.secret.set(secret)
+	s.webhookKey.set(secret)
+}
+
+// HandleReply records the RSVP a reply asks for, or forwards the reply to
+// the admins when it can't tell what the member meant or the RSVP can't be
+// made. Replies not sent to a reply-to address of ours, or not from the
+// member it was made for, are ignored.
+func (s *InboundEmailService) HandleReply(ctx context.Context, email InboundEmail) (InboundEmailResult, error) {
+	if !s.IsEnabled() {
+		return InboundIgnored, errors.New("email replies are not configured")
+	}
+
+	var sessionID, userID uuid.UUID
+	found := false
+	for _, recipient := range email.Recipients {
+		if sessionID, userID, found = s.replies.parse(recipient); found {
+			break
+		}
+	}
+	if !found {
+		log.Printf("Ignoring inbound email not sent to a reply address")
+		return InboundIgnored, nil
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		log.Printf("Ignoring email reply for unknown member %s", userID)
+		return InboundIgnored, nil
+	}
+	// The address could have been passed on; only the member can RSVP with it
+	from, err := mail.ParseAddress(email.From)
+	if err != nil || !strings.EqualFold(from.Address, user.Email) {
+		log.Printf("Ignoring email reply for member %s from another address", userID)
+		return InboundIgnored, nil
+	}
+
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		log.Printf("Ignoring email reply for unknown session %s", sessionID)
+		return InboundIgnored, nil
+	}
+
+	reply := replyText(email.Text)
+	status, ok := parseRSVPReply(reply)
+	if !ok {
+		s.forward(ctx, &user, &session, reply, "")
+		return InboundForwarded, nil
+	}
+
+	rsvp, err := s.rsvpService.CreateOrUpdateRSVP(RSVPInput{
+		SessionID: sessionID,
+		UserID:    userID,
+		Status:    status,
+	}, false)
+	if err != nil {
+		s.forward(ctx, &user, &session, reply, err.Error())
+		return InboundForwarded, nil
+	}
+
+	log.Printf("Recorded %s RSVP from email reply by %s for session %s", rsvp.Status, userID, sessionID)
+	s.confirm(ctx, &user, &session, rsvp.Status)
+	return InboundRSVPRecorded, nil
+}
+
+// confirm tells the member their reply was recorded
+func (s *InboundEmailService) confirm(ctx context.Context, user *models.User, session *models.Session, status models.RSVPStatus) {
+	if s.notificationService == nil {
+		return
+	}
+	title := "RSVP recorded"
+	body := fmt.Sprintf("Thanks for your reply. You're %s for %s on %s.",
+		strings.ToUpper(string(status)), session.Title, utils.FormatDateForDisplay(session.SessionDate))
+	if status == models.RSVPStatusWaitlisted {
+		body = fmt.Sprintf("Thanks for your reply. %s on %s is full, so you're on the waitlist. We'll let you know if a spot opens up.",
+			session.Title, utils.FormatDateForDisplay(session.SessionDate))
+	}
+	data := map[string]string{
+		"type":       string(models.NotificationEmailReply),
+		"session_id": session.ID.String(),
+	}
+	if err := s.notificationService.SendNotification(ctx, user.ID, models.NotificationEmailReply, title, body, data); err != nil {
+		log.Printf("Failed to confirm email reply RSVP: %v", err)
+	}
+}
+
+// forward passes a reply on to the admins, and tells the member it's with
+// them. reason is why the RSVP couldn't be made, if it got that far.
+func (s *InboundEmailService) forward(ctx context.Context, user *models.User, session *models.Session, reply, reason string) {
+	log.Printf("Forwarding email reply from %s about session %s to admins", user.ID, session.ID)
+	if s.notificationService == nil {
+		return
+	}
+
+	if len(reply) > maxForwardedReply {
+		reply = reply[:maxForwardedReply] + "…"
+	}
+	when := utils.FormatDateForDisplay(session.SessionDate)
+	data := map[string]string{
+		"type":       string(models.NotificationEmailReply),
+		"session_id": session.ID.String(),
+		"user_id":    user.ID.String(),
+	}
+
+	var adminIDs []uuid.UUID
+	if err := database.DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Pluck("id", &adminIDs).Error; err != nil {
+		log.Printf("Failed to find admins for email reply: %v", err)
+	} else if len(adminIDs) > 0 {
+		title := fmt.Sprintf("Email reply from %s", user.Name)
+		body := fmt.Sprintf("%s replied to the reminder for %s on %s: %q", user.Name, session.Title, when, reply)
+		if reason != "" {
+			body += fmt.Sprintf(" Their RSVP couldn't be recorded: %s.", reason)
+		}
+		s.notificationService.SendBulkNotification(ctx, adminIDs, models.NotificationEmailReply, title, body, data)
+	}
+
+	body := fmt.Sprintf("We couldn't tell from your reply whether you're in for %s on %s, so it's been passed to the admins. You can also RSVP in the app.", session.Title, when)
+	if reason != "" {
+		body = fmt.Sprintf("We couldn't record your RSVP for %s on %s: %s. Your reply has been passed to the admins.", session.Title, when, reason)
+	}
+	if err := s.notificationService.SendNotification(ctx, user.ID, models.NotificationEmailReply, "Your reply is with the admins", body, data); err != nil {
+		log.Printf("Failed to tell member about forwarded email reply: %v", err)
+	}
+}
+
+// quotedReplyStart matches the line mail clients put above the quoted
+// original: "On Tue, 3 Jun 2025 at 09:00, Weekday Masters <...> wrote:",
+// "-----Original Message-----" or an Outlook "From:" header
+var quotedReplyStart = regexp.MustCompile(`(?i)^(on\b.*\bwrote:?$|-+\s*original message\s*-+$|from:\s)`)
+
+// replyText returns what the member wrote, without the quoted email or a
+// signature below it
+func replyText(body string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, ">") || quotedReplyStart.MatchString(trimmed) || trimmed == "--" {
+			break
+		}
+		lines = append(lines, trimmed)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// rsvpReplyWords are the replies understood, after lower-casing and
+// dropping punctuation
+var rsvpReplyWords = map[models.RSVPStatus][]string{
+	models.RSVPStatusIn: {
+		"in", "im in", "i am in", "count me in", "yes", "y", "going", "coming",
+		"im coming", "i will be there", "ill be there", "attending",
+	},
+	models.RSVPStatusOut: {
+		"out", "im out", "i am out", "count me out", "no", "n", "not going", "not coming",
+		"cant make it", "cannot make it", "i cant make it", "i cannot make it", "cant come",
+	},
+	models.RSVPStatusMaybe: {
+		"maybe", "possibly", "not sure", "unsure", "might", "perhaps",
+	},
+}
+
+// parseRSVPReply reads IN, OUT or MAYBE from the first line of a reply. A
+// short phrase after it ("I'm in, thanks!") is fine; the longest phrase
+// that matches decides, so "not going" isn't read as "no". Anything else is
+// left for a person to read.
+func parseRSVPReply(reply string) (models.RSVPStatus, bool) {
+	first, _, _ := strings.Cut(reply, "\n")
+	normalized := normalizeReply(first)
+	if normalized == "" {
+		return "", false
+	}
+
+	var matched models.RSVPStatus
+	longest := 0
+	for status, phrases := range rsvpReplyWords {
+		for _, phrase := range phrases {
+			if len(phrase) > longest && (normalized == phrase || strings.HasPrefix(normalized, phrase+" ")) {
+				matched, longest = status, len(phrase)
+			}
+		}
+	}
+	return matched, matched != ""
+}
+
+// normalizeReply lower-cases a line and drops punctuation and emoji, so
+// "I'm IN!! 🏸" reads as "im in"
+func normalizeReply(line string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(line) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == ',' || r == '.' || r == '!' || r == '-':
+			space = true
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+// queuedEmailBatchSize bounds how many held emails one flush sends
+const queuedEmailBatchSize = 500
+
+// emailHoldUntil returns when an email of notifType may go out if the club's
+// email window is closed, or nil to send it straight away
+func emailHoldUntil(notifType models.NotificationType) *time.Time {
+	if notifType.IsUrgent() {
+		return nil
+	}
+
+	var club models.Club
+	if err := database.DB.First(&club).Error; err != nil {
+		return nil
+	}
+	now := utils.NowInSydney()
+	opens := club.EmailWindowOpensAt(now)
+	if !opens.After(now) {
+		return nil
+	}
+	return &opens
+}
+
+// FlushQueuedEmails sends emails held for the email window once it has
+// opened. Push notifications for them went out when they were created.
+func (s *NotificationService) FlushQueuedEmails(ctx context.Context) error {
+	var notifications []models.Notification
+	if err := database.DB.Preload("User").
+		Where("email_queued_until <= ? AND email_sent = ?", time.Now(), false).
+		Order("email_queued_until ASC").
+		Limit(queuedEmailBatchSize).
+		Find(&notifications).Error; err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range notifications {
+		n := &notifications[i]
+		if n.User != nil && s.emailEnabled {
+			s.deliver(ctx, n, n.User, false, true)
+		}
+		// Failures are recorded on the notification for an admin to resend
+		// rather than retried here every few minutes
+		n.EmailQueuedUntil = nil
+		if err := database.DB.Model(n).Select(
+			"email_sent", "email_sent_at", "email_error", "email_queued_until",
+		).Updates(n).Error; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(notifications) > 0 {
+		log.Printf("Sent %d queued emails", len(notifications))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/weekday-masters/backend/internal/storage"
+)
+
+// Kinds of domain error. Every DomainError wraps one, so handlers can pick
+// an HTTP status with errors.Is without knowing each service's errors.
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrForbidden      = errors.New("forbidden")
+	ErrDeadlinePassed = errors.New("deadline passed")
+	ErrSessionFull    = errors.New("session full")
+	ErrConflict       = errors.New("conflict") // the request clashes with the current state
+	ErrInvalid        = errors.New("invalid")  // the request itself is wrong
+	ErrRejected       = errors.New("rejected") // well-formed, but the content isn't accepted
+	ErrUnavailable    = errors.New("unavailable")
+)
+
+// DomainError is a failure the caller can act on. Message is shown to
+// members; Code is a stable snake_case identifier clients can switch on,
+// since messages get reworded.
+type DomainError struct {
+	Kind    error
+	Code    string
+	Message string
+}
+
+func (e *DomainError) Error() string { return e.Message }
+
+// Unwrap makes errors.Is(err, ErrNotFound) and the like match
+func (e *DomainError) Unwrap() error { return e.Kind }
+
+func domainError(kind error, code, message string) *DomainError {
+	return &DomainError{Kind: kind, Code: code, Message: message}
+}
+
+// Errors several services share
+var (
+	ErrSessionNotFound  = domainError(ErrNotFound, "session_not_found", "session not found")
+	ErrRSVPNotFound     = domainError(ErrNotFound, "rsvp_not_found", "RSVP not found")
+	ErrUserNotFound     = domainError(ErrNotFound, "user_not_found", "user not found")
+	ErrStorageDisabled  = domainError(ErrUnavailable, "storage_disabled", "file storage is not configured")
+	ErrSessionNotOpen   = domainError(ErrConflict, "session_not_open", "session is not open for RSVPs")
+	ErrRSVPDeadline     = domainError(ErrDeadlinePassed, "rsvp_deadline_passed", "RSVP deadline has passed")
+	ErrRSVPChangeLocked = domainError(ErrDeadlinePassed, "rsvp_locked_in", "cannot change RSVP from IN after deadline")
+	ErrRSVPRemoveLocked = domainError(ErrDeadlinePassed, "rsvp_locked_in", "cannot remove IN RSVP after deadline")
+	ErrSessionIsFull    = domainError(ErrSessionFull, "session_full", "session is full; remove a player before approving another")
+)
+
+// storeError reports a failed upload of what. An upload that ran past its
+// policy's size limit while being stored is returned as is, for the handler
+// to answer 413.
+func storeError(what string, err error) error {
+	var uploadErr *storage.UploadError
+	if errors.As(err, &uploadErr) {
+		return uploadErr
+	}
+	return fmt.Errorf("failed to store %s: %w", what, err)
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxEventPage bounds the events returned per page of the changelog
+const maxEventPage = 500
+
+// Event is a domain event to record
+type Event struct {
+	Type      models.EventType
+	SessionID *uuid.UUID
+	UserID    *uuid.UUID // the member it's about
+	ActorID   *uuid.UUID // who did it, if known
+	Data      interface{}
+}
+
+// recordEvent appends event to the events table in tx, the transaction making
+// the change it describes, and queues it for the outbox to deliver with
+// notifications. Call Outbox.Dispatch once tx has committed to deliver it
+// straight away; otherwise the scheduler picks it up within a minute.
+func recordEvent(tx *gorm.DB, event Event, notifications ...OutboxNotification) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("encoding %s event: %w", event.Type, err)
+	}
+	domainEvent := models.DomainEvent{
+		Type:      event.Type,
+		SessionID: event.SessionID,
+		UserID:    event.UserID,
+		ActorID:   event.ActorID,
+		Data:      string(data),
+	}
+	if err := tx.Create(&domainEvent).Error; err != nil {
+		return err
+	}
+
+	batches := []OutboxNotification{}
+	for _, n := range notifications {
+		if len(n.Messages) > 0 {
+			batches = append(batches, n)
+		}
+	}
+	encoded, err := json.Marshal(batches)
+	if err != nil {
+		return fmt.Errorf("encoding %s notifications: %w", event.Type, err)
+	}
+
+	return tx.Create(&models.OutboxEvent{
+		EventID:       domainEvent.ID,
+		Notifications: string(encoded),
+		DispatchAt:    time.Now(),
+	}).Error
+}
+
+// EventService reads the domain event changelog
+type EventService struct{}
+
+func NewEventService() *EventService {
+	return &EventService{}
+}
+
+// EventFilter narrows the changelog. Zero values don't filter.
+type EventFilter struct {
+	Types     []string // exact types, or a prefix ending in ".*" such as "rsvp.*"
+	SessionID *uuid.UUID
+	UserID    *uuid.UUID
+	ActorID   *uuid.UUID
+	Since     *time.Time
+	Until     *time.Time
+	AfterSeq  int64 // only events after this one, for reading on from the last page
+	Limit     int
+}
+
+// ListEvents returns events matching filter in the order they were recorded
+func (s *EventService) ListEvents(filter EventFilter) ([]models.DomainEvent, error) {
+	query := database.DB.Model(&models.DomainEvent{}).Where("seq > ?", filter.AfterSeq)
+
+	if len(filter.Types) > 0 {
+		var clauses []string
+		var args []interface{}
+		for _, t := range filter.Types {
+			if prefix, ok := strings.CutSuffix(t, "*"); ok {
+				clauses = append(clauses, "type LIKE ?")
+				args = append(args, prefix+"%")
+			} else {
+				clauses = append(clauses, "type = ?")
+				args = append(args, t)
+			}
+		}
+		query = query.Where(strings.Join(clauses, " OR "), args...)
+	}
+	if filter.SessionID != nil {
+		query = query.Where("session_id = ?", *filter.SessionID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Since != nil {
+		query = query.Where("occurred_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("occurred_at < ?", *filter.Until)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > maxEventPage {
+		limit = maxEventPage
+	}
+
+	var events []models.DomainEvent
+	if err := query.Order("seq ASC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// eloKFactor controls how much a single game moves a rating
+const eloKFactor = 32
+
+var (
+	ErrGameConfirmed = domainError(ErrConflict, "game_already_confirmed", "game is already confirmed")
+	ErrOwnGameScore  = domainError(ErrInvalid, "own_game_score", "another member must confirm this score")
+)
+
+type GameService struct {
+	badgeService *BadgeService
+}
+
+func NewGameService(badgeService *BadgeService) *GameService {
+	return &GameService{badgeService: badgeService}
+}
+
+type RecordGameInput struct {
+	SessionID  uuid.UUID
+	TeamA      []uuid.UUID
+	TeamB      []uuid.UUID
+	TeamAScore int
+	TeamBScore int
+	RecordedBy uuid.UUID
+}
+
+// RecordGame records a casual game score, pending confirmation by another member
+func (s *GameService) RecordGame(input RecordGameInput) (*models.Game, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", input.SessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if session.Status == models.SessionStatusCancelled {
+		return nil, domainError(ErrConflict, "session_cancelled", "cannot record games for a cancelled session")
+	}
+
+	if len(input.TeamA) < 1 || len(input.TeamA) > 2 || len(input.TeamB) != len(input.TeamA) {
+		return nil, domainError(ErrInvalid, "uneven_teams", "teams must have the same number of players (1 or 2)")
+	}
+	if input.TeamAScore < 0 || input.TeamBScore < 0 {
+		return nil, domainError(ErrInvalid, "negative_score", "scores cannot be negative")
+	}
+	if input.TeamAScore == input.TeamBScore {
+		return nil, domainError(ErrInvalid, "drawn_game", "games cannot end in a draw")
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	var players []models.GamePlayer
+	for _, team := range []struct {
+		ids  []uuid.UUID
+		team models.GameTeam
+	}{{input.TeamA, models.GameTeamA}, {input.TeamB, models.GameTeamB}} {
+		for _, id := range team.ids {
+			if seen[id] {
+				return nil, domainError(ErrInvalid, "duplicate_player", "a player cannot appear more than once in a game")
+			}
+			seen[id] = true
+			players = append(players, models.GamePlayer{UserID: id, Team: team.team})
+		}
+	}
+
+	var count int64
+	ids := append(append([]uuid.UUID{}, input.TeamA...), input.TeamB...)
+	if err := database.DB.Model(&models.User{}).
+		Where("id IN ? AND membership_status = ?", ids, models.MembershipApproved).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if int(count) != len(ids) {
+		return nil, domainError(ErrInvalid, "players_not_members", "all players must be approved members")
+	}
+
+	game := models.Game{
+		SessionID:  input.SessionID,
+		TeamAScore: input.TeamAScore,
+		TeamBScore: input.TeamBScore,
+		Status:     models.GameStatusPending,
+		RecordedBy: input.RecordedBy,
+		Players:    players,
+	}
+
+	if err := database.DB.Create(&game).Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetGame(game.ID)
+}
+
+// GetGame retrieves a game with its players
+func (s *GameService) GetGame(id uuid.UUID) (*models.Game, error) {
+	var game models.Game
+	if err := database.DB.Preload("Players.User").First(&game, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &game, nil
+}
+
+// ListSessionGames returns a session's games, most recent first
+func (s *GameService) ListSessionGames(sessionID uuid.UUID) ([]models.Game, error) {
+	var games []models.Game
+	if err := database.DB.Where("session_id = ?", sessionID).
+		Preload("Players.User").
+		Order("created_at DESC").
+		Find(&games).Error; err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// ConfirmGame confirms a pending game score and applies Elo rating changes.
+// The member who recorded the score cannot confirm it themselves. Players
+// reaching the games milestone get their badge straight away.
+func (s *GameService) ConfirmGame(ctx context.Context, sessionID, gameID, userID uuid.UUID) (*models.Game, error) {
+	var game models.Game
+	if err := database.DB.Preload("Players").
+		First(&game, "id = ? AND session_id = ?", gameID, sessionID).Error; err != nil {
+		return nil, ErrGameNotFound
+	}
+	if game.Status == models.GameStatusConfirmed {
+		return nil, ErrGameConfirmed
+	}
+	if game.RecordedBy == userID {
+		return nil, ErrOwnGameScore
+	}
+
+	var ratings []models.PlayerRating
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the confirmation that moves the game out of pending applies
+		// ratings; a concurrent one finds nothing to update
+		now := time.Now()
+		result := tx.Model(&models.Game{}).
+			Where("id = ? AND status = ?", game.ID, models.GameStatusPending).
+			Updates(map[string]interface{}{
+				"status":       models.GameStatusConfirmed,
+				"confirmed_by": userID,
+				"confirmed_at": now,
+				"updated_at":   now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrGameConfirmed
+		}
+
+		var err error
+		ratings, err = applyEloRatings(tx, &game)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.badgeService != nil {
+		for _, rating := range ratings {
+			if rating.GamesPlayed >= gamesMilestone {
+				s.badgeService.AwardBadge(ctx, rating.UserID, models.BadgeGames100)
+			}
+		}
+	}
+
+	return s.GetGame(game.ID)
+}
+
+// applyEloRatings updates each player's rating using team average ratings,
+// returning the updated ratings. Rating rows are locked so games confirmed
+// at the same time for the same player don't overwrite each other. They're
+// created and locked in user ID order, so games sharing players can't
+// deadlock, and a new player's first two games can't both create a row.
+func applyEloRatings(tx *gorm.DB, game *models.Game) ([]models.PlayerRating, error) {
+	userIDs := make([]uuid.UUID, len(game.Players))
+	for i, p := range game.Players {
+		userIDs[i] = p.UserID
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i].String() < userIDs[j].String() })
+	for _, userID := range userIDs {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.PlayerRating{UserID: userID, Rating: models.DefaultEloRating}).Error; err != nil {
+			return nil, err
+		}
+	}
+	var rows []models.PlayerRating
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id IN ?", userIDs).
+		Order("user_id").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	ratings := make(map[uuid.UUID]*models.PlayerRating, len(rows))
+	for i := range rows {
+		ratings[rows[i].UserID] = &rows[i]
+	}
+	teamTotals := map[models.GameTeam]float64{}
+	teamSizes := map[models.GameTeam]int{}
+	for _, p := range game.Players {
+		teamTotals[p.Team] += ratings[p.UserID].Rating
+		teamSizes[p.Team]++
+	}
+
+	avgA := teamTotals[models.GameTeamA] / float64(teamSizes[models.GameTeamA])
+	avgB := teamTotals[models.GameTeamB] / float64(teamSizes[models.GameTeamB])
+	expectedA := 1 / (1 + math.Pow(10, (avgB-avgA)/400))
+
+	winner := game.WinningTeam()
+	updated := make([]models.PlayerRating, 0, len(game.Players))
+	for _, p := range game.Players {
+		rating := ratings[p.UserID]
+		expected := expectedA
+		if p.Team == models.GameTeamB {
+			expected = 1 - expectedA
+		}
+		actual := 0.0
+		if p.Team == winner {
+			actual = 1
+			rating.GamesWon++
+		}
+		rating.Rating += eloKFactor * (actual - expected)
+		rating.GamesPlayed++
+		rating.UpdatedAt = time.Now()
+		if err := tx.Save(rating).Error; err != nil {
+			return nil, err
+		}
+		updated = append(updated, *rating)
+	}
+	return updated, nil
+}
+
+// GetPlayerRating returns a player's rating, or the default if they haven't played
+func (s *GameService) GetPlayerRating(userID uuid.UUID) (*models.PlayerRating, error) {
+	var rating models.PlayerRating
+	err := database.DB.Where("user_id = ?", userID).First(&rating).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.PlayerRating{UserID: userID, Rating: models.DefaultEloRating}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
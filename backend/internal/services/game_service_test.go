@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+func recordGame(t *testing.T, session *models.Session, recordedBy *models.User, teamA, teamB []*models.User, scoreA, scoreB int) *models.Game {
+	t.Helper()
+	input := RecordGameInput{SessionID: session.ID, TeamAScore: scoreA, TeamBScore: scoreB, RecordedBy: recordedBy.ID}
+	for _, u := range teamA {
+		input.TeamA = append(input.TeamA, u.ID)
+	}
+	for _, u := range teamB {
+		input.TeamB = append(input.TeamB, u.ID)
+	}
+	game, err := NewGameService(nil).RecordGame(input)
+	if err != nil {
+		t.Fatalf("recording game: %v", err)
+	}
+	return game
+}
+
+func ratingOf(t *testing.T, userID uuid.UUID) models.PlayerRating {
+	t.Helper()
+	var rating models.PlayerRating
+	if err := database.DB.First(&rating, "user_id = ?", userID).Error; err != nil {
+		t.Fatalf("reading rating: %v", err)
+	}
+	return rating
+}
+
+func TestConfirmGameNotByRecorder(t *testing.T) {
+	testDB(t)
+	a, b := newMember(t, "A"), newMember(t, "B")
+	session := newSession(t, -3*time.Hour, 8)
+	game := recordGame(t, session, a, []*models.User{a}, []*models.User{b}, 21, 15)
+
+	_, err := NewGameService(nil).ConfirmGame(context.Background(), session.ID, game.ID, a.ID)
+	if !errors.Is(err, ErrOwnGameScore) {
+		t.Errorf("confirming own game = %v, want ErrOwnGameScore", err)
+	}
+}
+
+// TestConfirmGameAppliesRatingsOnce confirms the same game from several
+// members at once; only one confirmation may move the ratings
+func TestConfirmGameAppliesRatingsOnce(t *testing.T) {
+	testDB(t)
+	a, b := newMember(t, "A"), newMember(t, "B")
+	confirmers := []*models.User{newMember(t, "C"), newMember(t, "D"), newMember(t, "E"), b}
+	session := newSession(t, -3*time.Hour, 8)
+	game := recordGame(t, session, a, []*models.User{a}, []*models.User{b}, 21, 15)
+
+	service := NewGameService(nil)
+	errs := concurrently(len(confirmers), func(i int) error {
+		_, err := service.ConfirmGame(context.Background(), session.ID, game.ID, confirmers[i].ID)
+		return err
+	})
+	confirmed := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			confirmed++
+		case !errors.Is(err, ErrGameConfirmed):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if confirmed != 1 {
+		t.Fatalf("%d confirmations applied, want 1", confirmed)
+	}
+
+	// Evenly rated players move by half the K factor
+	winner, loser := ratingOf(t, a.ID), ratingOf(t, b.ID)
+	if math.Abs(winner.Rating-(models.DefaultEloRating+eloKFactor/2)) > 0.001 || winner.GamesPlayed != 1 || winner.GamesWon != 1 {
+		t.Errorf("winner rating %+v, want %v after 1 win", winner, models.DefaultEloRating+eloKFactor/2)
+	}
+	if math.Abs(loser.Rating-(models.DefaultEloRating-eloKFactor/2)) > 0.001 || loser.GamesPlayed != 1 || loser.GamesWon != 0 {
+		t.Errorf("loser rating %+v, want %v after 1 loss", loser, models.DefaultEloRating-eloKFactor/2)
+	}
+}
+
+// TestConfirmGamesSharingPlayers confirms two games between the same new
+// players at once, listed in opposite orders; both must count
+func TestConfirmGamesSharingPlayers(t *testing.T) {
+	testDB(t)
+	a, b, c, d := newMember(t, "A"), newMember(t, "B"), newMember(t, "C"), newMember(t, "D")
+	session := newSession(t, -3*time.Hour, 8)
+	games := []*models.Game{
+		recordGame(t, session, c, []*models.User{a, b}, []*models.User{c, d}, 21, 18),
+		recordGame(t, session, c, []*models.User{d, c}, []*models.User{b, a}, 21, 18),
+	}
+
+	service := NewGameService(nil)
+	errs := concurrently(len(games), func(i int) error {
+		_, err := service.ConfirmGame(context.Background(), session.ID, games[i].ID, a.ID)
+		return err
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("ConfirmGame: %v", err)
+		}
+	}
+
+	for _, u := range []*models.User{a, b, c, d} {
+		rating := ratingOf(t, u.ID)
+		if rating.GamesPlayed != 2 || rating.GamesWon != 1 {
+			t.Errorf("%s has played %d and won %d, want 2 and 1", u.Name, rating.GamesPlayed, rating.GamesWon)
+		}
+	}
+}
+
+func TestRecordGameValidationErrors(t *testing.T) {
+	testDB(t)
+	a, b, c := newMember(t, "A"), newMember(t, "B"), newMember(t, "C")
+	pending := newMember(t, "Pending")
+	database.DB.Model(pending).Update("membership_status", models.MembershipPending)
+	session := newSession(t, -3*time.Hour, 8)
+
+	tests := []struct {
+		name         string
+		teamA, teamB []uuid.UUID
+		scoreA       int
+		scoreB       int
+	}{
+		{"uneven teams", []uuid.UUID{a.ID, b.ID}, []uuid.UUID{c.ID}, 21, 15},
+		{"negative score", []uuid.UUID{a.ID}, []uuid.UUID{b.ID}, -1, 15},
+		{"draw", []uuid.UUID{a.ID}, []uuid.UUID{b.ID}, 21, 21},
+		{"same player twice", []uuid.UUID{a.ID}, []uuid.UUID{a.ID}, 21, 15},
+		{"not a member", []uuid.UUID{a.ID}, []uuid.UUID{pending.ID}, 21, 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGameService(nil).RecordGame(RecordGameInput{
+				SessionID: session.ID, TeamA: tt.teamA, TeamB: tt.teamB,
+				TeamAScore: tt.scoreA, TeamBScore: tt.scoreB, RecordedBy: a.ID,
+			})
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("got %v, want an ErrInvalid domain error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrGuestsNotAllowed  = domainError(ErrConflict, "guests_not_allowed", "guests aren't allowed at this session")
+	ErrGuestHostNotIn    = domainError(ErrConflict, "guest_host_not_in", "RSVP IN before bringing a guest")
+	ErrGuestLimit        = domainError(ErrConflict, "guest_limit", "you can't bring any more guests to this session")
+	ErrGuestNotFound     = domainError(ErrNotFound, "guest_not_found", "guest not found")
+	ErrNotYourGuest      = domainError(ErrForbidden, "not_your_guest", "only the member who brought a guest can remove them")
+	ErrNoRoomForGuest    = domainError(ErrSessionFull, "session_full", "session is full; guests can only take a spot nobody is waiting for")
+	ErrGuestRemoveLocked = domainError(ErrDeadlinePassed, "guest_locked_in", "cannot remove a guest after the RSVP deadline")
+)
+
+// ListGuests returns the guests coming to a session, with the members
+// bringing them, in the order they were added
+func (s *RSVPService) ListGuests(sessionID uuid.UUID) ([]models.GuestRSVP, error) {
+	var guests []models.GuestRSVP
+	if err := database.DB.Where("session_id = ?", sessionID).
+		Preload("Inviter").
+		Order("created_at ASC").
+		Find(&guests).Error; err != nil {
+		return nil, err
+	}
+	return guests, nil
+}
+
+// AddGuest brings a guest named name to a session, as the member invitedBy.
+// Guests are allowed only where an admin has turned them on, by members who
+// are IN, up to models.MaxGuestsPerMember each. A guest takes a free spot
+// like an IN, but never joins the waitlist: with no spot free, or members
+// already waiting for one, the guest is turned away.
+func (s *RSVPService) AddGuest(sessionID, invitedBy uuid.UUID, name string) (*models.GuestRSVP, error) {
+	guest := models.GuestRSVP{SessionID: sessionID, InvitedBy: invitedBy, Name: name}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := lockSession(tx, sessionID)
+		if err != nil {
+			return ErrSessionNotFound
+		}
+		if session.Status != models.SessionStatusOpen {
+			return ErrSessionNotOpen
+		}
+		if !session.AllowGuests {
+			return ErrGuestsNotAllowed
+		}
+		if utils.NowInSydney().After(session.RSVPDeadline) {
+			return ErrRSVPDeadline
+		}
+
+		var rsvp models.RSVP
+		if err := tx.Where("session_id = ? AND user_id = ?", sessionID, invitedBy).First(&rsvp).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrGuestHostNotIn
+			}
+			return err
+		}
+		if rsvp.Status != models.RSVPStatusIn {
+			return ErrGuestHostNotIn
+		}
+
+		var brought int64
+		if err := tx.Model(&models.GuestRSVP{}).
+			Where("session_id = ? AND invited_by = ?", sessionID, invitedBy).
+			Count(&brought).Error; err != nil {
+			return err
+		}
+		if brought >= models.MaxGuestsPerMember {
+			return ErrGuestLimit
+		}
+
+		room, err := hasRoom(tx, session)
+		if err != nil {
+			return err
+		}
+		if !room {
+			return ErrNoRoomForGuest
+		}
+		return tx.Create(&guest).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	database.DB.Preload("Inviter").First(&guest, "id = ?", guest.ID)
+	return &guest, nil
+}
+
+// RemoveGuest takes a guest off a session, as user: the member who brought
+// them, until the RSVP deadline, or an admin at any time. The guest's spot is
+// offered to the next in line.
+func (s *RSVPService) RemoveGuest(sessionID, guestID uuid.UUID, user *models.User) error {
+	var guest models.GuestRSVP
+	if err := database.DB.Where("id = ? AND session_id = ?", guestID, sessionID).First(&guest).Error; err != nil {
+		return ErrGuestNotFound
+	}
+
+	byAdmin := user.IsAdmin()
+	if !byAdmin && guest.InvitedBy != user.ID {
+		return ErrNotYourGuest
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := lockSession(tx, sessionID)
+		if err != nil {
+			return ErrSessionNotFound
+		}
+		if !byAdmin && utils.NowInSydney().After(session.RSVPDeadline) {
+			return ErrGuestRemoveLocked
+		}
+		if err := tx.Delete(&guest).Error; err != nil {
+			return err
+		}
+		_, err = offerOpenSpots(tx, session)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.outbox.Dispatch()
+	return nil
+}
+
+// dropGuests takes the guests a member brought off a session, in tx, as the
+// member gives up their own spot. Guests are deleted one by one so each
+// recounts the session.
+func dropGuests(tx *gorm.DB, sessionID, userID uuid.UUID) error {
+	var guests []models.GuestRSVP
+	if err := tx.Where("session_id = ? AND invited_by = ?", sessionID, userID).Find(&guests).Error; err != nil {
+		return err
+	}
+	for i := range guests {
+		if err := tx.Delete(&guests[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
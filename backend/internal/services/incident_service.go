@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/storage"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+type IncidentService struct {
+	store               storage.Store // nil when no storage backend is configured
+	notificationService *NotificationService
+}
+
+func NewIncidentService(store storage.Store, notificationService *NotificationService) *IncidentService {
+	return &IncidentService{
+		store:               store,
+		notificationService: notificationService,
+	}
+}
+
+type IncidentInput struct {
+	Type        models.IncidentType
+	Severity    models.IncidentSeverity
+	Title       string
+	Description string
+	OccurredAt  *time.Time
+}
+
+// canReport reports whether user may file or view incidents for a session:
+// admins, and the member who organised it
+func canReport(user *models.User, session *models.Session) bool {
+	return user.IsAdmin() || session.CreatedBy == user.ID
+}
+
+// CreateIncident files a report against a session and alerts every admin
+func (s *IncidentService) CreateIncident(sessionID uuid.UUID, reporter *models.User, input IncidentInput) (*models.Incident, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if !canReport(reporter, &session) {
+		return nil, errors.New("only admins and the session organizer can report incidents")
+	}
+
+	incident := models.Incident{
+		SessionID:   sessionID,
+		ReportedBy:  reporter.ID,
+		Type:        input.Type,
+		Severity:    input.Severity,
+		Title:       input.Title,
+		Description: input.Description,
+		OccurredAt:  input.OccurredAt,
+		Status:      models.IncidentStatusOpen,
+	}
+	if err := database.DB.Create(&incident).Error; err != nil {
+		return nil, err
+	}
+
+	s.notifyAdmins(incident, session, reporter)
+	return &incident, nil
+}
+
+// GetIncident returns an incident with its attachments; only admins and the
+// reporter can see it
+func (s *IncidentService) GetIncident(id uuid.UUID, viewer *models.User) (*models.Incident, error) {
+	var incident models.Incident
+	if err := database.DB.Preload("Attachments").Preload("Reporter").Preload("Session").
+		First(&incident, "id = ?", id).Error; err != nil {
+		return nil, domainError(ErrNotFound, "incident_not_found", "incident not found")
+	}
+	if !viewer.IsAdmin() && incident.ReportedBy != viewer.ID {
+		return nil, domainError(ErrNotFound, "incident_not_found", "incident not found")
+	}
+	return &incident, nil
+}
+
+// ListIncidents returns incidents newest first, optionally filtered by status
+func (s *IncidentService) ListIncidents(status *models.IncidentStatus) ([]models.Incident, error) {
+	var incidents []models.Incident
+	query := database.DB.Preload("Reporter").Preload("Session")
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if err := query.Order("created_at DESC").Find(&incidents).Error; err != nil {
+		return nil, err
+	}
+	return incidents, nil
+}
+
+// ResolveIncident closes an open incident with notes on how it was handled
+func (s *IncidentService) ResolveIncident(id, resolvedBy uuid.UUID, notes string) (*models.Incident, error) {
+	var incident models.Incident
+	if err := database.DB.First(&incident, "id = ?", id).Error; err != nil {
+		return nil, domainError(ErrNotFound, "incident_not_found", "incident not found")
+	}
+	if incident.Status == models.IncidentStatusResolved {
+		return nil, errors.New("incident is already resolved")
+	}
+
+	now := time.Now()
+	incident.Status = models.IncidentStatusResolved
+	incident.ResolutionNotes = notes
+	incident.ResolvedBy = &resolvedBy
+	incident.ResolvedAt = &now
+
+	if err := database.DB.Save(&incident).Error; err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// AddAttachment stores a photo or PDF against an incident. The content is
+// sniffed rather than trusting the client's content type.
+func (s *IncidentService) AddAttachment(ctx context.Context, incidentID uuid.UUID, uploader *models.User, fileName string, size int64, r io.Reader) (*models.IncidentAttachment, error) {
+	if s.store == nil {
+		return nil, ErrStorageDisabled
+	}
+	incident, err := s.GetIncident(incidentID, uploader)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, err := storage.IncidentAttachments.Check(size, r)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := models.IncidentAttachment{
+		ID:          uuid.New(),
+		IncidentID:  incident.ID,
+		FileName:    fileName,
+		ContentType: upload.ContentType,
+		SizeBytes:   size,
+		UploadedBy:  uploader.ID,
+	}
+	attachment.StorageKey = fmt.Sprintf("incidents/%s/%s%s", incident.ID, attachment.ID, upload.Extension)
+
+	if err := s.store.Put(ctx, attachment.StorageKey, upload.ContentType, upload.Body); err != nil {
+		return nil, storeError("attachment", err)
+	}
+	if err := database.DB.Create(&attachment).Error; err != nil {
+		if delErr := s.store.Delete(ctx, attachment.StorageKey); delErr != nil {
+			log.Printf("Failed to remove orphaned attachment %s: %v", attachment.StorageKey, delErr)
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// OpenAttachment returns an attachment and its contents; the caller must close them
+func (s *IncidentService) OpenAttachment(ctx context.Context, incidentID, attachmentID uuid.UUID, viewer *models.User) (*models.IncidentAttachment, io.ReadCloser, error) {
+	if _, err := s.GetIncident(incidentID, viewer); err != nil {
+		return nil, nil, err
+	}
+
+	var attachment models.IncidentAttachment
+	if err := database.DB.First(&attachment, "id = ? AND incident_id = ?", attachmentID, incidentID).Error; err != nil {
+		return nil, nil, domainError(ErrNotFound, "attachment_not_found", "attachment not found")
+	}
+	if s.store == nil {
+		return nil, nil, ErrStorageDisabled
+	}
+
+	body, err := s.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	return &attachment, body, nil
+}
+
+func (s *IncidentService) notifyAdmins(incident models.Incident, session models.Session, reporter *models.User) {
+	if s.notificationService == nil {
+		return
+	}
+
+	var adminIDs []uuid.UUID
+	if err := database.DB.Model(&models.User{}).
+		Where("role = ?", models.RoleAdmin).
+		Pluck("id", &adminIDs).Error; err != nil {
+		log.Printf("Error finding admins for incident %s: %v", incident.ID, err)
+		return
+	}
+	if len(adminIDs) == 0 {
+		return
+	}
+
+	title := fmt.Sprintf("Incident reported (%s)", incident.Severity)
+	body := fmt.Sprintf("%s reported %q at %s on %s.",
+		reporter.Name, incident.Title, session.Title, utils.FormatDateForDisplay(session.SessionDate))
+	data := map[string]string{
+		"type":        string(models.NotificationIncidentReported),
+		"incident_id": incident.ID.String(),
+		"session_id":  session.ID.String(),
+	}
+
+	s.notificationService.SendBulkNotification(context.Background(), adminIDs, models.NotificationIncidentReported, title, body, data)
+}
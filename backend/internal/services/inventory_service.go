@@ -0,0 +1,655 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotEnoughStock      = domainError(ErrConflict, "not_enough_stock", "not enough on hand")
+	ErrNotSessionOrganizer = domainError(ErrForbidden, "not_session_organizer", "only admins and the session organizer can check equipment in and out")
+	ErrItemCheckedOut      = domainError(ErrConflict, "item_checked_out", "some of this item is checked out; check it back in first")
+)
+
+// maxConsumptionMonths bounds how far back the consumption report goes
+const maxConsumptionMonths = 24
+
+// InventoryService keeps track of the club's equipment and consumables:
+// how many it has, what organizers have taken to sessions, and what has
+// been used. Every change to a quantity is kept as a movement, and admins
+// are alerted once when an item runs low.
+type InventoryService struct {
+	notificationService *NotificationService
+}
+
+func NewInventoryService(notificationService *NotificationService) *InventoryService {
+	return &InventoryService{notificationService: notificationService}
+}
+
+// InventoryItemStock is an item with how many are out at sessions
+type InventoryItemStock struct {
+	models.InventoryItem
+	CheckedOut int  `json:"checked_out"`
+	Available  int  `json:"available"`
+	LowStock   bool `json:"low_stock"`
+}
+
+// ListItems returns every item by name, with what's checked out
+func (s *InventoryService) ListItems() ([]InventoryItemStock, error) {
+	var items []models.InventoryItem
+	if err := database.DB.Order("name ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	out, err := checkedOut(database.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := make([]InventoryItemStock, len(items))
+	for i, item := range items {
+		stock[i] = itemStock(item, out[item.ID])
+	}
+	return stock, nil
+}
+
+// GetItem returns an item with what's checked out
+func (s *InventoryService) GetItem(id uuid.UUID) (*InventoryItemStock, error) {
+	var item models.InventoryItem
+	if err := database.DB.First(&item, "id = ?", id).Error; err != nil {
+		return nil, domainError(ErrNotFound, "item_not_found", "item not found")
+	}
+	out, err := checkedOut(database.DB.Where("item_id = ?", id))
+	if err != nil {
+		return nil, err
+	}
+	stock := itemStock(item, out[id])
+	return &stock, nil
+}
+
+func itemStock(item models.InventoryItem, out int) InventoryItemStock {
+	return InventoryItemStock{
+		InventoryItem: item,
+		CheckedOut:    out,
+		Available:     item.Quantity - out,
+		LowStock:      item.IsLowStock(),
+	}
+}
+
+// checkedOut sums the quantity still out at sessions, by item
+func checkedOut(db *gorm.DB) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		ItemID   uuid.UUID
+		Quantity int
+	}
+	if err := db.Model(&models.InventoryCheckout{}).
+		Select("item_id, SUM(quantity) AS quantity").
+		Where("checked_in_at IS NULL").
+		Group("item_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		out[row.ItemID] = row.Quantity
+	}
+	return out, nil
+}
+
+// SaveInventoryItemInput describes an item. Quantity is only read when the
+// item is created; after that it changes through adjustments.
+type SaveInventoryItemInput struct {
+	Name              string
+	Category          models.InventoryCategory
+	Unit              string
+	Quantity          int
+	LowStockThreshold int
+	TracksShuttles    bool
+	Notes             string
+}
+
+func (input *SaveInventoryItemInput) validate() error {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return errors.New("name is required")
+	}
+	if input.Category == "" {
+		input.Category = models.InventoryEquipment
+	}
+	if input.Category != models.InventoryEquipment && input.Category != models.InventoryConsumable {
+		return fmt.Errorf("category must be %s or %s", models.InventoryEquipment, models.InventoryConsumable)
+	}
+	if input.Quantity < 0 || input.LowStockThreshold < 0 {
+		return errors.New("quantity and low stock threshold cannot be negative")
+	}
+	if input.TracksShuttles && input.Category != models.InventoryConsumable {
+		return errors.New("only a consumable can track shuttle usage")
+	}
+	return nil
+}
+
+// CreateItem adds an item with its opening quantity
+func (s *InventoryService) CreateItem(ctx context.Context, input SaveInventoryItemInput, actorID uuid.UUID) (*models.InventoryItem, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	item := models.InventoryItem{
+		Name:              input.Name,
+		Category:          input.Category,
+		Unit:              strings.TrimSpace(input.Unit),
+		LowStockThreshold: input.LowStockThreshold,
+		TracksShuttles:    input.TracksShuttles,
+		Notes:             strings.TrimSpace(input.Notes),
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkItemName(tx, item.Name, uuid.Nil); err != nil {
+			return err
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		if item.TracksShuttles {
+			if err := onlyShuttleItem(tx, item.ID); err != nil {
+				return err
+			}
+		}
+		if input.Quantity == 0 {
+			return nil
+		}
+		return changeStock(tx, &item, input.Quantity, models.InventoryAdjustment, nil, &actorID, "opening stock")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	alertLowStock(ctx, s.notificationService)
+	return &item, nil
+}
+
+// UpdateItem changes an item's details
+func (s *InventoryService) UpdateItem(ctx context.Context, id uuid.UUID, input SaveInventoryItemInput) (*models.InventoryItem, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+
+	var item models.InventoryItem
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&item, "id = ?", id).Error; err != nil {
+			return domainError(ErrNotFound, "item_not_found", "item not found")
+		}
+		if err := checkItemName(tx, input.Name, id); err != nil {
+			return err
+		}
+		item.Name = input.Name
+		item.Category = input.Category
+		item.Unit = strings.TrimSpace(input.Unit)
+		item.LowStockThreshold = input.LowStockThreshold
+		item.TracksShuttles = input.TracksShuttles
+		item.Notes = strings.TrimSpace(input.Notes)
+		updates := map[string]interface{}{
+			"name":                item.Name,
+			"category":            item.Category,
+			"unit":                item.Unit,
+			"low_stock_threshold": item.LowStockThreshold,
+			"tracks_shuttles":     item.TracksShuttles,
+			"notes":               item.Notes,
+		}
+		// A new threshold the item is above clears the alert
+		if !item.IsLowStock() {
+			item.LowStockNotifiedAt = nil
+			updates["low_stock_notified_at"] = nil
+		}
+		if err := tx.Model(&item).Updates(updates).Error; err != nil {
+			return err
+		}
+		if item.TracksShuttles {
+			return onlyShuttleItem(tx, item.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	alertLowStock(ctx, s.notificationService)
+	return &item, nil
+}
+
+// checkItemName fails when another item already has name
+func checkItemName(tx *gorm.DB, name string, id uuid.UUID) error {
+	var taken int64
+	if err := tx.Model(&models.InventoryItem{}).
+		Where("LOWER(name) = LOWER(?) AND id != ?", name, id).
+		Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		return errors.New("an item with that name already exists")
+	}
+	return nil
+}
+
+// onlyShuttleItem makes id the one item drawn down by shuttle usage
+func onlyShuttleItem(tx *gorm.DB, id uuid.UUID) error {
+	return tx.Model(&models.InventoryItem{}).
+		Where("tracks_shuttles = ? AND id != ?", true, id).
+		Update("tracks_shuttles", false).Error
+}
+
+// DeleteItem removes an item and its history. Items with some checked out
+// can't be deleted.
+func (s *InventoryService) DeleteItem(id uuid.UUID) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var item models.InventoryItem
+		if err := tx.First(&item, "id = ?", id).Error; err != nil {
+			return domainError(ErrNotFound, "item_not_found", "item not found")
+		}
+		out, err := checkedOut(tx.Where("item_id = ?", id))
+		if err != nil {
+			return err
+		}
+		if out[id] > 0 {
+			return ErrItemCheckedOut
+		}
+		if err := tx.Where("item_id = ?", id).Delete(&models.InventoryCheckout{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("item_id = ?", id).Delete(&models.InventoryMovement{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&item).Error
+	})
+}
+
+// Adjust restocks an item or corrects its quantity after a stocktake
+func (s *InventoryService) Adjust(ctx context.Context, id uuid.UUID, change int, reason models.InventoryMovementReason, note string, actorID uuid.UUID) (*models.InventoryItem, error) {
+	switch {
+	case change == 0:
+		return nil, errors.New("change cannot be zero")
+	case reason == models.InventoryRestock && change < 0:
+		return nil, errors.New("a restock must add to the quantity")
+	case reason != models.InventoryRestock && reason != models.InventoryAdjustment:
+		return nil, fmt.Errorf("reason must be %s or %s", models.InventoryRestock, models.InventoryAdjustment)
+	}
+
+	var item models.InventoryItem
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, "id = ?", id).Error; err != nil {
+			return domainError(ErrNotFound, "item_not_found", "item not found")
+		}
+		out, err := checkedOut(tx.Where("item_id = ?", id))
+		if err != nil {
+			return err
+		}
+		if item.Quantity+change < out[id] {
+			return fmt.Errorf("%d are checked out, so the quantity can't go below that", out[id])
+		}
+		return changeStock(tx, &item, change, reason, nil, &actorID, strings.TrimSpace(note))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	alertLowStock(ctx, s.notificationService)
+	return &item, nil
+}
+
+// Movements returns an item's quantity changes, newest first
+func (s *InventoryService) Movements(id uuid.UUID, limit int) ([]models.InventoryMovement, error) {
+	var movements []models.InventoryMovement
+	if err := database.DB.Where("item_id = ?", id).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&movements).Error; err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
+// changeStock changes an item's quantity and records the movement. Coming
+// back above the threshold re-arms the low stock alert.
+func changeStock(tx *gorm.DB, item *models.InventoryItem, change int, reason models.InventoryMovementReason, sessionID, actorID *uuid.UUID, note string) error {
+	if item.Quantity+change < 0 {
+		return ErrNotEnoughStock
+	}
+	item.Quantity += change
+	updates := map[string]interface{}{"quantity": item.Quantity}
+	if !item.IsLowStock() {
+		item.LowStockNotifiedAt = nil
+		updates["low_stock_notified_at"] = nil
+	}
+	if err := tx.Model(item).Updates(updates).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.InventoryMovement{
+		ItemID:    item.ID,
+		Change:    change,
+		Reason:    reason,
+		SessionID: sessionID,
+		ActorID:   actorID,
+		Note:      note,
+	}).Error
+}
+
+// recordShuttleUsage draws the shuttle stock down by a change in a
+// session's recorded shuttle usage, or back up when the usage is corrected
+// down. Nothing happens when no item tracks shuttles.
+func recordShuttleUsage(tx *gorm.DB, sessionID uuid.UUID, used int) error {
+	var item models.InventoryItem
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tracks_shuttles = ?", true).
+		First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// Usage beyond the stock on hand means the count was off; stop at empty
+	change := -used
+	if item.Quantity+change < 0 {
+		change = -item.Quantity
+	}
+	if change == 0 {
+		return nil
+	}
+	return changeStock(tx, &item, change, models.InventorySessionUsage, &sessionID, nil, "")
+}
+
+// alertLowStock tells admins about items that have run low since they were
+// last restocked
+func alertLowStock(ctx context.Context, notificationService *NotificationService) {
+	var items []models.InventoryItem
+	if err := database.DB.
+		Where("low_stock_threshold > 0 AND quantity <= low_stock_threshold AND low_stock_notified_at IS NULL").
+		Order("name ASC").
+		Find(&items).Error; err != nil {
+		log.Printf("Error checking for low stock: %v", err)
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	ids := make([]uuid.UUID, len(items))
+	names := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+		names[i] = fmt.Sprintf("%s (%d left)", item.Name, item.Quantity)
+	}
+	// Flag first so the alert isn't sent twice
+	if err := database.DB.Model(&models.InventoryItem{}).Where("id IN ?", ids).
+		Update("low_stock_notified_at", time.Now()).Error; err != nil {
+		log.Printf("Error flagging low stock: %v", err)
+		return
+	}
+	if notificationService == nil {
+		return
+	}
+
+	var adminIDs []uuid.UUID
+	if err := database.DB.Model(&models.User{}).
+		Where("role = ?", models.RoleAdmin).
+		Pluck("id", &adminIDs).Error; err != nil {
+		log.Printf("Error finding admins for low stock alert: %v", err)
+		return
+	}
+	if len(adminIDs) == 0 {
+		return
+	}
+
+	title := "Running Low"
+	body := fmt.Sprintf("Time to restock: %s.", strings.Join(names, ", "))
+	data := map[string]string{"type": string(models.NotificationLowStock)}
+	notificationService.SendBulkNotification(ctx, adminIDs, models.NotificationLowStock, title, body, data)
+}
+
+// organizedSession returns a session user may check equipment out for:
+// admins, and the member who organised it
+func organizedSession(tx *gorm.DB, sessionID uuid.UUID, user *models.User) (*models.Session, error) {
+	var session models.Session
+	if err := tx.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if !user.IsAdmin() && session.CreatedBy != user.ID {
+		return nil, ErrNotSessionOrganizer
+	}
+	return &session, nil
+}
+
+// SessionEquipment returns what's been checked out for a session
+func (s *InventoryService) SessionEquipment(sessionID uuid.UUID, user *models.User) ([]models.InventoryCheckout, error) {
+	if _, err := organizedSession(database.DB, sessionID, user); err != nil {
+		return nil, err
+	}
+	var checkouts []models.InventoryCheckout
+	if err := database.DB.Preload("Item").
+		Where("session_id = ?", sessionID).
+		Order("checked_out_at ASC").
+		Find(&checkouts).Error; err != nil {
+		return nil, err
+	}
+	return checkouts, nil
+}
+
+// CheckOut takes some of an item to a session
+func (s *InventoryService) CheckOut(sessionID, itemID uuid.UUID, quantity int, user *models.User) (*models.InventoryCheckout, error) {
+	if quantity < 1 {
+		return nil, errors.New("quantity must be at least 1")
+	}
+
+	var checkout models.InventoryCheckout
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := organizedSession(tx, sessionID, user); err != nil {
+			return err
+		}
+		var item models.InventoryItem
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, "id = ?", itemID).Error; err != nil {
+			return domainError(ErrNotFound, "item_not_found", "item not found")
+		}
+		out, err := checkedOut(tx.Where("item_id = ?", itemID))
+		if err != nil {
+			return err
+		}
+		if available := item.Quantity - out[itemID]; quantity > available {
+			return fmt.Errorf("%w: %d %s available", ErrNotEnoughStock, available, item.Name)
+		}
+
+		checkout = models.InventoryCheckout{
+			ItemID:       itemID,
+			SessionID:    sessionID,
+			Quantity:     quantity,
+			CheckedOutBy: user.ID,
+			CheckedOutAt: time.Now(),
+			Item:         &item,
+		}
+		return tx.Create(&checkout).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &checkout, nil
+}
+
+// CheckIn brings a checkout back, all of it unless returned says otherwise.
+// Whatever isn't returned comes off the item's quantity.
+func (s *InventoryService) CheckIn(ctx context.Context, sessionID, checkoutID uuid.UUID, returned *int, user *models.User) (*models.InventoryCheckout, error) {
+	var checkout models.InventoryCheckout
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := organizedSession(tx, sessionID, user); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ? AND session_id = ?", checkoutID, sessionID).First(&checkout).Error; err != nil {
+			return domainError(ErrNotFound, "checkout_not_found", "checkout not found")
+		}
+		if checkout.CheckedInAt != nil {
+			return errors.New("already checked in")
+		}
+		back := checkout.Quantity
+		if returned != nil {
+			back = *returned
+		}
+		if back < 0 || back > checkout.Quantity {
+			return fmt.Errorf("returned quantity must be between 0 and %d", checkout.Quantity)
+		}
+
+		now := time.Now()
+		checkout.ReturnedQuantity = &back
+		checkout.CheckedInBy = &user.ID
+		checkout.CheckedInAt = &now
+		if err := tx.Model(&checkout).Updates(map[string]interface{}{
+			"returned_quantity": back,
+			"checked_in_by":     user.ID,
+			"checked_in_at":     now,
+		}).Error; err != nil {
+			return err
+		}
+
+		var item models.InventoryItem
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, "id = ?", checkout.ItemID).Error; err != nil {
+			return err
+		}
+		checkout.Item = &item
+		if missing := checkout.Quantity - back; missing > 0 {
+			return changeStock(tx, &item, -missing, models.InventoryNotReturned, &sessionID, &user.ID, "")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	alertLowStock(ctx, s.notificationService)
+	return &checkout, nil
+}
+
+// ItemConsumption is how much of an item was used up and restocked in a month
+type ItemConsumption struct {
+	ItemID    uuid.UUID `json:"item_id"`
+	Name      string    `json:"name"`
+	Unit      string    `json:"unit"`
+	Used      int       `json:"used"` // used at sessions or not returned
+	Restocked int       `json:"restocked"`
+}
+
+// MonthlyConsumption is a month's line in the consumption report
+type MonthlyConsumption struct {
+	Month             string            `json:"month"` // YYYY-MM
+	ShuttlesUsed      int               `json:"shuttles_used"`
+	SessionsWithUsage int               `json:"sessions_with_usage"`
+	Items             []ItemConsumption `json:"items"`
+}
+
+// ConsumptionReport is what the club got through over recent months, and
+// how long the shuttles on hand will last at that rate
+type ConsumptionReport struct {
+	Months                []MonthlyConsumption `json:"months"`
+	AvgShuttlesPerSession float64              `json:"avg_shuttles_per_session"`
+	ShuttlesOnHand        *int                 `json:"shuttles_on_hand,omitempty"`       // when an item tracks shuttles
+	SessionsOfStockLeft   *int                 `json:"sessions_of_stock_left,omitempty"` // at the average above
+}
+
+// Consumption reports the last months calendar months in Sydney, this one
+// included, oldest first. Shuttle use comes from each session's recorded
+// usage; other items from their movements.
+func (s *InventoryService) Consumption(months int) (*ConsumptionReport, error) {
+	if months < 1 || months > maxConsumptionMonths {
+		return nil, fmt.Errorf("months must be between 1 and %d", maxConsumptionMonths)
+	}
+	now := utils.NowInSydney()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, utils.SydneyLocation).AddDate(0, 1-months, 0)
+
+	report := &ConsumptionReport{Months: make([]MonthlyConsumption, months)}
+	byMonth := make(map[string]*MonthlyConsumption, months)
+	for i := range report.Months {
+		month := start.AddDate(0, i, 0).Format("2006-01")
+		report.Months[i] = MonthlyConsumption{Month: month, Items: []ItemConsumption{}}
+		byMonth[month] = &report.Months[i]
+	}
+
+	var sessions []models.Session
+	if err := database.DB.Select("session_date", "shuttles_used").
+		Where("session_date >= ? AND shuttles_used IS NOT NULL AND status != ?", start, models.SessionStatusCancelled).
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	var shuttles, sessionsWithUsage int
+	for _, session := range sessions {
+		month, ok := byMonth[session.SessionDate.Format("2006-01")]
+		if !ok {
+			continue
+		}
+		month.ShuttlesUsed += *session.ShuttlesUsed
+		month.SessionsWithUsage++
+		shuttles += *session.ShuttlesUsed
+		sessionsWithUsage++
+	}
+	if sessionsWithUsage > 0 {
+		report.AvgShuttlesPerSession = float64(shuttles) / float64(sessionsWithUsage)
+	}
+
+	var items []models.InventoryItem
+	if err := database.DB.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	itemsByID := make(map[uuid.UUID]models.InventoryItem, len(items))
+	for _, item := range items {
+		itemsByID[item.ID] = item
+		if item.TracksShuttles {
+			onHand := item.Quantity
+			report.ShuttlesOnHand = &onHand
+			if report.AvgShuttlesPerSession > 0 {
+				left := int(float64(onHand) / report.AvgShuttlesPerSession)
+				report.SessionsOfStockLeft = &left
+			}
+		}
+	}
+
+	var movements []models.InventoryMovement
+	if err := database.DB.Where("created_at >= ? AND reason IN ?", start,
+		[]models.InventoryMovementReason{models.InventoryRestock, models.InventorySessionUsage, models.InventoryNotReturned}).
+		Find(&movements).Error; err != nil {
+		return nil, err
+	}
+	lines := make(map[string]map[uuid.UUID]*ItemConsumption, months)
+	for _, movement := range movements {
+		month, ok := byMonth[movement.CreatedAt.In(utils.SydneyLocation).Format("2006-01")]
+		item, known := itemsByID[movement.ItemID]
+		if !ok || !known {
+			continue
+		}
+		if lines[month.Month] == nil {
+			lines[month.Month] = map[uuid.UUID]*ItemConsumption{}
+		}
+		line := lines[month.Month][item.ID]
+		if line == nil {
+			line = &ItemConsumption{ItemID: item.ID, Name: item.Name, Unit: item.Unit}
+			lines[month.Month][item.ID] = line
+		}
+		if movement.Reason == models.InventoryRestock {
+			line.Restocked += movement.Change
+		} else {
+			line.Used -= movement.Change
+		}
+	}
+	for i := range report.Months {
+		month := &report.Months[i]
+		for _, line := range lines[month.Month] {
+			month.Items = append(month.Items, *line)
+		}
+		sort.Slice(month.Items, func(a, b int) bool { return month.Items[a].Name < month.Items[b].Name })
+	}
+	return report, nil
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInviteInvalid     = domainError(ErrNotFound, "invite_invalid", "invite link is not valid")
+	ErrInviteExpired     = domainError(ErrConflict, "invite_expired", "invite link has expired or been used up")
+	ErrAlreadyUsedInvite = domainError(ErrConflict, "invite_already_used", "you have already joined with another invite")
+	ErrInviteNotFound    = domainError(ErrNotFound, "invite_not_found", "invite not found")
+	ErrInviteRevoked     = domainError(ErrConflict, "invite_revoked", "invite has already been revoked")
+)
+
+const (
+	// MaxInviteUses caps how many people one link can let in
+	MaxInviteUses = 500
+	// MaxInviteLifetime is how far ahead an invite can expire
+	MaxInviteLifetime = 365 * 24 * time.Hour
+)
+
+type InviteService struct {
+	frontendURL string
+}
+
+func NewInviteService(frontendURL string) *InviteService {
+	return &InviteService{frontendURL: strings.TrimRight(frontendURL, "/")}
+}
+
+// Link returns the sign-up URL to hand out for an invite
+func (s *InviteService) Link(invite *models.Invite) string {
+	return s.frontendURL + "/join?invite=" + invite.Token
+}
+
+type CreateInviteInput struct {
+	Label       string
+	MaxUses     int
+	ExpiresAt   time.Time
+	AutoApprove bool
+}
+
+// Create makes a new invite link
+func (s *InviteService) Create(input CreateInviteInput, adminID uuid.UUID) (*models.Invite, error) {
+	if input.MaxUses < 1 || input.MaxUses > MaxInviteUses {
+		return nil, fmt.Errorf("max uses must be between 1 and %d", MaxInviteUses)
+	}
+	now := time.Now()
+	if !input.ExpiresAt.After(now) {
+		return nil, errors.New("expiry must be in the future")
+	}
+	if input.ExpiresAt.After(now.Add(MaxInviteLifetime)) {
+		return nil, errors.New("invites can last at most a year")
+	}
+
+	token, err := newInviteToken()
+	if err != nil {
+		return nil, err
+	}
+	invite := models.Invite{
+		Token:       token,
+		Label:       strings.TrimSpace(input.Label),
+		MaxUses:     input.MaxUses,
+		ExpiresAt:   input.ExpiresAt,
+		AutoApprove: input.AutoApprove,
+		CreatedBy:   adminID,
+	}
+	if err := database.DB.Create(&invite).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// newInviteToken returns a random URL-safe token
+func newInviteToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// List returns every invite, newest first
+func (s *InviteService) List() ([]models.Invite, error) {
+	var invites []models.Invite
+	if err := database.DB.Order("created_at DESC").Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+// Get returns an invite with the members who joined with it, earliest first
+func (s *InviteService) Get(id uuid.UUID) (*models.Invite, []models.InviteUse, error) {
+	var invite models.Invite
+	if err := database.DB.First(&invite, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInviteNotFound
+		}
+		return nil, nil, err
+	}
+
+	var uses []models.InviteUse
+	if err := database.DB.Preload("User").Where("invite_id = ?", id).Order("used_at").Find(&uses).Error; err != nil {
+		return nil, nil, err
+	}
+	return &invite, uses, nil
+}
+
+// Revoke stops an invite letting anyone else in. Members who already joined
+// with it are left as they are.
+func (s *InviteService) Revoke(id uuid.UUID) (*models.Invite, error) {
+	var invite models.Invite
+	if err := database.DB.First(&invite, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInviteNotFound
+		}
+		return nil, err
+	}
+	if invite.RevokedAt != nil {
+		return nil, ErrInviteRevoked
+	}
+
+	now := time.Now()
+	invite.RevokedAt = &now
+	if err := database.DB.Model(&invite).Update("revoked_at", now).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// Preview returns the invite behind a token, for the sign-up page to show
+// before the visitor signs in
+func (s *InviteService) Preview(token string) (*models.Invite, error) {
+	var invite models.Invite
+	if err := database.DB.Where("token = ?", token).First(&invite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInviteInvalid
+		}
+		return nil, err
+	}
+	if invite.RevokedAt != nil {
+		return nil, ErrInviteInvalid
+	}
+	return &invite, nil
+}
+
+// Accept records a pending member joining with an invite, and approves
+// them if the invite says to. Accepting the same invite again is a no-op,
+// so a retried sign-in doesn't use up a second place.
+func (s *InviteService) Accept(userID uuid.UUID, token string) (*models.InviteUse, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrInviteInvalid
+	}
+
+	var use models.InviteUse
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var invite models.Invite
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token = ?", token).First(&invite).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInviteInvalid
+			}
+			return err
+		}
+
+		err := tx.Where("user_id = ?", userID).First(&use).Error
+		if err == nil {
+			if use.InviteID != invite.ID {
+				return ErrAlreadyUsedInvite
+			}
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if invite.RevokedAt != nil {
+			return ErrInviteInvalid
+		}
+		if !invite.IsUsable(time.Now()) {
+			return ErrInviteExpired
+		}
+
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		if user.MembershipStatus != models.MembershipPending {
+			return ErrNotPendingMember
+		}
+
+		if err := tx.Model(&invite).UpdateColumn("uses", gorm.Expr("uses + 1")).Error; err != nil {
+			return err
+		}
+		use = models.InviteUse{
+			InviteID: invite.ID,
+			UserID:   user.ID,
+			Approved: invite.AutoApprove,
+			UsedAt:   time.Now(),
+		}
+		if err := tx.Create(&use).Error; err != nil {
+			return err
+		}
+
+		if !invite.AutoApprove {
+			return nil
+		}
+		detail := invite.Label
+		if detail == "" {
+			detail = "invite link"
+		}
+		return approveMember(tx, &user, &models.JoinApproval{
+			Rule:       models.JoinRuleInvite,
+			Detail:     detail,
+			InviteID:   &invite.ID,
+			ApprovedBy: &invite.CreatedBy,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Member %s joined with invite %s (approved: %t)", userID, use.InviteID, use.Approved)
+	return &use, nil
+}
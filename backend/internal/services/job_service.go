@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+// Scheduler jobs, as recorded in job runs
+const (
+	JobSessionReminders    = "session_reminders"
+	JobDeadlineReminders   = "deadline_reminders"
+	JobFairShareAllocation = "fair_share_allocation"
+	JobBadgeEvaluation     = "badge_evaluation"
+	JobRecurringSessions   = "recurring_sessions"
+	JobPushTokenCleanup    = "push_token_cleanup"
+	JobDatabaseBackup      = "database_backup"
+	JobDatabaseRestore     = "database_restore"
+	JobAnnouncementNudges  = "announcement_nudges"
+	JobDataArchive         = "data_archive"
+	JobMemberInactivity    = "member_inactivity"
+	JobRSVPSummaries       = "rsvp_summaries"
+	JobMaybeRSVPs          = "maybe_rsvps"
+	JobMonthlyRecaps       = "monthly_recaps"
+	JobWeatherProposals    = "weather_proposals"
+	JobRSVPCounts          = "rsvp_counts"
+	JobOverbooking         = "overbooking"
+	JobContactFollowUps    = "contact_follow_ups"
+)
+
+// ErrUnknownJob is returned when asked to run a job that can't be run on demand
+var ErrUnknownJob = domainError(ErrNotFound, "unknown_job", "unknown job")
+
+// JobReport lists what a job run on demand did, or on a dry run would have done
+type JobReport struct {
+	Job     string      `json:"job"`
+	DryRun  bool        `json:"dry_run"`
+	Actions []JobAction `json:"actions"`
+}
+
+// JobAction is one notification sent, session created, push token removed,
+// backup written or pruned, table archived, or maybe RSVP changed to out
+type JobAction struct {
+	Kind      string     `json:"kind"` // notification, create_session, delete_push_token, create_backup, delete_backup, archive, member_active, expire_maybe, propose_cancellation, fix_rsvp_counts or waitlist_overbooked
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	SessionID *uuid.UUID `json:"session_id,omitempty"`
+	Title     string     `json:"title"`
+	Detail    string     `json:"detail,omitempty"`
+}
+
+// isDryRun reports whether changes should be skipped. Scheduled runs pass a
+// nil report, so both methods are safe to call on nil.
+func (r *JobReport) isDryRun() bool {
+	return r != nil && r.DryRun
+}
+
+func (r *JobReport) add(action JobAction) {
+	if r != nil {
+		r.Actions = append(r.Actions, action)
+	}
+}
+
+// addNotifications records a batch of notifications about a session
+func (r *JobReport) addNotifications(notifType models.NotificationType, sessionID uuid.UUID, messages []NotificationMessage) {
+	for _, m := range messages {
+		userID := m.UserID
+		r.add(JobAction{
+			Kind:      "notification",
+			UserID:    &userID,
+			SessionID: &sessionID,
+			Title:     m.Title,
+			Detail:    string(notifType) + ": " + m.Body,
+		})
+	}
+}
+
+// JobService records scheduler job runs and reports them to an external
+// healthcheck, so reminders that silently stop going out get noticed
+type JobService struct {
+	healthcheckURL string // empty disables pings
+	httpClient     *http.Client
+}
+
+// NewJobService creates a job service. healthcheckURL is pinged after each
+// hourly run, with /fail appended when a job failed (healthchecks.io style).
+func NewJobService(healthcheckURL string) *JobService {
+	return &JobService{
+		healthcheckURL: strings.TrimSuffix(healthcheckURL, "/"),
+		httpClient:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Run runs fn as the named job and records how it went. A panic in fn is
+// recorded as a failure rather than taking down the scheduler.
+func (s *JobService) Run(name string, fn func() error) (err error) {
+	run := models.JobRun{JobName: name, StartedAt: time.Now(), Status: models.JobRunStatusRunning}
+	if createErr := database.DB.Create(&run).Error; createErr != nil {
+		log.Printf("Error recording start of job %s: %v", name, createErr)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+
+		finished := time.Now()
+		run.FinishedAt = &finished
+		run.Status = models.JobRunStatusSucceeded
+		if err != nil {
+			run.Status = models.JobRunStatusFailed
+			run.Error = err.Error()
+			log.Printf("Job %s failed: %v", name, err)
+		}
+		if saveErr := database.DB.Save(&run).Error; saveErr != nil {
+			log.Printf("Error recording result of job %s: %v", name, saveErr)
+		}
+	}()
+
+	return fn()
+}
+
+// PingHealthcheck tells the healthcheck the scheduler is alive, and whether its jobs failed
+func (s *JobService) PingHealthcheck(ctx context.Context, failed bool, detail string) {
+	if s.healthcheckURL == "" {
+		return
+	}
+
+	url := s.healthcheckURL
+	if failed {
+		url += "/fail"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(detail))
+	if err != nil {
+		log.Printf("Error building healthcheck ping: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "text/plain")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		log.Printf("Error pinging healthcheck: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("Healthcheck ping returned %s", resp.Status)
+	}
+}
+
+// ListJobRuns returns the most recent runs, optionally of one job
+func (s *JobService) ListJobRuns(name string, limit int) ([]models.JobRun, error) {
+	query := database.DB.Model(&models.JobRun{})
+	if name != "" {
+		query = query.Where("job_name = ?", name)
+	}
+
+	var runs []models.JobRun
+	if err := query.Order("started_at DESC").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// JobStatus is the latest state of one job
+type JobStatus struct {
+	Name            string         `json:"name"`
+	LastRun         *models.JobRun `json:"last_run"`
+	LastSucceededAt *time.Time     `json:"last_succeeded_at"`
+}
+
+// JobStatuses returns the latest run and last success of every job that has run
+func (s *JobService) JobStatuses() ([]JobStatus, error) {
+	var latest []models.JobRun
+	if err := database.DB.Raw(
+		"SELECT DISTINCT ON (job_name) * FROM job_runs ORDER BY job_name, started_at DESC",
+	).Scan(&latest).Error; err != nil {
+		return nil, err
+	}
+
+	var successes []struct {
+		JobName    string
+		FinishedAt time.Time
+	}
+	if err := database.DB.Model(&models.JobRun{}).
+		Select("job_name, MAX(finished_at) AS finished_at").
+		Where("status = ?", models.JobRunStatusSucceeded).
+		Group("job_name").
+		Scan(&successes).Error; err != nil {
+		return nil, err
+	}
+	lastSucceeded := make(map[string]time.Time, len(successes))
+	for _, row := range successes {
+		lastSucceeded[row.JobName] = row.FinishedAt
+	}
+
+	statuses := make([]JobStatus, len(latest))
+	for i := range latest {
+		statuses[i] = JobStatus{Name: latest[i].JobName, LastRun: &latest[i]}
+		if t, ok := lastSucceeded[latest[i].JobName]; ok {
+			statuses[i].LastSucceededAt = &t
+		}
+	}
+	return statuses, nil
+}
@@ -0,0 +1,436 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrReferralCodeInvalid = domainError(ErrInvalid, "referral_code_invalid", "referral code is not valid")
+	ErrNoReferralCode      = domainError(ErrForbidden, "no_referral_code", "only approved members have a referral code")
+	ErrNotPendingMember    = domainError(ErrConflict, "not_pending_member", "user is not pending approval")
+)
+
+// referralCodeAlphabet leaves out characters that are easy to misread
+const referralCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const referralCodeLength = 8
+
+type JoinRuleService struct{}
+
+func NewJoinRuleService() *JoinRuleService {
+	return &JoinRuleService{}
+}
+
+// Rules returns the club's join rules. A club that has never set them has
+// auto-approval off.
+func (s *JoinRuleService) Rules() (*models.JoinRules, error) {
+	var club models.Club
+	if err := database.DB.First(&club).Error; err != nil {
+		return nil, err
+	}
+
+	var rules models.JoinRules
+	err := database.DB.Where("club_id = ?", club.ID).First(&rules).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.JoinRules{ClubID: club.ID, EmailDomains: []string{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rules.EmailDomains == nil {
+		rules.EmailDomains = []string{}
+	}
+	return &rules, nil
+}
+
+// JoinRulesInput holds rule changes; nil fields are left as they are
+type JoinRulesInput struct {
+	AutoApprove     *bool
+	EmailDomains    *[]string // replaces the whole allowlist
+	ReferralCodes   *bool
+	PreapprovedList *bool
+}
+
+// UpdateRules changes the club's join rules
+func (s *JoinRuleService) UpdateRules(input JoinRulesInput, adminID uuid.UUID) (*models.JoinRules, error) {
+	rules, err := s.Rules()
+	if err != nil {
+		return nil, err
+	}
+
+	if input.AutoApprove != nil {
+		rules.AutoApprove = *input.AutoApprove
+	}
+	if input.EmailDomains != nil {
+		domains, err := normalizeEmailDomains(*input.EmailDomains)
+		if err != nil {
+			return nil, err
+		}
+		rules.EmailDomains = domains
+	}
+	if input.ReferralCodes != nil {
+		rules.ReferralCodes = *input.ReferralCodes
+	}
+	if input.PreapprovedList != nil {
+		rules.PreapprovedList = *input.PreapprovedList
+	}
+	rules.UpdatedBy = &adminID
+
+	if err := database.DB.Save(rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// normalizeEmailDomains lower-cases the allowlist, drops a leading @ and
+// duplicates, and rejects anything that isn't a domain
+func normalizeEmailDomains(domains []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+		if domain == "" || seen[domain] {
+			continue
+		}
+		if strings.ContainsAny(domain, "@ /") || !strings.Contains(domain, ".") ||
+			strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+			return nil, fmt.Errorf("%q is not an email domain", domain)
+		}
+		seen[domain] = true
+		normalized = append(normalized, domain)
+	}
+	return normalized, nil
+}
+
+// AutoApprove approves a pending member when one of the join rules matches,
+// and records which. The imported list is checked first, then the email
+// domain, then the referral code. Email rules only count once Auth0 has
+// verified the address. It returns nil, leaving the request for an admin,
+// when auto-approval is off or nothing matches; an unusable referral code
+// is ErrReferralCodeInvalid if it was the only way in.
+func (s *JoinRuleService) AutoApprove(userID uuid.UUID, emailVerified bool, referralCode string) (*models.JoinApproval, error) {
+	rules, err := s.Rules()
+	if err != nil {
+		return nil, err
+	}
+	if !rules.AutoApprove {
+		return nil, nil
+	}
+	referralCode = strings.ToUpper(strings.TrimSpace(referralCode))
+
+	var approval *models.JoinApproval
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		if user.MembershipStatus != models.MembershipPending {
+			return nil
+		}
+
+		var err error
+		approval, err = matchJoinRule(tx, rules, &user, emailVerified, referralCode)
+		if approval == nil {
+			return err
+		}
+
+		switch approval.Rule {
+		case models.JoinRulePreapproved:
+			now := time.Now()
+			if err := tx.Model(&models.PreapprovedMember{}).
+				Where("email = ? AND used_by IS NULL", approval.Detail).
+				Updates(map[string]interface{}{"used_by": user.ID, "used_at": now}).Error; err != nil {
+				return err
+			}
+		case models.JoinRuleReferralCode:
+			if err := tx.Model(&models.ReferralCode{}).Where("id = ?", *approval.ReferralCodeID).
+				UpdateColumn("uses", gorm.Expr("uses + 1")).Error; err != nil {
+				return err
+			}
+		}
+
+		return approveMember(tx, &user, approval)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if approval != nil {
+		log.Printf("Auto-approved join request from %s by %s rule", userID, approval.Rule)
+	}
+	return approval, nil
+}
+
+// matchJoinRule returns the approval for the first rule the user meets, or
+// nil when none does
+func matchJoinRule(tx *gorm.DB, rules *models.JoinRules, user *models.User, emailVerified bool, referralCode string) (*models.JoinApproval, error) {
+	email := strings.ToLower(strings.TrimSpace(user.Email))
+
+	if emailVerified && rules.PreapprovedList {
+		var entry models.PreapprovedMember
+		err := tx.Where("email = ? AND used_by IS NULL", email).First(&entry).Error
+		if err == nil {
+			return &models.JoinApproval{Rule: models.JoinRulePreapproved, Detail: entry.Email}, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	if emailVerified {
+		if _, domain, ok := strings.Cut(email, "@"); ok {
+			for _, allowed := range rules.EmailDomains {
+				if domain == allowed {
+					return &models.JoinApproval{Rule: models.JoinRuleEmailDomain, Detail: domain}, nil
+				}
+			}
+		}
+	}
+
+	if referralCode == "" || !rules.ReferralCodes {
+		return nil, nil
+	}
+	var code models.ReferralCode
+	if err := tx.Preload("Owner").Where("code = ?", referralCode).First(&code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReferralCodeInvalid
+		}
+		return nil, err
+	}
+	// A code only vouches while its owner is an approved member
+	if code.DisabledAt != nil || code.OwnerID == user.ID || code.Owner == nil ||
+		code.Owner.MembershipStatus != models.MembershipApproved {
+		return nil, ErrReferralCodeInvalid
+	}
+	return &models.JoinApproval{
+		Rule:           models.JoinRuleReferralCode,
+		Detail:         code.Code,
+		ReferralCodeID: &code.ID,
+	}, nil
+}
+
+// approveMember makes a pending user a player and records how, replacing
+// any record from an earlier approval
+func approveMember(tx *gorm.DB, user *models.User, approval *models.JoinApproval) error {
+	user.MembershipStatus = models.MembershipApproved
+	user.Role = models.RolePlayer
+	user.UpdatedAt = time.Now()
+	if err := tx.Save(user).Error; err != nil {
+		return err
+	}
+
+	approval.UserID = user.ID
+	approval.CreatedAt = time.Now()
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rule", "detail", "referral_code_id", "invite_id", "approved_by", "created_at"}),
+	}).Create(approval).Error; err != nil {
+		return err
+	}
+
+	return recordEvent(tx, Event{
+		Type:    models.EventMemberApproved,
+		UserID:  &user.ID,
+		ActorID: approval.ApprovedBy,
+		Data:    MemberApprovedEvent{Rule: approval.Rule, Detail: approval.Detail},
+	})
+}
+
+// MemberApprovedEvent is the data of the member.approved domain event
+type MemberApprovedEvent struct {
+	Rule   models.JoinRule `json:"rule"`
+	Detail string          `json:"detail,omitempty"`
+}
+
+// RedeemReferralCode approves a pending member with a referral code after
+// sign-up, for when they didn't have it at the time
+func (s *JoinRuleService) RedeemReferralCode(userID uuid.UUID, code string) (*models.JoinApproval, error) {
+	if strings.TrimSpace(code) == "" {
+		return nil, ErrReferralCodeInvalid
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	if user.MembershipStatus != models.MembershipPending {
+		return nil, ErrNotPendingMember
+	}
+
+	// The email rules were checked at sign-in
+	approval, err := s.AutoApprove(userID, false, code)
+	if err != nil {
+		return nil, err
+	}
+	if approval == nil {
+		// Auto-approval is off, or referral codes don't count
+		return nil, ErrReferralCodeInvalid
+	}
+	return approval, nil
+}
+
+// MyReferralCode returns an approved member's referral code, creating it
+// the first time
+func (s *JoinRuleService) MyReferralCode(user *models.User) (*models.ReferralCode, error) {
+	var code models.ReferralCode
+	err := database.DB.Where("owner_id = ?", user.ID).First(&code).Error
+	if err == nil {
+		return &code, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if user.MembershipStatus != models.MembershipApproved {
+		return nil, ErrNoReferralCode
+	}
+
+	// Retry the rare clash with an existing code
+	for attempt := 0; attempt < 5; attempt++ {
+		value, err := newReferralCode()
+		if err != nil {
+			return nil, err
+		}
+		code = models.ReferralCode{Code: value, OwnerID: user.ID}
+		result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&code)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return &code, nil
+		}
+		// Lost a race with another request for this member's code
+		if err := database.DB.Where("owner_id = ?", user.ID).First(&code).Error; err == nil {
+			return &code, nil
+		}
+	}
+	return nil, errors.New("failed to generate a referral code")
+}
+
+// newReferralCode returns a random code from referralCodeAlphabet
+func newReferralCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(referralCodeAlphabet)))
+	for i := 0; i < referralCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(referralCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// ListReferralCodes returns every member's referral code with its owner,
+// most used first
+func (s *JoinRuleService) ListReferralCodes() ([]models.ReferralCode, error) {
+	var codes []models.ReferralCode
+	if err := database.DB.Preload("Owner").Order("uses DESC, created_at").Find(&codes).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// SetReferralCodeDisabled stops a referral code approving anyone, or lets it
+// again
+func (s *JoinRuleService) SetReferralCodeDisabled(id uuid.UUID, disabled bool) (*models.ReferralCode, error) {
+	var code models.ReferralCode
+	if err := database.DB.Preload("Owner").First(&code, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	if disabled && code.DisabledAt == nil {
+		now := time.Now()
+		code.DisabledAt = &now
+	} else if !disabled {
+		code.DisabledAt = nil
+	}
+	if err := database.DB.Model(&code).Update("disabled_at", code.DisabledAt).Error; err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// PreapprovedEntry is one member on an imported list
+type PreapprovedEntry struct {
+	Email string
+	Name  string
+}
+
+// ImportPreapproved adds members to the pre-approved list. Emails already on
+// it are skipped; it returns how many were added.
+func (s *JoinRuleService) ImportPreapproved(entries []PreapprovedEntry, adminID uuid.UUID) (int, error) {
+	members := make([]models.PreapprovedMember, 0, len(entries))
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		email := strings.ToLower(strings.TrimSpace(entry.Email))
+		if email == "" || seen[email] {
+			continue
+		}
+		if !strings.Contains(email, "@") {
+			return 0, fmt.Errorf("%q is not an email address", entry.Email)
+		}
+		seen[email] = true
+		members = append(members, models.PreapprovedMember{
+			Email:   email,
+			Name:    strings.TrimSpace(entry.Name),
+			AddedBy: adminID,
+		})
+	}
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&members, 500)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+// ListPreapproved returns the pre-approved list, entries not yet used first
+func (s *JoinRuleService) ListPreapproved() ([]models.PreapprovedMember, error) {
+	var members []models.PreapprovedMember
+	if err := database.DB.Order("used_at IS NOT NULL, email").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// DeletePreapproved takes an entry off the pre-approved list. Members who
+// already joined with it stay approved.
+func (s *JoinRuleService) DeletePreapproved(id uuid.UUID) error {
+	result := database.DB.Delete(&models.PreapprovedMember{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListApprovals returns how members were approved, newest first, optionally
+// for one rule
+func (s *JoinRuleService) ListApprovals(rule models.JoinRule, limit, offset int) ([]models.JoinApproval, error) {
+	query := database.DB.Preload("User").Order("created_at DESC").Limit(limit).Offset(offset)
+	if rule != "" {
+		query = query.Where("rule = ?", rule)
+	}
+
+	var approvals []models.JoinApproval
+	if err := query.Find(&approvals).Error; err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
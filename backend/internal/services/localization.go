@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+var chineseWeekdays = [...]string{"星期日", "星期一", "星期二", "星期三", "星期四", "星期五", "星期六"}
+
+// formatChineseDate formats a date in Sydney as e.g. "2025年3月5日 星期三"
+func formatChineseDate(t time.Time) string {
+	t = t.In(utils.SydneyLocation)
+	return fmt.Sprintf("%d年%d月%d日 %s", t.Year(), t.Month(), t.Day(), chineseWeekdays[t.Weekday()])
+}
+
+// messageTemplateFuncs are the functions message wording can call, by
+// language. Times are on the 24-hour clock in every language.
+var messageTemplateFuncs = map[models.Language]template.FuncMap{
+	models.LanguageEnglish: {
+		"join":  strings.Join,
+		"clock": utils.FormatClock,
+		"date":  utils.FormatDateForDisplay,
+	},
+	models.LanguageChinese: {
+		"join":  strings.Join,
+		"clock": utils.FormatClock,
+		"date":  formatChineseDate,
+	},
+}
+
+// templateFuncs returns the functions for wording in lang
+func templateFuncs(lang models.Language) template.FuncMap {
+	for _, l := range lang.Fallbacks() {
+		if funcs, ok := messageTemplateFuncs[l]; ok {
+			return funcs
+		}
+	}
+	return messageTemplateFuncs[models.DefaultLanguage]
+}
+
+// emailWording is the fixed text around the message in every email
+type emailWording struct {
+	viewDashboard     string
+	reason            string
+	managePreferences string
+	replyHint         string // added to emails members can RSVP to by replying
+}
+
+var emailWordings = map[models.Language]emailWording{
+	models.LanguageEnglish: {
+		viewDashboard:     "View Dashboard",
+		reason:            "You received this email because you have notifications enabled for Weekday Masters.",
+		managePreferences: "Manage your notification preferences",
+		replyHint:         replyHint,
+	},
+	models.LanguageChinese: {
+		viewDashboard:     "查看主页",
+		reason:            "你收到这封邮件，是因为你开启了 Weekday Masters 的通知。",
+		managePreferences: "管理通知设置",
+		replyHint:         "直接回复此邮件 IN、OUT 或 MAYBE 即可报名。",
+	},
+}
+
+// emailWordingFor returns the email text in lang, or the default language's
+func emailWordingFor(lang models.Language) emailWording {
+	for _, l := range lang.Fallbacks() {
+		if w, ok := emailWordings[l]; ok {
+			return w
+		}
+	}
+	return emailWordings[models.DefaultLanguage]
+}
+
+// emailLanguageTag is lang as an HTML lang attribute
+func emailLanguageTag(lang models.Language) string {
+	if !lang.IsValid() {
+		return string(models.DefaultLanguage)
+	}
+	return string(lang)
+}
+
+// recipientLanguages returns each user's preferred language. Users not found
+// are left out, and get the default language from messageSet.
+func recipientLanguages(userIDs []uuid.UUID) map[uuid.UUID]models.Language {
+	languages := make(map[uuid.UUID]models.Language, len(userIDs))
+	if len(userIDs) == 0 {
+		return languages
+	}
+
+	var rows []struct {
+		ID                uuid.UUID
+		PreferredLanguage models.Language
+	}
+	database.DB.Model(&models.User{}).Select("id, preferred_language").
+		Where("id IN ?", userIDs).Scan(&rows)
+	for _, r := range rows {
+		languages[r.ID] = r.PreferredLanguage
+	}
+	return languages
+}
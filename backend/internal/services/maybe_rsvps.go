@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// checkMaybeRSVPs nudges members still on MAYBE as a session's RSVP deadline
+// approaches and, if the club expires maybes, changes the ones left at the
+// cutoff to OUT so the confirmed count can be planned around
+func (s *SchedulerService) checkMaybeRSVPs(report *JobReport) error {
+	var club models.Club
+	database.DB.First(&club)
+
+	ctx := context.Background()
+	var errs []error
+	if club.MaybeNudgeHours > 0 {
+		errs = append(errs, s.sendMaybeNudges(ctx, club, report))
+	}
+	if club.ExpireMaybes {
+		errs = append(errs, s.expireMaybeRSVPs(ctx, club, report))
+	}
+	return errors.Join(errs...)
+}
+
+// maybeCutoff is when a session's lingering MAYBE RSVPs are changed to OUT
+func maybeCutoff(club models.Club, session models.Session) time.Time {
+	return session.RSVPDeadline.Add(-time.Duration(club.MaybeExpiryHours) * time.Hour)
+}
+
+// sendMaybeNudges asks members on MAYBE to commit, once per session, when the
+// RSVP deadline is within the club's nudge window
+func (s *SchedulerService) sendMaybeNudges(ctx context.Context, club models.Club, report *JobReport) error {
+	now := time.Now()
+	windowEnd := now.Add(time.Duration(club.MaybeNudgeHours) * time.Hour)
+
+	var sessions []models.Session
+	if err := database.DB.Where(
+		"rsvp_deadline > ? AND rsvp_deadline <= ? AND status = ? AND maybe_nudge_sent_at IS NULL",
+		now, windowEnd, models.SessionStatusOpen,
+	).Order("rsvp_deadline ASC").Find(&sessions).Error; err != nil {
+		return fmt.Errorf("fetching sessions for maybe nudges: %w", err)
+	}
+
+	var errs []error
+	for _, session := range sessions {
+		errs = append(errs, s.sendMaybeNudge(ctx, club, session, report))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *SchedulerService) sendMaybeNudge(ctx context.Context, club models.Club, session models.Session, report *JobReport) error {
+	var recipients []uuid.UUID
+	if err := database.DB.Model(&models.RSVP{}).
+		Where("session_id = ? AND status = ?", session.ID, models.RSVPStatusMaybe).
+		Order("rsvp_timestamp ASC").
+		Pluck("user_id", &recipients).Error; err != nil {
+		return fmt.Errorf("fetching maybe RSVPs for session %s: %w", session.ID, err)
+	}
+
+	cutoff := maybeCutoff(club, session)
+	messages, err := loadMessage(models.MessageMaybeNudge).messagesFor(recipients, sameData(MaybeNudgeContext{
+		Session:  session,
+		Date:     utils.FormatDateForDisplay(session.SessionDate),
+		Deadline: session.RSVPDeadline.In(utils.SydneyLocation).Format("Monday 3:04 PM"),
+		Expires:  club.ExpireMaybes && cutoff.After(time.Now()),
+		Cutoff:   cutoff,
+	}), map[string]string{
+		"type":       string(models.NotificationMaybeNudge),
+		"session_id": session.ID.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("rendering maybe nudge for session %s: %w", session.ID, err)
+	}
+
+	report.addNotifications(models.NotificationMaybeNudge, session.ID, messages)
+	if report.isDryRun() {
+		return nil
+	}
+
+	if len(messages) > 0 {
+		if _, err := s.notificationService.SendBatch(ctx, models.NotificationMaybeNudge, messages); err != nil {
+			return fmt.Errorf("sending maybe nudges for session %s: %w", session.ID, err)
+		}
+	}
+	// Marked without touching updated_at, which clients sync sessions by
+	if err := database.DB.Model(&models.Session{}).Where("id = ?", session.ID).
+		UpdateColumn("maybe_nudge_sent_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("marking maybe nudge sent for session %s: %w", session.ID, err)
+	}
+	if len(messages) > 0 {
+		log.Printf("Nudged %d maybes to commit for session %s", len(messages), session.Title)
+	}
+	return nil
+}
+
+// expireMaybeRSVPs changes MAYBE RSVPs to OUT on sessions past the club's
+// cutoff. Only RSVPs last changed before the cutoff are expired, so a member
+// an admin marks MAYBE afterwards keeps it.
+func (s *SchedulerService) expireMaybeRSVPs(ctx context.Context, club models.Club, report *JobReport) error {
+	now := time.Now()
+	expiry := time.Duration(club.MaybeExpiryHours) * time.Hour
+
+	var sessions []models.Session
+	if err := database.DB.Where(
+		"rsvp_deadline <= ? AND starts_at > ? AND status != ?",
+		now.Add(expiry), now, models.SessionStatusCancelled,
+	).Order("starts_at ASC").Find(&sessions).Error; err != nil {
+		return fmt.Errorf("fetching sessions for maybe expiry: %w", err)
+	}
+
+	var errs []error
+	for _, session := range sessions {
+		errs = append(errs, s.expireSessionMaybes(ctx, session, maybeCutoff(club, session), report))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *SchedulerService) expireSessionMaybes(ctx context.Context, session models.Session, cutoff time.Time, report *JobReport) error {
+	var rsvps []models.RSVP
+	if err := database.DB.Preload("User").
+		Where("session_id = ? AND status = ? AND updated_at <= ?", session.ID, models.RSVPStatusMaybe, cutoff).
+		Find(&rsvps).Error; err != nil {
+		return fmt.Errorf("fetching maybe RSVPs for session %s: %w", session.ID, err)
+	}
+	if len(rsvps) == 0 {
+		return nil
+	}
+
+	recipients := make([]uuid.UUID, len(rsvps))
+	for i, rsvp := range rsvps {
+		recipients[i] = rsvp.UserID
+		name := "A former member"
+		if rsvp.User != nil {
+			name = rsvp.User.Name
+		}
+		report.add(JobAction{Kind: "expire_maybe", UserID: &recipients[i], SessionID: &session.ID, Title: name, Detail: "maybe changed to out for " + session.Title})
+	}
+
+	expired := recipients
+	if !report.isDryRun() {
+		expired = nil
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			now := time.Now()
+			for _, rsvp := range rsvps {
+				// Only if it's still MAYBE, in case the member answered meanwhile
+				result := tx.Model(&models.RSVP{}).
+					Where("id = ? AND status = ?", rsvp.ID, models.RSVPStatusMaybe).
+					Updates(map[string]interface{}{"status": models.RSVPStatusOut, "updated_at": now})
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					continue
+				}
+				if err := models.RecordRSVPEvent(tx, session.ID, rsvp.UserID, models.RSVPStatusOut); err != nil {
+					return err
+				}
+				expired = append(expired, rsvp.UserID)
+			}
+			return models.RefreshRSVPCounts(tx, session.ID)
+		})
+		if err != nil {
+			return fmt.Errorf("expiring maybe RSVPs for session %s: %w", session.ID, err)
+		}
+	}
+
+	messages, err := loadMessage(models.MessageMaybeExpired).messagesFor(expired, sameData(MaybeExpiredContext{
+		Session: session,
+		Date:    utils.FormatDateForDisplay(session.SessionDate),
+	}), map[string]string{
+		"type":       string(models.NotificationRSVPChanged),
+		"session_id": session.ID.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("rendering maybe expiry notice for session %s: %w", session.ID, err)
+	}
+
+	report.addNotifications(models.NotificationRSVPChanged, session.ID, messages)
+	if report.isDryRun() || len(messages) == 0 {
+		return nil
+	}
+
+	if _, err := s.notificationService.SendBatch(ctx, models.NotificationRSVPChanged, messages); err != nil {
+		return fmt.Errorf("sending maybe expiry notices for session %s: %w", session.ID, err)
+	}
+	log.Printf("Changed %d maybe RSVPs to out for session %s", len(expired), session.Title)
+	return nil
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// memberActivitySQL is each member's latest RSVP change, check-ins included,
+// across live and archived RSVPs
+const memberActivitySQL = `SELECT user_id, MAX(updated_at) AS last_active FROM (
+	SELECT user_id, updated_at FROM rsvps
+	UNION ALL
+	SELECT user_id, updated_at FROM rsvps_archive
+) rsvp_activity GROUP BY user_id`
+
+// MemberInactivityService finds members who have stopped playing. Members
+// with no RSVPs for inactiveAfterMonths are told so, and once graceDays have
+// passed without them playing they join a queue for an admin to archive them
+// or keep them on. Archived members are off the member list and reminders
+// until they sign in again or an admin restores them.
+type MemberInactivityService struct {
+	notificationService *NotificationService
+	inactiveAfterMonths int // 0 turns the policy off
+	graceDays           int
+}
+
+func NewMemberInactivityService(notificationService *NotificationService, inactiveAfterMonths, graceDays int) *MemberInactivityService {
+	return &MemberInactivityService{
+		notificationService: notificationService,
+		inactiveAfterMonths: inactiveAfterMonths,
+		graceDays:           graceDays,
+	}
+}
+
+// IsEnabled reports whether members are checked for inactivity
+func (s *MemberInactivityService) IsEnabled() bool {
+	return s.inactiveAfterMonths > 0
+}
+
+// InactiveMember is a member in the archival review queue
+type InactiveMember struct {
+	User           models.User
+	LastActiveAt   *time.Time // latest RSVP, if they've ever made one
+	ReviewDueAt    time.Time  // end of their grace period
+	ReadyToArchive bool
+}
+
+// players are the approved members the policy applies to, with their latest
+// activity as activity.last_active. Admins and non-playing members are left
+// alone.
+func (s *MemberInactivityService) players() *gorm.DB {
+	return database.DB.Model(&models.User{}).
+		Joins("LEFT JOIN ("+memberActivitySQL+") activity ON activity.user_id = users.id").
+		Where("users.membership_status = ? AND users.role != ? AND users.is_player = ?",
+			models.MembershipApproved, models.RoleAdmin, true)
+}
+
+// CheckInactivity clears the flag on flagged members who have played since,
+// and tells members who haven't played for the policy's months that they
+// will be reviewed for archival
+func (s *MemberInactivityService) CheckInactivity(ctx context.Context, report *JobReport) error {
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	var returned []models.User
+	if err := s.players().
+		Where("users.inactive_notified_at IS NOT NULL AND activity.last_active > users.inactive_notified_at").
+		Find(&returned).Error; err != nil {
+		return fmt.Errorf("fetching returning members: %w", err)
+	}
+	ids := make([]uuid.UUID, len(returned))
+	for i, member := range returned {
+		ids[i] = member.ID
+		report.add(JobAction{Kind: "member_active", UserID: &ids[i], Title: member.Name, Detail: "played again after being told they were inactive"})
+	}
+	if len(ids) > 0 && !report.isDryRun() {
+		if err := database.DB.Model(&models.User{}).Where("id IN ?", ids).
+			Update("inactive_notified_at", nil).Error; err != nil {
+			return fmt.Errorf("clearing inactivity for returning members: %w", err)
+		}
+	}
+
+	cutoff := time.Now().AddDate(0, -s.inactiveAfterMonths, 0)
+	var inactive []models.User
+	if err := s.players().
+		Where("users.inactive_notified_at IS NULL").
+		Where("GREATEST(users.created_at, users.kept_active_at, activity.last_active) < ?", cutoff).
+		Find(&inactive).Error; err != nil {
+		return fmt.Errorf("fetching inactive members: %w", err)
+	}
+
+	title := "We've missed you on court"
+	body := fmt.Sprintf("You haven't played in %d months. RSVP to a session in the next %d days to keep your membership active; "+
+		"after that an admin may archive it. You can come back any time by signing in.", s.inactiveAfterMonths, s.graceDays)
+	data := map[string]string{"type": string(models.NotificationMemberInactive)}
+	messages := make([]NotificationMessage, len(inactive))
+	ids = make([]uuid.UUID, len(inactive))
+	for i, member := range inactive {
+		ids[i] = member.ID
+		messages[i] = NotificationMessage{UserID: member.ID, Title: title, Body: body, Data: data}
+		report.add(JobAction{Kind: "notification", UserID: &ids[i], Title: title, Detail: string(models.NotificationMemberInactive) + ": " + member.Name})
+	}
+	if len(ids) == 0 || report.isDryRun() {
+		return nil
+	}
+
+	// Flag first so a failed send isn't repeated every night
+	if err := database.DB.Model(&models.User{}).Where("id IN ?", ids).
+		Update("inactive_notified_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("flagging inactive members: %w", err)
+	}
+	sent, err := s.notificationService.SendBatch(ctx, models.NotificationMemberInactive, messages)
+	if err != nil {
+		return fmt.Errorf("notifying inactive members: %w", err)
+	}
+	log.Printf("Told %d members they've been inactive for %d months", sent, s.inactiveAfterMonths)
+	return nil
+}
+
+// ReviewQueue returns flagged members, longest flagged first. Those whose
+// grace period is over are ready for an admin to archive or keep.
+func (s *MemberInactivityService) ReviewQueue() ([]InactiveMember, error) {
+	var users []models.User
+	if err := database.DB.
+		Where("membership_status = ? AND inactive_notified_at IS NOT NULL", models.MembershipApproved).
+		Order("inactive_notified_at ASC").
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	var activity []struct {
+		UserID     uuid.UUID
+		LastActive time.Time
+	}
+	if len(ids) > 0 {
+		if err := database.DB.Raw("SELECT user_id, last_active FROM ("+memberActivitySQL+") activity WHERE user_id IN ?", ids).
+			Scan(&activity).Error; err != nil {
+			return nil, err
+		}
+	}
+	lastActive := make(map[uuid.UUID]time.Time, len(activity))
+	for _, a := range activity {
+		lastActive[a.UserID] = a.LastActive
+	}
+
+	now := time.Now()
+	queue := make([]InactiveMember, len(users))
+	for i, user := range users {
+		due := user.InactiveNotifiedAt.AddDate(0, 0, s.graceDays)
+		queue[i] = InactiveMember{User: user, ReviewDueAt: due, ReadyToArchive: !now.Before(due)}
+		if t, ok := lastActive[user.ID]; ok {
+			queue[i].LastActiveAt = &t
+		}
+	}
+	return queue, nil
+}
+
+// Archive archives a flagged member whose grace period is over
+func (s *MemberInactivityService) Archive(userID, actorID uuid.UUID) (*models.User, error) {
+	return s.review(userID, actorID, models.AuditActionMemberArchived, func(user *models.User) (map[string]interface{}, error) {
+		due := user.InactiveNotifiedAt.AddDate(0, 0, s.graceDays)
+		if time.Now().Before(due) {
+			return nil, fmt.Errorf("the member has until %s to play again", due.Format("2 January"))
+		}
+		return map[string]interface{}{
+			"membership_status": models.MembershipArchived,
+			"archived_at":       time.Now(),
+		}, nil
+	})
+}
+
+// KeepActive takes a flagged member out of the queue; their inactivity is
+// counted afresh from now
+func (s *MemberInactivityService) KeepActive(userID, actorID uuid.UUID) (*models.User, error) {
+	return s.review(userID, actorID, models.AuditActionMemberKeptActive, func(*models.User) (map[string]interface{}, error) {
+		return map[string]interface{}{
+			"inactive_notified_at": nil,
+			"kept_active_at":       time.Now(),
+		}, nil
+	})
+}
+
+func (s *MemberInactivityService) review(userID, actorID uuid.UUID, action models.AuditAction, change func(*models.User) (map[string]interface{}, error)) (*models.User, error) {
+	var user models.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return domainError(ErrNotFound, "member_not_found", "member not found")
+		}
+		if user.MembershipStatus != models.MembershipApproved || user.InactiveNotifiedAt == nil {
+			return errors.New("member is not awaiting inactivity review")
+		}
+		updates, err := change(&user)
+		if err != nil {
+			return err
+		}
+		notifiedAt := user.InactiveNotifiedAt.Format(time.RFC3339)
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			EntityType: "user",
+			EntityID:   user.ID,
+			Action:     action,
+			ActorID:    actorID,
+			OldValue:   notifiedAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Restore brings an archived member back as an approved member, with their
+// inactivity counted afresh. Members restore themselves by signing in, with
+// actorID their own.
+func (s *MemberInactivityService) Restore(userID, actorID uuid.UUID) (*models.User, error) {
+	var user models.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return domainError(ErrNotFound, "member_not_found", "member not found")
+		}
+		return restoreArchivedMember(tx, &user, actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// restoreArchivedMember makes an archived user an approved member again
+func restoreArchivedMember(tx *gorm.DB, user *models.User, actorID uuid.UUID) error {
+	if user.MembershipStatus != models.MembershipArchived {
+		return errors.New("member is not archived")
+	}
+	archivedAt := ""
+	if user.ArchivedAt != nil {
+		archivedAt = user.ArchivedAt.Format(time.RFC3339)
+	}
+	now := time.Now()
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"membership_status":    models.MembershipApproved,
+		"archived_at":          nil,
+		"inactive_notified_at": nil,
+		"kept_active_at":       now,
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.AuditLog{
+		EntityType: "user",
+		EntityID:   user.ID,
+		Action:     models.AuditActionMemberRestored,
+		ActorID:    actorID,
+		OldValue:   archivedAt,
+	}).Error
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+// parseMentions returns the members that @mentions in body refer to, in the
+// order first mentioned. A mention matches a member's full name, or their
+// first name when no other member shares it; case is ignored and the longest
+// match wins, so "@Sam Lee" picks Sam Lee over another Sam. An @ inside a
+// word, as in an email address, isn't a mention.
+func parseMentions(body string, members []models.User) []uuid.UUID {
+	type candidate struct {
+		id   uuid.UUID
+		name string
+	}
+	var candidates []candidate
+	firstNames := make(map[string][]uuid.UUID)
+	for _, m := range members {
+		name := strings.ToLower(strings.Join(strings.Fields(m.Name), " "))
+		if name == "" {
+			continue
+		}
+		candidates = append(candidates, candidate{id: m.ID, name: name})
+		first, _, _ := strings.Cut(name, " ")
+		firstNames[first] = append(firstNames[first], m.ID)
+	}
+	for first, ids := range firstNames {
+		if len(ids) == 1 {
+			candidates = append(candidates, candidate{id: ids[0], name: first})
+		}
+	}
+
+	text := strings.ToLower(body)
+	seen := make(map[uuid.UUID]bool)
+	var mentioned []uuid.UUID
+	for i := 0; i < len(text); i++ {
+		if text[i] != '@' {
+			continue
+		}
+		if i > 0 {
+			if prev, _ := utf8.DecodeLastRuneInString(text[:i]); isNameRune(prev) {
+				continue
+			}
+		}
+
+		rest := collapseSpaces(text[i+1:])
+		var best *candidate
+		for j := range candidates {
+			c := &candidates[j]
+			if mentionMatches(rest, c.name) && (best == nil || len(c.name) > len(best.name)) {
+				best = c
+			}
+		}
+		if best != nil && !seen[best.id] {
+			seen[best.id] = true
+			mentioned = append(mentioned, best.id)
+		}
+	}
+	return mentioned
+}
+
+// mentionMatches reports whether text starts with name as whole words
+func mentionMatches(text, name string) bool {
+	if !strings.HasPrefix(text, name) {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(text[len(name):])
+	return next == utf8.RuneError || !isNameRune(next)
+}
+
+// collapseSpaces turns runs of whitespace into single spaces, so a mention
+// split across a line break still matches
+func collapseSpaces(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '-'
+}
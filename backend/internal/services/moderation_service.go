@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrContentRejected is returned when text contains a banned word
+var ErrContentRejected = domainError(ErrRejected, "content_rejected", "content contains language that isn't allowed")
+
+type ModerationService struct {
+	mu                  sync.RWMutex
+	bannedPattern       *regexp.Regexp
+	notificationService *NotificationService
+}
+
+// NewModerationService creates a moderation service that rejects the given
+// words (case-insensitive, whole words only)
+func NewModerationService(bannedWords []string, notificationService *NotificationService) *ModerationService {
+	service := &ModerationService{notificationService: notificationService}
+	service.SetBannedWords(bannedWords)
+	return service
+}
+
+// SetBannedWords replaces the list of rejected words
+func (s *ModerationService) SetBannedWords(bannedWords []string) {
+	var pattern *regexp.Regexp
+	if len(bannedWords) > 0 {
+		quoted := make([]string, len(bannedWords))
+		for i, w := range bannedWords {
+			quoted[i] = regexp.QuoteMeta(strings.ToLower(w))
+		}
+		pattern = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+	}
+
+	s.mu.Lock()
+	s.bannedPattern = pattern
+	s.mu.Unlock()
+}
+
+// CheckText returns ErrContentRejected if any of the texts contain a banned word
+func (s *ModerationService) CheckText(texts ...string) error {
+	s.mu.RLock()
+	pattern := s.bannedPattern
+	s.mu.RUnlock()
+
+	if pattern == nil {
+		return nil
+	}
+	for _, t := range texts {
+		if pattern.MatchString(t) {
+			return ErrContentRejected
+		}
+	}
+	return nil
+}
+
+// ReportComment files an abuse report against a comment
+func (s *ModerationService) ReportComment(commentID, reporterID uuid.UUID, reason string) (*models.CommentReport, error) {
+	var comment models.Comment
+	if err := database.DB.First(&comment, "id = ?", commentID).Error; err != nil {
+		return nil, domainError(ErrNotFound, "comment_not_found", "comment not found")
+	}
+	if comment.UserID == reporterID {
+		return nil, errors.New("cannot report your own comment")
+	}
+
+	var existing int64
+	database.DB.Model(&models.CommentReport{}).
+		Where("comment_id = ? AND reported_by = ?", commentID, reporterID).
+		Count(&existing)
+	if existing > 0 {
+		return nil, errors.New("you have already reported this comment")
+	}
+
+	report := models.CommentReport{
+		CommentID:  commentID,
+		ReportedBy: reporterID,
+		Reason:     reason,
+		Status:     models.ReportStatusOpen,
+	}
+	if err := database.DB.Create(&report).Error; err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// ListOpenReports returns the moderation queue, oldest first
+func (s *ModerationService) ListOpenReports() ([]models.CommentReport, error) {
+	var reports []models.CommentReport
+	if err := database.DB.Where("status = ?", models.ReportStatusOpen).
+		Preload("Comment.User").
+		Preload("Reporter").
+		Order("created_at ASC").
+		Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// ResolveReport applies a moderation action and closes every open report on the same comment
+func (s *ModerationService) ResolveReport(ctx context.Context, reportID, adminID uuid.UUID, action models.ModerationAction, note string) (*models.CommentReport, error) {
+	var report models.CommentReport
+	if err := database.DB.Preload("Comment").First(&report, "id = ?", reportID).Error; err != nil {
+		return nil, domainError(ErrNotFound, "report_not_found", "report not found")
+	}
+	if report.Status != models.ReportStatusOpen {
+		return nil, errors.New("report is already resolved")
+	}
+	if report.Comment == nil {
+		return nil, errors.New("reported comment no longer exists")
+	}
+	comment := *report.Comment
+
+	now := time.Now()
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.CommentReport{}).
+			Where("comment_id = ? AND status = ?", comment.ID, models.ReportStatusOpen).
+			Updates(map[string]interface{}{
+				"status":      models.ReportStatusResolved,
+				"action":      action,
+				"resolved_by": adminID,
+				"resolved_at": now,
+			}).Error; err != nil {
+			return err
+		}
+
+		// Deleting a comment also removes its reports
+		switch action {
+		case models.ModerationHide:
+			if err := tx.Model(&comment).Updates(map[string]interface{}{"hidden": true, "updated_at": now}).Error; err != nil {
+				return err
+			}
+		case models.ModerationDelete:
+			if err := tx.Delete(&comment).Error; err != nil {
+				return err
+			}
+		case models.ModerationWarn, models.ModerationDismiss:
+			// No change to the comment
+		default:
+			return fmt.Errorf("unknown moderation action %q", action)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if action == models.ModerationWarn && s.notificationService != nil {
+		title := "Community Guidelines Reminder"
+		body := "An admin reviewed one of your session comments and asked that you keep discussion respectful."
+		if note != "" {
+			body += " Note from the admin: " + note
+		}
+		data := map[string]string{
+			"type":       string(models.NotificationModeration),
+			"comment_id": comment.ID.String(),
+		}
+		if err := s.notificationService.SendNotification(ctx, comment.UserID, models.NotificationModeration, title, body, data); err != nil {
+			log.Printf("Error sending moderation warning to user %s: %v", comment.UserID, err)
+		}
+	}
+
+	report.Status = models.ReportStatusResolved
+	report.Action = &action
+	report.ResolvedBy = &adminID
+	report.ResolvedAt = &now
+	return &report, nil
+}
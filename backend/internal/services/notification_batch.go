@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+// notificationWorkers bounds concurrent provider calls during a batch send
+const notificationWorkers = 8
+
+// NotificationMessage is one recipient's notification in a batch
+type NotificationMessage struct {
+	UserID uuid.UUID
+	Title  string
+	Body   string
+	Data   map[string]string
+
+	// Urgent sends push, email and SMS whatever the member's preferences,
+	// without waiting for the club's email window
+	Urgent bool
+}
+
+// SendBatch sends many notifications of one type using a fixed number of
+// queries: users, preferences, and push tokens are each loaded once, the
+// notification records are inserted together, and delivery fans out over a
+// bounded worker pool. It blocks until every send has been attempted and
+// returns the number of notifications recorded.
+func (s *NotificationService) SendBatch(ctx context.Context, notifType models.NotificationType, messages []NotificationMessage) (int, error) {
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(messages))
+	for _, m := range messages {
+		userIDs = append(userIDs, m.UserID)
+	}
+
+	// Users
+	var users []models.User
+	if err := database.DB.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return 0, fmt.Errorf("failed to get users: %w", err)
+	}
+	userMap := make(map[uuid.UUID]*models.User, len(users))
+	for i := range users {
+		userMap[users[i].ID] = &users[i]
+	}
+
+	// Preferences, creating defaults for anyone who has none yet
+	var prefs []models.UserNotificationPreferences
+	if err := database.DB.Where("user_id IN ?", userIDs).Find(&prefs).Error; err != nil {
+		return 0, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	prefsMap := make(map[uuid.UUID]*models.UserNotificationPreferences, len(prefs))
+	for i := range prefs {
+		prefsMap[prefs[i].UserID] = &prefs[i]
+	}
+	var missing []models.UserNotificationPreferences
+	for id := range userMap {
+		if _, ok := prefsMap[id]; !ok {
+			missing = append(missing, models.UserNotificationPreferences{UserID: id})
+		}
+	}
+	if len(missing) > 0 {
+		if err := database.DB.Create(&missing).Error; err != nil {
+			return 0, fmt.Errorf("failed to create default preferences: %w", err)
+		}
+		// Reload so database column defaults are reflected
+		var created []models.UserNotificationPreferences
+		ids := make([]uuid.UUID, len(missing))
+		for i, p := range missing {
+			ids[i] = p.UserID
+		}
+		database.DB.Where("user_id IN ?", ids).Find(&created)
+		for i := range created {
+			prefsMap[created[i].UserID] = &created[i]
+		}
+	}
+	if err := loadTypePreferences(prefsMap); err != nil {
+		return 0, fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	// Push tokens
+	tokenMap := make(map[uuid.UUID][]models.UserPushToken)
+	if s.fcmEnabled {
+		var tokens []models.UserPushToken
+		if err := database.DB.Where("user_id IN ?", userIDs).Find(&tokens).Error; err != nil {
+			return 0, fmt.Errorf("failed to get push tokens: %w", err)
+		}
+		for _, t := range tokens {
+			tokenMap[t.UserID] = append(tokenMap[t.UserID], t)
+		}
+	}
+
+	// Notification records in one insert
+	notifications := make([]models.Notification, 0, len(messages))
+	sendable := make([]NotificationMessage, 0, len(messages))
+	for _, m := range messages {
+		if _, ok := userMap[m.UserID]; !ok {
+			log.Printf("Skipping notification for unknown user %s", m.UserID)
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			UserID:           m.UserID,
+			NotificationType: notifType,
+			Title:            m.Title,
+			Body:             m.Body,
+			Data:             models.NotificationData(m.Data),
+			Urgent:           m.Urgent,
+		})
+		sendable = append(sendable, m)
+	}
+	if len(notifications) == 0 {
+		return 0, nil
+	}
+	if err := database.DB.Create(&notifications).Error; err != nil {
+		return 0, fmt.Errorf("failed to create notification records: %w", err)
+	}
+
+	// Outside the club's email window, emails wait for the scheduler
+	emailHold := emailHoldUntil(notifType)
+
+	// Fan out delivery
+	var mu sync.Mutex
+	var pushed, emailed, queued, texted []uuid.UUID
+	failures := make(map[uuid.UUID]map[string]interface{})
+	recordFailure := func(id uuid.UUID, column string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures[id] == nil {
+			failures[id] = map[string]interface{}{}
+		}
+		failures[id][column] = err.Error()
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < notificationWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				m := sendable[i]
+				user := userMap[m.UserID]
+				p := prefsMap[m.UserID]
+				if p == nil {
+					continue
+				}
+
+				if s.fcmEnabled && (m.Urgent || p.IsEnabled(notifType, models.ChannelPush)) {
+					if err := s.sendPushToTokens(ctx, m.UserID, tokenMap[m.UserID], m.Title, m.Body, m.Data); err != nil {
+						log.Printf("Failed to send push to user %s: %v", m.UserID, err)
+						recordFailure(notifications[i].ID, "push_error", err)
+					} else {
+						mu.Lock()
+						pushed = append(pushed, notifications[i].ID)
+						mu.Unlock()
+					}
+				}
+
+				if s.emailEnabled && (m.Urgent || p.IsEnabled(notifType, models.ChannelEmail)) && user.Email != "" {
+					if emailHold != nil && !m.Urgent {
+						mu.Lock()
+						queued = append(queued, notifications[i].ID)
+						mu.Unlock()
+					} else if err := s.sendEmailNotification(ctx, user.Email, user.Name, m.Title, m.Body, notifType, s.replyAddressFor(m.UserID, notifType, m.Data), user.PreferredLanguage); err != nil {
+						log.Printf("Failed to send email to user %s: %v", m.UserID, err)
+						recordFailure(notifications[i].ID, "email_error", err)
+					} else {
+						mu.Lock()
+						emailed = append(emailed, notifications[i].ID)
+						mu.Unlock()
+					}
+				}
+
+				if s.smsEnabled && m.Urgent && user.PhoneNumber != "" {
+					if err := s.sendSMS(ctx, user.PhoneNumber, m.Title, m.Body); err != nil {
+						log.Printf("Failed to send SMS to user %s: %v", m.UserID, err)
+						recordFailure(notifications[i].ID, "sms_error", err)
+					} else {
+						mu.Lock()
+						texted = append(texted, notifications[i].ID)
+						mu.Unlock()
+					}
+				}
+			}
+		}()
+	}
+	for i := range sendable {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Record delivery in one update per channel rather than one per notification
+	now := time.Now()
+	if len(pushed) > 0 {
+		database.DB.Model(&models.Notification{}).Where("id IN ?", pushed).
+			Updates(map[string]interface{}{"push_sent": true, "push_sent_at": now})
+	}
+	if len(emailed) > 0 {
+		database.DB.Model(&models.Notification{}).Where("id IN ?", emailed).
+			Updates(map[string]interface{}{"email_sent": true, "email_sent_at": now})
+	}
+	if len(texted) > 0 {
+		database.DB.Model(&models.Notification{}).Where("id IN ?", texted).
+			Updates(map[string]interface{}{"sms_sent": true, "sms_sent_at": now})
+	}
+	if len(queued) > 0 {
+		database.DB.Model(&models.Notification{}).Where("id IN ?", queued).
+			Update("email_queued_until", *emailHold)
+	}
+	// Failures are expected to be rare, so they're recorded individually
+	for id, errs := range failures {
+		database.DB.Model(&models.Notification{}).Where("id = ?", id).Updates(errs)
+	}
+
+	return len(notifications), nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+// NotificationLogFilter narrows the admin notification log; zero values don't filter
+type NotificationLogFilter struct {
+	UserID     *uuid.UUID
+	Type       *models.NotificationType
+	Channel    string // "push", "email" or "sms": sent or attempted on that channel
+	FailedOnly bool
+}
+
+// ListNotificationLog returns notifications across all members, newest first,
+// with the recipient loaded
+func (s *NotificationService) ListNotificationLog(filter NotificationLogFilter, limit, offset int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	query := database.DB.Preload("User").Order("created_at DESC")
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Type != nil {
+		query = query.Where("notification_type = ?", *filter.Type)
+	}
+
+	switch filter.Channel {
+	case "push":
+		if filter.FailedOnly {
+			query = query.Where("push_sent = ? AND push_error <> ''", false)
+		} else {
+			query = query.Where("push_sent = ? OR push_error <> ''", true)
+		}
+	case "email":
+		if filter.FailedOnly {
+			query = query.Where("email_sent = ? AND email_error <> ''", false)
+		} else {
+			query = query.Where("email_sent = ? OR email_error <> ''", true)
+		}
+	case "sms":
+		if filter.FailedOnly {
+			query = query.Where("sms_sent = ? AND sms_error <> ''", false)
+		} else {
+			query = query.Where("sms_sent = ? OR sms_error <> ''", true)
+		}
+	default:
+		if filter.FailedOnly {
+			query = query.Where("(push_sent = ? AND push_error <> '') OR (email_sent = ? AND email_error <> '') OR (sms_sent = ? AND sms_error <> '')", false, false, false)
+		}
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	if err := query.Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// ResendNotification retries the channels of a notification that haven't been
+// delivered yet, still honouring the member's current preferences unless the
+// notification was urgent
+func (s *NotificationService) ResendNotification(ctx context.Context, id uuid.UUID) (*models.Notification, error) {
+	var notification models.Notification
+	if err := database.DB.Preload("User").First(&notification, "id = ?", id).Error; err != nil {
+		return nil, domainError(ErrNotFound, "notification_not_found", "notification not found")
+	}
+	if notification.User == nil {
+		return nil, errors.New("recipient no longer exists")
+	}
+
+	prefs, err := s.GetUserPreferences(notification.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	push := !notification.PushSent && s.fcmEnabled &&
+		(notification.Urgent || prefs.IsEnabled(notification.NotificationType, models.ChannelPush))
+	email := !notification.EmailSent && s.emailEnabled && notification.User.Email != "" &&
+		(notification.Urgent || prefs.IsEnabled(notification.NotificationType, models.ChannelEmail))
+	sms := notification.Urgent && !notification.SMSSent && s.smsEnabled && notification.User.PhoneNumber != ""
+	if !push && !email && !sms {
+		return nil, errors.New("nothing to resend: every enabled channel has already been delivered")
+	}
+
+	s.deliver(ctx, &notification, notification.User, push, email)
+	if sms {
+		s.deliverSMS(ctx, &notification, notification.User)
+	}
+
+	if err := database.DB.Model(&notification).Select(
+		"push_sent", "push_sent_at", "push_error", "email_sent", "email_sent_at", "email_error", "email_queued_until",
+		"sms_sent", "sms_sent_at", "sms_error",
+	).Updates(&notification).Error; err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TypePreferenceUpdates maps notification types to the channels being turned on or off
+type TypePreferenceUpdates map[models.NotificationType]map[models.NotificationChannel]bool
+
+// GetUserPreferences retrieves notification preferences for a user, with
+// their per-type choices, creating the defaults on first use
+func (s *NotificationService) GetUserPreferences(userID uuid.UUID) (*models.UserNotificationPreferences, error) {
+	var prefs models.UserNotificationPreferences
+	result := database.DB.Where("user_id = ?", userID).First(&prefs)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		// Create default preferences
+		prefs = models.UserNotificationPreferences{UserID: userID}
+		if err := database.DB.Create(&prefs).Error; err != nil {
+			return nil, err
+		}
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if err := loadTypePreferences(map[uuid.UUID]*models.UserNotificationPreferences{userID: &prefs}); err != nil {
+		return nil, err
+	}
+	prefs.Settings = prefs.TypeSettings()
+	return &prefs, nil
+}
+
+// UpdateUserPreferences updates a user's general notification settings and
+// their choices for individual types. Types that follow another type, and
+// channels a type can't be muted on, are refused.
+func (s *NotificationService) UpdateUserPreferences(userID uuid.UUID, updates map[string]interface{}, types TypePreferenceUpdates) (*models.UserNotificationPreferences, error) {
+	var rows []models.NotificationTypePreference
+	for t, channels := range types {
+		defaults, ok := models.NotificationDefaults[t]
+		if !ok {
+			return nil, domainError(ErrInvalid, "unknown_notification_type", fmt.Sprintf("unknown notification type %q", t))
+		}
+		for channel, enabled := range channels {
+			if defaults.Inherits != "" || !defaults.IsConfigurable(channel) {
+				return nil, domainError(ErrInvalid, "preference_not_configurable",
+					fmt.Sprintf("%s notifications can't be changed for %s", channel, t))
+			}
+			rows = append(rows, models.NotificationTypePreference{UserID: userID, Type: t, Channel: channel, Enabled: enabled})
+		}
+	}
+
+	prefs, err := s.GetUserPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(prefs).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if len(rows) > 0 {
+			return tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "channel"}},
+				DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+			}).Create(&rows).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Reload to get updated values
+	return s.GetUserPreferences(userID)
+}
+
+// loadTypePreferences fills in Types for each user's preferences in one query
+func loadTypePreferences(prefs map[uuid.UUID]*models.UserNotificationPreferences) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	userIDs := make([]uuid.UUID, 0, len(prefs))
+	for id := range prefs {
+		userIDs = append(userIDs, id)
+	}
+
+	var rows []models.NotificationTypePreference
+	if err := database.DB.Where("user_id IN ?", userIDs).Find(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if p := prefs[row.UserID]; p != nil {
+			p.Types = append(p.Types, row)
+		}
+	}
+	return nil
+}
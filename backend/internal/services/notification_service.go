@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	firebase "firebase.google.com/go/v4"
@@ -15,18 +16,34 @@ import (
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
 	"github.com/weekday-masters/backend/internal/database"
 	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/resilience"
 	"google.golang.org/api/option"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type NotificationService struct {
+	// clientsMu guards the provider clients, which are replaced when their
+	// credentials are rotated
+	clientsMu      sync.RWMutex
 	fcmClient      *messaging.Client
 	sendGridClient *sendgrid.Client
-	fromEmail      string
-	fromName       string
-	frontendURL    string
-	fcmEnabled     bool
-	emailEnabled   bool
+	smsClient      *twilioClient
+
+	fromEmail    string
+	fromName     string
+	frontendURL  string
+	replies      *replyAddresses // nil unless inbound email is set up
+	fcmEnabled   bool
+	emailEnabled bool
+	smsEnabled   bool
+
+	// Resilience for external providers so an outage fails fast instead of
+	// stalling every send (and the scheduler behind it)
+	fcmBreaker   *resilience.CircuitBreaker
+	emailBreaker *resilience.CircuitBreaker
+	smsBreaker   *resilience.CircuitBreaker
+	retryPolicy  resilience.Policy
 }
 
 type NotificationConfig struct {
@@ -35,32 +52,40 @@ type NotificationConfig struct {
 	SendGridFromEmail   string
 	SendGridFromName    string
 	FrontendURL         string
+
+	// Twilio texts urgent notifications; SMS is disabled without all three
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	TwilioFromNumber string
+
+	// Reminder emails can be answered by replying; both are needed
+	InboundEmailDomain string
+	InboundEmailSecret string
 }
 
 // NewNotificationService creates a new notification service
-// It gracefully handles missing credentials (FCM or SendGrid can be disabled independently)
+// It gracefully handles missing credentials (FCM, SendGrid or Twilio can be disabled independently)
 func NewNotificationService(cfg NotificationConfig) *NotificationService {
 	service := &NotificationService{
-		fromEmail:   cfg.SendGridFromEmail,
-		fromName:    cfg.SendGridFromName,
-		frontendURL: cfg.FrontendURL,
+		fromEmail:    cfg.SendGridFromEmail,
+		fromName:     cfg.SendGridFromName,
+		frontendURL:  cfg.FrontendURL,
+		replies:      newReplyAddresses(cfg.InboundEmailDomain, cfg.InboundEmailSecret),
+		fcmBreaker:   resilience.NewCircuitBreaker("fcm", 5, time.Minute),
+		emailBreaker: resilience.NewCircuitBreaker("sendgrid", 5, time.Minute),
+		smsBreaker:   resilience.NewCircuitBreaker("twilio", 5, time.Minute),
+		retryPolicy:  resilience.DefaultPolicy,
 	}
 
 	// Initialize Firebase FCM if credentials provided
 	if cfg.FirebaseCredentials != "" {
-		opt := option.WithCredentialsJSON([]byte(cfg.FirebaseCredentials))
-		app, err := firebase.NewApp(context.Background(), nil, opt)
+		fcmClient, err := newFCMClient(cfg.FirebaseCredentials)
 		if err != nil {
-			log.Printf("Warning: Failed to initialize Firebase: %v", err)
+			log.Printf("Warning: Failed to initialize FCM: %v", err)
 		} else {
-			fcmClient, err := app.Messaging(context.Background())
-			if err != nil {
-				log.Printf("Warning: Failed to initialize FCM: %v", err)
-			} else {
-				service.fcmClient = fcmClient
-				service.fcmEnabled = true
-				log.Println("Firebase Cloud Messaging initialized successfully")
-			}
+			service.fcmClient = fcmClient
+			service.fcmEnabled = true
+			log.Println("Firebase Cloud Messaging initialized successfully")
 		}
 	} else {
 		log.Println("Firebase credentials not configured, push notifications disabled")
@@ -75,12 +100,75 @@ func NewNotificationService(cfg NotificationConfig) *NotificationService {
 		log.Println("SendGrid API key not configured, email notifications disabled")
 	}
 
+	// Initialize Twilio if an account and sending number are provided
+	if cfg.TwilioAccountSID != "" && cfg.TwilioAuthToken != "" && cfg.TwilioFromNumber != "" {
+		service.smsClient = newTwilioClient(cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioFromNumber)
+		service.smsEnabled = true
+		log.Println("Twilio initialized successfully")
+	} else {
+		log.Println("Twilio not configured, urgent SMS disabled")
+	}
+
 	return service
 }
 
+func newFCMClient(credentials string) (*messaging.Client, error) {
+	app, err := firebase.NewApp(context.Background(), nil, option.WithCredentialsJSON([]byte(credentials)))
+	if err != nil {
+		return nil, err
+	}
+	return app.Messaging(context.Background())
+}
+
+// SetFirebaseCredentials switches push notifications to rotated service
+// account credentials, keeping the current client if they don't work
+func (s *NotificationService) SetFirebaseCredentials(credentials string) {
+	if !s.fcmEnabled {
+		log.Println("FIREBASE_CREDENTIALS set; restart to enable push notifications")
+		return
+	}
+	client, err := newFCMClient(credentials)
+	if err != nil {
+		log.Printf("Warning: keeping the current FCM client, rotated credentials failed: %v", err)
+		return
+	}
+	s.clientsMu.Lock()
+	s.fcmClient = client
+	s.clientsMu.Unlock()
+}
+
+// SetSendGridAPIKey switches email to a rotated API key
+func (s *NotificationService) SetSendGridAPIKey(key string) {
+	if !s.emailEnabled {
+		log.Println("SENDGRID_API_KEY set; restart to enable email notifications")
+		return
+	}
+	s.clientsMu.Lock()
+	s.sendGridClient = sendgrid.NewSendClient(key)
+	s.clientsMu.Unlock()
+}
+
+// SetTwilioAuthToken switches SMS to a rotated auth token
+func (s *NotificationService) SetTwilioAuthToken(token string) {
+	if !s.smsEnabled {
+		log.Println("TWILIO_AUTH_TOKEN set; restart to enable urgent SMS")
+		return
+	}
+	s.clientsMu.Lock()
+	s.smsClient = newTwilioClient(s.smsClient.accountSID, token, s.smsClient.from)
+	s.clientsMu.Unlock()
+}
+
+// clients returns the provider clients in use
+func (s *NotificationService) clients() (*messaging.Client, *sendgrid.Client, *twilioClient) {
+	s.clientsMu.RLock()
+	defer s.clientsMu.RUnlock()
+	return s.fcmClient, s.sendGridClient, s.smsClient
+}
+
 // IsEnabled returns true if at least one notification channel is enabled
 func (s *NotificationService) IsEnabled() bool {
-	return s.fcmEnabled || s.emailEnabled
+	return s.fcmEnabled || s.emailEnabled || s.smsEnabled
 }
 
 // SendNotification sends a notification to a single user via configured channels
@@ -98,24 +186,18 @@ func (s *NotificationService) SendNotification(
 	}
 
 	// Get or create notification preferences
-	var prefs models.UserNotificationPreferences
-	result := database.DB.Where("user_id = ?", userID).First(&prefs)
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		// Create default preferences
-		prefs = models.UserNotificationPreferences{UserID: userID}
-		database.DB.Create(&prefs)
-	} else if result.Error != nil {
-		return fmt.Errorf("failed to get preferences: %w", result.Error)
+	prefs, err := s.GetUserPreferences(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get preferences: %w", err)
 	}
 
 	// Create notification record
-	dataJSON, _ := json.Marshal(data)
 	notification := models.Notification{
 		UserID:           userID,
 		NotificationType: notifType,
 		Title:            title,
 		Body:             body,
-		Data:             string(dataJSON),
+		Data:             models.NotificationData(data),
 	}
 
 	if err := database.DB.Create(&notification).Error; err != nil {
@@ -123,34 +205,92 @@ func (s *NotificationService) SendNotification(
 	}
 
 	// Check if push is enabled for this notification type
-	pushEnabled := prefs.IsPushEnabledForType(notifType) && s.fcmEnabled
-	emailEnabled := prefs.IsEmailEnabledForType(notifType) && s.emailEnabled
+	pushEnabled := prefs.IsEnabled(notifType, models.ChannelPush) && s.fcmEnabled
+	emailEnabled := prefs.IsEnabled(notifType, models.ChannelEmail) && s.emailEnabled
+	if emailEnabled && user.Email != "" {
+		// Outside the club's email window, the email waits for the scheduler
+		if hold := emailHoldUntil(notifType); hold != nil {
+			notification.EmailQueuedUntil = hold
+			emailEnabled = false
+		}
+	}
+
+	s.deliver(ctx, &notification, &user, pushEnabled, emailEnabled)
+
+	// Update notification record
+	database.DB.Save(&notification)
+
+	return nil
+}
+
+// deliver sends a notification over the requested channels, recording on it
+// which succeeded and the error from any that failed
+func (s *NotificationService) deliver(ctx context.Context, n *models.Notification, user *models.User, push, email bool) {
+	data := map[string]string(n.Data)
 
 	// Send push notification
-	if pushEnabled {
-		if err := s.sendPushNotification(ctx, userID, title, body, data); err != nil {
-			log.Printf("Failed to send push to user %s: %v", userID, err)
+	if push {
+		if err := s.sendPushNotification(ctx, user.ID, n.Title, n.Body, data); err != nil {
+			log.Printf("Failed to send push to user %s: %v", user.ID, err)
+			n.PushError = err.Error()
 		} else {
 			now := time.Now()
-			notification.PushSent = true
-			notification.PushSentAt = &now
+			n.PushSent = true
+			n.PushSentAt = &now
+			n.PushError = ""
 		}
 	}
 
 	// Send email notification
-	if emailEnabled && user.Email != "" {
-		if err := s.sendEmailNotification(user.Email, user.Name, title, body, notifType); err != nil {
-			log.Printf("Failed to send email to user %s: %v", userID, err)
+	if email && user.Email != "" {
+		replyTo := s.replyAddressFor(user.ID, n.NotificationType, data)
+		if err := s.sendEmailNotification(ctx, user.Email, user.Name, n.Title, n.Body, n.NotificationType, replyTo, user.PreferredLanguage); err != nil {
+			log.Printf("Failed to send email to user %s: %v", user.ID, err)
+			n.EmailError = err.Error()
 		} else {
 			now := time.Now()
-			notification.EmailSent = true
-			notification.EmailSentAt = &now
+			n.EmailSent = true
+			n.EmailSentAt = &now
+			n.EmailError = ""
+			n.EmailQueuedUntil = nil
 		}
 	}
+}
 
-	// Update notification record
-	database.DB.Save(&notification)
+// deliverSMS texts an urgent notification to the member's phone, recording
+// on it whether it went
+func (s *NotificationService) deliverSMS(ctx context.Context, n *models.Notification, user *models.User) {
+	if err := s.sendSMS(ctx, user.PhoneNumber, n.Title, n.Body); err != nil {
+		log.Printf("Failed to send SMS to user %s: %v", user.ID, err)
+		n.SMSError = err.Error()
+		return
+	}
+	now := time.Now()
+	n.SMSSent = true
+	n.SMSSentAt = &now
+	n.SMSError = ""
+}
+
+// sendSMS texts a notification to a phone number
+func (s *NotificationService) sendSMS(ctx context.Context, phone, title, body string) error {
+	if !s.smsEnabled {
+		return errors.New("SMS not enabled")
+	}
+	to, err := smsNumber(phone)
+	if err != nil {
+		return err
+	}
+
+	_, _, smsClient := s.clients()
+	err = resilience.Do(ctx, s.retryPolicy, s.smsBreaker, func(ctx context.Context) error {
+		return smsClient.send(ctx, to, smsText(title, body))
+	})
+	if err != nil {
+		return err
+	}
 
+	// The number stays out of the log, as it's encrypted at rest
+	log.Printf("SMS sent: %s", title)
 	return nil
 }
 
@@ -171,6 +311,21 @@ func (s *NotificationService) sendPushNotification(
 		return err
 	}
 
+	return s.sendPushToTokens(ctx, userID, tokens, title, body, data)
+}
+
+// sendPushToTokens sends a push notification to the given devices of a user
+func (s *NotificationService) sendPushToTokens(
+	ctx context.Context,
+	userID uuid.UUID,
+	tokens []models.UserPushToken,
+	title, body string,
+	data map[string]string,
+) error {
+	if !s.fcmEnabled {
+		return errors.New("FCM not enabled")
+	}
+
 	if len(tokens) == 0 {
 		return nil // No tokens, nothing to send
 	}
@@ -191,13 +346,20 @@ func (s *NotificationService) sendPushNotification(
 		Data: data,
 		Webpush: &messaging.WebpushConfig{
 			Notification: &messaging.WebpushNotification{
-				Icon: "/icons/icon-192x192.png",
+				Icon:    "/icons/icon-192x192.png",
+				Actions: pushActions(data),
 			},
 		},
 	}
 
 	// Send
-	response, err := s.fcmClient.SendEachForMulticast(ctx, message)
+	var response *messaging.BatchResponse
+	fcmClient, _, _ := s.clients()
+	err := resilience.Do(ctx, s.retryPolicy, s.fcmBreaker, func(ctx context.Context) error {
+		var err error
+		response, err = fcmClient.SendEachForMulticast(ctx, message)
+		return err
+	})
 	if err != nil {
 		return err
 	}
@@ -216,8 +378,24 @@ func (s *NotificationService) sendPushNotification(
 	return nil
 }
 
-// sendEmailNotification sends an email notification
-func (s *NotificationService) sendEmailNotification(toEmail, toName, subject, body string, notifType models.NotificationType) error {
+// pushActions decodes the buttons a notification offers, given in its data
+// as a JSON array of {action, title} under "actions"
+func pushActions(data map[string]string) []*messaging.WebpushNotificationAction {
+	if data["actions"] == "" {
+		return nil
+	}
+	var actions []*messaging.WebpushNotificationAction
+	if err := json.Unmarshal([]byte(data["actions"]), &actions); err != nil {
+		log.Printf("Ignoring invalid push actions %q: %v", data["actions"], err)
+		return nil
+	}
+	return actions
+}
+
+// sendEmailNotification sends an email notification, with the text around
+// the message in lang. With a replyTo address, the member can RSVP by
+// answering it.
+func (s *NotificationService) sendEmailNotification(ctx context.Context, toEmail, toName, subject, body string, notifType models.NotificationType, replyTo string, lang models.Language) error {
 	if !s.emailEnabled {
 		return errors.New("email not enabled")
 	}
@@ -225,26 +403,67 @@ func (s *NotificationService) sendEmailNotification(toEmail, toName, subject, bo
 	from := mail.NewEmail(s.fromName, s.fromEmail)
 	to := mail.NewEmail(toName, toEmail)
 
+	wording := emailWordingFor(lang)
+	if replyTo != "" {
+		body += " " + wording.replyHint
+	}
+
 	// Build HTML email
-	htmlContent := s.buildEmailHTML(subject, body, notifType)
+	htmlContent := s.buildEmailHTML(subject, body, notifType, lang)
 
 	message := mail.NewSingleEmail(from, subject, to, body, htmlContent)
+	if replyTo != "" {
+		message.SetReplyTo(mail.NewEmail(s.fromName, replyTo))
+	}
 
-	response, err := s.sendGridClient.Send(message)
+	_, sendGridClient, _ := s.clients()
+	err := resilience.Do(ctx, s.retryPolicy, s.emailBreaker, func(ctx context.Context) error {
+		response, err := sendGridClient.SendWithContext(ctx, message)
+		if err != nil {
+			return err
+		}
+		if response.StatusCode >= 400 {
+			err = fmt.Errorf("SendGrid returned status %d: %s", response.StatusCode, response.Body)
+			// Client errors other than rate limiting won't succeed on retry
+			if response.StatusCode < 500 && response.StatusCode != 429 {
+				return resilience.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	if response.StatusCode >= 400 {
-		return fmt.Errorf("SendGrid returned status %d: %s", response.StatusCode, response.Body)
-	}
-
 	log.Printf("Email sent to %s: %s", toEmail, subject)
 	return nil
 }
 
-// buildEmailHTML creates a styled HTML email
-func (s *NotificationService) buildEmailHTML(subject, body string, notifType models.NotificationType) string {
+// SessionURL returns a session's page in the app
+func (s *NotificationService) SessionURL(sessionID uuid.UUID) string {
+	return s.frontendURL + "/sessions/" + sessionID.String()
+}
+
+// SendAccountLinkCode emails a member the code that lets a new login claim
+// their account. It goes straight to email, skipping preferences and history,
+// since the code must only reach the address on the account.
+func (s *NotificationService) SendAccountLinkCode(ctx context.Context, toEmail, toName, code string, lang models.Language) error {
+	subject := "Your account link code"
+	body := fmt.Sprintf("Someone signed in to Weekday Masters with a new login and asked to link it to your account. "+
+		"If that was you, enter this code: %s. It expires in %d minutes. If it wasn't you, ignore this email and your account stays as it is.",
+		code, int(accountLinkCodeTTL.Minutes()))
+	if lang == models.LanguageChinese {
+		subject = "你的账户关联验证码"
+		body = fmt.Sprintf("有人用新的登录方式登录了 Weekday Masters，并请求关联到你的账户。"+
+			"如果是你本人，请输入验证码：%s，%d 分钟内有效。如果不是你，请忽略这封邮件，你的账户不会有任何变化。",
+			code, int(accountLinkCodeTTL.Minutes()))
+	}
+	return s.sendEmailNotification(ctx, toEmail, toName, subject, body, models.NotificationAccountLink, "", lang)
+}
+
+// buildEmailHTML creates a styled HTML email with its fixed text in lang
+func (s *NotificationService) buildEmailHTML(subject, body string, notifType models.NotificationType, lang models.Language) string {
 	// Icon based on notification type
 	iconEmoji := "🏸"
 	switch notifType {
@@ -256,11 +475,32 @@ func (s *NotificationService) buildEmailHTML(subject, body string, notifType mod
 		iconEmoji = "🎉"
 	case models.NotificationAdminAnnouncement:
 		iconEmoji = "📢"
+	case models.NotificationBadgeAwarded:
+		iconEmoji = "🏅"
+	case models.NotificationRSVPChanged:
+		iconEmoji = "📝"
+	case models.NotificationIncidentReported:
+		iconEmoji = "🚑"
+	case models.NotificationSessionChanged:
+		iconEmoji = "🔄"
+	case models.NotificationAccountLink:
+		iconEmoji = "🔗"
+	case models.NotificationRSVPSummary:
+		iconEmoji = "📋"
+	case models.NotificationMaybeNudge:
+		iconEmoji = "🤔"
+	case models.NotificationMonthlyRecap:
+		iconEmoji = "📊"
+	case models.NotificationWeatherProposal:
+		iconEmoji = "⛈️"
+	case models.NotificationContactFollowUp:
+		iconEmoji = "📞"
 	}
 
+	wording := emailWordingFor(lang)
 	return fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
@@ -274,16 +514,17 @@ func (s *NotificationService) buildEmailHTML(subject, body string, notifType mod
         <h2 style="color: #1e293b; margin-top: 0;">%s</h2>
         <p style="color: #475569; font-size: 16px; line-height: 1.6;">%s</p>
         <div style="text-align: center; margin-top: 24px;">
-            <a href="%s/dashboard" style="display: inline-block; background-color: #0891b2; color: white; padding: 12px 24px; text-decoration: none; border-radius: 8px; font-weight: 600;">View Dashboard</a>
+            <a href="%s/dashboard" style="display: inline-block; background-color: #0891b2; color: white; padding: 12px 24px; text-decoration: none; border-radius: 8px; font-weight: 600;">%s</a>
         </div>
     </div>
     <div style="background-color: #f1f5f9; padding: 16px; text-align: center; font-size: 12px; color: #64748b;">
-        <p style="margin: 0 0 8px 0;">You received this email because you have notifications enabled for Weekday Masters.</p>
-        <p style="margin: 0;"><a href="%s/profile" style="color: #0891b2;">Manage your notification preferences</a></p>
+        <p style="margin: 0 0 8px 0;">%s</p>
+        <p style="margin: 0;"><a href="%s/profile" style="color: #0891b2;">%s</a></p>
     </div>
 </body>
 </html>
-`, iconEmoji, subject, body, s.frontendURL, s.frontendURL)
+`, emailLanguageTag(lang), iconEmoji, subject, body, s.frontendURL, wording.viewDashboard,
+		wording.reason, s.frontendURL, wording.managePreferences)
 }
 
 // SendBulkNotification sends notifications to multiple users
@@ -294,46 +535,17 @@ func (s *NotificationService) SendBulkNotification(
 	title, body string,
 	data map[string]string,
 ) {
-	for _, userID := range userIDs {
-		// Send in goroutine for parallelism
-		go func(uid uuid.UUID) {
-			if err := s.SendNotification(ctx, uid, notifType, title, body, data); err != nil {
-				log.Printf("Failed to send notification to user %s: %v", uid, err)
-			}
-		}(userID)
+	messages := make([]NotificationMessage, len(userIDs))
+	for i, userID := range userIDs {
+		messages[i] = NotificationMessage{UserID: userID, Title: title, Body: body, Data: data}
 	}
-}
 
-// GetUserPreferences retrieves notification preferences for a user
-func (s *NotificationService) GetUserPreferences(userID uuid.UUID) (*models.UserNotificationPreferences, error) {
-	var prefs models.UserNotificationPreferences
-	result := database.DB.Where("user_id = ?", userID).First(&prefs)
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		// Create default preferences
-		prefs = models.UserNotificationPreferences{UserID: userID}
-		if err := database.DB.Create(&prefs).Error; err != nil {
-			return nil, err
+	// Send in the background so callers aren't held up by provider latency
+	go func() {
+		if _, err := s.SendBatch(ctx, notifType, messages); err != nil {
+			log.Printf("Failed to send bulk notification: %v", err)
 		}
-	} else if result.Error != nil {
-		return nil, result.Error
-	}
-	return &prefs, nil
-}
-
-// UpdateUserPreferences updates notification preferences for a user
-func (s *NotificationService) UpdateUserPreferences(userID uuid.UUID, updates map[string]interface{}) (*models.UserNotificationPreferences, error) {
-	prefs, err := s.GetUserPreferences(userID)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := database.DB.Model(prefs).Updates(updates).Error; err != nil {
-		return nil, err
-	}
-
-	// Reload to get updated values
-	database.DB.First(prefs, "id = ?", prefs.ID)
-	return prefs, nil
+	}()
 }
 
 // RegisterPushToken registers a new FCM push token for a user
@@ -372,10 +584,86 @@ func (s *NotificationService) UnregisterPushToken(userID uuid.UUID, token string
 	return database.DB.Where("user_id = ?", userID).Delete(&models.UserPushToken{}).Error
 }
 
-// GetUserNotifications retrieves notification history for a user
-func (s *NotificationService) GetUserNotifications(userID uuid.UUID, limit, offset int) ([]models.Notification, error) {
+// stalePushTokenAge is how long a token can go without being re-registered
+// before it's dropped; FCM treats tokens unrefreshed for a month or two as stale
+const stalePushTokenAge = 60 * 24 * time.Hour
+
+// cleanupStalePushTokens deletes push tokens that haven't been re-registered
+// within stalePushTokenAge, only recording them in report on a dry run
+func (s *NotificationService) cleanupStalePushTokens(report *JobReport) error {
+	var tokens []models.UserPushToken
+	if err := database.DB.Where("last_used_at < ?", time.Now().Add(-stalePushTokenAge)).
+		Find(&tokens).Error; err != nil {
+		return fmt.Errorf("fetching stale push tokens: %w", err)
+	}
+
+	for _, t := range tokens {
+		userID := t.UserID
+		report.add(JobAction{
+			Kind:   "delete_push_token",
+			UserID: &userID,
+			Title:  t.DeviceName,
+			Detail: "Last registered " + t.LastUsedAt.Format(time.RFC3339),
+		})
+	}
+	if report.isDryRun() || len(tokens) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(tokens))
+	for i, t := range tokens {
+		ids[i] = t.ID
+	}
+	if err := database.DB.Where("id IN ?", ids).Delete(&models.UserPushToken{}).Error; err != nil {
+		return fmt.Errorf("deleting stale push tokens: %w", err)
+	}
+	log.Printf("Deleted %d stale push tokens", len(tokens))
+	return nil
+}
+
+// NotificationFilter narrows a user's notification history; zero values don't filter
+type NotificationFilter struct {
+	SessionID *uuid.UUID
+	Type      *models.NotificationType
+	Read      *bool
+	From      *time.Time // inclusive
+	To        *time.Time // inclusive of the whole day
+	Search    string     // full-text search over title and body
+}
+
+// GetUserNotifications retrieves notification history for a user, newest
+// first, or best match first when searching
+func (s *NotificationService) GetUserNotifications(userID uuid.UUID, filter NotificationFilter, limit, offset int) ([]models.Notification, error) {
 	var notifications []models.Notification
-	query := database.DB.Where("user_id = ?", userID).Order("created_at DESC")
+	query := database.DB.Where("user_id = ?", userID)
+
+	if filter.SessionID != nil {
+		// Containment query so the GIN index on data is used
+		query = query.Where("data @> ?", models.NotificationData{"session_id": filter.SessionID.String()})
+	}
+	if filter.Type != nil {
+		query = query.Where("notification_type = ?", *filter.Type)
+	}
+	if filter.Read != nil {
+		if *filter.Read {
+			query = query.Where("read_at IS NOT NULL")
+		} else {
+			query = query.Where("read_at IS NULL")
+		}
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", filter.To.AddDate(0, 0, 1))
+	}
+
+	if filter.Search != "" {
+		// search_vector is a generated column maintained by Postgres, see database.Migrate
+		query = query.Where("search_vector @@ websearch_to_tsquery('english', ?)", filter.Search).
+			Order(clause.Expr{SQL: "ts_rank(search_vector, websearch_to_tsquery('english', ?)) DESC", Vars: []interface{}{filter.Search}})
+	}
+	query = query.Order("created_at DESC")
 
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -390,6 +678,15 @@ func (s *NotificationService) GetUserNotifications(userID uuid.UUID, limit, offs
 	return notifications, nil
 }
 
+// CountUnread counts a user's notifications they haven't read
+func (s *NotificationService) CountUnread(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
 // MarkNotificationRead marks a notification as read
 func (s *NotificationService) MarkNotificationRead(notificationID, userID uuid.UUID) error {
 	now := time.Now()
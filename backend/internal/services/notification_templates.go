@@ -0,0 +1,693 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// maxReminderAttendees caps how many names are listed in a reminder
+const maxReminderAttendees = 8
+
+// Limits on the club's own message wording
+const (
+	maxMessageTitleLength = 200
+	maxMessageBodyLength  = 2000
+)
+
+var (
+	ErrUnknownMessage      = domainError(ErrNotFound, "unknown_message", "unknown message")
+	ErrUnsupportedLanguage = domainError(ErrInvalid, "unsupported_language", "unsupported language")
+)
+
+// SessionReminderContext is what a single recipient's session reminder is built from
+type SessionReminderContext struct {
+	Session          models.Session
+	Date             string
+	Label            string
+	WaitlistPosition int // 0 when the recipient has a confirmed spot
+	ConfirmedCount   int
+	Attendees        []string // other confirmed players; empty unless the recipient opted in
+	MoreAttendees    int      // confirmed players not named in Attendees
+	Weather          string   // forecast for outdoor sessions, if available
+	Attachments      []string // names of the files attached to the session
+	SessionURL       string   // the session in the app, where members open the files
+}
+
+// DeadlineReminderContext is what an RSVP deadline reminder is built from
+type DeadlineReminderContext struct {
+	Session  models.Session
+	Date     string
+	Deadline string // e.g. "Tuesday 6:00 PM"
+}
+
+// RSVPSummaryContext is what the summary admins get as a session's RSVPs
+// close is built from. Names are in the order spots were allocated.
+type RSVPSummaryContext struct {
+	Session          models.Session
+	Date             string
+	Confirmed        []string
+	Waitlist         []string
+	AwaitingApproval []string // IN RSVPs an admin hasn't approved yet
+	Warnings         []string // shortfalls, e.g. too few players for the courts
+	SessionURL       string   // the session in the app
+}
+
+// MaybeNudgeContext is what the nudge to members still on MAYBE is built from
+type MaybeNudgeContext struct {
+	Session  models.Session
+	Date     string
+	Deadline string    // e.g. "Tuesday 6:00 PM"
+	Expires  bool      // whether MAYBE turns into OUT at Cutoff
+	Cutoff   time.Time // when MAYBE turns into OUT
+}
+
+// MaybeExpiredContext is what the notice that a MAYBE was changed to OUT is
+// built from
+type MaybeExpiredContext struct {
+	Session models.Session
+	Date    string
+}
+
+// MonthlyRecapContext is what a member's recap of last month is built from
+type MonthlyRecapContext struct {
+	MonthStart     time.Time
+	SessionsPlayed int
+	GamesPlayed    int
+	GamesWon       int
+	CurrentStreak  int      // consecutive weeks played, at the end of the month
+	LongestStreak  int      // ever
+	Badges         []string // earned during the month
+}
+
+// WaitlistUpdateContext is what the offer of a spot off the waitlist is built from
+type WaitlistUpdateContext struct {
+	Session  models.Session
+	Date     string
+	Deadline string // when the offer lapses, e.g. "Tuesday 6:00 PM"
+}
+
+// messageWording is a message's title and body in one language
+type messageWording struct {
+	title string
+	body  string
+}
+
+// messageDefinition is a message the club can reword: its built-in wording,
+// the placeholders it can use, and sample data to validate and preview with
+type messageDefinition struct {
+	description string
+	title       string // in models.DefaultLanguage
+	body        string
+
+	// translations is the built-in wording in other languages
+	translations map[models.Language]messageWording
+
+	placeholders []string
+	sample       func() interface{}
+}
+
+// wording returns the built-in wording in lang, and whether it's translated
+func (d messageDefinition) wording(lang models.Language) (messageWording, bool) {
+	if lang == models.DefaultLanguage {
+		return messageWording{title: d.title, body: d.body}, true
+	}
+	w, ok := d.translations[lang]
+	return w, ok
+}
+
+var messageCatalog = map[models.MessageTemplateKey]messageDefinition{
+	models.MessageSessionReminder: {
+		description: "Sent to everyone who RSVP'd, ahead of each session",
+		title:       `Session Reminder ({{.Label}})`,
+		body: `Don't forget! {{.Session.Title}} is on {{.Date}} at {{clock .Session.StartsAt}}.` +
+			`{{if .WaitlistPosition}} You're #{{.WaitlistPosition}} on the waitlist.{{else}} You're confirmed.{{end}}` +
+			` {{.ConfirmedCount}}/{{.Session.MaxPlayers}} players confirmed.` +
+			`{{if .Attendees}} Coming: {{join .Attendees ", "}}{{if .MoreAttendees}} and {{.MoreAttendees}} more{{end}}.{{end}}` +
+			`{{if .Weather}} Forecast: {{.Weather}}.{{end}}` +
+			`{{if .Attachments}} Files: {{join .Attachments ", "}}, at {{.SessionURL}}{{end}}`,
+		translations: map[models.Language]messageWording{
+			models.LanguageChinese: {
+				title: `活动提醒（{{.Label}}）`,
+				body: `别忘了！{{.Session.Title}} 将于 {{date .Session.SessionDate}} {{clock .Session.StartsAt}} 开始。` +
+					`{{if .WaitlistPosition}}你在候补名单第 {{.WaitlistPosition}} 位。{{else}}你的名额已确认。{{end}}` +
+					`已确认 {{.ConfirmedCount}}/{{.Session.MaxPlayers}} 人。` +
+					`{{if .Attendees}}一起参加的有：{{join .Attendees "、"}}{{if .MoreAttendees}} 等 {{.MoreAttendees}} 人{{end}}。{{end}}` +
+					`{{if .Weather}}天气预报：{{.Weather}}。{{end}}` +
+					`{{if .Attachments}}附件：{{join .Attachments "、"}}，可在 {{.SessionURL}} 查看。{{end}}`,
+			},
+		},
+		placeholders: []string{"{{.Session.Title}}", "{{.Session.Courts}}", "{{.Session.MaxPlayers}}",
+			"{{clock .Session.StartsAt}}", "{{.Date}}", "{{date .Session.SessionDate}}", "{{.Label}}", "{{.WaitlistPosition}}",
+			"{{.ConfirmedCount}}", `{{join .Attendees ", "}}`, "{{.MoreAttendees}}", "{{.Weather}}",
+			`{{join .Attachments ", "}}`, "{{.SessionURL}}"},
+		sample: func() interface{} {
+			session := sampleMessageSession()
+			return SessionReminderContext{
+				Session:        session,
+				Date:           utils.FormatDateForDisplay(session.SessionDate),
+				Label:          "24h",
+				ConfirmedCount: 12,
+				Attendees:      []string{"Alex", "Sam", "Priya"},
+				MoreAttendees:  9,
+				Weather:        "Partly cloudy, 18°C",
+				Attachments:    []string{"court-map.pdf"},
+				SessionURL:     "https://app.example.com/sessions/" + session.ID.String(),
+			}
+		},
+	},
+	models.MessageRSVPDeadline: {
+		description: "Sent to members who haven't RSVP'd as a session's RSVP deadline nears",
+		title:       `RSVP Deadline Approaching`,
+		body:        `The RSVP deadline for {{.Session.Title}} ({{.Date}}) is {{.Deadline}}. Don't miss out!`,
+		translations: map[models.Language]messageWording{
+			models.LanguageChinese: {
+				title: `报名即将截止`,
+				body: `{{.Session.Title}}（{{date .Session.SessionDate}}）的报名将于 ` +
+					`{{date .Session.RSVPDeadline}} {{clock .Session.RSVPDeadline}} 截止，别错过！`,
+			},
+		},
+		placeholders: []string{"{{.Session.Title}}", "{{.Session.Courts}}", "{{clock .Session.StartsAt}}", "{{.Date}}",
+			"{{date .Session.SessionDate}}", "{{.Deadline}}", "{{clock .Session.RSVPDeadline}}"},
+		sample: func() interface{} {
+			session := sampleMessageSession()
+			return DeadlineReminderContext{
+				Session:  session,
+				Date:     utils.FormatDateForDisplay(session.SessionDate),
+				Deadline: session.RSVPDeadline.In(utils.SydneyLocation).Format("Monday 3:04 PM"),
+			}
+		},
+	},
+	models.MessageWaitlistUpdate: {
+		description: "Sent to the next member on a session's waitlist when a spot opens up, to confirm it",
+		title:       `Spot Available!`,
+		body:        `A spot has opened up for {{.Session.Title}} on {{.Date}} and it's yours if you confirm by {{.Deadline}}. After that it goes to the next person on the waitlist.`,
+		translations: map[models.Language]messageWording{
+			models.LanguageChinese: {
+				title: `有空位了！`,
+				body:  `{{.Session.Title}}（{{date .Session.SessionDate}}）空出了名额，请在 {{.Deadline}} 前确认，逾期名额将让给候补名单上的下一位。`,
+			},
+		},
+		placeholders: []string{"{{.Session.Title}}", "{{.Session.Courts}}", "{{clock .Session.StartsAt}}", "{{.Date}}",
+			"{{date .Session.SessionDate}}", "{{.Deadline}}"},
+		sample: func() interface{} {
+			session := sampleMessageSession()
+			return WaitlistUpdateContext{
+				Session:  session,
+				Date:     utils.FormatDateForDisplay(session.SessionDate),
+				Deadline: session.StartsAt.Add(-22 * time.Hour).In(utils.SydneyLocation).Format("Monday 3:04 PM"),
+			}
+		},
+	},
+	models.MessageRSVPSummary: {
+		description: "Sent to admins, and optionally the organizer, when a session's RSVP deadline passes",
+		title:       `RSVPs closed: {{.Session.Title}}`,
+		body: `RSVPs for {{.Session.Title}} on {{.Date}} at {{clock .Session.StartsAt}} have closed.` +
+			` Confirmed ({{len .Confirmed}}/{{.Session.MaxPlayers}}): {{if .Confirmed}}{{join .Confirmed ", "}}{{else}}nobody{{end}}.` +
+			`{{if .Waitlist}} Waitlist: {{join .Waitlist ", "}}.{{end}}` +
+			`{{if .AwaitingApproval}} Awaiting approval: {{join .AwaitingApproval ", "}}.{{end}}` +
+			`{{if .Warnings}} Heads up: {{join .Warnings "; "}}.{{end}}` +
+			` Details: {{.SessionURL}}`,
+		translations: map[models.Language]messageWording{
+			models.LanguageChinese: {
+				title: `报名已截止：{{.Session.Title}}`,
+				body: `{{.Session.Title}}（{{date .Session.SessionDate}} {{clock .Session.StartsAt}}）的报名已截止。` +
+					`已确认（{{len .Confirmed}}/{{.Session.MaxPlayers}}）：{{if .Confirmed}}{{join .Confirmed "、"}}{{else}}无{{end}}。` +
+					`{{if .Waitlist}}候补：{{join .Waitlist "、"}}。{{end}}` +
+					`{{if .AwaitingApproval}}待审批：{{join .AwaitingApproval "、"}}。{{end}}` +
+					`{{if .Warnings}}请注意：{{join .Warnings "；"}}。{{end}}` +
+					`详情：{{.SessionURL}}`,
+			},
+		},
+		placeholders: []string{"{{.Session.Title}}", "{{.Session.Courts}}", "{{.Session.MaxPlayers}}",
+			"{{clock .Session.StartsAt}}", "{{.Date}}", "{{date .Session.SessionDate}}", "{{len .Confirmed}}", `{{join .Confirmed ", "}}`,
+			`{{join .Waitlist ", "}}`, `{{join .AwaitingApproval ", "}}`, `{{join .Warnings "; "}}`, "{{.SessionURL}}"},
+		sample: func() interface{} {
+			session := sampleMessageSession()
+			return RSVPSummaryContext{
+				Session:    session,
+				Date:       utils.FormatDateForDisplay(session.SessionDate),
+				Confirmed:  []string{"Alex", "Sam", "Priya", "Jordan", "Mei", "Tom", "Lena", "Raj", "Chris", "Ana"},
+				Waitlist:   []string{"Noor"},
+				Warnings:   []string{"10 players for 3 courts (4 per court)"},
+				SessionURL: "https://app.example.com/sessions/" + session.ID.String(),
+			}
+		},
+	},
+	models.MessageMaybeNudge: {
+		description: "Sent to members still on maybe ahead of a session's RSVP deadline",
+		title:       `Still a maybe for {{.Session.Title}}?`,
+		body: `You're down as maybe for {{.Session.Title}} on {{.Date}}. Please RSVP IN or OUT by {{.Deadline}}` +
+			` so we can plan the courts.{{if .Expires}} Maybes still open at {{clock .Cutoff}} on {{date .Cutoff}} will be changed to OUT.{{end}}`,
+		translations: map[models.Language]messageWording{
+			models.LanguageChinese: {
+				title: `{{.Session.Title}} 还在考虑吗？`,
+				body: `你在 {{.Session.Title}}（{{date .Session.SessionDate}}）的报名是“可能”。请在 ` +
+					`{{date .Session.RSVPDeadline}} {{clock .Session.RSVPDeadline}} 前确认参加或不参加，方便安排场地。` +
+					`{{if .Expires}}到 {{date .Cutoff}} {{clock .Cutoff}} 仍为“可能”的报名将改为不参加。{{end}}`,
+			},
+		},
+		placeholders: []string{"{{.Session.Title}}", "{{.Session.Courts}}", "{{clock .Session.StartsAt}}", "{{.Date}}",
+			"{{date .Session.SessionDate}}", "{{.Deadline}}", "{{clock .Session.RSVPDeadline}}", "{{clock .Cutoff}}", "{{date .Cutoff}}"},
+		sample: func() interface{} {
+			session := sampleMessageSession()
+			return MaybeNudgeContext{
+				Session:  session,
+				Date:     utils.FormatDateForDisplay(session.SessionDate),
+				Deadline: session.RSVPDeadline.In(utils.SydneyLocation).Format("Monday 3:04 PM"),
+				Expires:  true,
+				Cutoff:   session.RSVPDeadline.Add(-2 * time.Hour),
+			}
+		},
+	},
+	models.MessageMaybeExpired: {
+		description: "Sent to members whose maybe was changed to out at the club's cutoff",
+		title:       `You're now OUT for {{.Session.Title}}`,
+		body:        `Your maybe for {{.Session.Title}} on {{.Date}} has been changed to OUT. If you can make it after all, RSVP IN while spots remain.`,
+		translations: map[models.Language]messageWording{
+			models.LanguageChinese: {
+				title: `{{.Session.Title}} 已改为不参加`,
+				body:  `你在 {{.Session.Title}}（{{date .Session.SessionDate}}）的“可能”已改为不参加。如果还能来，请趁还有名额时重新报名。`,
+			},
+		},
+		placeholders: []string{"{{.Session.Title}}", "{{.Session.Courts}}", "{{clock .Session.StartsAt}}", "{{.Date}}",
+			"{{date .Session.SessionDate}}"},
+		sample: func() interface{} {
+			session := sampleMessageSession()
+			return MaybeExpiredContext{Session: session, Date: utils.FormatDateForDisplay(session.SessionDate)}
+		},
+	},
+	models.MessageMonthlyRecap: {
+		description: "Sent at the start of each month to members who played in the month before",
+		title:       `Your {{.MonthStart.Format "January"}} on court`,
+		body: `You played {{.SessionsPlayed}} sessions in {{.MonthStart.Format "January"}}` +
+			`{{if .GamesPlayed}} and won {{.GamesWon}} of {{.GamesPlayed}} games{{end}}.` +
+			`{{if .CurrentStreak}} You're on a {{.CurrentStreak}}-week streak (best: {{.LongestStreak}}).{{end}}` +
+			`{{if .Badges}} New badges: {{join .Badges ", "}}.{{end}}`,
+		translations: map[models.Language]messageWording{
+			models.LanguageChinese: {
+				title: `你的{{printf "%d" .MonthStart.Month}}月球场回顾`,
+				body: `你在{{printf "%d" .MonthStart.Month}}月参加了 {{.SessionsPlayed}} 次活动` +
+					`{{if .GamesPlayed}}，{{.GamesPlayed}} 场比赛中赢了 {{.GamesWon}} 场{{end}}。` +
+					`{{if .CurrentStreak}}你已连续 {{.CurrentStreak}} 周参加（最长 {{.LongestStreak}} 周）。{{end}}` +
+					`{{if .Badges}}新徽章：{{join .Badges "、"}}。{{end}}`,
+			},
+		},
+		placeholders: []string{`{{.MonthStart.Format "January"}}`, `{{printf "%d" .MonthStart.Month}}`, "{{.SessionsPlayed}}",
+			"{{.GamesPlayed}}", "{{.GamesWon}}", "{{.CurrentStreak}}", "{{.LongestStreak}}", `{{join .Badges ", "}}`},
+		sample: func() interface{} {
+			return MonthlyRecapContext{
+				MonthStart:     utils.WallClock(2025, time.March, 1, 0, 0, 0),
+				SessionsPlayed: 6,
+				GamesPlayed:    14,
+				GamesWon:       8,
+				CurrentStreak:  5,
+				LongestStreak:  9,
+				Badges:         []string{models.BadgeSessions50.DisplayName()},
+			}
+		},
+	},
+}
+
+// messageKeys lists the catalog in a stable order
+var messageKeys = []models.MessageTemplateKey{
+	models.MessageSessionReminder,
+	models.MessageRSVPDeadline,
+	models.MessageWaitlistUpdate,
+	models.MessageRSVPSummary,
+	models.MessageMaybeNudge,
+	models.MessageMaybeExpired,
+	models.MessageMonthlyRecap,
+}
+
+// sampleMessageSession is the session messages are validated and previewed against
+func sampleMessageSession() models.Session {
+	startsAt := utils.WallClock(2025, time.March, 5, 19, 0, 0)
+	return models.Session{
+		Title:        "Wednesday Night Badminton",
+		SessionDate:  utils.StartOfDay(startsAt),
+		StartsAt:     startsAt,
+		EndsAt:       startsAt.Add(2 * time.Hour),
+		Courts:       3,
+		MaxPlayers:   16,
+		RSVPDeadline: startsAt.Add(-24 * time.Hour),
+	}
+}
+
+// compiledMessage is a message's title and body in one language, ready to
+// render for each recipient, with the wording to fall back to if it fails
+type compiledMessage struct {
+	key      models.MessageTemplateKey
+	language models.Language
+	source   MessagePreview // the wording as written
+	title    *template.Template
+	body     *template.Template
+	fallback *compiledMessage
+}
+
+func compileMessage(key models.MessageTemplateKey, lang models.Language, title, body string) (*compiledMessage, error) {
+	funcs := templateFuncs(lang)
+	t, err := template.New(string(key) + "_title").Funcs(funcs).Option("missingkey=error").Parse(title)
+	if err != nil {
+		return nil, fmt.Errorf("title: %w", err)
+	}
+	b, err := template.New(string(key) + "_body").Funcs(funcs).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+	return &compiledMessage{key: key, language: lang, source: MessagePreview{Title: title, Body: body}, title: t, body: b}, nil
+}
+
+// render builds the title and body for one recipient
+func (m *compiledMessage) render(data interface{}) (title, body string, err error) {
+	var t, b strings.Builder
+	if err = m.title.Execute(&t, data); err == nil {
+		err = m.body.Execute(&b, data)
+	}
+	if err != nil {
+		if m.fallback != nil {
+			log.Printf("Error rendering %s wording for %s, falling back: %v", m.language, m.key, err)
+			return m.fallback.render(data)
+		}
+		return "", "", err
+	}
+	return strings.TrimSpace(t.String()), strings.TrimSpace(b.String()), nil
+}
+
+// messageSet is a message in every supported language
+type messageSet struct {
+	key        models.MessageTemplateKey
+	byLanguage map[models.Language]*compiledMessage
+}
+
+// loadMessage returns a message in every language. Each language uses the
+// club's wording, then the built-in translation, then the default language's
+// club and built-in wording, moving on when one is missing, can't be loaded or
+// fails to render.
+func loadMessage(key models.MessageTemplateKey) *messageSet {
+	def := messageCatalog[key]
+
+	var rows []models.MessageTemplate
+	if err := database.DB.Where("key = ?", key).Find(&rows).Error; err != nil {
+		log.Printf("Error loading club wording for %s, using the default: %v", key, err)
+	}
+	custom := make(map[models.Language]models.MessageTemplate, len(rows))
+	for _, m := range rows {
+		custom[m.Language] = m
+	}
+
+	// chain puts lang's club wording and built-in translation in front of next
+	chain := func(lang models.Language, next *compiledMessage) *compiledMessage {
+		if w, ok := def.wording(lang); ok {
+			builtIn, err := compileMessage(key, lang, w.title, w.body)
+			if err != nil {
+				panic(fmt.Sprintf("default %s message in %s: %v", key, lang, err))
+			}
+			builtIn.fallback = next
+			next = builtIn
+		}
+		if m, ok := custom[lang]; ok {
+			msg, err := compileMessage(key, lang, m.Title, m.Body)
+			if err != nil {
+				log.Printf("Club wording for %s in %s doesn't compile, falling back: %v", key, lang, err)
+			} else {
+				msg.fallback = next
+				next = msg
+			}
+		}
+		return next
+	}
+
+	set := &messageSet{key: key, byLanguage: make(map[models.Language]*compiledMessage, len(models.SupportedLanguages))}
+	fallback := chain(models.DefaultLanguage, nil)
+	for _, lang := range models.SupportedLanguages {
+		if lang == models.DefaultLanguage {
+			set.byLanguage[lang] = fallback
+		} else if msg := chain(lang, fallback); msg != fallback {
+			set.byLanguage[lang] = msg
+		}
+	}
+	return set
+}
+
+// forLanguage returns the wording to use for lang
+func (m *messageSet) forLanguage(lang models.Language) *compiledMessage {
+	for _, l := range lang.Fallbacks() {
+		if msg := m.byLanguage[l]; msg != nil {
+			return msg
+		}
+	}
+	return m.byLanguage[models.DefaultLanguage]
+}
+
+// render builds the title and body for one recipient who reads lang
+func (m *messageSet) render(lang models.Language, data interface{}) (title, body string, err error) {
+	return m.forLanguage(lang).render(data)
+}
+
+// messagesFor addresses the message to each recipient in their language,
+// rendering it once per language with the data built for that language
+func (m *messageSet) messagesFor(recipients []uuid.UUID, data func(models.Language) interface{}, payload map[string]string) ([]NotificationMessage, error) {
+	languages := recipientLanguages(recipients)
+
+	type rendered struct{ title, body string }
+	byLanguage := make(map[*compiledMessage]rendered)
+	messages := make([]NotificationMessage, 0, len(recipients))
+	for _, id := range recipients {
+		lang := languages[id]
+		msg := m.forLanguage(lang)
+		r, ok := byLanguage[msg]
+		if !ok {
+			title, body, err := msg.render(data(msg.language))
+			if err != nil {
+				return nil, err
+			}
+			r = rendered{title, body}
+			byLanguage[msg] = r
+		}
+		messages = append(messages, NotificationMessage{UserID: id, Title: r.title, Body: r.body, Data: payload})
+	}
+	return messages, nil
+}
+
+// sameData is for messagesFor when the data doesn't depend on the language
+func sameData(data interface{}) func(models.Language) interface{} {
+	return func(models.Language) interface{} { return data }
+}
+
+// MessageTemplateInfo describes a message the club can reword, in one language
+type MessageTemplateInfo struct {
+	Key          models.MessageTemplateKey `json:"key"`
+	Language     models.Language           `json:"language"`
+	Description  string                    `json:"description"`
+	Title        string                    `json:"title"`
+	Body         string                    `json:"body"`
+	DefaultTitle string                    `json:"default_title"`
+	DefaultBody  string                    `json:"default_body"`
+	Customized   bool                      `json:"customized"`
+	Placeholders []string                  `json:"placeholders"`
+	UpdatedAt    *time.Time                `json:"updated_at,omitempty"`
+}
+
+// MessagePreview is a message rendered with sample data
+type MessagePreview struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// MessageCatalogService lets admins reword the reminder, deadline, waitlist,
+// RSVP summary, maybe and monthly recap notifications in each language.
+// Wording is Go text/template source checked against sample data before it's
+// saved; clearing it restores the default.
+type MessageCatalogService struct{}
+
+func NewMessageCatalogService() *MessageCatalogService {
+	return &MessageCatalogService{}
+}
+
+// ListMessages returns every message in every language with its current
+// and default wording. A language without a built-in translation defaults
+// to the default language's wording.
+func (s *MessageCatalogService) ListMessages() ([]MessageTemplateInfo, error) {
+	var custom []models.MessageTemplate
+	if err := database.DB.Find(&custom).Error; err != nil {
+		return nil, err
+	}
+	type messageID struct {
+		key  models.MessageTemplateKey
+		lang models.Language
+	}
+	byID := make(map[messageID]models.MessageTemplate, len(custom))
+	for _, m := range custom {
+		byID[messageID{m.Key, m.Language}] = m
+	}
+
+	infos := make([]MessageTemplateInfo, 0, len(messageKeys)*len(models.SupportedLanguages))
+	for _, key := range messageKeys {
+		def := messageCatalog[key]
+		for _, lang := range models.SupportedLanguages {
+			w, ok := def.wording(lang)
+			if !ok {
+				w, _ = def.wording(models.DefaultLanguage)
+			}
+			info := MessageTemplateInfo{
+				Key:          key,
+				Language:     lang,
+				Description:  def.description,
+				Title:        w.title,
+				Body:         w.body,
+				DefaultTitle: w.title,
+				DefaultBody:  w.body,
+				Placeholders: def.placeholders,
+			}
+			if m, ok := byID[messageID{key, lang}]; ok {
+				info.Title, info.Body, info.Customized = m.Title, m.Body, true
+				updatedAt := m.UpdatedAt
+				info.UpdatedAt = &updatedAt
+			}
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+// catalogEntry looks up a message and its built-in wording in lang
+func catalogEntry(key models.MessageTemplateKey, lang models.Language) (messageDefinition, messageWording, error) {
+	def, ok := messageCatalog[key]
+	if !ok {
+		return def, messageWording{}, ErrUnknownMessage
+	}
+	if !lang.IsValid() {
+		return def, messageWording{}, ErrUnsupportedLanguage
+	}
+	w, ok := def.wording(lang)
+	if !ok {
+		w, _ = def.wording(models.DefaultLanguage)
+	}
+	return def, w, nil
+}
+
+// PreviewMessage renders wording for key in lang with sample data. Empty
+// title or body preview the current wording.
+func (s *MessageCatalogService) PreviewMessage(key models.MessageTemplateKey, lang models.Language, title, body string) (*MessagePreview, error) {
+	def, _, err := catalogEntry(key, lang)
+	if err != nil {
+		return nil, err
+	}
+	if title == "" || body == "" {
+		current := loadMessage(key).forLanguage(lang).source
+		if title == "" {
+			title = current.Title
+		}
+		if body == "" {
+			body = current.Body
+		}
+	}
+	return renderSample(key, lang, def, title, body)
+}
+
+// SetMessage saves the club's wording for key in lang after checking it renders
+func (s *MessageCatalogService) SetMessage(key models.MessageTemplateKey, lang models.Language, title, body string, actorID uuid.UUID) (*models.MessageTemplate, error) {
+	def, builtIn, err := catalogEntry(key, lang)
+	if err != nil {
+		return nil, err
+	}
+	title, body = strings.TrimSpace(title), strings.TrimSpace(body)
+	if title == "" || body == "" {
+		return nil, errors.New("title and body are required")
+	}
+	if len(title) > maxMessageTitleLength {
+		return nil, fmt.Errorf("title must be at most %d characters", maxMessageTitleLength)
+	}
+	if len(body) > maxMessageBodyLength {
+		return nil, fmt.Errorf("body must be at most %d characters", maxMessageBodyLength)
+	}
+	if _, err := renderSample(key, lang, def, title, body); err != nil {
+		return nil, err
+	}
+
+	var msg models.MessageTemplate
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("key = ? AND language = ?", key, lang).First(&msg).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		oldValue := builtIn.title + "\n" + builtIn.body
+		if err == nil {
+			oldValue = msg.Title + "\n" + msg.Body
+		}
+
+		msg.Key, msg.Language, msg.Title, msg.Body, msg.UpdatedByID = key, lang, title, body, actorID
+		if err := tx.Save(&msg).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			EntityType: "message_template",
+			EntityID:   msg.ID,
+			Action:     models.AuditActionMessageTemplateChanged,
+			ActorID:    actorID,
+			OldValue:   oldValue,
+			NewValue:   title + "\n" + body,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// ResetMessage drops the club's wording for key in lang so the default is
+// used again
+func (s *MessageCatalogService) ResetMessage(key models.MessageTemplateKey, lang models.Language, actorID uuid.UUID) error {
+	_, builtIn, err := catalogEntry(key, lang)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var msg models.MessageTemplate
+		if err := tx.Where("key = ? AND language = ?", key, lang).First(&msg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil // already the default
+			}
+			return err
+		}
+		if err := tx.Delete(&msg).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			EntityType: "message_template",
+			EntityID:   msg.ID,
+			Action:     models.AuditActionMessageTemplateChanged,
+			ActorID:    actorID,
+			OldValue:   msg.Title + "\n" + msg.Body,
+			NewValue:   builtIn.title + "\n" + builtIn.body,
+		}).Error
+	})
+}
+
+// renderSample compiles wording and renders it with the message's sample
+// data, so mistakes show up before the wording is saved
+func renderSample(key models.MessageTemplateKey, lang models.Language, def messageDefinition, title, body string) (*MessagePreview, error) {
+	msg, err := compileMessage(key, lang, title, body)
+	if err != nil {
+		return nil, err
+	}
+	t, b, err := msg.render(def.sample())
+	if err != nil {
+		return nil, err
+	}
+	if t == "" || b == "" {
+		return nil, errors.New("title and body must not render empty")
+	}
+	return &MessagePreview{Title: t, Body: b}, nil
+}
@@ -0,0 +1,548 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// maxOrderQuantity bounds the quantity on one order line
+const maxOrderQuantity = 50
+
+var (
+	ErrOrderWindowNotFound = domainError(ErrNotFound, "order_window_not_found", "order window not found")
+	ErrOrderWindowClosed   = domainError(ErrConflict, "order_window_closed", "this order has closed")
+	ErrOrderNotFound       = domainError(ErrNotFound, "order_not_found", "you haven't ordered in this window")
+)
+
+// OrderService runs group orders: admins open a window with items for sale,
+// members place one order each while it's open, and the window closes on
+// its own at closes_at, when the admin who opened it is sent the totals
+type OrderService struct {
+	notificationService *NotificationService
+}
+
+func NewOrderService(notificationService *NotificationService) *OrderService {
+	return &OrderService{notificationService: notificationService}
+}
+
+type OrderItemInput struct {
+	Name       string
+	Options    []string
+	PriceCents int
+}
+
+type CreateOrderWindowInput struct {
+	Title       string
+	Description string
+	ClosesAt    time.Time
+	Items       []OrderItemInput
+	CreatedBy   uuid.UUID
+}
+
+// CreateWindow opens a group order and tells members about it
+func (s *OrderService) CreateWindow(input CreateOrderWindowInput) (*models.OrderWindow, error) {
+	if !input.ClosesAt.After(time.Now()) {
+		return nil, errors.New("closes_at must be in the future")
+	}
+	if len(input.Items) == 0 {
+		return nil, errors.New("an order needs at least one item")
+	}
+
+	window := models.OrderWindow{
+		Title:       strings.TrimSpace(input.Title),
+		Description: input.Description,
+		Status:      models.OrderWindowOpen,
+		ClosesAt:    input.ClosesAt,
+		CreatedBy:   input.CreatedBy,
+	}
+	names := make(map[string]bool, len(input.Items))
+	for i, item := range input.Items {
+		name := strings.TrimSpace(item.Name)
+		if name == "" {
+			return nil, fmt.Errorf("item %d has no name", i+1)
+		}
+		if names[strings.ToLower(name)] {
+			return nil, fmt.Errorf("%s is listed twice", name)
+		}
+		names[strings.ToLower(name)] = true
+		if item.PriceCents < 0 {
+			return nil, fmt.Errorf("%s can't have a negative price", name)
+		}
+		options, err := cleanOrderOptions(name, item.Options)
+		if err != nil {
+			return nil, err
+		}
+		window.Items = append(window.Items, models.OrderItem{
+			Name:       name,
+			Options:    options,
+			PriceCents: item.PriceCents,
+			Position:   i,
+		})
+	}
+
+	if err := database.DB.Create(&window).Error; err != nil {
+		return nil, err
+	}
+
+	var members []uuid.UUID
+	if err := database.DB.Model(&models.User{}).
+		Where("membership_status = ?", models.MembershipApproved).
+		Pluck("id", &members).Error; err != nil {
+		log.Printf("Error fetching members to tell about order %s: %v", window.Title, err)
+	} else {
+		s.notificationService.SendBulkNotification(
+			context.Background(),
+			members,
+			models.NotificationOrderWindow,
+			window.Title+" order is open",
+			fmt.Sprintf("Order by %s %s.", utils.FormatDateForDisplay(window.ClosesAt), utils.FormatTimeForDisplay(window.ClosesAt)),
+			map[string]string{"type": string(models.NotificationOrderWindow), "order_window_id": window.ID.String()},
+		)
+	}
+
+	return &window, nil
+}
+
+// cleanOrderOptions trims an item's options and rejects blanks and repeats
+func cleanOrderOptions(item string, options []string) ([]string, error) {
+	cleaned := make([]string, 0, len(options))
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return nil, fmt.Errorf("%s has a blank option", item)
+		}
+		if seen[strings.ToLower(option)] {
+			return nil, fmt.Errorf("%s lists %s twice", item, option)
+		}
+		seen[strings.ToLower(option)] = true
+		cleaned = append(cleaned, option)
+	}
+	return cleaned, nil
+}
+
+// ListWindows returns every order window with its items, open ones first,
+// then most recently closing
+func (s *OrderService) ListWindows() ([]models.OrderWindow, error) {
+	var windows []models.OrderWindow
+	if err := database.DB.Preload("Items", orderItemsInOrder).
+		Order("CASE WHEN status = 'open' THEN 0 ELSE 1 END, closes_at DESC").
+		Find(&windows).Error; err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+func orderItemsInOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
+
+// GetWindow returns an order window with its items
+func (s *OrderService) GetWindow(id uuid.UUID) (*models.OrderWindow, error) {
+	var window models.OrderWindow
+	if err := database.DB.Preload("Items", orderItemsInOrder).First(&window, "id = ?", id).Error; err != nil {
+		return nil, ErrOrderWindowNotFound
+	}
+	return &window, nil
+}
+
+// GetOrder returns a member's order in a window, or nil if they haven't ordered
+func (s *OrderService) GetOrder(windowID, userID uuid.UUID) (*models.Order, error) {
+	var order models.Order
+	err := database.DB.Preload("Lines").
+		Where("window_id = ? AND user_id = ?", windowID, userID).
+		First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+type OrderLineInput struct {
+	ItemID   uuid.UUID
+	Option   string
+	Quantity int
+}
+
+// SubmitOrder places a member's order, replacing any they placed earlier in
+// the same window
+func (s *OrderService) SubmitOrder(windowID, userID uuid.UUID, lines []OrderLineInput, notes string) (*models.Order, error) {
+	window, err := s.GetWindow(windowID)
+	if err != nil {
+		return nil, err
+	}
+	if !window.IsOpen(time.Now()) {
+		return nil, ErrOrderWindowClosed
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("an order needs at least one line")
+	}
+
+	items := make(map[uuid.UUID]models.OrderItem, len(window.Items))
+	for _, item := range window.Items {
+		items[item.ID] = item
+	}
+	orderLines := make([]models.OrderLine, len(lines))
+	seen := make(map[string]bool, len(lines))
+	for i, line := range lines {
+		item, ok := items[line.ItemID]
+		if !ok {
+			return nil, errors.New("that item isn't in this order")
+		}
+		option, err := matchOrderOption(item, line.Option)
+		if err != nil {
+			return nil, err
+		}
+		if line.Quantity < 1 || line.Quantity > maxOrderQuantity {
+			return nil, fmt.Errorf("quantity must be between 1 and %d", maxOrderQuantity)
+		}
+		key := item.ID.String() + "/" + option
+		if seen[key] {
+			return nil, fmt.Errorf("%s is ordered twice; combine the lines", describeOrderLine(item.Name, option))
+		}
+		seen[key] = true
+		orderLines[i] = models.OrderLine{ItemID: item.ID, Option: option, Quantity: line.Quantity}
+	}
+
+	var order models.Order
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("window_id = ? AND user_id = ?", windowID, userID).First(&order).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			order = models.Order{WindowID: windowID, UserID: userID, Notes: notes}
+			if err := tx.Create(&order).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			if err := tx.Where("order_id = ?", order.ID).Delete(&models.OrderLine{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&order).Update("notes", notes).Error; err != nil {
+				return err
+			}
+		}
+		for i := range orderLines {
+			orderLines[i].OrderID = order.ID
+		}
+		return tx.Create(&orderLines).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	order.Lines = orderLines
+	return &order, nil
+}
+
+// matchOrderOption returns the item's option that option names, ignoring
+// case; items without options take none
+func matchOrderOption(item models.OrderItem, option string) (string, error) {
+	option = strings.TrimSpace(option)
+	if len(item.Options) == 0 {
+		if option != "" {
+			return "", fmt.Errorf("%s has no options", item.Name)
+		}
+		return "", nil
+	}
+	for _, o := range item.Options {
+		if strings.EqualFold(o, option) {
+			return o, nil
+		}
+	}
+	return "", fmt.Errorf("choose one of %s for %s", strings.Join(item.Options, ", "), item.Name)
+}
+
+// CancelOrder withdraws a member's order while the window is open
+func (s *OrderService) CancelOrder(windowID, userID uuid.UUID) error {
+	window, err := s.GetWindow(windowID)
+	if err != nil {
+		return err
+	}
+	if !window.IsOpen(time.Now()) {
+		return ErrOrderWindowClosed
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Where("window_id = ? AND user_id = ?", windowID, userID).First(&order).Error; err != nil {
+			return ErrOrderNotFound
+		}
+		if err := tx.Where("order_id = ?", order.ID).Delete(&models.OrderLine{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&order).Error
+	})
+}
+
+type UpdateOrderWindowInput struct {
+	Title       *string
+	Description *string
+	ClosesAt    *time.Time
+}
+
+// UpdateWindow changes a window's details. Moving closes_at into the future
+// reopens a closed window.
+func (s *OrderService) UpdateWindow(id uuid.UUID, input UpdateOrderWindowInput) (*models.OrderWindow, error) {
+	window, err := s.GetWindow(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		if title == "" {
+			return nil, errors.New("title can't be blank")
+		}
+		updates["title"] = title
+	}
+	if input.Description != nil {
+		updates["description"] = *input.Description
+	}
+	if input.ClosesAt != nil {
+		if !input.ClosesAt.After(time.Now()) {
+			return nil, errors.New("closes_at must be in the future; close the order to end it now")
+		}
+		updates["closes_at"] = *input.ClosesAt
+		updates["status"] = models.OrderWindowOpen
+		updates["closed_at"] = nil
+	}
+	if len(updates) == 0 {
+		return window, nil
+	}
+
+	if err := database.DB.Model(window).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return s.GetWindow(id)
+}
+
+// CloseWindow closes an open window now
+func (s *OrderService) CloseWindow(ctx context.Context, id uuid.UUID) (*models.OrderWindow, error) {
+	window, err := s.GetWindow(id)
+	if err != nil {
+		return nil, err
+	}
+	if window.Status == models.OrderWindowClosed {
+		return nil, errors.New("this order is already closed")
+	}
+	if err := s.close(ctx, window); err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+// CloseDueWindows closes open windows whose closing time has passed
+func (s *OrderService) CloseDueWindows(ctx context.Context) error {
+	var windows []models.OrderWindow
+	if err := database.DB.Where("status = ? AND closes_at <= ?", models.OrderWindowOpen, time.Now()).
+		Find(&windows).Error; err != nil {
+		return fmt.Errorf("fetching order windows to close: %w", err)
+	}
+
+	var errs []error
+	for i := range windows {
+		if err := s.close(ctx, &windows[i]); err != nil {
+			errs = append(errs, fmt.Errorf("closing order %s: %w", windows[i].ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// close marks a window closed and sends the admin who opened it the totals
+func (s *OrderService) close(ctx context.Context, window *models.OrderWindow) error {
+	now := time.Now()
+	// Only the first of concurrent closes goes on to send the totals
+	result := database.DB.Model(&models.OrderWindow{}).
+		Where("id = ? AND status = ?", window.ID, models.OrderWindowOpen).
+		Updates(map[string]interface{}{"status": models.OrderWindowClosed, "closed_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	window.Status = models.OrderWindowClosed
+	window.ClosedAt = &now
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	summary, err := s.Summary(window.ID)
+	if err != nil {
+		return err
+	}
+	title := window.Title + " order closed"
+	body := fmt.Sprintf("%d orders came to %s.", summary.OrderCount, FormatCents(summary.TotalCents))
+	if summary.OrderCount == 1 {
+		body = fmt.Sprintf("1 order came to %s.", FormatCents(summary.TotalCents))
+	}
+	if _, err := s.notificationService.SendBatch(ctx, models.NotificationOrderWindow, []NotificationMessage{{
+		UserID: window.CreatedBy,
+		Title:  title,
+		Body:   body + " Download the order from the orders page.",
+		Data:   map[string]string{"type": string(models.NotificationOrderWindow), "order_window_id": window.ID.String()},
+	}}); err != nil {
+		log.Printf("Error sending totals for order %s: %v", window.Title, err)
+	}
+	log.Printf("Closed order %s with %d orders", window.Title, summary.OrderCount)
+	return nil
+}
+
+// OrderItemTotal is how many of an item and option were ordered in all
+type OrderItemTotal struct {
+	ItemID     uuid.UUID `json:"item_id"`
+	Item       string    `json:"item"`
+	Option     string    `json:"option"`
+	Quantity   int       `json:"quantity"`
+	PriceCents int       `json:"price_cents"`
+	TotalCents int       `json:"total_cents"`
+}
+
+// OrderSummary is a window's orders, member by member and added up
+type OrderSummary struct {
+	Window     models.OrderWindow
+	Totals     []OrderItemTotal // in item order, then option order
+	Orders     []models.Order   // with lines and members, by member name
+	OrderCount int
+	TotalCents int
+}
+
+// OrderTotalCents is what an order costs
+func (s *OrderSummary) OrderTotalCents(order models.Order) int {
+	prices := make(map[uuid.UUID]int, len(s.Window.Items))
+	for _, item := range s.Window.Items {
+		prices[item.ID] = item.PriceCents
+	}
+	total := 0
+	for _, line := range order.Lines {
+		total += prices[line.ItemID] * line.Quantity
+	}
+	return total
+}
+
+// Summary adds up a window's orders
+func (s *OrderService) Summary(windowID uuid.UUID) (*OrderSummary, error) {
+	window, err := s.GetWindow(windowID)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []models.Order
+	if err := database.DB.Preload("Lines").Preload("User").
+		Joins("JOIN users ON users.id = orders.user_id").
+		Where("orders.window_id = ?", windowID).
+		Order("users.name ASC").
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	summary := &OrderSummary{Window: *window, Orders: orders, OrderCount: len(orders)}
+	quantities := make(map[string]int)
+	for _, order := range orders {
+		for _, line := range order.Lines {
+			quantities[line.ItemID.String()+"/"+line.Option] += line.Quantity
+		}
+	}
+	for _, item := range window.Items {
+		options := item.Options
+		if len(options) == 0 {
+			options = []string{""}
+		}
+		for _, option := range options {
+			quantity := quantities[item.ID.String()+"/"+option]
+			if quantity == 0 {
+				continue
+			}
+			total := OrderItemTotal{
+				ItemID:     item.ID,
+				Item:       item.Name,
+				Option:     option,
+				Quantity:   quantity,
+				PriceCents: item.PriceCents,
+				TotalCents: quantity * item.PriceCents,
+			}
+			summary.Totals = append(summary.Totals, total)
+			summary.TotalCents += total.TotalCents
+		}
+	}
+	return summary, nil
+}
+
+// WriteOrderCSV writes the summary as CSV: the totals to order from the
+// supplier, or with byMember each member's lines for collecting payment
+func WriteOrderCSV(w io.Writer, summary *OrderSummary, byMember bool) error {
+	out := csv.NewWriter(w)
+	if byMember {
+		names := make(map[uuid.UUID]string, len(summary.Window.Items))
+		prices := make(map[uuid.UUID]int, len(summary.Window.Items))
+		positions := make(map[uuid.UUID]int, len(summary.Window.Items))
+		for _, item := range summary.Window.Items {
+			names[item.ID] = item.Name
+			prices[item.ID] = item.PriceCents
+			positions[item.ID] = item.Position
+		}
+		out.Write([]string{"Member", "Email", "Item", "Option", "Quantity", "Price", "Total", "Order total", "Notes"})
+		for _, order := range summary.Orders {
+			member, email := "", ""
+			if order.User != nil {
+				member, email = order.User.Name, order.User.Email
+			}
+			lines := order.Lines
+			sort.SliceStable(lines, func(i, j int) bool {
+				return positions[lines[i].ItemID] < positions[lines[j].ItemID]
+			})
+			orderTotal := FormatCents(summary.OrderTotalCents(order))
+			for i, line := range lines {
+				row := []string{member, email, names[line.ItemID], line.Option, strconv.Itoa(line.Quantity),
+					FormatCents(prices[line.ItemID]), FormatCents(prices[line.ItemID] * line.Quantity), "", ""}
+				// The order's total and notes once, on its first line
+				if i == 0 {
+					row[7], row[8] = orderTotal, order.Notes
+				}
+				out.Write(row)
+			}
+		}
+	} else {
+		out.Write([]string{"Item", "Option", "Quantity", "Price", "Total"})
+		for _, total := range summary.Totals {
+			out.Write([]string{total.Item, total.Option, strconv.Itoa(total.Quantity),
+				FormatCents(total.PriceCents), FormatCents(total.TotalCents)})
+		}
+		out.Write([]string{"Total", "", "", "", FormatCents(summary.TotalCents)})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// describeOrderLine names an item with its option, such as "Club shirt (M)"
+func describeOrderLine(item, option string) string {
+	if option == "" {
+		return item
+	}
+	return item + " (" + option + ")"
+}
+
+// FormatCents formats an amount in cents as dollars, such as "$12.50"
+func FormatCents(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s$%d.%02d", sign, cents/100, cents%100)
+}
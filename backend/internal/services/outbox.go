@@ -0,0 +1,261 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// outboxClaim is how long a dispatcher holds an event. If it stops
+	// mid-delivery the event is picked up again once this passes.
+	outboxClaim = 2 * time.Minute
+	// outboxMaxAttempts is how many times delivery is tried before the
+	// event is left for an admin to look at
+	outboxMaxAttempts = 10
+	// outboxBatchSize bounds the events claimed per flush
+	outboxBatchSize = 100
+	// outboxRetention is how long delivered events are kept
+	outboxRetention = 30 * 24 * time.Hour
+)
+
+// OutboxNotification is a batch of one notification type an event sends
+type OutboxNotification struct {
+	Type     models.NotificationType `json:"type"`
+	Messages []NotificationMessage   `json:"messages"`
+}
+
+// Outbox delivers domain events recorded alongside the changes that raised
+// them (see recordEvent): their notifications go to the notification
+// pipeline and the events are posted to the configured webhooks. Failed deliveries are retried with
+// backoff, and the parts already delivered aren't repeated.
+type Outbox struct {
+	notificationService *NotificationService
+	webhookURLs         []string
+	webhookSecret       *signingKey // signs webhook bodies; empty sends them unsigned
+	client              *http.Client
+}
+
+// NewOutbox creates an outbox posting events to webhookURLs
+func NewOutbox(notificationService *NotificationService, webhookURLs []string, webhookSecret string) *Outbox {
+	return &Outbox{
+		notificationService: notificationService,
+		webhookURLs:         webhookURLs,
+		webhookSecret:       newSigningKey(webhookSecret),
+		client:              &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// SetWebhookSecret switches webhook signatures to a rotated secret
+func (o *Outbox) SetWebhookSecret(secret string) {
+	o.webhookSecret.set(secret)
+}
+
+// Dispatch delivers due events in the background, so a committed change is
+// announced straight away rather than at the scheduler's next flush
+func (o *Outbox) Dispatch() {
+	// Not tied to the request, which has usually finished by the time it runs
+	go func() {
+		if err := o.Flush(context.Background()); err != nil {
+			log.Printf("Error dispatching outbox events: %v", err)
+		}
+	}()
+}
+
+// Flush delivers every event that is due. Events are claimed with SKIP
+// LOCKED and pushed back by outboxClaim, so concurrent flushes, here or on
+// another instance, don't deliver the same event twice.
+func (o *Outbox) Flush(ctx context.Context) error {
+	var due []models.OutboxEvent
+	now := time.Now()
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at IS NULL AND dispatch_at <= ? AND attempts < ?", now, outboxMaxAttempts).
+			Preload("Event").
+			Order("created_at ASC").
+			Limit(outboxBatchSize).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(due))
+		for i := range due {
+			ids[i] = due[i].ID
+			due[i].Attempts++
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"dispatch_at": now.Add(outboxClaim),
+			"attempts":    gorm.Expr("attempts + 1"),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("claiming outbox events: %w", err)
+	}
+
+	for i := range due {
+		if due[i].Event == nil {
+			log.Printf("Outbox event %s has no domain event %s", due[i].ID, due[i].EventID)
+			continue
+		}
+		o.deliver(ctx, &due[i])
+	}
+	return nil
+}
+
+// deliver sends an event's notifications, then its webhooks, recording each
+// part as it succeeds so a retry picks up where this attempt stopped
+func (o *Outbox) deliver(ctx context.Context, event *models.OutboxEvent) {
+	updates := map[string]interface{}{}
+
+	var err error
+	if event.NotifiedAt == nil {
+		if err = o.notify(ctx, event); err == nil {
+			updates["notified_at"] = time.Now()
+		}
+	}
+	if err == nil && event.WebhooksSentAt == nil {
+		if err = o.postWebhooks(ctx, event); err == nil {
+			updates["webhooks_sent_at"] = time.Now()
+		}
+	}
+
+	if err != nil {
+		updates["last_error"] = err.Error()
+		updates["dispatch_at"] = time.Now().Add(outboxBackoff(event.Attempts))
+		if event.Attempts >= outboxMaxAttempts {
+			log.Printf("Giving up on outbox event %s (%s) after %d attempts: %v", event.EventID, event.Event.Type, event.Attempts, err)
+		} else {
+			log.Printf("Error delivering outbox event %s (%s), attempt %d: %v", event.EventID, event.Event.Type, event.Attempts, err)
+		}
+	} else {
+		updates["last_error"] = ""
+		updates["dispatched_at"] = time.Now()
+	}
+
+	if err := database.DB.Model(&models.OutboxEvent{}).Where("id = ?", event.ID).Updates(updates).Error; err != nil {
+		log.Printf("Error recording delivery of outbox event %s: %v", event.EventID, err)
+	}
+}
+
+// outboxBackoff is the wait before retrying after the given attempt: 30s
+// doubling each time, up to an hour
+func outboxBackoff(attempt int) time.Duration {
+	delay := 30 * time.Second
+	for i := 1; i < attempt && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+	return delay
+}
+
+func (o *Outbox) notify(ctx context.Context, event *models.OutboxEvent) error {
+	if o.notificationService == nil {
+		return nil
+	}
+
+	var batches []OutboxNotification
+	if err := json.Unmarshal([]byte(event.Notifications), &batches); err != nil {
+		return fmt.Errorf("decoding notifications: %w", err)
+	}
+	for _, batch := range batches {
+		if _, err := o.notificationService.SendBatch(ctx, batch.Type, batch.Messages); err != nil {
+			return fmt.Errorf("sending %s notifications: %w", batch.Type, err)
+		}
+	}
+	return nil
+}
+
+// webhookDelivery is the body posted to each webhook
+type webhookDelivery struct {
+	ID         uuid.UUID        `json:"id"`
+	Seq        int64            `json:"seq"`
+	Type       models.EventType `json:"type"`
+	SessionID  *uuid.UUID       `json:"session_id,omitempty"`
+	UserID     *uuid.UUID       `json:"user_id,omitempty"`
+	ActorID    *uuid.UUID       `json:"actor_id,omitempty"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       json.RawMessage  `json:"data"`
+}
+
+// postWebhooks posts the event to every webhook. Receivers should ignore
+// deliveries whose X-Webhook-ID they've seen, as a failure at one URL means
+// all of them are posted to again.
+func (o *Outbox) postWebhooks(ctx context.Context, event *models.OutboxEvent) error {
+	if len(o.webhookURLs) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(webhookDelivery{
+		ID:         event.Event.ID,
+		Seq:        event.Event.Seq,
+		Type:       event.Event.Type,
+		SessionID:  event.Event.SessionID,
+		UserID:     event.Event.UserID,
+		ActorID:    event.Event.ActorID,
+		OccurredAt: event.Event.OccurredAt,
+		Data:       json.RawMessage(event.Event.Data),
+	})
+	if err != nil {
+		return fmt.Errorf("encoding webhook body: %w", err)
+	}
+
+	for _, url := range o.webhookURLs {
+		if err := o.postWebhook(ctx, url, event, body); err != nil {
+			return fmt.Errorf("webhook %s: %w", url, err)
+		}
+	}
+	return nil
+}
+
+func (o *Outbox) postWebhook(ctx context.Context, url string, event *models.OutboxEvent, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-ID", event.EventID.String())
+	req.Header.Set("X-Webhook-Event", string(event.Event.Type))
+	if secret := o.webhookSecret.get(); len(secret) > 0 {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PruneDelivered deletes the delivery records of events delivered more than
+// outboxRetention ago. The events themselves are kept.
+func (o *Outbox) PruneDelivered() error {
+	return database.DB.Where("dispatched_at < ?", time.Now().Add(-outboxRetention)).
+		Delete(&models.OutboxEvent{}).Error
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	// forecastLookback is how far back the occupancy forecast looks for
+	// sessions to learn drop-out and no-show rates from
+	forecastLookback = 90 * 24 * time.Hour
+
+	// minSeriesSessions is how many past sessions a recurring series needs
+	// before its forecast uses them rather than the whole club's
+	minSeriesSessions = 4
+)
+
+// SettleOverbooking puts overbooked sessions back to their spots once RSVPs
+// close: if fewer members dropped out than the margin allowed for, the last
+// to RSVP IN go back to the front of the waitlist, in RSVP order, and are
+// told. Players an admin added stay IN. Spots that come free later are
+// offered to them as usual. The scheduler runs it hourly.
+func (s *RSVPService) SettleOverbooking(report *JobReport) error {
+	now := time.Now()
+	var sessions []models.Session
+	if err := database.DB.Where(
+		"overbooking > 0 AND overbook_settled_at IS NULL AND rsvp_deadline <= ? AND starts_at > ? AND status = ?",
+		now, now, models.SessionStatusOpen,
+	).Order("starts_at ASC").Find(&sessions).Error; err != nil {
+		return fmt.Errorf("fetching overbooked sessions: %w", err)
+	}
+
+	var errs []error
+	for _, session := range sessions {
+		if err := s.settleOverbooking(session, report); err != nil {
+			errs = append(errs, fmt.Errorf("settling overbooking for session %s: %w", session.ID, err))
+		}
+	}
+	s.outbox.Dispatch()
+	return errors.Join(errs...)
+}
+
+func (s *RSVPService) settleOverbooking(session models.Session, report *JobReport) error {
+	if report.isDryRun() {
+		bumped, err := overbookedRSVPs(database.DB, session)
+		for _, rsvp := range bumped {
+			reportOverbooked(report, session, rsvp)
+		}
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := lockSession(tx, session.ID)
+		if err != nil {
+			return err
+		}
+		if session.OverbookSettledAt != nil {
+			return nil
+		}
+
+		bumped, err := overbookedRSVPs(tx, session)
+		if err != nil {
+			return err
+		}
+		if len(bumped) > 0 {
+			// They RSVP'd before anyone already waiting, so they go ahead of them
+			if err := tx.Model(&models.WaitlistEntry{}).
+				Where("session_id = ?", session.ID).
+				UpdateColumn("position", gorm.Expr("position + ?", len(bumped))).Error; err != nil {
+				return err
+			}
+		}
+		for i := range bumped {
+			rsvp := &bumped[i]
+			if err := tx.Create(&models.WaitlistEntry{SessionID: session.ID, UserID: rsvp.UserID, Position: i + 1}).Error; err != nil {
+				return err
+			}
+			rsvp.Status = models.RSVPStatusWaitlisted
+			rsvp.UpdatedAt = time.Now()
+			if err := tx.Omit("User").Save(rsvp).Error; err != nil {
+				return err
+			}
+			// Guests come only with a member who's IN
+			if err := dropGuests(tx, session.ID, rsvp.UserID); err != nil {
+				return err
+			}
+			err := recordEvent(tx, Event{
+				Type:      models.EventWaitlistOverbooked,
+				SessionID: &session.ID,
+				UserID:    &rsvp.UserID,
+			}, overbookedNotice(session, rsvp.UserID, i+1))
+			if err != nil {
+				return err
+			}
+			reportOverbooked(report, session, *rsvp)
+		}
+
+		return tx.Model(&models.Session{}).Where("id = ?", session.ID).
+			Update("overbook_settled_at", time.Now()).Error
+	})
+}
+
+// overbookedRSVPs returns the IN RSVPs holding spots past session's
+// MaxPlayers, the latest first chosen, in RSVP order. Players an admin
+// added aren't moved.
+func overbookedRSVPs(db *gorm.DB, session models.Session) ([]models.RSVP, error) {
+	held, err := spotsHeld(db, session.ID)
+	if err != nil || held <= session.MaxPlayers {
+		return nil, err
+	}
+
+	var rsvps []models.RSVP
+	if err := db.Preload("User").
+		Where("session_id = ? AND status = ? AND added_by_admin = ?", session.ID, models.RSVPStatusIn, false).
+		Order("rsvp_timestamp DESC").
+		Limit(held - session.MaxPlayers).
+		Find(&rsvps).Error; err != nil {
+		return nil, err
+	}
+	sort.Slice(rsvps, func(i, j int) bool { return rsvps[i].RSVPTimestamp.Before(rsvps[j].RSVPTimestamp) })
+	return rsvps, nil
+}
+
+func reportOverbooked(report *JobReport, session models.Session, rsvp models.RSVP) {
+	name := "A former member"
+	if rsvp.User != nil {
+		name = rsvp.User.Name
+	}
+	report.add(JobAction{Kind: "waitlist_overbooked", UserID: &rsvp.UserID, SessionID: &session.ID, Title: name, Detail: "moved to the waitlist for " + session.Title})
+}
+
+// overbookedNotice tells a member their overbooked spot didn't come free
+func overbookedNotice(session models.Session, userID uuid.UUID, position int) OutboxNotification {
+	body := fmt.Sprintf("%s on %s took a few more RSVPs than it has spots, expecting some to drop out, but not enough did. You're now #%d on the waitlist and will be offered the next spot that comes free.",
+		session.Title, utils.FormatDateForDisplay(session.SessionDate), position)
+	data := map[string]string{
+		"type":       string(models.NotificationWaitlistUpdate),
+		"session_id": session.ID.String(),
+	}
+	return OutboxNotification{
+		Type:     models.NotificationWaitlistUpdate,
+		Messages: []NotificationMessage{{UserID: userID, Title: "Moved to the Waitlist", Body: body, Data: data}},
+	}
+}
+
+// OccupancyForecast is how full a session is expected to be, from how often
+// members dropped out of and missed similar past sessions
+type OccupancyForecast struct {
+	SessionID   uuid.UUID `json:"session_id"`
+	MaxPlayers  int       `json:"max_players"`
+	Overbooking int       `json:"overbooking"`
+	Confirmed   int       `json:"confirmed"`
+	Waitlisted  int       `json:"waitlisted"`
+	RSVPsOpen   bool      `json:"rsvps_open"`
+
+	// Basis is whose past sessions the rates come from: "series" for the
+	// session's recurring series, or "club" for every session
+	Basis           string  `json:"basis"`
+	SessionsSampled int     `json:"sessions_sampled"`
+	DropRate        float64 `json:"drop_rate"`    // share of INs that had dropped out by the RSVP deadline
+	NoShowRate      float64 `json:"no_show_rate"` // share of INs who didn't turn up, where attendance was taken
+
+	ExpectedDrops      float64 `json:"expected_drops"`      // of those IN now, before RSVPs close
+	ExpectedPlayers    float64 `json:"expected_players"`    // IN once RSVPs close, with the waitlist filling spots that come free
+	ExpectedAttendance float64 `json:"expected_attendance"` // expected players less no-shows
+	// SuggestedOverbooking is the margin the drop-out rate supports: about
+	// how many of MaxPlayers plus it usually drop out
+	SuggestedOverbooking int `json:"suggested_overbooking"`
+}
+
+// Forecast estimates how many players a session will have, and suggests an
+// overbooking margin, from the drop-out and no-show rates of recent sessions
+// in its series, or of the whole club's when the series is too new
+func (s *RSVPService) Forecast(sessionID uuid.UUID) (*OccupancyForecast, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, err
+	}
+
+	forecast := &OccupancyForecast{
+		SessionID:   session.ID,
+		MaxPlayers:  session.MaxPlayers,
+		Overbooking: session.Overbooking,
+		Confirmed:   session.ConfirmedCount,
+		Waitlisted:  session.WaitlistCount,
+		RSVPsOpen:   time.Now().Before(session.RSVPDeadline),
+	}
+
+	ids, basis, err := forecastSample(&session)
+	if err != nil {
+		return nil, err
+	}
+	forecast.Basis, forecast.SessionsSampled = basis, len(ids)
+	if forecast.DropRate, err = dropRate(ids); err != nil {
+		return nil, err
+	}
+	if forecast.NoShowRate, err = noShowRate(ids); err != nil {
+		return nil, err
+	}
+
+	players := float64(forecast.Confirmed)
+	if forecast.RSVPsOpen {
+		forecast.ExpectedDrops = players * forecast.DropRate
+		players = float64(forecast.Confirmed+forecast.Waitlisted) * (1 - forecast.DropRate)
+	}
+	players = math.Min(players, float64(session.MaxPlayers))
+	forecast.ExpectedPlayers = players
+	forecast.ExpectedAttendance = players * (1 - forecast.NoShowRate)
+	if forecast.DropRate < 1 {
+		suggested := math.Round(float64(session.MaxPlayers) * forecast.DropRate / (1 - forecast.DropRate))
+		forecast.SuggestedOverbooking = min(int(suggested), session.MaxPlayers)
+	}
+
+	for _, v := range []*float64{&forecast.DropRate, &forecast.NoShowRate, &forecast.ExpectedDrops, &forecast.ExpectedPlayers, &forecast.ExpectedAttendance} {
+		*v = math.Round(*v*100) / 100
+	}
+	return forecast, nil
+}
+
+// forecastSample returns the past sessions a forecast for session learns
+// from, and whether they're its series' or the club's
+func forecastSample(session *models.Session) ([]uuid.UUID, string, error) {
+	now := time.Now()
+	past := func() *gorm.DB {
+		return database.DB.Model(&models.Session{}).
+			Where("starts_at < ? AND starts_at >= ? AND status != ? AND id != ?",
+				now, now.Add(-forecastLookback), models.SessionStatusCancelled, session.ID)
+	}
+
+	series := session.RecurringParentID
+	if series == nil && session.IsRecurring {
+		series = &session.ID
+	}
+	var ids []uuid.UUID
+	if series != nil {
+		if err := past().Where("recurring_parent_id = ? OR id = ?", *series, *series).Pluck("id", &ids).Error; err != nil {
+			return nil, "", err
+		}
+		if len(ids) >= minSeriesSessions {
+			return ids, "series", nil
+		}
+	}
+	ids = nil
+	if err := past().Pluck("id", &ids).Error; err != nil {
+		return nil, "", err
+	}
+	return ids, "club", nil
+}
+
+// dropRate is the share of members who were IN for one of sessions before
+// its RSVP deadline but weren't by then, from the RSVP history. Changes
+// after the deadline, such as settling an overbooking, don't count.
+func dropRate(sessionIDs []uuid.UUID) (float64, error) {
+	if len(sessionIDs) == 0 {
+		return 0, nil
+	}
+	var events []models.RSVPEvent
+	if err := database.DB.Joins("JOIN sessions ON sessions.id = rsvp_events.session_id").
+		Where("rsvp_events.session_id IN ? AND rsvp_events.occurred_at <= sessions.rsvp_deadline", sessionIDs).
+		Order("rsvp_events.occurred_at ASC").
+		Find(&events).Error; err != nil {
+		return 0, err
+	}
+
+	type key struct{ session, user uuid.UUID }
+	wasIn := map[key]bool{}
+	last := map[key]models.RSVPStatus{}
+	for _, e := range events {
+		k := key{e.SessionID, e.UserID}
+		if e.Status == models.RSVPStatusIn {
+			wasIn[k] = true
+		}
+		last[k] = e.Status
+	}
+	dropped := 0
+	for k := range wasIn {
+		if last[k] != models.RSVPStatusIn {
+			dropped++
+		}
+	}
+	if len(wasIn) == 0 {
+		return 0, nil
+	}
+	return float64(dropped) / float64(len(wasIn)), nil
+}
+
+// noShowRate is the share of confirmed players who neither turned up nor
+// were excused, over those of sessions where attendance was taken, as the
+// attendance report counts them
+func noShowRate(sessionIDs []uuid.UUID) (float64, error) {
+	if len(sessionIDs) == 0 {
+		return 0, nil
+	}
+	var tracked []uuid.UUID
+	if err := database.DB.Model(&models.Attendance{}).
+		Where("session_id IN ?", sessionIDs).
+		Distinct("session_id").
+		Pluck("session_id", &tracked).Error; err != nil {
+		return 0, err
+	}
+	if len(tracked) == 0 {
+		return 0, nil
+	}
+
+	var confirmed, attended int64
+	if err := database.DB.Raw(`SELECT COUNT(*) FROM (
+			SELECT session_id, user_id FROM rsvps WHERE session_id IN ? AND status = ?
+			UNION ALL
+			SELECT session_id, user_id FROM rsvps_archive WHERE session_id IN ? AND status = ?
+		) r`, tracked, models.RSVPStatusIn, tracked, models.RSVPStatusIn).Scan(&confirmed).Error; err != nil {
+		return 0, err
+	}
+	if confirmed == 0 {
+		return 0, nil
+	}
+	if err := database.DB.Raw(`SELECT COUNT(*) FROM (
+			SELECT session_id, user_id FROM rsvps WHERE session_id IN ? AND status = ?
+			UNION ALL
+			SELECT session_id, user_id FROM rsvps_archive WHERE session_id IN ? AND status = ?
+		) r JOIN attendances a ON a.session_id = r.session_id AND a.user_id = r.user_id AND a.status IN ?`,
+		tracked, models.RSVPStatusIn, tracked, models.RSVPStatusIn,
+		[]models.AttendanceStatus{models.AttendancePresent, models.AttendanceExcused}).Scan(&attended).Error; err != nil {
+		return 0, err
+	}
+	return float64(confirmed-attended) / float64(confirmed), nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// minPeerRaters is how many different members must have rated a player
+// before their peer level is used, so one harsh or generous rating can't
+// decide who they play with
+const minPeerRaters = 3
+
+var (
+	ErrGameNotFound     = domainError(ErrNotFound, "game_not_found", "game not found")
+	ErrGameNotConfirmed = domainError(ErrInvalid, "game_not_confirmed", "players can be rated once the score is confirmed")
+	ErrNotInGame        = domainError(ErrForbidden, "not_in_game", "only players in the game can rate each other")
+)
+
+// RatePlayers records a player's view of the level of the others in a
+// confirmed game, replacing any earlier rating they gave in that game. Rating
+// is optional and can cover some of the players only.
+func (s *GameService) RatePlayers(sessionID, gameID, raterID uuid.UUID, levels map[uuid.UUID]int) ([]models.PeerRating, error) {
+	var game models.Game
+	if err := database.DB.Preload("Players").
+		First(&game, "id = ? AND session_id = ?", gameID, sessionID).Error; err != nil {
+		return nil, ErrGameNotFound
+	}
+	if game.Status != models.GameStatusConfirmed {
+		return nil, ErrGameNotConfirmed
+	}
+
+	inGame := make(map[uuid.UUID]bool, len(game.Players))
+	for _, p := range game.Players {
+		inGame[p.UserID] = true
+	}
+	if !inGame[raterID] {
+		return nil, ErrNotInGame
+	}
+
+	rows := make([]models.PeerRating, 0, len(levels))
+	for rateeID, level := range levels {
+		if rateeID == raterID {
+			return nil, domainError(ErrInvalid, "self_rating", "you can't rate yourself")
+		}
+		if !inGame[rateeID] {
+			return nil, domainError(ErrInvalid, "ratee_not_in_game", "you can only rate players in this game")
+		}
+		if level < models.MinPeerLevel || level > models.MaxPeerLevel {
+			return nil, domainError(ErrInvalid, "invalid_level",
+				fmt.Sprintf("levels run from %d to %d", models.MinPeerLevel, models.MaxPeerLevel))
+		}
+		rows = append(rows, models.PeerRating{GameID: gameID, RaterID: raterID, RateeID: rateeID, Level: level})
+	}
+
+	if len(rows) > 0 {
+		if err := database.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "game_id"}, {Name: "rater_id"}, {Name: "ratee_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"level", "updated_at"}),
+		}).Create(&rows).Error; err != nil {
+			return nil, err
+		}
+	}
+	return s.MyRatings(gameID, raterID)
+}
+
+// MyRatings returns the ratings a player gave in a game. Only the rater sees
+// them; nobody is shown the ratings they received.
+func (s *GameService) MyRatings(gameID, raterID uuid.UUID) ([]models.PeerRating, error) {
+	ratings := []models.PeerRating{}
+	if err := database.DB.Where("game_id = ? AND rater_id = ?", gameID, raterID).
+		Find(&ratings).Error; err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
+
+// peerLevels returns the average level other members rate each player at,
+// counting only each rater's latest rating, for players rated by at least
+// minPeerRaters members
+func peerLevels(userIDs []uuid.UUID) (map[uuid.UUID]float64, error) {
+	levels := make(map[uuid.UUID]float64)
+	if len(userIDs) == 0 {
+		return levels, nil
+	}
+
+	var rows []struct {
+		RateeID uuid.UUID
+		Level   float64
+	}
+	if err := database.DB.Raw(`
+		SELECT ratee_id, AVG(level) AS level
+		FROM (
+			SELECT DISTINCT ON (rater_id, ratee_id) ratee_id, level
+			FROM peer_ratings
+			WHERE ratee_id IN ?
+			ORDER BY rater_id, ratee_id, updated_at DESC
+		) latest
+		GROUP BY ratee_id
+		HAVING COUNT(*) >= ?`, userIDs, minPeerRaters).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		levels[r.RateeID] = r.Level
+	}
+	return levels, nil
+}
+
+// groupByLevel orders players so that each run of models.PlayersPerCourt has
+// players of a similar level. Players without enough ratings count as the
+// middle of the scale, and equal levels keep their queue order.
+func groupByLevel(players []models.User, levels map[uuid.UUID]float64) []models.User {
+	level := func(u models.User) float64 {
+		if l, ok := levels[u.ID]; ok {
+			return l
+		}
+		return float64(models.MinPeerLevel+models.MaxPeerLevel) / 2
+	}
+	grouped := append([]models.User{}, players...)
+	sort.SliceStable(grouped, func(i, j int) bool {
+		return level(grouped[i]) > level(grouped[j])
+	})
+	return grouped
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Errors from requesting and reviewing pending actions
+var (
+	ErrActionAwaitingApproval = domainError(ErrConflict, "action_awaiting_approval", "this action is already awaiting approval")
+	ErrActionReviewed         = domainError(ErrConflict, "action_already_reviewed", "action has already been reviewed")
+)
+
+// PendingActionService holds destructive admin actions until a second admin
+// approves them
+type PendingActionService struct {
+	userService    *UserService
+	sessionService *SessionService
+}
+
+func NewPendingActionService(userService *UserService, sessionService *SessionService) *PendingActionService {
+	return &PendingActionService{
+		userService:    userService,
+		sessionService: sessionService,
+	}
+}
+
+// RequestIfRequired records a pending action when the action needs a second
+// admin, returning nil when the caller may go ahead immediately. Approval is
+// needed for deleting a session with RSVPs, rejecting a member, and changing
+// another admin's role, unless the requester is the club's only admin.
+func (s *PendingActionService) RequestIfRequired(actionType models.PendingActionType, targetID uuid.UUID, payload string, requestedBy uuid.UUID) (*models.PendingAction, error) {
+	required, err := s.isDestructive(actionType, targetID, requestedBy)
+	if err != nil || !required {
+		return nil, err
+	}
+
+	var otherAdmins int64
+	if err := database.DB.Model(&models.User{}).
+		Where("role = ? AND id <> ?", models.RoleAdmin, requestedBy).
+		Count(&otherAdmins).Error; err != nil {
+		return nil, err
+	}
+	if otherAdmins == 0 {
+		return nil, nil
+	}
+
+	var existing models.PendingAction
+	err = database.DB.Where("action_type = ? AND target_id = ? AND status = ?",
+		actionType, targetID, models.PendingActionStatusPending).First(&existing).Error
+	if err == nil {
+		return nil, ErrActionAwaitingApproval
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	action := models.PendingAction{
+		ActionType:  actionType,
+		TargetID:    targetID,
+		Payload:     payload,
+		Status:      models.PendingActionStatusPending,
+		RequestedBy: requestedBy,
+	}
+	if err := database.DB.Create(&action).Error; err != nil {
+		return nil, err
+	}
+	return &action, nil
+}
+
+// isDestructive reports whether an action on this target needs a second admin
+func (s *PendingActionService) isDestructive(actionType models.PendingActionType, targetID, requestedBy uuid.UUID) (bool, error) {
+	switch actionType {
+	case models.PendingActionDeleteSession:
+		var rsvpCount int64
+		err := database.DB.Model(&models.RSVP{}).Where("session_id = ?", targetID).Count(&rsvpCount).Error
+		return rsvpCount > 0, err
+	case models.PendingActionRejectMember:
+		return true, nil
+	case models.PendingActionChangeAdminRole:
+		var target models.User
+		if err := database.DB.First(&target, "id = ?", targetID).Error; err != nil {
+			return false, ErrUserNotFound
+		}
+		return target.IsAdmin() && target.ID != requestedBy, nil
+	default:
+		return false, fmt.Errorf("unknown action type %q", actionType)
+	}
+}
+
+// ListPendingActions returns actions newest first, optionally filtered by status
+func (s *PendingActionService) ListPendingActions(status *models.PendingActionStatus) ([]models.PendingAction, error) {
+	var actions []models.PendingAction
+	query := database.DB.Preload("Requester").Order("created_at DESC")
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if err := query.Find(&actions).Error; err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+// ApprovePendingAction executes a pending action; the approver must be a
+// different admin from the one who requested it
+func (s *PendingActionService) ApprovePendingAction(id, approverID uuid.UUID) (*models.PendingAction, error) {
+	action, err := s.getPending(id)
+	if err != nil {
+		return nil, err
+	}
+	if action.RequestedBy == approverID {
+		return nil, errors.New("another admin must approve this action")
+	}
+
+	// Claim the action first so two admins approving at once can't both run it
+	if err := s.markReviewed(action, models.PendingActionStatusApproved, approverID); err != nil {
+		return nil, err
+	}
+
+	if err := s.execute(action); err != nil {
+		database.DB.Model(&models.PendingAction{}).Where("id = ?", action.ID).
+			Updates(map[string]interface{}{"status": models.PendingActionStatusPending, "reviewed_by": nil, "reviewed_at": nil})
+		return nil, fmt.Errorf("failed to carry out action: %w", err)
+	}
+	return action, nil
+}
+
+// DeclinePendingAction discards a pending action; the requester may withdraw their own
+func (s *PendingActionService) DeclinePendingAction(id, reviewerID uuid.UUID) (*models.PendingAction, error) {
+	action, err := s.getPending(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.markReviewed(action, models.PendingActionStatusDeclined, reviewerID); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+func (s *PendingActionService) getPending(id uuid.UUID) (*models.PendingAction, error) {
+	var action models.PendingAction
+	if err := database.DB.First(&action, "id = ?", id).Error; err != nil {
+		return nil, domainError(ErrNotFound, "pending_action_not_found", "pending action not found")
+	}
+	if action.Status != models.PendingActionStatusPending {
+		return nil, ErrActionReviewed
+	}
+	return &action, nil
+}
+
+func (s *PendingActionService) execute(action *models.PendingAction) error {
+	switch action.ActionType {
+	case models.PendingActionDeleteSession:
+		return s.sessionService.DeleteSession(action.TargetID)
+	case models.PendingActionRejectMember:
+		_, err := s.userService.RejectJoinRequest(action.TargetID)
+		return err
+	case models.PendingActionChangeAdminRole:
+		_, err := s.userService.UpdateUserRole(action.TargetID, models.UserRole(action.Payload))
+		return err
+	default:
+		return fmt.Errorf("unknown action type %q", action.ActionType)
+	}
+}
+
+// markReviewed moves a still-pending action to status, failing if another
+// admin reviewed it first
+func (s *PendingActionService) markReviewed(action *models.PendingAction, status models.PendingActionStatus, reviewerID uuid.UUID) error {
+	now := time.Now()
+	result := database.DB.Model(&models.PendingAction{}).
+		Where("id = ? AND status = ?", action.ID, models.PendingActionStatusPending).
+		Updates(map[string]interface{}{"status": status, "reviewed_by": reviewerID, "reviewed_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrActionReviewed
+	}
+
+	action.Status = status
+	action.ReviewedBy = &reviewerID
+	action.ReviewedAt = &now
+	return nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+// RecurrencePreviewInput is a recurring series as it would be created
+type RecurrencePreviewInput struct {
+	Title              string
+	SessionDate        time.Time
+	StartTime          utils.Clock
+	EndTime            utils.Clock
+	RecurringDayOfWeek *int
+	Occurrences        *int
+}
+
+// RecurrenceOccurrence is one session of a previewed series
+type RecurrenceOccurrence struct {
+	SessionDate  string    `json:"session_date"` // YYYY-MM-DD
+	Title        string    `json:"title"`
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+	RSVPDeadline time.Time `json:"rsvp_deadline"`
+	// Why the session won't be created, e.g. because the date has passed
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// RecurrencePreview is what creating a series would generate
+type RecurrencePreview struct {
+	Occurrences []RecurrenceOccurrence `json:"occurrences"` // the first session, then each week after
+	Created     int                    `json:"created"`     // sessions that would be created now
+	Until       string                 `json:"until"`       // last date generated now
+	// With no set number of occurrences the nightly job keeps adding weeks
+	// to stay the club's look-ahead window ahead
+	Continues bool     `json:"continues"`
+	Warnings  []string `json:"warnings"`
+}
+
+// PreviewRecurrence returns the sessions creating a recurring series would
+// generate, without creating anything. It steps through the dates exactly
+// as CreateSession does.
+func (s *SessionService) PreviewRecurrence(input RecurrencePreviewInput) (*RecurrencePreview, error) {
+	if input.RecurringDayOfWeek == nil {
+		return nil, errors.New("recurring_day_of_week is required")
+	}
+	if day := *input.RecurringDayOfWeek; day < 0 || day > 6 {
+		return nil, errors.New("recurring_day_of_week must be between 0 (Sunday) and 6 (Saturday)")
+	}
+
+	until := seriesEnd(input.SessionDate, input.Occurrences)
+	preview := &RecurrencePreview{
+		Until:     until.Format("2006-01-02"),
+		Continues: input.Occurrences == nil || *input.Occurrences <= 0,
+		Warnings:  []string{},
+	}
+
+	if weekday := input.SessionDate.Weekday(); int(weekday) != *input.RecurringDayOfWeek {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf(
+			"%s is a %s but recurring_day_of_week is %s; sessions repeat every 7 days from session_date",
+			input.SessionDate.Format("2006-01-02"), weekday, time.Weekday(*input.RecurringDayOfWeek)))
+	}
+
+	today := utils.StartOfDay(utils.NowInSydney())
+	if utils.StartOfDay(input.SessionDate).Before(today) {
+		preview.Warnings = append(preview.Warnings, "session_date has passed; the first session would still be created, but later dates that have passed would not")
+	}
+
+	title := input.Title
+	if title == "" {
+		title = recurringTitle(input.SessionDate)
+	}
+	preview.Occurrences = append(preview.Occurrences, occurrenceOn(input, input.SessionDate, title))
+	preview.Created++
+
+	blackouts, err := blackoutDates()
+	if err != nil {
+		return nil, err
+	}
+	for _, date := range recurrenceDates(input.SessionDate, until) {
+		occurrence := occurrenceOn(input, date, recurringTitle(date))
+		if utils.StartOfDay(date).Before(today) {
+			occurrence.Skipped = "in the past"
+		} else if reason, ok := blackouts[dateKey(date)]; ok {
+			occurrence.Skipped = "a blackout date"
+			if reason != "" {
+				occurrence.Skipped += ": " + reason
+			}
+		} else {
+			preview.Created++
+		}
+		preview.Occurrences = append(preview.Occurrences, occurrence)
+	}
+	return preview, nil
+}
+
+func occurrenceOn(input RecurrencePreviewInput, date time.Time, title string) RecurrenceOccurrence {
+	startsAt, endsAt := sessionTimes(date, input.StartTime, input.EndTime)
+	return RecurrenceOccurrence{
+		SessionDate:  date.Format("2006-01-02"),
+		Title:        title,
+		StartsAt:     startsAt,
+		EndsAt:       endsAt,
+		RSVPDeadline: utils.CalculateRSVPDeadline(date),
+	}
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+type ReportService struct{}
+
+func NewReportService() *ReportService {
+	return &ReportService{}
+}
+
+// SessionUsage is a single session's line in the monthly report
+type SessionUsage struct {
+	SessionID       uuid.UUID `json:"session_id"`
+	Title           string    `json:"title"`
+	SessionDate     time.Time `json:"session_date"`
+	Courts          int       `json:"courts"`
+	Attendees       int       `json:"attendees"`
+	ShuttlesUsed    *int      `json:"shuttles_used"`
+	DurationMinutes int       `json:"duration_minutes"`
+	ShuttlesPerHead float64   `json:"shuttles_per_head"`
+}
+
+// MonthlyReport summarises session usage for a calendar month
+type MonthlyReport struct {
+	Month                  string         `json:"month"` // YYYY-MM
+	SessionsHeld           int            `json:"sessions_held"`
+	SessionsCancelled      int            `json:"sessions_cancelled"`
+	TotalAttendees         int            `json:"total_attendees"`
+	TotalShuttlesUsed      int            `json:"total_shuttles_used"`
+	SessionsWithUsage      int            `json:"sessions_with_usage"`
+	AvgShuttlesPerSession  float64        `json:"avg_shuttles_per_session"`
+	TotalMinutesPlayed     int            `json:"total_minutes_played"`
+	UpcomingSessions       int            `json:"upcoming_sessions_next_month"`
+	ForecastShuttlesNeeded int            `json:"forecast_shuttles_next_month"`
+	Sessions               []SessionUsage `json:"sessions"`
+}
+
+// GetMonthlyReport builds the usage report for the month starting at monthStart
+// (in Sydney time) and forecasts shuttle needs for the following month
+func (s *ReportService) GetMonthlyReport(monthStart time.Time) (*MonthlyReport, error) {
+	monthStart = monthStart.In(utils.SydneyLocation)
+	start := time.Date(monthStart.Year(), monthStart.Month(), 1, 0, 0, 0, 0, utils.SydneyLocation)
+	end := start.AddDate(0, 1, 0)
+
+	var sessions []models.Session
+	if err := database.DB.Where("session_date >= ? AND session_date < ?", start, end).
+		Preload("RSVPs", "status = ?", models.RSVPStatusIn).
+		Order("starts_at ASC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	report := &MonthlyReport{
+		Month:    start.Format("2006-01"),
+		Sessions: []SessionUsage{},
+	}
+
+	for _, session := range sessions {
+		if session.Status == models.SessionStatusCancelled {
+			report.SessionsCancelled++
+			continue
+		}
+
+		usage := SessionUsage{
+			SessionID:       session.ID,
+			Title:           session.Title,
+			SessionDate:     session.SessionDate,
+			Courts:          session.Courts,
+			Attendees:       len(session.RSVPs),
+			ShuttlesUsed:    session.ShuttlesUsed,
+			DurationMinutes: session.ActualDurationMinutes(),
+		}
+		if session.ShuttlesUsed != nil {
+			report.TotalShuttlesUsed += *session.ShuttlesUsed
+			report.SessionsWithUsage++
+			if usage.Attendees > 0 {
+				usage.ShuttlesPerHead = float64(*session.ShuttlesUsed) / float64(usage.Attendees)
+			}
+		}
+
+		report.SessionsHeld++
+		report.TotalAttendees += usage.Attendees
+		report.TotalMinutesPlayed += usage.DurationMinutes
+		report.Sessions = append(report.Sessions, usage)
+	}
+
+	if report.SessionsWithUsage > 0 {
+		report.AvgShuttlesPerSession = float64(report.TotalShuttlesUsed) / float64(report.SessionsWithUsage)
+	}
+
+	// Forecast next month's shuttle order from this month's average usage
+	var upcoming int64
+	database.DB.Model(&models.Session{}).
+		Where("session_date >= ? AND session_date < ? AND status != ?", end, end.AddDate(0, 1, 0), models.SessionStatusCancelled).
+		Count(&upcoming)
+	report.UpcomingSessions = int(upcoming)
+	report.ForecastShuttlesNeeded = int(report.AvgShuttlesPerSession*float64(upcoming) + 0.5)
+
+	return report, nil
+}
+
+// MemberAttendance is one member's line in the attendance report. Only
+// sessions where attendance was taken count.
+type MemberAttendance struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	Name        string     `json:"name"`
+	Confirmed   int        `json:"confirmed"` // sessions they were IN for
+	Attended    int        `json:"attended"`
+	Excused     int        `json:"excused"`
+	NoShows     int        `json:"no_shows"`    // IN, but not there or excused
+	WalkIns     int        `json:"walk_ins"`    // there without being IN
+	Reliability float64    `json:"reliability"` // share of confirmed sessions attended or excused, 0-1
+	LastNoShow  *time.Time `json:"last_no_show,omitempty"`
+}
+
+// MonthAttendance is the club's no-show rate for a month
+type MonthAttendance struct {
+	Month           string  `json:"month"` // YYYY-MM
+	SessionsTracked int     `json:"sessions_tracked"`
+	Confirmed       int     `json:"confirmed"`
+	NoShows         int     `json:"no_shows"`
+	NoShowRate      float64 `json:"no_show_rate"`
+}
+
+// AttendanceReport compares confirmed RSVPs with who turned up. Sessions
+// nobody recorded attendance at are left out rather than counted as everyone
+// missing.
+type AttendanceReport struct {
+	SessionsTracked   int                `json:"sessions_tracked"`
+	SessionsUntracked int                `json:"sessions_untracked"`
+	Members           []MemberAttendance `json:"members"` // most no-shows first
+	Months            []MonthAttendance  `json:"months"`
+}
+
+// GetAttendanceReport builds the attendance report for sessions that have
+// ended between from and to (dates in Sydney, to inclusive)
+func (s *ReportService) GetAttendanceReport(from, to time.Time) (*AttendanceReport, error) {
+	var sessions []models.Session
+	if err := database.DB.Where("session_date >= ? AND session_date <= ? AND status != ? AND ends_at < ?",
+		from, to, models.SessionStatusCancelled, time.Now()).
+		Order("starts_at ASC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(sessions))
+	for i, session := range sessions {
+		ids[i] = session.ID
+	}
+
+	var attendance []models.Attendance
+	var rsvps []models.RSVP
+	if len(ids) > 0 {
+		if err := database.DB.Where("session_id IN ?", ids).Find(&attendance).Error; err != nil {
+			return nil, err
+		}
+		if err := database.DB.Where("session_id IN ? AND status = ?", ids, models.RSVPStatusIn).Find(&rsvps).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	type key struct{ session, user uuid.UUID }
+	recorded := map[key]models.AttendanceStatus{}
+	tracked := map[uuid.UUID]bool{}
+	for _, a := range attendance {
+		recorded[key{a.SessionID, a.UserID}] = a.Status
+		tracked[a.SessionID] = true
+	}
+	confirmed := map[key]bool{}
+	for _, r := range rsvps {
+		confirmed[key{r.SessionID, r.UserID}] = true
+	}
+
+	report := &AttendanceReport{Members: []MemberAttendance{}, Months: []MonthAttendance{}}
+	members := map[uuid.UUID]*MemberAttendance{}
+	member := func(userID uuid.UUID) *MemberAttendance {
+		if m, ok := members[userID]; ok {
+			return m
+		}
+		m := &MemberAttendance{UserID: userID}
+		members[userID] = m
+		return m
+	}
+
+	sessionsByID := map[uuid.UUID]models.Session{}
+	for _, session := range sessions {
+		sessionsByID[session.ID] = session
+		if !tracked[session.ID] {
+			report.SessionsUntracked++
+			continue
+		}
+		report.SessionsTracked++
+
+		month := session.SessionDate.Format("2006-01")
+		if len(report.Months) == 0 || report.Months[len(report.Months)-1].Month != month {
+			report.Months = append(report.Months, MonthAttendance{Month: month})
+		}
+		report.Months[len(report.Months)-1].SessionsTracked++
+	}
+
+	for _, r := range rsvps {
+		session := sessionsByID[r.SessionID]
+		if !tracked[session.ID] {
+			continue
+		}
+		m := member(r.UserID)
+		month := &report.Months[monthIndex(report.Months, session.SessionDate.Format("2006-01"))]
+		m.Confirmed++
+		month.Confirmed++
+		switch recorded[key{r.SessionID, r.UserID}] {
+		case models.AttendancePresent:
+			m.Attended++
+		case models.AttendanceExcused:
+			m.Excused++
+		default:
+			m.NoShows++
+			month.NoShows++
+			if m.LastNoShow == nil || session.StartsAt.After(*m.LastNoShow) {
+				startsAt := session.StartsAt
+				m.LastNoShow = &startsAt
+			}
+		}
+	}
+	for _, a := range attendance {
+		if a.Status == models.AttendancePresent && !confirmed[key{a.SessionID, a.UserID}] {
+			member(a.UserID).WalkIns++
+		}
+	}
+
+	for i := range report.Months {
+		if report.Months[i].Confirmed > 0 {
+			report.Months[i].NoShowRate = float64(report.Months[i].NoShows) / float64(report.Months[i].Confirmed)
+		}
+	}
+
+	if len(members) == 0 {
+		return report, nil
+	}
+	userIDs := make([]uuid.UUID, 0, len(members))
+	for id := range members {
+		userIDs = append(userIDs, id)
+	}
+	var users []models.User
+	if err := database.DB.Select("id", "name").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		members[u.ID].Name = u.Name
+	}
+
+	for _, m := range members {
+		if m.Confirmed > 0 {
+			m.Reliability = float64(m.Attended+m.Excused) / float64(m.Confirmed)
+		}
+		report.Members = append(report.Members, *m)
+	}
+	sort.Slice(report.Members, func(i, j int) bool {
+		a, b := report.Members[i], report.Members[j]
+		if a.NoShows != b.NoShows {
+			return a.NoShows > b.NoShows
+		}
+		return a.Name < b.Name
+	})
+
+	return report, nil
+}
+
+// monthIndex finds a month in the report's months, which are in order
+func monthIndex(months []MonthAttendance, month string) int {
+	return sort.Search(len(months), func(i int) bool { return months[i].Month >= month })
+}
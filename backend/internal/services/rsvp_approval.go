@@ -0,0 +1,195 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// rotationWindow is how far back attendance is counted when ranking requests,
+// so members who've played less lately are offered spots first
+const rotationWindow = 28 * 24 * time.Hour
+
+// RSVPRequest is a member waiting for approval to play
+type RSVPRequest struct {
+	RSVP models.RSVP
+	// RecentAttendance is how many sessions they played in the rotation window
+	RecentAttendance int
+}
+
+// ListRSVPRequests returns a session's pending requests in suggested order:
+// fewest recent sessions first, then earliest request
+func (s *RSVPService) ListRSVPRequests(sessionID uuid.UUID) ([]RSVPRequest, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	requests, err := s.pendingRequests(session, rotationWindow)
+	if err != nil {
+		return nil, err
+	}
+	// Stable, so equal attendance keeps request order
+	sort.SliceStable(requests, func(i, j int) bool {
+		return requests[i].RecentAttendance < requests[j].RecentAttendance
+	})
+	return requests, nil
+}
+
+// pendingRequests returns a session's requests in the order they were made,
+// with each member's attendance over window before the session
+func (s *RSVPService) pendingRequests(session models.Session, window time.Duration) ([]RSVPRequest, error) {
+	var rsvps []models.RSVP
+	if err := database.DB.Where("session_id = ? AND status = ?", session.ID, models.RSVPStatusRequested).
+		Preload("User").
+		Order("rsvp_timestamp ASC").
+		Find(&rsvps).Error; err != nil {
+		return nil, err
+	}
+	if len(rsvps) == 0 {
+		return []RSVPRequest{}, nil
+	}
+
+	userIDs := make([]uuid.UUID, len(rsvps))
+	for i, r := range rsvps {
+		userIDs[i] = r.UserID
+	}
+
+	var counts []struct {
+		UserID uuid.UUID
+		Played int
+	}
+	if err := database.DB.Model(&models.RSVP{}).
+		Select("rsvps.user_id, COUNT(*) AS played").
+		Joins("JOIN sessions ON sessions.id = rsvps.session_id").
+		Where("rsvps.user_id IN ? AND rsvps.status = ?", userIDs, models.RSVPStatusIn).
+		Where("sessions.status <> ?", models.SessionStatusCancelled).
+		Where("sessions.session_date >= ? AND sessions.session_date < ?", session.SessionDate.Add(-window), session.SessionDate).
+		Group("rsvps.user_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	played := make(map[uuid.UUID]int, len(counts))
+	for _, c := range counts {
+		played[c.UserID] = c.Played
+	}
+
+	requests := make([]RSVPRequest, len(rsvps))
+	for i, r := range rsvps {
+		requests[i] = RSVPRequest{RSVP: r, RecentAttendance: played[r.UserID]}
+	}
+	return requests, nil
+}
+
+// ApproveRSVPRequest confirms a member's request, as long as there's room
+func (s *RSVPService) ApproveRSVPRequest(sessionID, userID uuid.UUID) (*models.RSVP, error) {
+	session, rsvp, err := s.pendingRequest(sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	held, err := spotsHeld(database.DB, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if held >= session.RSVPCapacity() {
+		return nil, ErrSessionIsFull
+	}
+
+	rsvp.Status = models.RSVPStatusIn
+	rsvp.UpdatedAt = time.Now()
+	if err := s.saveRequestOutcome(*session, rsvp, true, ""); err != nil {
+		return nil, err
+	}
+
+	database.DB.Preload("User").First(rsvp, "id = ?", rsvp.ID)
+	return rsvp, nil
+}
+
+// DeclineRSVPRequest turns down a member's request, telling them why if a reason is given
+func (s *RSVPService) DeclineRSVPRequest(sessionID, userID uuid.UUID, reason string) (*models.RSVP, error) {
+	session, rsvp, err := s.pendingRequest(sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rsvp.Status = models.RSVPStatusDeclined
+	rsvp.UpdatedAt = time.Now()
+	if err := s.saveRequestOutcome(*session, rsvp, false, reason); err != nil {
+		return nil, err
+	}
+
+	database.DB.Preload("User").First(rsvp, "id = ?", rsvp.ID)
+	return rsvp, nil
+}
+
+// pendingRequest loads a session and a member's request to play in it
+func (s *RSVPService) pendingRequest(sessionID, userID uuid.UUID) (*models.Session, *models.RSVP, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, nil, ErrSessionNotFound
+	}
+	if session.Status != models.SessionStatusOpen {
+		return nil, nil, ErrSessionNotOpen
+	}
+
+	var rsvp models.RSVP
+	if err := database.DB.Where("session_id = ? AND user_id = ?", sessionID, userID).First(&rsvp).Error; err != nil {
+		return nil, nil, ErrRSVPNotFound
+	}
+	if rsvp.Status != models.RSVPStatusRequested {
+		return nil, nil, fmt.Errorf("RSVP is %s, not awaiting approval", rsvp.Status)
+	}
+	return &session, &rsvp, nil
+}
+
+// saveRequestOutcome saves an approved or declined request along with the
+// event telling the member
+func (s *RSVPService) saveRequestOutcome(session models.Session, rsvp *models.RSVP, approved bool, reason string) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(rsvp).Error; err != nil {
+			return err
+		}
+		return s.addRequestOutcome(tx, session, rsvp.UserID, approved, reason)
+	})
+	if err != nil {
+		return err
+	}
+	s.outbox.Dispatch()
+	return nil
+}
+
+// addRequestOutcome records in tx the rsvp.request_approved or
+// rsvp.request_declined event for a member's request, which tells them
+func (s *RSVPService) addRequestOutcome(tx *gorm.DB, session models.Session, userID uuid.UUID, approved bool, reason string) error {
+	dateStr := utils.FormatDateForDisplay(session.SessionDate)
+	eventType := models.EventRSVPRequestApproved
+	status := models.RSVPStatusIn
+	title := "You're In!"
+	body := fmt.Sprintf("Your request to play %s on %s has been approved.", session.Title, dateStr)
+	if !approved {
+		eventType = models.EventRSVPRequestDeclined
+		status = models.RSVPStatusDeclined
+		title = "Request Declined"
+		body = fmt.Sprintf("Your request to play %s on %s was not approved this time.", session.Title, dateStr)
+		if reason != "" {
+			body += " Reason: " + reason
+		}
+	}
+	data := map[string]string{
+		"type":       string(models.NotificationRSVPChanged),
+		"session_id": session.ID.String(),
+	}
+
+	return recordEvent(tx, rsvpEvent(eventType, session.ID, userID, RSVPEvent{Status: &status, ByAdmin: true, Reason: reason}),
+		OutboxNotification{
+			Type:     models.NotificationRSVPChanged,
+			Messages: []NotificationMessage{{UserID: userID, Title: title, Body: body, Data: data}},
+		})
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// driftedSession is a session whose RSVP counters disagree with its RSVPs
+type driftedSession struct {
+	ID    uuid.UUID
+	Title string
+	models.RSVPCounts
+}
+
+// ReconcileRSVPCounts recounts the RSVPs of every session whose counters
+// have drifted from them. Only writes that skip the RSVP model's hooks, like
+// a fix made directly in the database, should cause drift. Each session is
+// reported with the counts it had and the ones its RSVPs give.
+func (s *RSVPService) ReconcileRSVPCounts(report *JobReport) error {
+	var drifted []driftedSession
+	err := database.DB.Table("sessions").
+		Select(`sessions.id, sessions.title, sessions.confirmed_count, sessions.maybe_count,
+			sessions.out_count, sessions.requested_count, sessions.waitlist_count, sessions.guest_count`).
+		Joins("LEFT JOIN (?) c ON c.session_id = sessions.id", models.RSVPTallies(database.DB)).
+		Where(`(sessions.confirmed_count, sessions.maybe_count, sessions.out_count, sessions.requested_count,
+				sessions.waitlist_count, sessions.guest_count)
+			IS DISTINCT FROM (COALESCE(c.confirmed_count, 0), COALESCE(c.maybe_count, 0), COALESCE(c.out_count, 0),
+			COALESCE(c.requested_count, 0), COALESCE(c.waitlist_count, 0), COALESCE(c.guest_count, 0))`).
+		Order("sessions.starts_at ASC").
+		Scan(&drifted).Error
+	if err != nil {
+		return fmt.Errorf("finding drifted RSVP counts: %w", err)
+	}
+
+	for i := range drifted {
+		session := &drifted[i]
+		counts, err := models.TallyRSVPs(database.DB, session.ID)
+		if err != nil {
+			return fmt.Errorf("counting RSVPs for session %s: %w", session.ID, err)
+		}
+		report.add(JobAction{
+			Kind:      "fix_rsvp_counts",
+			SessionID: &session.ID,
+			Title:     session.Title,
+			Detail:    fmt.Sprintf("counters had %s; RSVPs give %s", formatRSVPCounts(session.RSVPCounts), formatRSVPCounts(counts)),
+		})
+		if report.isDryRun() {
+			continue
+		}
+		if err := database.DB.Transaction(func(tx *gorm.DB) error {
+			return models.RefreshRSVPCounts(tx, session.ID)
+		}); err != nil {
+			return fmt.Errorf("recounting RSVPs for session %s: %w", session.ID, err)
+		}
+	}
+	return nil
+}
+
+func formatRSVPCounts(c models.RSVPCounts) string {
+	return fmt.Sprintf("in %d, maybe %d, out %d, requested %d, waitlisted %d, guests %d",
+		c.ConfirmedCount, c.MaybeCount, c.OutCount, c.RequestedCount, c.WaitlistCount, c.GuestCount)
+}
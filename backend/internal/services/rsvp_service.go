@@ -2,6 +2,8 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,12 +11,47 @@ import (
 	"github.com/weekday-masters/backend/internal/models"
 	"github.com/weekday-masters/backend/internal/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type RSVPService struct{}
+// ErrWeeklyQuotaReached is returned when an RSVP would take a member past
+// their membership tier's or the club's sessions per week
+var ErrWeeklyQuotaReached = domainError(ErrForbidden, "weekly_quota_reached", "weekly RSVP limit reached")
 
-func NewRSVPService() *RSVPService {
-	return &RSVPService{}
+// ErrSeriesQuotaReached is returned when an RSVP would take a member past the
+// club's upcoming sessions per recurring series
+var ErrSeriesQuotaReached = domainError(ErrForbidden, "series_quota_reached", "series RSVP limit reached")
+
+type RSVPService struct {
+	notificationService *NotificationService
+	documentService     *DocumentService
+	outbox              *Outbox
+}
+
+func NewRSVPService(notificationService *NotificationService, documentService *DocumentService, outbox *Outbox) *RSVPService {
+	return &RSVPService{
+		notificationService: notificationService,
+		documentService:     documentService,
+		outbox:              outbox,
+	}
+}
+
+// RSVPEvent is the data of the rsvp.* domain events
+type RSVPEvent struct {
+	Status  *models.RSVPStatus `json:"status"` // nil once the RSVP is withdrawn or removed
+	ByAdmin bool               `json:"by_admin"`
+	Reason  string             `json:"reason,omitempty"`
+	Offered []uuid.UUID        `json:"offered,omitempty"` // offered a spot off the waitlist as a result
+}
+
+// rsvpEvent is an rsvp.* domain event about a member's RSVP to a session.
+// Members are the actor for their own changes; admins aren't known here.
+func rsvpEvent(eventType models.EventType, sessionID, userID uuid.UUID, data RSVPEvent) Event {
+	event := Event{Type: eventType, SessionID: &sessionID, UserID: &userID, Data: data}
+	if !data.ByAdmin {
+		event.ActorID = &userID
+	}
+	return event
 }
 
 type RSVPInput struct {
@@ -28,12 +65,12 @@ func (s *RSVPService) CreateOrUpdateRSVP(input RSVPInput, byAdmin bool) (*models
 	// Get the session
 	var session models.Session
 	if err := database.DB.First(&session, "id = ?", input.SessionID).Error; err != nil {
-		return nil, errors.New("session not found")
+		return nil, ErrSessionNotFound
 	}
 
 	// Check if session is open
 	if session.Status != models.SessionStatusOpen {
-		return nil, errors.New("session is not open for RSVPs")
+		return nil, ErrSessionNotOpen
 	}
 
 	now := utils.NowInSydney()
@@ -41,7 +78,7 @@ func (s *RSVPService) CreateOrUpdateRSVP(input RSVPInput, byAdmin bool) (*models
 
 	// Check RSVP deadline for non-admin
 	if !byAdmin && isLate {
-		return nil, errors.New("RSVP deadline has passed")
+		return nil, ErrRSVPDeadline
 	}
 
 	// Check if RSVP already exists
@@ -50,17 +87,28 @@ func (s *RSVPService) CreateOrUpdateRSVP(input RSVPInput, byAdmin bool) (*models
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			if !byAdmin {
+				if err := s.checkFirstRSVPAllowed(input.UserID); err != nil {
+					return nil, err
+				}
+			}
+
+			status, err := rsvpStatusFor(session, nil, input.Status, byAdmin)
+			if err != nil {
+				return nil, err
+			}
+
 			// Create new RSVP
 			rsvp = models.RSVP{
 				SessionID:     input.SessionID,
 				UserID:        input.UserID,
-				Status:        input.Status,
+				Status:        status,
 				RSVPTimestamp: now,
 				IsLateRSVP:    isLate,
 				AddedByAdmin:  byAdmin,
 			}
 
-			if err := database.DB.Create(&rsvp).Error; err != nil {
+			if err := s.saveRSVP(&rsvp, "", byAdmin, !byAdmin && holdsSpot(status)); err != nil {
 				return nil, err
 			}
 		} else {
@@ -69,11 +117,17 @@ func (s *RSVPService) CreateOrUpdateRSVP(input RSVPInput, byAdmin bool) (*models
 	} else {
 		// Check if user is trying to change from IN to OUT after deadline
 		if !byAdmin && isLate && rsvp.Status == models.RSVPStatusIn && input.Status != models.RSVPStatusIn {
-			return nil, errors.New("cannot change RSVP from IN after deadline")
+			return nil, ErrRSVPChangeLocked
+		}
+
+		status, err := rsvpStatusFor(session, &rsvp, input.Status, byAdmin)
+		if err != nil {
+			return nil, err
 		}
 
 		// Update existing RSVP
-		rsvp.Status = input.Status
+		previous := rsvp.Status
+		rsvp.Status = status
 		rsvp.UpdatedAt = time.Now()
 
 		// Don't update timestamp unless admin is changing it
@@ -81,7 +135,7 @@ func (s *RSVPService) CreateOrUpdateRSVP(input RSVPInput, byAdmin bool) (*models
 			rsvp.AddedByAdmin = true
 		}
 
-		if err := database.DB.Save(&rsvp).Error; err != nil {
+		if err := s.saveRSVP(&rsvp, previous, byAdmin, !byAdmin && holdsSpot(status) && !holdsSpot(previous)); err != nil {
 			return nil, err
 		}
 	}
@@ -92,18 +146,208 @@ func (s *RSVPService) CreateOrUpdateRSVP(input RSVPInput, byAdmin bool) (*models
 	return &rsvp, nil
 }
 
+// saveRSVP creates or updates an RSVP, previously of status previous ("" for
+// a new one), along with its rsvp.changed event. An IN to a full session
+// joins the waitlist instead (see placeRSVP), and a spot given up is offered
+// to the next in line. checkLimits applies the member's RSVP limits first.
+func (s *RSVPService) saveRSVP(rsvp *models.RSVP, previous models.RSVPStatus, byAdmin, checkLimits bool) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := lockSession(tx, rsvp.SessionID)
+		if err != nil {
+			return ErrSessionNotFound
+		}
+		if checkLimits {
+			if err := checkRSVPLimits(tx, rsvp.UserID, session); err != nil {
+				return err
+			}
+		}
+		freed, err := placeRSVP(tx, session, rsvp, previous)
+		if err != nil {
+			return err
+		}
+
+		save := tx.Save
+		if previous == "" {
+			save = tx.Create
+		}
+		if err := save(rsvp).Error; err != nil {
+			return err
+		}
+		status := rsvp.Status
+		event := RSVPEvent{Status: &status, ByAdmin: byAdmin}
+		if freed {
+			if event.Offered, err = offerOpenSpots(tx, session); err != nil {
+				return err
+			}
+		}
+		return recordEvent(tx, rsvpEvent(models.EventRSVPChanged, rsvp.SessionID, rsvp.UserID, event))
+	})
+	if err != nil {
+		return err
+	}
+	s.outbox.Dispatch()
+	return nil
+}
+
+// rsvpStatusFor returns the status to store when a member asks for want. On
+// sessions that require approval a member's IN becomes a request, unless an
+// admin has already approved them; admins set statuses directly.
+func rsvpStatusFor(session models.Session, current *models.RSVP, want models.RSVPStatus, byAdmin bool) (models.RSVPStatus, error) {
+	if byAdmin || !session.RequiresApproval || want != models.RSVPStatusIn {
+		return want, nil
+	}
+	if current != nil {
+		switch current.Status {
+		case models.RSVPStatusIn:
+			return models.RSVPStatusIn, nil
+		case models.RSVPStatusDeclined:
+			return "", domainError(ErrForbidden, "rsvp_request_declined", "your request for this session was declined")
+		}
+	}
+	return models.RSVPStatusRequested, nil
+}
+
+// holdsSpot reports whether an RSVP counts towards a member's RSVP limits
+func holdsSpot(status models.RSVPStatus) bool {
+	return status == models.RSVPStatusIn || status == models.RSVPStatusRequested
+}
+
+// checkRSVPLimits stops a member RSVPing in to session past their tier's or
+// the club's weekly limit, or the club's limit per recurring series. Requests
+// awaiting approval count; cancelled sessions don't. Admins aren't limited
+// when adding members themselves, which is how they override a limit.
+//
+// It runs in tx with the session locked, and locks the member so their RSVPs
+// to other sessions are counted one at a time; otherwise two at once could
+// each see room for one more.
+func checkRSVPLimits(tx *gorm.DB, userID uuid.UUID, session models.Session) error {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "tier").First(&user, "id = ?", userID).Error; err != nil {
+		return err
+	}
+	var club models.Club
+	if err := tx.First(&club).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err := checkWeeklyQuota(tx, user, session, club.MaxRSVPsPerWeek); err != nil {
+		return err
+	}
+	return checkSeriesQuota(tx, user.ID, session, club.MaxRSVPsPerSeries)
+}
+
+// checkWeeklyQuota stops a member RSVPing in to more sessions in session's
+// calendar week (Monday to Sunday) than their tier or the club's cap allows,
+// whichever is lower
+func checkWeeklyQuota(tx *gorm.DB, user models.User, session models.Session, clubCap int) error {
+	quota := user.Tier.WeeklyRSVPQuota()
+	limitedBy := fmt.Sprintf("the most your %s membership allows", strings.ReplaceAll(string(user.Tier), "_", "-"))
+	if clubCap > 0 && (quota == 0 || clubCap < quota) {
+		quota, limitedBy = clubCap, "the most the club allows"
+	}
+	if quota == 0 {
+		return nil
+	}
+
+	weekStart := utils.StartOfWeek(session.SessionDate)
+	taken, err := sessionsHeldBy(tx, user.ID, session.ID, func(q *gorm.DB) *gorm.DB {
+		return q.Where("sessions.session_date >= ? AND sessions.session_date < ?", weekStart, weekStart.AddDate(0, 0, 7))
+	})
+	if err != nil {
+		return err
+	}
+	if len(taken) < quota {
+		return nil
+	}
+	return fmt.Errorf("%w: you have used %d/%d sessions this week (%s), %s",
+		ErrWeeklyQuotaReached, len(taken), quota, sessionDates(taken), limitedBy)
+}
+
+// checkSeriesQuota stops a member being in for more than clubCap upcoming
+// sessions of session's recurring series, so nobody books out every week of
+// a series ahead of everyone else. One-off sessions aren't limited.
+func checkSeriesQuota(tx *gorm.DB, userID uuid.UUID, session models.Session, clubCap int) error {
+	seriesID := session.RecurringParentID
+	if seriesID == nil && session.IsRecurring {
+		seriesID = &session.ID
+	}
+	if clubCap == 0 || seriesID == nil {
+		return nil
+	}
+
+	taken, err := sessionsHeldBy(tx, userID, session.ID, func(q *gorm.DB) *gorm.DB {
+		return q.Where("sessions.id = ? OR sessions.recurring_parent_id = ?", *seriesID, *seriesID).
+			Where("sessions.starts_at > ?", time.Now())
+	})
+	if err != nil {
+		return err
+	}
+	if len(taken) < clubCap {
+		return nil
+	}
+	return fmt.Errorf("%w: you have used %d/%d upcoming sessions in this series (%s), the most the club allows",
+		ErrSeriesQuotaReached, len(taken), clubCap, sessionDates(taken))
+}
+
+// sessionsHeldBy returns the sessions other than excludeID, narrowed by
+// scope, that userID is in for or has requested, soonest first
+func sessionsHeldBy(tx *gorm.DB, userID, excludeID uuid.UUID, scope func(*gorm.DB) *gorm.DB) ([]models.Session, error) {
+	var sessions []models.Session
+	err := scope(tx.Model(&models.Session{}).
+		Joins("JOIN rsvps ON rsvps.session_id = sessions.id").
+		Where("rsvps.user_id = ? AND rsvps.status IN ?", userID,
+			[]models.RSVPStatus{models.RSVPStatusIn, models.RSVPStatusRequested}).
+		Where("sessions.id != ? AND sessions.status != ?", excludeID, models.SessionStatusCancelled)).
+		Order("sessions.starts_at ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// sessionDates lists sessions' dates for an error message: "Tue 3 Mar, Thu
+// 5 Mar and Sat 7 Mar"
+func sessionDates(sessions []models.Session) string {
+	dates := make([]string, len(sessions))
+	for i, session := range sessions {
+		dates[i] = session.SessionDate.In(utils.SydneyLocation).Format("Mon 2 Jan")
+	}
+	if len(dates) < 2 {
+		return strings.Join(dates, "")
+	}
+	return strings.Join(dates[:len(dates)-1], ", ") + " and " + dates[len(dates)-1]
+}
+
+// checkFirstRSVPAllowed stops a member's first ever RSVP until they have
+// acknowledged every required club document
+func (s *RSVPService) checkFirstRSVPAllowed(userID uuid.UUID) error {
+	var previous int64
+	if err := database.DB.Model(&models.RSVP{}).Where("user_id = ?", userID).Count(&previous).Error; err != nil {
+		return err
+	}
+	if previous > 0 {
+		return nil
+	}
+
+	pending, err := s.documentService.PendingRequiredDocuments(userID)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return ErrDocumentsNotAcknowledged
+	}
+	return nil
+}
+
 // DeleteRSVP removes an RSVP
 func (s *RSVPService) DeleteRSVP(sessionID, userID uuid.UUID, byAdmin bool) error {
 	// Get the session
 	var session models.Session
 	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
-		return errors.New("session not found")
+		return ErrSessionNotFound
 	}
 
 	// Get the RSVP
 	var rsvp models.RSVP
 	if err := database.DB.Where("session_id = ? AND user_id = ?", sessionID, userID).First(&rsvp).Error; err != nil {
-		return errors.New("RSVP not found")
+		return ErrRSVPNotFound
 	}
 
 	now := utils.NowInSydney()
@@ -111,10 +355,34 @@ func (s *RSVPService) DeleteRSVP(sessionID, userID uuid.UUID, byAdmin bool) erro
 
 	// Check if user is trying to delete IN RSVP after deadline
 	if !byAdmin && isLate && rsvp.Status == models.RSVPStatusIn {
-		return errors.New("cannot remove IN RSVP after deadline")
+		return ErrRSVPRemoveLocked
 	}
 
-	return database.DB.Delete(&rsvp).Error
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := lockSession(tx, sessionID)
+		if err != nil {
+			return ErrSessionNotFound
+		}
+		freed, err := vacate(tx, session, userID, rsvp.Status)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&rsvp).Error; err != nil {
+			return err
+		}
+		event := RSVPEvent{ByAdmin: byAdmin}
+		if freed {
+			if event.Offered, err = offerOpenSpots(tx, session); err != nil {
+				return err
+			}
+		}
+		return recordEvent(tx, rsvpEvent(models.EventRSVPChanged, sessionID, userID, event))
+	})
+	if err != nil {
+		return err
+	}
+	s.outbox.Dispatch()
+	return nil
 }
 
 // GetRSVPsForSession returns all RSVPs for a session, ordered by timestamp
@@ -139,54 +407,92 @@ func (s *RSVPService) GetUserRSVPForSession(sessionID, userID uuid.UUID) (*model
 	return &rsvp, nil
 }
 
+// GetUserRSVPs returns a user's RSVPs for sessions dated within [from, to].
+// Either bound may be nil.
+func (s *RSVPService) GetUserRSVPs(userID uuid.UUID, from, to *time.Time) ([]models.RSVP, error) {
+	query := database.DB.Joins("JOIN sessions ON sessions.id = rsvps.session_id").
+		Where("rsvps.user_id = ?", userID)
+	if from != nil {
+		query = query.Where("sessions.session_date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("sessions.session_date <= ?", *to)
+	}
+
+	var rsvps []models.RSVP
+	if err := query.Order("sessions.starts_at ASC").Find(&rsvps).Error; err != nil {
+		return nil, err
+	}
+	return rsvps, nil
+}
+
+// GetUserRSVPsForSessions returns a user's RSVPs for the given sessions, keyed by session ID
+func (s *RSVPService) GetUserRSVPsForSessions(userID uuid.UUID, sessionIDs []uuid.UUID) (map[uuid.UUID]models.RSVP, error) {
+	result := make(map[uuid.UUID]models.RSVP)
+	if len(sessionIDs) == 0 {
+		return result, nil
+	}
+
+	var rsvps []models.RSVP
+	if err := database.DB.Where("user_id = ? AND session_id IN ?", userID, sessionIDs).
+		Find(&rsvps).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rsvps {
+		result[r.SessionID] = r
+	}
+	return result, nil
+}
+
 // RSVPSummary contains summary statistics for a session's RSVPs
 type RSVPSummary struct {
-	TotalIn    int `json:"total_in"`
-	TotalOut   int `json:"total_out"`
-	TotalMaybe int `json:"total_maybe"`
-	MaxPlayers int `json:"max_players"`
-	SpotsLeft  int `json:"spots_left"`
+	TotalIn        int `json:"total_in"`
+	TotalOut       int `json:"total_out"`
+	TotalMaybe     int `json:"total_maybe"`
+	TotalRequested int `json:"total_requested"` // awaiting approval
+	TotalWaitlist  int `json:"total_waitlist"`
+	TotalGuests    int `json:"total_guests"` // each takes a spot
+	MaxPlayers     int `json:"max_players"`
+	Overbooking    int `json:"overbooking"` // extra INs taken until RSVPs close
+	SpotsLeft      int `json:"spots_left"`
 }
 
-// GetRSVPSummary returns summary statistics for a session
+// GetRSVPSummary returns summary statistics for a session, from the RSVP
+// counters kept on its row
 func (s *RSVPService) GetRSVPSummary(sessionID uuid.UUID) (*RSVPSummary, error) {
 	var session models.Session
 	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
 		return nil, err
 	}
 
-	var inCount, outCount, maybeCount int64
-
-	database.DB.Model(&models.RSVP{}).
-		Where("session_id = ? AND status = ?", sessionID, models.RSVPStatusIn).
-		Count(&inCount)
-
-	database.DB.Model(&models.RSVP{}).
-		Where("session_id = ? AND status = ?", sessionID, models.RSVPStatusOut).
-		Count(&outCount)
-
-	database.DB.Model(&models.RSVP{}).
-		Where("session_id = ? AND status = ?", sessionID, models.RSVPStatusMaybe).
-		Count(&maybeCount)
+	summary := SummarizeRSVPs(&session)
+	return &summary, nil
+}
 
-	spotsLeft := session.MaxPlayers - int(inCount)
-	if spotsLeft < 0 {
-		spotsLeft = 0
+// SummarizeRSVPs returns summary statistics for a session already loaded,
+// so a list of sessions needs no queries for them
+func SummarizeRSVPs(session *models.Session) RSVPSummary {
+	return RSVPSummary{
+		TotalIn:        session.ConfirmedCount,
+		TotalOut:       session.OutCount,
+		TotalMaybe:     session.MaybeCount,
+		TotalRequested: session.RequestedCount,
+		TotalWaitlist:  session.WaitlistCount,
+		TotalGuests:    session.GuestCount,
+		MaxPlayers:     session.MaxPlayers,
+		Overbooking:    session.Overbooking,
+		SpotsLeft:      max(session.RSVPCapacity()-session.ConfirmedCount-session.GuestCount, 0),
 	}
-
-	return &RSVPSummary{
-		TotalIn:    int(inCount),
-		TotalOut:   int(outCount),
-		TotalMaybe: int(maybeCount),
-		MaxPlayers: session.MaxPlayers,
-		SpotsLeft:  spotsLeft,
-	}, nil
 }
 
 // GetConfirmedPlayers returns players who have RSVP'd IN, ordered by timestamp
 func (s *RSVPService) GetConfirmedPlayers(sessionID uuid.UUID) ([]models.RSVP, error) {
+	return confirmedPlayers(database.DB, sessionID)
+}
+
+func confirmedPlayers(db *gorm.DB, sessionID uuid.UUID) ([]models.RSVP, error) {
 	var rsvps []models.RSVP
-	if err := database.DB.Where("session_id = ? AND status = ?", sessionID, models.RSVPStatusIn).
+	if err := db.Where("session_id = ? AND status = ?", sessionID, models.RSVPStatusIn).
 		Preload("User").
 		Order("rsvp_timestamp ASC").
 		Find(&rsvps).Error; err != nil {
@@ -194,3 +500,80 @@ func (s *RSVPService) GetConfirmedPlayers(sessionID uuid.UUID) ([]models.RSVP, e
 	}
 	return rsvps, nil
 }
+
+// AdminRemoveRSVP removes a player's RSVP, or changes it to newStatus if given.
+// The player is told why, and a spot they give up is offered to the next in
+// line.
+func (s *RSVPService) AdminRemoveRSVP(sessionID, userID uuid.UUID, newStatus *models.RSVPStatus, reason string) error {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return ErrSessionNotFound
+	}
+
+	var rsvp models.RSVP
+	if err := database.DB.Where("session_id = ? AND user_id = ?", sessionID, userID).First(&rsvp).Error; err != nil {
+		return ErrRSVPNotFound
+	}
+	if newStatus != nil && *newStatus == rsvp.Status {
+		return domainError(ErrConflict, "rsvp_status_unchanged", fmt.Sprintf("RSVP is already %s", rsvp.Status))
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := lockSession(tx, sessionID)
+		if err != nil {
+			return ErrSessionNotFound
+		}
+		freed, err := vacate(tx, session, userID, rsvp.Status)
+		if err != nil {
+			return err
+		}
+
+		if newStatus != nil {
+			rsvp.Status = *newStatus
+			rsvp.AddedByAdmin = true
+			rsvp.UpdatedAt = time.Now()
+			if err := tx.Save(&rsvp).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Delete(&rsvp).Error; err != nil {
+			return err
+		}
+
+		event := RSVPEvent{Status: newStatus, ByAdmin: true, Reason: reason}
+		if freed {
+			if event.Offered, err = offerOpenSpots(tx, session); err != nil {
+				return err
+			}
+		}
+		return recordEvent(tx, rsvpEvent(models.EventRSVPRemoved, sessionID, userID, event),
+			removedNotice(session, userID, newStatus, reason))
+	})
+	if err != nil {
+		return err
+	}
+	s.outbox.Dispatch()
+
+	return nil
+}
+
+// removedNotice tells a player an admin removed or changed their RSVP
+func removedNotice(session models.Session, userID uuid.UUID, newStatus *models.RSVPStatus, reason string) OutboxNotification {
+	dateStr := utils.FormatDateForDisplay(session.SessionDate)
+	title := "RSVP Updated"
+	body := fmt.Sprintf("An admin removed your RSVP for %s on %s.", session.Title, dateStr)
+	if newStatus != nil {
+		body = fmt.Sprintf("An admin changed your RSVP for %s on %s to %s.", session.Title, dateStr, *newStatus)
+	}
+	if reason != "" {
+		body += " Reason: " + reason
+	}
+	data := map[string]string{
+		"type":       string(models.NotificationRSVPChanged),
+		"session_id": session.ID.String(),
+	}
+
+	return OutboxNotification{
+		Type:     models.NotificationRSVPChanged,
+		Messages: []NotificationMessage{{UserID: userID, Title: title, Body: body, Data: data}},
+	}
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+// newRSVPService returns an RSVP service whose notifications go nowhere
+func newRSVPService() *RSVPService {
+	notifications := NewNotificationService(NotificationConfig{})
+	return NewRSVPService(notifications, NewDocumentService(nil), NewOutbox(notifications, nil, ""))
+}
+
+func rsvpAs(service *RSVPService, session *models.Session, user *models.User, status models.RSVPStatus) (*models.RSVP, error) {
+	return service.CreateOrUpdateRSVP(RSVPInput{SessionID: session.ID, UserID: user.ID, Status: status}, false)
+}
+
+func setTier(t *testing.T, user *models.User, tier models.MembershipTier) {
+	t.Helper()
+	if err := database.DB.Model(user).Update("tier", tier).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func setClubCaps(t *testing.T, perWeek, perSeries int) {
+	t.Helper()
+	if err := database.DB.Model(&models.Club{}).Where("1 = 1").
+		Updates(map[string]interface{}{"max_rsvps_per_week": perWeek, "max_rsvps_per_series": perSeries}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sessionsOnOneDay creates n sessions starting at the same time, so they
+// fall in the same week
+func sessionsOnOneDay(t *testing.T, n int) []*models.Session {
+	t.Helper()
+	sessions := make([]*models.Session, n)
+	for i := range sessions {
+		sessions[i] = newSession(t, 72*time.Hour, 8)
+	}
+	return sessions
+}
+
+func TestWeeklyQuotaByTier(t *testing.T) {
+	testDB(t)
+	service := newRSVPService()
+	member := newMember(t, "Member")
+	setTier(t, member, models.TierRegular)
+	sessions := sessionsOnOneDay(t, 2)
+
+	if _, err := rsvpAs(service, sessions[0], member, models.RSVPStatusIn); err != nil {
+		t.Fatal(err)
+	}
+	_, err := rsvpAs(service, sessions[1], member, models.RSVPStatusIn)
+	if !errors.Is(err, ErrWeeklyQuotaReached) || !strings.Contains(err.Error(), "1/1") {
+		t.Fatalf("second RSVP this week = %v, want the weekly quota reached with 1/1", err)
+	}
+
+	// Maybe doesn't hold a spot, and admins can go past the quota
+	if _, err := rsvpAs(service, sessions[1], member, models.RSVPStatusMaybe); err != nil {
+		t.Errorf("RSVPing maybe past the quota: %v", err)
+	}
+	if _, err := service.CreateOrUpdateRSVP(RSVPInput{SessionID: sessions[1].ID, UserID: member.ID, Status: models.RSVPStatusIn}, true); err != nil {
+		t.Errorf("admin adding the member past their quota: %v", err)
+	}
+}
+
+func TestClubWeeklyCapBelowTier(t *testing.T) {
+	testDB(t)
+	service := newRSVPService()
+	member := newMember(t, "Member")
+	setClubCaps(t, 1, 0)
+	sessions := sessionsOnOneDay(t, 2)
+
+	if _, err := rsvpAs(service, sessions[0], member, models.RSVPStatusIn); err != nil {
+		t.Fatal(err)
+	}
+	_, err := rsvpAs(service, sessions[1], member, models.RSVPStatusIn)
+	if !errors.Is(err, ErrWeeklyQuotaReached) || !strings.Contains(err.Error(), "the most the club allows") {
+		t.Errorf("RSVP past the club's cap = %v, want the club's weekly quota reached", err)
+	}
+}
+
+// TestWeeklyQuotaConcurrent RSVPs one member in to several sessions of a
+// week at once; no more than their quota may go through
+func TestWeeklyQuotaConcurrent(t *testing.T) {
+	testDB(t)
+	service := newRSVPService()
+	member := newMember(t, "Member")
+	setTier(t, member, models.TierTwiceAWeek)
+	sessions := sessionsOnOneDay(t, 5)
+
+	errs := concurrently(len(sessions), func(i int) error {
+		_, err := rsvpAs(service, sessions[i], member, models.RSVPStatusIn)
+		return err
+	})
+	in := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			in++
+		case !errors.Is(err, ErrWeeklyQuotaReached):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if in != 2 {
+		t.Errorf("in for %d sessions, want 2", in)
+	}
+}
+
+func TestSeriesQuota(t *testing.T) {
+	testDB(t)
+	service := newRSVPService()
+	member := newMember(t, "Member")
+	setClubCaps(t, 0, 2)
+
+	// A weekly series of four
+	series := make([]*models.Session, 4)
+	for i := range series {
+		series[i] = newSession(t, time.Duration(i+1)*7*24*time.Hour, 8)
+		updates := map[string]interface{}{"is_recurring": true}
+		if i > 0 {
+			updates["recurring_parent_id"] = series[0].ID
+		}
+		if err := database.DB.Model(series[i]).Updates(updates).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	oneOff := newSession(t, 8*24*time.Hour, 8)
+
+	for _, session := range series[:2] {
+		if _, err := rsvpAs(service, session, member, models.RSVPStatusIn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := rsvpAs(service, series[2], member, models.RSVPStatusIn); !errors.Is(err, ErrSeriesQuotaReached) {
+		t.Errorf("third RSVP in the series = %v, want the series quota reached", err)
+	}
+	if _, err := rsvpAs(service, oneOff, member, models.RSVPStatusIn); err != nil {
+		t.Errorf("RSVP to a one-off session: %v", err)
+	}
+}
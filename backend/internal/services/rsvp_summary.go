@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+// playersPerDoublesCourt is how many players keep a court busy
+const playersPerDoublesCourt = 4
+
+// checkRSVPSummaries sends admins a summary of each session whose RSVP
+// deadline has passed since the last run. Sessions that have already started
+// are skipped, so a summary is never sent for a session that's over.
+func (s *SchedulerService) checkRSVPSummaries(report *JobReport) error {
+	now := time.Now()
+
+	var sessions []models.Session
+	if err := database.DB.Where(
+		"rsvp_deadline <= ? AND starts_at > ? AND status != ? AND rsvp_summary_sent_at IS NULL",
+		now, now, models.SessionStatusCancelled,
+	).Order("starts_at ASC").Find(&sessions).Error; err != nil {
+		return fmt.Errorf("fetching sessions for RSVP summaries: %w", err)
+	}
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	var club models.Club
+	database.DB.First(&club)
+
+	var adminIDs []uuid.UUID
+	if err := database.DB.Model(&models.User{}).
+		Where("role = ? AND membership_status = ?", models.RoleAdmin, models.MembershipApproved).
+		Pluck("id", &adminIDs).Error; err != nil {
+		return fmt.Errorf("fetching admins for RSVP summaries: %w", err)
+	}
+
+	var errs []error
+	for _, session := range sessions {
+		recipients := adminIDs
+		if club.RSVPSummaryToOrganizer {
+			recipients = withRecipient(adminIDs, session.CreatedBy)
+		}
+		errs = append(errs, s.sendRSVPSummary(context.Background(), session, recipients, report))
+	}
+	return errors.Join(errs...)
+}
+
+// withRecipient returns ids with id added, unless it's already there or unset
+func withRecipient(ids []uuid.UUID, id uuid.UUID) []uuid.UUID {
+	if id == uuid.Nil {
+		return ids
+	}
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(append([]uuid.UUID{}, ids...), id)
+}
+
+func (s *SchedulerService) sendRSVPSummary(ctx context.Context, session models.Session, recipients []uuid.UUID, report *JobReport) error {
+	var rsvps []models.RSVP
+	if err := database.DB.Preload("User").
+		Where("session_id = ? AND status IN ?", session.ID,
+			[]models.RSVPStatus{models.RSVPStatusIn, models.RSVPStatusRequested}).
+		Order("rsvp_timestamp ASC").
+		Find(&rsvps).Error; err != nil {
+		return fmt.Errorf("fetching RSVPs for session %s: %w", session.ID, err)
+	}
+
+	summary := RSVPSummaryContext{
+		Session:    session,
+		Date:       utils.FormatDateForDisplay(session.SessionDate),
+		SessionURL: s.notificationService.SessionURL(session.ID),
+	}
+	for _, rsvp := range rsvps {
+		name := "A former member"
+		if rsvp.User != nil {
+			name = rsvp.User.Name
+		}
+		if rsvp.Status == models.RSVPStatusRequested {
+			summary.AwaitingApproval = append(summary.AwaitingApproval, name)
+		} else {
+			summary.Confirmed = append(summary.Confirmed, name)
+		}
+	}
+
+	waitlist, err := waitlistFor(database.DB, session.ID)
+	if err != nil {
+		return fmt.Errorf("fetching waitlist for session %s: %w", session.ID, err)
+	}
+	for _, entry := range waitlist {
+		name := "A former member"
+		if entry.User != nil {
+			name = entry.Name
+		}
+		summary.Waitlist = append(summary.Waitlist, name)
+	}
+
+	messages, err := loadMessage(models.MessageRSVPSummary).messagesFor(recipients, func(lang models.Language) interface{} {
+		s := summary
+		s.Warnings = rsvpShortfalls(lang, session, len(summary.Confirmed), len(summary.Waitlist))
+		return s
+	}, map[string]string{
+		"type":       string(models.NotificationRSVPSummary),
+		"session_id": session.ID.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("rendering RSVP summary for session %s: %w", session.ID, err)
+	}
+
+	report.addNotifications(models.NotificationRSVPSummary, session.ID, messages)
+	if report.isDryRun() {
+		return nil
+	}
+
+	if len(messages) > 0 {
+		if _, err := s.notificationService.SendBatch(ctx, models.NotificationRSVPSummary, messages); err != nil {
+			return fmt.Errorf("sending RSVP summary for session %s: %w", session.ID, err)
+		}
+	}
+	// Marked without touching updated_at, which clients sync sessions by
+	if err := database.DB.Model(&models.Session{}).Where("id = ?", session.ID).
+		UpdateColumn("rsvp_summary_sent_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("marking RSVP summary sent for session %s: %w", session.ID, err)
+	}
+	log.Printf("Sent RSVP summary for session %s to %d admins", session.Title, len(messages))
+	return nil
+}
+
+// shortfallWording is how the RSVP summary's warnings read in one language
+type shortfallWording struct {
+	nobody   string
+	tooFew   string // players, courts, players per court
+	waitlist string // players waiting
+}
+
+var shortfallWordings = map[models.Language]shortfallWording{
+	models.LanguageEnglish: {
+		nobody:   "nobody is confirmed",
+		tooFew:   "%d players for %d courts (%d per court)",
+		waitlist: "%d on the waitlist who another court could take",
+	},
+	models.LanguageChinese: {
+		nobody:   "没有人确认参加",
+		tooFew:   "%d 人用 %d 片场地（每片需 %d 人）",
+		waitlist: "%d 人在候补，可以考虑加一片场地",
+	},
+}
+
+// rsvpShortfalls warns, in lang, about numbers that don't suit a session's
+// courts: too few players to keep every court busy, or people left waiting
+// who another court would take
+func rsvpShortfalls(lang models.Language, session models.Session, confirmed, waiting int) []string {
+	wording, ok := shortfallWordings[lang]
+	if !ok {
+		wording = shortfallWordings[models.DefaultLanguage]
+	}
+
+	var warnings []string
+	switch {
+	case confirmed == 0:
+		warnings = append(warnings, wording.nobody)
+	case confirmed < session.Courts*playersPerDoublesCourt:
+		warnings = append(warnings, fmt.Sprintf(wording.tooFew, confirmed, session.Courts, playersPerDoublesCourt))
+	}
+	if waiting > 0 && session.Courts < models.MaxCourts {
+		warnings = append(warnings, fmt.Sprintf(wording.waitlist, waiting))
+	}
+	return warnings
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+// RSVPTimelinePoint is the state of a session's RSVPs at the end of an hour
+type RSVPTimelinePoint struct {
+	Hour   time.Time `json:"hour"`   // start of the hour
+	In     int       `json:"in"`     // members in by the end of it
+	Joined int       `json:"joined"` // went in during the hour
+	Left   int       `json:"left"`   // dropped out during the hour
+}
+
+// RSVPTimeline is how a session filled up, hour by hour
+type RSVPTimeline struct {
+	SessionID   uuid.UUID  `json:"session_id"`
+	StartsAt    time.Time  `json:"starts_at"`
+	MaxPlayers  int        `json:"max_players"`
+	FirstRSVPAt *time.Time `json:"first_rsvp_at,omitempty"`
+	FilledAt    *time.Time `json:"filled_at,omitempty"` // the hour it first reached MaxPlayers
+	// Some RSVPs predate the RSVP history; they're counted from when the
+	// member first RSVP'd, at their current status
+	Approximate bool                `json:"approximate"`
+	Points      []RSVPTimelinePoint `json:"points"`
+}
+
+// rsvpTimelineEvent is a change to one member's RSVP
+type rsvpTimelineEvent struct {
+	UserID uuid.UUID
+	Status models.RSVPStatus
+	At     time.Time
+}
+
+// Timeline replays a session's RSVP history into the cumulative number of
+// members in at the end of each hour, from the first RSVP until the session
+// starts (or now, or the last change, whichever is later)
+func (s *RSVPService) Timeline(sessionID uuid.UUID) (*RSVPTimeline, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, err
+	}
+
+	var logged []models.RSVPEvent
+	if err := database.DB.Where("session_id = ?", sessionID).
+		Order("occurred_at, id").Find(&logged).Error; err != nil {
+		return nil, err
+	}
+	events := make([]rsvpTimelineEvent, 0, len(logged))
+	hasHistory := make(map[uuid.UUID]bool, len(logged))
+	for _, e := range logged {
+		events = append(events, rsvpTimelineEvent{UserID: e.UserID, Status: e.Status, At: e.OccurredAt})
+		hasHistory[e.UserID] = true
+	}
+
+	// RSVPs from before the history was kept, live or archived
+	var current []models.RSVP
+	if err := database.DB.Where("session_id = ?", sessionID).Find(&current).Error; err != nil {
+		return nil, err
+	}
+	var archived []models.ArchivedRSVP
+	if err := database.DB.Where("session_id = ?", sessionID).Find(&archived).Error; err != nil {
+		return nil, err
+	}
+	approximate := false
+	seed := func(userID uuid.UUID, status models.RSVPStatus, at time.Time) {
+		if hasHistory[userID] {
+			return
+		}
+		approximate = true
+		events = append(events, rsvpTimelineEvent{UserID: userID, Status: status, At: at})
+	}
+	for _, r := range current {
+		seed(r.UserID, r.Status, r.RSVPTimestamp)
+	}
+	for _, r := range archived {
+		seed(r.UserID, r.Status, r.RSVPTimestamp)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+
+	timeline := &RSVPTimeline{
+		SessionID:   session.ID,
+		StartsAt:    session.StartsAt,
+		MaxPlayers:  session.MaxPlayers,
+		Approximate: approximate,
+		Points:      []RSVPTimelinePoint{},
+	}
+	if len(events) == 0 {
+		return timeline, nil
+	}
+
+	first := events[0].At
+	timeline.FirstRSVPAt = &first
+	end := events[len(events)-1].At
+	cutoff := session.StartsAt
+	if now := time.Now(); now.Before(cutoff) {
+		cutoff = now
+	}
+	if cutoff.After(end) {
+		end = cutoff
+	}
+
+	statuses := map[uuid.UUID]models.RSVPStatus{}
+	in := 0
+	next := 0
+	for hour := first.Truncate(time.Hour); !hour.After(end); hour = hour.Add(time.Hour) {
+		point := RSVPTimelinePoint{Hour: hour}
+		until := hour.Add(time.Hour)
+		for ; next < len(events) && events[next].At.Before(until); next++ {
+			e := events[next]
+			was := statuses[e.UserID] == models.RSVPStatusIn
+			now := e.Status == models.RSVPStatusIn
+			switch {
+			case now && !was:
+				in++
+				point.Joined++
+			case was && !now:
+				in--
+				point.Left++
+			}
+			if e.Status == "" {
+				delete(statuses, e.UserID)
+			} else {
+				statuses[e.UserID] = e.Status
+			}
+		}
+		point.In = in
+		if timeline.FilledAt == nil && session.MaxPlayers > 0 && in >= session.MaxPlayers {
+			filled := hour
+			timeline.FilledAt = &filled
+		}
+		timeline.Points = append(timeline.Points, point)
+	}
+	return timeline, nil
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,13 +18,49 @@ import (
 type SchedulerService struct {
 	cron                *cron.Cron
 	notificationService *NotificationService
-	reminderHours24     int
-	reminderHours12     int
-	deadlineHours       int
+	badgeService        *BadgeService
+	allocationService   *AllocationService
+	sessionService      *SessionService
+	rsvpService         *RSVPService
+	announcementService *AnnouncementService
+	archiveService      *ArchiveService
+	inactivityService   *MemberInactivityService
+	orderService        *OrderService
+	statsService        *StatsService
+	jobs                *JobService
+	changeDigest        *SessionChangeDigest
+	outbox              *Outbox
+	backupService       *BackupService // nil disables backups
+	nightlyBackup       bool
+	weather             WeatherProvider // nil disables forecasts
+	weatherProposals    *WeatherProposalService
+	contactLogService   *ContactLogService
+
+	mu              sync.RWMutex
+	reminderHours24 int
+	reminderHours12 int
+	deadlineHours   int
 }
 
 type SchedulerConfig struct {
 	NotificationService    *NotificationService
+	BadgeService           *BadgeService
+	AllocationService      *AllocationService
+	SessionService         *SessionService
+	RSVPService            *RSVPService
+	AnnouncementService    *AnnouncementService
+	ArchiveService         *ArchiveService
+	InactivityService      *MemberInactivityService
+	OrderService           *OrderService
+	StatsService           *StatsService
+	JobService             *JobService
+	SessionChangeDigest    *SessionChangeDigest
+	Outbox                 *Outbox
+	BackupService          *BackupService
+	NightlyBackup          bool // back up the database every night, not just on demand
+	Weather                WeatherProvider
+	WeatherProposals       *WeatherProposalService
+	ContactLogService      *ContactLogService
 	SessionReminderHours24 int
 	SessionReminderHours12 int
 	DeadlineReminderHours  int
@@ -30,9 +68,31 @@ type SchedulerConfig struct {
 
 // NewSchedulerService creates a new scheduler service for notification cron jobs
 func NewSchedulerService(cfg SchedulerConfig) *SchedulerService {
+	jobs := cfg.JobService
+	if jobs == nil {
+		jobs = NewJobService("")
+	}
 	return &SchedulerService{
-		cron:                cron.New(cron.WithSeconds()),
+		// Daily jobs run at club time, whatever the server's timezone
+		cron:                cron.New(cron.WithSeconds(), cron.WithLocation(utils.SydneyLocation)),
 		notificationService: cfg.NotificationService,
+		badgeService:        cfg.BadgeService,
+		allocationService:   cfg.AllocationService,
+		sessionService:      cfg.SessionService,
+		rsvpService:         cfg.RSVPService,
+		announcementService: cfg.AnnouncementService,
+		archiveService:      cfg.ArchiveService,
+		inactivityService:   cfg.InactivityService,
+		orderService:        cfg.OrderService,
+		statsService:        cfg.StatsService,
+		jobs:                jobs,
+		changeDigest:        cfg.SessionChangeDigest,
+		outbox:              cfg.Outbox,
+		backupService:       cfg.BackupService,
+		nightlyBackup:       cfg.NightlyBackup,
+		weather:             cfg.Weather,
+		weatherProposals:    cfg.WeatherProposals,
+		contactLogService:   cfg.ContactLogService,
 		reminderHours24:     cfg.SessionReminderHours24,
 		reminderHours12:     cfg.SessionReminderHours12,
 		deadlineHours:       cfg.DeadlineReminderHours,
@@ -43,20 +103,329 @@ func NewSchedulerService(cfg SchedulerConfig) *SchedulerService {
 func (s *SchedulerService) Start() {
 	// Run every hour at minute 0 to check for reminders
 	// This runs at :00 of each hour
-	_, err := s.cron.AddFunc("0 0 * * * *", func() {
-		s.checkSessionReminders()
-		s.checkDeadlineReminders()
-	})
+	_, err := s.cron.AddFunc("0 0 * * * *", s.runHourlyJobs)
 	if err != nil {
 		log.Printf("Failed to add cron job: %v", err)
 		return
 	}
 
+	// Evaluate player milestones once a day at 03:00
+	if s.badgeService != nil {
+		_, err = s.cron.AddFunc("0 0 3 * * *", func() {
+			s.jobs.Run(JobBadgeEvaluation, func() error {
+				return s.badgeService.EvaluateBadges(context.Background())
+			})
+		})
+		if err != nil {
+			log.Printf("Failed to add badge cron job: %v", err)
+		}
+	}
+
+	// Send coalesced session change notices as their windows close
+	if s.changeDigest != nil {
+		_, err = s.cron.AddFunc("0 * * * * *", func() {
+			if err := s.changeDigest.Flush(context.Background()); err != nil {
+				log.Printf("Error flushing session change notices: %v", err)
+			}
+		})
+		if err != nil {
+			log.Printf("Failed to add session change digest cron job: %v", err)
+		}
+	}
+
+	// Retry outbox events whose delivery failed or whose dispatcher stopped
+	// mid-send, and clear out delivered ones nightly at 04:15
+	if s.outbox != nil {
+		_, err = s.cron.AddFunc("0 * * * * *", func() {
+			if err := s.outbox.Flush(context.Background()); err != nil {
+				log.Printf("Error flushing outbox events: %v", err)
+			}
+		})
+		if err != nil {
+			log.Printf("Failed to add outbox cron job: %v", err)
+		}
+		_, err = s.cron.AddFunc("0 15 4 * * *", func() {
+			if err := s.outbox.PruneDelivered(); err != nil {
+				log.Printf("Error pruning delivered outbox events: %v", err)
+			}
+		})
+		if err != nil {
+			log.Printf("Failed to add outbox pruning cron job: %v", err)
+		}
+	}
+
+	// Pass spots offered off a waitlist down the line once their offers lapse
+	if s.rsvpService != nil {
+		_, err = s.cron.AddFunc("0 * * * * *", func() {
+			if err := s.rsvpService.ExpireWaitlistOffers(); err != nil {
+				log.Printf("Error lapsing waitlist offers: %v", err)
+			}
+		})
+		if err != nil {
+			log.Printf("Failed to add waitlist offer cron job: %v", err)
+		}
+	}
+
+	// Recount the RSVPs of sessions whose counters have drifted nightly at
+	// 04:45, after archiving
+	if s.rsvpService != nil {
+		_, err = s.cron.AddFunc("0 45 4 * * *", func() {
+			s.jobs.Run(JobRSVPCounts, func() error {
+				return s.rsvpService.ReconcileRSVPCounts(nil)
+			})
+		})
+		if err != nil {
+			log.Printf("Failed to add RSVP count cron job: %v", err)
+		}
+	}
+
+	// Close group orders as their closing time passes
+	if s.orderService != nil {
+		_, err = s.cron.AddFunc("0 * * * * *", func() {
+			if err := s.orderService.CloseDueWindows(context.Background()); err != nil {
+				log.Printf("Error closing group orders: %v", err)
+			}
+		})
+		if err != nil {
+			log.Printf("Failed to add group order cron job: %v", err)
+		}
+	}
+
+	// Send emails held outside the club's email window once it opens
+	_, err = s.cron.AddFunc("0 */5 * * * *", func() {
+		if err := s.notificationService.FlushQueuedEmails(context.Background()); err != nil {
+			log.Printf("Error sending queued emails: %v", err)
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to add queued email cron job: %v", err)
+	}
+
+	// Back up the database nightly at 01:30, clear of the 02:00-03:00 hour
+	// daylight saving skips or repeats
+	if s.backupService != nil && s.nightlyBackup {
+		_, err = s.cron.AddFunc("0 30 1 * * *", func() {
+			s.jobs.Run(JobDatabaseBackup, func() error {
+				_, err := s.backupService.Backup(context.Background(), nil)
+				return err
+			})
+		})
+		if err != nil {
+			log.Printf("Failed to add database backup cron job: %v", err)
+		}
+	}
+
+	// Keep recurring series filled to the club's look-ahead window, nightly at 01:00
+	if s.sessionService != nil {
+		_, err = s.cron.AddFunc("0 0 1 * * *", func() {
+			s.jobs.Run(JobRecurringSessions, s.sessionService.RefreshRecurringSessions)
+		})
+		if err != nil {
+			log.Printf("Failed to add recurring sessions cron job: %v", err)
+		}
+	}
+
+	// Nudge members who haven't acknowledged announcements, daily at 10:00
+	if s.announcementService != nil {
+		_, err = s.cron.AddFunc("0 0 10 * * *", func() {
+			s.jobs.Run(JobAnnouncementNudges, func() error {
+				return s.announcementService.SendAckNudges(context.Background(), nil)
+			})
+		})
+		if err != nil {
+			log.Printf("Failed to add announcement nudge cron job: %v", err)
+		}
+	}
+
+	// Drop push tokens that haven't been refreshed in a while, daily at 04:00
+	_, err = s.cron.AddFunc("0 0 4 * * *", func() {
+		s.jobs.Run(JobPushTokenCleanup, func() error {
+			return s.notificationService.cleanupStalePushTokens(nil)
+		})
+	})
+	if err != nil {
+		log.Printf("Failed to add push token cleanup cron job: %v", err)
+	}
+
+	// Move old notifications and RSVPs to the archive tables, nightly at 04:30
+	if s.archiveService != nil {
+		_, err = s.cron.AddFunc("0 30 4 * * *", func() {
+			s.jobs.Run(JobDataArchive, func() error { return s.archiveService.Archive(nil) })
+		})
+		if err != nil {
+			log.Printf("Failed to add data archive cron job: %v", err)
+		}
+	}
+
+	// Tell members who have stopped playing they'll be reviewed, daily at 09:00
+	if s.inactivityService != nil && s.inactivityService.IsEnabled() {
+		_, err = s.cron.AddFunc("0 0 9 * * *", func() {
+			s.jobs.Run(JobMemberInactivity, func() error {
+				return s.inactivityService.CheckInactivity(context.Background(), nil)
+			})
+		})
+		if err != nil {
+			log.Printf("Failed to add member inactivity cron job: %v", err)
+		}
+	}
+
+	// Send members their recap of last month on the 1st at 09:00
+	if s.statsService != nil {
+		_, err = s.cron.AddFunc("0 0 9 1 * *", func() {
+			s.jobs.Run(JobMonthlyRecaps, func() error {
+				return s.statsService.SendMonthlyRecaps(context.Background(), nil)
+			})
+		})
+		if err != nil {
+			log.Printf("Failed to add monthly recap cron job: %v", err)
+		}
+	}
+
 	s.cron.Start()
 	log.Printf("Scheduler started - Session reminders at %dh and %dh, Deadline alerts at %dh",
 		s.reminderHours24, s.reminderHours12, s.deadlineHours)
 }
 
+// runHourlyJobs sends reminders and RSVP summaries, nudges and expires maybe
+// RSVPs, settles overbooked sessions whose RSVPs have closed, allocates
+// closed fair-share sessions and reminds admins of due follow-ups, then pings the healthcheck so a scheduler that
+// stops running is noticed
+func (s *SchedulerService) runHourlyJobs() {
+	errs := []error{
+		s.jobs.Run(JobSessionReminders, func() error { return s.checkSessionReminders(nil) }),
+		s.jobs.Run(JobDeadlineReminders, func() error { return s.checkDeadlineReminders(nil) }),
+		s.jobs.Run(JobMaybeRSVPs, func() error { return s.checkMaybeRSVPs(nil) }),
+	}
+	// Before the summaries, so they show who's really IN
+	if s.rsvpService != nil {
+		errs = append(errs, s.jobs.Run(JobOverbooking, func() error { return s.rsvpService.SettleOverbooking(nil) }))
+	}
+	errs = append(errs, s.jobs.Run(JobRSVPSummaries, func() error { return s.checkRSVPSummaries(nil) }))
+	if s.allocationService != nil {
+		errs = append(errs, s.jobs.Run(JobFairShareAllocation, func() error {
+			return s.allocationService.AllocateClosedSessions(context.Background())
+		}))
+	}
+	if s.weatherProposals != nil && s.weatherProposals.IsEnabled() {
+		errs = append(errs, s.jobs.Run(JobWeatherProposals, func() error {
+			return s.weatherProposals.ProposeCancellations(context.Background(), nil)
+		}))
+	}
+	if s.contactLogService != nil {
+		errs = append(errs, s.jobs.Run(JobContactFollowUps, func() error {
+			return s.contactLogService.SendFollowUpReminders(context.Background(), nil)
+		}))
+	}
+
+	err := errors.Join(errs...)
+	detail := "ok"
+	if err != nil {
+		detail = err.Error()
+	}
+	s.jobs.PingHealthcheck(context.Background(), err != nil, detail)
+}
+
+// ManualJobs are the jobs an admin can run on demand
+var ManualJobs = []string{JobSessionReminders, JobDeadlineReminders, JobMaybeRSVPs, JobRSVPSummaries, JobRecurringSessions, JobPushTokenCleanup, JobDatabaseBackup, JobAnnouncementNudges, JobDataArchive, JobMemberInactivity, JobMonthlyRecaps, JobWeatherProposals, JobRSVPCounts, JobOverbooking, JobContactFollowUps}
+
+// RunJob runs a job now, as the scheduler would at this moment. On a dry run
+// nothing is sent, created or deleted and the report lists what would have
+// been; real runs are recorded like scheduled ones.
+func (s *SchedulerService) RunJob(name string, dryRun bool) (*JobReport, error) {
+	report := &JobReport{Job: name, DryRun: dryRun, Actions: []JobAction{}}
+
+	var fn func() error
+	switch name {
+	case JobSessionReminders:
+		fn = func() error { return s.checkSessionReminders(report) }
+	case JobDeadlineReminders:
+		fn = func() error { return s.checkDeadlineReminders(report) }
+	case JobMaybeRSVPs:
+		fn = func() error { return s.checkMaybeRSVPs(report) }
+	case JobRSVPSummaries:
+		fn = func() error { return s.checkRSVPSummaries(report) }
+	case JobRecurringSessions:
+		if s.sessionService == nil {
+			return nil, ErrUnknownJob
+		}
+		fn = func() error { return s.sessionService.refreshRecurringSessions(report) }
+	case JobPushTokenCleanup:
+		fn = func() error { return s.notificationService.cleanupStalePushTokens(report) }
+	case JobAnnouncementNudges:
+		if s.announcementService == nil {
+			return nil, ErrUnknownJob
+		}
+		fn = func() error { return s.announcementService.SendAckNudges(context.Background(), report) }
+	case JobDataArchive:
+		if s.archiveService == nil {
+			return nil, ErrUnknownJob
+		}
+		fn = func() error { return s.archiveService.Archive(report) }
+	case JobMemberInactivity:
+		if s.inactivityService == nil || !s.inactivityService.IsEnabled() {
+			return nil, ErrUnknownJob
+		}
+		fn = func() error { return s.inactivityService.CheckInactivity(context.Background(), report) }
+	case JobMonthlyRecaps:
+		if s.statsService == nil {
+			return nil, ErrUnknownJob
+		}
+		fn = func() error { return s.statsService.SendMonthlyRecaps(context.Background(), report) }
+	case JobWeatherProposals:
+		if s.weatherProposals == nil || !s.weatherProposals.IsEnabled() {
+			return nil, ErrUnknownJob
+		}
+		fn = func() error { return s.weatherProposals.ProposeCancellations(context.Background(), report) }
+	case JobOverbooking:
+		if s.rsvpService == nil {
+			return nil, ErrUnknownJob
+		}
+		fn = func() error { return s.rsvpService.SettleOverbooking(report) }
+	case JobContactFollowUps:
+		if s.contactLogService == nil {
+			return nil, ErrUnknownJob
+		}
+		fn = func() error { return s.contactLogService.SendFollowUpReminders(context.Background(), report) }
+	case JobRSVPCounts:
+		if s.rsvpService == nil {
+			return nil, ErrUnknownJob
+		}
+		fn = func() error { return s.rsvpService.ReconcileRSVPCounts(report) }
+	case JobDatabaseBackup:
+		if s.backupService == nil {
+			return nil, ErrUnknownJob
+		}
+		fn = func() error {
+			_, err := s.backupService.Backup(context.Background(), report)
+			return err
+		}
+	default:
+		return nil, ErrUnknownJob
+	}
+
+	if dryRun {
+		return report, fn()
+	}
+	return report, s.jobs.Run(name, fn)
+}
+
+// UpdateTimings changes the reminder offsets used from the next run onwards
+func (s *SchedulerService) UpdateTimings(reminderHours24, reminderHours12, deadlineHours int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reminderHours24 = reminderHours24
+	s.reminderHours12 = reminderHours12
+	s.deadlineHours = deadlineHours
+	log.Printf("Scheduler timings updated - Session reminders at %dh and %dh, Deadline alerts at %dh",
+		reminderHours24, reminderHours12, deadlineHours)
+}
+
+func (s *SchedulerService) timings() (reminderHours24, reminderHours12, deadlineHours int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.reminderHours24, s.reminderHours12, s.deadlineHours
+}
+
 // Stop gracefully stops the scheduler
 func (s *SchedulerService) Stop() {
 	ctx := s.cron.Stop()
@@ -65,93 +434,203 @@ func (s *SchedulerService) Stop() {
 }
 
 // checkSessionReminders checks for sessions that need reminders sent
-func (s *SchedulerService) checkSessionReminders() {
+func (s *SchedulerService) checkSessionReminders(report *JobReport) error {
 	now := utils.NowInSydney()
 	log.Printf("Checking session reminders at %s", now.Format("2006-01-02 15:04"))
 
-	// Check for 24h reminders
-	s.sendSessionRemindersForWindow(now, s.reminderHours24, "24h")
+	reminderHours24, reminderHours12, _ := s.timings()
 
-	// Check for 12h reminders
-	s.sendSessionRemindersForWindow(now, s.reminderHours12, "12h")
+	return errors.Join(
+		// First (24h by default) reminders
+		s.sendSessionRemindersForWindow(now, reminderHours24, fmt.Sprintf("%dh", reminderHours24), report),
+		// Second (12h by default) reminders
+		s.sendSessionRemindersForWindow(now, reminderHours12, fmt.Sprintf("%dh", reminderHours12), report),
+	)
 }
 
-// sendSessionRemindersForWindow sends reminders for sessions starting within a time window
-func (s *SchedulerService) sendSessionRemindersForWindow(now time.Time, hoursAhead int, label string) {
-	// Calculate the target time window (e.g., 24h from now, within a 1-hour window)
-	windowStart := now.Add(time.Duration(hoursAhead) * time.Hour)
-	windowEnd := windowStart.Add(1 * time.Hour)
-
-	// Find sessions starting within this window
+// sendSessionRemindersForWindow sends reminders that fall due within the next
+// hour for sessions starting hoursAhead later. Hours are counted on Sydney
+// clocks, so a 24h reminder goes out at the same time of day as the session
+// even across a daylight saving change.
+func (s *SchedulerService) sendSessionRemindersForWindow(now time.Time, hoursAhead int, label string, report *JobReport) error {
+	from, to := reminderSearchRange(now, hoursAhead)
 	var sessions []models.Session
 	err := database.DB.Where(
-		"session_date = ? AND status = ?",
-		windowStart.Format("2006-01-02"),
-		models.SessionStatusOpen,
+		"starts_at >= ? AND starts_at < ? AND status = ?",
+		from, to, models.SessionStatusOpen,
 	).Find(&sessions).Error
 
 	if err != nil {
-		log.Printf("Error fetching sessions for %s reminders: %v", label, err)
-		return
+		return fmt.Errorf("fetching sessions for %s reminders: %w", label, err)
 	}
 
+	var errs []error
 	for _, session := range sessions {
-		// Parse session start time and check if it falls within our window
-		sessionStart, err := s.parseSessionDateTime(session)
-		if err != nil {
-			log.Printf("Error parsing session time: %v", err)
-			continue
-		}
-
-		if sessionStart.After(windowStart) && sessionStart.Before(windowEnd) {
-			s.sendSessionReminders(session, label)
+		if reminderDue(session.StartsAt, now, hoursAhead) {
+			errs = append(errs, s.sendSessionReminders(session, label, report))
 		}
 	}
+	return errors.Join(errs...)
+}
+
+// reminderSearchRange returns the start times of sessions whose reminders
+// hoursAhead before could fall in the hour from now, with an hour's slack
+// either side for a daylight saving change in between
+func reminderSearchRange(now time.Time, hoursAhead int) (from, to time.Time) {
+	return utils.AddWallClock(now, hoursAhead-1), utils.AddWallClock(now.Add(time.Hour), hoursAhead+1)
+}
+
+// reminderDue reports whether the reminder hoursAhead before a session
+// starting at startsAt falls in the hour from now
+func reminderDue(startsAt, now time.Time, hoursAhead int) bool {
+	remindAt := utils.AddWallClock(startsAt, -hoursAhead)
+	return !remindAt.Before(now) && remindAt.Before(now.Add(time.Hour))
 }
 
-// sendSessionReminders sends reminders to all users who have RSVP'd to a session
-func (s *SchedulerService) sendSessionReminders(session models.Session, label string) {
+// sendSessionReminders sends each player who RSVP'd IN or is on the waitlist
+// a reminder personalised with their spot, the confirmed count, optionally
+// who else is coming, and the forecast for outdoor sessions
+func (s *SchedulerService) sendSessionReminders(session models.Session, label string, report *JobReport) error {
 	ctx := context.Background()
 
-	// Get all RSVPs with status "in" for this session
-	var rsvps []models.RSVP
-	err := database.DB.Where("session_id = ? AND status = ?", session.ID, models.RSVPStatusIn).Find(&rsvps).Error
+	// Get all RSVPs with status "in" for this session, in the order spots were taken
+	rsvps, err := confirmedPlayers(database.DB, session.ID)
 	if err != nil {
-		log.Printf("Error fetching RSVPs for session %s: %v", session.ID, err)
-		return
+		return fmt.Errorf("fetching RSVPs for session %s: %w", session.ID, err)
 	}
-
-	if len(rsvps) == 0 {
-		return
+	waitlist, err := waitlistFor(database.DB, session.ID)
+	if err != nil {
+		return fmt.Errorf("fetching waitlist for session %s: %w", session.ID, err)
 	}
 
-	// Format session date for display
-	dateStr := utils.FormatDateForDisplay(session.SessionDate)
+	if len(rsvps) == 0 && len(waitlist) == 0 {
+		return nil
+	}
 
+	// Everyone reminded, confirmed players first, then the waitlist in order
+	type recipient struct {
+		userID   uuid.UUID
+		user     *models.User
+		position int // on the waitlist
+	}
+	recipients := make([]recipient, 0, len(rsvps)+len(waitlist))
 	for _, rsvp := range rsvps {
-		title := fmt.Sprintf("Session Reminder (%s)", label)
-		body := fmt.Sprintf("Don't forget! %s is on %s at %s", session.Title, dateStr, session.StartTime)
-		data := map[string]string{
-			"type":       string(models.NotificationSessionReminder),
-			"session_id": session.ID.String(),
+		recipients = append(recipients, recipient{userID: rsvp.UserID, user: rsvp.User})
+	}
+	for _, entry := range waitlist {
+		recipients = append(recipients, recipient{userID: entry.UserID, user: entry.User, position: entry.Position})
+	}
+
+	// Recipients who opted in to seeing who else is coming
+	userIDs := make([]uuid.UUID, len(recipients))
+	for i, r := range recipients {
+		userIDs[i] = r.userID
+	}
+	var showAttendees []uuid.UUID
+	database.DB.Model(&models.UserNotificationPreferences{}).
+		Where("user_id IN ? AND reminder_show_attendees = ?", userIDs, true).
+		Pluck("user_id", &showAttendees)
+	wantsAttendees := make(map[uuid.UUID]bool, len(showAttendees))
+	for _, id := range showAttendees {
+		wantsAttendees[id] = true
+	}
+
+	var attachments []string
+	database.DB.Model(&models.SessionAttachment{}).
+		Where("session_id = ?", session.ID).
+		Order("created_at ASC").
+		Pluck("file_name", &attachments)
+
+	reminder := SessionReminderContext{
+		Session:        session,
+		Date:           utils.FormatDateForDisplay(session.SessionDate),
+		Label:          label,
+		ConfirmedCount: len(rsvps),
+		Weather:        s.forecastFor(ctx, session),
+		Attachments:    attachments,
+		SessionURL:     s.notificationService.SessionURL(session.ID),
+	}
+	data := map[string]string{
+		"type":       string(models.NotificationSessionReminder),
+		"session_id": session.ID.String(),
+	}
+
+	msg := loadMessage(models.MessageSessionReminder)
+	messages := make([]NotificationMessage, 0, len(recipients))
+	for _, to := range recipients {
+		r := reminder
+		r.WaitlistPosition = to.position
+		if wantsAttendees[to.userID] {
+			r.Attendees, r.MoreAttendees = otherAttendees(rsvps, to.userID)
 		}
 
-		if err := s.notificationService.SendNotification(ctx, rsvp.UserID, models.NotificationSessionReminder, title, body, data); err != nil {
-			log.Printf("Error sending session reminder to user %s: %v", rsvp.UserID, err)
+		lang := models.DefaultLanguage
+		if to.user != nil {
+			lang = to.user.PreferredLanguage
 		}
+		title, body, err := msg.render(lang, r)
+		if err != nil {
+			log.Printf("Error rendering session reminder for user %s: %v", to.userID, err)
+			continue
+		}
+		messages = append(messages, NotificationMessage{UserID: to.userID, Title: title, Body: body, Data: data})
+	}
+
+	report.addNotifications(models.NotificationSessionReminder, session.ID, messages)
+	if report.isDryRun() {
+		return nil
 	}
 
-	log.Printf("Sent %s session reminders to %d users for session %s", label, len(rsvps), session.Title)
+	sent, err := s.notificationService.SendBatch(ctx, models.NotificationSessionReminder, messages)
+	if err != nil {
+		return fmt.Errorf("sending session reminders for session %s: %w", session.ID, err)
+	}
+
+	log.Printf("Sent %s session reminders to %d users for session %s", label, sent, session.Title)
+	return nil
+}
+
+// otherAttendees names the confirmed players other than userID, up to
+// maxReminderAttendees, and counts the rest
+func otherAttendees(confirmed []models.RSVP, userID uuid.UUID) ([]string, int) {
+	var names []string
+	more := 0
+	for _, rsvp := range confirmed {
+		if rsvp.UserID == userID || rsvp.User == nil {
+			continue
+		}
+		if len(names) < maxReminderAttendees {
+			names = append(names, rsvp.User.Name)
+		} else {
+			more++
+		}
+	}
+	return names, more
+}
+
+// forecastFor returns the forecast at an outdoor session's start, or "" if
+// the session is indoors or no forecast is available
+func (s *SchedulerService) forecastFor(ctx context.Context, session models.Session) string {
+	if !session.IsOutdoor || s.weather == nil {
+		return ""
+	}
+	forecast, err := s.weather.Forecast(ctx, session.StartsAt)
+	if err != nil {
+		log.Printf("Error fetching forecast for session %s: %v", session.ID, err)
+		return ""
+	}
+	return forecast.String()
 }
 
 // checkDeadlineReminders checks for sessions with approaching RSVP deadlines
-func (s *SchedulerService) checkDeadlineReminders() {
+func (s *SchedulerService) checkDeadlineReminders(report *JobReport) error {
 	now := utils.NowInSydney()
 	ctx := context.Background()
 
 	// Calculate the deadline window (e.g., deadlines within the next 6 hours)
+	_, _, deadlineHours := s.timings()
 	windowStart := now
-	windowEnd := now.Add(time.Duration(s.deadlineHours) * time.Hour)
+	windowEnd := now.Add(time.Duration(deadlineHours) * time.Hour)
 
 	// Find sessions with deadlines in this window that are still open
 	var sessions []models.Session
@@ -163,23 +642,23 @@ func (s *SchedulerService) checkDeadlineReminders() {
 	).Find(&sessions).Error
 
 	if err != nil {
-		log.Printf("Error fetching sessions for deadline reminders: %v", err)
-		return
+		return fmt.Errorf("fetching sessions for deadline reminders: %w", err)
 	}
 
+	var errs []error
 	for _, session := range sessions {
-		s.sendDeadlineReminders(ctx, session)
+		errs = append(errs, s.sendDeadlineReminders(ctx, session, report))
 	}
+	return errors.Join(errs...)
 }
 
 // sendDeadlineReminders sends deadline alerts to users who haven't RSVP'd yet
-func (s *SchedulerService) sendDeadlineReminders(ctx context.Context, session models.Session) {
+func (s *SchedulerService) sendDeadlineReminders(ctx context.Context, session models.Session, report *JobReport) error {
 	// Get all approved members
 	var users []models.User
 	err := database.DB.Where("membership_status = ?", models.MembershipApproved).Find(&users).Error
 	if err != nil {
-		log.Printf("Error fetching users for deadline reminders: %v", err)
-		return
+		return fmt.Errorf("fetching users for deadline reminders: %w", err)
 	}
 
 	// Get existing RSVPs for this session
@@ -196,103 +675,39 @@ func (s *SchedulerService) sendDeadlineReminders(ctx context.Context, session mo
 	deadlineStr := session.RSVPDeadline.In(utils.SydneyLocation).Format("Monday 3:04 PM")
 	dateStr := utils.FormatDateForDisplay(session.SessionDate)
 
-	notifiedCount := 0
+	var recipients []uuid.UUID
 	for _, user := range users {
 		// Skip users who have already RSVP'd
 		if rsvpUserMap[user.ID] {
 			continue
 		}
-
-		title := "RSVP Deadline Approaching"
-		body := fmt.Sprintf("The RSVP deadline for %s (%s) is %s. Don't miss out!", session.Title, dateStr, deadlineStr)
-		data := map[string]string{
-			"type":       string(models.NotificationRSVPDeadline),
-			"session_id": session.ID.String(),
-		}
-
-		if err := s.notificationService.SendNotification(ctx, user.ID, models.NotificationRSVPDeadline, title, body, data); err != nil {
-			log.Printf("Error sending deadline reminder to user %s: %v", user.ID, err)
-		} else {
-			notifiedCount++
-		}
-	}
-
-	if notifiedCount > 0 {
-		log.Printf("Sent RSVP deadline reminders to %d users for session %s", notifiedCount, session.Title)
+		recipients = append(recipients, user.ID)
 	}
-}
-
-// parseSessionDateTime parses a session's date and start time into a time.Time
-func (s *SchedulerService) parseSessionDateTime(session models.Session) (time.Time, error) {
-	// session.SessionDate is already a time.Time (date only)
-	// session.StartTime is a string like "18:30"
-
-	dateInSydney := session.SessionDate.In(utils.SydneyLocation)
 
-	// Parse start time
-	startTime, err := time.Parse("15:04", session.StartTime)
+	messages, err := loadMessage(models.MessageRSVPDeadline).messagesFor(recipients, sameData(DeadlineReminderContext{
+		Session:  session,
+		Date:     dateStr,
+		Deadline: deadlineStr,
+	}), map[string]string{
+		"type":       string(models.NotificationRSVPDeadline),
+		"session_id": session.ID.String(),
+	})
 	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to parse start time %s: %w", session.StartTime, err)
-	}
-
-	// Combine date and time
-	result := time.Date(
-		dateInSydney.Year(),
-		dateInSydney.Month(),
-		dateInSydney.Day(),
-		startTime.Hour(),
-		startTime.Minute(),
-		0, 0,
-		utils.SydneyLocation,
-	)
-
-	return result, nil
-}
-
-// SendWaitlistUpdate sends a notification when a spot opens up
-// This should be called from RSVPService when someone cancels their RSVP
-func (s *SchedulerService) SendWaitlistUpdate(ctx context.Context, session models.Session) {
-	// Get confirmed count
-	var confirmedCount int64
-	database.DB.Model(&models.RSVP{}).
-		Where("session_id = ? AND status = ?", session.ID, models.RSVPStatusIn).
-		Count(&confirmedCount)
-
-	// If session is full, no need to notify
-	if int(confirmedCount) >= session.MaxPlayers {
-		return
+		return fmt.Errorf("rendering deadline reminder for session %s: %w", session.ID, err)
 	}
 
-	spotsAvailable := session.MaxPlayers - int(confirmedCount)
-
-	// Get users who marked "maybe" or are on the waitlist, ordered by RSVP time
-	var maybeRSVPs []models.RSVP
-	err := database.DB.Where("session_id = ? AND status = ?", session.ID, models.RSVPStatusMaybe).
-		Order("rsvp_timestamp ASC").
-		Limit(spotsAvailable).
-		Find(&maybeRSVPs).Error
-
-	if err != nil {
-		log.Printf("Error fetching maybe RSVPs: %v", err)
-		return
+	report.addNotifications(models.NotificationRSVPDeadline, session.ID, messages)
+	if report.isDryRun() {
+		return nil
 	}
 
-	dateStr := utils.FormatDateForDisplay(session.SessionDate)
-
-	for _, rsvp := range maybeRSVPs {
-		title := "Spot Available!"
-		body := fmt.Sprintf("A spot has opened up for %s on %s. RSVP now to confirm your place!", session.Title, dateStr)
-		data := map[string]string{
-			"type":       string(models.NotificationWaitlistUpdate),
-			"session_id": session.ID.String(),
-		}
-
-		if err := s.notificationService.SendNotification(ctx, rsvp.UserID, models.NotificationWaitlistUpdate, title, body, data); err != nil {
-			log.Printf("Error sending waitlist update to user %s: %v", rsvp.UserID, err)
-		}
+	notifiedCount, err := s.notificationService.SendBatch(ctx, models.NotificationRSVPDeadline, messages)
+	if err != nil {
+		return fmt.Errorf("sending deadline reminders for session %s: %w", session.ID, err)
 	}
 
-	if len(maybeRSVPs) > 0 {
-		log.Printf("Sent waitlist updates to %d users for session %s", len(maybeRSVPs), session.Title)
+	if notifiedCount > 0 {
+		log.Printf("Sent RSVP deadline reminders to %d users for session %s", notifiedCount, session.Title)
 	}
+	return nil
 }
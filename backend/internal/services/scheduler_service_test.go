@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+// TestReminderWindowsAcrossDST runs the hourly reminder check through both
+// 2025 daylight saving weekends and checks each session's reminders go out
+// exactly once, at the session's time of day on Sydney clocks
+func TestReminderWindowsAcrossDST(t *testing.T) {
+	tests := []struct {
+		name       string
+		startsAt   time.Time
+		hoursAhead int
+		wantAt     time.Time
+	}{
+		{"24h, evening DST ends", utils.WallClock(2025, time.April, 6, 18, 0, 0), 24, utils.WallClock(2025, time.April, 5, 18, 0, 0)},
+		{"12h, evening DST ends", utils.WallClock(2025, time.April, 6, 18, 0, 0), 12, utils.WallClock(2025, time.April, 6, 6, 0, 0)},
+		{"12h, morning DST ends", utils.WallClock(2025, time.April, 6, 10, 0, 0), 12, utils.WallClock(2025, time.April, 5, 22, 0, 0)},
+		{"24h, evening after DST ends", utils.WallClock(2025, time.April, 7, 18, 30, 0), 24, utils.WallClock(2025, time.April, 6, 18, 30, 0)},
+		{"24h, evening DST starts", utils.WallClock(2025, time.October, 5, 18, 0, 0), 24, utils.WallClock(2025, time.October, 4, 18, 0, 0)},
+		{"12h, evening DST starts", utils.WallClock(2025, time.October, 5, 18, 0, 0), 12, utils.WallClock(2025, time.October, 5, 6, 0, 0)},
+		{"12h, morning DST starts", utils.WallClock(2025, time.October, 5, 10, 0, 0), 12, utils.WallClock(2025, time.October, 4, 22, 0, 0)},
+		{"24h, evening after DST starts", utils.WallClock(2025, time.October, 6, 18, 30, 0), 24, utils.WallClock(2025, time.October, 5, 18, 30, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every hourly run over the three days around the session
+			var due []time.Time
+			for now := tt.startsAt.Add(-72 * time.Hour).Truncate(time.Hour); now.Before(tt.startsAt); now = now.Add(time.Hour) {
+				if !reminderDue(tt.startsAt, now, tt.hoursAhead) {
+					continue
+				}
+				due = append(due, now)
+
+				from, to := reminderSearchRange(now, tt.hoursAhead)
+				if tt.startsAt.Before(from) || !tt.startsAt.Before(to) {
+					t.Errorf("run at %s searches [%s, %s), missing the session", now.In(utils.SydneyLocation), from.In(utils.SydneyLocation), to.In(utils.SydneyLocation))
+				}
+			}
+
+			if len(due) != 1 {
+				t.Fatalf("reminder due in %d runs, want 1: %v", len(due), due)
+			}
+			if wantRun := tt.wantAt.Truncate(time.Hour); !due[0].Equal(wantRun) {
+				t.Errorf("reminder due in the run at %s, want %s", due[0].In(utils.SydneyLocation), wantRun.In(utils.SydneyLocation))
+			}
+		})
+	}
+}
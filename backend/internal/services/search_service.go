@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchType is a kind of thing the site search finds
+type SearchType string
+
+const (
+	SearchMembers       SearchType = "members"
+	SearchSessions      SearchType = "sessions"
+	SearchAnnouncements SearchType = "announcements"
+)
+
+// SearchTypes lists every type, in the order results are returned
+var SearchTypes = []SearchType{SearchMembers, SearchSessions, SearchAnnouncements}
+
+const (
+	// MaxSearchLimit caps the results returned per type
+	MaxSearchLimit = 50
+	// maxSearchTerms keeps a pasted paragraph from becoming a huge query
+	maxSearchTerms = 8
+)
+
+var ErrEmptySearch = domainError(ErrInvalid, "empty_search", "search for at least one word")
+
+// SearchResults holds the matches of each type searched, best first. Types
+// not searched, or that the viewer can't see, are nil.
+type SearchResults struct {
+	Members       []models.User
+	Sessions      []models.Session
+	Announcements []models.Announcement
+}
+
+type SearchService struct{}
+
+func NewSearchService() *SearchService {
+	return &SearchService{}
+}
+
+// SearchableTypes returns the types viewer may search: everyone sees the
+// schedule, and approved members also find each other and announcements
+func SearchableTypes(viewer *models.User) []SearchType {
+	if viewer != nil && (viewer.IsAdmin() || viewer.IsApproved()) {
+		return SearchTypes
+	}
+	return []SearchType{SearchSessions}
+}
+
+// Search finds members by name, sessions by title and description, and
+// announcements by title and body, using the search_vector columns kept by
+// Postgres (see database.Migrate). Each word of query matches as a prefix,
+// so results appear while a name is still being typed. Types the viewer
+// can't see are skipped; only admins find members who aren't approved.
+func (s *SearchService) Search(viewer *models.User, query string, types []SearchType, limit int) (*SearchResults, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, ErrEmptySearch
+	}
+	if limit < 1 || limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	allowed := map[SearchType]bool{}
+	for _, t := range SearchableTypes(viewer) {
+		allowed[t] = true
+	}
+
+	results := &SearchResults{}
+	for _, t := range types {
+		if !allowed[t] {
+			continue
+		}
+		switch t {
+		case SearchMembers:
+			q := matching(database.DB, "simple", terms)
+			if !viewer.IsAdmin() {
+				q = q.Where("membership_status = ?", models.MembershipApproved)
+			}
+			results.Members = []models.User{}
+			if err := q.Order("name ASC").Limit(limit).Find(&results.Members).Error; err != nil {
+				return nil, err
+			}
+		case SearchSessions:
+			results.Sessions = []models.Session{}
+			if err := matching(database.DB, "english", terms).
+				Order("starts_at DESC").Limit(limit).Find(&results.Sessions).Error; err != nil {
+				return nil, err
+			}
+		case SearchAnnouncements:
+			results.Announcements = []models.Announcement{}
+			if err := matching(visibleAnnouncements(database.DB, viewer), "english", terms).
+				Order("sent_at DESC").Limit(limit).Find(&results.Announcements).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// matching narrows db to rows whose search_vector matches every term,
+// best matches first
+func matching(db *gorm.DB, config string, terms []string) *gorm.DB {
+	tsquery := strings.Join(terms, " & ")
+	return db.Where("search_vector @@ to_tsquery(?::regconfig, ?)", config, tsquery).
+		Order(clause.Expr{SQL: "ts_rank(search_vector, to_tsquery(?::regconfig, ?)) DESC", Vars: []interface{}{config, tsquery}})
+}
+
+// searchTerms splits a query into prefix terms for to_tsquery ("court ma"
+// becomes "court:*", "ma:*"). Only letters and digits are kept, so nothing
+// the user types can break the tsquery syntax.
+func searchTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+	terms := make([]string, len(words))
+	for i, w := range words {
+		terms[i] = w + ":*"
+	}
+	return terms
+}
+
+// ParseSearchTypes reads a comma-separated list of types, all of them when
+// empty
+func ParseSearchTypes(s string) ([]SearchType, error) {
+	if strings.TrimSpace(s) == "" {
+		return SearchTypes, nil
+	}
+	var types []SearchType
+	for _, part := range strings.Split(s, ",") {
+		t := SearchType(strings.TrimSpace(part))
+		switch t {
+		case SearchMembers, SearchSessions, SearchAnnouncements:
+			types = append(types, t)
+		default:
+			return nil, errors.New("type must be members, sessions or announcements")
+		}
+	}
+	return types, nil
+}
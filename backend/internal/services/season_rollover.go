@@ -0,0 +1,329 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// maxSeasonDays bounds how long a season rollover can schedule, to catch typos
+const maxSeasonDays = 366
+
+var (
+	ErrRolloverNotFound  = domainError(ErrNotFound, "rollover_not_found", "season rollover not found")
+	ErrRolloverUndone    = domainError(ErrConflict, "rollover_undone", "this season rollover has already been undone")
+	ErrNothingToRollOver = domainError(ErrInvalid, "nothing_to_roll_over", "no sessions to create: every date is past, blacked out or already scheduled")
+)
+
+// Blackout is a day to leave out of a season, and why
+type Blackout struct {
+	Date   time.Time
+	Reason string
+}
+
+// RolloverInput is a season to generate recurring sessions for. Dates are
+// inclusive. With no TemplateIDs, every open recurring series is rolled over.
+type RolloverInput struct {
+	Name        string
+	StartDate   time.Time
+	EndDate     time.Time
+	Blackouts   []Blackout
+	TemplateIDs []uuid.UUID
+}
+
+// PlannedSession is a session a rollover creates, or would on a preview
+type PlannedSession struct {
+	SessionID   *uuid.UUID `json:"session_id,omitempty"` // once created
+	TemplateID  uuid.UUID  `json:"template_id"`
+	Template    string     `json:"template"`
+	Title       string     `json:"title"`
+	SessionDate string     `json:"session_date"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      time.Time  `json:"ends_at"`
+}
+
+// SkippedDate is a week of a series a rollover leaves out, and why
+type SkippedDate struct {
+	TemplateID  uuid.UUID `json:"template_id"`
+	Template    string    `json:"template"`
+	SessionDate string    `json:"session_date"`
+	Reason      string    `json:"reason"` // "blackout", "scheduled" or "past"
+	Detail      string    `json:"detail,omitempty"`
+}
+
+// RolloverPlan is what a season rollover creates and leaves out
+type RolloverPlan struct {
+	Templates int              `json:"templates"`
+	Sessions  []PlannedSession `json:"sessions"`
+	Skipped   []SkippedDate    `json:"skipped"`
+}
+
+// RolloverUndo is what undoing a rollover deleted and kept
+type RolloverUndo struct {
+	Deleted int              `json:"deleted"`
+	Kept    []models.Session `json:"kept"` // RSVP'd to or commented on
+}
+
+// dateKey identifies a calendar day, whichever zone a date was read in
+func dateKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// blackoutDates returns the days blacked out by season rollovers, with why
+func blackoutDates() (map[string]string, error) {
+	var blackouts []models.BlackoutDate
+	if err := database.DB.Find(&blackouts).Error; err != nil {
+		return nil, fmt.Errorf("fetching blackout dates: %w", err)
+	}
+	dates := make(map[string]string, len(blackouts))
+	for _, b := range blackouts {
+		dates[dateKey(b.Date)] = b.Reason
+	}
+	return dates, nil
+}
+
+// PreviewRollover returns what RollOverSeason would create for input,
+// without creating anything
+func (s *SessionService) PreviewRollover(input RolloverInput) (*RolloverPlan, error) {
+	plan, _, err := planRollover(input)
+	return plan, err
+}
+
+// planRollover works out each weekly occurrence of the templates within the
+// season, leaving out blackouts, past days and weeks already scheduled. It
+// also returns the templates, by ID.
+func planRollover(input RolloverInput) (*RolloverPlan, map[uuid.UUID]*models.Session, error) {
+	if input.EndDate.Before(input.StartDate) {
+		return nil, nil, domainError(ErrInvalid, "invalid_season", "the season must end on or after its start")
+	}
+	if input.EndDate.Sub(input.StartDate) > maxSeasonDays*24*time.Hour {
+		return nil, nil, domainError(ErrInvalid, "invalid_season", "a season can be at most a year long")
+	}
+
+	query := database.DB.Where("is_recurring = ? AND status = ? AND recurring_day_of_week IS NOT NULL", true, models.SessionStatusOpen)
+	if len(input.TemplateIDs) > 0 {
+		query = query.Where("id IN ?", input.TemplateIDs)
+	}
+	var templates []models.Session
+	if err := query.Order("recurring_day_of_week ASC, title ASC").Find(&templates).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(templates) < len(input.TemplateIDs) {
+		return nil, nil, domainError(ErrNotFound, "template_not_found", "every template must be an open recurring series")
+	}
+
+	blackouts := make(map[string]string, len(input.Blackouts))
+	for _, b := range input.Blackouts {
+		blackouts[dateKey(b.Date)] = b.Reason
+	}
+	today := utils.StartOfDay(utils.NowInSydney())
+
+	plan := &RolloverPlan{Templates: len(templates), Sessions: []PlannedSession{}, Skipped: []SkippedDate{}}
+	byID := make(map[uuid.UUID]*models.Session, len(templates))
+	for i := range templates {
+		template := &templates[i]
+		byID[template.ID] = template
+
+		// Weeks already scheduled, including the template's own
+		var existing []time.Time
+		if err := database.DB.Model(&models.Session{}).
+			Where("recurring_parent_id = ? AND session_date BETWEEN ? AND ?",
+				template.ID, dateKey(input.StartDate), dateKey(input.EndDate)).
+			Pluck("session_date", &existing).Error; err != nil {
+			return nil, nil, err
+		}
+		scheduled := map[string]bool{dateKey(template.SessionDate): true}
+		for _, d := range existing {
+			scheduled[dateKey(d)] = true
+		}
+
+		offset := (*template.RecurringDayOfWeek - int(input.StartDate.Weekday()) + 7) % 7
+		for date := input.StartDate.AddDate(0, 0, offset); !date.After(input.EndDate); date = date.AddDate(0, 0, 7) {
+			key := dateKey(date)
+			skip := SkippedDate{TemplateID: template.ID, Template: template.Title, SessionDate: key}
+			if reason, ok := blackouts[key]; ok {
+				skip.Reason, skip.Detail = "blackout", reason
+				plan.Skipped = append(plan.Skipped, skip)
+				continue
+			}
+			if scheduled[key] {
+				skip.Reason = "scheduled"
+				plan.Skipped = append(plan.Skipped, skip)
+				continue
+			}
+			if utils.StartOfDay(date).Before(today) {
+				skip.Reason = "past"
+				plan.Skipped = append(plan.Skipped, skip)
+				continue
+			}
+
+			child := recurringChild(template, date)
+			plan.Sessions = append(plan.Sessions, PlannedSession{
+				TemplateID:  template.ID,
+				Template:    template.Title,
+				Title:       child.Title,
+				SessionDate: key,
+				StartsAt:    child.StartsAt,
+				EndsAt:      child.EndsAt,
+			})
+		}
+	}
+
+	sort.SliceStable(plan.Sessions, func(i, j int) bool {
+		return plan.Sessions[i].StartsAt.Before(plan.Sessions[j].StartsAt)
+	})
+	return plan, byID, nil
+}
+
+// RollOverSeason creates every session planned for a season in one
+// transaction, with the series' regulars RSVP'd IN as usual, and records
+// the season's blackout dates so the nightly top-up leaves them out too
+func (s *SessionService) RollOverSeason(input RolloverInput, createdBy uuid.UUID) (*models.SeasonRollover, *RolloverPlan, error) {
+	plan, templates, err := planRollover(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(plan.Sessions) == 0 {
+		return nil, nil, ErrNothingToRollOver
+	}
+
+	rollover := models.SeasonRollover{
+		Name:            input.Name,
+		StartDate:       input.StartDate,
+		EndDate:         input.EndDate,
+		SessionsCreated: len(plan.Sessions),
+		CreatedBy:       createdBy,
+	}
+	seen := map[string]bool{}
+	for _, b := range input.Blackouts {
+		if !seen[dateKey(b.Date)] {
+			seen[dateKey(b.Date)] = true
+			rollover.Blackouts = append(rollover.Blackouts, models.BlackoutDate{Date: b.Date, Reason: b.Reason})
+		}
+	}
+
+	created := make([]models.Session, len(plan.Sessions))
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rollover).Error; err != nil {
+			return err
+		}
+		for i := range plan.Sessions {
+			planned := &plan.Sessions[i]
+			date, err := utils.ParseDateInSydney(planned.SessionDate)
+			if err != nil {
+				return err
+			}
+			created[i] = recurringChild(templates[planned.TemplateID], date)
+			created[i].SeasonRolloverID = &rollover.ID
+			if err := createSession(tx, &created[i], &createdBy); err != nil {
+				return fmt.Errorf("creating %s: %w", created[i].Title, err)
+			}
+			planned.SessionID = &created[i].ID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	s.outbox.Dispatch()
+
+	for i := range created {
+		rsvpRegulars(&created[i], *created[i].RecurringParentID)
+	}
+	return &rollover, plan, nil
+}
+
+// ListRollovers returns season rollovers, most recent first
+func (s *SessionService) ListRollovers() ([]models.SeasonRollover, error) {
+	var rollovers []models.SeasonRollover
+	err := database.DB.Preload("Blackouts", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date ASC")
+	}).Preload("Creator").
+		Order("created_at DESC").
+		Find(&rollovers).Error
+	return rollovers, err
+}
+
+// UndoRollover deletes the sessions a rollover created that nobody has
+// RSVP'd to, other than as one of the series' regulars, or commented on, and
+// lifts its blackout dates. Sessions members have engaged with are kept for
+// an admin to cancel if need be.
+func (s *SessionService) UndoRollover(id, actorID uuid.UUID) (*RolloverUndo, error) {
+	result := &RolloverUndo{Kept: []models.Session{}}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var rollover models.SeasonRollover
+		if err := tx.First(&rollover, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRolloverNotFound
+			}
+			return err
+		}
+		if rollover.UndoneAt != nil {
+			return ErrRolloverUndone
+		}
+
+		var sessions []models.Session
+		if err := tx.Where("season_rollover_id = ?", id).Order("starts_at ASC").Find(&sessions).Error; err != nil {
+			return err
+		}
+		var engaged []uuid.UUID
+		if err := tx.Model(&models.Session{}).
+			Where("season_rollover_id = ?", id).
+			Where("EXISTS (SELECT 1 FROM rsvps WHERE rsvps.session_id = sessions.id AND rsvps.as_regular = ?) OR EXISTS (SELECT 1 FROM comments WHERE comments.session_id = sessions.id)", false).
+			Pluck("id", &engaged).Error; err != nil {
+			return err
+		}
+		keep := make(map[uuid.UUID]bool, len(engaged))
+		for _, sessionID := range engaged {
+			keep[sessionID] = true
+		}
+
+		for i := range sessions {
+			if keep[sessions[i].ID] {
+				result.Kept = append(result.Kept, sessions[i])
+				continue
+			}
+			if err := deleteUntouchedSession(tx, &sessions[i]); err != nil {
+				return err
+			}
+			result.Deleted++
+		}
+
+		if err := tx.Where("rollover_id = ?", id).Delete(&models.BlackoutDate{}).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		return tx.Model(&rollover).Updates(map[string]interface{}{"undone_at": now, "undone_by": actorID}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// deleteUntouchedSession deletes a session only regulars were put in to,
+// with their RSVPs and its history
+func deleteUntouchedSession(tx *gorm.DB, session *models.Session) error {
+	var rsvps []models.RSVP
+	if err := tx.Where("session_id = ?", session.ID).Find(&rsvps).Error; err != nil {
+		return err
+	}
+	if len(rsvps) > 0 {
+		if err := tx.Delete(&rsvps).Error; err != nil {
+			return err
+		}
+	}
+	for _, model := range []interface{}{&models.RSVPEvent{}, &models.WaitlistEntry{}, &models.SessionAttachment{}} {
+		if err := tx.Where("session_id = ?", session.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Delete(session).Error
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotRecurring      = domainError(ErrInvalid, "not_recurring", "only recurring sessions have regulars")
+	ErrRegularNotAMember = domainError(ErrInvalid, "regular_not_member", "regulars must be approved members")
+)
+
+// RegularsUpdate is the outcome of setting a series' regulars
+type RegularsUpdate struct {
+	Regulars []models.SeriesRegular `json:"regulars"`
+	// RSVPsAdded counts the IN RSVPs newly added regulars were given in
+	// upcoming sessions of the series
+	RSVPsAdded int `json:"rsvps_added"`
+}
+
+// seriesParent returns the parent session of the recurring series the
+// session belongs to
+func seriesParent(sessionID uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if session.RecurringParentID != nil {
+		if err := database.DB.First(&session, "id = ?", *session.RecurringParentID).Error; err != nil {
+			return nil, ErrSessionNotFound
+		}
+	}
+	if !session.IsRecurring {
+		return nil, ErrNotRecurring
+	}
+	return &session, nil
+}
+
+// ListRegulars returns the regulars of the series a session belongs to, by name
+func (s *SessionService) ListRegulars(sessionID uuid.UUID) ([]models.SeriesRegular, error) {
+	parent, err := seriesParent(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return listRegulars(parent.ID)
+}
+
+func listRegulars(seriesID uuid.UUID) ([]models.SeriesRegular, error) {
+	var regulars []models.SeriesRegular
+	if err := database.DB.Preload("User").Where("series_id = ?", seriesID).Find(&regulars).Error; err != nil {
+		return nil, err
+	}
+	sort.Slice(regulars, func(i, j int) bool {
+		if regulars[i].User == nil || regulars[j].User == nil {
+			return regulars[j].User == nil && regulars[i].User != nil
+		}
+		return regulars[i].User.Name < regulars[j].User.Name
+	})
+	return regulars, nil
+}
+
+// SetRegulars replaces the regulars of the series a session belongs to.
+// Members newly added are also put IN to the series' upcoming sessions whose
+// RSVP deadline hasn't passed, unless they've already answered for them.
+// Removing a regular leaves their RSVPs alone.
+func (s *SessionService) SetRegulars(sessionID uuid.UUID, userIDs []uuid.UUID, adminID uuid.UUID) (*RegularsUpdate, error) {
+	parent, err := seriesParent(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+	ids := make([]uuid.UUID, 0, len(wanted))
+	for id := range wanted {
+		ids = append(ids, id)
+	}
+	if len(ids) > 0 {
+		var members int64
+		if err := database.DB.Model(&models.User{}).
+			Where("id IN ? AND membership_status = ?", ids, models.MembershipApproved).
+			Count(&members).Error; err != nil {
+			return nil, err
+		}
+		if int(members) != len(ids) {
+			return nil, ErrRegularNotAMember
+		}
+	}
+
+	var existing []uuid.UUID
+	if err := database.DB.Model(&models.SeriesRegular{}).Where("series_id = ?", parent.ID).
+		Pluck("user_id", &existing).Error; err != nil {
+		return nil, err
+	}
+	current := make(map[uuid.UUID]bool, len(existing))
+	for _, id := range existing {
+		current[id] = true
+	}
+	var added []uuid.UUID
+	for _, id := range ids {
+		if !current[id] {
+			added = append(added, id)
+		}
+	}
+
+	result := &RegularsUpdate{}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		remove := tx.Where("series_id = ?", parent.ID)
+		if len(ids) > 0 {
+			remove = remove.Where("user_id NOT IN ?", ids)
+		}
+		if err := remove.Delete(&models.SeriesRegular{}).Error; err != nil {
+			return err
+		}
+		if len(added) == 0 {
+			return nil
+		}
+
+		rows := make([]models.SeriesRegular, len(added))
+		for i, id := range added {
+			rows[i] = models.SeriesRegular{SeriesID: parent.ID, UserID: id, AddedBy: adminID}
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+			return err
+		}
+
+		var upcoming []models.Session
+		if err := tx.Where("(id = ? OR recurring_parent_id = ?) AND status = ? AND rsvp_deadline > ?",
+			parent.ID, parent.ID, models.SessionStatusOpen, time.Now()).
+			Order("starts_at ASC").Find(&upcoming).Error; err != nil {
+			return err
+		}
+		for i := range upcoming {
+			n, err := addRegularRSVPs(tx, &upcoming[i], added)
+			if err != nil {
+				return err
+			}
+			result.RSVPsAdded += n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Regulars, err = listRegulars(parent.ID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// addRegularRSVPs puts each of userIDs IN to session unless they already
+// have an RSVP for it, so a member who opted out of a week stays out. Past
+// the session's capacity they join the waitlist like any other late RSVP.
+func addRegularRSVPs(tx *gorm.DB, session *models.Session, userIDs []uuid.UUID) (int, error) {
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	var answered []uuid.UUID
+	if err := tx.Model(&models.RSVP{}).Where("session_id = ? AND user_id IN ?", session.ID, userIDs).
+		Pluck("user_id", &answered).Error; err != nil {
+		return 0, err
+	}
+	skip := make(map[uuid.UUID]bool, len(answered))
+	for _, id := range answered {
+		skip[id] = true
+	}
+
+	free, err := freeSpots(tx, *session)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var rsvps []models.RSVP
+	var waitlisted []uuid.UUID
+	for _, id := range userIDs {
+		if skip[id] {
+			continue
+		}
+		status := models.RSVPStatusIn
+		if len(rsvps) >= free {
+			status = models.RSVPStatusWaitlisted
+			waitlisted = append(waitlisted, id)
+		}
+		rsvps = append(rsvps, models.RSVP{
+			SessionID:     session.ID,
+			UserID:        id,
+			Status:        status,
+			RSVPTimestamp: now,
+			AddedByAdmin:  true,
+			AsRegular:     true,
+		})
+	}
+	if len(rsvps) == 0 {
+		return 0, nil
+	}
+	if err := tx.Create(&rsvps).Error; err != nil {
+		return 0, err
+	}
+	for _, id := range waitlisted {
+		if err := joinWaitlist(tx, session.ID, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(rsvps), nil
+}
+
+// rsvpRegulars puts the series' regulars who are still approved members IN
+// to a newly generated occurrence
+func rsvpRegulars(session *models.Session, seriesID uuid.UUID) int {
+	var userIDs []uuid.UUID
+	if err := database.DB.Model(&models.SeriesRegular{}).
+		Joins("JOIN users ON users.id = series_regulars.user_id").
+		Where("series_regulars.series_id = ? AND users.membership_status = ?", seriesID, models.MembershipApproved).
+		Order("series_regulars.created_at ASC").
+		Pluck("series_regulars.user_id", &userIDs).Error; err != nil {
+		log.Printf("Failed to load regulars for series %s: %v", seriesID, err)
+		return 0
+	}
+
+	n, err := addRegularRSVPs(database.DB, session, userIDs)
+	if err != nil {
+		log.Printf("Failed to RSVP regulars to session %s: %v", session.ID, err)
+	}
+	return n
+}
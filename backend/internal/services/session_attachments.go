@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/storage"
+	"gorm.io/gorm"
+)
+
+// MaxSessionAttachments caps how many files one session can carry
+const MaxSessionAttachments = 10
+
+var (
+	ErrSessionAttachmentNotFound = domainError(ErrNotFound, "attachment_not_found", "attachment not found")
+	ErrTooManyAttachments        = domainError(ErrConflict, "too_many_attachments", fmt.Sprintf("a session can have at most %d attachments", MaxSessionAttachments))
+)
+
+// orderAttachments lists a session's attachments in the order they were added
+func orderAttachments(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at ASC")
+}
+
+// AddAttachment stores a PDF or image, such as a court map or tournament
+// draw, against a session. The content is sniffed rather than trusting the
+// client's content type.
+func (s *SessionService) AddAttachment(ctx context.Context, sessionID uuid.UUID, fileName string, size int64, r io.Reader, uploadedBy uuid.UUID) (*models.SessionAttachment, error) {
+	if s.store == nil {
+		return nil, ErrStorageDisabled
+	}
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrSessionNotFound
+	}
+	var count int64
+	if err := database.DB.Model(&models.SessionAttachment{}).Where("session_id = ?", sessionID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= MaxSessionAttachments {
+		return nil, ErrTooManyAttachments
+	}
+
+	upload, err := storage.SessionAttachments.Check(size, r)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := models.SessionAttachment{
+		ID:          uuid.New(),
+		SessionID:   session.ID,
+		FileName:    fileName,
+		ContentType: upload.ContentType,
+		SizeBytes:   size,
+		UploadedBy:  uploadedBy,
+	}
+	attachment.StorageKey = fmt.Sprintf("sessions/%s/%s%s", session.ID, attachment.ID, upload.Extension)
+
+	if err := s.store.Put(ctx, attachment.StorageKey, upload.ContentType, upload.Body); err != nil {
+		return nil, storeError("attachment", err)
+	}
+	if err := database.DB.Create(&attachment).Error; err != nil {
+		s.removeStoredAttachment(attachment)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// OpenAttachment returns a session attachment and its contents; the caller
+// must close them
+func (s *SessionService) OpenAttachment(ctx context.Context, sessionID, attachmentID uuid.UUID) (*models.SessionAttachment, io.ReadCloser, error) {
+	var attachment models.SessionAttachment
+	if err := database.DB.First(&attachment, "id = ? AND session_id = ?", attachmentID, sessionID).Error; err != nil {
+		return nil, nil, ErrSessionAttachmentNotFound
+	}
+	if s.store == nil {
+		return nil, nil, ErrStorageDisabled
+	}
+
+	body, err := s.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	return &attachment, body, nil
+}
+
+// DeleteAttachment removes a session attachment and its stored file
+func (s *SessionService) DeleteAttachment(sessionID, attachmentID uuid.UUID) error {
+	var attachment models.SessionAttachment
+	if err := database.DB.First(&attachment, "id = ? AND session_id = ?", attachmentID, sessionID).Error; err != nil {
+		return ErrSessionAttachmentNotFound
+	}
+	if err := database.DB.Delete(&attachment).Error; err != nil {
+		return err
+	}
+	s.removeStoredAttachment(attachment)
+	return nil
+}
+
+// removeStoredAttachment deletes an attachment's file, logging rather than
+// failing since the record is already gone
+func (s *SessionService) removeStoredAttachment(attachment models.SessionAttachment) {
+	if s.store == nil {
+		return
+	}
+	if err := s.store.Delete(context.Background(), attachment.StorageKey); err != nil {
+		log.Printf("Failed to remove stored attachment %s: %v", attachment.StorageKey, err)
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SessionChangeDigest holds back session_changed notifications for a window
+// after a session's first edit, then sends each member one message covering
+// everything that changed in it. Pending notices live in the database, so a
+// restart doesn't lose them.
+type SessionChangeDigest struct {
+	notificationService *NotificationService
+	window              time.Duration
+}
+
+// NewSessionChangeDigest creates a digest that sends changes window after the first one
+func NewSessionChangeDigest(notificationService *NotificationService, window time.Duration) *SessionChangeDigest {
+	return &SessionChangeDigest{notificationService: notificationService, window: window}
+}
+
+// Queue adds changes to each member's pending notice for the session. The
+// window isn't extended by later edits, so a busy session still notifies.
+func (d *SessionChangeDigest) Queue(sessionID uuid.UUID, userIDs []uuid.UUID, changes []SessionChange) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var existing []models.PendingSessionChange
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("session_id = ? AND user_id IN ?", sessionID, userIDs).
+			Find(&existing).Error; err != nil {
+			return err
+		}
+		pending := make(map[uuid.UUID]models.PendingSessionChange, len(existing))
+		for _, p := range existing {
+			pending[p.UserID] = p
+		}
+
+		for _, userID := range userIDs {
+			p, ok := pending[userID]
+			if !ok {
+				encoded, err := json.Marshal(changes)
+				if err != nil {
+					return err
+				}
+				p = models.PendingSessionChange{
+					UserID:     userID,
+					SessionID:  sessionID,
+					Changes:    string(encoded),
+					DispatchAt: time.Now().Add(d.window),
+				}
+				if err := tx.Create(&p).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			var queued []SessionChange
+			if err := json.Unmarshal([]byte(p.Changes), &queued); err != nil {
+				return fmt.Errorf("decoding pending changes %s: %w", p.ID, err)
+			}
+			encoded, err := json.Marshal(mergeSessionChanges(queued, changes))
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&p).Updates(map[string]interface{}{
+				"changes":    string(encoded),
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// mergeSessionChanges folds next into queued, keeping each field's original
+// value and its latest one. Fields changed back to where they started drop out.
+func mergeSessionChanges(queued, next []SessionChange) []SessionChange {
+	merged := append([]SessionChange(nil), queued...)
+	for _, change := range next {
+		found := false
+		for i := range merged {
+			if merged[i].Field == change.Field {
+				merged[i].New = change.New
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, change)
+		}
+	}
+
+	result := merged[:0]
+	for _, change := range merged {
+		if change.Old != change.New {
+			result = append(result, change)
+		}
+	}
+	return result
+}
+
+// Flush sends every pending notice whose window has closed. Notices for
+// sessions cancelled in the meantime are dropped, as the cancellation says it all.
+func (d *SessionChangeDigest) Flush(ctx context.Context) error {
+	var due []models.PendingSessionChange
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Skip rows another instance is already sending
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatch_at <= ?", time.Now()).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+		return tx.Delete(&due).Error
+	})
+	if err != nil {
+		return fmt.Errorf("claiming pending session changes: %w", err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	bySession := make(map[uuid.UUID][]models.PendingSessionChange)
+	for _, p := range due {
+		bySession[p.SessionID] = append(bySession[p.SessionID], p)
+	}
+
+	for sessionID, notices := range bySession {
+		var session models.Session
+		if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+			log.Printf("Dropping %d pending change notices for missing session %s", len(notices), sessionID)
+			continue
+		}
+		if session.Status == models.SessionStatusCancelled {
+			continue
+		}
+
+		messages := make([]NotificationMessage, 0, len(notices))
+		for _, p := range notices {
+			var changes []SessionChange
+			if err := json.Unmarshal([]byte(p.Changes), &changes); err != nil {
+				log.Printf("Error decoding pending changes %s: %v", p.ID, err)
+				continue
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			title, body, data := sessionChangedMessage(session, changes)
+			messages = append(messages, NotificationMessage{UserID: p.UserID, Title: title, Body: body, Data: data})
+		}
+
+		if _, err := d.notificationService.SendBatch(ctx, models.NotificationSessionChanged, messages); err != nil {
+			log.Printf("Error sending session change notices for session %s: %v", sessionID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+// SessionHistory is sessions that have ended, each with its final RSVPs
+// (archived ones included, with their members) and the attendance taken at
+// it, keyed by session ID
+type SessionHistory struct {
+	Sessions   []models.Session
+	Attendance map[uuid.UUID][]models.Attendance
+}
+
+// ListSessionHistory returns sessions that have ended within [from, to],
+// most recent first, including cancelled ones. Either bound may be nil.
+func (s *SessionService) ListSessionHistory(from, to *time.Time, limit, offset int) (*SessionHistory, error) {
+	query := database.DB.Where("ends_at <= ?", time.Now())
+	if from != nil {
+		query = query.Where("session_date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("session_date <= ?", *to)
+	}
+
+	var sessions []models.Session
+	if err := query.
+		Preload("Coach").
+		Order("starts_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	history := &SessionHistory{Sessions: sessions, Attendance: map[uuid.UUID][]models.Attendance{}}
+	if len(sessions) == 0 {
+		return history, nil
+	}
+	ids := make([]uuid.UUID, len(sessions))
+	for i := range sessions {
+		ids[i] = sessions[i].ID
+	}
+
+	rsvps, err := finalRSVPs(ids, nil)
+	if err != nil {
+		return nil, err
+	}
+	bySession := make(map[uuid.UUID][]models.RSVP, len(sessions))
+	for _, r := range rsvps {
+		bySession[r.SessionID] = append(bySession[r.SessionID], r)
+	}
+	for i := range history.Sessions {
+		history.Sessions[i].RSVPs = bySession[history.Sessions[i].ID]
+	}
+
+	var attendance []models.Attendance
+	if err := database.DB.Where("session_id IN ?", ids).
+		Preload("User").
+		Order("created_at ASC").
+		Find(&attendance).Error; err != nil {
+		return nil, err
+	}
+	for _, a := range attendance {
+		history.Attendance[a.SessionID] = append(history.Attendance[a.SessionID], a)
+	}
+	return history, nil
+}
+
+// PlayedSession is a session a member played, with their RSVP to it and
+// their attendance record if one was taken
+type PlayedSession struct {
+	Session    models.Session
+	RSVP       *models.RSVP       // nil for a walk-in
+	Attendance *models.Attendance // nil where attendance wasn't taken
+}
+
+// ListPlayedSessions returns the sessions a member played within [from, to],
+// most recent first. They played a session that went ahead if they were
+// marked present, or were IN to one where no attendance was taken; being IN
+// where attendance was taken but not being marked present doesn't count.
+func (s *SessionService) ListPlayedSessions(userID uuid.UUID, from, to *time.Time, limit, offset int) ([]PlayedSession, error) {
+	wasIn := database.DB.Raw(`SELECT session_id FROM rsvps WHERE user_id = ? AND status = ?
+		UNION ALL SELECT session_id FROM `+models.ArchivedRSVP{}.TableName()+` WHERE user_id = ? AND status = ?`,
+		userID, models.RSVPStatusIn, userID, models.RSVPStatusIn)
+	query := database.DB.
+		Where("status != ? AND ends_at <= ?", models.SessionStatusCancelled, time.Now()).
+		Where(`(EXISTS (SELECT 1 FROM attendances a WHERE a.session_id = sessions.id AND a.user_id = ? AND a.status = ?)
+			OR (sessions.id IN (?) AND NOT EXISTS (SELECT 1 FROM attendances a WHERE a.session_id = sessions.id)))`,
+			userID, models.AttendancePresent, wasIn)
+	if from != nil {
+		query = query.Where("session_date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("session_date <= ?", *to)
+	}
+
+	var sessions []models.Session
+	if err := query.
+		Preload("Coach").
+		Order("starts_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return []PlayedSession{}, nil
+	}
+	ids := make([]uuid.UUID, len(sessions))
+	for i := range sessions {
+		ids[i] = sessions[i].ID
+	}
+
+	rsvps, err := finalRSVPs(ids, &userID)
+	if err != nil {
+		return nil, err
+	}
+	myRSVPs := make(map[uuid.UUID]models.RSVP, len(rsvps))
+	for _, r := range rsvps {
+		myRSVPs[r.SessionID] = r
+	}
+	var attendance []models.Attendance
+	if err := database.DB.Where("session_id IN ? AND user_id = ?", ids, userID).
+		Find(&attendance).Error; err != nil {
+		return nil, err
+	}
+	myAttendance := make(map[uuid.UUID]models.Attendance, len(attendance))
+	for _, a := range attendance {
+		myAttendance[a.SessionID] = a
+	}
+
+	played := make([]PlayedSession, len(sessions))
+	for i, session := range sessions {
+		played[i] = PlayedSession{Session: session}
+		if r, ok := myRSVPs[session.ID]; ok {
+			played[i].RSVP = &r
+		}
+		if a, ok := myAttendance[session.ID]; ok {
+			played[i].Attendance = &a
+		}
+	}
+	return played, nil
+}
+
+// finalRSVPs returns the RSVPs to sessionIDs, live and archived, with their
+// members, oldest first. A non-nil userID narrows them to that member's.
+func finalRSVPs(sessionIDs []uuid.UUID, userID *uuid.UUID) ([]models.RSVP, error) {
+	live := database.DB.Where("session_id IN ?", sessionIDs).Preload("User")
+	archivedQuery := database.DB.Where("session_id IN ?", sessionIDs)
+	if userID != nil {
+		live = live.Where("user_id = ?", *userID)
+		archivedQuery = archivedQuery.Where("user_id = ?", *userID)
+	}
+
+	var rsvps []models.RSVP
+	if err := live.Find(&rsvps).Error; err != nil {
+		return nil, err
+	}
+	var archived []models.ArchivedRSVP
+	if err := archivedQuery.Find(&archived).Error; err != nil {
+		return nil, err
+	}
+	if len(archived) > 0 {
+		userIDs := make([]uuid.UUID, len(archived))
+		for i, a := range archived {
+			userIDs[i] = a.UserID
+		}
+		var users []models.User
+		if err := database.DB.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		byID := make(map[uuid.UUID]*models.User, len(users))
+		for i := range users {
+			byID[users[i].ID] = &users[i]
+		}
+		for _, a := range archived {
+			rsvps = append(rsvps, models.RSVP{
+				ID:            a.ID,
+				SessionID:     a.SessionID,
+				UserID:        a.UserID,
+				Status:        a.Status,
+				RSVPTimestamp: a.RSVPTimestamp,
+				IsLateRSVP:    a.IsLateRSVP,
+				AddedByAdmin:  a.AddedByAdmin,
+				AsRegular:     a.AsRegular,
+				CheckedInAt:   a.CheckedInAt,
+				CreatedAt:     a.CreatedAt,
+				UpdatedAt:     a.UpdatedAt,
+				User:          byID[a.UserID],
+			})
+		}
+	}
+
+	sort.SliceStable(rsvps, func(i, j int) bool { return rsvps[i].RSVPTimestamp.Before(rsvps[j].RSVPTimestamp) })
+	return rsvps, nil
+}
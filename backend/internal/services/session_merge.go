@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// RSVPConflict decides which answer a member keeps when they RSVP'd to both
+// sessions being merged. Their earliest RSVP time is kept either way, so
+// nobody loses their place in the queue.
+type RSVPConflict string
+
+const (
+	RSVPConflictLatest        RSVPConflict = "latest"         // the answer they gave most recently
+	RSVPConflictMostCommitted RSVPConflict = "most_committed" // in, then waitlisted, requested, maybe, declined, out
+	RSVPConflictTarget        RSVPConflict = "target"         // the kept session's answer
+	RSVPConflictSource        RSVPConflict = "source"         // the duplicate's answer
+)
+
+// rsvpCommitment ranks statuses for RSVPConflictMostCommitted
+var rsvpCommitment = map[models.RSVPStatus]int{
+	models.RSVPStatusIn:         5,
+	models.RSVPStatusWaitlisted: 4,
+	models.RSVPStatusRequested:  3,
+	models.RSVPStatusMaybe:      2,
+	models.RSVPStatusDeclined:   1,
+	models.RSVPStatusOut:        0,
+}
+
+// MergedRSVP describes how one member's RSVPs to both sessions were combined
+type MergedRSVP struct {
+	UserID       uuid.UUID         `json:"user_id"`
+	TargetStatus models.RSVPStatus `json:"target_status"`
+	SourceStatus models.RSVPStatus `json:"source_status"`
+	Status       models.RSVPStatus `json:"status"`
+}
+
+// MergeResult summarises a merge
+type MergeResult struct {
+	Session          *models.Session `json:"-"`
+	MovedRSVPs       int             `json:"moved_rsvps"`
+	MergedRSVPs      []MergedRSVP    `json:"merged_rsvps"`
+	MovedComments    int64           `json:"moved_comments"`
+	MovedAttachments int64           `json:"moved_attachments"`
+}
+
+// MergeSessions folds the duplicate session sourceID into targetID: RSVPs,
+// comments and attachments move across, members who RSVP'd to both keep their earliest RSVP
+// time and the answer chosen by conflict, and the duplicate is cancelled.
+// Members whose RSVP moved are told where it went.
+func (s *SessionService) MergeSessions(targetID, sourceID uuid.UUID, conflict RSVPConflict, actorID uuid.UUID) (*MergeResult, error) {
+	switch conflict {
+	case "":
+		conflict = RSVPConflictLatest
+	case RSVPConflictLatest, RSVPConflictMostCommitted, RSVPConflictTarget, RSVPConflictSource:
+	default:
+		return nil, fmt.Errorf("unknown rsvp conflict option %q", conflict)
+	}
+	if targetID == sourceID {
+		return nil, errors.New("cannot merge a session into itself")
+	}
+
+	result := &MergeResult{MergedRSVPs: []MergedRSVP{}}
+	var target, source models.Session
+	var movedUserIDs []uuid.UUID
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&target, "id = ?", targetID).Error; err != nil {
+			return ErrSessionNotFound
+		}
+		if err := tx.First(&source, "id = ?", sourceID).Error; err != nil {
+			return domainError(ErrNotFound, "session_not_found", "session to merge not found")
+		}
+		if target.Status == models.SessionStatusCancelled {
+			return errors.New("cannot merge into a cancelled session")
+		}
+		if source.Status == models.SessionStatusCancelled {
+			return errors.New("session to merge is already cancelled")
+		}
+
+		var targetRSVPs, sourceRSVPs []models.RSVP
+		if err := tx.Where("session_id = ?", targetID).Find(&targetRSVPs).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("session_id = ?", sourceID).Find(&sourceRSVPs).Error; err != nil {
+			return err
+		}
+		existing := make(map[uuid.UUID]models.RSVP, len(targetRSVPs))
+		for _, r := range targetRSVPs {
+			existing[r.UserID] = r
+		}
+
+		now := time.Now()
+		for _, r := range sourceRSVPs {
+			movedUserIDs = append(movedUserIDs, r.UserID)
+
+			kept, ok := existing[r.UserID]
+			if !ok {
+				if err := tx.Model(&models.RSVP{}).Where("id = ?", r.ID).
+					Updates(map[string]interface{}{"session_id": targetID, "updated_at": now}).Error; err != nil {
+					return err
+				}
+				if err := models.RecordRSVPEvent(tx, sourceID, r.UserID, ""); err != nil {
+					return err
+				}
+				if err := models.RecordRSVPEvent(tx, targetID, r.UserID, r.Status); err != nil {
+					return err
+				}
+				result.MovedRSVPs++
+				continue
+			}
+
+			merged := resolveRSVPConflict(kept, r, conflict)
+			if r.RSVPTimestamp.Before(merged.RSVPTimestamp) {
+				merged.RSVPTimestamp = r.RSVPTimestamp
+			}
+			// Delete the duplicate's RSVP first, recording a tombstone for sync
+			if err := tx.Delete(&r).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.RSVP{}).Where("id = ?", kept.ID).Updates(map[string]interface{}{
+				"status":         merged.Status,
+				"rsvp_timestamp": merged.RSVPTimestamp,
+				"is_late_rsvp":   merged.IsLateRSVP,
+				"added_by_admin": merged.AddedByAdmin,
+				"updated_at":     now,
+			}).Error; err != nil {
+				return err
+			}
+			if err := models.RecordRSVPEvent(tx, targetID, kept.UserID, merged.Status); err != nil {
+				return err
+			}
+			result.MergedRSVPs = append(result.MergedRSVPs, MergedRSVP{
+				UserID:       r.UserID,
+				TargetStatus: kept.Status,
+				SourceStatus: r.Status,
+				Status:       merged.Status,
+			})
+		}
+
+		// Guests come along with the members bringing them
+		if err := tx.Model(&models.GuestRSVP{}).Where("session_id = ?", sourceID).
+			Update("session_id", targetID).Error; err != nil {
+			return err
+		}
+
+		for _, id := range []uuid.UUID{sourceID, targetID} {
+			if err := models.RefreshRSVPCounts(tx, id); err != nil {
+				return err
+			}
+		}
+
+		// Members waiting for the duplicate join the end of the kept
+		// session's waitlist, and any spot it has free is offered
+		if err := tx.Where("session_id = ?", sourceID).Delete(&models.WaitlistEntry{}).Error; err != nil {
+			return err
+		}
+		if err := rebuildWaitlist(tx, targetID); err != nil {
+			return err
+		}
+		if _, err := offerOpenSpots(tx, target); err != nil {
+			return err
+		}
+
+		comments := tx.Model(&models.Comment{}).Where("session_id = ?", sourceID).
+			Update("session_id", targetID)
+		if comments.Error != nil {
+			return comments.Error
+		}
+		result.MovedComments = comments.RowsAffected
+
+		attachments := tx.Model(&models.SessionAttachment{}).Where("session_id = ?", sourceID).
+			Update("session_id", targetID)
+		if attachments.Error != nil {
+			return attachments.Error
+		}
+		result.MovedAttachments = attachments.RowsAffected
+
+		updates := map[string]interface{}{"updated_at": now}
+		if source.AdminNotes != "" {
+			notes := source.AdminNotes
+			if target.AdminNotes != "" {
+				notes = target.AdminNotes + "\n\n" + notes
+			}
+			updates["admin_notes"] = notes
+		}
+		if err := tx.Model(&models.Session{}).Where("id = ?", targetID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Session{}).Where("id = ?", sourceID).Updates(map[string]interface{}{
+			"status":              models.SessionStatusCancelled,
+			"cancellation_reason": "Merged into " + target.Title,
+			"updated_at":          now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.AuditLog{
+			EntityType: "session",
+			EntityID:   targetID,
+			Action:     models.AuditActionSessionMerged,
+			ActorID:    actorID,
+			OldValue:   sourceID.String(),
+			NewValue:   targetID.String(),
+			Reason:     string(conflict),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.outbox.Dispatch()
+	s.notifySessionMerged(source, target, movedUserIDs)
+
+	merged, err := s.GetSessionByID(targetID)
+	if err != nil {
+		return nil, err
+	}
+	result.Session = merged
+	return result, nil
+}
+
+// resolveRSVPConflict picks which of a member's two RSVPs supplies their answer
+func resolveRSVPConflict(target, source models.RSVP, conflict RSVPConflict) models.RSVP {
+	switch conflict {
+	case RSVPConflictSource:
+		return source
+	case RSVPConflictTarget:
+		return target
+	case RSVPConflictMostCommitted:
+		if rsvpCommitment[source.Status] > rsvpCommitment[target.Status] {
+			return source
+		}
+		return target
+	default:
+		if source.UpdatedAt.After(target.UpdatedAt) {
+			return source
+		}
+		return target
+	}
+}
+
+// notifySessionMerged tells members who RSVP'd to the duplicate where their RSVP went
+func (s *SessionService) notifySessionMerged(source, target models.Session, userIDs []uuid.UUID) {
+	if s.notificationService == nil || len(userIDs) == 0 {
+		return
+	}
+
+	title := "Session Merged"
+	body := fmt.Sprintf("%s was a duplicate, so your RSVP has moved to %s (%s, %s).",
+		source.Title, target.Title, utils.FormatDateForDisplay(target.SessionDate), utils.FormatClock(target.StartsAt))
+	data := map[string]string{
+		"type":              string(models.NotificationSessionChanged),
+		"session_id":        target.ID.String(),
+		"merged_session_id": source.ID.String(),
+	}
+
+	// Sent in the background, so don't tie it to the request context
+	s.notificationService.SendBulkNotification(context.Background(), userIDs, models.NotificationSessionChanged, title, body, data)
+}
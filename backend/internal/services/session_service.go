@@ -1,83 +1,278 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/weekday-masters/backend/internal/database"
 	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/storage"
 	"github.com/weekday-masters/backend/internal/utils"
 	"gorm.io/gorm"
 )
 
-type SessionService struct{}
+// urgentCancellationWindow is how close to its start a cancellation is
+// escalated to confirmed players by SMS as well as push and email
+const urgentCancellationWindow = 3 * time.Hour
 
-func NewSessionService() *SessionService {
-	return &SessionService{}
+type SessionService struct {
+	notificationService *NotificationService
+	changeDigest        *SessionChangeDigest // nil sends change notices straight away
+	store               storage.Store        // attachments; nil when no storage backend is configured
+	outbox              *Outbox
+}
+
+func NewSessionService(notificationService *NotificationService, changeDigest *SessionChangeDigest, store storage.Store, outbox *Outbox) *SessionService {
+	return &SessionService{notificationService: notificationService, changeDigest: changeDigest, store: store, outbox: outbox}
 }
 
 type CreateSessionInput struct {
 	Title              string
 	Description        string
 	SessionDate        time.Time
-	StartTime          string
-	EndTime            string
+	StartTime          utils.Clock
+	EndTime            utils.Clock
 	Courts             int
+	IsOutdoor          bool
+	RequiresApproval   bool
+	FairShare          bool
+	AllowGuests        bool
 	IsRecurring        bool
 	RecurringDayOfWeek *int
 	Occurrences        *int
+	SessionType        models.SessionType // empty for a social session
+	CoachID            *uuid.UUID         // training sessions only
+	CurriculumNotes    string
+	Spots              *int // training sessions only: fewer players than the courts hold
+	Overbooking        int  // extra INs taken past the spots until RSVPs close
 	CreatedBy          uuid.UUID
 }
 
 // CreateSession creates a new session
 func (s *SessionService) CreateSession(input CreateSessionInput) (*models.Session, error) {
-	if input.Courts < 1 || input.Courts > 3 {
-		return nil, errors.New("courts must be between 1 and 3")
+	if err := validateCourts(input.Courts); err != nil {
+		return nil, err
 	}
 
+	startsAt, endsAt := sessionTimes(input.SessionDate, input.StartTime, input.EndTime)
 	session := models.Session{
 		Title:              input.Title,
 		Description:        input.Description,
 		SessionDate:        input.SessionDate,
-		StartTime:          input.StartTime,
-		EndTime:            input.EndTime,
+		StartsAt:           startsAt,
+		EndsAt:             endsAt,
 		Courts:             input.Courts,
-		MaxPlayers:         models.MaxPlayersForCourts(input.Courts),
+		MaxPlayers:         maxPlayersFor(input.Courts),
 		RSVPDeadline:       utils.CalculateRSVPDeadline(input.SessionDate),
+		IsOutdoor:          input.IsOutdoor,
+		RequiresApproval:   input.RequiresApproval || input.FairShare,
+		FairShare:          input.FairShare,
+		AllowGuests:        input.AllowGuests,
 		IsRecurring:        input.IsRecurring,
 		RecurringDayOfWeek: input.RecurringDayOfWeek,
 		Status:             models.SessionStatusOpen,
+		SessionType:        input.SessionType,
+		CurriculumNotes:    input.CurriculumNotes,
+		Overbooking:        input.Overbooking,
 		CreatedBy:          input.CreatedBy,
 	}
+	if session.SessionType == "" {
+		session.SessionType = models.SessionTypeSocial
+	}
+	if err := applyTraining(&session, input.CoachID, input.Spots); err != nil {
+		return nil, err
+	}
+	if err := validateOverbooking(&session); err != nil {
+		return nil, err
+	}
 
-	if err := database.DB.Create(&session).Error; err != nil {
+	if err := createSession(database.DB, &session, &input.CreatedBy); err != nil {
 		return nil, err
 	}
+	s.outbox.Dispatch()
 
-	// If recurring, generate sessions for the specified number of occurrences
+	// If recurring, generate the requested number of occurrences, or else
+	// enough to fill the club's look-ahead window
 	if input.IsRecurring && input.RecurringDayOfWeek != nil {
-		occurrences := 4 // default
-		if input.Occurrences != nil && *input.Occurrences > 0 {
-			occurrences = *input.Occurrences
-		}
-		s.generateRecurringSessions(&session, occurrences)
+		s.generateRecurringSessions(&session, seriesEnd(session.SessionDate, input.Occurrences), nil)
 	}
 
 	return &session, nil
 }
 
-// generateRecurringSessions creates recurring session instances
-func (s *SessionService) generateRecurringSessions(parent *models.Session, occurrences int) error {
+// SessionCreatedEvent is the data of the session.created domain event
+type SessionCreatedEvent struct {
+	Title             string             `json:"title"`
+	SessionType       models.SessionType `json:"session_type"`
+	StartsAt          time.Time          `json:"starts_at"`
+	EndsAt            time.Time          `json:"ends_at"`
+	MaxPlayers        int                `json:"max_players"`
+	RecurringParentID *uuid.UUID         `json:"recurring_parent_id,omitempty"`
+}
+
+// createSession inserts session along with its session.created event.
+// Sessions generated for a series have no actor.
+func createSession(db *gorm.DB, session *models.Session, actorID *uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, Event{
+			Type:      models.EventSessionCreated,
+			SessionID: &session.ID,
+			ActorID:   actorID,
+			Data: SessionCreatedEvent{
+				Title:             session.Title,
+				SessionType:       session.SessionType,
+				StartsAt:          session.StartsAt,
+				EndsAt:            session.EndsAt,
+				MaxPlayers:        session.MaxPlayers,
+				RecurringParentID: session.RecurringParentID,
+			},
+		})
+	})
+}
+
+// sessionTimes returns when a session on date between start and end begins
+// and ends. An end at or before the start is taken to be after midnight.
+func sessionTimes(date time.Time, start, end utils.Clock) (time.Time, time.Time) {
+	startsAt := start.On(date)
+	endsAt := end.On(date)
+	if !endsAt.After(startsAt) {
+		endsAt = end.On(date.AddDate(0, 0, 1))
+	}
+	return startsAt, endsAt
+}
+
+// applyTraining sets a training session's coach, and its spots when given,
+// and checks that only training sessions have them
+func applyTraining(session *models.Session, coachID *uuid.UUID, spots *int) error {
+	switch session.SessionType {
+	case models.SessionTypeSocial:
+		if coachID != nil || spots != nil || session.CurriculumNotes != "" {
+			return errors.New("only training sessions have a coach, curriculum notes or a set number of spots")
+		}
+		session.CoachID = nil
+		return nil
+	case models.SessionTypeTraining:
+	default:
+		return fmt.Errorf("unknown session type %q", session.SessionType)
+	}
+
+	if coachID != nil {
+		var coach models.User
+		if err := database.DB.First(&coach, "id = ?", *coachID).Error; err != nil {
+			return domainError(ErrNotFound, "coach_not_found", "coach not found")
+		}
+		if !coach.IsCoach() || !coach.IsApproved() {
+			return fmt.Errorf("%s isn't a coach", coach.Name)
+		}
+		session.CoachID = coachID
+	}
+	if session.CoachID == nil {
+		return errors.New("a training session needs a coach")
+	}
+	if spots != nil {
+		if capacity := maxPlayersFor(session.Courts); *spots < 1 || *spots > capacity {
+			return fmt.Errorf("spots must be between 1 and %d, what %d courts hold", capacity, session.Courts)
+		}
+		session.MaxPlayers = *spots
+	}
+	return nil
+}
+
+// validateCourts checks a session's court count
+func validateCourts(courts int) error {
+	if courts < 1 || courts > models.MaxCourts {
+		return fmt.Errorf("courts must be between 1 and %d", models.MaxCourts)
+	}
+	return nil
+}
+
+// validateOverbooking checks a session's overbooking margin against its spots
+func validateOverbooking(session *models.Session) error {
+	if session.Overbooking < 0 || session.Overbooking > session.MaxPlayers {
+		return fmt.Errorf("overbooking must be between 0 and %d, the session's spots", session.MaxPlayers)
+	}
+	if session.Overbooking > 0 && session.FairShare {
+		return errors.New("fair-share sessions are allocated exactly to their spots, so they can't be overbooked")
+	}
+	return nil
+}
+
+// maxPlayersFor returns the capacity of a session on courts under the club's settings
+func maxPlayersFor(courts int) int {
+	var club models.Club
+	if err := database.DB.First(&club).Error; err != nil {
+		return models.MaxPlayersForCourts(courts)
+	}
+	return club.MaxPlayersForCourts(courts)
+}
+
+// recurringHorizon returns the last date recurring sessions are generated up
+// to, from the club's look-ahead window
+func recurringHorizon() time.Time {
+	weeks := models.DefaultRecurringWeeksAhead
+	var club models.Club
+	if err := database.DB.First(&club).Error; err == nil && club.RecurringWeeksAhead > 0 {
+		weeks = club.RecurringWeeksAhead
+	}
+	return utils.StartOfDay(utils.NowInSydney()).AddDate(0, 0, 7*weeks)
+}
+
+// seriesEnd returns the last date a new series starting on first is
+// generated up to: its last occurrence, or the look-ahead window if it has
+// no set number
+func seriesEnd(first time.Time, occurrences *int) time.Time {
+	if occurrences != nil && *occurrences > 0 {
+		return first.AddDate(0, 0, 7*(*occurrences-1))
+	}
+	return recurringHorizon()
+}
+
+// recurrenceDates returns the weekly dates after first, up to and including until
+func recurrenceDates(first, until time.Time) []time.Time {
+	var dates []time.Time
+	// Dates read back from the database are midnight UTC, so compare calendar days
+	for next := first.AddDate(0, 0, 7); !utils.StartOfDay(next).After(until); next = next.AddDate(0, 0, 7) {
+		dates = append(dates, next)
+	}
+	return dates
+}
+
+// recurringTitle is the title of a generated session, e.g. "Monday - 02 Jan 2006"
+func recurringTitle(date time.Time) string {
+	return date.Format("Monday - 02 Jan 2006")
+}
+
+// generateRecurringSessions creates the weekly instances of parent up to and
+// including until, skipping any already past. On a dry run they're only
+// recorded in report.
+func (s *SessionService) generateRecurringSessions(parent *models.Session, until time.Time, report *JobReport) error {
 	if parent.RecurringDayOfWeek == nil {
 		return nil
 	}
 
+	today := utils.StartOfDay(utils.NowInSydney())
+	blackouts, err := blackoutDates()
+	if err != nil {
+		return err
+	}
+
 	// Start from the next week after the parent session
-	nextDate := parent.SessionDate.AddDate(0, 0, 7)
+	for _, nextDate := range recurrenceDates(parent.SessionDate, until) {
+		if utils.StartOfDay(nextDate).Before(today) {
+			continue
+		}
+		if _, ok := blackouts[dateKey(nextDate)]; ok {
+			continue
+		}
 
-	// Generate sessions for the specified number of occurrences (minus 1 since parent counts as first)
-	for i := 0; i < occurrences-1; i++ {
 		// Check if session already exists
 		var count int64
 		database.DB.Model(&models.Session{}).
@@ -85,72 +280,170 @@ func (s *SessionService) generateRecurringSessions(parent *models.Session, occur
 			Count(&count)
 
 		if count == 0 {
-			// Generate title for this occurrence in format "Day - DD MMM YYYY"
-			childTitle := nextDate.Format("Monday - 02 Jan 2006")
-
-			child := models.Session{
-				Title:             childTitle,
-				Description:       parent.Description,
-				SessionDate:       nextDate,
-				StartTime:         parent.StartTime,
-				EndTime:           parent.EndTime,
-				Courts:            parent.Courts,
-				MaxPlayers:        parent.MaxPlayers,
-				RSVPDeadline:      utils.CalculateRSVPDeadline(nextDate),
-				IsRecurring:       false,
-				RecurringParentID: &parent.ID,
-				Status:            models.SessionStatusOpen,
-				CreatedBy:         parent.CreatedBy,
+			child := recurringChild(parent, nextDate)
+			childTitle := child.Title
+			regulars := 0
+			if !report.isDryRun() {
+				if err := createSession(database.DB, &child, nil); err == nil {
+					regulars = rsvpRegulars(&child, parent.ID)
+				}
 			}
-			database.DB.Create(&child)
-		}
 
-		nextDate = nextDate.AddDate(0, 0, 7)
+			action := JobAction{Kind: "create_session", Title: childTitle, Detail: "Repeats " + parent.Title}
+			if regulars > 0 {
+				action.Detail += fmt.Sprintf(", with %d regulars IN", regulars)
+			}
+			if child.ID != uuid.Nil {
+				action.SessionID = &child.ID
+			}
+			report.add(action)
+		}
 	}
 
 	return nil
 }
 
+// recurringChild is the occurrence of a recurring series on date
+func recurringChild(parent *models.Session, date time.Time) models.Session {
+	startsAt, endsAt := sessionTimes(date, utils.ClockOf(parent.StartsAt), utils.ClockOf(parent.EndsAt))
+	return models.Session{
+		Title:             recurringTitle(date),
+		Description:       parent.Description,
+		SessionDate:       date,
+		StartsAt:          startsAt,
+		EndsAt:            endsAt,
+		Courts:            parent.Courts,
+		MaxPlayers:        parent.MaxPlayers,
+		RSVPDeadline:      utils.CalculateRSVPDeadline(date),
+		IsOutdoor:         parent.IsOutdoor,
+		RequiresApproval:  parent.RequiresApproval,
+		FairShare:         parent.FairShare,
+		AllowGuests:       parent.AllowGuests,
+		SessionType:       parent.SessionType,
+		CoachID:           parent.CoachID,
+		CurriculumNotes:   parent.CurriculumNotes,
+		Overbooking:       parent.Overbooking,
+		IsRecurring:       false,
+		RecurringParentID: &parent.ID,
+		Status:            models.SessionStatusOpen,
+		CreatedBy:         parent.CreatedBy,
+	}
+}
+
 // RefreshRecurringSessions generates any missing recurring session instances
-// This is called for maintenance/refresh - uses default of 4 weeks ahead
+// so each series always has the club's look-ahead window of sessions ahead
 func (s *SessionService) RefreshRecurringSessions() error {
+	return s.refreshRecurringSessions(nil)
+}
+
+func (s *SessionService) refreshRecurringSessions(report *JobReport) error {
 	var parentSessions []models.Session
 	if err := database.DB.Where("is_recurring = ? AND status = ?", true, models.SessionStatusOpen).
 		Find(&parentSessions).Error; err != nil {
 		return err
 	}
 
+	until := recurringHorizon()
 	for _, parent := range parentSessions {
-		s.generateRecurringSessions(&parent, 4) // Default to 4 weeks for refresh
+		s.generateRecurringSessions(&parent, until, report)
 	}
 
 	return nil
 }
 
+// TrimRecurringSessions deletes generated sessions beyond the club's
+// look-ahead window, for when the window shrinks. Sessions anyone has
+// RSVP'd to or commented on, that an admin has closed or cancelled, or that
+// a season rollover scheduled, are kept. It returns how many were deleted.
+func (s *SessionService) TrimRecurringSessions() (int, error) {
+	var children []models.Session
+	if err := database.DB.
+		Where("recurring_parent_id IS NOT NULL AND season_rollover_id IS NULL AND status = ? AND session_date > ?",
+			models.SessionStatusOpen, recurringHorizon().Format("2006-01-02")).
+		Where("NOT EXISTS (SELECT 1 FROM rsvps WHERE rsvps.session_id = sessions.id)").
+		Where("NOT EXISTS (SELECT 1 FROM comments WHERE comments.session_id = sessions.id)").
+		Find(&children).Error; err != nil {
+		return 0, err
+	}
+
+	for i := range children {
+		if err := database.DB.Delete(&children[i]).Error; err != nil {
+			return i, err
+		}
+	}
+	return len(children), nil
+}
+
 // GetSessionByID retrieves a session by ID with RSVPs and user details
 func (s *SessionService) GetSessionByID(id uuid.UUID) (*models.Session, error) {
 	var session models.Session
 	if err := database.DB.Preload("RSVPs", func(db *gorm.DB) *gorm.DB {
 		return db.Order("rsvp_timestamp ASC")
-	}).Preload("RSVPs.User").Preload("Creator").
+	}).Preload("RSVPs.User").Preload("Creator").Preload("Coach").Preload("Attachments", orderAttachments).
 		First(&session, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &session, nil
 }
 
-// ListUpcomingSessions returns upcoming sessions
-func (s *SessionService) ListUpcomingSessions() ([]models.Session, error) {
+// SessionListFilter narrows the sessions listed. Dates are inclusive; with
+// neither From nor IncludePast, only sessions from today onwards are listed.
+type SessionListFilter struct {
+	From        *time.Time
+	To          *time.Time
+	IncludePast bool
+}
+
+// ListSessions returns sessions that aren't cancelled, soonest first
+func (s *SessionService) ListSessions(filter SessionListFilter) ([]models.Session, error) {
+	query := database.DB.Where("status != ?", models.SessionStatusCancelled)
+	switch {
+	case filter.From != nil:
+		query = query.Where("session_date >= ?", *filter.From)
+	case !filter.IncludePast:
+		query = query.Where("session_date >= ?", utils.StartOfDay(utils.NowInSydney()))
+	}
+	if filter.To != nil {
+		query = query.Where("session_date <= ?", *filter.To)
+	}
+
 	var sessions []models.Session
-	now := utils.NowInSydney()
-	today := utils.StartOfDay(now)
+	if err := query.
+		Preload("RSVPs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("rsvp_timestamp ASC")
+		}).
+		Preload("RSVPs.User").
+		Preload("Attachments", orderAttachments).
+		Order("starts_at ASC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
 
-	if err := database.DB.Where("session_date >= ? AND status != ?", today, models.SessionStatusCancelled).
+// ListPastSessions returns sessions before today within [from, to], most
+// recent first, including cancelled ones so the history is complete.
+// Either bound may be nil.
+func (s *SessionService) ListPastSessions(from, to *time.Time, limit, offset int) ([]models.Session, error) {
+	query := database.DB.Where("session_date < ?", utils.StartOfDay(utils.NowInSydney()))
+	if from != nil {
+		query = query.Where("session_date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("session_date <= ?", *to)
+	}
+
+	var sessions []models.Session
+	if err := query.
 		Preload("RSVPs", func(db *gorm.DB) *gorm.DB {
 			return db.Order("rsvp_timestamp ASC")
 		}).
 		Preload("RSVPs.User").
-		Order("session_date ASC, start_time ASC").
+		Preload("Attachments", orderAttachments).
+		Order("starts_at DESC").
+		Limit(limit).
+		Offset(offset).
 		Find(&sessions).Error; err != nil {
 		return nil, err
 	}
@@ -165,7 +458,7 @@ func (s *SessionService) ListCancelledUpcomingSessions() ([]models.Session, erro
 	today := utils.StartOfDay(now)
 
 	if err := database.DB.Where("session_date >= ? AND status = ?", today, models.SessionStatusCancelled).
-		Order("session_date ASC, start_time ASC").
+		Order("starts_at ASC").
 		Find(&sessions).Error; err != nil {
 		return nil, err
 	}
@@ -174,13 +467,22 @@ func (s *SessionService) ListCancelledUpcomingSessions() ([]models.Session, erro
 }
 
 type UpdateSessionInput struct {
-	Title       *string
-	Description *string
-	SessionDate *time.Time
-	StartTime   *string
-	EndTime     *string
-	Courts      *int
-	Status      *models.SessionStatus
+	Title            *string
+	Description      *string
+	SessionDate      *time.Time
+	StartTime        *utils.Clock
+	EndTime          *utils.Clock
+	Courts           *int
+	IsOutdoor        *bool
+	RequiresApproval *bool
+	FairShare        *bool
+	AllowGuests      *bool // guests already coming keep their spots when turned off
+	Status           *models.SessionStatus
+	SessionType      *models.SessionType
+	CoachID          *uuid.UUID
+	CurriculumNotes  *string
+	Spots            *int
+	Overbooking      *int
 }
 
 // UpdateSession updates a session
@@ -189,6 +491,7 @@ func (s *SessionService) UpdateSession(id uuid.UUID, input UpdateSessionInput) (
 	if err := database.DB.First(&session, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
+	before := session
 
 	if input.Title != nil {
 		session.Title = *input.Title
@@ -196,22 +499,68 @@ func (s *SessionService) UpdateSession(id uuid.UUID, input UpdateSessionInput) (
 	if input.Description != nil {
 		session.Description = *input.Description
 	}
-	if input.SessionDate != nil {
-		session.SessionDate = *input.SessionDate
-		session.RSVPDeadline = utils.CalculateRSVPDeadline(*input.SessionDate)
-	}
-	if input.StartTime != nil {
-		session.StartTime = *input.StartTime
-	}
-	if input.EndTime != nil {
-		session.EndTime = *input.EndTime
+	// Changing the date keeps the times of day, and changing a time keeps the date
+	if input.SessionDate != nil || input.StartTime != nil || input.EndTime != nil {
+		start, end := utils.ClockOf(session.StartsAt), utils.ClockOf(session.EndsAt)
+		if input.SessionDate != nil {
+			session.SessionDate = *input.SessionDate
+			session.RSVPDeadline = utils.CalculateRSVPDeadline(*input.SessionDate)
+		}
+		if input.StartTime != nil {
+			start = *input.StartTime
+		}
+		if input.EndTime != nil {
+			end = *input.EndTime
+		}
+		session.StartsAt, session.EndsAt = sessionTimes(session.SessionDate, start, end)
 	}
 	if input.Courts != nil {
-		if *input.Courts < 1 || *input.Courts > 3 {
-			return nil, errors.New("courts must be between 1 and 3")
+		if err := validateCourts(*input.Courts); err != nil {
+			return nil, err
+		}
+		capacity := maxPlayersFor(*input.Courts)
+		// Training sessions keep their spots, up to what the courts hold
+		if session.SessionType != models.SessionTypeTraining || session.MaxPlayers > capacity {
+			session.MaxPlayers = capacity
 		}
 		session.Courts = *input.Courts
-		session.MaxPlayers = models.MaxPlayersForCourts(*input.Courts)
+	}
+	if input.SessionType != nil {
+		// A training session made social drops its coach, notes and spots
+		if *input.SessionType != session.SessionType && *input.SessionType == models.SessionTypeSocial {
+			session.CoachID = nil
+			session.CurriculumNotes = ""
+			session.MaxPlayers = maxPlayersFor(session.Courts)
+		}
+		session.SessionType = *input.SessionType
+	}
+	if input.CurriculumNotes != nil {
+		session.CurriculumNotes = *input.CurriculumNotes
+	}
+	if err := applyTraining(&session, input.CoachID, input.Spots); err != nil {
+		return nil, err
+	}
+	if input.IsOutdoor != nil {
+		session.IsOutdoor = *input.IsOutdoor
+	}
+	if input.RequiresApproval != nil {
+		session.RequiresApproval = *input.RequiresApproval
+	}
+	if input.FairShare != nil {
+		session.FairShare = *input.FairShare
+	}
+	// Fair-share allocation works on requests, so it needs approval mode
+	if session.FairShare {
+		session.RequiresApproval = true
+	}
+	if input.AllowGuests != nil {
+		session.AllowGuests = *input.AllowGuests
+	}
+	if input.Overbooking != nil {
+		session.Overbooking = *input.Overbooking
+	}
+	if err := validateOverbooking(&session); err != nil {
+		return nil, err
 	}
 	if input.Status != nil {
 		session.Status = *input.Status
@@ -219,13 +568,133 @@ func (s *SessionService) UpdateSession(id uuid.UUID, input UpdateSessionInput) (
 
 	session.UpdatedAt = time.Now()
 
-	if err := database.DB.Save(&session).Error; err != nil {
+	changes := diffSession(before, session)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockSession(tx, session.ID); err != nil {
+			return err
+		}
+		if err := tx.Save(&session).Error; err != nil {
+			return err
+		}
+		err := recordEvent(tx, Event{
+			Type:      models.EventSessionUpdated,
+			SessionID: &session.ID,
+			Data:      SessionUpdatedEvent{Status: session.Status, Changes: append([]SessionChange{}, changes...)},
+		})
+		if err != nil {
+			return err
+		}
+		// Spots added, or a session reopened, go to the waitlist
+		_, err = offerOpenSpots(tx, session)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.outbox.Dispatch()
+
+	if len(changes) > 0 && session.Status != models.SessionStatusCancelled {
+		s.notifySessionChanged(session, changes)
+	}
 
 	return &session, nil
 }
 
+// SessionUpdatedEvent is the data of the session.updated domain event.
+// Changes lists only the material fields members are told about.
+type SessionUpdatedEvent struct {
+	Status  models.SessionStatus `json:"status"`
+	Changes []SessionChange      `json:"changes"`
+}
+
+// SessionChange is one material field of a session that was changed
+type SessionChange struct {
+	Field string `json:"field"`
+	Label string `json:"label"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// diffSession lists the changes members who RSVP'd need to hear about: when
+// and where they're playing, and how many can play
+func diffSession(before, after models.Session) []SessionChange {
+	var changes []SessionChange
+	add := func(field, label, old, new string) {
+		if old != new {
+			changes = append(changes, SessionChange{Field: field, Label: label, Old: old, New: new})
+		}
+	}
+
+	add("session_date", "Date", utils.FormatDateForDisplay(before.SessionDate), utils.FormatDateForDisplay(after.SessionDate))
+	add("start_time", "Start time", utils.FormatClock(before.StartsAt), utils.FormatClock(after.StartsAt))
+	add("end_time", "End time", utils.FormatClock(before.EndsAt), utils.FormatClock(after.EndsAt))
+	add("courts", "Courts", fmt.Sprint(before.Courts), fmt.Sprint(after.Courts))
+	add("location", "Location", sessionLocation(before), sessionLocation(after))
+
+	return changes
+}
+
+func sessionLocation(session models.Session) string {
+	if session.IsOutdoor {
+		return "Outdoor"
+	}
+	return "Indoor"
+}
+
+// notifySessionChanged tells members who RSVP'd in or maybe, are waitlisted
+// or asked to play what changed, through the digest when one is configured
+func (s *SessionService) notifySessionChanged(session models.Session, changes []SessionChange) {
+	if s.notificationService == nil {
+		return
+	}
+
+	var userIDs []uuid.UUID
+	if err := database.DB.Model(&models.RSVP{}).
+		Where("session_id = ? AND status IN ?", session.ID, []models.RSVPStatus{models.RSVPStatusIn, models.RSVPStatusWaitlisted, models.RSVPStatusMaybe, models.RSVPStatusRequested}).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		log.Printf("Error fetching RSVPs for session change: %v", err)
+		return
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+
+	if s.changeDigest != nil {
+		err := s.changeDigest.Queue(session.ID, userIDs, changes)
+		if err == nil {
+			return
+		}
+		log.Printf("Error queueing change notices for session %s, sending now: %v", session.ID, err)
+	}
+
+	title, body, data := sessionChangedMessage(session, changes)
+
+	// Sent in the background, so don't tie it to the request context
+	s.notificationService.SendBulkNotification(context.Background(), userIDs, models.NotificationSessionChanged, title, body, data)
+}
+
+// sessionChangedMessage describes changes to session, with each field's old
+// and new value in the data for clients
+func sessionChangedMessage(session models.Session, changes []SessionChange) (string, string, map[string]string) {
+	fields := make([]string, len(changes))
+	lines := make([]string, len(changes))
+	data := map[string]string{
+		"type":       string(models.NotificationSessionChanged),
+		"session_id": session.ID.String(),
+	}
+	for i, change := range changes {
+		fields[i] = change.Field
+		lines[i] = fmt.Sprintf("%s: %s → %s", change.Label, change.Old, change.New)
+		data["old_"+change.Field] = change.Old
+		data["new_"+change.Field] = change.New
+	}
+	data["changed_fields"] = strings.Join(fields, ",")
+
+	title := "Session Changed"
+	body := fmt.Sprintf("%s has been changed. %s.", session.Title, strings.Join(lines, "; "))
+	return title, body, data
+}
+
 // DeleteSession deletes or cancels a session
 func (s *SessionService) DeleteSession(id uuid.UUID) error {
 	var session models.Session
@@ -243,24 +712,272 @@ func (s *SessionService) DeleteSession(id uuid.UUID) error {
 		return database.DB.Save(&session).Error
 	}
 
-	// Otherwise, delete it
-	return database.DB.Delete(&session).Error
+	// Otherwise, delete it along with the history of RSVPs since withdrawn
+	// and its attachments
+	var attachments []models.SessionAttachment
+	if err := database.DB.Where("session_id = ?", id).Find(&attachments).Error; err != nil {
+		return err
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", id).Delete(&models.RSVPEvent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("session_id = ?", id).Delete(&models.SessionAttachment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("series_id = ?", id).Delete(&models.SeriesRegular{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&session).Error
+	})
+	if err != nil {
+		return err
+	}
+	for _, a := range attachments {
+		s.removeStoredAttachment(a)
+	}
+	return nil
 }
 
-// CancelSession cancels a session with an optional reason
+// CancelSession cancels a session with an optional reason and tells members
+// who RSVP'd. Confirmed players of a session starting within
+// urgentCancellationWindow get an urgent notice, so they hear by text even
+// if they're already on their way.
 func (s *SessionService) CancelSession(id uuid.UUID, reason string) (*models.Session, error) {
 	var session models.Session
 	if err := database.DB.First(&session, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
+	if session.Status == models.SessionStatusCancelled {
+		return nil, domainError(ErrConflict, "session_cancelled", "session is already cancelled")
+	}
 
 	session.Status = models.SessionStatusCancelled
 	session.CancellationReason = reason
 	session.UpdatedAt = time.Now()
 
-	if err := database.DB.Save(&session).Error; err != nil {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&session).Error; err != nil {
+			return err
+		}
+		notice, err := sessionCancelledNotice(tx, session)
+		if err != nil {
+			return err
+		}
+		return recordEvent(tx, Event{Type: models.EventSessionCancelled, SessionID: &session.ID, Data: SessionCancelledEvent{Reason: reason}}, notice)
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.outbox.Dispatch()
 
 	return &session, nil
 }
+
+// SessionCancelledEvent is the data of the session.cancelled domain event
+type SessionCancelledEvent struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// sessionCancelledNotice tells members who RSVP'd in or maybe, are waitlisted
+// or asked to play that a session is off, escalating for confirmed players
+// when it was about to start. Nobody is told once the session has ended.
+func sessionCancelledNotice(tx *gorm.DB, session models.Session) (OutboxNotification, error) {
+	notice := OutboxNotification{Type: models.NotificationSessionChanged}
+	now := time.Now()
+	if !now.Before(session.EndsAt) {
+		return notice, nil
+	}
+
+	var rsvps []models.RSVP
+	if err := tx.Select("user_id", "status").
+		Where("session_id = ? AND status IN ?", session.ID, []models.RSVPStatus{models.RSVPStatusIn, models.RSVPStatusWaitlisted, models.RSVPStatusMaybe, models.RSVPStatusRequested}).
+		Find(&rsvps).Error; err != nil {
+		return notice, fmt.Errorf("fetching RSVPs for session cancellation: %w", err)
+	}
+
+	urgent := session.StartsAt.Sub(now) < urgentCancellationWindow
+	title := "Session Cancelled"
+	body := fmt.Sprintf("%s on %s at %s has been cancelled.", session.Title,
+		utils.FormatDateForDisplay(session.SessionDate), session.StartsAt.In(utils.SydneyLocation).Format("3:04 PM"))
+	if session.CancellationReason != "" {
+		body += " Reason: " + session.CancellationReason
+	}
+	data := map[string]string{
+		"type":       string(models.NotificationSessionChanged),
+		"session_id": session.ID.String(),
+		"cancelled":  "true",
+	}
+
+	notice.Messages = make([]NotificationMessage, len(rsvps))
+	for i, rsvp := range rsvps {
+		notice.Messages[i] = NotificationMessage{
+			UserID: rsvp.UserID,
+			Title:  title,
+			Body:   body,
+			Data:   data,
+			Urgent: urgent && rsvp.Status == models.RSVPStatusIn,
+		}
+	}
+	return notice, nil
+}
+
+type SessionUsageInput struct {
+	ShuttlesUsed  *int
+	ActualStartAt *time.Time
+	ActualEndAt   *time.Time
+}
+
+// RecordUsage records shuttles used and the actual start/end of a session
+func (s *SessionService) RecordUsage(id uuid.UUID, input SessionUsageInput) (*models.Session, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	previouslyUsed := 0
+	if session.ShuttlesUsed != nil {
+		previouslyUsed = *session.ShuttlesUsed
+	}
+	if input.ShuttlesUsed != nil {
+		if *input.ShuttlesUsed < 0 {
+			return nil, errors.New("shuttles used cannot be negative")
+		}
+		session.ShuttlesUsed = input.ShuttlesUsed
+	}
+	if input.ActualStartAt != nil {
+		session.ActualStartAt = input.ActualStartAt
+	}
+	if input.ActualEndAt != nil {
+		session.ActualEndAt = input.ActualEndAt
+	}
+	if session.ActualStartAt != nil && session.ActualEndAt != nil && !session.ActualEndAt.After(*session.ActualStartAt) {
+		return nil, errors.New("actual end must be after actual start")
+	}
+
+	session.UpdatedAt = time.Now()
+
+	// A change in shuttles used draws the shuttle stock down (or back up)
+	used := 0
+	if session.ShuttlesUsed != nil {
+		used = *session.ShuttlesUsed
+	}
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&session).Error; err != nil {
+			return err
+		}
+		if used == previouslyUsed {
+			return nil
+		}
+		return recordShuttleUsage(tx, session.ID, used-previouslyUsed)
+	}); err != nil {
+		return nil, err
+	}
+	if used > previouslyUsed {
+		alertLowStock(context.Background(), s.notificationService)
+	}
+
+	return &session, nil
+}
+
+// GetAdminNotes returns a session's private admin notes
+func (s *SessionService) GetAdminNotes(id uuid.UUID) (string, error) {
+	var session models.Session
+	if err := database.DB.Select("id", "admin_notes").First(&session, "id = ?", id).Error; err != nil {
+		return "", err
+	}
+	return session.AdminNotes, nil
+}
+
+// UpdateAdminNotes replaces a session's private admin notes
+func (s *SessionService) UpdateAdminNotes(id uuid.UUID, notes string) error {
+	result := database.DB.Model(&models.Session{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"admin_notes": notes, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ExtendDeadline moves a session's RSVP deadline later, records the change in
+// the audit log, and lets members who haven't RSVP'd know they have more time
+func (s *SessionService) ExtendDeadline(id uuid.UUID, deadline time.Time, actorID uuid.UUID, reason string) (*models.Session, error) {
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	if session.Status == models.SessionStatusCancelled {
+		return nil, domainError(ErrConflict, "session_cancelled", "cannot extend the deadline of a cancelled session")
+	}
+	if !deadline.After(session.RSVPDeadline) {
+		return nil, errors.New("new deadline must be later than the current deadline")
+	}
+	if !deadline.After(time.Now()) {
+		return nil, errors.New("new deadline must be in the future")
+	}
+
+	if !deadline.Before(session.StartsAt) {
+		return nil, errors.New("new deadline must be before the session starts")
+	}
+
+	previous := session.RSVPDeadline
+	session.RSVPDeadline = deadline
+	session.UpdatedAt = time.Now()
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&session).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AuditLog{
+			EntityType: "session",
+			EntityID:   session.ID,
+			Action:     models.AuditActionDeadlineExtended,
+			ActorID:    actorID,
+			OldValue:   previous.Format(time.RFC3339),
+			NewValue:   deadline.Format(time.RFC3339),
+			Reason:     reason,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyDeadlineExtended(session)
+
+	return &session, nil
+}
+
+// notifyDeadlineExtended tells approved members without an RSVP about the new deadline
+func (s *SessionService) notifyDeadlineExtended(session models.Session) {
+	if s.notificationService == nil {
+		return
+	}
+
+	var userIDs []uuid.UUID
+	if err := database.DB.Model(&models.User{}).
+		Where("membership_status = ?", models.MembershipApproved).
+		Where("id NOT IN (?)", database.DB.Model(&models.RSVP{}).Select("user_id").Where("session_id = ?", session.ID)).
+		Pluck("id", &userIDs).Error; err != nil {
+		log.Printf("Error fetching users for deadline extension: %v", err)
+		return
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+
+	deadlineStr := session.RSVPDeadline.In(utils.SydneyLocation).Format("Monday 3:04 PM")
+	dateStr := utils.FormatDateForDisplay(session.SessionDate)
+	title := "RSVP Deadline Extended"
+	body := fmt.Sprintf("You have more time to RSVP for %s (%s). The new deadline is %s.", session.Title, dateStr, deadlineStr)
+	data := map[string]string{
+		"type":       string(models.NotificationRSVPDeadline),
+		"session_id": session.ID.String(),
+	}
+
+	// Sent in the background, so don't tie it to the request context
+	s.notificationService.SendBulkNotification(context.Background(), userIDs, models.NotificationRSVPDeadline, title, body, data)
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/ogimage"
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+var (
+	// ErrSharingDisabled is returned when no share link secret is configured
+	ErrSharingDisabled = domainError(ErrUnavailable, "sharing_disabled", "session sharing is not configured")
+	// ErrInvalidShareToken is returned for tokens that are malformed, tampered with or expired
+	ErrInvalidShareToken = domainError(ErrNotFound, "invalid_share_token", "share link is invalid or has expired")
+)
+
+// shareLinkGrace keeps a link working for a while after the session starts
+const shareLinkGrace = 24 * time.Hour
+
+// ShareService signs and checks tokens for public session preview links.
+// A token carries the session ID and an expiry, signed with HMAC-SHA256, so
+// links need no storage and can't be altered to point at another session.
+type ShareService struct {
+	secret *signingKey
+}
+
+// NewShareService creates a share service; an empty secret disables sharing
+func NewShareService(secret string) *ShareService {
+	return &ShareService{secret: newSigningKey(secret)}
+}
+
+// IsEnabled reports whether share links can be created
+func (s *ShareService) IsEnabled() bool {
+	return len(s.secret.get()) > 0
+}
+
+// SetSecret switches to a rotated secret. Links signed with the old one
+// stop working.
+func (s *ShareService) SetSecret(secret string) {
+	s.secret.set(secret)
+}
+
+// CreateToken returns a token for session that expires a day after it starts
+func (s *ShareService) CreateToken(session *models.Session) (string, time.Time, error) {
+	if !s.IsEnabled() {
+		return "", time.Time{}, ErrSharingDisabled
+	}
+	if session.Status == models.SessionStatusCancelled {
+		return "", time.Time{}, errors.New("cannot share a cancelled session")
+	}
+
+	expires := session.StartsAt.Add(shareLinkGrace)
+	if !expires.After(time.Now()) {
+		return "", time.Time{}, errors.New("cannot share a session that has already happened")
+	}
+
+	payload := make([]byte, 24)
+	copy(payload, session.ID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expires.Unix()))
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(s.sign(payload)), expires, nil
+}
+
+// ParseToken returns the session a token was issued for
+func (s *ShareService) ParseToken(token string) (uuid.UUID, error) {
+	if !s.IsEnabled() {
+		return uuid.Nil, ErrSharingDisabled
+	}
+
+	encPayload, encSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, ErrInvalidShareToken
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(encPayload)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, ErrInvalidShareToken
+	}
+	sig, err := enc.DecodeString(encSig)
+	if err != nil || !hmac.Equal(sig, s.sign(payload)) {
+		return uuid.Nil, ErrInvalidShareToken
+	}
+
+	expires := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0)
+	if time.Now().After(expires) {
+		return uuid.Nil, ErrInvalidShareToken
+	}
+
+	id, err := uuid.FromBytes(payload[:16])
+	if err != nil {
+		return uuid.Nil, ErrInvalidShareToken
+	}
+	return id, nil
+}
+
+// SessionPreview is what a share link shows to anyone: when and where the
+// session is and how many spots are left, but nothing about who is coming
+type SessionPreview struct {
+	ClubName     string               `json:"club_name"`
+	Title        string               `json:"title"`
+	Description  string               `json:"description"`
+	SessionDate  time.Time            `json:"session_date"`
+	StartTime    string               `json:"start_time"`
+	EndTime      string               `json:"end_time"`
+	Status       models.SessionStatus `json:"status"`
+	VenueName    string               `json:"venue_name"`
+	VenueAddress string               `json:"venue_address"`
+	MaxPlayers   int                  `json:"max_players"`
+	SpotsLeft    int                  `json:"spots_left"`
+}
+
+// Headline sums up whether there's room: "3 spots left", "Full", or why
+// RSVPs aren't being taken
+func (p *SessionPreview) Headline() string {
+	switch {
+	case p.Status == models.SessionStatusCancelled:
+		return "Cancelled"
+	case p.Status == models.SessionStatusClosed:
+		return "RSVPs closed"
+	case p.SpotsLeft == 0:
+		return "Full - join the waitlist"
+	case p.SpotsLeft == 1:
+		return "1 spot left"
+	}
+	return fmt.Sprintf("%d spots left", p.SpotsLeft)
+}
+
+// When is the session's day and time: "Tuesday 3 March, 19:00 - 21:00"
+func (p *SessionPreview) When() string {
+	return fmt.Sprintf("%s, %s - %s", p.SessionDate.Format("Monday 2 January"), p.StartTime, p.EndTime)
+}
+
+// GetPreview returns the public preview for a share token
+func (s *ShareService) GetPreview(token string) (*SessionPreview, error) {
+	sessionID, err := s.ParseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var session models.Session
+	if err := database.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return nil, ErrInvalidShareToken
+	}
+
+	preview := &SessionPreview{
+		Title:       session.Title,
+		Description: session.Description,
+		SessionDate: session.SessionDate,
+		StartTime:   utils.FormatClock(session.StartsAt),
+		EndTime:     utils.FormatClock(session.EndsAt),
+		Status:      session.Status,
+		MaxPlayers:  session.MaxPlayers,
+	}
+
+	var confirmed int64
+	if err := database.DB.Model(&models.RSVP{}).
+		Where("session_id = ? AND status = ?", session.ID, models.RSVPStatusIn).
+		Count(&confirmed).Error; err != nil {
+		return nil, err
+	}
+	preview.SpotsLeft = max(session.RSVPCapacity()-int(confirmed)-session.GuestCount, 0)
+
+	var club models.Club
+	if err := database.DB.First(&club).Error; err == nil {
+		preview.ClubName = club.Name
+		preview.VenueName = club.VenueName
+		preview.VenueAddress = club.VenueAddress
+	}
+	return preview, nil
+}
+
+// PreviewImage draws the image chat apps show with a share link
+func (s *ShareService) PreviewImage(preview *SessionPreview) ([]byte, error) {
+	lines := []string{preview.Title, preview.When()}
+	if preview.VenueName != "" {
+		lines = append(lines, preview.VenueName)
+	}
+	return ogimage.Render(ogimage.Card{
+		Headline: preview.Headline(),
+		Lines:    lines,
+		Footer:   preview.ClubName,
+	})
+}
+
+func (s *ShareService) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret.get())
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,24 @@
+package services
+
+import "sync/atomic"
+
+// signingKey is an HMAC key that can be swapped while in use, so a secret
+// rotated in the secret manager takes effect without a restart
+type signingKey struct {
+	key atomic.Pointer[[]byte]
+}
+
+func newSigningKey(secret string) *signingKey {
+	k := &signingKey{}
+	k.set(secret)
+	return k
+}
+
+func (k *signingKey) get() []byte {
+	return *k.key.Load()
+}
+
+func (k *signingKey) set(secret string) {
+	key := []byte(secret)
+	k.key.Store(&key)
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/weekday-masters/backend/internal/resilience"
+)
+
+// twilioAPIBase is the Twilio REST API root
+const twilioAPIBase = "https://api.twilio.com/2010-04-01"
+
+// smsMaxLength keeps urgent texts to a few segments; Twilio would accept 1600
+const smsMaxLength = 480
+
+// twilioClient sends text messages through Twilio's Messages API
+type twilioClient struct {
+	accountSID string
+	authToken  string
+	from       string
+	client     *http.Client
+}
+
+func newTwilioClient(accountSID, authToken, from string) *twilioClient {
+	return &twilioClient{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		client:     &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// send texts body to an E.164 number
+func (c *twilioClient) send(ctx context.Context, to, body string) error {
+	form := url.Values{"To": {to}, "From": {c.from}, "Body": {body}}
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", twilioAPIBase, url.PathEscape(c.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.accountSID, c.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	var apiErr struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if json.Unmarshal(raw, &apiErr) == nil && apiErr.Message != "" {
+		err = fmt.Errorf("Twilio returned status %d: %s (code %d)", resp.StatusCode, apiErr.Message, apiErr.Code)
+	} else {
+		err = fmt.Errorf("Twilio returned status %d: %s", resp.StatusCode, raw)
+	}
+	// Client errors such as a bad number won't succeed on retry
+	if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return resilience.Permanent(err)
+	}
+	return err
+}
+
+// smsNumber converts a phone number as members enter it to E.164, reading
+// numbers without a country code as Australian
+func smsNumber(phone string) (string, error) {
+	var digits strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		switch {
+		case unicode.IsDigit(r):
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return "", errors.New("phone number has characters other than digits")
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case strings.HasPrefix(number, "+"):
+	case strings.HasPrefix(number, "0") && len(number) == 10:
+		number = "+61" + number[1:]
+	case strings.HasPrefix(number, "61") && len(number) == 11:
+		number = "+" + number
+	default:
+		return "", errors.New("phone number has no country code")
+	}
+	if len(number) < 9 || len(number) > 16 {
+		return "", errors.New("phone number is the wrong length")
+	}
+	return number, nil
+}
+
+// smsText joins a notification's title and body into one text
+func smsText(title, body string) string {
+	text := "Weekday Masters: " + title + ". " + body
+	if runes := []rune(text); len(runes) > smsMaxLength {
+		text = string(runes[:smsMaxLength-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// fastestFingersSize is how many members the weekly board lists
+const fastestFingersSize = 5
+
+// StatsService works out members' streaks and monthly recaps and the weekly
+// fastest-RSVP board. A member has played a session when they were IN for
+// it and it went ahead; archived RSVPs count too.
+type StatsService struct {
+	notificationService *NotificationService
+}
+
+func NewStatsService(notificationService *NotificationService) *StatsService {
+	return &StatsService{notificationService: notificationService}
+}
+
+// Streak is a run of consecutive Monday-to-Sunday weeks with a session played
+type Streak struct {
+	CurrentWeeks int `json:"current_weeks"` // still going if this week has no session yet
+	LongestWeeks int `json:"longest_weeks"`
+}
+
+// MemberRecap is what a member did in a calendar month
+type MemberRecap struct {
+	Month              string             `json:"month"` // YYYY-MM
+	SessionsPlayed     int                `json:"sessions_played"`
+	GamesPlayed        int                `json:"games_played"`
+	GamesWon           int                `json:"games_won"`
+	BadgesEarned       []models.UserBadge `json:"badges_earned"`
+	FastestRSVPSeconds *int               `json:"fastest_rsvp_seconds"` // quickest IN after a session was posted
+	Streak             Streak             `json:"streak"`               // as at the end of the month
+}
+
+// FastestFinger is a member on the weekly board, with their quickest IN
+// after one of the week's sessions was posted
+type FastestFinger struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Name    string    `json:"name"`
+	Seconds int       `json:"seconds"`
+
+	User *models.User `json:"-"` // for privacy filtering when serialized
+}
+
+// playedRSVPs are IN RSVPs, live and archived, for sessions that went ahead
+// before today
+func playedRSVPs() *gorm.DB {
+	allRSVPs := database.DB.Raw("SELECT session_id, user_id, status FROM rsvps UNION ALL SELECT session_id, user_id, status FROM " +
+		models.ArchivedRSVP{}.TableName())
+	return database.DB.Table("(?) AS rsvps", allRSVPs).
+		Joins("JOIN sessions ON sessions.id = rsvps.session_id").
+		Where("rsvps.status = ? AND sessions.status != ? AND sessions.session_date < ?",
+			models.RSVPStatusIn, models.SessionStatusCancelled, utils.StartOfDay(utils.NowInSydney()))
+}
+
+// monthBounds returns the first instant of month's calendar month in Sydney
+// and of the month after
+func monthBounds(month time.Time) (time.Time, time.Time) {
+	month = month.In(utils.SydneyLocation)
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, utils.SydneyLocation)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// GetStreak returns a member's week streaks as at asOf
+func (s *StatsService) GetStreak(userID uuid.UUID, asOf time.Time) (Streak, error) {
+	var dates []time.Time
+	if err := playedRSVPs().
+		Where("rsvps.user_id = ? AND sessions.session_date < ?", userID, utils.StartOfDay(asOf).AddDate(0, 0, 1)).
+		Distinct().Order("sessions.session_date ASC").
+		Pluck("sessions.session_date", &dates).Error; err != nil {
+		return Streak{}, err
+	}
+	return streakFrom(dates, utils.StartOfWeek(asOf)), nil
+}
+
+// streakFrom works out streaks from ascending session dates. The current
+// streak runs back from thisWeek, or from the week before if nothing has been
+// played in thisWeek yet.
+func streakFrom(dates []time.Time, thisWeek time.Time) Streak {
+	var weeks []time.Time
+	for _, d := range dates {
+		week := utils.StartOfWeek(d)
+		if len(weeks) == 0 || !weeks[len(weeks)-1].Equal(week) {
+			weeks = append(weeks, week)
+		}
+	}
+
+	var streak Streak
+	run := 0
+	for i, week := range weeks {
+		if i > 0 && weeks[i-1].AddDate(0, 0, 7).Equal(week) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > streak.LongestWeeks {
+			streak.LongestWeeks = run
+		}
+	}
+	if len(weeks) > 0 {
+		last := weeks[len(weeks)-1]
+		if last.Equal(thisWeek) || last.AddDate(0, 0, 7).Equal(thisWeek) {
+			streak.CurrentWeeks = run
+		}
+	}
+	return streak
+}
+
+// GetRecap returns what a member did in month's calendar month. For the
+// current month it covers the month so far.
+func (s *StatsService) GetRecap(userID uuid.UUID, month time.Time) (*MemberRecap, error) {
+	start, end := monthBounds(month)
+	recap := &MemberRecap{Month: start.Format("2006-01"), BadgesEarned: []models.UserBadge{}}
+
+	var played int64
+	if err := playedRSVPs().
+		Where("rsvps.user_id = ? AND sessions.session_date >= ? AND sessions.session_date < ?", userID, start, end).
+		Count(&played).Error; err != nil {
+		return nil, fmt.Errorf("counting sessions played: %w", err)
+	}
+	recap.SessionsPlayed = int(played)
+
+	type gameResult struct {
+		TeamAScore int
+		TeamBScore int
+		Team       models.GameTeam
+	}
+	var games []gameResult
+	if err := database.DB.Table("games").
+		Select("games.team_a_score, games.team_b_score, game_players.team").
+		Joins("JOIN game_players ON game_players.game_id = games.id").
+		Joins("JOIN sessions ON sessions.id = games.session_id").
+		Where("game_players.user_id = ? AND games.status = ? AND sessions.session_date >= ? AND sessions.session_date < ?",
+			userID, models.GameStatusConfirmed, start, end).
+		Scan(&games).Error; err != nil {
+		return nil, fmt.Errorf("fetching games played: %w", err)
+	}
+	for _, g := range games {
+		recap.GamesPlayed++
+		game := models.Game{TeamAScore: g.TeamAScore, TeamBScore: g.TeamBScore}
+		if game.WinningTeam() == g.Team {
+			recap.GamesWon++
+		}
+	}
+
+	if err := database.DB.Where("user_id = ? AND awarded_at >= ? AND awarded_at < ?", userID, start, end).
+		Order("awarded_at ASC").Find(&recap.BadgesEarned).Error; err != nil {
+		return nil, fmt.Errorf("fetching badges earned: %w", err)
+	}
+
+	var fastest []*float64
+	if err := s.responseTimes().
+		Where("rsvps.user_id = ? AND sessions.session_date >= ? AND sessions.session_date < ?", userID, start, end).
+		Pluck("MIN(EXTRACT(EPOCH FROM rsvps.rsvp_timestamp - sessions.created_at))", &fastest).Error; err != nil {
+		return nil, fmt.Errorf("fetching fastest RSVP: %w", err)
+	}
+	if len(fastest) > 0 && fastest[0] != nil {
+		seconds := int(*fastest[0])
+		recap.FastestRSVPSeconds = &seconds
+	}
+
+	asOf := end.AddDate(0, 0, -1)
+	if now := utils.NowInSydney(); now.Before(asOf) {
+		asOf = now
+	}
+	streak, err := s.GetStreak(userID, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("working out streak: %w", err)
+	}
+	recap.Streak = streak
+
+	return recap, nil
+}
+
+// responseTimes are members' own IN RSVPs joined to their sessions, for
+// timing how soon after a session was posted they answered
+func (s *StatsService) responseTimes() *gorm.DB {
+	return database.DB.Table("rsvps").
+		Joins("JOIN sessions ON sessions.id = rsvps.session_id").
+		Where("rsvps.status = ? AND rsvps.added_by_admin = ? AND sessions.status != ?",
+			models.RSVPStatusIn, false, models.SessionStatusCancelled)
+}
+
+// GetFastestFingers returns the members who RSVP'd IN quickest after the
+// sessions of week's Monday-to-Sunday week were posted, quickest first
+func (s *StatsService) GetFastestFingers(week time.Time) ([]FastestFinger, error) {
+	start := utils.StartOfWeek(week)
+
+	type quickest struct {
+		UserID  uuid.UUID
+		Seconds float64
+	}
+	var rows []quickest
+	if err := s.responseTimes().
+		Select("rsvps.user_id, MIN(EXTRACT(EPOCH FROM rsvps.rsvp_timestamp - sessions.created_at)) AS seconds").
+		Where("sessions.session_date >= ? AND sessions.session_date < ?", start, start.AddDate(0, 0, 7)).
+		Group("rsvps.user_id").
+		Order("seconds ASC").
+		Limit(fastestFingersSize).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, r := range rows {
+		ids[i] = r.UserID
+	}
+	var users []models.User
+	if len(ids) > 0 {
+		if err := database.DB.Where("id IN ?", ids).Find(&users).Error; err != nil {
+			return nil, err
+		}
+	}
+	byID := make(map[uuid.UUID]*models.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+
+	board := make([]FastestFinger, 0, len(rows))
+	for _, r := range rows {
+		entry := FastestFinger{UserID: r.UserID, Name: "A former member", Seconds: int(r.Seconds), User: byID[r.UserID]}
+		if entry.User != nil {
+			entry.Name = entry.User.Name
+		}
+		board = append(board, entry)
+	}
+	return board, nil
+}
+
+// SendMonthlyRecaps sends each member who played last month their recap
+func (s *StatsService) SendMonthlyRecaps(ctx context.Context, report *JobReport) error {
+	start, end := monthBounds(utils.NowInSydney().AddDate(0, -1, 0))
+
+	var ids []uuid.UUID
+	if err := playedRSVPs().
+		Joins("JOIN users ON users.id = rsvps.user_id").
+		Where("users.membership_status = ? AND sessions.session_date >= ? AND sessions.session_date < ?",
+			models.MembershipApproved, start, end).
+		Distinct().Order("rsvps.user_id").Pluck("rsvps.user_id", &ids).Error; err != nil {
+		return fmt.Errorf("fetching members who played in %s: %w", start.Format("2006-01"), err)
+	}
+
+	message := loadMessage(models.MessageMonthlyRecap)
+	var messages []NotificationMessage
+	for _, id := range ids {
+		recap, err := s.GetRecap(id, start)
+		if err != nil {
+			return fmt.Errorf("building recap for member %s: %w", id, err)
+		}
+		recapContext := MonthlyRecapContext{
+			MonthStart:     start,
+			SessionsPlayed: recap.SessionsPlayed,
+			GamesPlayed:    recap.GamesPlayed,
+			GamesWon:       recap.GamesWon,
+			CurrentStreak:  recap.Streak.CurrentWeeks,
+			LongestStreak:  recap.Streak.LongestWeeks,
+		}
+		for _, badge := range recap.BadgesEarned {
+			recapContext.Badges = append(recapContext.Badges, badge.BadgeType.DisplayName())
+		}
+		rendered, err := message.messagesFor([]uuid.UUID{id}, sameData(recapContext), map[string]string{
+			"type":  string(models.NotificationMonthlyRecap),
+			"month": recap.Month,
+		})
+		if err != nil {
+			return fmt.Errorf("rendering recap for member %s: %w", id, err)
+		}
+		messages = append(messages, rendered...)
+	}
+
+	for i := range messages {
+		report.add(JobAction{Kind: "notification", UserID: &messages[i].UserID, Title: messages[i].Title,
+			Detail: string(models.NotificationMonthlyRecap) + ": " + messages[i].Body})
+	}
+	if report.isDryRun() || len(messages) == 0 {
+		return nil
+	}
+
+	sent, err := s.notificationService.SendBatch(ctx, models.NotificationMonthlyRecap, messages)
+	if err != nil {
+		return fmt.Errorf("sending monthly recaps: %w", err)
+	}
+	log.Printf("Sent %s recaps to %d members", start.Format("2006-01"), sent)
+	return nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"time"
+
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+type SyncService struct{}
+
+func NewSyncService() *SyncService {
+	return &SyncService{}
+}
+
+// SyncDelta is everything that changed since a client last synced
+type SyncDelta struct {
+	Since         time.Time             `json:"since"`
+	ServerTime    time.Time             `json:"server_time"` // pass back as since on the next sync
+	Sessions      []models.Session      `json:"sessions"`
+	RSVPs         []models.RSVP         `json:"rsvps"`
+	Announcements []models.Announcement `json:"announcements"`
+	Deleted       []models.Tombstone    `json:"deleted"`
+}
+
+// GetChangesSince returns sessions, RSVPs and the announcements viewer may
+// see created or updated after since, plus tombstones for anything deleted.
+// A zero since returns everything.
+func (s *SyncService) GetChangesSince(since time.Time, viewer *models.User) (*SyncDelta, error) {
+	// Take the server time first so changes made during the queries are picked up next time
+	delta := &SyncDelta{
+		Since:         since,
+		ServerTime:    time.Now(),
+		Sessions:      []models.Session{},
+		RSVPs:         []models.RSVP{},
+		Announcements: []models.Announcement{},
+		Deleted:       []models.Tombstone{},
+	}
+
+	if err := database.DB.Where("updated_at > ?", since).
+		Order("updated_at ASC").
+		Find(&delta.Sessions).Error; err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Where("updated_at > ?", since).
+		Preload("User").
+		Order("updated_at ASC").
+		Find(&delta.RSVPs).Error; err != nil {
+		return nil, err
+	}
+
+	if err := visibleAnnouncements(database.DB, viewer).Where("created_at > ?", since).
+		Preload("Creator").
+		Order("created_at ASC").
+		Find(&delta.Announcements).Error; err != nil {
+		return nil, err
+	}
+
+	if !since.IsZero() {
+		if err := database.DB.Where("deleted_at > ?", since).
+			Order("deleted_at ASC").
+			Find(&delta.Deleted).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return delta, nil
+}
@@ -0,0 +1,582 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTournamentNotFound = domainError(ErrNotFound, "tournament_not_found", "tournament not found")
+	ErrRegistrationClosed = domainError(ErrConflict, "registration_closed", "tournament registration is closed")
+	ErrAlreadyRegistered  = domainError(ErrConflict, "already_registered", "already registered for this tournament")
+	ErrTournamentFull     = domainError(ErrConflict, "tournament_full", "tournament is full")
+	ErrNotRegistered      = domainError(ErrNotFound, "not_registered", "not registered for this tournament")
+	ErrFixturesGenerated  = domainError(ErrConflict, "fixtures_generated", "fixtures have already been generated")
+	ErrWithdrawClosed     = domainError(ErrConflict, "withdrawal_closed", "cannot withdraw after fixtures are generated")
+)
+
+type TournamentService struct {
+	badgeService *BadgeService
+}
+
+func NewTournamentService(badgeService *BadgeService) *TournamentService {
+	return &TournamentService{badgeService: badgeService}
+}
+
+type CreateTournamentInput struct {
+	Name        string
+	Description string
+	Format      models.TournamentFormat
+	MaxEntries  int // 0 for no limit
+	CreatedBy   uuid.UUID
+}
+
+// CreateTournament creates a new tournament open for registration
+func (s *TournamentService) CreateTournament(input CreateTournamentInput) (*models.Tournament, error) {
+	if input.Format != models.TournamentRoundRobin && input.Format != models.TournamentKnockout {
+		return nil, domainError(ErrInvalid, "invalid_format", "format must be round_robin or knockout")
+	}
+	if input.MaxEntries < 0 {
+		return nil, domainError(ErrInvalid, "invalid_max_entries", "max entries cannot be negative")
+	}
+
+	tournament := models.Tournament{
+		Name:        input.Name,
+		Description: input.Description,
+		Format:      input.Format,
+		MaxEntries:  input.MaxEntries,
+		Status:      models.TournamentStatusRegistration,
+		CreatedBy:   input.CreatedBy,
+	}
+
+	if err := database.DB.Create(&tournament).Error; err != nil {
+		return nil, err
+	}
+
+	return &tournament, nil
+}
+
+// ListTournaments returns all tournaments, most recent first
+func (s *TournamentService) ListTournaments() ([]models.Tournament, error) {
+	var tournaments []models.Tournament
+	if err := database.DB.Preload("Winner").
+		Order("created_at DESC").
+		Find(&tournaments).Error; err != nil {
+		return nil, err
+	}
+	return tournaments, nil
+}
+
+// GetTournamentByID retrieves a tournament with its entries and fixtures
+func (s *TournamentService) GetTournamentByID(id uuid.UUID) (*models.Tournament, error) {
+	var tournament models.Tournament
+	if err := database.DB.Preload("Entries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("seed ASC, created_at ASC")
+	}).Preload("Entries.User").
+		Preload("Matches", func(db *gorm.DB) *gorm.DB {
+			return db.Order("round ASC, match_number ASC")
+		}).Preload("Matches.Player1").Preload("Matches.Player2").
+		Preload("Winner").
+		First(&tournament, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &tournament, nil
+}
+
+// lockTournament reads a tournament in tx, locking its row until tx ends so
+// registrations, withdrawals and fixture generation happen one at a time
+func lockTournament(tx *gorm.DB, id uuid.UUID) (models.Tournament, error) {
+	var tournament models.Tournament
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tournament, "id = ?", id).Error
+	return tournament, err
+}
+
+// Register enters a player into a tournament, while registration is open
+// and, for a tournament with a limit, there's room
+func (s *TournamentService) Register(tournamentID, userID uuid.UUID) (*models.TournamentEntry, error) {
+	entry := models.TournamentEntry{
+		TournamentID: tournamentID,
+		UserID:       userID,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		tournament, err := lockTournament(tx, tournamentID)
+		if err != nil {
+			return ErrTournamentNotFound
+		}
+		if tournament.Status != models.TournamentStatusRegistration {
+			return ErrRegistrationClosed
+		}
+
+		var registered int64
+		if err := tx.Model(&models.TournamentEntry{}).
+			Where("tournament_id = ? AND user_id = ?", tournamentID, userID).
+			Count(&registered).Error; err != nil {
+			return err
+		}
+		if registered > 0 {
+			return ErrAlreadyRegistered
+		}
+
+		if tournament.MaxEntries > 0 {
+			var entries int64
+			if err := tx.Model(&models.TournamentEntry{}).
+				Where("tournament_id = ?", tournamentID).
+				Count(&entries).Error; err != nil {
+				return err
+			}
+			if entries >= int64(tournament.MaxEntries) {
+				return ErrTournamentFull
+			}
+		}
+
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Withdraw removes a player's registration before fixtures are generated
+func (s *TournamentService) Withdraw(tournamentID, userID uuid.UUID) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		tournament, err := lockTournament(tx, tournamentID)
+		if err != nil {
+			return ErrTournamentNotFound
+		}
+		if tournament.Status != models.TournamentStatusRegistration {
+			return ErrWithdrawClosed
+		}
+
+		result := tx.Where("tournament_id = ? AND user_id = ?", tournamentID, userID).
+			Delete(&models.TournamentEntry{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotRegistered
+		}
+		return nil
+	})
+}
+
+// GenerateFixtures closes registration and creates the match schedule.
+// Rounds are spread across the given sessions in order, wrapping around
+// if there are more rounds than sessions.
+func (s *TournamentService) GenerateFixtures(tournamentID uuid.UUID, sessionIDs []uuid.UUID) (*models.Tournament, error) {
+	if len(sessionIDs) > 0 {
+		var count int64
+		database.DB.Model(&models.Session{}).Where("id IN ?", sessionIDs).Count(&count)
+		if int(count) != len(sessionIDs) {
+			return nil, domainError(ErrNotFound, "session_not_found", "one or more sessions not found")
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Registrations wait on the lock, so the entries read here are final
+		tournament, err := lockTournament(tx, tournamentID)
+		if err != nil {
+			return ErrTournamentNotFound
+		}
+		if tournament.Status != models.TournamentStatusRegistration {
+			return ErrFixturesGenerated
+		}
+
+		var entries []models.TournamentEntry
+		if err := tx.Where("tournament_id = ?", tournamentID).
+			Order("created_at ASC").
+			Find(&entries).Error; err != nil {
+			return err
+		}
+		if len(entries) < 2 {
+			return domainError(ErrConflict, "not_enough_entries", "at least 2 players must be registered")
+		}
+		if err := seedEntries(tx, entries); err != nil {
+			return err
+		}
+
+		players := make([]*uuid.UUID, len(entries))
+		for i := range entries {
+			players[i] = &entries[i].UserID
+		}
+
+		var matches []models.TournamentMatch
+		switch tournament.Format {
+		case models.TournamentRoundRobin:
+			matches = roundRobinFixtures(tournamentID, players)
+		case models.TournamentKnockout:
+			matches = knockoutFixtures(tournamentID, players)
+		}
+
+		for i := range matches {
+			if len(sessionIDs) > 0 {
+				sessionID := sessionIDs[(matches[i].Round-1)%len(sessionIDs)]
+				matches[i].SessionID = &sessionID
+			}
+		}
+
+		if err := tx.Create(&matches).Error; err != nil {
+			return err
+		}
+		if tournament.Format == models.TournamentKnockout {
+			// Byes in the first round advance immediately
+			for i := range matches {
+				if matches[i].Status == models.MatchStatusCompleted {
+					if err := advanceKnockoutWinner(tx, &matches[i]); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		tournament.Status = models.TournamentStatusInProgress
+		tournament.UpdatedAt = time.Now()
+		return tx.Save(&tournament).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetTournamentByID(tournamentID)
+}
+
+// roundRobinFixtures pairs every player against every other using the circle method
+func roundRobinFixtures(tournamentID uuid.UUID, players []*uuid.UUID) []models.TournamentMatch {
+	slots := append([]*uuid.UUID{}, players...)
+	if len(slots)%2 == 1 {
+		slots = append(slots, nil) // bye
+	}
+	n := len(slots)
+
+	var matches []models.TournamentMatch
+	for round := 1; round < n; round++ {
+		matchNumber := 1
+		for i := 0; i < n/2; i++ {
+			p1, p2 := slots[i], slots[n-1-i]
+			if p1 == nil || p2 == nil {
+				continue
+			}
+			matches = append(matches, models.TournamentMatch{
+				TournamentID: tournamentID,
+				Round:        round,
+				MatchNumber:  matchNumber,
+				Player1ID:    p1,
+				Player2ID:    p2,
+				Status:       models.MatchStatusScheduled,
+			})
+			matchNumber++
+		}
+		// Rotate all but the first slot
+		last := slots[n-1]
+		copy(slots[2:], slots[1:n-1])
+		slots[1] = last
+	}
+	return matches
+}
+
+// seedEntries seeds a tournament's entries by Elo rating, highest first.
+// Players without a rating count as the default rating, and ties keep
+// registration order. entries are sorted into seed order.
+func seedEntries(tx *gorm.DB, entries []models.TournamentEntry) error {
+	userIDs := make([]uuid.UUID, len(entries))
+	for i, e := range entries {
+		userIDs[i] = e.UserID
+	}
+	var ratings []models.PlayerRating
+	if err := tx.Where("user_id IN ?", userIDs).Find(&ratings).Error; err != nil {
+		return err
+	}
+	byUser := make(map[uuid.UUID]float64, len(ratings))
+	for _, r := range ratings {
+		byUser[r.UserID] = r.Rating
+	}
+	rating := func(userID uuid.UUID) float64 {
+		if r, ok := byUser[userID]; ok {
+			return r
+		}
+		return models.DefaultEloRating
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return rating(entries[i].UserID) > rating(entries[j].UserID)
+	})
+	for i := range entries {
+		entries[i].Seed = i + 1
+		if err := tx.Model(&entries[i]).Update("seed", entries[i].Seed).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bracketOrder returns the seeds, from 1, in the order they fill a bracket
+// of size slots: first-round pairs are adjacent, each seed s meets seed
+// size+1-s, and seeds 1 and 2 can only meet in the final
+func bracketOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		n := len(order) * 2
+		next := make([]int, 0, n)
+		for _, seed := range order {
+			next = append(next, seed, n+1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// knockoutFixtures builds a single-elimination bracket from players in seed
+// order, padding with byes to the next power of two. The byes go to the top
+// seeds. Later-round slots are filled as results come in.
+func knockoutFixtures(tournamentID uuid.UUID, players []*uuid.UUID) []models.TournamentMatch {
+	size := 1
+	for size < len(players) {
+		size *= 2
+	}
+	slots := make([]*uuid.UUID, size)
+	for i, seed := range bracketOrder(size) {
+		if seed <= len(players) {
+			slots[i] = players[seed-1]
+		}
+	}
+
+	var matches []models.TournamentMatch
+	for i := 0; i < size/2; i++ {
+		match := models.TournamentMatch{
+			TournamentID: tournamentID,
+			Round:        1,
+			MatchNumber:  i + 1,
+			Player1ID:    slots[2*i],
+			Player2ID:    slots[2*i+1],
+			Status:       models.MatchStatusScheduled,
+		}
+		if match.Player2ID == nil {
+			match.WinnerID = match.Player1ID
+			match.Status = models.MatchStatusCompleted
+		}
+		matches = append(matches, match)
+	}
+
+	round := 2
+	for count := size / 4; count >= 1; count /= 2 {
+		for i := 0; i < count; i++ {
+			matches = append(matches, models.TournamentMatch{
+				TournamentID: tournamentID,
+				Round:        round,
+				MatchNumber:  i + 1,
+				Status:       models.MatchStatusScheduled,
+			})
+		}
+		round++
+	}
+	return matches
+}
+
+// advanceKnockoutWinner places a completed match's winner into the next round
+func advanceKnockoutWinner(tx *gorm.DB, match *models.TournamentMatch) error {
+	var next models.TournamentMatch
+	err := tx.Where("tournament_id = ? AND round = ? AND match_number = ?",
+		match.TournamentID, match.Round+1, (match.MatchNumber+1)/2).
+		First(&next).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil // final
+	}
+	if err != nil {
+		return err
+	}
+
+	if match.MatchNumber%2 == 1 {
+		next.Player1ID = match.WinnerID
+	} else {
+		next.Player2ID = match.WinnerID
+	}
+	next.UpdatedAt = time.Now()
+	return tx.Save(&next).Error
+}
+
+// RecordResult records a match score and advances the tournament. The
+// tournament is locked while it does, so the last round-robin result sees
+// every other one and a knockout match can't be decided twice.
+func (s *TournamentService) RecordResult(ctx context.Context, tournamentID, matchID uuid.UUID, player1Score, player2Score int) (*models.TournamentMatch, error) {
+	if player1Score < 0 || player2Score < 0 {
+		return nil, domainError(ErrInvalid, "negative_score", "scores cannot be negative")
+	}
+	if player1Score == player2Score {
+		return nil, domainError(ErrInvalid, "drawn_match", "matches cannot end in a draw")
+	}
+
+	var match models.TournamentMatch
+	var winnerID *uuid.UUID
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		tournament, err := lockTournament(tx, tournamentID)
+		if err != nil {
+			return ErrTournamentNotFound
+		}
+		if tournament.Status != models.TournamentStatusInProgress {
+			return domainError(ErrConflict, "tournament_not_in_progress", "tournament is not in progress")
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&match, "id = ? AND tournament_id = ?", matchID, tournamentID).Error; err != nil {
+			return domainError(ErrNotFound, "match_not_found", "match not found")
+		}
+		if match.Player1ID == nil || match.Player2ID == nil {
+			return domainError(ErrConflict, "match_players_undecided", "match players are not yet decided")
+		}
+		if tournament.Format == models.TournamentKnockout && match.Status == models.MatchStatusCompleted {
+			return domainError(ErrConflict, "knockout_result_recorded", "knockout results cannot be changed once recorded")
+		}
+
+		match.Player1Score = &player1Score
+		match.Player2Score = &player2Score
+		if player1Score > player2Score {
+			match.WinnerID = match.Player1ID
+		} else {
+			match.WinnerID = match.Player2ID
+		}
+		match.Status = models.MatchStatusCompleted
+		match.UpdatedAt = time.Now()
+		if err := tx.Save(&match).Error; err != nil {
+			return err
+		}
+
+		switch tournament.Format {
+		case models.TournamentKnockout:
+			if err := advanceKnockoutWinner(tx, &match); err != nil {
+				return err
+			}
+			var maxRound int
+			if err := tx.Model(&models.TournamentMatch{}).
+				Where("tournament_id = ?", tournamentID).
+				Select("COALESCE(MAX(round), 0)").Scan(&maxRound).Error; err != nil {
+				return err
+			}
+			if match.Round == maxRound {
+				winnerID = match.WinnerID
+			}
+		case models.TournamentRoundRobin:
+			var remaining int64
+			if err := tx.Model(&models.TournamentMatch{}).
+				Where("tournament_id = ? AND status != ?", tournamentID, models.MatchStatusCompleted).
+				Count(&remaining).Error; err != nil {
+				return err
+			}
+			if remaining == 0 {
+				standings, err := computeStandings(tx, tournamentID)
+				if err != nil {
+					return err
+				}
+				if len(standings) > 0 {
+					winnerID = &standings[0].UserID
+				}
+			}
+		}
+
+		if winnerID != nil {
+			tournament.WinnerID = winnerID
+			tournament.Status = models.TournamentStatusCompleted
+			tournament.UpdatedAt = time.Now()
+			return tx.Save(&tournament).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if winnerID != nil && s.badgeService != nil {
+		s.badgeService.AwardBadge(ctx, *winnerID, models.BadgeFirstTournamentWin)
+	}
+
+	return &match, nil
+}
+
+// Standing is a player's aggregated record in a tournament
+type Standing struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Name          string    `json:"name"`
+	Played        int       `json:"played"`
+	Wins          int       `json:"wins"`
+	Losses        int       `json:"losses"`
+	PointsFor     int       `json:"points_for"`
+	PointsAgainst int       `json:"points_against"`
+
+	User *models.User `json:"-"` // for privacy filtering when serialized
+}
+
+// GetStandings returns the tournament table ordered by wins then points difference
+func (s *TournamentService) GetStandings(tournamentID uuid.UUID) ([]Standing, error) {
+	var tournament models.Tournament
+	if err := database.DB.First(&tournament, "id = ?", tournamentID).Error; err != nil {
+		return nil, ErrTournamentNotFound
+	}
+	return computeStandings(database.DB, tournamentID)
+}
+
+func computeStandings(db *gorm.DB, tournamentID uuid.UUID) ([]Standing, error) {
+	var entries []models.TournamentEntry
+	if err := db.Where("tournament_id = ?", tournamentID).Preload("User").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	var matches []models.TournamentMatch
+	if err := db.Where("tournament_id = ? AND status = ? AND player1_score IS NOT NULL",
+		tournamentID, models.MatchStatusCompleted).
+		Find(&matches).Error; err != nil {
+		return nil, err
+	}
+
+	table := make(map[uuid.UUID]*Standing, len(entries))
+	standings := make([]Standing, len(entries))
+	for i, e := range entries {
+		standings[i] = Standing{UserID: e.UserID}
+		if e.User != nil {
+			standings[i].Name = e.User.Name
+			standings[i].User = e.User
+		}
+		table[e.UserID] = &standings[i]
+	}
+
+	for _, m := range matches {
+		p1, ok1 := table[*m.Player1ID]
+		p2, ok2 := table[*m.Player2ID]
+		if !ok1 || !ok2 {
+			continue
+		}
+		p1.Played++
+		p2.Played++
+		p1.PointsFor += *m.Player1Score
+		p1.PointsAgainst += *m.Player2Score
+		p2.PointsFor += *m.Player2Score
+		p2.PointsAgainst += *m.Player1Score
+		if *m.WinnerID == *m.Player1ID {
+			p1.Wins++
+			p2.Losses++
+		} else {
+			p2.Wins++
+			p1.Losses++
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Wins != standings[j].Wins {
+			return standings[i].Wins > standings[j].Wins
+		}
+		di := standings[i].PointsFor - standings[i].PointsAgainst
+		dj := standings[j].PointsFor - standings[j].PointsAgainst
+		if di != dj {
+			return di > dj
+		}
+		return standings[i].Name < standings[j].Name
+	})
+
+	return standings, nil
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+func TestBracketOrder(t *testing.T) {
+	tests := []struct {
+		size int
+		want []int
+	}{
+		{2, []int{1, 2}},
+		{4, []int{1, 4, 2, 3}},
+		{8, []int{1, 8, 4, 5, 2, 7, 3, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.size), func(t *testing.T) {
+			if got := bracketOrder(tt.size); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bracketOrder(%d) = %v, want %v", tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestKnockoutFixturesSeeding checks byes go to the top seeds and the top
+// two seeds are in different halves of the bracket
+func TestKnockoutFixturesSeeding(t *testing.T) {
+	seeds := make([]*uuid.UUID, 5)
+	seedOf := map[uuid.UUID]int{}
+	for i := range seeds {
+		id := uuid.New()
+		seeds[i] = &id
+		seedOf[id] = i + 1
+	}
+
+	matches := knockoutFixtures(uuid.New(), seeds)
+	var first []models.TournamentMatch
+	for _, m := range matches {
+		if m.Round == 1 {
+			first = append(first, m)
+		}
+	}
+	if len(first) != 4 || len(matches) != 7 {
+		t.Fatalf("got %d matches, %d in the first round; want 7 and 4", len(matches), len(first))
+	}
+
+	pairs := make([][2]int, len(first))
+	for i, m := range first {
+		pairs[i][0] = seedOf[*m.Player1ID]
+		if m.Player2ID != nil {
+			pairs[i][1] = seedOf[*m.Player2ID]
+		}
+		if (m.Player2ID == nil) != (m.Status == models.MatchStatusCompleted) {
+			t.Errorf("match %d: bye %v but status %s", m.MatchNumber, m.Player2ID == nil, m.Status)
+		}
+	}
+	// Seeds 1, 2 and 3 have byes; 4 plays 5
+	want := [][2]int{{1, 0}, {4, 5}, {2, 0}, {3, 0}}
+	if !reflect.DeepEqual(pairs, want) {
+		t.Errorf("first round seeds = %v, want %v", pairs, want)
+	}
+}
+
+func newTournament(t *testing.T, format models.TournamentFormat, maxEntries int, players ...*models.User) *models.Tournament {
+	t.Helper()
+	service := NewTournamentService(nil)
+	tournament, err := service.CreateTournament(CreateTournamentInput{
+		Name: "Club champs", Format: format, MaxEntries: maxEntries, CreatedBy: players[0].ID,
+	})
+	if err != nil {
+		t.Fatalf("creating tournament: %v", err)
+	}
+	for _, p := range players {
+		if _, err := service.Register(tournament.ID, p.ID); err != nil {
+			t.Fatalf("registering %s: %v", p.Name, err)
+		}
+	}
+	return tournament
+}
+
+func TestRegisterRespectsMaxEntries(t *testing.T) {
+	testDB(t)
+	service := NewTournamentService(nil)
+	owner := newMember(t, "Owner")
+	tournament, err := service.CreateTournament(CreateTournamentInput{
+		Name: "Doubles", Format: models.TournamentRoundRobin, MaxEntries: 2, CreatedBy: owner.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	players := make([]*models.User, 6)
+	for i := range players {
+		players[i] = newMember(t, "Player")
+	}
+	errs := concurrently(len(players), func(i int) error {
+		_, err := service.Register(tournament.ID, players[i].ID)
+		return err
+	})
+
+	registered := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			registered++
+		case !errors.Is(err, ErrTournamentFull):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if registered != 2 {
+		t.Errorf("%d registered, want 2", registered)
+	}
+}
+
+func TestGenerateFixturesSeedsByRating(t *testing.T) {
+	testDB(t)
+	unrated, mid, low, top := newMember(t, "Unrated"), newMember(t, "Mid"), newMember(t, "Low"), newMember(t, "Top")
+	for user, rating := range map[*models.User]float64{top: 1200, mid: 1100, low: 900} {
+		if err := database.DB.Create(&models.PlayerRating{UserID: user.ID, Rating: rating}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Registered in a different order from their ratings
+	tournament := newTournament(t, models.TournamentKnockout, 0, unrated, mid, low, top)
+
+	got, err := NewTournamentService(nil).GenerateFixtures(tournament.ID, nil)
+	if err != nil {
+		t.Fatalf("GenerateFixtures: %v", err)
+	}
+
+	wantSeeds := map[uuid.UUID]int{top.ID: 1, mid.ID: 2, unrated.ID: 3, low.ID: 4}
+	for _, e := range got.Entries {
+		if e.Seed != wantSeeds[e.UserID] {
+			t.Errorf("%s seeded %d, want %d", e.User.Name, e.Seed, wantSeeds[e.UserID])
+		}
+	}
+	// 1 plays 4 and 2 plays 3, so 1 and 2 can only meet in the final
+	wantFirst := [][2]uuid.UUID{{top.ID, low.ID}, {mid.ID, unrated.ID}}
+	for _, m := range got.Matches {
+		if m.Round != 1 {
+			continue
+		}
+		want := wantFirst[m.MatchNumber-1]
+		if *m.Player1ID != want[0] || *m.Player2ID != want[1] {
+			t.Errorf("match %d is %s v %s, want %s v %s", m.MatchNumber, m.Player1ID, m.Player2ID, want[0], want[1])
+		}
+	}
+}
+
+func TestWithdrawAfterFixtures(t *testing.T) {
+	testDB(t)
+	a, b := newMember(t, "A"), newMember(t, "B")
+	tournament := newTournament(t, models.TournamentRoundRobin, 0, a, b)
+	service := NewTournamentService(nil)
+	if _, err := service.GenerateFixtures(tournament.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	err := service.Withdraw(tournament.ID, a.ID)
+	var domain *DomainError
+	if !errors.As(err, &domain) || domain.Code != "withdrawal_closed" {
+		t.Errorf("Withdraw = %v, want withdrawal_closed", err)
+	}
+}
+
+// TestRecordResultCompletesRoundRobin records the last two results of a
+// round robin at once; whichever lands last must complete the tournament
+func TestRecordResultCompletesRoundRobin(t *testing.T) {
+	testDB(t)
+	players := []*models.User{newMember(t, "A"), newMember(t, "B"), newMember(t, "C")}
+	tournament := newTournament(t, models.TournamentRoundRobin, 0, players...)
+	service := NewTournamentService(nil)
+	generated, err := service.GenerateFixtures(tournament.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches := generated.Matches
+	if len(matches) != 3 {
+		t.Fatalf("%d matches, want 3", len(matches))
+	}
+
+	ctx := context.Background()
+	if _, err := service.RecordResult(ctx, tournament.ID, matches[0].ID, 21, 15); err != nil {
+		t.Fatal(err)
+	}
+	errs := concurrently(2, func(i int) error {
+		_, err := service.RecordResult(ctx, tournament.ID, matches[i+1].ID, 21, 10+i)
+		return err
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("RecordResult: %v", err)
+		}
+	}
+
+	var got models.Tournament
+	if err := database.DB.First(&got, "id = ?", tournament.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.TournamentStatusCompleted || got.WinnerID == nil {
+		t.Errorf("tournament is %s with winner %v, want completed with a winner", got.Status, got.WinnerID)
+	}
+}
+
+// TestRecordResultKnockoutOnce records a semi-final several times at once;
+// only one result may stand and advance its winner
+func TestRecordResultKnockoutOnce(t *testing.T) {
+	testDB(t)
+	players := []*models.User{newMember(t, "A"), newMember(t, "B"), newMember(t, "C"), newMember(t, "D")}
+	tournament := newTournament(t, models.TournamentKnockout, 0, players...)
+	service := NewTournamentService(nil)
+	generated, err := service.GenerateFixtures(tournament.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	semi := generated.Matches[0]
+
+	// Half the attempts have player 1 win, half player 2
+	errs := concurrently(6, func(i int) error {
+		_, err := service.RecordResult(context.Background(), tournament.ID, semi.ID, 21-i%2*10, 11+i%2*10)
+		return err
+	})
+	recorded := 0
+	for _, err := range errs {
+		var domain *DomainError
+		switch {
+		case err == nil:
+			recorded++
+		case !errors.As(err, &domain) || domain.Code != "knockout_result_recorded":
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if recorded != 1 {
+		t.Fatalf("%d results recorded, want 1", recorded)
+	}
+
+	var result, final models.TournamentMatch
+	database.DB.First(&result, "id = ?", semi.ID)
+	database.DB.First(&final, "tournament_id = ? AND round = 2", tournament.ID)
+	if final.Player1ID == nil || result.WinnerID == nil || *final.Player1ID != *result.WinnerID {
+		t.Errorf("final's first player is %v, want the semi-final winner %v", final.Player1ID, result.WinnerID)
+	}
+}
+
+// TestTournamentValidationErrors checks bad input is reported as invalid,
+// before anything is read
+func TestTournamentValidationErrors(t *testing.T) {
+	service := NewTournamentService(nil)
+	_, badFormat := service.CreateTournament(CreateTournamentInput{Name: "Cup", Format: "swiss"})
+	_, badEntries := service.CreateTournament(CreateTournamentInput{Name: "Cup", Format: models.TournamentKnockout, MaxEntries: -1})
+	_, negative := service.RecordResult(context.Background(), uuid.New(), uuid.New(), -1, 21)
+	_, draw := service.RecordResult(context.Background(), uuid.New(), uuid.New(), 21, 21)
+
+	for _, err := range []error{badFormat, badEntries, negative, draw} {
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("got %v, want an ErrInvalid domain error", err)
+		}
+	}
+}
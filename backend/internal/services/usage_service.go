@@ -0,0 +1,235 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// activeUsageDays is how recently a member must have used the app to count
+// as an active user of it
+const activeUsageDays = 14
+
+// Usage levels of a member in the usage report
+const (
+	UsageActive = "active" // used the app in the last activeUsageDays
+	UsageLapsed = "lapsed" // used it before, but not lately
+	UsageNever  = "never"  // not since usage was first tracked
+)
+
+type usageKey struct {
+	userID uuid.UUID
+	day    string // YYYY-MM-DD in Sydney
+}
+
+type usageCount struct {
+	requests int
+	lastSeen time.Time
+}
+
+// UsageService counts members' API requests in memory and adds them to the
+// daily usage table every flush, so a request costs no database write. Each
+// instance adds its own counts, so running several is fine.
+type UsageService struct {
+	mu      sync.Mutex
+	pending map[usageKey]*usageCount
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func NewUsageService() *UsageService {
+	return &UsageService{pending: make(map[usageKey]*usageCount)}
+}
+
+// Record counts a request by a member
+func (s *UsageService) Record(userID uuid.UUID) {
+	now := time.Now()
+	key := usageKey{userID: userID, day: utils.NowInSydney().Format("2006-01-02")}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count, ok := s.pending[key]
+	if !ok {
+		count = &usageCount{}
+		s.pending[key] = count
+	}
+	count.requests++
+	count.lastSeen = now
+}
+
+// Start flushes counts every interval until Stop is called
+func (s *UsageService) Start(interval time.Duration) {
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Flush(); err != nil {
+					log.Printf("Error flushing API usage: %v", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends background flushing and flushes what's left
+func (s *UsageService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+	if err := s.Flush(); err != nil {
+		log.Printf("Error flushing API usage: %v", err)
+	}
+}
+
+// Flush adds the counts since the last flush to the usage table. Counts
+// that fail to save are kept for the next flush.
+func (s *UsageService) Flush() error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[usageKey]*usageCount)
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	rows := make([]models.APIUsage, 0, len(pending))
+	for key, count := range pending {
+		day, err := utils.ParseDateInSydney(key.day)
+		if err != nil {
+			s.requeue(pending)
+			return err
+		}
+		rows = append(rows, models.APIUsage{UserID: key.userID, Day: day, Requests: count.requests, LastSeenAt: count.lastSeen})
+	}
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"requests":     gorm.Expr("api_usages.requests + EXCLUDED.requests"),
+			"last_seen_at": gorm.Expr("GREATEST(api_usages.last_seen_at, EXCLUDED.last_seen_at)"),
+		}),
+	}).Create(&rows).Error
+	if err != nil {
+		s.requeue(pending)
+		return fmt.Errorf("saving API usage: %w", err)
+	}
+	return nil
+}
+
+// requeue puts counts that couldn't be saved back with those since
+func (s *UsageService) requeue(counts map[usageKey]*usageCount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, count := range counts {
+		if current, ok := s.pending[key]; ok {
+			current.requests += count.requests
+			if count.lastSeen.After(current.lastSeen) {
+				current.lastSeen = count.lastSeen
+			}
+			continue
+		}
+		s.pending[key] = count
+	}
+}
+
+// MemberUsage is one member's use of the app
+type MemberUsage struct {
+	User       models.User
+	Requests   int        // in the report's window
+	ActiveDays int        // days in the window they used it
+	LastSeenAt *time.Time // ever, if they have
+	Level      string     // UsageActive, UsageLapsed or UsageNever
+}
+
+// UsageReport is how approved members use the app, to tell who's reached
+// through it and who relies on the group chat
+type UsageReport struct {
+	Days    int
+	Active  int
+	Lapsed  int
+	Never   int
+	Members []MemberUsage // most recently seen first; never seen last, by name
+}
+
+// Report returns each approved member's app use over the last days days,
+// with when they were last seen at all
+func (s *UsageService) Report(days int) (*UsageReport, error) {
+	var members []models.User
+	if err := database.DB.Where("membership_status = ?", models.MembershipApproved).
+		Order("name ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	since := utils.StartOfDay(utils.NowInSydney()).AddDate(0, 0, -(days - 1))
+	var totals []struct {
+		UserID     uuid.UUID
+		Requests   int
+		ActiveDays int
+		LastSeenAt time.Time
+	}
+	if err := database.DB.Model(&models.APIUsage{}).
+		Select(`user_id, SUM(CASE WHEN day >= ? THEN requests ELSE 0 END) AS requests,
+			COUNT(*) FILTER (WHERE day >= ?) AS active_days, MAX(last_seen_at) AS last_seen_at`, since, since).
+		Group("user_id").
+		Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+	byUser := make(map[uuid.UUID]int, len(totals))
+	for i, total := range totals {
+		byUser[total.UserID] = i
+	}
+
+	activeSince := time.Now().AddDate(0, 0, -activeUsageDays)
+	report := &UsageReport{Days: days, Members: make([]MemberUsage, len(members))}
+	for i, member := range members {
+		usage := MemberUsage{User: member, Level: UsageNever}
+		if j, ok := byUser[member.ID]; ok {
+			total := totals[j]
+			usage.Requests = total.Requests
+			usage.ActiveDays = total.ActiveDays
+			usage.LastSeenAt = &total.LastSeenAt
+			usage.Level = UsageLapsed
+			if total.LastSeenAt.After(activeSince) {
+				usage.Level = UsageActive
+			}
+		}
+		switch usage.Level {
+		case UsageActive:
+			report.Active++
+		case UsageLapsed:
+			report.Lapsed++
+		default:
+			report.Never++
+		}
+		report.Members[i] = usage
+	}
+
+	sort.SliceStable(report.Members, func(i, j int) bool {
+		a, b := report.Members[i].LastSeenAt, report.Members[j].LastSeenAt
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.After(*b)
+	})
+	return report, nil
+}
@@ -2,6 +2,9 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,6 +24,7 @@ func NewUserService(adminEmail string) *UserService {
 type CreateUserInput struct {
 	Auth0ID        string
 	Email          string
+	EmailVerified  bool // Auth0 has confirmed the member owns Email
 	Name           string
 	ProfilePicture string
 }
@@ -33,6 +37,13 @@ func (s *UserService) CreateOrUpdateUser(input CreateUserInput) (*models.User, b
 	result := database.DB.Where("auth0_id = ?", input.Auth0ID).First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			// A known email under a new login has to be linked, not duplicated
+			if _, err := findUserByEmail(database.DB, input.Email); err == nil {
+				return nil, false, ErrAccountLinkRequired
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, false, err
+			}
+
 			// Create new user
 			isNew = true
 			user = models.User{
@@ -46,7 +57,7 @@ func (s *UserService) CreateOrUpdateUser(input CreateUserInput) (*models.User, b
 			}
 
 			// Check if this is the admin user
-			if s.adminEmail != "" && input.Email == s.adminEmail {
+			if s.isAdminEmail(input.Email, input.EmailVerified) {
 				user.Role = models.RoleAdmin
 				user.MembershipStatus = models.MembershipApproved
 			}
@@ -58,6 +69,26 @@ func (s *UserService) CreateOrUpdateUser(input CreateUserInput) (*models.User, b
 			return nil, false, result.Error
 		}
 	} else {
+		// Archived members rejoin by signing in
+		if user.MembershipStatus == models.MembershipArchived {
+			if err := database.DB.Transaction(func(tx *gorm.DB) error {
+				return restoreArchivedMember(tx, &user, user.ID)
+			}); err != nil {
+				return nil, false, err
+			}
+			log.Printf("Restored archived member %s on sign-in", user.ID)
+		}
+
+		// An admin who signed up before verifying their address is promoted
+		// once they sign in with it verified. The address in the token is
+		// the one Auth0 verified, so it has to be the admin's as well as
+		// the stored one.
+		if s.isAdminEmail(input.Email, input.EmailVerified) && strings.EqualFold(user.Email, input.Email) &&
+			user.MembershipStatus == models.MembershipPending {
+			user.Role = models.RoleAdmin
+			user.MembershipStatus = models.MembershipApproved
+		}
+
 		// Update existing user
 		user.Name = input.Name
 		user.ProfilePicture = input.ProfilePicture
@@ -71,6 +102,12 @@ func (s *UserService) CreateOrUpdateUser(input CreateUserInput) (*models.User, b
 	return &user, isNew, nil
 }
 
+// isAdminEmail reports whether a sign-in is from the configured admin. The
+// address must be verified, or anyone could sign up with it and become admin.
+func (s *UserService) isAdminEmail(email string, verified bool) bool {
+	return s.adminEmail != "" && verified && strings.EqualFold(email, s.adminEmail)
+}
+
 // GetUserByID retrieves a user by ID
 func (s *UserService) GetUserByID(id uuid.UUID) (*models.User, error) {
 	var user models.User
@@ -80,6 +117,17 @@ func (s *UserService) GetUserByID(id uuid.UUID) (*models.User, error) {
 	return &user, nil
 }
 
+// GetUserProfile retrieves a user by ID along with their earned badges
+func (s *UserService) GetUserProfile(id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := database.DB.Preload("Badges", func(db *gorm.DB) *gorm.DB {
+		return db.Order("awarded_at DESC")
+	}).First(&user, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // GetUserByAuth0ID retrieves a user by Auth0 ID
 func (s *UserService) GetUserByAuth0ID(auth0ID string) (*models.User, error) {
 	var user models.User
@@ -89,14 +137,46 @@ func (s *UserService) GetUserByAuth0ID(auth0ID string) (*models.User, error) {
 	return &user, nil
 }
 
-// UpdateProfile updates user profile (phone number)
-func (s *UserService) UpdateProfile(userID uuid.UUID, phoneNumber string) (*models.User, error) {
+// ProfileUpdate holds editable profile fields; nil fields are left unchanged
+type ProfileUpdate struct {
+	PhoneNumber           *string
+	EmergencyContactName  *string
+	EmergencyContactPhone *string
+	MedicalNotes          *string
+	Privacy               *models.PrivacySettings // replaces all privacy choices
+	PreferredLanguage     *models.Language
+}
+
+// UpdateProfile updates the user's contact and emergency details, privacy
+// choices and language
+func (s *UserService) UpdateProfile(userID uuid.UUID, update ProfileUpdate) (*models.User, error) {
+	if update.PreferredLanguage != nil && !update.PreferredLanguage.IsValid() {
+		return nil, ErrUnsupportedLanguage
+	}
+
 	var user models.User
 	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
 		return nil, err
 	}
 
-	user.PhoneNumber = phoneNumber
+	if update.PhoneNumber != nil {
+		user.PhoneNumber = *update.PhoneNumber
+	}
+	if update.EmergencyContactName != nil {
+		user.EmergencyContactName = *update.EmergencyContactName
+	}
+	if update.EmergencyContactPhone != nil {
+		user.EmergencyContactPhone = *update.EmergencyContactPhone
+	}
+	if update.MedicalNotes != nil {
+		user.MedicalNotes = *update.MedicalNotes
+	}
+	if update.Privacy != nil {
+		user.Privacy = *update.Privacy
+	}
+	if update.PreferredLanguage != nil {
+		user.PreferredLanguage = *update.PreferredLanguage
+	}
 	user.UpdatedAt = time.Now()
 
 	if err := database.DB.Save(&user).Error; err != nil {
@@ -117,33 +197,35 @@ func (s *UserService) ListApprovedMembers() ([]models.User, error) {
 	return users, nil
 }
 
-// ListPendingJoinRequests returns all pending membership requests
+// ListPendingJoinRequests returns all pending membership requests, those
+// who joined with an invite link first
 func (s *UserService) ListPendingJoinRequests() ([]models.User, error) {
 	var users []models.User
-	if err := database.DB.Where("membership_status = ?", models.MembershipPending).
-		Order("created_at ASC").
+	if err := database.DB.Select("users.*").
+		Joins("LEFT JOIN invite_uses ON invite_uses.user_id = users.id").
+		Where("users.membership_status = ?", models.MembershipPending).
+		Order("invite_uses.id IS NULL, users.created_at ASC").
 		Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
 }
 
-// ApproveJoinRequest approves a user's membership request
-func (s *UserService) ApproveJoinRequest(userID uuid.UUID) (*models.User, error) {
+// ApproveJoinRequest approves a user's membership request, recording the
+// admin who did
+func (s *UserService) ApproveJoinRequest(userID, adminID uuid.UUID) (*models.User, error) {
 	var user models.User
 	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
 		return nil, err
 	}
 
 	if user.MembershipStatus != models.MembershipPending {
-		return nil, errors.New("user is not pending approval")
+		return nil, ErrNotPendingMember
 	}
 
-	user.MembershipStatus = models.MembershipApproved
-	user.Role = models.RolePlayer
-	user.UpdatedAt = time.Now()
-
-	if err := database.DB.Save(&user).Error; err != nil {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return approveMember(tx, &user, &models.JoinApproval{Rule: models.JoinRuleManual, ApprovedBy: &adminID})
+	}); err != nil {
 		return nil, err
 	}
 
@@ -164,7 +246,12 @@ func (s *UserService) RejectJoinRequest(userID uuid.UUID) (*models.User, error)
 	user.MembershipStatus = models.MembershipRejected
 	user.UpdatedAt = time.Now()
 
-	if err := database.DB.Save(&user).Error; err != nil {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		return recordEvent(tx, Event{Type: models.EventMemberRejected, UserID: &user.ID, Data: struct{}{}})
+	}); err != nil {
 		return nil, err
 	}
 
@@ -187,3 +274,24 @@ func (s *UserService) UpdateUserRole(userID uuid.UUID, role models.UserRole) (*m
 
 	return &user, nil
 }
+
+// UpdateUserTier changes a member's membership tier
+func (s *UserService) UpdateUserTier(userID uuid.UUID, tier models.MembershipTier) (*models.User, error) {
+	if !tier.IsValid() {
+		return nil, fmt.Errorf("unknown membership tier %q", tier)
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	user.Tier = tier
+	user.UpdatedAt = time.Now()
+
+	if err := database.DB.Save(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
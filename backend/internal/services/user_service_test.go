@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+const testAdminEmail = "admin@example.com"
+
+func signIn(t *testing.T, auth0ID, email string, verified bool) *models.User {
+	t.Helper()
+	user, _, err := NewUserService(testAdminEmail).CreateOrUpdateUser(CreateUserInput{
+		Auth0ID: auth0ID, Email: email, EmailVerified: verified, Name: "Someone",
+	})
+	if err != nil {
+		t.Fatalf("signing in as %s: %v", email, err)
+	}
+	return user
+}
+
+func TestAdminEmailNeedsVerification(t *testing.T) {
+	testDB(t)
+	login := "auth0|" + uuid.NewString()
+
+	if user := signIn(t, login, testAdminEmail, false); user.Role == models.RoleAdmin {
+		t.Fatal("unverified admin address was made admin")
+	}
+	// Auth0 reports the address differently cased once it's verified
+	if user := signIn(t, login, "Admin@Example.com", true); user.Role != models.RoleAdmin || user.MembershipStatus != models.MembershipApproved {
+		t.Errorf("verified admin is %s and %s, want admin and approved", user.Role, user.MembershipStatus)
+	}
+}
+
+// TestAdminEmailClaimedUnverified is someone signing up with the admin's
+// address unverified, then verifying a different address of their own on
+// the same login; they mustn't be promoted
+func TestAdminEmailClaimedUnverified(t *testing.T) {
+	testDB(t)
+	login := "auth0|" + uuid.NewString()
+
+	signIn(t, login, testAdminEmail, false)
+	if user := signIn(t, login, "someone@example.com", true); user.Role == models.RoleAdmin {
+		t.Error("promoted to admin on a verified address that isn't the admin's")
+	}
+}
+
+func TestKnownEmailNeedsLinking(t *testing.T) {
+	testDB(t)
+	member := newMember(t, "Member")
+
+	_, _, err := NewUserService(testAdminEmail).CreateOrUpdateUser(CreateUserInput{
+		Auth0ID: "auth0|" + uuid.NewString(), Email: member.Email, EmailVerified: true, Name: "Member",
+	})
+	if !errors.Is(err, ErrAccountLinkRequired) {
+		t.Errorf("new login with a member's email = %v, want ErrAccountLinkRequired", err)
+	}
+}
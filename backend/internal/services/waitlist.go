@@ -0,0 +1,528 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultWaitlistOfferHours is how long an offered spot is held when the
+// club hasn't set models.Club.WaitlistOfferHours
+const defaultWaitlistOfferHours = 2
+
+var (
+	ErrNotWaitlisted       = domainError(ErrNotFound, "not_waitlisted", "you're not on the waitlist for this session")
+	ErrNoWaitlistOffer     = domainError(ErrConflict, "no_waitlist_offer", "no spot has been offered to you yet")
+	ErrWaitlistOfferLapsed = domainError(ErrDeadlinePassed, "waitlist_offer_lapsed", "the spot offered to you has lapsed")
+)
+
+// WaitlistOfferEvent is the data of the waitlist.offered and waitlist.lapsed
+// domain events
+type WaitlistOfferEvent struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// WaitlistEntry is a member waiting for a spot in a full session
+type WaitlistEntry struct {
+	Position       int        `json:"position"`
+	UserID         uuid.UUID  `json:"user_id"`
+	Name           string     `json:"name"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"` // set while a spot is held for them
+
+	User *models.User `json:"-"` // for privacy filtering when serialized
+}
+
+// GetWaitlist returns a session's waitlist in order
+func (s *RSVPService) GetWaitlist(sessionID uuid.UUID) ([]WaitlistEntry, error) {
+	return waitlistFor(database.DB, sessionID)
+}
+
+// waitlistFor reads a session's waitlist as db sees it, so it can be read
+// inside a transaction
+func waitlistFor(db *gorm.DB, sessionID uuid.UUID) ([]WaitlistEntry, error) {
+	var rows []models.WaitlistEntry
+	if err := db.Preload("User").
+		Where("session_id = ?", sessionID).
+		Order("position ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	waitlist := make([]WaitlistEntry, len(rows))
+	for i, row := range rows {
+		waitlist[i] = WaitlistEntry{
+			Position:       row.Position,
+			UserID:         row.UserID,
+			OfferExpiresAt: row.OfferExpiresAt,
+		}
+		if row.User != nil {
+			waitlist[i].Name = row.User.Name
+			waitlist[i].User = row.User
+		}
+	}
+	return waitlist, nil
+}
+
+// GetWaitlistEntry returns a member's place on a session's waitlist, or nil
+// if they aren't on it
+func (s *RSVPService) GetWaitlistEntry(sessionID, userID uuid.UUID) (*models.WaitlistEntry, error) {
+	return waitlistEntry(database.DB, sessionID, userID)
+}
+
+func waitlistEntry(db *gorm.DB, sessionID, userID uuid.UUID) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	if err := db.Where("session_id = ? AND user_id = ?", sessionID, userID).Limit(1).Find(&entry).Error; err != nil {
+		return nil, err
+	}
+	if entry.ID == uuid.Nil {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// ConfirmWaitlistOffer takes up the spot offered to a member off the
+// waitlist. Offers can come after the RSVP deadline, so it doesn't apply.
+func (s *RSVPService) ConfirmWaitlistOffer(sessionID, userID uuid.UUID) (*models.RSVP, error) {
+	var rsvp models.RSVP
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := lockSession(tx, sessionID)
+		if err != nil {
+			return ErrSessionNotFound
+		}
+		if session.Status != models.SessionStatusOpen {
+			return ErrSessionNotOpen
+		}
+
+		entry, err := waitlistEntry(tx, sessionID, userID)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			return ErrNotWaitlisted
+		}
+		if entry.OfferExpiresAt == nil {
+			return ErrNoWaitlistOffer
+		}
+		if !entry.OfferExpiresAt.After(time.Now()) {
+			return ErrWaitlistOfferLapsed
+		}
+		if err := checkRSVPLimits(tx, userID, session); err != nil {
+			return err
+		}
+
+		if _, err := leaveWaitlist(tx, sessionID, userID); err != nil {
+			return err
+		}
+		if err := tx.Where("session_id = ? AND user_id = ?", sessionID, userID).First(&rsvp).Error; err != nil {
+			return ErrRSVPNotFound
+		}
+		rsvp.Status = models.RSVPStatusIn
+		rsvp.UpdatedAt = time.Now()
+		if err := tx.Save(&rsvp).Error; err != nil {
+			return err
+		}
+		status := rsvp.Status
+		return recordEvent(tx, rsvpEvent(models.EventRSVPChanged, sessionID, userID, RSVPEvent{Status: &status}))
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.outbox.Dispatch()
+
+	database.DB.Preload("User").First(&rsvp, "id = ?", rsvp.ID)
+	return &rsvp, nil
+}
+
+// LeaveWaitlist takes a member off a session's waitlist and changes their
+// RSVP to OUT. A spot offered to them goes to the next in line. Members can
+// leave after the RSVP deadline too.
+func (s *RSVPService) LeaveWaitlist(sessionID, userID uuid.UUID) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := lockSession(tx, sessionID)
+		if err != nil {
+			return ErrSessionNotFound
+		}
+
+		entry, err := leaveWaitlist(tx, sessionID, userID)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			return ErrNotWaitlisted
+		}
+		if err := setRSVPStatus(tx, sessionID, userID, models.RSVPStatusOut); err != nil {
+			return err
+		}
+		status := models.RSVPStatusOut
+		if err := recordEvent(tx, rsvpEvent(models.EventRSVPChanged, sessionID, userID, RSVPEvent{Status: &status})); err != nil {
+			return err
+		}
+		if entry.OfferedAt != nil {
+			_, err = offerOpenSpots(tx, session)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.outbox.Dispatch()
+	return nil
+}
+
+// ExpireWaitlistOffers takes members who let an offered spot lapse off the
+// waitlist, changing their RSVP to OUT, and offers the spot to the next in
+// line. Offers for sessions since cancelled are left alone. The scheduler
+// runs it every minute.
+func (s *RSVPService) ExpireWaitlistOffers() error {
+	var due []models.WaitlistEntry
+	if err := database.DB.Joins("JOIN sessions ON sessions.id = waitlist_entries.session_id").
+		Where("waitlist_entries.offer_expires_at <= ? AND sessions.status = ?", time.Now(), models.SessionStatusOpen).
+		Find(&due).Error; err != nil {
+		return err
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, entry := range due {
+		if err := s.lapseOffer(entry); err != nil {
+			errs = append(errs, fmt.Errorf("lapsing waitlist offer %s: %w", entry.ID, err))
+		}
+	}
+	s.outbox.Dispatch()
+	log.Printf("Lapsed %d of %d waitlist offers", len(due)-len(errs), len(due))
+	return errors.Join(errs...)
+}
+
+func (s *RSVPService) lapseOffer(due models.WaitlistEntry) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		session, err := lockSession(tx, due.SessionID)
+		if err != nil {
+			return err
+		}
+		// The member may have confirmed or left since
+		entry, err := waitlistEntry(tx, due.SessionID, due.UserID)
+		if err != nil || entry == nil || entry.OfferExpiresAt == nil || entry.OfferExpiresAt.After(time.Now()) {
+			return err
+		}
+
+		if _, err := leaveWaitlist(tx, session.ID, entry.UserID); err != nil {
+			return err
+		}
+		if err := setRSVPStatus(tx, session.ID, entry.UserID, models.RSVPStatusOut); err != nil {
+			return err
+		}
+		err = recordEvent(tx, Event{
+			Type:      models.EventWaitlistLapsed,
+			SessionID: &session.ID,
+			UserID:    &entry.UserID,
+			Data:      WaitlistOfferEvent{ExpiresAt: *entry.OfferExpiresAt},
+		}, offerLapsedNotice(session, entry.UserID))
+		if err != nil {
+			return err
+		}
+		_, err = offerOpenSpots(tx, session)
+		return err
+	})
+}
+
+// lockSession loads a session for update, so that RSVPs taking and giving
+// up its spots are decided one at a time
+func lockSession(tx *gorm.DB, id uuid.UUID) (models.Session, error) {
+	var session models.Session
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&session, "id = ?", id).Error
+	return session, err
+}
+
+// placeRSVP settles in tx, with the session locked, a member's change of RSVP
+// from previous to rsvp.Status. An IN takes a spot only when one is free and
+// nobody is waiting for it; otherwise the member joins the waitlist. A
+// waitlisted member's IN takes up a spot offered to them, or else leaves
+// them waiting. Leaving the waitlist gives up the member's place in it.
+// freed reports whether a spot was given up for the next in line.
+func placeRSVP(tx *gorm.DB, session models.Session, rsvp *models.RSVP, previous models.RSVPStatus) (freed bool, err error) {
+	if rsvp.Status != models.RSVPStatusIn {
+		return vacate(tx, session, rsvp.UserID, previous)
+	}
+
+	switch previous {
+	case models.RSVPStatusIn:
+		return false, nil
+	case models.RSVPStatusWaitlisted:
+		entry, err := waitlistEntry(tx, session.ID, rsvp.UserID)
+		if err != nil {
+			return false, err
+		}
+		if entry != nil {
+			if entry.OfferExpiresAt != nil && entry.OfferExpiresAt.After(time.Now()) {
+				_, err := leaveWaitlist(tx, session.ID, rsvp.UserID)
+				return false, err
+			}
+			rsvp.Status = models.RSVPStatusWaitlisted
+			return false, nil
+		}
+	}
+
+	room, err := hasRoom(tx, session)
+	if err != nil || room {
+		return false, err
+	}
+	rsvp.Status = models.RSVPStatusWaitlisted
+	return false, joinWaitlist(tx, session.ID, rsvp.UserID)
+}
+
+// vacate gives up what a member with an RSVP of previous held in session:
+// their spot and their guests' if they were IN, or their place on the
+// waitlist. freed reports whether a spot came free, either theirs or one
+// offered to them.
+func vacate(tx *gorm.DB, session models.Session, userID uuid.UUID, previous models.RSVPStatus) (freed bool, err error) {
+	switch previous {
+	case models.RSVPStatusIn:
+		return true, dropGuests(tx, session.ID, userID)
+	case models.RSVPStatusWaitlisted:
+		entry, err := leaveWaitlist(tx, session.ID, userID)
+		return entry != nil && entry.OfferedAt != nil, err
+	}
+	return false, nil
+}
+
+// hasRoom reports whether an IN can take a spot in session straight away
+func hasRoom(tx *gorm.DB, session models.Session) (bool, error) {
+	free, err := freeSpots(tx, session)
+	return free > 0, err
+}
+
+// freeSpots counts the spots in session that nobody holds, overbooked ones
+// included, or 0 while anyone on its waitlist is still waiting for an offer
+func freeSpots(tx *gorm.DB, session models.Session) (int, error) {
+	var waiting int64
+	if err := tx.Model(&models.WaitlistEntry{}).
+		Where("session_id = ? AND offered_at IS NULL", session.ID).
+		Count(&waiting).Error; err != nil {
+		return 0, err
+	}
+	if waiting > 0 {
+		return 0, nil
+	}
+	held, err := spotsHeld(tx, session.ID)
+	if err != nil {
+		return 0, err
+	}
+	return max(session.RSVPCapacity()-held, 0), nil
+}
+
+// spotsHeld counts a session's IN RSVPs, its guests and the spots held for
+// members offered one off the waitlist. Offers past their expiry still count
+// until they're lapsed, so nobody jumps the line in between.
+func spotsHeld(db *gorm.DB, sessionID uuid.UUID) (int, error) {
+	var in, guests, offered int64
+	if err := db.Model(&models.RSVP{}).
+		Where("session_id = ? AND status = ?", sessionID, models.RSVPStatusIn).
+		Count(&in).Error; err != nil {
+		return 0, err
+	}
+	if err := db.Model(&models.GuestRSVP{}).
+		Where("session_id = ?", sessionID).
+		Count(&guests).Error; err != nil {
+		return 0, err
+	}
+	if err := db.Model(&models.WaitlistEntry{}).
+		Where("session_id = ? AND offered_at IS NOT NULL", sessionID).
+		Count(&offered).Error; err != nil {
+		return 0, err
+	}
+	return int(in + guests + offered), nil
+}
+
+// joinWaitlist puts a member at the end of a session's waitlist, unless
+// they're already on it
+func joinWaitlist(tx *gorm.DB, sessionID, userID uuid.UUID) error {
+	var last int
+	if err := tx.Model(&models.WaitlistEntry{}).
+		Where("session_id = ?", sessionID).
+		Select("COALESCE(MAX(position), 0)").
+		Scan(&last).Error; err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.WaitlistEntry{
+		SessionID: sessionID,
+		UserID:    userID,
+		Position:  last + 1,
+	}).Error
+}
+
+// leaveWaitlist takes a member off a session's waitlist and closes the gap
+// behind them. It returns the entry removed, or nil if they weren't on it.
+func leaveWaitlist(tx *gorm.DB, sessionID, userID uuid.UUID) (*models.WaitlistEntry, error) {
+	entry, err := waitlistEntry(tx, sessionID, userID)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	if err := tx.Delete(entry).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.WaitlistEntry{}).
+		Where("session_id = ? AND position > ?", sessionID, entry.Position).
+		UpdateColumn("position", gorm.Expr("position - 1")).Error; err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// rebuildWaitlist makes a session's waitlist match its waitlisted RSVPs after
+// they were changed in bulk: entries whose RSVP has moved on are dropped,
+// members waitlisted without an entry join the end in RSVP order, and
+// positions are numbered from 1 again
+func rebuildWaitlist(tx *gorm.DB, sessionID uuid.UUID) error {
+	var waitlisted []models.RSVP
+	if err := tx.Where("session_id = ? AND status = ?", sessionID, models.RSVPStatusWaitlisted).
+		Order("rsvp_timestamp ASC").
+		Find(&waitlisted).Error; err != nil {
+		return err
+	}
+	var entries []models.WaitlistEntry
+	if err := tx.Where("session_id = ?", sessionID).Order("position ASC").Find(&entries).Error; err != nil {
+		return err
+	}
+
+	waiting := make(map[uuid.UUID]bool, len(waitlisted))
+	for _, r := range waitlisted {
+		waiting[r.UserID] = true
+	}
+	position := 0
+	for _, entry := range entries {
+		if !waiting[entry.UserID] {
+			if err := tx.Delete(&entry).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		delete(waiting, entry.UserID)
+		position++
+		if entry.Position != position {
+			if err := tx.Model(&entry).UpdateColumn("position", position).Error; err != nil {
+				return err
+			}
+		}
+	}
+	for _, r := range waitlisted {
+		if !waiting[r.UserID] {
+			continue
+		}
+		position++
+		if err := tx.Create(&models.WaitlistEntry{SessionID: sessionID, UserID: r.UserID, Position: position}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setRSVPStatus changes a member's RSVP through the model, so the change
+// lands in the RSVP history
+func setRSVPStatus(tx *gorm.DB, sessionID, userID uuid.UUID, status models.RSVPStatus) error {
+	var rsvp models.RSVP
+	if err := tx.Where("session_id = ? AND user_id = ?", sessionID, userID).First(&rsvp).Error; err != nil {
+		return err
+	}
+	rsvp.Status = status
+	rsvp.UpdatedAt = time.Now()
+	return tx.Save(&rsvp).Error
+}
+
+// offerOpenSpots offers the spots nobody holds in session to the members
+// first in line without an offer, holding each for the club's
+// WaitlistOfferHours or until the session starts. Each offer is recorded as
+// a waitlist.offered event telling the member. Nothing is offered once the
+// session has started or isn't open. It returns who was offered a spot.
+func offerOpenSpots(tx *gorm.DB, session models.Session) ([]uuid.UUID, error) {
+	now := time.Now()
+	if session.Status != models.SessionStatusOpen || !session.StartsAt.After(now) {
+		return nil, nil
+	}
+	capacity := session.RSVPCapacity()
+	held, err := spotsHeld(tx, session.ID)
+	if err != nil || held >= capacity {
+		return nil, err
+	}
+
+	var next []models.WaitlistEntry
+	if err := tx.Where("session_id = ? AND offered_at IS NULL", session.ID).
+		Order("position ASC").
+		Limit(capacity - held).
+		Find(&next).Error; err != nil {
+		return nil, err
+	}
+	if len(next) == 0 {
+		return nil, nil
+	}
+
+	var club models.Club
+	if err := tx.First(&club).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	hours := club.WaitlistOfferHours
+	if hours <= 0 {
+		hours = defaultWaitlistOfferHours
+	}
+	expires := now.Add(time.Duration(hours) * time.Hour)
+	if session.StartsAt.Before(expires) {
+		expires = session.StartsAt
+	}
+
+	offered := make([]uuid.UUID, len(next))
+	for i, entry := range next {
+		offered[i] = entry.UserID
+	}
+	messages, err := loadMessage(models.MessageWaitlistUpdate).messagesFor(offered, sameData(WaitlistUpdateContext{
+		Session:  session,
+		Date:     utils.FormatDateForDisplay(session.SessionDate),
+		Deadline: expires.In(utils.SydneyLocation).Format("Monday 3:04 PM"),
+	}), map[string]string{
+		"type":       string(models.NotificationWaitlistUpdate),
+		"session_id": session.ID.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rendering waitlist offer for session %s: %w", session.ID, err)
+	}
+
+	for i, entry := range next {
+		if err := tx.Model(&models.WaitlistEntry{}).Where("id = ?", entry.ID).
+			Updates(map[string]interface{}{"offered_at": now, "offer_expires_at": expires}).Error; err != nil {
+			return nil, err
+		}
+		err := recordEvent(tx, Event{
+			Type:      models.EventWaitlistOffered,
+			SessionID: &session.ID,
+			UserID:    &offered[i],
+			Data:      WaitlistOfferEvent{ExpiresAt: expires},
+		}, OutboxNotification{Type: models.NotificationWaitlistUpdate, Messages: messages[i : i+1]})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return offered, nil
+}
+
+// offerLapsedNotice tells a member the spot offered to them has gone to the
+// next in line
+func offerLapsedNotice(session models.Session, userID uuid.UUID) OutboxNotification {
+	body := fmt.Sprintf("The spot offered to you for %s on %s wasn't confirmed in time, so it has gone to the next person on the waitlist. Your RSVP is now OUT.",
+		session.Title, utils.FormatDateForDisplay(session.SessionDate))
+	data := map[string]string{
+		"type":       string(models.NotificationWaitlistUpdate),
+		"session_id": session.ID.String(),
+	}
+	return OutboxNotification{
+		Type:     models.NotificationWaitlistUpdate,
+		Messages: []NotificationMessage{{UserID: userID, Title: "Waitlist Spot Lapsed", Body: body, Data: data}},
+	}
+}
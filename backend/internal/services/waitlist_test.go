@@ -0,0 +1,184 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+)
+
+func statusOf(t *testing.T, session *models.Session, user *models.User) models.RSVPStatus {
+	t.Helper()
+	var rsvp models.RSVP
+	if err := database.DB.First(&rsvp, "session_id = ? AND user_id = ?", session.ID, user.ID).Error; err != nil {
+		t.Fatalf("reading %s's RSVP: %v", user.Name, err)
+	}
+	return rsvp.Status
+}
+
+func entryOf(t *testing.T, session *models.Session, user *models.User) *models.WaitlistEntry {
+	t.Helper()
+	entry, err := waitlistEntry(database.DB, session.ID, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entry
+}
+
+func mustRSVP(t *testing.T, service *RSVPService, session *models.Session, user *models.User, status models.RSVPStatus) {
+	t.Helper()
+	if _, err := rsvpAs(service, session, user, status); err != nil {
+		t.Fatalf("%s RSVPing %s: %v", user.Name, status, err)
+	}
+}
+
+func TestFullSessionWaitlists(t *testing.T) {
+	testDB(t)
+	service := newRSVPService()
+	session := newSession(t, 48*time.Hour, 2)
+	a, b, c, d := newMember(t, "A"), newMember(t, "B"), newMember(t, "C"), newMember(t, "D")
+	for _, u := range []*models.User{a, b, c, d} {
+		mustRSVP(t, service, session, u, models.RSVPStatusIn)
+	}
+
+	for _, u := range []*models.User{a, b} {
+		if got := statusOf(t, session, u); got != models.RSVPStatusIn {
+			t.Errorf("%s is %s, want in", u.Name, got)
+		}
+	}
+	for i, u := range []*models.User{c, d} {
+		entry := entryOf(t, session, u)
+		if got := statusOf(t, session, u); got != models.RSVPStatusWaitlisted || entry == nil || entry.Position != i+1 {
+			t.Errorf("%s is %s with entry %+v, want waitlisted at %d", u.Name, got, entry, i+1)
+		}
+	}
+}
+
+// TestConcurrentRSVPsFillOnce RSVPs more members than there are spots at
+// once; the spots must fill exactly, with everyone else waitlisted in order
+func TestConcurrentRSVPsFillOnce(t *testing.T) {
+	testDB(t)
+	service := newRSVPService()
+	session := newSession(t, 48*time.Hour, 3)
+	members := make([]*models.User, 8)
+	for i := range members {
+		members[i] = newMember(t, "Member")
+	}
+
+	errs := concurrently(len(members), func(i int) error {
+		_, err := rsvpAs(service, session, members[i], models.RSVPStatusIn)
+		return err
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("RSVP: %v", err)
+		}
+	}
+
+	var in int64
+	database.DB.Model(&models.RSVP{}).Where("session_id = ? AND status = ?", session.ID, models.RSVPStatusIn).Count(&in)
+	waitlist, err := service.GetWaitlist(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in != 3 || len(waitlist) != 5 {
+		t.Fatalf("%d in and %d waitlisted, want 3 and 5", in, len(waitlist))
+	}
+	for i, entry := range waitlist {
+		if entry.Position != i+1 {
+			t.Errorf("waitlist positions %v, want 1 to 5", waitlist)
+			break
+		}
+	}
+}
+
+func TestDropOutOffersSpot(t *testing.T) {
+	testDB(t)
+	service := newRSVPService()
+	session := newSession(t, 48*time.Hour, 1)
+	a, b, c := newMember(t, "A"), newMember(t, "B"), newMember(t, "C")
+	mustRSVP(t, service, session, a, models.RSVPStatusIn)
+	mustRSVP(t, service, session, b, models.RSVPStatusIn)
+
+	mustRSVP(t, service, session, a, models.RSVPStatusOut)
+	offer := entryOf(t, session, b)
+	if offer == nil || offer.OfferExpiresAt == nil {
+		t.Fatalf("B's entry is %+v, want a spot offered", offer)
+	}
+
+	// The offered spot is held, so a newcomer waits behind B
+	mustRSVP(t, service, session, c, models.RSVPStatusIn)
+	if got := statusOf(t, session, c); got != models.RSVPStatusWaitlisted {
+		t.Errorf("C is %s while B's offer stands, want waitlisted", got)
+	}
+
+	if _, err := service.ConfirmWaitlistOffer(session.ID, b.ID); err != nil {
+		t.Fatalf("ConfirmWaitlistOffer: %v", err)
+	}
+	if got := statusOf(t, session, b); got != models.RSVPStatusIn || entryOf(t, session, b) != nil {
+		t.Errorf("B is %s after confirming, want in and off the waitlist", got)
+	}
+	if entry := entryOf(t, session, c); entry == nil || entry.Position != 1 {
+		t.Errorf("C's entry is %+v, want first in line", entry)
+	}
+}
+
+// TestConfirmOfferOnce confirms the same offer several times at once; it
+// may only be taken up once
+func TestConfirmOfferOnce(t *testing.T) {
+	testDB(t)
+	service := newRSVPService()
+	session := newSession(t, 48*time.Hour, 1)
+	a, b := newMember(t, "A"), newMember(t, "B")
+	mustRSVP(t, service, session, a, models.RSVPStatusIn)
+	mustRSVP(t, service, session, b, models.RSVPStatusIn)
+	mustRSVP(t, service, session, a, models.RSVPStatusOut)
+
+	errs := concurrently(4, func(int) error {
+		_, err := service.ConfirmWaitlistOffer(session.ID, b.ID)
+		return err
+	})
+	confirmed := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			confirmed++
+		case !errors.Is(err, ErrNotWaitlisted):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if confirmed != 1 {
+		t.Errorf("offer taken up %d times, want 1", confirmed)
+	}
+}
+
+func TestLapsedOfferMovesOn(t *testing.T) {
+	testDB(t)
+	service := newRSVPService()
+	session := newSession(t, 48*time.Hour, 1)
+	a, b, c := newMember(t, "A"), newMember(t, "B"), newMember(t, "C")
+	for _, u := range []*models.User{a, b, c} {
+		mustRSVP(t, service, session, u, models.RSVPStatusIn)
+	}
+	mustRSVP(t, service, session, a, models.RSVPStatusOut)
+
+	if err := database.DB.Model(&models.WaitlistEntry{}).Where("session_id = ? AND user_id = ?", session.ID, b.ID).
+		Update("offer_expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ConfirmWaitlistOffer(session.ID, b.ID); !errors.Is(err, ErrWaitlistOfferLapsed) {
+		t.Errorf("confirming a lapsed offer = %v, want ErrWaitlistOfferLapsed", err)
+	}
+	if err := service.ExpireWaitlistOffers(); err != nil {
+		t.Fatalf("ExpireWaitlistOffers: %v", err)
+	}
+
+	if got := statusOf(t, session, b); got != models.RSVPStatusOut || entryOf(t, session, b) != nil {
+		t.Errorf("B is %s after the offer lapsed, want out and off the waitlist", got)
+	}
+	if entry := entryOf(t, session, c); entry == nil || entry.Position != 1 || entry.OfferExpiresAt == nil {
+		t.Errorf("C's entry is %+v, want first in line with the spot offered", entry)
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/weekday-masters/backend/internal/utils"
+)
+
+// WeatherProvider returns the forecast for the venue at a given time
+type WeatherProvider interface {
+	Forecast(ctx context.Context, at time.Time) (*Forecast, error)
+}
+
+// Forecast is the weather expected for one hour
+type Forecast struct {
+	TemperatureC float64 `json:"temperature_c"`
+	RainChance   int     `json:"rain_chance"` // percent
+	WindKmh      float64 `json:"wind_kmh"`
+}
+
+// String returns something like "19°C, 30% chance of rain"
+func (f *Forecast) String() string {
+	return fmt.Sprintf("%.0f°C, %d%% chance of rain", f.TemperatureC, f.RainChance)
+}
+
+// OpenMeteoWeather fetches hourly forecasts from Open-Meteo, which needs no API key
+type OpenMeteoWeather struct {
+	latitude  float64
+	longitude float64
+	client    *http.Client
+}
+
+func NewOpenMeteoWeather(latitude, longitude float64) *OpenMeteoWeather {
+	return &OpenMeteoWeather{
+		latitude:  latitude,
+		longitude: longitude,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Forecast returns the forecast for the hour containing at
+func (w *OpenMeteoWeather) Forecast(ctx context.Context, at time.Time) (*Forecast, error) {
+	at = at.In(utils.SydneyLocation)
+	day := at.Format("2006-01-02")
+
+	params := url.Values{}
+	params.Set("latitude", fmt.Sprintf("%.4f", w.latitude))
+	params.Set("longitude", fmt.Sprintf("%.4f", w.longitude))
+	params.Set("hourly", "temperature_2m,precipitation_probability,wind_speed_10m")
+	params.Set("timezone", utils.SydneyLocation.String())
+	params.Set("start_date", day)
+	params.Set("end_date", day)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.open-meteo.com/v1/forecast?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("open-meteo returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Hourly struct {
+			Time                     []string  `json:"time"`
+			Temperature2m            []float64 `json:"temperature_2m"`
+			PrecipitationProbability []int     `json:"precipitation_probability"`
+			WindSpeed10m             []float64 `json:"wind_speed_10m"`
+		} `json:"hourly"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode forecast: %w", err)
+	}
+
+	hour := at.Format("2006-01-02T15") + ":00"
+	for i, t := range body.Hourly.Time {
+		if t != hour || i >= len(body.Hourly.Temperature2m) || i >= len(body.Hourly.PrecipitationProbability) || i >= len(body.Hourly.WindSpeed10m) {
+			continue
+		}
+		return &Forecast{
+			TemperatureC: body.Hourly.Temperature2m[i],
+			RainChance:   body.Hourly.PrecipitationProbability[i],
+			WindKmh:      body.Hourly.WindSpeed10m[i],
+		}, nil
+	}
+	return nil, errors.New("no forecast for that hour")
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// weatherProposalWindow is how far ahead outdoor sessions' forecasts are
+// checked for a cancellation proposal
+const weatherProposalWindow = 48 * time.Hour
+
+// Decisions on a cancellation proposal, as used in its links
+const (
+	ProposalApprove = "approve"
+	ProposalDecline = "decline"
+)
+
+var (
+	ErrProposalNotFound    = domainError(ErrNotFound, "proposal_not_found", "cancellation proposal not found")
+	ErrProposalDecided     = domainError(ErrConflict, "proposal_decided", "this proposal has already been decided")
+	ErrProposalLinkInvalid = domainError(ErrForbidden, "proposal_link_invalid", "this link is not valid")
+	ErrProposalLapsed      = domainError(ErrConflict, "proposal_lapsed", "the session has already started or been cancelled")
+)
+
+// WeatherProposalService proposes cancelling outdoor sessions whose forecast
+// crosses the club's thresholds, and carries out admins' decisions
+type WeatherProposalService struct {
+	weather             WeatherProvider // nil disables proposals
+	sessionService      *SessionService
+	notificationService *NotificationService
+}
+
+func NewWeatherProposalService(weather WeatherProvider, sessionService *SessionService, notificationService *NotificationService) *WeatherProposalService {
+	return &WeatherProposalService{
+		weather:             weather,
+		sessionService:      sessionService,
+		notificationService: notificationService,
+	}
+}
+
+// IsEnabled reports whether a forecast source is configured
+func (s *WeatherProposalService) IsEnabled() bool {
+	return s.weather != nil
+}
+
+// ProposeCancellations checks the forecast for outdoor sessions starting
+// within weatherProposalWindow that haven't had a proposal, and proposes
+// cancelling those past a threshold. Pending proposals whose session has
+// started or been cancelled meanwhile lapse.
+func (s *WeatherProposalService) ProposeCancellations(ctx context.Context, report *JobReport) error {
+	if !report.isDryRun() {
+		if err := lapseProposals(); err != nil {
+			return err
+		}
+	}
+
+	var club models.Club
+	database.DB.First(&club)
+
+	now := time.Now()
+	var sessions []models.Session
+	if err := database.DB.Where(
+		"is_outdoor = ? AND status != ? AND starts_at > ? AND starts_at <= ?",
+		true, models.SessionStatusCancelled, now, now.Add(weatherProposalWindow),
+	).Where("NOT EXISTS (SELECT 1 FROM cancellation_proposals WHERE cancellation_proposals.session_id = sessions.id)").
+		Order("starts_at ASC").
+		Find(&sessions).Error; err != nil {
+		return fmt.Errorf("fetching outdoor sessions for weather proposals: %w", err)
+	}
+
+	var errs []error
+	for _, session := range sessions {
+		forecast, err := s.weather.Forecast(ctx, session.StartsAt)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fetching forecast for session %s: %w", session.ID, err))
+			continue
+		}
+		reasons := weatherReasons(club, forecast)
+		if len(reasons) == 0 {
+			continue
+		}
+
+		proposal := models.CancellationProposal{
+			SessionID: session.ID,
+			Status:    models.ProposalPending,
+			Reason:    strings.Join(reasons, ", "),
+			Forecast:  forecast.String(),
+		}
+		report.add(JobAction{Kind: "propose_cancellation", SessionID: &session.ID, Title: session.Title, Detail: proposal.Reason})
+		if report.isDryRun() {
+			continue
+		}
+
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		proposal.Secret = hex.EncodeToString(secret)
+		// A proposal made meanwhile, say by another instance, wins
+		result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&proposal)
+		if result.Error != nil {
+			errs = append(errs, fmt.Errorf("creating weather proposal for session %s: %w", session.ID, result.Error))
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		if err := s.notifyAdmins(ctx, proposal, session); err != nil {
+			errs = append(errs, err)
+		}
+		log.Printf("Proposed cancelling session %s for the weather: %s", session.Title, proposal.Reason)
+	}
+	return errors.Join(errs...)
+}
+
+// weatherReasons lists the club's thresholds a forecast reaches
+func weatherReasons(club models.Club, f *Forecast) []string {
+	var reasons []string
+	if club.WeatherRainChance > 0 && f.RainChance >= club.WeatherRainChance {
+		reasons = append(reasons, fmt.Sprintf("%d%% chance of rain", f.RainChance))
+	}
+	if club.WeatherWindKmh > 0 && f.WindKmh >= float64(club.WeatherWindKmh) {
+		reasons = append(reasons, fmt.Sprintf("wind %.0f km/h", f.WindKmh))
+	}
+	if club.WeatherTemperature > 0 && f.TemperatureC >= float64(club.WeatherTemperature) {
+		reasons = append(reasons, fmt.Sprintf("%.0f°C", f.TemperatureC))
+	}
+	return reasons
+}
+
+// lapseProposals closes pending proposals whose session has started or was
+// cancelled some other way
+func lapseProposals() error {
+	return database.DB.Model(&models.CancellationProposal{}).
+		Where("status = ?", models.ProposalPending).
+		Where("session_id IN (SELECT id FROM sessions WHERE starts_at <= ? OR status = ?)", time.Now(), models.SessionStatusCancelled).
+		Updates(map[string]interface{}{"status": models.ProposalLapsed, "updated_at": time.Now()}).Error
+}
+
+// notifyAdmins asks every admin to approve or decline a proposal. Each push
+// carries approve and decline links signed for that admin, which the app's
+// service worker follows when one of the notification's buttons is tapped.
+func (s *WeatherProposalService) notifyAdmins(ctx context.Context, proposal models.CancellationProposal, session models.Session) error {
+	var adminIDs []uuid.UUID
+	if err := database.DB.Model(&models.User{}).
+		Where("role = ?", models.RoleAdmin).
+		Pluck("id", &adminIDs).Error; err != nil {
+		return fmt.Errorf("finding admins for weather proposal %s: %w", proposal.ID, err)
+	}
+
+	actions, err := json.Marshal([]map[string]string{
+		{"action": ProposalApprove, "title": "Cancel session"},
+		{"action": ProposalDecline, "title": "Keep it on"},
+	})
+	if err != nil {
+		return err
+	}
+
+	title := "Cancel " + session.Title + " for the weather?"
+	body := fmt.Sprintf("The forecast for %s at %s is %s (%s). Nothing is cancelled until an admin approves.",
+		utils.FormatDateForDisplay(session.SessionDate), session.StartsAt.In(utils.SydneyLocation).Format("3:04 PM"),
+		proposal.Forecast, proposal.Reason)
+
+	messages := make([]NotificationMessage, len(adminIDs))
+	for i, adminID := range adminIDs {
+		messages[i] = NotificationMessage{
+			UserID: adminID,
+			Title:  title,
+			Body:   body,
+			Data: map[string]string{
+				"type":         string(models.NotificationWeatherProposal),
+				"session_id":   session.ID.String(),
+				"proposal_id":  proposal.ID.String(),
+				"actions":      string(actions),
+				"approve_path": proposalLinkPath(proposal, adminID, ProposalApprove),
+				"decline_path": proposalLinkPath(proposal, adminID, ProposalDecline),
+			},
+		}
+	}
+	if _, err := s.notificationService.SendBatch(ctx, models.NotificationWeatherProposal, messages); err != nil {
+		return fmt.Errorf("notifying admins of weather proposal %s: %w", proposal.ID, err)
+	}
+	return nil
+}
+
+// proposalLinkPath is the API path, under /api, that carries out decision
+// for an admin without signing in
+func proposalLinkPath(proposal models.CancellationProposal, adminID uuid.UUID, decision string) string {
+	return fmt.Sprintf("/weather-proposals/%s/%s?admin=%s&token=%s",
+		proposal.ID, decision, adminID, proposalToken(proposal, adminID, decision))
+}
+
+func proposalToken(proposal models.CancellationProposal, adminID uuid.UUID, decision string) string {
+	mac := hmac.New(sha256.New, []byte(proposal.Secret))
+	mac.Write([]byte(adminID.String() + ":" + decision))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ListProposals returns cancellation proposals, newest first, optionally
+// only those with status
+func (s *WeatherProposalService) ListProposals(status models.ProposalStatus) ([]models.CancellationProposal, error) {
+	query := database.DB.Preload("Session").Preload("Decider")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var proposals []models.CancellationProposal
+	err := query.Order("created_at DESC").Limit(100).Find(&proposals).Error
+	return proposals, err
+}
+
+// DecideWithToken carries out a decision from a link sent to an admin, once
+// its token checks out and they're still an admin
+func (s *WeatherProposalService) DecideWithToken(id, adminID uuid.UUID, decision, token string) (*models.CancellationProposal, error) {
+	var proposal models.CancellationProposal
+	if err := database.DB.First(&proposal, "id = ?", id).Error; err != nil {
+		return nil, ErrProposalNotFound
+	}
+	expected := proposalToken(proposal, adminID, decision)
+	if !hmac.Equal([]byte(token), []byte(expected)) {
+		return nil, ErrProposalLinkInvalid
+	}
+	var admin models.User
+	if err := database.DB.First(&admin, "id = ?", adminID).Error; err != nil || !admin.IsAdmin() {
+		return nil, ErrProposalLinkInvalid
+	}
+	return s.Decide(id, adminID, decision == ProposalApprove)
+}
+
+// Decide approves a proposal, cancelling its session and telling members
+// why, or declines it so the session goes ahead. The first admin to decide
+// settles it.
+func (s *WeatherProposalService) Decide(id, adminID uuid.UUID, approve bool) (*models.CancellationProposal, error) {
+	var proposal models.CancellationProposal
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&proposal, "id = ?", id).Error; err != nil {
+			return ErrProposalNotFound
+		}
+		if proposal.Status != models.ProposalPending {
+			return ErrProposalDecided
+		}
+
+		var session models.Session
+		if err := tx.First(&session, "id = ?", proposal.SessionID).Error; err != nil {
+			return ErrSessionNotFound
+		}
+		status := models.ProposalDeclined
+		if approve {
+			status = models.ProposalApproved
+		}
+		if session.Status == models.SessionStatusCancelled || !time.Now().Before(session.StartsAt) {
+			status = models.ProposalLapsed
+		}
+
+		now := time.Now()
+		proposal.Status = status
+		proposal.DecidedBy = &adminID
+		proposal.DecidedAt = &now
+		return tx.Save(&proposal).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status == models.ProposalLapsed {
+		return nil, ErrProposalLapsed
+	}
+
+	if proposal.Status == models.ProposalApproved {
+		if _, err := s.sessionService.CancelSession(proposal.SessionID, "Forecast: "+proposal.Reason); err != nil {
+			return nil, err
+		}
+	}
+	return &proposal, nil
+}
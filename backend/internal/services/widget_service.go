@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/weekday-masters/backend/internal/database"
+	"github.com/weekday-masters/backend/internal/models"
+	"github.com/weekday-masters/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// WidgetCacheTTL is how long the public widget is served from memory
+const WidgetCacheTTL = 5 * time.Minute
+
+// Widget is the public summary shown on the club's website
+type Widget struct {
+	ClubName    string         `json:"club_name"`
+	NextSession *WidgetSession `json:"next_session"`
+	MemberCount int64          `json:"member_count"`
+	GeneratedAt time.Time      `json:"generated_at"`
+}
+
+// WidgetSession is the public view of the next open session
+type WidgetSession struct {
+	Title       string `json:"title"`
+	SessionDate string `json:"session_date"` // YYYY-MM-DD
+	StartTime   string `json:"start_time"`
+	EndTime     string `json:"end_time"`
+	MaxPlayers  int    `json:"max_players"`
+	SpotsLeft   int    `json:"spots_left"`
+}
+
+// WidgetService builds the public widget, caching it so an embedded page
+// with heavy traffic doesn't reach the database on every view
+type WidgetService struct {
+	mu        sync.Mutex
+	cached    *Widget
+	expiresAt time.Time
+}
+
+func NewWidgetService() *WidgetService {
+	return &WidgetService{}
+}
+
+// GetWidget returns the cached widget, rebuilding it once the TTL has passed
+func (s *WidgetService) GetWidget() (*Widget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && time.Now().Before(s.expiresAt) {
+		return s.cached, nil
+	}
+
+	widget, err := s.build()
+	if err != nil {
+		// Serve the stale copy rather than failing the embed
+		if s.cached != nil {
+			return s.cached, nil
+		}
+		return nil, err
+	}
+	s.cached = widget
+	s.expiresAt = time.Now().Add(WidgetCacheTTL)
+	return widget, nil
+}
+
+func (s *WidgetService) build() (*Widget, error) {
+	widget := &Widget{GeneratedAt: time.Now()}
+
+	var club models.Club
+	if err := database.DB.First(&club).Error; err == nil {
+		widget.ClubName = club.Name
+	}
+
+	if err := database.DB.Model(&models.User{}).
+		Where("membership_status = ?", models.MembershipApproved).
+		Count(&widget.MemberCount).Error; err != nil {
+		return nil, err
+	}
+
+	var session models.Session
+	today := utils.StartOfDay(utils.NowInSydney())
+	err := database.DB.Where("session_date >= ? AND status = ?", today, models.SessionStatusOpen).
+		Order("starts_at ASC").
+		First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return widget, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var inCount int64
+	if err := database.DB.Model(&models.RSVP{}).
+		Where("session_id = ? AND status = ?", session.ID, models.RSVPStatusIn).
+		Count(&inCount).Error; err != nil {
+		return nil, err
+	}
+	spotsLeft := session.RSVPCapacity() - int(inCount) - session.GuestCount
+	if spotsLeft < 0 {
+		spotsLeft = 0
+	}
+
+	widget.NextSession = &WidgetSession{
+		Title:       session.Title,
+		SessionDate: session.SessionDate.Format("2006-01-02"),
+		StartTime:   utils.FormatClock(session.StartsAt),
+		EndTime:     utils.FormatClock(session.EndsAt),
+		MaxPlayers:  session.MaxPlayers,
+		SpotsLeft:   spotsLeft,
+	}
+	return widget, nil
+}
@@ -0,0 +1,326 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// GCSStore keeps objects in a Google Cloud Storage bucket using application
+// default credentials. URLs are presigned with the service account's key
+// when the credentials include one, and otherwise through the IAM
+// Credentials API, which needs the account to hold Service Account Token
+// Creator on itself.
+type GCSStore struct {
+	bucket string
+	client *http.Client
+
+	email      string          // service account, once known
+	privateKey *rsa.PrivateKey // from a key file; nil signs through IAM
+	emailOnce  sync.Once
+	emailErr   error
+}
+
+func NewGCSStore(ctx context.Context, bucket string) (*GCSStore, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("bucket is required for Cloud Storage")
+	}
+	creds, err := google.FindDefaultCredentials(ctx,
+		"https://www.googleapis.com/auth/devstorage.read_write",
+		"https://www.googleapis.com/auth/iam")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Google credentials: %w", err)
+	}
+	s := &GCSStore{bucket: bucket, client: oauth2.NewClient(ctx, creds.TokenSource)}
+
+	var keyFile struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	if len(creds.JSON) > 0 && json.Unmarshal(creds.JSON, &keyFile) == nil && keyFile.PrivateKey != "" {
+		key, err := parseRSAKey(keyFile.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service account key: %w", err)
+		}
+		s.email, s.privateKey = keyFile.ClientEmail, key
+	}
+	return s, nil
+}
+
+func parseRSAKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA key")
+	}
+	return key, nil
+}
+
+func (s *GCSStore) Name() string {
+	return "gcs"
+}
+
+func (s *GCSStore) objectURL(key string) string {
+	return fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o/%s",
+		url.PathEscape(s.bucket), url.PathEscape(key))
+}
+
+// Put uploads an object, replacing any existing one with the same key
+func (s *GCSStore) Put(ctx context.Context, key, contentType string, r io.Reader) error {
+	endpoint := fmt.Sprintf("https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		url.PathEscape(s.bucket), url.QueryEscape(key))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cloud storage upload returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Get returns the object's contents; the caller must close them
+func (s *GCSStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key)+"?alt=media", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("cloud storage download returned status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes an object; deleting a missing object is not an error
+func (s *GCSStore) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("cloud storage delete returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// List returns the objects whose keys start with prefix
+func (s *GCSStore) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	pageToken := ""
+	for {
+		query := url.Values{"prefix": {prefix}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		endpoint := fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o?%s", url.PathEscape(s.bucket), query.Encode())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Items []struct {
+				Name    string    `json:"name"`
+				Size    string    `json:"size"` // int64 sent as a string
+				Updated time.Time `json:"updated"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		err = func() error {
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("cloud storage list returned status %d", resp.StatusCode)
+			}
+			return json.NewDecoder(resp.Body).Decode(&page)
+		}()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range page.Items {
+			size, _ := strconv.ParseInt(item.Size, 10, 64)
+			objects = append(objects, Object{Key: item.Name, Size: size, UpdatedAt: item.Updated})
+		}
+		if page.NextPageToken == "" {
+			return objects, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// PresignURL returns a V4 signed GET URL for the object
+func (s *GCSStore) PresignURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := checkExpiry(expiry); err != nil {
+		return "", err
+	}
+	email, err := s.serviceAccount()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	timestamp := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/auto/storage/goog4_request"
+	u := &url.URL{Scheme: "https", Host: "storage.googleapis.com", Path: "/" + s.bucket + "/" + key}
+
+	query := url.Values{
+		"X-Goog-Algorithm":     {"GOOG4-RSA-SHA256"},
+		"X-Goog-Credential":    {email + "/" + scope},
+		"X-Goog-Date":          {timestamp},
+		"X-Goog-Expires":       {strconv.Itoa(int(expiry.Seconds()))},
+		"X-Goog-SignedHeaders": {"host"},
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	params := make([]string, len(keys))
+	for i, k := range keys {
+		params[i] = url.QueryEscape(k) + "=" + strings.ReplaceAll(url.QueryEscape(query.Get(k)), "+", "%20")
+	}
+	u.RawQuery = strings.Join(params, "&")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		u.RawQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	digest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"GOOG4-RSA-SHA256", timestamp, scope, hex.EncodeToString(digest[:])}, "\n")
+
+	signature, err := s.signBytes(ctx, email, []byte(stringToSign))
+	if err != nil {
+		return "", fmt.Errorf("signing URL: %w", err)
+	}
+	u.RawQuery += "&X-Goog-Signature=" + hex.EncodeToString(signature)
+	return u.String(), nil
+}
+
+// serviceAccount returns the account URLs are signed as: from the key file,
+// or else the one the metadata server says the instance runs as. It's looked
+// up once, outside any request's context, since the answer is cached.
+func (s *GCSStore) serviceAccount() (string, error) {
+	s.emailOnce.Do(func() {
+		if s.email != "" {
+			return
+		}
+		req, err := http.NewRequest(http.MethodGet,
+			"http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/email", nil)
+		if err != nil {
+			s.emailErr = err
+			return
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		resp, err := (&http.Client{Timeout: 5 * time.Second}).Do(req)
+		if err != nil {
+			s.emailErr = fmt.Errorf("presigning needs a service account key or the metadata server: %w", err)
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			s.emailErr = fmt.Errorf("metadata server returned status %d", resp.StatusCode)
+			return
+		}
+		s.email = strings.TrimSpace(string(body))
+	})
+	return s.email, s.emailErr
+}
+
+// signBytes signs with RSA-SHA256, locally with the key file's key or
+// through the IAM Credentials signBlob API
+func (s *GCSStore) signBytes(ctx context.Context, email string, data []byte) ([]byte, error) {
+	if s.privateKey != nil {
+		digest := sha256.Sum256(data)
+		return rsa.SignPKCS1v15(nil, s.privateKey, crypto.SHA256, digest[:])
+	}
+
+	body, err := json.Marshal(map[string]string{"payload": base64.StdEncoding.EncodeToString(data)})
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/%s:signBlob", url.PathEscape(email))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signBlob returned status %d", resp.StatusCode)
+	}
+
+	var signed struct {
+		SignedBlob string `json:"signedBlob"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&signed); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(signed.SignedBlob)
+}
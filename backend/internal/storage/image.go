@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // registers the PNG decoder
+)
+
+// MaxImagePixels is the largest photo, in pixels, that's decoded for
+// resizing; a small file can still decode to a huge image
+const MaxImagePixels = 24_000_000
+
+// SquareJPEGs crops a JPEG or PNG photo to a centred square, turns it
+// upright as its EXIF orientation says, and returns it scaled to each of
+// sizes, in pixels, encoded as JPEG. Photos smaller than a size are scaled up.
+func SquareJPEGs(data []byte, sizes []int) ([][]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, &UploadError{Kind: ErrUnsupportedType, Message: "The photo couldn't be read"}
+	}
+	if config.Width*config.Height > MaxImagePixels {
+		return nil, &UploadError{Kind: ErrTooLarge, Message: "Photos must be 24 megapixels or smaller"}
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &UploadError{Kind: ErrUnsupportedType, Message: "The photo couldn't be read"}
+	}
+
+	// Crop once into RGBA so scaling reads pixels directly. Transparency in
+	// a PNG goes white, as JPEG has none.
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	origin := image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2)
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(square, square.Bounds(), src, origin, draw.Over)
+	square = orient(square, exifOrientation(data))
+
+	out := make([][]byte, len(sizes))
+	for i, size := range sizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, scale(square, size), &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+		out[i] = buf.Bytes()
+	}
+	return out, nil
+}
+
+// scale resizes a square image to size×size, averaging the source pixels
+// each target pixel covers
+func scale(src *image.RGBA, size int) *image.RGBA {
+	side := src.Bounds().Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for dy := 0; dy < size; dy++ {
+		y0, y1 := span(dy, side, size)
+		for dx := 0; dx < size; dx++ {
+			x0, x1 := span(dx, side, size)
+			var sum [4]int
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride:]
+				for x := x0; x < x1; x++ {
+					p := row[x*4 : x*4+4]
+					sum[0] += int(p[0])
+					sum[1] += int(p[1])
+					sum[2] += int(p[2])
+					sum[3] += int(p[3])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			d := dst.Pix[dy*dst.Stride+dx*4:]
+			for c := 0; c < 4; c++ {
+				d[c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// span is the range of source pixels, out of side, that target pixel i of
+// size covers; at least one, so scaling up repeats pixels
+func span(i, side, size int) (int, int) {
+	start := i * side / size
+	end := (i + 1) * side / size
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
+
+// orient turns a square image as EXIF orientation o (1-8) says it should be
+// shown
+func orient(src *image.RGBA, o int) *image.RGBA {
+	if o < 2 || o > 8 {
+		return src
+	}
+	n := src.Bounds().Dx() - 1
+	dst := image.NewRGBA(src.Bounds())
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			// Where the pixel shown at (x, y) is stored
+			sx, sy := x, y
+			switch o {
+			case 2:
+				sx = n - x
+			case 3:
+				sx, sy = n-x, n-y
+			case 4:
+				sy = n - y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, n-x
+			case 7:
+				sx, sy = n-y, n-x
+			case 8:
+				sx, sy = n-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:])
+		}
+	}
+	return dst
+}
+
+// exifOrientation reads the orientation tag from a JPEG's EXIF data: 1 for
+// upright, up to 8, or 0 when there's none
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 0
+		}
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			return 0 // image data starts, or the segment is cut short
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 0
+}
+
+// tiffOrientation finds the orientation tag (0x0112) in the first IFD of
+// TIFF-structured EXIF data
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		entry := ifd + 2 + e*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
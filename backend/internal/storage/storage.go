@@ -0,0 +1,60 @@
+// Package storage keeps uploaded files and database backups in object
+// storage: Google Cloud Storage or S3 in production, a local directory for
+// development.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// MaxPresignExpiry is the longest a presigned URL can last, the limit S3 and
+// Cloud Storage both set
+const MaxPresignExpiry = 7 * 24 * time.Hour
+
+// Store saves and serves file contents by key
+type Store interface {
+	Put(ctx context.Context, key, contentType string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// PresignURL returns a URL anyone can download the object from until
+	// expiry passes, without going through the API
+	PresignURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	Name() string
+}
+
+// checkExpiry rejects presign expiries the backends won't accept
+func checkExpiry(expiry time.Duration) error {
+	if expiry <= 0 || expiry > MaxPresignExpiry {
+		return fmt.Errorf("presigned URL expiry must be between 1s and %s", MaxPresignExpiry)
+	}
+	return nil
+}
+
+// Object describes a stored object
+type Object struct {
+	Key       string
+	Size      int64
+	UpdatedAt time.Time
+}
+
+// New returns the store for backend: "gcs" and "s3" need a bucket, "local" a directory
+func New(ctx context.Context, backend, bucket, dir string) (Store, error) {
+	switch backend {
+	case "gcs":
+		return NewGCSStore(ctx, bucket)
+	case "s3":
+		return NewS3Store(bucket)
+	case "local":
+		return NewLocalStore(dir)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Upload errors, wrapped in an *UploadError carrying the message for members
+var (
+	ErrUnsupportedType = errors.New("unsupported file type")
+	ErrTooLarge        = errors.New("file too large")
+)
+
+// UploadError is an upload an UploadPolicy refuses
+type UploadError struct {
+	Kind    error // ErrUnsupportedType or ErrTooLarge
+	Message string
+}
+
+func (e *UploadError) Error() string { return e.Message }
+func (e *UploadError) Unwrap() error { return e.Kind }
+
+// typeNames are how accepted content types are listed in error messages
+var typeNames = map[string]string{
+	"application/pdf": "PDF",
+	"image/jpeg":      "JPEG",
+	"image/png":       "PNG",
+	"image/webp":      "WebP",
+	"image/gif":       "GIF",
+}
+
+// imagesAndPDF are the types accepted for photos and attachments
+var imagesAndPDF = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// UploadPolicy is what one upload feature accepts. Content types are sniffed
+// from the file itself rather than trusting the client.
+type UploadPolicy struct {
+	Noun     string            // what's uploaded, for messages: "Documents"
+	MaxBytes int64             // largest file accepted
+	Types    map[string]string // accepted content types and the extension each is stored with
+}
+
+// Policies for the upload features
+var (
+	Documents           = UploadPolicy{Noun: "Documents", MaxBytes: 20 << 20, Types: map[string]string{"application/pdf": ".pdf"}}
+	SessionAttachments  = UploadPolicy{Noun: "Attachments", MaxBytes: 20 << 20, Types: imagesAndPDF}
+	IncidentAttachments = UploadPolicy{Noun: "Attachments", MaxBytes: 10 << 20, Types: imagesAndPDF}
+	Avatars             = UploadPolicy{Noun: "Photos", MaxBytes: 10 << 20, Types: map[string]string{"image/jpeg": ".jpg", "image/png": ".png"}}
+)
+
+// RequestLimit is the most a multipart request carrying one file under the
+// policy should need, for http.MaxBytesReader: the file plus 1 MB of form
+func (p UploadPolicy) RequestLimit() int64 {
+	return p.MaxBytes + 1<<20
+}
+
+// CheckSize refuses a declared size over the limit
+func (p UploadPolicy) CheckSize(size int64) error {
+	if size > p.MaxBytes {
+		return &UploadError{Kind: ErrTooLarge, Message: fmt.Sprintf("%s must be %d MB or smaller", p.Noun, p.MaxBytes>>20)}
+	}
+	return nil
+}
+
+// Upload is a file a policy accepted
+type Upload struct {
+	ContentType string
+	Extension   string    // including the dot
+	Body        io.Reader // the whole file, failing with ErrTooLarge if it runs past the limit
+}
+
+// Check sniffs r's content type and checks it and the declared size against
+// the policy. The returned body still starts at the beginning of the file.
+func (p UploadPolicy) Check(size int64, r io.Reader) (*Upload, error) {
+	if err := p.CheckSize(size); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(512)
+	contentType := http.DetectContentType(head)
+	ext, ok := p.Types[contentType]
+	if !ok {
+		return nil, &UploadError{Kind: ErrUnsupportedType, Message: fmt.Sprintf("%s must be %s files", strings.ToLower(p.Noun), p.typeList())}
+	}
+	return &Upload{ContentType: contentType, Extension: ext, Body: &limitedReader{r: br, remaining: p.MaxBytes, policy: p}}, nil
+}
+
+// typeList names the accepted types: "PDF, JPEG or PNG"
+func (p UploadPolicy) typeList() string {
+	names := make([]string, 0, len(p.Types))
+	for t := range p.Types {
+		name, ok := typeNames[t]
+		if !ok {
+			name = t
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+// limitedReader fails rather than truncating once more than the policy's
+// limit has been read, so an understated size can't slip a large file through
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	policy    UploadPolicy
+}
+
+func (l *limitedReader) Read(b []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.policy.CheckSize(l.policy.MaxBytes + 1)
+	}
+	if int64(len(b)) > l.remaining+1 {
+		b = b[:l.remaining+1]
+	}
+	n, err := l.r.Read(b)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, l.policy.CheckSize(l.policy.MaxBytes + 1)
+	}
+	return n, err
+}
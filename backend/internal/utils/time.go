@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -78,3 +79,83 @@ func EndOfDay(t time.Time) time.Time {
 		SydneyLocation,
 	)
 }
+
+// StartOfWeek returns midnight on the Monday of t's week in Sydney timezone
+func StartOfWeek(t time.Time) time.Time {
+	day := StartOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+	return day.AddDate(0, 0, -offset)
+}
+
+// Clock is a time of day on Sydney clocks
+type Clock struct {
+	Hour   int
+	Minute int
+}
+
+// ParseClock parses a time of day like "18:30"
+func ParseClock(s string) (Clock, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return Clock{}, fmt.Errorf("invalid time %q, use HH:MM", s)
+	}
+	return Clock{Hour: t.Hour(), Minute: t.Minute()}, nil
+}
+
+// ClockOf returns the time of day t shows on Sydney clocks
+func ClockOf(t time.Time) Clock {
+	s := t.In(SydneyLocation)
+	return Clock{Hour: s.Hour(), Minute: s.Minute()}
+}
+
+// On returns the instant the clock shows on date's Sydney calendar day.
+// Dates read from date columns (midnight UTC) fall on the right day too.
+func (c Clock) On(date time.Time) time.Time {
+	day := date.In(SydneyLocation)
+	return WallClock(day.Year(), day.Month(), day.Day(), c.Hour, c.Minute, 0)
+}
+
+// String formats the clock as HH:MM
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", c.Hour, c.Minute)
+}
+
+// FormatClock formats t's time of day in Sydney as HH:MM, the form session
+// times take in the API
+func FormatClock(t time.Time) string {
+	return ClockOf(t).String()
+}
+
+// AddWallClock moves t by hours as shown on Sydney clocks rather than by
+// elapsed time, so 24 hours before a 6pm session is 6pm the day before even
+// when daylight saving starts or ends in between
+func AddWallClock(t time.Time, hours int) time.Time {
+	s := t.In(SydneyLocation)
+	return WallClock(s.Year(), s.Month(), s.Day(), s.Hour()+hours, s.Minute(), s.Second())
+}
+
+// WallClock returns the instant Sydney clocks show the given date and time;
+// out-of-range values are normalized as time.Date does. A time skipped when
+// daylight saving starts is read as the clocks would have shown it without
+// the jump (02:30 becomes 03:30), and a time repeated when it ends is taken
+// at its first occurrence.
+func WallClock(year int, month time.Month, day, hour, min, sec int) time.Time {
+	want := time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	t := time.Date(want.Year(), want.Month(), want.Day(), want.Hour(), want.Minute(), want.Second(), 0, SydneyLocation)
+	if sameWallClock(t, want) {
+		if earlier := t.Add(-time.Hour); sameWallClock(earlier, want) {
+			return earlier
+		}
+		return t
+	}
+
+	// Skipped: count on from midnight, which Sydney's transitions never skip
+	midnight := time.Date(want.Year(), want.Month(), want.Day(), 0, 0, 0, 0, SydneyLocation)
+	return midnight.Add(want.Sub(time.Date(want.Year(), want.Month(), want.Day(), 0, 0, 0, 0, time.UTC)))
+}
+
+// sameWallClock reports whether t reads the same as want on Sydney clocks
+func sameWallClock(t, want time.Time) bool {
+	s := t.In(SydneyLocation)
+	return s.Day() == want.Day() && s.Hour() == want.Hour() && s.Minute() == want.Minute() && s.Second() == want.Second()
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// Sydney's 2025 transitions: clocks went back from 03:00 to 02:00 on Sunday
+// 6 April, and forward from 02:00 to 03:00 on Sunday 5 October.
+
+func TestWallClock(t *testing.T) {
+	tests := []struct {
+		name              string
+		year              int
+		month             time.Month
+		day, hour, minute int
+		want              string // UTC
+	}{
+		{"day before DST ends", 2025, time.April, 5, 18, 0, "2025-04-05T07:00:00Z"},
+		{"evening DST ends", 2025, time.April, 6, 18, 0, "2025-04-06T08:00:00Z"},
+		{"repeated hour takes the first", 2025, time.April, 6, 2, 30, "2025-04-05T15:30:00Z"},
+		{"hour after the repeat", 2025, time.April, 6, 3, 0, "2025-04-05T17:00:00Z"},
+		{"day before DST starts", 2025, time.October, 4, 18, 0, "2025-10-04T08:00:00Z"},
+		{"evening DST starts", 2025, time.October, 5, 18, 0, "2025-10-05T07:00:00Z"},
+		{"skipped hour reads on", 2025, time.October, 5, 2, 30, "2025-10-04T16:30:00Z"},
+		{"midnight DST starts", 2025, time.October, 5, 0, 0, "2025-10-04T14:00:00Z"},
+		{"hour 24 normalizes", 2025, time.October, 4, 24, 0, "2025-10-04T14:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WallClock(tt.year, tt.month, tt.day, tt.hour, tt.minute, 0)
+			if got.UTC().Format(time.RFC3339) != tt.want {
+				t.Errorf("WallClock = %s, want %s", got.UTC().Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+func TestAddWallClock(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    time.Time
+		hours   int
+		want    time.Time
+		elapsed time.Duration
+	}{
+		{
+			name:    "24h before a session the evening DST ends",
+			from:    WallClock(2025, time.April, 6, 18, 0, 0),
+			hours:   -24,
+			want:    WallClock(2025, time.April, 5, 18, 0, 0),
+			elapsed: 25 * time.Hour,
+		},
+		{
+			name:    "12h before a session the morning DST ends",
+			from:    WallClock(2025, time.April, 6, 10, 0, 0),
+			hours:   -12,
+			want:    WallClock(2025, time.April, 5, 22, 0, 0),
+			elapsed: 13 * time.Hour,
+		},
+		{
+			name:    "24h before a session the evening DST starts",
+			from:    WallClock(2025, time.October, 5, 18, 0, 0),
+			hours:   -24,
+			want:    WallClock(2025, time.October, 4, 18, 0, 0),
+			elapsed: 23 * time.Hour,
+		},
+		{
+			name:    "12h before a session the morning DST starts",
+			from:    WallClock(2025, time.October, 5, 10, 0, 0),
+			hours:   -12,
+			want:    WallClock(2025, time.October, 4, 22, 0, 0),
+			elapsed: 11 * time.Hour,
+		},
+		{
+			name:    "24h after the evening before DST ends",
+			from:    WallClock(2025, time.April, 5, 18, 0, 0),
+			hours:   24,
+			want:    WallClock(2025, time.April, 6, 18, 0, 0),
+			elapsed: 25 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AddWallClock(tt.from, tt.hours)
+			if !got.Equal(tt.want) {
+				t.Errorf("AddWallClock = %s, want %s", got, tt.want)
+			}
+			if elapsed := tt.from.Sub(got); elapsed != tt.elapsed && -elapsed != tt.elapsed {
+				t.Errorf("elapsed = %s, want %s", elapsed, tt.elapsed)
+			}
+		})
+	}
+}
+
+func TestClockOn(t *testing.T) {
+	// Session dates come from date columns, read as midnight UTC
+	dateColumn := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		clock Clock
+		date  time.Time
+		want  string // Sydney
+	}{
+		{"evening DST ends", Clock{18, 30}, dateColumn(2025, time.April, 6), "2025-04-06T18:30:00+10:00"},
+		{"morning DST ends", Clock{9, 0}, dateColumn(2025, time.April, 6), "2025-04-06T09:00:00+10:00"},
+		{"evening before DST ends", Clock{18, 30}, dateColumn(2025, time.April, 5), "2025-04-05T18:30:00+11:00"},
+		{"evening DST starts", Clock{18, 30}, dateColumn(2025, time.October, 5), "2025-10-05T18:30:00+11:00"},
+		{"evening before DST starts", Clock{18, 30}, dateColumn(2025, time.October, 4), "2025-10-04T18:30:00+10:00"},
+		{"skipped hour DST starts", Clock{2, 30}, dateColumn(2025, time.October, 5), "2025-10-05T03:30:00+11:00"},
+		{"Sydney date read in Sydney", Clock{18, 30}, WallClock(2025, time.October, 5, 0, 0, 0), "2025-10-05T18:30:00+11:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.clock.On(tt.date).In(SydneyLocation).Format(time.RFC3339)
+			if got != tt.want {
+				t.Errorf("Clock.On = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,15 +6,29 @@ importScripts('https://www.gstatic.com/firebasejs/10.7.0/firebase-messaging-comp
 
 // Firebase config will be injected at runtime via message from main app
 let firebaseConfig = null;
+// Base URL of the API, for notification buttons that call it directly
+let apiUrl = '/api';
 
 // Listen for config message from main app
 self.addEventListener('message', (event) => {
   if (event.data && event.data.type === 'FIREBASE_CONFIG') {
     firebaseConfig = event.data.config;
+    if (event.data.apiUrl) {
+      apiUrl = event.data.apiUrl;
+    }
     initializeFirebase();
   }
 });
 
+// Buttons offered by a notification, sent as JSON in its data
+function notificationActions(data) {
+  try {
+    return data?.actions ? JSON.parse(data.actions) : [];
+  } catch {
+    return [];
+  }
+}
+
 function initializeFirebase() {
   if (!firebaseConfig) {
     console.log('Firebase config not available yet');
@@ -36,6 +50,7 @@ function initializeFirebase() {
         badge: '/icons/icon-192x192.svg',
         data: payload.data,
         tag: payload.data?.type || 'default',
+        actions: notificationActions(payload.data),
         requireInteraction: true
       };
 
@@ -54,6 +69,30 @@ self.addEventListener('notificationclick', (event) => {
   event.notification.close();
 
   const data = event.notification.data;
+
+  // Approve or decline a weather cancellation proposal without opening the
+  // app; the link is signed for this admin
+  const actionPath = event.action && data?.[`${event.action}_path`];
+  if (actionPath) {
+    event.waitUntil(
+      fetch(`${apiUrl}${actionPath}`, { method: 'POST' }).then((response) =>
+        response.json().then((body) =>
+          self.registration.showNotification(
+            response.ok ? 'Done' : 'Not done',
+            {
+              body: response.ok
+                ? (event.action === 'approve' ? 'The session has been cancelled.' : 'The session stays on.')
+                : body.error || 'Open the app to decide.',
+              icon: '/icons/icon-192x192.svg',
+              tag: data.type
+            }
+          )
+        )
+      )
+    );
+    return;
+  }
+
   let url = '/dashboard';
 
   // Navigate to specific page based on notification type
@@ -9,6 +9,7 @@ import Profile from './pages/Profile';
 import Admin from './pages/Admin';
 import AdminSessions from './pages/AdminSessions';
 import PendingApproval from './pages/PendingApproval';
+import SharedSession from './pages/SharedSession';
 import Loading from './components/ui/Loading';
 
 function ProtectedRoute({ children, requireApproved = true, requireAdmin = false }: {
@@ -48,6 +49,10 @@ function AppRoutes() {
     <Routes>
       <Route path="/" element={isAuthenticated ? <Navigate to={isApproved ? "/dashboard" : "/pending"} replace /> : <Home />} />
 
+      <Route path="/share/:token" element={<SharedSession />} />
+      {/* Served by the backend when hosting rewrites it; here in case it doesn't */}
+      <Route path="/share/sessions/:token" element={<SharedSession />} />
+
       <Route path="/pending" element={
         <ProtectedRoute requireApproved={false}>
           <PendingApproval />
@@ -61,14 +66,15 @@ function AppRoutes() {
           </ProtectedRoute>
         } />
 
+        {/* Readable while membership is pending, without member names */}
         <Route path="/sessions" element={
-          <ProtectedRoute>
+          <ProtectedRoute requireApproved={false}>
             <Sessions />
           </ProtectedRoute>
         } />
 
         <Route path="/sessions/:id" element={
-          <ProtectedRoute>
+          <ProtectedRoute requireApproved={false}>
             <SessionDetail />
           </ProtectedRoute>
         } />
@@ -29,7 +29,7 @@ export default function Header() {
           )}
 
           <Link to="/profile" className="flex items-center gap-2">
-            <Avatar src={user?.profile_picture} name={user?.name || ''} size="sm" />
+            <Avatar src={user?.avatar_url} name={user?.name || ''} size="sm" />
             <span className="text-sm font-medium text-slate-700 hidden sm:block">
               {user?.name}
             </span>
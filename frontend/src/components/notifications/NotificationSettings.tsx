@@ -1,6 +1,12 @@
 import { useState, useEffect } from 'react';
 import { Bell, Mail, Loader2, BellOff, BellRing, Smartphone } from 'lucide-react';
-import { notificationService, NotificationPreferences } from '../../services/notifications';
+import {
+  notificationService,
+  NotificationPreferences,
+  NotificationPreferencesUpdate
+} from '../../services/notifications';
+import type { NotificationChannel } from '../../services/api';
+import type { CommentNotificationLevel } from '../../types';
 
 interface ToggleSwitchProps {
   enabled: boolean;
@@ -111,7 +117,7 @@ export default function NotificationSettings() {
       const success = await notificationService.enablePushNotifications();
       if (success) {
         setPushPermission('granted');
-        await updatePreference('push_enabled', true);
+        await updatePreference({ push_enabled: true });
         setMessage({ type: 'success', text: 'Push notifications enabled!' });
       } else {
         setMessage({ type: 'error', text: 'Failed to enable push notifications. Please check your browser settings.' });
@@ -124,12 +130,12 @@ export default function NotificationSettings() {
     }
   };
 
-  const updatePreference = async (key: keyof NotificationPreferences, value: boolean) => {
+  const updatePreference = async (updates: NotificationPreferencesUpdate) => {
     if (!preferences) return;
 
     setIsSaving(true);
     try {
-      const updated = await notificationService.updatePreferences({ [key]: value });
+      const updated = await notificationService.updatePreferences(updates);
       setPreferences(updated);
     } catch (error) {
       console.error('Failed to update preference:', error);
@@ -139,6 +145,12 @@ export default function NotificationSettings() {
     }
   };
 
+  const updateTypePreference = (type: string, channel: NotificationChannel, enabled: boolean) =>
+    updatePreference({ types: { [type]: { [channel]: enabled } } });
+
+  const typeEnabled = (type: string, channel: NotificationChannel) =>
+    preferences?.types[type]?.[channel] ?? false;
+
   if (isLoading) {
     return (
       <div className="flex items-center justify-center py-8">
@@ -199,7 +211,7 @@ export default function NotificationSettings() {
             </div>
             <ToggleSwitch
               enabled={preferences?.push_enabled ?? false}
-              onChange={(enabled) => updatePreference('push_enabled', enabled)}
+              onChange={(enabled) => updatePreference({ push_enabled: enabled })}
               disabled={isSaving}
             />
           </div>
@@ -213,43 +225,59 @@ export default function NotificationSettings() {
             <NotificationRow
               label="Session Reminders"
               description="Get reminded before sessions you've RSVP'd to"
-              pushEnabled={preferences.push_session_reminders}
-              emailEnabled={preferences.email_session_reminders}
-              onPushChange={(enabled) => updatePreference('push_session_reminders', enabled)}
-              onEmailChange={(enabled) => updatePreference('email_session_reminders', enabled)}
+              pushEnabled={typeEnabled('session_reminder', 'push')}
+              emailEnabled={typeEnabled('session_reminder', 'email')}
+              onPushChange={(enabled) => updateTypePreference('session_reminder', 'push', enabled)}
+              onEmailChange={(enabled) => updateTypePreference('session_reminder', 'email', enabled)}
               pushDisabled={isSaving || !pushGlobalEnabled}
               emailDisabled={isSaving || !emailGlobalEnabled}
             />
             <NotificationRow
               label="RSVP Deadlines"
               description="Get alerted when RSVP deadlines are approaching"
-              pushEnabled={preferences.push_rsvp_deadlines}
-              emailEnabled={preferences.email_rsvp_deadlines}
-              onPushChange={(enabled) => updatePreference('push_rsvp_deadlines', enabled)}
-              onEmailChange={(enabled) => updatePreference('email_rsvp_deadlines', enabled)}
+              pushEnabled={typeEnabled('rsvp_deadline', 'push')}
+              emailEnabled={typeEnabled('rsvp_deadline', 'email')}
+              onPushChange={(enabled) => updateTypePreference('rsvp_deadline', 'push', enabled)}
+              onEmailChange={(enabled) => updateTypePreference('rsvp_deadline', 'email', enabled)}
               pushDisabled={isSaving || !pushGlobalEnabled}
               emailDisabled={isSaving || !emailGlobalEnabled}
             />
             <NotificationRow
               label="Waitlist Updates"
               description="Get notified when spots open up"
-              pushEnabled={preferences.push_waitlist_updates}
-              emailEnabled={preferences.email_waitlist_updates}
-              onPushChange={(enabled) => updatePreference('push_waitlist_updates', enabled)}
-              onEmailChange={(enabled) => updatePreference('email_waitlist_updates', enabled)}
+              pushEnabled={typeEnabled('waitlist_update', 'push')}
+              emailEnabled={typeEnabled('waitlist_update', 'email')}
+              onPushChange={(enabled) => updateTypePreference('waitlist_update', 'push', enabled)}
+              onEmailChange={(enabled) => updateTypePreference('waitlist_update', 'email', enabled)}
               pushDisabled={isSaving || !pushGlobalEnabled}
               emailDisabled={isSaving || !emailGlobalEnabled}
             />
             <NotificationRow
               label="Club Announcements"
               description="Receive important updates from club admins"
-              pushEnabled={preferences.push_admin_announcements}
-              emailEnabled={preferences.email_admin_announcements}
-              onPushChange={(enabled) => updatePreference('push_admin_announcements', enabled)}
-              onEmailChange={(enabled) => updatePreference('email_admin_announcements', enabled)}
+              pushEnabled={typeEnabled('admin_announcement', 'push')}
+              emailEnabled={typeEnabled('admin_announcement', 'email')}
+              onPushChange={(enabled) => updateTypePreference('admin_announcement', 'push', enabled)}
+              onEmailChange={(enabled) => updateTypePreference('admin_announcement', 'email', enabled)}
               pushDisabled={isSaving || !pushGlobalEnabled}
               emailDisabled={isSaving || !emailGlobalEnabled}
             />
+            <div className="flex items-center justify-between py-4">
+              <div className="flex-1 min-w-0 pr-4">
+                <p className="text-sm font-medium text-slate-900">Session Comments</p>
+                <p className="text-xs text-slate-500">Which comments on sessions you're playing in notify you</p>
+              </div>
+              <select
+                value={preferences.comment_notifications}
+                onChange={(e) => updatePreference({ comment_notifications: e.target.value as CommentNotificationLevel })}
+                disabled={isSaving || !pushGlobalEnabled}
+                className="text-sm border border-slate-200 rounded-lg px-2 py-1"
+              >
+                <option value="all">All comments</option>
+                <option value="mentions">Only @mentions</option>
+                <option value="none">None</option>
+              </select>
+            </div>
           </div>
         )}
       </div>
@@ -269,7 +297,7 @@ export default function NotificationSettings() {
             </div>
             <ToggleSwitch
               enabled={preferences.email_enabled}
-              onChange={(enabled) => updatePreference('email_enabled', enabled)}
+              onChange={(enabled) => updatePreference({ email_enabled: enabled })}
               disabled={isSaving}
             />
           </div>
@@ -277,19 +305,19 @@ export default function NotificationSettings() {
           {preferences.email_enabled && (
             <div className="space-y-3">
               {[
-                { key: 'email_session_reminders' as const, label: 'Session Reminders', desc: 'Get reminded before sessions' },
-                { key: 'email_rsvp_deadlines' as const, label: 'RSVP Deadlines', desc: 'Get deadline alerts' },
-                { key: 'email_waitlist_updates' as const, label: 'Waitlist Updates', desc: 'Get notified when spots open' },
-                { key: 'email_admin_announcements' as const, label: 'Club Announcements', desc: 'Receive club updates' }
-              ].map(({ key, label, desc }) => (
-                <div key={key} className="flex items-center justify-between py-2">
+                { type: 'session_reminder', label: 'Session Reminders', desc: 'Get reminded before sessions' },
+                { type: 'rsvp_deadline', label: 'RSVP Deadlines', desc: 'Get deadline alerts' },
+                { type: 'waitlist_update', label: 'Waitlist Updates', desc: 'Get notified when spots open' },
+                { type: 'admin_announcement', label: 'Club Announcements', desc: 'Receive club updates' }
+              ].map(({ type, label, desc }) => (
+                <div key={type} className="flex items-center justify-between py-2">
                   <div>
                     <p className="text-sm font-medium text-slate-700">{label}</p>
                     <p className="text-xs text-slate-500">{desc}</p>
                   </div>
                   <ToggleSwitch
-                    enabled={preferences[key]}
-                    onChange={(enabled) => updatePreference(key, enabled)}
+                    enabled={typeEnabled(type, 'email')}
+                    onChange={(enabled) => updateTypePreference(type, 'email', enabled)}
                     disabled={isSaving}
                   />
                 </div>
@@ -104,7 +104,7 @@ function PlayerItem({ rsvp, position, isOverCapacity, variant = 'confirmed' }: P
         </span>
       )}
 
-      <Avatar src={user.profile_picture} name={user.name} size="sm" />
+      <Avatar src={user.avatar_url} name={user.name} size="sm" />
 
       <div className="flex-1 min-w-0">
         <p className="font-medium text-slate-900 truncate">{user.name}</p>
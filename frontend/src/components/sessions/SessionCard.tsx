@@ -132,7 +132,7 @@ export default function SessionCard({ session, venueName }: SessionCardProps) {
               </div>
               <div className="flex flex-wrap gap-2">
                 {confirmedRsvps.map((rsvp) => (
-                  <PlayerChip key={rsvp.id} name={rsvp.user?.name || ''} picture={rsvp.user?.profile_picture} variant="confirmed" />
+                  <PlayerChip key={rsvp.id} name={rsvp.user?.name || ''} picture={rsvp.user?.avatar_url} variant="confirmed" />
                 ))}
               </div>
             </div>
@@ -147,7 +147,7 @@ export default function SessionCard({ session, venueName }: SessionCardProps) {
               </div>
               <div className="flex flex-wrap gap-2">
                 {maybeRsvps.map((rsvp) => (
-                  <PlayerChip key={rsvp.id} name={rsvp.user?.name || ''} picture={rsvp.user?.profile_picture} variant="maybe" />
+                  <PlayerChip key={rsvp.id} name={rsvp.user?.name || ''} picture={rsvp.user?.avatar_url} variant="maybe" />
                 ))}
               </div>
             </div>
@@ -162,7 +162,7 @@ export default function SessionCard({ session, venueName }: SessionCardProps) {
               </div>
               <div className="flex flex-wrap gap-2">
                 {declinedRsvps.map((rsvp) => (
-                  <PlayerChip key={rsvp.id} name={rsvp.user?.name || ''} picture={rsvp.user?.profile_picture} variant="declined" />
+                  <PlayerChip key={rsvp.id} name={rsvp.user?.name || ''} picture={rsvp.user?.avatar_url} variant="declined" />
                 ))}
               </div>
             </div>
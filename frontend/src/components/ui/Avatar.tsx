@@ -1,4 +1,5 @@
 import { User } from 'lucide-react';
+import { apiAssetUrl } from '../../services/api';
 
 interface AvatarProps {
   src?: string;
@@ -21,11 +22,15 @@ export default function Avatar({ src, name, size = 'md' }: AvatarProps) {
     .slice(0, 2);
 
   if (src) {
-    // Add size parameter for Google profile pictures to reduce rate limiting
+    // Add size parameter for Google profile pictures to reduce rate limiting,
+    // and take the small size of uploaded photos for small avatars
     const imageSizes = { sm: 64, md: 80, lg: 128 };
-    const optimizedSrc = src.includes('googleusercontent.com')
+    let optimizedSrc = src.includes('googleusercontent.com')
       ? `${src.split('=')[0]}=s${imageSizes[size]}`
-      : src;
+      : apiAssetUrl(src);
+    if (size === 'sm' && src.startsWith('/api/avatars/')) {
+      optimizedSrc = optimizedSrc.replace(/\/md$/, '/sm');
+    }
 
     return (
       <img
@@ -40,13 +40,8 @@ export function AuthProvider({ children }: { children: ReactNode }) {
       const token = await getAccessTokenSilently();
       api.setAccessToken(token);
 
-      // Sync user with backend
-      const response = await api.authCallback(
-        auth0User.sub || '',
-        auth0User.email || '',
-        auth0User.name || '',
-        auth0User.picture || ''
-      );
+      // Sync user with backend (identity is taken from the access token)
+      const response = await api.authCallback();
 
       setUser(response.user);
     } catch (error) {
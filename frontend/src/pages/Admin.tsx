@@ -16,7 +16,7 @@ export default function Admin() {
   const [clubMessage, setClubMessage] = useState<{ type: 'success' | 'error'; text: string } | null>(null);
 
   // Announcement state
-  const [announcementForm, setAnnouncementForm] = useState({ title: '', body: '' });
+  const [announcementForm, setAnnouncementForm] = useState({ title: '', body: '', requiresAck: false });
   const [isSendingAnnouncement, setIsSendingAnnouncement] = useState(false);
   const [announcementMessage, setAnnouncementMessage] = useState<{ type: 'success' | 'error'; text: string } | null>(null);
 
@@ -90,9 +90,26 @@ export default function Admin() {
     setIsSendingAnnouncement(true);
     setAnnouncementMessage(null);
     try {
-      await api.sendAnnouncement(announcementForm.title, announcementForm.body);
-      setAnnouncementMessage({ type: 'success', text: 'Announcement sent to all members!' });
-      setAnnouncementForm({ title: '', body: '' });
+      let sent;
+      try {
+        sent = await api.sendAnnouncement(announcementForm.title, announcementForm.body, false, announcementForm.requiresAck);
+      } catch (error) {
+        // Over the announcement limit: send anyway only if the admin confirms
+        if ((error as { response?: { status?: number } }).response?.status !== 429) throw error;
+        if (!confirm('The announcement limit for this period has been reached. Send anyway?')) {
+          setAnnouncementMessage({ type: 'error', text: 'Announcement limit reached; not sent' });
+          return;
+        }
+        sent = await api.sendAnnouncement(announcementForm.title, announcementForm.body, true, announcementForm.requiresAck);
+      }
+      const { quota } = sent;
+      setAnnouncementMessage({
+        type: 'success',
+        text: quota.limit > 0
+          ? `Announcement sent to all members! ${quota.remaining} of ${quota.limit} left for this period.`
+          : 'Announcement sent to all members!'
+      });
+      setAnnouncementForm({ title: '', body: '', requiresAck: false });
     } catch (error) {
       console.error('Failed to send announcement:', error);
       setAnnouncementMessage({ type: 'error', text: 'Failed to send announcement' });
@@ -240,6 +257,16 @@ export default function Admin() {
             />
           </div>
 
+          <label className="flex items-center gap-2 text-sm text-slate-700">
+            <input
+              type="checkbox"
+              checked={announcementForm.requiresAck}
+              onChange={(e) => setAnnouncementForm({ ...announcementForm, requiresAck: e.target.checked })}
+              className="rounded border-slate-300 text-primary-600 focus:ring-primary-500"
+            />
+            Ask members to confirm they've read it
+          </label>
+
           {announcementMessage && (
             <div className={`p-3 rounded-lg text-sm ${
               announcementMessage.type === 'success' ? 'bg-green-50 text-green-700' : 'bg-red-50 text-red-700'
@@ -287,7 +314,7 @@ export default function Admin() {
                 className="flex items-center justify-between p-4 bg-slate-50 rounded-lg"
               >
                 <div className="flex items-center gap-3">
-                  <Avatar src={user.profile_picture} name={user.name} />
+                  <Avatar src={user.avatar_url} name={user.name} />
                   <div>
                     <p className="font-medium text-slate-900">{user.name}</p>
                     <p className="text-sm text-slate-500">{user.email}</p>
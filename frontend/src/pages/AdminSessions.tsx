@@ -3,12 +3,14 @@ import { useNavigate } from 'react-router-dom';
 import { ArrowLeft, Plus, Calendar, Trash2, Loader2, XCircle, X } from 'lucide-react';
 import { format, parseISO } from 'date-fns';
 import { api } from '../services/api';
-import type { Session, CreateSessionInput } from '../types';
+import { MAX_COURTS, maxPlayersForCourts } from '../types';
+import type { Club, Session, CreateSessionInput } from '../types';
 import Badge from '../components/ui/Badge';
 
 export default function AdminSessions() {
   const navigate = useNavigate();
   const [sessions, setSessions] = useState<Session[]>([]);
+  const [club, setClub] = useState<Club | null>(null);
   const [isLoading, setIsLoading] = useState(true);
   const [showForm, setShowForm] = useState(false);
   const [isSubmitting, setIsSubmitting] = useState(false);
@@ -39,6 +41,7 @@ export default function AdminSessions() {
 
   useEffect(() => {
     loadSessions();
+    api.getClub().then(setClub).catch(() => setClub(null));
   }, []);
 
   const loadSessions = async () => {
@@ -223,15 +226,17 @@ export default function AdminSessions() {
 
             <div>
               <label className="block text-sm font-medium text-slate-700 mb-1">Courts *</label>
-              <select
+              <input
+                type="number"
+                min={1}
+                max={MAX_COURTS}
                 value={formData.courts}
-                onChange={(e) => setFormData({ ...formData, courts: parseInt(e.target.value) })}
+                onChange={(e) => setFormData({ ...formData, courts: parseInt(e.target.value) || 1 })}
                 className="w-full px-4 py-2 rounded-lg border border-slate-300 focus:outline-none focus:ring-2 focus:ring-primary-500"
-              >
-                <option value={1}>1 Court (Max 6 players)</option>
-                <option value={2}>2 Courts (Max 10 players)</option>
-                <option value={3}>3 Courts (Max 16 players)</option>
-              </select>
+              />
+              <p className="text-xs text-slate-500 mt-1">
+                Max {maxPlayersForCourts(formData.courts, club)} players
+              </p>
             </div>
           </div>
 
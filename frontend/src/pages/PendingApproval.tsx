@@ -1,4 +1,5 @@
-import { Clock, LogOut } from 'lucide-react';
+import { Link } from 'react-router-dom';
+import { Calendar, Clock, LogOut } from 'lucide-react';
 import { useAuth } from '../context/AuthContext';
 import Avatar from '../components/ui/Avatar';
 
@@ -22,7 +23,7 @@ export default function PendingApproval() {
 
         {user && (
           <div className="flex items-center gap-3 p-4 bg-slate-50 rounded-lg mb-6">
-            <Avatar src={user.profile_picture} name={user.name} />
+            <Avatar src={user.avatar_url} name={user.name} />
             <div className="text-left">
               <p className="font-medium text-slate-900">{user.name}</p>
               <p className="text-sm text-slate-500">{user.email}</p>
@@ -30,6 +31,14 @@ export default function PendingApproval() {
           </div>
         )}
 
+        <Link
+          to="/sessions"
+          className="w-full flex items-center justify-center gap-2 px-4 py-2 mb-2 rounded-lg bg-primary-600 text-white hover:bg-primary-700 transition-colors"
+        >
+          <Calendar className="w-4 h-4" />
+          Browse the schedule
+        </Link>
+
         <button
           onClick={logout}
           className="w-full flex items-center justify-center gap-2 px-4 py-2 rounded-lg text-slate-600 hover:bg-slate-100 transition-colors"
@@ -1,22 +1,65 @@
 import { useState } from 'react';
-import { User, Mail, Phone, Shield, Save, Loader2, Bell } from 'lucide-react';
+import { User, Mail, Phone, Shield, Save, Loader2, Bell, EyeOff, QrCode, Languages, Camera } from 'lucide-react';
 import { useAuth } from '../context/AuthContext';
 import { api } from '../services/api';
 import Avatar from '../components/ui/Avatar';
 import Badge from '../components/ui/Badge';
 import NotificationSettings from '../components/notifications/NotificationSettings';
+import type { Language, MembershipCard, PrivacySettings } from '../types';
+
+const privacyOptions: { key: keyof PrivacySettings; label: string }[] = [
+  { key: 'hide_from_waitlist', label: 'Hide my name on session waitlists' },
+  { key: 'hide_from_leaderboards', label: 'Hide my name on tournament standings' },
+  { key: 'hide_attendance', label: 'Hide my check-ins and attendance badges' },
+];
+
+const languageOptions: { value: Language; label: string }[] = [
+  { value: 'en', label: 'English' },
+  { value: 'zh', label: '中文' },
+];
 
 export default function Profile() {
   const { user, refreshUser } = useAuth();
   const [phoneNumber, setPhoneNumber] = useState(user?.phone_number || '');
+  const [privacy, setPrivacy] = useState<PrivacySettings>(user?.privacy || {
+    hide_from_waitlist: false,
+    hide_from_leaderboards: false,
+    hide_attendance: false,
+  });
+  const [language, setLanguage] = useState<Language>(user?.preferred_language || 'en');
   const [isSaving, setIsSaving] = useState(false);
   const [message, setMessage] = useState<{ type: 'success' | 'error'; text: string } | null>(null);
+  const [card, setCard] = useState<MembershipCard | null>(null);
+  const [cardError, setCardError] = useState<string | null>(null);
+
+  const handleShowCard = async () => {
+    setCardError(null);
+    try {
+      setCard(await api.getMyCard());
+    } catch (error) {
+      setCardError('Membership card is not available');
+    }
+  };
+
+  const handlePhoto = async (file: File | undefined, remove = false) => {
+    setMessage(null);
+    try {
+      if (remove) {
+        await api.deleteAvatar();
+      } else if (file) {
+        await api.uploadAvatar(file);
+      }
+      await refreshUser();
+    } catch (error) {
+      setMessage({ type: 'error', text: remove ? 'Failed to remove photo' : 'Failed to upload photo; use a JPEG or PNG under 10 MB' });
+    }
+  };
 
   const handleSave = async () => {
     setIsSaving(true);
     setMessage(null);
     try {
-      await api.updateMe(phoneNumber);
+      await api.updateMe({ phone_number: phoneNumber, privacy, preferred_language: language });
       await refreshUser();
       setMessage({ type: 'success', text: 'Profile updated successfully!' });
     } catch (error) {
@@ -42,7 +85,7 @@ export default function Profile() {
 
       <div className="bg-white rounded-xl border border-slate-200 p-6">
         <div className="flex items-center gap-4 mb-6 pb-6 border-b border-slate-200">
-          <Avatar src={user.profile_picture} name={user.name} size="lg" />
+          <Avatar src={user.avatar_url} name={user.name} size="lg" />
           <div>
             <h2 className="text-xl font-semibold text-slate-900">{user.name}</h2>
             <div className="flex items-center gap-2 mt-1">
@@ -54,6 +97,23 @@ export default function Profile() {
                 {user.membership_status.charAt(0).toUpperCase() + user.membership_status.slice(1)}
               </Badge>
             </div>
+            <div className="flex items-center gap-3 mt-2 text-sm">
+              <label className="text-primary-600 hover:text-primary-700 cursor-pointer flex items-center gap-1">
+                <Camera className="w-4 h-4" />
+                {user.avatar_id ? 'Change photo' : 'Upload photo'}
+                <input
+                  type="file"
+                  accept="image/jpeg,image/png"
+                  className="hidden"
+                  onChange={(e) => handlePhoto(e.target.files?.[0])}
+                />
+              </label>
+              {user.avatar_id && (
+                <button onClick={() => handlePhoto(undefined, true)} className="text-slate-500 hover:text-slate-700">
+                  Remove photo
+                </button>
+              )}
+            </div>
           </div>
         </div>
 
@@ -65,7 +125,7 @@ export default function Profile() {
             </label>
             <input
               type="email"
-              value={user.email}
+              value={user.email || ''}
               disabled
               className="w-full px-4 py-2 rounded-lg border border-slate-300 bg-slate-50 text-slate-500"
             />
@@ -86,6 +146,44 @@ export default function Profile() {
             />
           </div>
 
+          <div>
+            <label className="block text-sm font-medium text-slate-700 mb-1">
+              <EyeOff className="w-4 h-4 inline mr-2" />
+              Privacy
+            </label>
+            <div className="space-y-2">
+              {privacyOptions.map(({ key, label }) => (
+                <label key={key} className="flex items-center gap-2 text-sm text-slate-700">
+                  <input
+                    type="checkbox"
+                    checked={privacy[key]}
+                    onChange={(e) => setPrivacy({ ...privacy, [key]: e.target.checked })}
+                    className="rounded border-slate-300 text-primary-600 focus:ring-primary-500"
+                  />
+                  {label}
+                </label>
+              ))}
+            </div>
+            <p className="text-xs text-slate-500 mt-1">Other members see "Hidden member" instead. Admins still see everything.</p>
+          </div>
+
+          <div>
+            <label className="block text-sm font-medium text-slate-700 mb-1">
+              <Languages className="w-4 h-4 inline mr-2" />
+              Notification Language
+            </label>
+            <select
+              value={language}
+              onChange={(e) => setLanguage(e.target.value as Language)}
+              className="w-full px-4 py-2 rounded-lg border border-slate-300 focus:outline-none focus:ring-2 focus:ring-primary-500 focus:border-transparent"
+            >
+              {languageOptions.map(({ value, label }) => (
+                <option key={value} value={value}>{label}</option>
+              ))}
+            </select>
+            <p className="text-xs text-slate-500 mt-1">Reminders and emails are sent in this language where a translation exists, otherwise in English.</p>
+          </div>
+
           {message && (
             <div className={`p-3 rounded-lg text-sm ${
               message.type === 'success' ? 'bg-green-50 text-green-700' : 'bg-red-50 text-red-700'
@@ -109,6 +207,34 @@ export default function Profile() {
         </div>
       </div>
 
+      {/* Membership Card */}
+      {user.membership_status === 'approved' && (
+        <div className="bg-white rounded-xl border border-slate-200 p-6">
+          <h2 className="text-xl font-bold text-slate-900 flex items-center gap-2 mb-4">
+            <QrCode className="w-6 h-6 text-primary-600" />
+            Membership Card
+          </h2>
+          {card ? (
+            <div className="flex flex-col items-center gap-2 text-center">
+              <img src={card.qr_code} alt="Membership card QR code" className="w-56 h-56" />
+              <p className="font-semibold text-slate-900">{card.name}</p>
+              <p className="text-sm text-slate-600">
+                Member since {new Date(card.member_since).toLocaleDateString()} · valid until{' '}
+                {new Date(card.expires_at).toLocaleDateString()}
+              </p>
+            </div>
+          ) : (
+            <button
+              onClick={handleShowCard}
+              className="px-4 py-2 bg-primary-600 text-white rounded-lg hover:bg-primary-700"
+            >
+              Show my card
+            </button>
+          )}
+          {cardError && <p className="mt-2 text-sm text-red-600">{cardError}</p>}
+        </div>
+      )}
+
       {/* Notification Settings */}
       <div>
         <h2 className="text-xl font-bold text-slate-900 flex items-center gap-2 mb-4">
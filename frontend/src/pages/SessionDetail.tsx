@@ -12,7 +12,7 @@ import Badge from '../components/ui/Badge';
 export default function SessionDetail() {
   const { id } = useParams<{ id: string }>();
   const navigate = useNavigate();
-  const { user } = useAuth();
+  const { user, isApproved } = useAuth();
 
   const [session, setSession] = useState<Session | null>(null);
   const [summary, setSummary] = useState<RSVPSummary | null>(null);
@@ -150,7 +150,13 @@ export default function SessionDetail() {
         )}
       </div>
 
-      {!isCancelled && (
+      {!isApproved && (
+        <div className="bg-white rounded-xl border border-slate-200 p-6 text-sm text-slate-600">
+          You can RSVP and see who's playing once your membership is approved.
+        </div>
+      )}
+
+      {isApproved && !isCancelled && (
         <div className="bg-white rounded-xl border border-slate-200 p-6">
           <h2 className="font-semibold text-slate-900 mb-4">Your RSVP</h2>
 
@@ -176,12 +182,14 @@ export default function SessionDetail() {
         </div>
       )}
 
-      <div className="bg-white rounded-xl border border-slate-200 p-6">
-        <PlayerList
-          rsvps={session.rsvps || []}
-          maxPlayers={session.max_players}
-        />
-      </div>
+      {isApproved && (
+        <div className="bg-white rounded-xl border border-slate-200 p-6">
+          <PlayerList
+            rsvps={session.rsvps || []}
+            maxPlayers={session.max_players}
+          />
+        </div>
+      )}
     </div>
   );
 }
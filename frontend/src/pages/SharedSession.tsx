@@ -0,0 +1,90 @@
+import { useEffect, useState } from 'react';
+import { useParams } from 'react-router-dom';
+import { Calendar, Clock, MapPin, Users } from 'lucide-react';
+import { format, parseISO } from 'date-fns';
+import { useAuth } from '../context/AuthContext';
+import { api } from '../services/api';
+import type { SessionPreview } from '../types';
+
+// Public preview of a session, opened from a share link
+export default function SharedSession() {
+  const { token } = useParams<{ token: string }>();
+  const { login } = useAuth();
+  const [session, setSession] = useState<SessionPreview | null>(null);
+  const [error, setError] = useState(false);
+
+  useEffect(() => {
+    if (!token) return;
+    api.getSharedSession(token)
+      .then((shared) => setSession(shared.session))
+      .catch(() => setError(true));
+  }, [token]);
+
+  return (
+    <div className="min-h-screen bg-gradient-to-b from-primary-600 to-primary-800">
+      <div className="max-w-xl mx-auto px-4 py-12 text-white">
+        <div className="text-center mb-8">
+          <div className="w-16 h-16 bg-white/20 rounded-2xl flex items-center justify-center mx-auto mb-4 text-4xl">
+            🏸
+          </div>
+          <h1 className="text-3xl font-bold">{session?.club_name || 'Weekday Masters'}</h1>
+        </div>
+
+        {error && (
+          <p className="text-center text-primary-100 mb-8">This link is invalid or has expired.</p>
+        )}
+
+        {session && (
+          <div className="bg-white/10 backdrop-blur rounded-xl border border-white/20 p-6 mb-8">
+            <h2 className="text-2xl font-semibold mb-2">{session.title}</h2>
+            {session.status === 'cancelled' && (
+              <p className="text-red-200 font-medium mb-2">This session has been cancelled.</p>
+            )}
+            {session.description && (
+              <p className="text-primary-100 mb-4">{session.description}</p>
+            )}
+            <div className="space-y-3 text-sm">
+              <div className="flex items-center gap-3">
+                <Calendar className="w-5 h-5 text-secondary-400" />
+                <span>{format(parseISO(session.session_date), 'EEEE, d MMMM yyyy')}</span>
+              </div>
+              <div className="flex items-center gap-3">
+                <Clock className="w-5 h-5 text-secondary-400" />
+                <span>{session.start_time} – {session.end_time}</span>
+              </div>
+              {session.status === 'open' && (
+                <div className="flex items-center gap-3">
+                  <Users className="w-5 h-5 text-secondary-400" />
+                  <span>
+                    {session.spots_left === 0
+                      ? 'Full – waitlist open'
+                      : `${session.spots_left} of ${session.max_players} spots left`}
+                  </span>
+                </div>
+              )}
+              {session.venue_name && (
+                <div className="flex items-center gap-3">
+                  <MapPin className="w-5 h-5 text-secondary-400" />
+                  <span>
+                    {session.venue_name}
+                    {session.venue_address && <span className="text-primary-200"> · {session.venue_address}</span>}
+                  </span>
+                </div>
+              )}
+            </div>
+          </div>
+        )}
+
+        <div className="text-center">
+          <p className="text-primary-100 mb-4">Want to play? Join the club to RSVP.</p>
+          <button
+            onClick={login}
+            className="bg-white text-primary-700 px-8 py-4 rounded-xl font-semibold text-lg hover:bg-primary-50 transition-colors shadow-lg"
+          >
+            Join the club
+          </button>
+        </div>
+      </div>
+    </div>
+  );
+}
@@ -1,18 +1,104 @@
 import axios, { AxiosInstance } from 'axios';
 import type {
   User,
+  AvatarReport,
+  MembershipTier,
+  CommitteeRole,
+  CommitteeMinutes,
+  ContactLog,
+  ContactLogInput,
+  TimelineEntry,
   Club,
   Session,
   RSVP,
+  Guest,
   SessionWithSummary,
   AuthCallbackResponse,
   CreateSessionInput,
   UpdateSessionInput,
   RSVPStatus,
+  RSVPRequest,
+  AllocationResult,
+  ShareLink,
+  SessionListFilter,
+  PastSessionsFilter,
+  HistoryFilter,
+  SessionHistoryEntry,
+  PlayedSession,
+  RSVPConflict,
+  MergeSessionsResult,
+  SharedSession,
+  ClubDocument,
+  DocumentCategory,
+  UpdateProfileInput,
+  Language,
+  Incident,
+  IncidentAttachment,
+  SessionAttachment,
+  SeriesRegulars,
+  RecurrencePreviewInput,
+  RecurrencePreview,
+  RolloverInput,
+  RolloverPlan,
+  SeasonRollover,
+  IncidentStatus,
+  CreateIncidentInput,
+  PendingAction,
+  PendingActionStatus,
+  JobRun,
+  JobStatus,
+  DomainEvent,
+  JobReport,
+  ManualJob,
+  MembershipCard,
+  ArchiveTableStats,
+  InactiveMember,
+  OrderWindow,
+  Order,
+  OrderLineInput,
+  CreateOrderWindowInput,
+  OrderSummary,
+  TrainingProgress,
+  SkillAssessment,
+  SkillAssessmentInput,
+  TrainingRosterEntry,
+  Trainee,
+  CommentNotificationLevel,
+  CommentNotificationSetting,
+  CarpoolBoard,
+  InventoryItem,
+  InventoryItemInput,
+  InventoryMovement,
+  InventoryCheckout,
+  ConsumptionReport,
+  CancellationProposal,
+  ProposalStatus,
+  Attendance,
+  AttendanceStatus,
+  AttendanceReport,
+  JoinRules,
+  JoinRule,
+  JoinApproval,
+  ReferralCode,
+  PreapprovedMember,
+  Invite,
+  CreateInviteInput,
+  InvitePreview,
+  RSVPTimeline,
+  OccupancyForecast,
+  StatsRecap,
+  UsageReport,
 } from '../types';
 
 const API_URL = import.meta.env.VITE_API_URL || '/api';
 
+// apiAssetUrl resolves a path the API hands out relative to its host, such as
+// a member's avatar_url, against the API; data: and absolute URLs are unchanged
+export function apiAssetUrl(url: string): string {
+  if (!url.startsWith('/api/')) return url;
+  return new URL(url, new URL(API_URL, window.location.href)).toString();
+}
+
 class ApiService {
   private client: AxiosInstance;
   private accessToken: string | null = null;
@@ -38,29 +124,58 @@ class ApiService {
   }
 
   // Auth
-  async authCallback(auth0Id: string, email: string, name: string, profilePicture: string): Promise<AuthCallbackResponse> {
-    const response = await this.client.post<AuthCallbackResponse>('/auth/callback', {
-      auth0_id: auth0Id,
-      email,
-      name,
-      profile_picture: profilePicture,
-    });
+  async authCallback(join?: { invite_token?: string; referral_code?: string }): Promise<AuthCallbackResponse> {
+    const response = await this.client.post<AuthCallbackResponse>('/auth/callback', join);
+    return response.data;
+  }
+
+  async previewInvite(token: string): Promise<InvitePreview> {
+    const response = await this.client.get<InvitePreview>(`/invites/${token}`);
     return response.data;
   }
 
+  async requestAccountLink(email: string): Promise<void> {
+    await this.client.post('/auth/link/request', { email });
+  }
+
+  async confirmAccountLink(email: string, code: string): Promise<User> {
+    const response = await this.client.post<{ user: User }>('/auth/link/confirm', { email, code });
+    return response.data.user;
+  }
+
   // Users
   async getMe(): Promise<User> {
     const response = await this.client.get<User>('/users/me');
     return response.data;
   }
 
-  async updateMe(phoneNumber: string): Promise<User> {
-    const response = await this.client.put<User>('/users/me', { phone_number: phoneNumber });
+  async updateMe(updates: UpdateProfileInput): Promise<User> {
+    const response = await this.client.put<User>('/users/me', updates);
+    return response.data;
+  }
+
+  async getMyCard(): Promise<MembershipCard> {
+    const response = await this.client.get<MembershipCard>('/users/me/card');
+    return response.data;
+  }
+
+  async getStatsRecap(month?: string, week?: string): Promise<StatsRecap> {
+    const response = await this.client.get<StatsRecap>('/stats/recap', { params: { month, week } });
     return response.data;
   }
 
-  async listMembers(): Promise<User[]> {
-    const response = await this.client.get<User[]>('/users');
+  async getMyReferralCode(): Promise<ReferralCode> {
+    const response = await this.client.get<ReferralCode>('/users/me/referral-code');
+    return response.data;
+  }
+
+  async redeemReferralCode(code: string): Promise<void> {
+    await this.client.post('/users/me/referral-code/redeem', { code });
+  }
+
+  // fields, comma-separated, trims each member to those keys (and id)
+  async listMembers(fields?: string): Promise<User[]> {
+    const response = await this.client.get<User[]>('/users', { params: { fields } });
     return response.data;
   }
 
@@ -71,8 +186,30 @@ class ApiService {
   }
 
   // Sessions
-  async listSessions(): Promise<Session[]> {
-    const response = await this.client.get<Session[]>('/sessions');
+  async search(q: string, types?: SearchType[], limit?: number): Promise<SearchResults> {
+    const response = await this.client.get<SearchResults>('/search', {
+      params: { q, type: types?.join(','), limit },
+    });
+    return response.data;
+  }
+
+  async listSessions(filter: SessionListFilter = {}): Promise<Session[]> {
+    const response = await this.client.get<Session[]>('/sessions', { params: filter });
+    return response.data;
+  }
+
+  async listPastSessions(filter: PastSessionsFilter = {}): Promise<Session[]> {
+    const response = await this.client.get<Session[]>('/sessions/past', { params: filter });
+    return response.data;
+  }
+
+  async getSessionHistory(filter: HistoryFilter = {}): Promise<SessionHistoryEntry[]> {
+    const response = await this.client.get<SessionHistoryEntry[]>('/sessions/history', { params: filter });
+    return response.data;
+  }
+
+  async getMySessions(filter: HistoryFilter = {}): Promise<PlayedSession[]> {
+    const response = await this.client.get<PlayedSession[]>('/users/me/sessions', { params: filter });
     return response.data;
   }
 
@@ -86,6 +223,31 @@ class ApiService {
     return response.data;
   }
 
+  async shareSession(id: string): Promise<ShareLink> {
+    const response = await this.client.get<ShareLink>(`/sessions/${id}/share`);
+    return response.data;
+  }
+
+  async getCommentNotifications(sessionId: string): Promise<CommentNotificationSetting> {
+    const response = await this.client.get<CommentNotificationSetting>(`/sessions/${sessionId}/comment-notifications`);
+    return response.data;
+  }
+
+  async setCommentNotifications(sessionId: string, level: CommentNotificationLevel): Promise<CommentNotificationSetting> {
+    const response = await this.client.put<CommentNotificationSetting>(`/sessions/${sessionId}/comment-notifications`, { level });
+    return response.data;
+  }
+
+  async resetCommentNotifications(sessionId: string): Promise<CommentNotificationSetting> {
+    const response = await this.client.delete<CommentNotificationSetting>(`/sessions/${sessionId}/comment-notifications`);
+    return response.data;
+  }
+
+  async getSharedSession(token: string): Promise<SharedSession> {
+    const response = await this.client.get<SharedSession>(`/public/sessions/${token}`);
+    return response.data;
+  }
+
   // RSVPs
   async createRSVP(sessionId: string, status: RSVPStatus): Promise<RSVP> {
     const response = await this.client.post<RSVP>(`/sessions/${sessionId}/rsvp`, { status });
@@ -101,6 +263,36 @@ class ApiService {
     await this.client.delete(`/sessions/${sessionId}/rsvp`);
   }
 
+  async checkIn(sessionId: string): Promise<RSVP> {
+    const response = await this.client.post<RSVP>(`/sessions/${sessionId}/checkin`);
+    return response.data;
+  }
+
+  async confirmWaitlistOffer(sessionId: string): Promise<RSVP> {
+    const response = await this.client.post<RSVP>(`/sessions/${sessionId}/waitlist/confirm`);
+    return response.data;
+  }
+
+  async leaveWaitlist(sessionId: string): Promise<void> {
+    await this.client.post(`/sessions/${sessionId}/waitlist/leave`);
+  }
+
+  async addGuest(sessionId: string, name: string): Promise<Guest> {
+    const response = await this.client.post<Guest>(`/sessions/${sessionId}/guests`, { name });
+    return response.data;
+  }
+
+  async removeGuest(sessionId: string, guestId: string): Promise<void> {
+    await this.client.delete(`/sessions/${sessionId}/guests/${guestId}`);
+  }
+
+  async getMyRSVPs(from?: string, to?: string): Promise<Record<string, RSVP>> {
+    const response = await this.client.get<Record<string, RSVP>>('/rsvps/me', {
+      params: { from, to }
+    });
+    return response.data;
+  }
+
   async getMyRSVP(sessionId: string): Promise<RSVP | null> {
     try {
       const response = await this.client.get<RSVP>(`/sessions/${sessionId}/rsvp/me`);
@@ -110,6 +302,64 @@ class ApiService {
     }
   }
 
+  // Documents
+  async listDocuments(): Promise<ClubDocument[]> {
+    const response = await this.client.get<ClubDocument[]>('/documents');
+    return response.data;
+  }
+
+  async downloadDocument(id: string): Promise<Blob> {
+    const response = await this.client.get(`/documents/${id}/download`, { responseType: 'blob' });
+    return response.data;
+  }
+
+  async downloadSessionAttachment(sessionId: string, attachmentId: string): Promise<Blob> {
+    const response = await this.client.get(`/sessions/${sessionId}/attachments/${attachmentId}`, { responseType: 'blob' });
+    return response.data;
+  }
+
+  async acknowledgeDocument(id: string): Promise<void> {
+    await this.client.post(`/documents/${id}/acknowledge`);
+  }
+
+  // Incidents
+  async reportIncident(sessionId: string, input: CreateIncidentInput): Promise<Incident> {
+    const response = await this.client.post<Incident>(`/sessions/${sessionId}/incidents`, input);
+    return response.data;
+  }
+
+  async getIncident(id: string): Promise<Incident> {
+    const response = await this.client.get<Incident>(`/incidents/${id}`);
+    return response.data;
+  }
+
+  async uploadAvatar(file: File): Promise<User> {
+    const form = new FormData();
+    form.append('file', file);
+    const response = await this.client.post<User>('/users/me/avatar', form, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    });
+    return response.data;
+  }
+
+  async deleteAvatar(): Promise<void> {
+    await this.client.delete('/users/me/avatar');
+  }
+
+  async reportAvatar(userId: string, reason?: string): Promise<AvatarReport> {
+    const response = await this.client.post<AvatarReport>(`/users/${userId}/avatar/report`, { reason });
+    return response.data;
+  }
+
+  async uploadIncidentAttachment(id: string, file: File): Promise<IncidentAttachment> {
+    const form = new FormData();
+    form.append('file', file);
+    const response = await this.client.post<IncidentAttachment>(`/incidents/${id}/attachments`, form, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    });
+    return response.data;
+  }
+
   // Admin - Join Requests
   async listJoinRequests(): Promise<User[]> {
     const response = await this.client.get<User[]>('/admin/join-requests');
@@ -126,12 +376,146 @@ class ApiService {
     return response.data;
   }
 
+  // Admin - Join request auto-approval
+  async getJoinRules(): Promise<JoinRules> {
+    const response = await this.client.get<JoinRules>('/admin/join-rules');
+    return response.data;
+  }
+
+  async updateJoinRules(
+    data: Partial<Pick<JoinRules, 'auto_approve' | 'email_domains' | 'referral_codes' | 'preapproved_list'>>
+  ): Promise<JoinRules> {
+    const response = await this.client.put<JoinRules>('/admin/join-rules', data);
+    return response.data;
+  }
+
+  async listJoinApprovals(params?: { rule?: JoinRule; limit?: number; offset?: number }): Promise<JoinApproval[]> {
+    const response = await this.client.get<JoinApproval[]>('/admin/join-approvals', { params });
+    return response.data;
+  }
+
+  async listReferralCodes(): Promise<ReferralCode[]> {
+    const response = await this.client.get<ReferralCode[]>('/admin/referral-codes');
+    return response.data;
+  }
+
+  async setReferralCodeDisabled(id: string, disabled: boolean): Promise<ReferralCode> {
+    const response = await this.client.post<ReferralCode>(
+      `/admin/referral-codes/${id}/${disabled ? 'disable' : 'enable'}`
+    );
+    return response.data;
+  }
+
+  async listPreapprovedMembers(): Promise<PreapprovedMember[]> {
+    const response = await this.client.get<PreapprovedMember[]>('/admin/preapproved-members');
+    return response.data;
+  }
+
+  async importPreapprovedMembers(
+    members: { email: string; name?: string }[]
+  ): Promise<{ added: number; skipped: number }> {
+    const response = await this.client.post<{ added: number; skipped: number }>('/admin/preapproved-members', {
+      members,
+    });
+    return response.data;
+  }
+
+  async deletePreapprovedMember(id: string): Promise<void> {
+    await this.client.delete(`/admin/preapproved-members/${id}`);
+  }
+
+  // Admin - Invite links
+  async listInvites(): Promise<Invite[]> {
+    const response = await this.client.get<Invite[]>('/admin/invites');
+    return response.data;
+  }
+
+  async getInvite(id: string): Promise<Invite> {
+    const response = await this.client.get<Invite>(`/admin/invites/${id}`);
+    return response.data;
+  }
+
+  async createInvite(data: CreateInviteInput): Promise<Invite> {
+    const response = await this.client.post<Invite>('/admin/invites', data);
+    return response.data;
+  }
+
+  async revokeInvite(id: string): Promise<Invite> {
+    const response = await this.client.post<Invite>(`/admin/invites/${id}/revoke`);
+    return response.data;
+  }
+
   // Admin - User Management
   async updateUserRole(userId: string, role: string): Promise<User> {
     const response = await this.client.put<User>(`/admin/users/${userId}/role`, { role });
     return response.data;
   }
 
+  async updateUserTier(userId: string, tier: MembershipTier): Promise<User> {
+    const response = await this.client.put<User>(`/admin/users/${userId}/tier`, { tier });
+    return response.data;
+  }
+
+  // An empty role takes the member off the committee
+  async updateCommitteeRole(userId: string, committeeRole: CommitteeRole | ''): Promise<User> {
+    const response = await this.client.put<User>(`/admin/users/${userId}/committee-role`, { committee_role: committeeRole });
+    return response.data;
+  }
+
+  async restoreMember(userId: string): Promise<User> {
+    const response = await this.client.post<User>(`/admin/users/${userId}/restore`);
+    return response.data;
+  }
+
+  async getUserTimeline(userId: string): Promise<TimelineEntry[]> {
+    const response = await this.client.get<TimelineEntry[]>(`/admin/users/${userId}/timeline`);
+    return response.data;
+  }
+
+  async logContact(userId: string, input: ContactLogInput): Promise<ContactLog> {
+    const response = await this.client.post<ContactLog>(`/admin/users/${userId}/contacts`, input);
+    return response.data;
+  }
+
+  async updateContact(id: string, input: ContactLogInput): Promise<ContactLog> {
+    const response = await this.client.put<ContactLog>(`/admin/contacts/${id}`, input);
+    return response.data;
+  }
+
+  async deleteContact(id: string): Promise<void> {
+    await this.client.delete(`/admin/contacts/${id}`);
+  }
+
+  async completeFollowUp(id: string): Promise<ContactLog> {
+    const response = await this.client.post<ContactLog>(`/admin/contacts/${id}/follow-up/done`);
+    return response.data;
+  }
+
+  async getFollowUps(mine = false): Promise<ContactLog[]> {
+    const response = await this.client.get<ContactLog[]>('/admin/follow-ups', { params: { mine } });
+    return response.data;
+  }
+
+  async getUsage(days?: number): Promise<UsageReport> {
+    const response = await this.client.get<UsageReport>('/admin/usage', { params: { days } });
+    return response.data;
+  }
+
+  async getInactiveMembers(): Promise<InactiveMember[]> {
+    const response = await this.client.get<InactiveMember[]>('/admin/inactive-members');
+    return response.data;
+  }
+
+  async archiveInactiveMember(userId: string): Promise<User> {
+    const response = await this.client.post<User>(`/admin/inactive-members/${userId}/archive`);
+    return response.data;
+  }
+
+  async keepInactiveMember(userId: string): Promise<User> {
+    const response = await this.client.post<User>(`/admin/inactive-members/${userId}/keep`);
+    return response.data;
+  }
+
   // Admin - Sessions
   async createSession(input: CreateSessionInput): Promise<Session> {
     const response = await this.client.post<Session>('/admin/sessions', input);
@@ -152,12 +536,250 @@ class ApiService {
     return response.data;
   }
 
+  async previewRecurrence(input: RecurrencePreviewInput): Promise<RecurrencePreview> {
+    const response = await this.client.post<RecurrencePreview>('/admin/sessions/preview-recurrence', input);
+    return response.data;
+  }
+
+  // Admin - Season rollovers
+  async previewRollover(input: RolloverInput): Promise<RolloverPlan> {
+    const response = await this.client.post<RolloverPlan>('/admin/season-rollovers/preview', input);
+    return response.data;
+  }
+
+  async rollOverSeason(input: RolloverInput): Promise<{ rollover: SeasonRollover; plan: RolloverPlan }> {
+    const response = await this.client.post<{ rollover: SeasonRollover; plan: RolloverPlan }>('/admin/season-rollovers', input);
+    return response.data;
+  }
+
+  async listRollovers(): Promise<SeasonRollover[]> {
+    const response = await this.client.get<SeasonRollover[]>('/admin/season-rollovers');
+    return response.data;
+  }
+
+  async undoRollover(id: string): Promise<{ deleted: number; kept: Session[] }> {
+    const response = await this.client.post<{ deleted: number; kept: Session[] }>(`/admin/season-rollovers/${id}/undo`);
+    return response.data;
+  }
+
+  async mergeSessions(id: string, otherId: string, rsvpConflict: RSVPConflict = 'latest'): Promise<MergeSessionsResult> {
+    const response = await this.client.post<MergeSessionsResult>(`/admin/sessions/${id}/merge/${otherId}`, {
+      rsvp_conflict: rsvpConflict,
+    });
+    return response.data;
+  }
+
   // Admin - RSVP Management
   async adminAddRSVP(sessionId: string, userId: string, status: RSVPStatus): Promise<RSVP> {
     const response = await this.client.post<RSVP>(`/admin/sessions/${sessionId}/rsvp/${userId}`, { status });
     return response.data;
   }
 
+  async checkInPlayer(sessionId: string, userId: string): Promise<RSVP> {
+    const response = await this.client.post<RSVP>(`/admin/sessions/${sessionId}/check-ins/${userId}`);
+    return response.data;
+  }
+
+  async getSessionAttendance(sessionId: string): Promise<Attendance[]> {
+    const response = await this.client.get<Attendance[]>(`/admin/sessions/${sessionId}/attendance`);
+    return response.data;
+  }
+
+  async recordAttendance(sessionId: string, userId: string, status: AttendanceStatus, note?: string): Promise<Attendance> {
+    const response = await this.client.post<Attendance>(`/admin/sessions/${sessionId}/attendance/${userId}`, { status, note });
+    return response.data;
+  }
+
+  async getWeatherProposals(status?: ProposalStatus): Promise<CancellationProposal[]> {
+    const response = await this.client.get<CancellationProposal[]>('/admin/weather-proposals', { params: { status } });
+    return response.data;
+  }
+
+  async decideWeatherProposal(id: string, decision: 'approve' | 'decline'): Promise<CancellationProposal> {
+    const response = await this.client.post<CancellationProposal>(`/admin/weather-proposals/${id}/${decision}`);
+    return response.data;
+  }
+
+  async getRSVPRequests(sessionId: string): Promise<RSVPRequest[]> {
+    const response = await this.client.get<RSVPRequest[]>(`/admin/sessions/${sessionId}/rsvp-requests`);
+    return response.data;
+  }
+
+  async approveRSVPRequest(sessionId: string, userId: string): Promise<RSVP> {
+    const response = await this.client.post<RSVP>(`/admin/sessions/${sessionId}/rsvp-requests/${userId}/approve`);
+    return response.data;
+  }
+
+  async uploadSessionAttachment(sessionId: string, file: File): Promise<SessionAttachment> {
+    const form = new FormData();
+    form.append('file', file);
+    const response = await this.client.post<SessionAttachment>(`/admin/sessions/${sessionId}/attachments`, form, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    });
+    return response.data;
+  }
+
+  async deleteSessionAttachment(sessionId: string, attachmentId: string): Promise<void> {
+    await this.client.delete(`/admin/sessions/${sessionId}/attachments/${attachmentId}`);
+  }
+
+  async getSeriesRegulars(sessionId: string): Promise<SeriesRegulars> {
+    const response = await this.client.get<SeriesRegulars>(`/admin/sessions/${sessionId}/regulars`);
+    return response.data;
+  }
+
+  async setSeriesRegulars(sessionId: string, userIds: string[]): Promise<SeriesRegulars> {
+    const response = await this.client.put<SeriesRegulars>(`/admin/sessions/${sessionId}/regulars`, {
+      user_ids: userIds,
+    });
+    return response.data;
+  }
+
+  async getRSVPTimeline(sessionId: string): Promise<RSVPTimeline> {
+    const response = await this.client.get<RSVPTimeline>(`/admin/sessions/${sessionId}/rsvp-timeline`);
+    return response.data;
+  }
+
+  async getSessionForecast(sessionId: string): Promise<OccupancyForecast> {
+    const response = await this.client.get<OccupancyForecast>(`/admin/sessions/${sessionId}/forecast`);
+    return response.data;
+  }
+
+  async getAllocation(sessionId: string): Promise<AllocationResult[]> {
+    const response = await this.client.get<AllocationResult[]>(`/admin/sessions/${sessionId}/allocation`);
+    return response.data;
+  }
+
+  async declineRSVPRequest(sessionId: string, userId: string, reason?: string): Promise<RSVP> {
+    const response = await this.client.post<RSVP>(`/admin/sessions/${sessionId}/rsvp-requests/${userId}/decline`, { reason });
+    return response.data;
+  }
+
+  // Admin - Documents
+  async uploadDocument(file: File, title: string, category: DocumentCategory, required: boolean, description = '', committeeOnly = false): Promise<ClubDocument> {
+    const form = new FormData();
+    form.append('file', file);
+    form.append('title', title);
+    form.append('description', description);
+    form.append('category', category);
+    form.append('required', String(required));
+    form.append('committee_only', String(committeeOnly));
+    const response = await this.client.post<ClubDocument>('/admin/documents', form, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    });
+    return response.data;
+  }
+
+  async deleteDocument(id: string): Promise<void> {
+    await this.client.delete(`/admin/documents/${id}`);
+  }
+
+  // Admin - Incidents
+  async listIncidents(status?: IncidentStatus): Promise<Incident[]> {
+    const response = await this.client.get<Incident[]>('/admin/incidents', { params: { status } });
+    return response.data;
+  }
+
+  async getAvatarReports(): Promise<AvatarReport[]> {
+    const response = await this.client.get<AvatarReport[]>('/admin/moderation/avatar-reports');
+    return response.data;
+  }
+
+  async resolveAvatarReport(id: string, action: 'delete' | 'dismiss', note?: string): Promise<AvatarReport> {
+    const response = await this.client.post<AvatarReport>(`/admin/moderation/avatar-reports/${id}/resolve`, { action, note });
+    return response.data;
+  }
+
+  async resolveIncident(id: string, resolutionNotes: string): Promise<Incident> {
+    const response = await this.client.post<Incident>(`/admin/incidents/${id}/resolve`, { resolution_notes: resolutionNotes });
+    return response.data;
+  }
+
+  // Session equipment (admins and the session organizer)
+  async getSessionEquipment(sessionId: string): Promise<{ checkouts: InventoryCheckout[]; items: InventoryItem[] }> {
+    const response = await this.client.get<{ checkouts: InventoryCheckout[]; items: InventoryItem[] }>(
+      `/sessions/${sessionId}/equipment`
+    );
+    return response.data;
+  }
+
+  async checkOutEquipment(sessionId: string, itemId: string, quantity: number): Promise<InventoryCheckout> {
+    const response = await this.client.post<InventoryCheckout>(`/sessions/${sessionId}/equipment`, {
+      item_id: itemId,
+      quantity,
+    });
+    return response.data;
+  }
+
+  async checkInEquipment(sessionId: string, checkoutId: string, returnedQuantity?: number): Promise<InventoryCheckout> {
+    const response = await this.client.post<InventoryCheckout>(
+      `/sessions/${sessionId}/equipment/${checkoutId}/return`,
+      returnedQuantity === undefined ? undefined : { returned_quantity: returnedQuantity }
+    );
+    return response.data;
+  }
+
+  // Admin - Inventory
+  async getInventory(): Promise<InventoryItem[]> {
+    const response = await this.client.get<InventoryItem[]>('/admin/inventory');
+    return response.data;
+  }
+
+  async createInventoryItem(input: InventoryItemInput): Promise<InventoryItem> {
+    const response = await this.client.post<InventoryItem>('/admin/inventory', input);
+    return response.data;
+  }
+
+  async updateInventoryItem(id: string, input: InventoryItemInput): Promise<InventoryItem> {
+    const response = await this.client.put<InventoryItem>(`/admin/inventory/${id}`, input);
+    return response.data;
+  }
+
+  async deleteInventoryItem(id: string): Promise<void> {
+    await this.client.delete(`/admin/inventory/${id}`);
+  }
+
+  async adjustInventoryItem(
+    id: string,
+    change: number,
+    reason: 'restock' | 'adjustment',
+    note = ''
+  ): Promise<InventoryItem> {
+    const response = await this.client.post<InventoryItem>(`/admin/inventory/${id}/adjust`, { change, reason, note });
+    return response.data;
+  }
+
+  async getInventoryMovements(id: string, limit?: number): Promise<InventoryMovement[]> {
+    const response = await this.client.get<InventoryMovement[]>(`/admin/inventory/${id}/movements`, { params: { limit } });
+    return response.data;
+  }
+
+  async getAttendanceReport(from?: string, to?: string): Promise<AttendanceReport> {
+    const response = await this.client.get<AttendanceReport>('/admin/reports/attendance', { params: { from, to } });
+    return response.data;
+  }
+
+  async getConsumptionReport(months?: number): Promise<ConsumptionReport> {
+    const response = await this.client.get<ConsumptionReport>('/admin/reports/consumption', { params: { months } });
+    return response.data;
+  }
+
+  // Admin - Second-admin approvals
+  async listPendingActions(status: PendingActionStatus | 'all' = 'pending'): Promise<PendingAction[]> {
+    const response = await this.client.get<PendingAction[]>('/admin/pending-actions', { params: { status } });
+    return response.data;
+  }
+
+  async approvePendingAction(id: string): Promise<PendingAction> {
+    const response = await this.client.post<PendingAction>(`/admin/pending-actions/${id}/approve`);
+    return response.data;
+  }
+
+  async declinePendingAction(id: string): Promise<PendingAction> {
+    const response = await this.client.post<PendingAction>(`/admin/pending-actions/${id}/decline`);
+    return response.data;
+  }
+
   // Admin - Club
   async updateClub(data: Partial<Club>): Promise<Club> {
     const response = await this.client.put<Club>('/admin/club', data);
@@ -170,7 +792,7 @@ class ApiService {
     return response.data;
   }
 
-  async updateNotificationPreferences(updates: Partial<NotificationPreferences>): Promise<NotificationPreferences> {
+  async updateNotificationPreferences(updates: NotificationPreferencesUpdate): Promise<NotificationPreferences> {
     const response = await this.client.put<NotificationPreferences>('/users/me/notifications', updates);
     return response.data;
   }
@@ -184,10 +806,17 @@ class ApiService {
     await this.client.delete('/users/me/push-tokens', { data: { token } });
   }
 
+  // Registers the push token (if the user allowed push) and fetches preferences,
+  // unread count and recent history in one request
+  async initDevice(input: { token?: string; device_name?: string; history_limit?: number } = {}): Promise<DeviceInit> {
+    const response = await this.client.post<DeviceInit>('/users/me/devices/init', input);
+    return response.data;
+  }
+
   // Notifications - History
-  async getNotificationHistory(limit = 20, offset = 0): Promise<Notification[]> {
+  async getNotificationHistory(limit = 20, offset = 0, filter: NotificationHistoryFilter = {}): Promise<Notification[]> {
     const response = await this.client.get<Notification[]>('/users/me/notifications/history', {
-      params: { limit, offset }
+      params: { limit, offset, ...filter }
     });
     return response.data;
   }
@@ -196,46 +825,400 @@ class ApiService {
     await this.client.post(`/notifications/${notificationId}/read`);
   }
 
+  // Admin - Notification log
+  async getNotificationLog(filter: NotificationLogFilter = {}, limit = 50, offset = 0): Promise<NotificationLogEntry[]> {
+    const response = await this.client.get<NotificationLogEntry[]>('/admin/notifications', {
+      params: { ...filter, limit, offset }
+    });
+    return response.data;
+  }
+
+  async resendNotification(id: string): Promise<NotificationLogEntry> {
+    const response = await this.client.post<NotificationLogEntry>(`/admin/notifications/${id}/resend`);
+    return response.data;
+  }
+
+  // Admin - Scheduler jobs
+  async getJobs(name?: string, limit = 50): Promise<{ jobs: JobStatus[]; runs: JobRun[] }> {
+    const response = await this.client.get<{ jobs: JobStatus[]; runs: JobRun[] }>('/admin/jobs', {
+      params: { name, limit }
+    });
+    return response.data;
+  }
+
+  // Admin - Domain event changelog; pass next_after back as after for the next page
+  async getEvents(filter: DomainEventFilter = {}, after = 0, limit = 100): Promise<{ events: DomainEvent[]; next_after: number }> {
+    const response = await this.client.get<{ events: DomainEvent[]; next_after: number }>('/admin/events', {
+      params: { ...filter, after, limit }
+    });
+    return response.data;
+  }
+
+  async getArchiveStats(): Promise<ArchiveTableStats[]> {
+    const response = await this.client.get<ArchiveTableStats[]>('/admin/archive');
+    return response.data;
+  }
+
+  // Admin - Club export, a JSON bundle for `server import` on another deployment
+  async exportClub(): Promise<Blob> {
+    const response = await this.client.get('/admin/export', { responseType: 'blob' });
+    return response.data;
+  }
+
+  async runJob(name: ManualJob, dryRun = true): Promise<JobReport> {
+    const response = await this.client.post<JobReport>(`/admin/jobs/${name}/run`, null, {
+      params: { dry_run: dryRun }
+    });
+    return response.data;
+  }
+
   // Admin - Announcements
-  async sendAnnouncement(title: string, body: string): Promise<Announcement> {
-    const response = await this.client.post<Announcement>('/admin/announcements', { title, body });
+  // Pass override to send past the announcement limit; it's sent with confirm
+  async sendAnnouncement(title: string, body: string, override = false, requiresAck = false): Promise<SentAnnouncement> {
+    const response = await this.client.post<SentAnnouncement>('/admin/announcements', {
+      title,
+      body,
+      requires_ack: requiresAck,
+      override,
+      confirm: override
+    });
+    return response.data;
+  }
+
+  async getAnnouncementAckSummaries(): Promise<AnnouncementAckSummary[]> {
+    const response = await this.client.get<AnnouncementAckSummary[]>('/admin/announcements/acknowledgements');
+    return response.data;
+  }
+
+  async getAnnouncementAcknowledgements(id: string): Promise<AnnouncementAcknowledgements> {
+    const response = await this.client.get<AnnouncementAcknowledgements>(`/admin/announcements/${id}/acknowledgements`);
+    return response.data;
+  }
+
+  // Announcements
+  async getAnnouncements(): Promise<AnnouncementWithAck[]> {
+    const response = await this.client.get<AnnouncementWithAck[]>('/announcements');
+    return response.data;
+  }
+
+  async acknowledgeAnnouncement(id: string): Promise<void> {
+    await this.client.post(`/announcements/${id}/acknowledge`);
+  }
+
+  // Committee
+  async getCommittee(): Promise<User[]> {
+    const response = await this.client.get<User[]>('/committee');
+    return response.data;
+  }
+
+  async listMinutes(): Promise<CommitteeMinutes[]> {
+    const response = await this.client.get<CommitteeMinutes[]>('/committee/minutes');
+    return response.data;
+  }
+
+  async getMinutes(id: string): Promise<CommitteeMinutes> {
+    const response = await this.client.get<CommitteeMinutes>(`/committee/minutes/${id}`);
+    return response.data;
+  }
+
+  // meetingDate is YYYY-MM-DD
+  async createMinutes(meetingDate: string, title: string, body: string): Promise<CommitteeMinutes> {
+    const response = await this.client.post<CommitteeMinutes>('/committee/minutes', { meeting_date: meetingDate, title, body });
+    return response.data;
+  }
+
+  async updateMinutes(id: string, meetingDate: string, title: string, body: string): Promise<CommitteeMinutes> {
+    const response = await this.client.put<CommitteeMinutes>(`/committee/minutes/${id}`, { meeting_date: meetingDate, title, body });
+    return response.data;
+  }
+
+  async deleteMinutes(id: string): Promise<void> {
+    await this.client.delete(`/committee/minutes/${id}`);
+  }
+
+  async listCommitteeDocuments(): Promise<ClubDocument[]> {
+    const response = await this.client.get<ClubDocument[]>('/committee/documents');
+    return response.data;
+  }
+
+  async uploadCommitteeDocument(file: File, title: string, category: DocumentCategory, description = ''): Promise<ClubDocument> {
+    const form = new FormData();
+    form.append('file', file);
+    form.append('title', title);
+    form.append('description', description);
+    form.append('category', category);
+    const response = await this.client.post<ClubDocument>('/committee/documents', form, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    });
+    return response.data;
+  }
+
+  async deleteCommitteeDocument(id: string): Promise<void> {
+    await this.client.delete(`/committee/documents/${id}`);
+  }
+
+  async sendCommitteeAnnouncement(title: string, body: string, requiresAck = false): Promise<Announcement> {
+    const response = await this.client.post<Announcement>('/committee/announcements', { title, body, requires_ack: requiresAck });
+    return response.data;
+  }
+
+  // Admin - Notification wording
+  async getMessageTemplates(): Promise<MessageTemplate[]> {
+    const response = await this.client.get<MessageTemplate[]>('/admin/message-templates');
+    return response.data;
+  }
+
+  async updateMessageTemplate(key: MessageTemplateKey, language: Language, title: string, body: string): Promise<void> {
+    await this.client.put(`/admin/message-templates/${key}`, { title, body }, { params: { language } });
+  }
+
+  async resetMessageTemplate(key: MessageTemplateKey, language: Language): Promise<void> {
+    await this.client.delete(`/admin/message-templates/${key}`, { params: { language } });
+  }
+
+  // Omitted title or body preview the current wording
+  async previewMessageTemplate(key: MessageTemplateKey, language: Language, title?: string, body?: string): Promise<MessagePreview> {
+    const response = await this.client.post<MessagePreview>(`/admin/message-templates/${key}/preview`, { title, body }, { params: { language } });
+    return response.data;
+  }
+
+  // Training program
+  async getMyTraining(): Promise<TrainingProgress> {
+    const response = await this.client.get<TrainingProgress>('/users/me/training');
+    return response.data;
+  }
+
+  // Coaches - Training program
+  async getTrainingSessions(): Promise<Session[]> {
+    const response = await this.client.get<Session[]>('/coaching/sessions');
+    return response.data;
+  }
+
+  async updateCurriculum(sessionId: string, curriculumNotes: string): Promise<Session> {
+    const response = await this.client.put<Session>(`/coaching/sessions/${sessionId}/curriculum`, {
+      curriculum_notes: curriculumNotes
+    });
+    return response.data;
+  }
+
+  async getTrainingAttendance(sessionId: string): Promise<TrainingRosterEntry[]> {
+    const response = await this.client.get<TrainingRosterEntry[]>(`/coaching/sessions/${sessionId}/attendance`);
+    return response.data;
+  }
+
+  async recordTrainingAttendance(sessionId: string, userIds: string[], attended = true): Promise<TrainingRosterEntry[]> {
+    const response = await this.client.post<TrainingRosterEntry[]>(`/coaching/sessions/${sessionId}/attendance`, {
+      user_ids: userIds,
+      attended
+    });
+    return response.data;
+  }
+
+  async getTrainees(): Promise<Trainee[]> {
+    const response = await this.client.get<Trainee[]>('/coaching/players');
+    return response.data;
+  }
+
+  async getPlayerTraining(userId: string): Promise<TrainingProgress> {
+    const response = await this.client.get<TrainingProgress>(`/coaching/players/${userId}/progress`);
+    return response.data;
+  }
+
+  async updatePlayerGoals(userId: string, goals: string): Promise<void> {
+    await this.client.put(`/coaching/players/${userId}/goals`, { goals });
+  }
+
+  async recordAssessment(userId: string, input: SkillAssessmentInput): Promise<SkillAssessment> {
+    const response = await this.client.post<SkillAssessment>(`/coaching/players/${userId}/assessments`, input);
+    return response.data;
+  }
+
+  async deleteAssessment(id: string): Promise<void> {
+    await this.client.delete(`/coaching/assessments/${id}`);
+  }
+
+  // Carpools
+  async getCarpool(sessionId: string): Promise<CarpoolBoard> {
+    const response = await this.client.get<CarpoolBoard>(`/sessions/${sessionId}/carpool`);
+    return response.data;
+  }
+
+  async saveCarpoolOffer(
+    sessionId: string,
+    data: { origin_suburb: string; seats: number; notes?: string }
+  ): Promise<CarpoolBoard> {
+    const response = await this.client.put<CarpoolBoard>(`/sessions/${sessionId}/carpool/offer`, data);
+    return response.data;
+  }
+
+  async deleteCarpoolOffer(sessionId: string): Promise<void> {
+    await this.client.delete(`/sessions/${sessionId}/carpool/offer`);
+  }
+
+  async saveCarpoolRequest(
+    sessionId: string,
+    data: { origin_suburb: string; notes?: string; offer_id?: string }
+  ): Promise<CarpoolBoard> {
+    const response = await this.client.put<CarpoolBoard>(`/sessions/${sessionId}/carpool/request`, data);
+    return response.data;
+  }
+
+  async deleteCarpoolRequest(sessionId: string): Promise<void> {
+    await this.client.delete(`/sessions/${sessionId}/carpool/request`);
+  }
+
+  async confirmCarpoolRider(sessionId: string, requestId: string): Promise<CarpoolBoard> {
+    const response = await this.client.post<CarpoolBoard>(
+      `/sessions/${sessionId}/carpool/requests/${requestId}/confirm`
+    );
+    return response.data;
+  }
+
+  async declineCarpoolRider(sessionId: string, requestId: string): Promise<CarpoolBoard> {
+    const response = await this.client.post<CarpoolBoard>(
+      `/sessions/${sessionId}/carpool/requests/${requestId}/decline`
+    );
+    return response.data;
+  }
+
+  // Group orders
+  async getOrderWindows(): Promise<OrderWindow[]> {
+    const response = await this.client.get<OrderWindow[]>('/orders');
+    return response.data;
+  }
+
+  async getOrderWindow(id: string): Promise<{ window: OrderWindow; my_order: Order | null }> {
+    const response = await this.client.get<{ window: OrderWindow; my_order: Order | null }>(`/orders/${id}`);
+    return response.data;
+  }
+
+  async submitOrder(id: string, lines: OrderLineInput[], notes = ''): Promise<Order> {
+    const response = await this.client.put<Order>(`/orders/${id}/my-order`, { lines, notes });
+    return response.data;
+  }
+
+  async cancelOrder(id: string): Promise<void> {
+    await this.client.delete(`/orders/${id}/my-order`);
+  }
+
+  // Admin - Group orders
+  async createOrderWindow(input: CreateOrderWindowInput): Promise<OrderWindow> {
+    const response = await this.client.post<OrderWindow>('/orders', input);
+    return response.data;
+  }
+
+  async updateOrderWindow(
+    id: string,
+    data: Partial<Pick<OrderWindow, 'title' | 'description' | 'closes_at'>>
+  ): Promise<OrderWindow> {
+    const response = await this.client.put<OrderWindow>(`/orders/${id}`, data);
+    return response.data;
+  }
+
+  async closeOrderWindow(id: string): Promise<OrderWindow> {
+    const response = await this.client.post<OrderWindow>(`/orders/${id}/close`);
+    return response.data;
+  }
+
+  async getOrderSummary(id: string): Promise<OrderSummary> {
+    const response = await this.client.get<OrderSummary>(`/orders/${id}/summary`);
+    return response.data;
+  }
+
+  async exportOrders(id: string, byMember = false): Promise<Blob> {
+    const response = await this.client.get(`/orders/${id}/export`, {
+      params: byMember ? { by: 'member' } : undefined,
+      responseType: 'blob'
+    });
     return response.data;
   }
 }
 
 // Notification types
+export type NotificationChannel = 'push' | 'email';
+
+// Per-type settings, keyed by notification type; only the channels a
+// member can change are present
+export type NotificationTypeSettings = Record<string, Partial<Record<NotificationChannel, boolean>>>;
+
 export interface NotificationPreferences {
   id: string;
   user_id: string;
   push_enabled: boolean;
-  push_session_reminders: boolean;
-  push_rsvp_deadlines: boolean;
-  push_waitlist_updates: boolean;
-  push_admin_announcements: boolean;
   email_enabled: boolean;
-  email_session_reminders: boolean;
-  email_rsvp_deadlines: boolean;
-  email_waitlist_updates: boolean;
-  email_admin_announcements: boolean;
+  reminder_show_attendees: boolean;
+  comment_notifications: CommentNotificationLevel;
+  types: NotificationTypeSettings;
   created_at: string;
   updated_at: string;
 }
 
+export interface NotificationPreferencesUpdate {
+  push_enabled?: boolean;
+  email_enabled?: boolean;
+  reminder_show_attendees?: boolean;
+  comment_notifications?: CommentNotificationLevel;
+  types?: NotificationTypeSettings;
+}
+
 export interface Notification {
   id: string;
   user_id: string;
   notification_type: string;
   title: string;
   body: string;
-  data?: string;
+  data?: Record<string, string>;
   push_sent: boolean;
   push_sent_at?: string;
+  push_error?: string;
   email_sent: boolean;
   email_sent_at?: string;
+  email_error?: string;
+  sms_sent: boolean;
+  sms_sent_at?: string;
+  sms_error?: string;
+  urgent: boolean;
   read_at?: string;
   created_at: string;
 }
 
+export interface DeviceInit {
+  push_token_registered: boolean;
+  preferences: NotificationPreferences;
+  unread_count: number;
+  history: Notification[];
+}
+
+export interface NotificationHistoryFilter {
+  session_id?: string;
+  type?: string;
+  read?: boolean;
+  from?: string; // YYYY-MM-DD
+  to?: string; // YYYY-MM-DD
+  q?: string; // full-text search over title and body
+  fields?: string; // comma-separated; notifications come back with only these keys (and id)
+}
+
+export interface NotificationLogEntry extends Notification {
+  recipient: User;
+}
+
+export interface NotificationLogFilter {
+  user_id?: string;
+  type?: string;
+  channel?: 'push' | 'email' | 'sms';
+  failed?: boolean;
+}
+
+export interface DomainEventFilter {
+  type?: string; // comma-separated, 'rsvp.*' for a prefix
+  session_id?: string;
+  user_id?: string;
+  actor_id?: string;
+  since?: string;
+  until?: string;
+}
+
 export interface Announcement {
   id: string;
   title: string;
@@ -243,6 +1226,67 @@ export interface Announcement {
   created_by: string;
   sent_at: string;
   created_at: string;
+  requires_ack: boolean;
+  nudges_sent: number;
+  last_nudged_at?: string;
+  audience: 'members' | 'committee';
+}
+
+export type SearchType = 'members' | 'sessions' | 'announcements';
+
+// Only the types searched, and the viewer can see, are present
+export interface SearchResults {
+  query: string;
+  members?: User[];
+  sessions?: Session[];
+  announcements?: Announcement[];
+}
+
+export interface AnnouncementWithAck extends Announcement {
+  acknowledged_at?: string;
+}
+
+export interface AnnouncementAckSummary extends Announcement {
+  acknowledged: number;
+  outstanding: number;
+}
+
+export interface AnnouncementAcknowledgements {
+  announcement: Announcement;
+  acknowledged: { user: User; acknowledged_at: string }[];
+  outstanding: User[];
+}
+
+export interface AnnouncementQuota {
+  limit: number; // 0 means uncapped
+  used: number;
+  remaining: number;
+  resets_at?: string;
+}
+
+export interface SentAnnouncement extends Announcement {
+  quota: AnnouncementQuota;
+}
+
+export type MessageTemplateKey = 'session_reminder' | 'rsvp_deadline' | 'waitlist_update' | 'rsvp_summary' | 'maybe_nudge' | 'maybe_expired' | 'monthly_recap';
+
+// One message in one language
+export interface MessageTemplate {
+  key: MessageTemplateKey;
+  language: Language;
+  description: string;
+  title: string; // Go text/template source
+  body: string;
+  default_title: string;
+  default_body: string;
+  customized: boolean;
+  placeholders: string[];
+  updated_at?: string;
+}
+
+export interface MessagePreview {
+  title: string;
+  body: string;
 }
 
 export const api = new ApiService();
@@ -79,7 +79,8 @@ export const initializeMessaging = async (): Promise<Messaging | null> => {
       if (registration.active) {
         registration.active.postMessage({
           type: 'FIREBASE_CONFIG',
-          config: firebaseConfig
+          config: firebaseConfig,
+          apiUrl: import.meta.env.VITE_API_URL || '/api'
         });
       }
 
@@ -1,4 +1,10 @@
-import { api, NotificationPreferences, Notification } from './api';
+import {
+  api,
+  NotificationPreferences,
+  NotificationPreferencesUpdate,
+  Notification,
+  NotificationHistoryFilter
+} from './api';
 import {
   requestNotificationPermission,
   onForegroundMessage,
@@ -6,7 +12,7 @@ import {
   isFirebaseConfigured
 } from './firebase';
 
-export type { NotificationPreferences, Notification };
+export type { NotificationPreferences, NotificationPreferencesUpdate, Notification };
 
 export const notificationService = {
   // Check if push notifications are supported
@@ -68,15 +74,13 @@ export const notificationService = {
   },
 
   // Update user's notification preferences
-  async updatePreferences(
-    updates: Partial<Omit<NotificationPreferences, 'id' | 'user_id' | 'created_at' | 'updated_at'>>
-  ): Promise<NotificationPreferences> {
+  async updatePreferences(updates: NotificationPreferencesUpdate): Promise<NotificationPreferences> {
     return api.updateNotificationPreferences(updates);
   },
 
   // Get notification history
-  async getHistory(limit = 20, offset = 0): Promise<Notification[]> {
-    return api.getNotificationHistory(limit, offset);
+  async getHistory(limit = 20, offset = 0, filter: NotificationHistoryFilter = {}): Promise<Notification[]> {
+    return api.getNotificationHistory(limit, offset, filter);
   },
 
   // Mark notification as read
@@ -1,51 +1,170 @@
-export type UserRole = 'pending' | 'player' | 'admin';
-export type MembershipStatus = 'pending' | 'approved' | 'rejected';
-export type RSVPStatus = 'in' | 'out' | 'maybe';
+// Coaches are players who also run training sessions
+export type UserRole = 'pending' | 'player' | 'coach' | 'admin';
+// 'archived' members were inactive and are restored when they next sign in
+export type MembershipStatus = 'pending' | 'approved' | 'rejected' | 'archived';
+// President, treasurer and secretary each have one holder; 'member' is an ordinary committee member
+export type CommitteeRole = 'president' | 'treasurer' | 'secretary' | 'member';
+// Weekly RSVP limit: regular 1, twice_a_week 2, unlimited none
+export type MembershipTier = 'regular' | 'twice_a_week' | 'unlimited';
+// 'requested' and 'declined' only occur on sessions that require approval
+export type RSVPStatus = 'in' | 'out' | 'maybe' | 'requested' | 'declined' | 'waitlisted';
 export type SessionStatus = 'open' | 'closed' | 'cancelled';
+export type SessionType = 'social' | 'training';
+// Languages notifications can be sent in
+export type Language = 'en' | 'zh';
 
 export interface User {
   id: string;
-  auth0_id: string;
-  email: string;
   name: string;
-  profile_picture: string;
-  phone_number: string;
+  profile_picture: string; // from Auth0
+  avatar_id?: string | null; // the photo they uploaded, if any
+  avatar_url: string; // uploaded photo, Auth0 picture or generated initials; resolve with apiAssetUrl
   role: UserRole;
   is_player: boolean;
   membership_status: MembershipStatus;
+  committee_role?: CommitteeRole; // absent when not on the committee
+  // Only returned for your own profile, or to admins
+  auth0_id?: string;
+  tier?: MembershipTier;
+  email?: string;
+  phone_number?: string;
+  created_at?: string;
+  updated_at?: string;
+  privacy?: PrivacySettings;
+  preferred_language?: Language;
+  inactive_notified_at?: string; // when they were told they'd been inactive
+  archived_at?: string;
+}
+
+export type AvatarSize = 'sm' | 'md' | 'lg'; // 64, 256 and 512 px
+
+// A member's report of another member's photo, for admins to review
+export interface AvatarReport {
+  id: string;
+  user_id: string; // whose photo
+  avatar_id: string;
+  reported_by: string;
+  reason: string;
+  status: 'open' | 'resolved';
+  action?: 'delete' | 'dismiss';
+  resolved_by?: string;
+  resolved_at?: string;
   created_at: string;
-  updated_at: string;
+  user?: User;
+  reporter?: User;
+}
+
+// What a member keeps from other members; admins still see everything
+export interface PrivacySettings {
+  hide_from_waitlist: boolean;
+  hide_from_leaderboards: boolean;
+  hide_attendance: boolean; // check-in times and attendance badges
+}
+
+export interface UpdateProfileInput {
+  phone_number?: string;
+  // Write-only: never returned, printed only on the session sheet
+  emergency_contact_name?: string;
+  emergency_contact_phone?: string;
+  medical_notes?: string;
+  privacy?: PrivacySettings;
+  preferred_language?: Language;
 }
 
+export type AttendeeVisibility = 'names' | 'count' | 'after_rsvp';
+
 export interface Club {
   id: string;
   name: string;
   venue_name: string;
   venue_address: string;
+  // Session capacity is courts × players_per_court + extra_players;
+  // players_per_court 0 uses the default 6/10/16 table
+  players_per_court: number;
+  extra_players: number;
+  // Hours (0-23, club time) during which non-urgent emails are sent; equal means any time
+  email_window_start: number;
+  email_window_end: number;
+  // Weeks of recurring sessions kept generated ahead (1-52)
+  recurring_weeks_ahead: number;
+  // Club-wide RSVP caps per calendar week and per recurring series; 0 is no cap
+  max_rsvps_per_week: number;
+  max_rsvps_per_series: number;
+  // Copy the RSVP-close summary to the member who created the session
+  rsvp_summary_to_organizer: boolean;
+  // Hours before the RSVP deadline that members on maybe are nudged; 0 is off
+  maybe_nudge_hours: number;
+  // Change maybes to out this many hours before the deadline
+  expire_maybes: boolean;
+  maybe_expiry_hours: number;
+  waitlist_offer_hours: number;
+  weather_rain_chance: number; // forecast thresholds for weather proposals; 0 ignores one
+  weather_wind_kmh: number;
+  weather_temperature: number;
+  // How much members see of who's coming; admins, organizers and coaches see everyone
+  attendee_visibility: AttendeeVisibility;
   created_at: string;
   updated_at: string;
 }
 
+export const MAX_COURTS = 20;
+
+// Mirrors the backend's capacity formula, for previews before saving
+export function maxPlayersForCourts(courts: number, club?: Club | null): number {
+  if (club && club.players_per_court > 0) {
+    return courts * club.players_per_court + club.extra_players;
+  }
+  if (courts <= 1) return 6;
+  if (courts === 2) return 10;
+  return 16 + (courts - 3) * 6;
+}
+
 export interface Session {
   id: string;
   title: string;
   description: string;
   session_date: string;
+  starts_at: string;
+  ends_at: string;
   start_time: string;
   end_time: string;
   courts: number;
   max_players: number;
+  overbooking: number; // extra INs taken until RSVPs close
   rsvp_deadline: string;
   is_recurring: boolean;
   recurring_day_of_week: number | null;
   recurring_parent_id: string | null;
+  season_rollover_id?: string; // scheduled by a season rollover
   status: SessionStatus;
+  is_outdoor: boolean;
+  requires_approval: boolean;
+  fair_share: boolean;
+  allow_guests: boolean; // members who are IN can bring a guest
+  session_type: SessionType;
+  coach_id?: string; // training sessions only
+  curriculum_notes?: string;
+  allocated_at?: string;
   cancellation_reason?: string;
   created_by: string;
   created_at: string;
   updated_at: string;
   rsvps?: RSVP[];
   creator?: User;
+  coach?: User;
+  attachments?: SessionAttachment[]; // approved members only
+  rsvp_summary?: RSVPSummary; // on session lists
+  my_rsvp?: RSVP | null;
+}
+
+export interface SessionAttachment {
+  id: string;
+  session_id: string;
+  file_name: string;
+  content_type: string;
+  size_bytes: number;
+  uploaded_by: string;
+  created_at: string;
 }
 
 export interface RSVP {
@@ -56,28 +175,404 @@ export interface RSVP {
   rsvp_timestamp: string;
   is_late_rsvp: boolean;
   added_by_admin: boolean;
+  as_regular: boolean; // put IN as one of the series' regulars
+  checked_in_at?: string;
   created_at: string;
   updated_at: string;
   user?: User;
   session?: Session;
+  waitlist_position?: number;
+  waitlist_offer_expires_at?: string; // a spot is held for me until then
+}
+
+export interface SeriesRegulars {
+  regulars: User[];
+  rsvps_added?: number; // only when setting them
 }
 
 export interface RSVPSummary {
   total_in: number;
   total_out: number;
   total_maybe: number;
+  total_requested: number;
+  total_waitlist: number;
+  total_guests: number; // each takes a spot
   max_players: number;
+  overbooking: number;
   spots_left: number;
 }
 
+export interface RSVPRequest extends RSVP {
+  recent_attendance: number;
+}
+
+export type AllocationAlgorithm = 'fewest_recent' | 'weighted_lottery' | 'first_come';
+
+export interface AllocationResult {
+  id: string;
+  session_id: string;
+  user_id: string;
+  algorithm: AllocationAlgorithm;
+  recent_attendance: number;
+  score: number;
+  rank: number;
+  selected: boolean;
+  created_at: string;
+  user?: User;
+}
+
+export type RSVPConflict = 'latest' | 'most_committed' | 'target' | 'source';
+
+export interface MergedRSVP {
+  user_id: string;
+  target_status: RSVPStatus;
+  source_status: RSVPStatus;
+  status: RSVPStatus;
+}
+
+export interface MergeSessionsResult {
+  session: Session;
+  moved_rsvps: number;
+  merged_rsvps: MergedRSVP[];
+  moved_comments: number;
+  moved_attachments: number;
+}
+
+export interface SessionListFilter {
+  from?: string; // YYYY-MM-DD
+  to?: string; // YYYY-MM-DD
+  include_past?: boolean;
+  fields?: string; // comma-separated; sessions come back with only these keys (and id)
+}
+
+export interface PastSessionsFilter {
+  from?: string; // YYYY-MM-DD
+  to?: string; // YYYY-MM-DD
+  limit?: number;
+  offset?: number;
+  fields?: string; // comma-separated; sessions come back with only these keys (and id)
+}
+
+export interface MembershipCard {
+  member_id: string;
+  name: string;
+  tier: MembershipTier;
+  member_since: string;
+  token: string;
+  qr_code: string; // PNG data URL
+  expires_at: string;
+}
+
+export interface ShareLink {
+  token: string;
+  url: string;
+  expires_at: string;
+}
+
+export interface SessionPreview {
+  club_name: string;
+  title: string;
+  description: string;
+  session_date: string;
+  start_time: string;
+  end_time: string;
+  status: SessionStatus;
+  venue_name: string;
+  venue_address: string;
+  max_players: number;
+  spots_left: number;
+}
+
+export interface SharedSession {
+  session: SessionPreview;
+  join_url: string;
+}
+
+export interface WaitlistEntry {
+  position: number;
+  user_id?: string; // omitted, with a placeholder name, for members who hide from waitlists
+  name: string;
+  offer_expires_at?: string; // offered a spot, held until then
+}
+
+export interface Guest {
+  id: string;
+  session_id: string;
+  name: string;
+  invited_by: string;
+  created_at: string;
+  inviter?: User;
+}
+
 export interface SessionWithSummary {
   session: Session;
   rsvp_summary: RSVPSummary;
+  waitlist?: WaitlistEntry[];
+  guests?: Guest[];
+}
+
+export type DocumentCategory = 'rules' | 'constitution' | 'induction' | 'other';
+
+export interface ClubDocument {
+  id: string;
+  title: string;
+  description: string;
+  category: DocumentCategory;
+  file_name: string;
+  content_type: string;
+  size_bytes: number;
+  required: boolean;
+  committee_only: boolean; // a committee paper, only seen by the committee
+  uploaded_by: string;
+  created_at: string;
+  updated_at: string;
+  acknowledged_at?: string | null;
+}
+
+// Minutes of a committee meeting
+export interface CommitteeMinutes {
+  id: string;
+  meeting_date: string;
+  title: string;
+  body: string;
+  created_by: string;
+  created_at: string;
+  updated_at: string;
+  creator?: User;
+}
+
+export type ContactMethod = 'phone' | 'email' | 'sms' | 'in_person' | 'other';
+
+// An admin's off-platform contact with a member, with an optional follow-up
+export interface ContactLog {
+  id: string;
+  user_id: string;
+  method: ContactMethod;
+  subject: string;
+  notes?: string;
+  contacted_at: string;
+  logged_by: string;
+  follow_up_at?: string;
+  assigned_to?: string; // who follows up
+  follow_up_done_at?: string;
+  follow_up_done_by?: string;
+  created_at: string;
+  updated_at: string;
+  user?: User;
+  logger?: User;
+  assignee?: User;
+}
+
+export interface ContactLogInput {
+  method: ContactMethod;
+  subject: string;
+  notes?: string;
+  contacted_at?: string; // now if omitted
+  follow_up_at?: string;
+  assigned_to?: string; // the logging admin if omitted
+}
+
+export interface AuditLog {
+  id: string;
+  entity_type: string;
+  entity_id: string;
+  action: string;
+  actor_id: string;
+  old_value?: string;
+  new_value?: string;
+  reason?: string;
+  created_at: string;
+  actor?: User;
+}
+
+// One entry on a member's timeline
+export interface TimelineEntry {
+  kind: 'contact' | 'audit';
+  at: string;
+  contact?: ContactLog;
+  audit?: AuditLog;
+}
+
+export type IncidentType = 'injury' | 'facility' | 'other';
+export type IncidentSeverity = 'low' | 'medium' | 'high' | 'critical';
+export type IncidentStatus = 'open' | 'resolved';
+
+export interface IncidentAttachment {
+  id: string;
+  incident_id: string;
+  file_name: string;
+  content_type: string;
+  size_bytes: number;
+  uploaded_by: string;
+  created_at: string;
+}
+
+export interface Incident {
+  id: string;
+  session_id: string;
+  reported_by: string;
+  type: IncidentType;
+  severity: IncidentSeverity;
+  title: string;
+  description: string;
+  occurred_at?: string;
+  status: IncidentStatus;
+  resolution_notes?: string;
+  resolved_by?: string;
+  resolved_at?: string;
+  created_at: string;
+  updated_at: string;
+  session?: Session;
+  reporter?: User;
+  attachments?: IncidentAttachment[];
+}
+
+export interface CreateIncidentInput {
+  type: IncidentType;
+  severity: IncidentSeverity;
+  title: string;
+  description: string;
+  occurred_at?: string;
+}
+
+export type PendingActionType = 'delete_session' | 'reject_member' | 'change_admin_role';
+export type PendingActionStatus = 'pending' | 'approved' | 'declined';
+
+export interface PendingAction {
+  id: string;
+  action_type: PendingActionType;
+  target_id: string;
+  payload?: string;
+  status: PendingActionStatus;
+  requested_by: string;
+  reviewed_by?: string;
+  reviewed_at?: string;
+  created_at: string;
+  updated_at: string;
+  requester?: User;
+}
+
+export type JobRunStatus = 'running' | 'succeeded' | 'failed';
+
+export interface JobRun {
+  id: string;
+  job_name: string;
+  started_at: string;
+  finished_at?: string;
+  status: JobRunStatus;
+  error?: string;
+}
+
+export interface JobStatus {
+  name: string;
+  last_run: JobRun;
+  last_succeeded_at: string | null;
+}
+
+export type DomainEventType =
+  | 'session.created'
+  | 'session.updated'
+  | 'session.cancelled'
+  | 'rsvp.changed'
+  | 'rsvp.removed'
+  | 'rsvp.request_approved'
+  | 'rsvp.request_declined'
+  | 'waitlist.offered'
+  | 'waitlist.lapsed'
+  | 'waitlist.overbooked'
+  | 'member.approved'
+  | 'member.rejected';
+
+export interface DomainEvent {
+  seq: number;
+  id: string;
+  type: DomainEventType;
+  session_id?: string;
+  user_id?: string;
+  actor_id?: string;
+  data: Record<string, unknown>;
+  occurred_at: string;
+}
+
+export type ManualJob =
+  | 'session_reminders'
+  | 'deadline_reminders'
+  | 'maybe_rsvps'
+  | 'rsvp_summaries'
+  | 'recurring_sessions'
+  | 'push_token_cleanup'
+  | 'database_backup'
+  | 'announcement_nudges'
+  | 'data_archive'
+  | 'member_inactivity'
+  | 'monthly_recaps'
+  | 'weather_proposals'
+  | 'rsvp_counts'
+  | 'overbooking'
+  | 'contact_follow_ups';
+
+export interface JobAction {
+  kind: 'notification' | 'create_session' | 'delete_push_token' | 'create_backup' | 'delete_backup' | 'archive' | 'member_active' | 'expire_maybe';
+  user_id?: string;
+  session_id?: string;
+  title: string;
+  detail?: string;
+}
+
+// Live and archived rows of a table the data_archive job moves old rows out of
+export interface ArchiveTableStats {
+  table: 'notifications' | 'rsvps';
+  after_months: number; // 0 when archiving is off
+  live: number;
+  archived: number;
+  due: number; // live rows the next run will move
+  oldest_live?: string;
+  last_archived_at?: string;
+}
+
+// How much a member uses the app, for GET /admin/usage
+export interface MemberUsage {
+  user: User;
+  requests: number; // in the report's window
+  active_days: number;
+  last_seen_at?: string;
+  level: 'active' | 'lapsed' | 'never';
+}
+
+export interface UsageReport {
+  days: number;
+  active: number;
+  lapsed: number;
+  never: number;
+  members: MemberUsage[]; // most recently seen first
+}
+
+// A member awaiting review for archival
+export interface InactiveMember {
+  user: User;
+  last_active_at?: string; // latest RSVP, if they've ever made one
+  notified_at: string;
+  review_due_at: string; // end of their grace period
+  ready_to_archive: boolean;
+}
+
+export interface JobReport {
+  job: ManualJob;
+  dry_run: boolean;
+  actions: JobAction[];
 }
 
 export interface AuthCallbackResponse {
   user: User;
   is_new: boolean;
+  invite_error?: string; // the invite link couldn't be used; the sign-in still worked
+}
+
+// Returned with a 409 from the auth callback when the login's email belongs to another member
+export interface AccountLinkRequired {
+  error: string;
+  link_required: true;
+  email: string;
 }
 
 export interface CreateSessionInput {
@@ -87,9 +582,18 @@ export interface CreateSessionInput {
   start_time: string;
   end_time: string;
   courts: number;
+  is_outdoor?: boolean;
+  requires_approval?: boolean;
+  fair_share?: boolean;
+  allow_guests?: boolean;
   is_recurring?: boolean;
   recurring_day_of_week?: number;
   occurrences?: number;
+  session_type?: SessionType;
+  coach_id?: string;
+  curriculum_notes?: string;
+  spots?: number; // training sessions: fewer players than the courts hold
+  overbooking?: number; // extra INs taken past the spots until RSVPs close
 }
 
 export interface UpdateSessionInput {
@@ -99,5 +603,545 @@ export interface UpdateSessionInput {
   start_time?: string;
   end_time?: string;
   courts?: number;
+  is_outdoor?: boolean;
+  requires_approval?: boolean;
+  fair_share?: boolean;
+  allow_guests?: boolean; // turning it off keeps guests already coming
   status?: SessionStatus;
+  session_type?: SessionType;
+  coach_id?: string;
+  curriculum_notes?: string;
+  spots?: number;
+  overbooking?: number;
+}
+
+// A player's record in the training program
+export interface TrainingProgress {
+  user_id: string;
+  goals: string;
+  sessions_attended: number;
+  last_attended_at?: string;
+  attendance: { session_id: string; title: string; starts_at: string; from_check_in: boolean }[]; // newest first
+  skills: SkillProgress[];
+}
+
+export interface SkillProgress {
+  skill: string;
+  first_score: number;
+  latest_score: number;
+  assessments: SkillAssessment[]; // oldest first
+}
+
+// Scored from 1 (just starting) to 5 (match ready)
+export interface SkillAssessment {
+  id: string;
+  progress_id: string;
+  user_id: string;
+  skill: string;
+  score: number;
+  notes: string;
+  session_id?: string;
+  assessed_by: string;
+  assessed_at: string;
+  created_at: string;
+}
+
+export interface SkillAssessmentInput {
+  skill: string;
+  score: number;
+  notes?: string;
+  session_id?: string;
+  assessed_at?: string;
+}
+
+export interface TrainingRosterEntry {
+  user: User;
+  rsvp_status: RSVPStatus | null; // null for a walk-in
+  waitlisted: boolean;
+  attended: boolean;
+  from_check_in: boolean;
+}
+
+export interface Trainee {
+  user: User;
+  sessions_attended: number;
+  last_attended_at?: string;
+  last_assessed_at?: string;
+}
+
+export type OrderWindowStatus = 'open' | 'closed';
+
+// A group order, such as club shirts or a box of shuttles
+export interface OrderWindow {
+  id: string;
+  title: string;
+  description: string;
+  status: OrderWindowStatus;
+  closes_at: string;
+  closed_at?: string;
+  created_by: string;
+  created_at: string;
+  updated_at: string;
+  items?: OrderItem[];
+}
+
+export interface OrderItem {
+  id: string;
+  window_id: string;
+  name: string;
+  options: string[]; // such as sizes; empty when there's nothing to choose
+  price_cents: number;
+  position: number;
+}
+
+export interface Order {
+  id: string;
+  window_id: string;
+  user_id: string;
+  notes: string;
+  created_at: string;
+  updated_at: string;
+  lines: OrderLine[];
+}
+
+export interface OrderLine {
+  id: string;
+  order_id: string;
+  item_id: string;
+  option: string;
+  quantity: number;
+}
+
+export interface OrderLineInput {
+  item_id: string;
+  option?: string;
+  quantity: number;
+}
+
+export interface CreateOrderWindowInput {
+  title: string;
+  description?: string;
+  closes_at: string;
+  items: { name: string; options?: string[]; price_cents: number }[];
+}
+
+export interface OrderItemTotal {
+  item_id: string;
+  item: string;
+  option: string;
+  quantity: number;
+  price_cents: number;
+  total_cents: number;
+}
+
+export interface OrderSummary {
+  window: OrderWindow;
+  totals: OrderItemTotal[];
+  orders: (Order & { user: User; total_cents: number })[];
+  order_count: number;
+  total_cents: number;
+}
+
+export type InventoryCategory = 'equipment' | 'consumable';
+
+export interface InventoryItem {
+  id: string;
+  name: string;
+  category: InventoryCategory;
+  unit: string;
+  quantity: number; // on hand, checked out included
+  checked_out: number;
+  available: number;
+  low_stock_threshold: number; // 0 never alerts
+  low_stock: boolean;
+  tracks_shuttles: boolean; // drawn down by each session's shuttles used
+  notes: string;
+  low_stock_notified_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface InventoryItemInput {
+  name: string;
+  category: InventoryCategory;
+  unit?: string;
+  quantity?: number; // opening stock; ignored on update
+  low_stock_threshold?: number;
+  tracks_shuttles?: boolean;
+  notes?: string;
+}
+
+export type InventoryMovementReason = 'restock' | 'adjustment' | 'session_usage' | 'not_returned';
+
+export interface InventoryMovement {
+  id: string;
+  item_id: string;
+  change: number;
+  reason: InventoryMovementReason;
+  session_id?: string;
+  actor_id?: string;
+  note: string;
+  created_at: string;
+}
+
+// Equipment an organizer took to a session
+export interface InventoryCheckout {
+  id: string;
+  item_id: string;
+  session_id: string;
+  quantity: number;
+  checked_out_by: string;
+  checked_out_at: string;
+  returned_quantity?: number;
+  checked_in_by?: string;
+  checked_in_at?: string;
+  item?: InventoryItem;
+}
+
+export interface ConsumptionReport {
+  months: {
+    month: string; // YYYY-MM
+    shuttles_used: number;
+    sessions_with_usage: number;
+    items: { item_id: string; name: string; unit: string; used: number; restocked: number }[];
+  }[];
+  avg_shuttles_per_session: number;
+  shuttles_on_hand?: number;
+  sessions_of_stock_left?: number;
+}
+
+export type ProposalStatus = 'pending' | 'approved' | 'declined' | 'lapsed';
+
+// A suggestion to cancel an outdoor session for its forecast, decided by an admin
+export interface CancellationProposal {
+  id: string;
+  session_id: string;
+  status: ProposalStatus;
+  reason: string;
+  forecast: string;
+  decided_by?: string;
+  decided_at?: string;
+  created_at: string;
+  session?: Session;
+  decider?: User;
+}
+
+export type AttendanceStatus = 'present' | 'absent' | 'excused';
+
+export interface Attendance {
+  id: string;
+  session_id: string;
+  user_id: string;
+  status: AttendanceStatus;
+  checked_in_at?: string;
+  recorded_by?: string; // missing for a check-in
+  note?: string;
+  updated_at: string;
+  user?: User;
+}
+
+// A session that has ended, with its final RSVPs (in rsvps) and who turned up
+export interface SessionHistoryEntry extends Session {
+  rsvp_summary: RSVPSummary;
+  attendance: Attendance[];
+}
+
+export interface HistoryFilter {
+  from?: string; // YYYY-MM-DD
+  to?: string; // YYYY-MM-DD
+  limit?: number;
+  offset?: number;
+}
+
+// A session I played: marked present, or IN where no attendance was taken
+export interface PlayedSession extends Session {
+  my_rsvp: RSVP | null; // null for a walk-in
+  my_attendance: Attendance | null;
+}
+
+// Confirmed RSVPs against attendance, for sessions where attendance was taken
+export interface AttendanceReport {
+  sessions_tracked: number;
+  sessions_untracked: number;
+  members: {
+    user_id: string;
+    name: string;
+    confirmed: number;
+    attended: number;
+    excused: number;
+    no_shows: number;
+    walk_ins: number;
+    reliability: number; // 0-1
+    last_no_show?: string;
+  }[];
+  months: {
+    month: string; // YYYY-MM
+    sessions_tracked: number;
+    confirmed: number;
+    no_shows: number;
+    no_show_rate: number;
+  }[];
+}
+
+export type CarpoolRequestStatus = 'open' | 'asked' | 'confirmed';
+
+// A member looking for a lift to a session; offer_id is the driver they've
+// asked or been confirmed with
+export interface CarpoolRequest {
+  id: string;
+  rider: User;
+  origin_suburb: string;
+  notes: string;
+  status: CarpoolRequestStatus;
+  offer_id?: string;
+  confirmed_at?: string;
+}
+
+// A member driving to a session with seats to spare
+export interface CarpoolOffer {
+  id: string;
+  driver: User;
+  origin_suburb: string;
+  seats: number;
+  seats_left: number;
+  notes: string;
+  riders: CarpoolRequest[];
+}
+
+export interface CarpoolBoard {
+  offers: CarpoolOffer[];
+  open_requests: CarpoolRequest[];
+  my_offer_id?: string;
+  my_request?: CarpoolRequest;
+  suggested_offers: CarpoolOffer[]; // while I need a lift
+  suggested_requests: CarpoolRequest[]; // while I have seats
+}
+
+// Which comments on a session a member is notified of
+export type CommentNotificationLevel = 'all' | 'mentions' | 'none';
+
+export interface CommentNotificationSetting {
+  session_id: string;
+  level: CommentNotificationLevel;
+  is_default: boolean; // from the member's notification preferences rather than set for this session
+}
+
+// Which join requests are approved without an admin; with auto_approve off
+// every request goes to manual review
+export interface JoinRules {
+  id: string;
+  club_id: string;
+  auto_approve: boolean;
+  email_domains: string[];
+  referral_codes: boolean;
+  preapproved_list: boolean;
+  updated_by?: string;
+  updated_at: string;
+}
+
+export type JoinRule = 'email_domain' | 'referral_code' | 'preapproved_list' | 'manual';
+
+// How a member's join request was approved
+export interface JoinApproval {
+  id: string;
+  user: User;
+  rule: JoinRule;
+  detail: string; // the domain, code or list entry that matched
+  referral_code_id?: string;
+  approved_by?: string; // the admin, for manual approvals
+  created_at: string;
+}
+
+export interface ReferralCode {
+  id: string;
+  code: string;
+  owner?: User;
+  uses: number;
+  disabled_at?: string;
+  created_at: string;
+}
+
+export interface PreapprovedMember {
+  id: string;
+  email: string;
+  name: string;
+  added_by: string;
+  used_by?: string;
+  used_at?: string;
+  created_at: string;
+}
+
+// An admin's sign-up link
+export interface Invite {
+  id: string;
+  token: string;
+  label: string;
+  max_uses: number;
+  uses: number;
+  expires_at: string;
+  auto_approve: boolean;
+  created_by: string;
+  revoked_at?: string;
+  created_at: string;
+  url: string;
+  usable: boolean;
+  used_by?: { user: User; approved: boolean; used_at: string }[];
+}
+
+export interface CreateInviteInput {
+  label?: string;
+  max_uses: number;
+  expires_at: string;
+  auto_approve: boolean;
+}
+
+// What the sign-up page shows about an invite before signing in
+export interface InvitePreview {
+  label: string;
+  expires_at: string;
+  auto_approve: boolean;
+  usable: boolean;
+}
+
+// How a session filled up, hour by hour
+export interface RSVPTimeline {
+  session_id: string;
+  starts_at: string;
+  max_players: number;
+  first_rsvp_at?: string;
+  filled_at?: string;
+  approximate: boolean; // some RSVPs predate the RSVP history
+  points: { hour: string; in: number; joined: number; left: number }[];
+}
+
+// How full a session is expected to be, from past drop-outs and no-shows
+export interface OccupancyForecast {
+  session_id: string;
+  max_players: number;
+  overbooking: number;
+  confirmed: number;
+  waitlisted: number;
+  rsvps_open: boolean;
+  basis: 'series' | 'club'; // whose past sessions the rates come from
+  sessions_sampled: number;
+  drop_rate: number; // 0-1
+  no_show_rate: number; // 0-1
+  expected_drops: number;
+  expected_players: number;
+  expected_attendance: number;
+  suggested_overbooking: number;
+}
+
+export interface RecurrencePreviewInput {
+  title?: string;
+  session_date: string; // YYYY-MM-DD
+  start_time: string; // HH:MM
+  end_time: string; // HH:MM
+  recurring_day_of_week: number;
+  occurrences?: number;
+}
+
+export interface RecurrenceOccurrence {
+  session_date: string;
+  title: string;
+  starts_at: string;
+  ends_at: string;
+  rsvp_deadline: string;
+  skipped?: string; // why it won't be created, e.g. the date has passed
+}
+
+export interface RecurrencePreview {
+  occurrences: RecurrenceOccurrence[];
+  created: number;
+  until: string;
+  continues: boolean; // the nightly job keeps adding weeks
+  warnings: string[];
+}
+
+export interface RolloverInput {
+  name: string;
+  start_date: string; // YYYY-MM-DD
+  end_date: string; // YYYY-MM-DD
+  blackouts?: { date: string; reason?: string }[];
+  template_ids?: string[]; // every recurring series if omitted
+}
+
+export interface PlannedSession {
+  session_id?: string; // once created
+  template_id: string;
+  template: string;
+  title: string;
+  session_date: string;
+  starts_at: string;
+  ends_at: string;
+}
+
+export interface RolloverPlan {
+  templates: number;
+  sessions: PlannedSession[];
+  skipped: {
+    template_id: string;
+    template: string;
+    session_date: string;
+    reason: 'blackout' | 'scheduled' | 'past';
+    detail?: string;
+  }[];
+}
+
+export interface BlackoutDate {
+  id: string;
+  rollover_id: string;
+  date: string;
+  reason?: string;
+  created_at: string;
+}
+
+export interface SeasonRollover {
+  id: string;
+  name: string;
+  start_date: string;
+  end_date: string;
+  sessions_created: number;
+  created_by: string;
+  created_at: string;
+  undone_at?: string;
+  undone_by?: string;
+  blackouts?: BlackoutDate[];
+  creator?: User;
+}
+
+export interface UserBadge {
+  id: string;
+  user_id: string;
+  badge_type: string;
+  awarded_at: string;
+  created_at: string;
+}
+
+export interface Streak {
+  // Consecutive Monday-to-Sunday weeks played; still going if this week has no session yet
+  current_weeks: number;
+  longest_weeks: number;
+}
+
+export interface MemberRecap {
+  month: string; // YYYY-MM
+  sessions_played: number;
+  games_played: number;
+  games_won: number;
+  badges_earned: UserBadge[];
+  fastest_rsvp_seconds: number | null;
+  streak: Streak;
+}
+
+export interface FastestFinger {
+  user_id?: string; // left out for members hiding from leaderboards
+  name: string;
+  seconds: number;
+}
+
+export interface StatsRecap {
+  recap: MemberRecap;
+  week: string; // Monday, YYYY-MM-DD
+  fastest_fingers: FastestFinger[];
 }
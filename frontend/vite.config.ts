@@ -37,6 +37,10 @@ export default defineConfig({
         target: 'http://localhost:8080',
         changeOrigin: true,
       },
+      '/share/sessions': {
+        target: 'http://localhost:8080',
+        changeOrigin: true,
+      },
     },
   },
 })